@@ -0,0 +1,185 @@
+// cmd/admin/main.go
+// Службова консоль адміністратора для операційних задач
+//
+// Замінює ручні запити до MongoDB у робочому середовищі: підключається до бази
+// з тими самими змінними оточення, що й сервер, і виконує дії через його сервіси.
+//
+// Використання:
+//
+//	go run ./cmd/admin promote -user <id|email> [-role ADMIN] -reason "..."
+//	go run ./cmd/admin resend-verification -user <id|email> [-phone +380...] [-force]
+//	go run ./cmd/admin rebuild-indexes
+//	go run ./cmd/admin recompute-polls [-poll <id>]
+//	go run ./cmd/admin purge-user -user <id|email> -yes
+//	go run ./cmd/admin send-test-notification -user <id|email> [-title "..."] [-body "..."]
+//
+// Загальний прапорець -timeout вказується перед командою:
+//
+//	go run ./cmd/admin -timeout 30m recompute-polls
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/database"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// command - підкоманда консолі. setup оголошує прапорці команди і повертає дію,
+// яка виконується після їх розбору та підключення до бази.
+type command struct {
+	name    string
+	summary string
+	setup   func(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error
+}
+
+var commands = []command{
+	{"promote", "change the role of a user (ADMIN by default)", runPromote},
+	{"resend-verification", "send a new phone verification code", runResendVerification},
+	{"rebuild-indexes", "create missing MongoDB indexes", runRebuildIndexes},
+	{"recompute-polls", "recalculate stored poll results and poll listings", runRecomputePolls},
+	{"purge-user", "delete an account and anonymize its data", runPurgeUser},
+	{"send-test-notification", "send a system notification to a user", runSendTestNotification},
+}
+
+// adminApp - підключення до бази та сервіси, спільні для команд
+type adminApp struct {
+	cfg *config.Config
+	db  *database.MongoDB
+
+	notificationService *services.NotificationService
+}
+
+func main() {
+	timeout := flag.Duration("timeout", 10*time.Minute, "overall timeout")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	// Прапорці розбираються до підключення: помилка або -h не чекають на базу
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	run := cmd.setup(fs)
+	if err := parseFlags(fs, flag.Args()[1:]); err != nil {
+		log.Fatalf("❌ %s: %v", cmd.name, err)
+	}
+
+	cfg := config.Load()
+
+	db, err := database.NewMongoDB(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	app := &adminApp{cfg: cfg, db: db}
+	if err := run(ctx, app); err != nil {
+		// log.Fatalf не виконує defer: з'єднання закривається явно
+		cancel()
+		db.Close()
+		log.Fatalf("❌ %s: %v", cmd.name, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: go run ./cmd/admin [-timeout 10m] <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run go run ./cmd/admin <command> -h for command flags.")
+}
+
+// collection - колекція робочої бази
+func (a *adminApp) collection(name string) *mongo.Collection {
+	return a.db.Database.Collection(name)
+}
+
+// notifications - сервіс сповіщень з налаштуваннями FCM сервера
+func (a *adminApp) notifications() *services.NotificationService {
+	if a.notificationService == nil {
+		a.notificationService = services.NewNotificationService(a.cfg, a.collection("users"), a.collection("notifications"), a.collection("notification_outbox"))
+	}
+	return a.notificationService
+}
+
+// fileStorage - файлове сховище, як його налаштовує сервер (FILE_STORAGE_DRIVER)
+func (a *adminApp) fileStorage() (services.FileStorage, error) {
+	switch a.cfg.FileStorageDriver {
+	case "s3":
+		return services.NewS3FileStorage(a.cfg.S3Endpoint, a.cfg.S3Region, a.cfg.S3Bucket, a.cfg.S3AccessKey, a.cfg.S3SecretKey, a.cfg.S3PathStyle)
+	case "local":
+		return services.NewLocalFileStorage(a.cfg.FileStorageDir, a.cfg.FileURLSecret, "/media")
+	default:
+		return nil, fmt.Errorf("unknown FILE_STORAGE_DRIVER %q (expected local or s3)", a.cfg.FileStorageDriver)
+	}
+}
+
+// findUser шукає користувача за ID або email
+func (a *adminApp) findUser(ctx context.Context, ref string) (*models.User, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("-user is required")
+	}
+
+	filter := bson.M{"email": ref}
+	if id, err := primitive.ObjectIDFromHex(ref); err == nil {
+		filter = bson.M{"_id": id}
+	}
+
+	var user models.User
+	err := a.collection("users").FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("user %q not found", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+	return &user, nil
+}
+
+// parseFlags розбирає прапорці команди; позиційні аргументи не приймаються
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
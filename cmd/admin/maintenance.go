@@ -0,0 +1,161 @@
+// cmd/admin/maintenance.go
+// Обслуговування бази та перевірка доставки сповіщень
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// runRebuildIndexes створює індекси, як при старті сервера; наявні індекси не змінюються
+func runRebuildIndexes(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	return func(ctx context.Context, app *adminApp) error {
+		log.Println("🔧 Creating indexes...")
+		if err := app.db.CreateIndexes(ctx); err != nil {
+			return err
+		}
+		log.Println("✅ Indexes are up to date")
+		return nil
+	}
+}
+
+// runRecomputePolls перераховує збережені підсумки опитувань (results, total_responses)
+// і кеш підсумків (poll_results) за відповідями та перебудовує картки списку опитувань (poll_summaries)
+func runRecomputePolls(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	pollHex := fs.String("poll", "", "poll ID (all polls if empty)")
+
+	return func(ctx context.Context, app *adminApp) error {
+		filter := bson.M{}
+		var pollID primitive.ObjectID
+		if *pollHex != "" {
+			id, err := primitive.ObjectIDFromHex(*pollHex)
+			if err != nil {
+				return fmt.Errorf("invalid poll ID: %w", err)
+			}
+			pollID = id
+			filter["_id"] = pollID
+		}
+
+		pollCollection := app.collection("polls")
+		pollResults := services.NewPollResultService(app.collection("poll_responses"), app.collection("poll_results"))
+		pollResponses := services.NewPollResponseService(pollCollection, app.collection("poll_responses"), pollResults)
+		// Відповіді, що ще зберігаються в документах опитувань (сервер після оновлення не запускався), інакше не врахуються
+		moved, err := pollResponses.MigrateEmbedded(ctx)
+		if err != nil {
+			return fmt.Errorf("error moving poll responses: %w", err)
+		}
+		if moved > 0 {
+			log.Printf("📦 Moved %d poll responses to poll_responses", moved)
+		}
+
+		cursor, err := pollCollection.Find(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("error fetching polls: %w", err)
+		}
+		defer cursor.Close(ctx)
+
+		updated := 0
+		for cursor.Next(ctx) {
+			var poll models.Poll
+			if err := cursor.Decode(&poll); err != nil {
+				return fmt.Errorf("error decoding poll: %w", err)
+			}
+
+			responses, err := pollResponses.ForPoll(ctx, poll.ID)
+			if err != nil {
+				return fmt.Errorf("error fetching responses of poll %s: %w", poll.ID.Hex(), err)
+			}
+			_, err = pollCollection.UpdateOne(ctx, bson.M{"_id": poll.ID}, bson.M{"$set": bson.M{
+				"results":         poll.CalculateResults(responses),
+				"total_responses": len(responses),
+				"response_count":  len(responses),
+			}})
+			if err != nil {
+				return fmt.Errorf("error saving results of poll %s: %w", poll.ID.Hex(), err)
+			}
+			if _, err := pollResults.Rebuild(ctx, &poll); err != nil {
+				return fmt.Errorf("error rebuilding results cache of poll %s: %w", poll.ID.Hex(), err)
+			}
+			updated++
+		}
+		if err := cursor.Err(); err != nil {
+			return fmt.Errorf("error reading polls: %w", err)
+		}
+		if *pollHex != "" && updated == 0 {
+			return fmt.Errorf("poll %s not found", pollID.Hex())
+		}
+		log.Printf("📊 Recomputed results of %d polls", updated)
+
+		summaries := services.NewPollSummaryService(pollCollection, app.collection("poll_summaries"))
+		if *pollHex != "" {
+			if err := summaries.Sync(ctx, pollID); err != nil {
+				return fmt.Errorf("error syncing poll summary: %w", err)
+			}
+			log.Printf("✅ Poll %s recomputed", pollID.Hex())
+			return nil
+		}
+
+		count, err := summaries.Rebuild(ctx)
+		if err != nil {
+			return fmt.Errorf("error rebuilding poll summaries: %w", err)
+		}
+		log.Printf("✅ Polls recomputed, %d poll summaries rebuilt", count)
+		return nil
+	}
+}
+
+// runSendTestNotification надсилає системне сповіщення: запис у стрічці користувача
+// та push на його пристрої, якщо налаштовано FCM
+func runSendTestNotification(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	userRef := fs.String("user", "", "user ID or email")
+	title := fs.String("title", "Тестове сповіщення", "notification title")
+	body := fs.String("body", "Перевірка доставки сповіщень єМісто", "notification body")
+
+	return func(ctx context.Context, app *adminApp) error {
+		if strings.TrimSpace(*title) == "" || strings.TrimSpace(*body) == "" {
+			return fmt.Errorf("-title and -body must not be empty")
+		}
+
+		user, err := app.findUser(ctx, *userRef)
+		if err != nil {
+			return err
+		}
+
+		if app.cfg.FirebaseKey == "" {
+			log.Println("⚠️  Warning: FIREBASE_KEY is not set, the notification is only stored")
+		}
+
+		notifications := app.notifications()
+		data := map[string]interface{}{
+			"test":    true,
+			"sent_at": time.Now().Format(time.RFC3339),
+		}
+		if err := notifications.SendNotificationToUser(ctx, user.ID, *title, *body, services.NotificationTypeSystem, data, nil); err != nil {
+			return fmt.Errorf("error sending notification: %w", err)
+		}
+
+		// Невдалий push не повертає помилку, а стає в чергу: стан провайдерів показує, чи дійшов він
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tSTATE\tFAILURES\tLAST ERROR")
+		for _, health := range notifications.ProviderHealth() {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", health.Name, health.State, health.ConsecutiveFailures, health.LastError)
+		}
+		w.Flush()
+
+		log.Printf("✅ Test notification sent to %s (%s)", user.Email, user.ID.Hex())
+		return nil
+	}
+}
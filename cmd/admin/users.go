@@ -0,0 +1,206 @@
+// cmd/admin/users.go
+// Команди для облікових записів: роль, повторний код підтвердження, видалення
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// runPromote змінює роль так само, як PUT /users/:id/role: з записом в історії ролей
+// та відкликанням токенів, виданих зі старою роллю
+func runPromote(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	userRef := fs.String("user", "", "user ID or email")
+	role := fs.String("role", string(models.RoleAdmin), "new role (USER, MODERATOR, ADMIN, SUPER_ADMIN)")
+	reason := fs.String("reason", "", "reason recorded in the role history")
+
+	return func(ctx context.Context, app *adminApp) error {
+		toRole := models.UserRole(strings.ToUpper(strings.TrimSpace(*role)))
+		if !toRole.IsValid() {
+			return fmt.Errorf("invalid role %q", *role)
+		}
+		if strings.TrimSpace(*reason) == "" {
+			return fmt.Errorf("-reason is required")
+		}
+
+		user, err := app.findUser(ctx, *userRef)
+		if err != nil {
+			return err
+		}
+
+		fromRole := user.GetRole()
+		if fromRole == toRole {
+			log.Printf("✅ User %s already has role %s", user.Email, toRole)
+			return nil
+		}
+
+		now := time.Now()
+		change := models.RoleChange{
+			FromRole:  string(fromRole),
+			ToRole:    string(toRole),
+			Source:    models.RoleChangeSourceCLI,
+			Reason:    strings.TrimSpace(*reason),
+			ChangedAt: now,
+		}
+
+		// Фільтр за старою роллю, щоб історія не розійшлася з паралельною зміною
+		var currentRole interface{} = user.Role
+		if user.Role == "" {
+			currentRole = bson.M{"$in": []interface{}{"", nil}} // legacy users без поля role
+		}
+		result, err := app.collection("users").UpdateOne(ctx,
+			bson.M{"_id": user.ID, "role": currentRole},
+			bson.M{
+				"$set": bson.M{
+					"role":       string(toRole),
+					"updated_at": now,
+				},
+				"$inc": bson.M{"token_version": 1},
+				"$push": bson.M{"role_history": bson.M{
+					"$each":  []models.RoleChange{change},
+					"$slice": -models.MaxRoleHistory,
+				}},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error updating role: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return fmt.Errorf("user role was changed concurrently, run the command again")
+		}
+
+		// Користувач входить заново з новою роллю
+		refreshTokens := services.NewRefreshTokenService(app.collection("refresh_tokens"), time.Duration(app.cfg.RefreshTokenTTLDays)*24*time.Hour)
+		if _, err := refreshTokens.RevokeAll(ctx, user.ID); err != nil {
+			log.Printf("⚠️  Warning: error revoking refresh tokens: %v", err)
+		}
+		sessions := services.NewSessionService(app.db.Collection("sessions"), time.Duration(app.cfg.SessionCheckIntervalSec)*time.Second)
+		if err := sessions.RevokeAll(ctx, user.ID); err != nil {
+			log.Printf("⚠️  Warning: error revoking sessions: %v", err)
+		}
+
+		log.Printf("✅ User %s (%s): role %s -> %s", user.Email, user.ID.Hex(), fromRole, toRole)
+		return nil
+	}
+}
+
+// runResendVerification надсилає новий код підтвердження телефону з тими ж лімітами,
+// що й POST /auth/phone/request-code
+func runResendVerification(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	userRef := fs.String("user", "", "user ID or email")
+	phone := fs.String("phone", "", "phone number (defaults to the phone in the profile)")
+	force := fs.Bool("force", false, "send a code even if the phone is already verified")
+
+	return func(ctx context.Context, app *adminApp) error {
+		user, err := app.findUser(ctx, *userRef)
+		if err != nil {
+			return err
+		}
+
+		number := strings.TrimSpace(*phone)
+		if number == "" {
+			number = user.Phone
+		}
+		if number == "" {
+			return fmt.Errorf("user %s has no phone number, pass -phone", user.Email)
+		}
+		if user.PhoneVerifiedAt != nil && number == user.Phone && !*force {
+			return fmt.Errorf("phone of user %s was verified at %s, pass -force to send a new code",
+				user.Email, user.PhoneVerifiedAt.Format(time.RFC3339))
+		}
+
+		var smsProvider services.SMSProvider = services.LogSMSProvider{}
+		if app.cfg.SMSGatewayURL != "" {
+			smsProvider = services.NewHTTPSMSProvider(app.cfg.SMSGatewayURL, app.cfg.SMSGatewayToken, app.cfg.SMSSender)
+		} else {
+			log.Println("⚠️  Warning: SMS_GATEWAY_URL is not set, the code is written to the log")
+		}
+		phoneVerification := services.NewPhoneVerificationService(app.collection("phone_codes"), app.collection("users"), smsProvider, services.PhoneVerificationConfig{
+			CodeTTL:        time.Duration(app.cfg.PhoneCodeTTLMin) * time.Minute,
+			MaxAttempts:    app.cfg.PhoneCodeMaxAttempts,
+			CodesPerHour:   app.cfg.PhoneCodesPerHour,
+			ResendInterval: time.Duration(app.cfg.PhoneCodeResendSec) * time.Second,
+		})
+
+		expiresAt, err := phoneVerification.RequestCode(ctx, user.ID, number)
+		if err != nil {
+			return fmt.Errorf("error sending verification code: %w", err)
+		}
+
+		log.Printf("✅ Verification code sent to %s (user %s), valid until %s", number, user.Email, expiresAt.Format(time.RFC3339))
+		return nil
+	}
+}
+
+// runPurgeUser видаляє акаунт так само, як DELETE /auth/account, і одразу виконує
+// знеособлення, не чекаючи фонового обробника сервера
+func runPurgeUser(fs *flag.FlagSet) func(ctx context.Context, app *adminApp) error {
+	userRef := fs.String("user", "", "user ID or email")
+	yes := fs.Bool("yes", false, "confirm that the account and its personal data are erased irreversibly")
+
+	return func(ctx context.Context, app *adminApp) error {
+		user, err := app.findUser(ctx, *userRef)
+		if err != nil {
+			return err
+		}
+		if !*yes {
+			return fmt.Errorf("erasing %s (%s) cannot be undone, pass -yes to confirm", user.Email, user.ID.Hex())
+		}
+
+		fileStorage, err := app.fileStorage()
+		if err != nil {
+			return fmt.Errorf("failed to initialize file storage: %w", err)
+		}
+		urlTTL := time.Duration(app.cfg.SignedURLTTLMin) * time.Minute
+		avatarService := services.NewAvatarService(fileStorage, app.collection("users"), urlTTL)
+		chatAttachmentService := services.NewChatAttachmentService(fileStorage, app.collection("chat_uploads"), int64(app.cfg.ChatAttachmentMaxMB)<<20, urlTTL)
+		dataExportService := services.NewDataExportService(
+			app.db.Database,
+			fileStorage,
+			app.notifications(),
+			time.Duration(app.cfg.DataExportRetentionDays)*24*time.Hour,
+			urlTTL,
+		)
+		apiKeyService := services.NewAPIKeyService(app.collection("api_keys"))
+		erasure := services.NewAccountErasureService(app.db.Database, avatarService, chatAttachmentService, dataExportService, apiKeyService)
+
+		deletion, code, err := erasure.Request(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error requesting account deletion: %w", err)
+		}
+		log.Printf("🗑️  Account %s blocked, erasing personal data...", user.Email)
+
+		// Заодно обробляються запити, що вже чекали в черзі
+		processed := erasure.ProcessPending()
+
+		deletion, err = erasure.Receipt(ctx, deletion.ID, code)
+		if err != nil {
+			return fmt.Errorf("error fetching deletion status: %w", err)
+		}
+		steps := make([]string, 0, len(deletion.Results))
+		for step := range deletion.Results {
+			steps = append(steps, step)
+		}
+		sort.Strings(steps)
+		for _, step := range steps {
+			fmt.Printf("  %-28s %d\n", step, deletion.Results[step])
+		}
+		if deletion.Status != models.AccountDeletionCompleted {
+			return fmt.Errorf("deletion %s is %s: %s", deletion.ID.Hex(), deletion.Status, deletion.LastError)
+		}
+
+		log.Printf("✅ Account %s erased (deletion %s, %d queued requests processed)", user.ID.Hex(), deletion.ID.Hex(), processed)
+		return nil
+	}
+}
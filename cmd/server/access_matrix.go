@@ -0,0 +1,468 @@
+// cmd/server/access_matrix.go
+
+package main
+
+import (
+	"net/http"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeAccessMatrix - вимоги доступу для кожного маршруту.
+// Новий маршрут без запису тут зупинить запуск сервера, а розбіжність
+// між матрицею та middleware груп маршрутів виявить access_matrix_test.go.
+var routeAccessMatrix = middleware.AccessMatrix{
+	// ===== АВТОРИЗАЦІЯ =====
+	public(http.MethodPost, "/api/v1/auth/register"),
+	public(http.MethodPost, "/api/v1/auth/login"),
+	public(http.MethodPost, "/api/v1/auth/refresh"),
+	public(http.MethodPost, "/api/v1/auth/logout"),
+	public(http.MethodGet, "/api/v1/auth/account/deletions/:id"),
+	public(http.MethodGet, "/api/v1/auth/sso/providers"),
+	public(http.MethodGet, "/api/v1/auth/sso/:provider/start"),
+	public(http.MethodGet, "/api/v1/auth/sso/:provider/callback"),
+
+	// ===== ПІСОЧНИЦЯ (лише при SANDBOX_MODE) =====
+	public(http.MethodPost, "/api/v1/sandbox/reset"),
+
+	// ===== ГРОМАДИ ТА БРЕНДИНГ =====
+	public(http.MethodGet, "/api/v1/communities"),
+	public(http.MethodGet, "/api/v1/public/settings"),
+	public(http.MethodGet, "/api/v1/public/banners"),
+	public(http.MethodGet, "/api/v1/public/stats/history"),
+	public(http.MethodGet, "/api/v1/embed/:token"), // Токен віджета перевіряє обробник
+	public(http.MethodGet, "/api/v1/embed/:token/data"),
+
+	// ===== КАТЕГОРІЇ (ТАКСОНОМІЇ) =====
+	public(http.MethodGet, "/api/v1/taxonomies"),
+	public(http.MethodGet, "/api/v1/calendar"),
+
+	// ===== BATCH (холодний старт мобільного застосунку) =====
+	public(http.MethodPost, "/api/v1/batch"),
+
+	// ===== ТЕГИ =====
+	public(http.MethodGet, "/api/v1/tags/autocomplete"),
+	public(http.MethodGet, "/api/v1/tags/trending"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/tags/merge"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/tags/:name"),
+
+	// ===== ПРОФІЛЬ КОРИСТУВАЧА =====
+	authenticated(http.MethodGet, "/api/v1/auth/profile"),
+	authenticated(http.MethodPut, "/api/v1/auth/profile"),
+	authenticated(http.MethodPut, "/api/v1/auth/password"),
+	authenticated(http.MethodGet, "/api/v1/auth/export"),
+	authenticated(http.MethodGet, "/api/v1/auth/api-keys"),
+	authenticated(http.MethodPost, "/api/v1/auth/api-keys"),
+	authenticated(http.MethodDelete, "/api/v1/auth/api-keys/:id"),
+	authenticated(http.MethodGet, "/api/v1/auth/profile/interests"),
+	authenticated(http.MethodPut, "/api/v1/auth/profile/interests"),
+	authenticated(http.MethodPut, "/api/v1/auth/profile/privacy"),
+	authenticated(http.MethodGet, "/api/v1/auth/sessions"),
+	authenticated(http.MethodDelete, "/api/v1/auth/sessions/:id"),
+	authenticated(http.MethodDelete, "/api/v1/auth/account"),
+	authenticated(http.MethodPost, "/api/v1/auth/phone/request-code"),
+	authenticated(http.MethodPost, "/api/v1/auth/phone/verify"),
+	authenticated(http.MethodPost, "/api/v1/users/me/avatar"),
+	authenticated(http.MethodDelete, "/api/v1/users/me/avatar"),
+	authenticated(http.MethodGet, "/api/v1/users/me/resident-card"),
+	authenticated(http.MethodPost, "/api/v1/users/me/resident-card"),
+	authenticated(http.MethodDelete, "/api/v1/users/me/resident-card"),
+	public(http.MethodGet, "/api/v1/users/:id/avatar"),
+	public(http.MethodGet, "/api/v1/users/:id/public"),
+
+	// ===== ГРОМАДИ =====
+	authenticated(http.MethodPost, "/api/v1/communities/:code/join"),
+	authenticated(http.MethodPost, "/api/v1/communities/:code/leave"),
+
+	// ===== ПОШУК =====
+	authenticated(http.MethodGet, "/api/v1/search/users"),
+
+	// ===== СТАТИСТИКА =====
+	authenticated(http.MethodGet, "/api/v1/stats/user"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/stats/platform"),
+
+	// ===== МОДЕРАЦІЯ КОРИСТУВАЧІВ =====
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/users/:id/ban"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/users/:id/unban"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/users/:id/trust"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/notes"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/notes/mentions"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/notes"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/notes/:id"),
+	role(models.RoleModerator, http.MethodDelete, "/api/v1/moderation/notes/:id"),
+
+	// ===== ІМПОРТ З СОЦМЕРЕЖ =====
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/import/feeds"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/import/feeds"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/import/feeds/:id"),
+	role(models.RoleModerator, http.MethodDelete, "/api/v1/moderation/import/feeds/:id"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/import/feeds/:id/run"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/import/feeds/:id/imports"),
+
+	// ===== УПРАВЛІННЯ КОРИСТУВАЧАМИ (ADMIN) =====
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/users"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/users/:id"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id"),
+	permission(models.RoleAdmin, models.PermissionManageUsers, http.MethodDelete, "/api/v1/users/:id"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id/block"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id/unblock"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id/suspend"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id/verify"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/users/:id/role"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/users/:id/role-history"),
+	role(models.RoleAdmin, http.MethodPut, "/api/v1/admin/users/:id/moderation-scope"),
+	permission(models.RoleAdmin, models.PermissionManageUsers, http.MethodPost, "/api/v1/admin/users/bulk"),
+	permission(models.RoleAdmin, models.PermissionVerifyUser, http.MethodGet, "/api/v1/admin/resident-cards"),
+	permission(models.RoleAdmin, models.PermissionVerifyUser, http.MethodPost, "/api/v1/admin/resident-cards/:id/verify"),
+	permission(models.RoleAdmin, models.PermissionVerifyUser, http.MethodPost, "/api/v1/admin/resident-cards/:id/reject"),
+	permission(models.RoleAdmin, models.PermissionVerifyUser, http.MethodPost, "/api/v1/admin/resident-cards/:id/revoke"),
+
+	// ===== АНАЛІТИКА =====
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/users"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/analytics/content"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/moderation"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/cohorts"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/segments"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodPost, "/api/v1/analytics/segments"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodPut, "/api/v1/analytics/segments/:id"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodDelete, "/api/v1/analytics/segments/:id"),
+
+	// ===== ВИВАНТАЖЕННЯ ДЛЯ ДОСЛІДНИКІВ =====
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/exports"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodPost, "/api/v1/analytics/exports/salt/rotate"),
+	permission(models.RoleAdmin, models.PermissionViewAnalytics, http.MethodGet, "/api/v1/analytics/exports/:dataset"),
+
+	// ===== ЖУРНАЛ АУДИТУ =====
+	permission(models.RoleAdmin, models.PermissionViewAuditLogs, http.MethodGet, "/api/v1/admin/audit-logs"),
+
+	// ===== УПРАВЛІННЯ ГРОМАДАМИ (SUPER_ADMIN) =====
+	role(models.RoleSuperAdmin, http.MethodPost, "/api/v1/communities"),
+	role(models.RoleSuperAdmin, http.MethodPut, "/api/v1/communities/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPut, "/api/v1/admin/settings"),
+
+	// ===== УПРАВЛІННЯ КАТЕГОРІЯМИ =====
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/taxonomies"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/taxonomies"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPut, "/api/v1/admin/taxonomies/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/banners"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/banners"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPut, "/api/v1/admin/banners/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodDelete, "/api/v1/admin/banners/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/embeds"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/storage"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/database/retries"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/backups"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/backups"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/counters/reconciliations"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/counters/reconcile"),
+
+	// ===== EMAIL =====
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/email/queue"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/email/logs"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/calendar/days"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodDelete, "/api/v1/admin/calendar/days/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/petition-thresholds"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPut, "/api/v1/admin/petition-thresholds/:id"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodDelete, "/api/v1/admin/petition-thresholds/:id"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/email/suppressions"),
+	role(models.RoleAdmin, http.MethodPost, "/api/v1/admin/email/suppressions"),
+	role(models.RoleAdmin, http.MethodDelete, "/api/v1/admin/email/suppressions/:email"),
+
+	// ===== OUTBOX СПОВІЩЕНЬ =====
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/notifications/outbox"),
+	role(models.RoleAdmin, http.MethodPost, "/api/v1/admin/notifications/outbox/:id/retry"),
+
+	// ===== REALTIME-ЛІЧИЛЬНИКИ АДМІН-ПАНЕЛІ =====
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/admin/realtime/counters"),
+	public(http.MethodGet, "/ws/admin"), // Токен адміністратора перевіряє обробник
+
+	// ===== ГРУПИ ТА ЧАТИ =====
+	public(http.MethodGet, "/api/v1/groups/public"),
+	public(http.MethodGet, "/api/v1/search/groups"),
+	permission(models.RoleUser, models.PermissionCreateGroup, http.MethodPost, "/api/v1/groups"),
+	authenticated(http.MethodGet, "/api/v1/groups"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id"),
+	authenticated(http.MethodPut, "/api/v1/groups/:id"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id"),
+	permission(models.RoleUser, models.PermissionJoinGroup, http.MethodPost, "/api/v1/groups/:id/join"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/leave"),
+	authenticated(http.MethodPut, "/api/v1/groups/:id/members/:userId/role"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id/members/:userId"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/members/:userId/ban"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id/members/:userId/ban"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/bans"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/join-requests"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/join-requests/:requestId/approve"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/join-requests/:requestId/reject"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/invites"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/invites"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id/invites/:inviteId"),
+	permission(models.RoleUser, models.PermissionJoinGroup, http.MethodPost, "/api/v1/groups/join-by-invite"),
+	permission(models.RoleUser, models.PermissionSendMessage, http.MethodPost, "/api/v1/groups/:id/messages"),
+	permission(models.RoleUser, models.PermissionSendMessage, http.MethodPost, "/api/v1/groups/:id/attachments"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/messages"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/messages/poll"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/messages/search"),
+	authenticated(http.MethodPut, "/api/v1/groups/:id/messages/:msgId"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id/messages/:msgId"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/messages/:msgId/report"),
+	authenticated(http.MethodPut, "/api/v1/groups/:id/slow-mode"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/messages/pinned"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/messages/:msgId/pin"),
+	authenticated(http.MethodDelete, "/api/v1/groups/:id/messages/:msgId/pin"),
+	authenticated(http.MethodPut, "/api/v1/groups/:id/announcements-only"),
+	authenticated(http.MethodPost, "/api/v1/groups/:id/read"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/read-receipts"),
+	authenticated(http.MethodGet, "/api/v1/groups/:id/presence"),
+	authenticated(http.MethodGet, "/api/v1/conversations"),
+	authenticated(http.MethodGet, "/api/v1/conversations/unread"),
+	authenticated(http.MethodGet, "/api/v1/conversations/:userId/messages"),
+	permission(models.RoleUser, models.PermissionSendMessage, http.MethodPost, "/api/v1/conversations/:userId/messages"),
+	authenticated(http.MethodPost, "/api/v1/conversations/:userId/read"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/messages/held"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/messages/:id/approve"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/messages/:id/reject"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/messages/reported"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/messages/:id/review"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodGet, "/api/v1/admin/chat/banned-words"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodPost, "/api/v1/admin/chat/banned-words"),
+	permission(models.RoleAdmin, models.PermissionManageSystemSettings, http.MethodDelete, "/api/v1/admin/chat/banned-words/:id"),
+	authenticated(http.MethodGet, "/api/v1/stats/groups/:id"),
+	public(http.MethodGet, "/ws"), // Токен перевіряє обробник
+
+	// ===== ОГОЛОШЕННЯ =====
+	public(http.MethodGet, "/api/v1/announcements"),
+	public(http.MethodGet, "/api/v1/announcements/attributes"),
+	public(http.MethodGet, "/api/v1/announcements/:id"),
+	authenticated(http.MethodGet, "/api/v1/announcements/recommended"),
+	permission(models.RoleUser, models.PermissionCreateAnnouncement, http.MethodPost, "/api/v1/announcements"),
+	authenticated(http.MethodPut, "/api/v1/announcements/:id"),
+	authenticated(http.MethodDelete, "/api/v1/announcements/:id"),
+	permission(models.RoleModerator, models.PermissionModerateAnnouncement, http.MethodPut, "/api/v1/announcements/:id/approve"),
+	permission(models.RoleModerator, models.PermissionModerateAnnouncement, http.MethodPut, "/api/v1/announcements/:id/reject"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/announcements/:id/revisions"),
+	permission(models.RoleModerator, models.PermissionModerateAnnouncement, http.MethodGet, "/api/v1/moderation/posts/pending"),
+	permission(models.RoleModerator, models.PermissionModerateAnnouncement, http.MethodPost, "/api/v1/moderation/posts/:id/approve"),
+	permission(models.RoleModerator, models.PermissionModerateAnnouncement, http.MethodPost, "/api/v1/moderation/posts/:id/reject"),
+
+	// ===== ПОДІЇ =====
+	public(http.MethodGet, "/api/v1/events"),
+	public(http.MethodGet, "/api/v1/events/:id"),
+	public(http.MethodGet, "/api/v1/events/nearby"),
+	public(http.MethodGet, "/api/v1/search/events"),
+	authenticated(http.MethodGet, "/api/v1/events/recommended"),
+	permission(models.RoleUser, models.PermissionCreateEvent, http.MethodPost, "/api/v1/events"),
+	authenticated(http.MethodPut, "/api/v1/events/:id"),
+	authenticated(http.MethodDelete, "/api/v1/events/:id"),
+	authenticated(http.MethodPost, "/api/v1/events/:id/attend"),
+	authenticated(http.MethodPost, "/api/v1/events/:id/leave"),
+	permission(models.RoleModerator, models.PermissionModerateEvent, http.MethodPut, "/api/v1/events/:id/moderate"),
+	permission(models.RoleModerator, models.PermissionModerateEvent, http.MethodGet, "/api/v1/moderation/events/pending"),
+
+	// ===== ПЕТИЦІЇ =====
+	public(http.MethodGet, "/api/v1/petitions"),
+	public(http.MethodGet, "/api/v1/petitions/similar"),
+	public(http.MethodGet, "/api/v1/petitions/thresholds"),
+	public(http.MethodGet, "/api/v1/petitions/:id"),
+	permission(models.RoleUser, models.PermissionCreatePetition, http.MethodPost, "/api/v1/petitions"),
+	authenticated(http.MethodPost, "/api/v1/petitions/:id/publish"),
+	authenticated(http.MethodPost, "/api/v1/petitions/:id/extend-draft"),
+	permission(models.RoleUser, models.PermissionSignPetition, http.MethodPost, "/api/v1/petitions/:id/sign"),
+	authenticated(http.MethodPut, "/api/v1/petitions/:id"),
+	authenticated(http.MethodDelete, "/api/v1/petitions/:id"),
+	authenticated(http.MethodGet, "/api/v1/petitions/my"),
+	authenticated(http.MethodPost, "/api/v1/petitions/:id/co-authors"),
+	authenticated(http.MethodPost, "/api/v1/petitions/:id/co-authors/accept"),
+	authenticated(http.MethodPost, "/api/v1/petitions/:id/co-authors/decline"),
+	authenticated(http.MethodDelete, "/api/v1/petitions/:id/co-authors/:user_id"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/petitions/:id/status"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/petitions/:id/revisions"),
+	public(http.MethodGet, "/api/v1/feeds/petitions"),
+	public(http.MethodGet, "/api/v1/feeds/petitions/responses"),
+
+	// ===== ОПИТУВАННЯ =====
+	public(http.MethodGet, "/api/v1/polls"),
+	public(http.MethodGet, "/api/v1/polls/:id"),
+	public(http.MethodGet, "/api/v1/polls/:id/results"),
+	authenticated(http.MethodGet, "/api/v1/polls/recommended"),
+	permission(models.RoleUser, models.PermissionCreatePoll, http.MethodPost, "/api/v1/polls"),
+	permission(models.RoleUser, models.PermissionVotePoll, http.MethodPost, "/api/v1/polls/:id/respond"),
+	permission(models.RoleUser, models.PermissionVotePoll, http.MethodPut, "/api/v1/polls/:id/respond"),
+	authenticated(http.MethodPut, "/api/v1/polls/:id"),
+	authenticated(http.MethodDelete, "/api/v1/polls/:id"),
+	authenticated(http.MethodPost, "/api/v1/polls/:id/extend-draft"),
+	authenticated(http.MethodPost, "/api/v1/polls/:id/submit"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/polls/:id/status"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/polls"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/polls/:id/approve"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/polls/:id/reject"),
+	role(models.RoleModerator, http.MethodDelete, "/api/v1/polls/:id/force"),
+	role(models.RoleAdmin, http.MethodGet, "/api/v1/analytics/polls"),
+
+	// ===== ПРОБЛЕМИ МІСТА =====
+	public(http.MethodGet, "/api/v1/city-issues"),
+	public(http.MethodGet, "/api/v1/city-issues/similar"),
+	public(http.MethodGet, "/api/v1/city-issues/:id"),
+	permission(models.RoleUser, models.PermissionReportCityIssue, http.MethodPost, "/api/v1/city-issues"),
+	authenticated(http.MethodPut, "/api/v1/city-issues/:id"),
+	authenticated(http.MethodPost, "/api/v1/city-issues/:id/upvote"),
+	authenticated(http.MethodPost, "/api/v1/city-issues/:id/comments"),
+	authenticated(http.MethodPost, "/api/v1/city-issues/:id/subscribe"),
+	authenticated(http.MethodPut, "/api/v1/city-issues/:id/subscription"),
+	permission(models.RoleModerator, models.PermissionModerateCityIssue, http.MethodPut, "/api/v1/city-issues/:id/status"),
+	permission(models.RoleModerator, models.PermissionModerateCityIssue, http.MethodPut, "/api/v1/city-issues/:id/assign"),
+	permission(models.RoleModerator, models.PermissionModerateCityIssue, http.MethodPut, "/api/v1/city-issues/:id/priority"),
+	public(http.MethodGet, "/api/v1/feeds/issues"),
+	public(http.MethodGet, "/api/v1/feeds/issues/responses"),
+
+	// ===== ГРОМАДСЬКИЙ ТРАНСПОРТ =====
+	public(http.MethodGet, "/api/v1/transport/routes"),
+	public(http.MethodGet, "/api/v1/transport/routes/:id"),
+	public(http.MethodGet, "/api/v1/transport/routes/:id/schedule"),
+	public(http.MethodGet, "/api/v1/transport/routes/:id/timetable.pdf"),
+	public(http.MethodGet, "/api/v1/transport/stops/nearby"),
+	public(http.MethodGet, "/api/v1/transport/arrivals"),
+	public(http.MethodGet, "/api/v1/transport/live"),
+	public(http.MethodGet, "/api/v1/transport/alerts"),
+	public(http.MethodGet, "/api/v1/transport/alerts/:id"),
+	public(http.MethodPost, "/api/v1/transport/gps/:provider/webhook"), // Підпис провайдера перевіряє обробник
+	public(http.MethodGet, "/api/v1/transport/concessions/categories"),
+	authenticated(http.MethodGet, "/api/v1/transport/concession"),
+	authenticated(http.MethodPost, "/api/v1/transport/concession"),
+	authenticated(http.MethodDelete, "/api/v1/transport/concession"),
+	permission(models.RoleModerator, models.PermissionVerifyUser, http.MethodGet, "/api/v1/moderation/concessions/pending"),
+	permission(models.RoleModerator, models.PermissionVerifyUser, http.MethodPost, "/api/v1/moderation/concessions/:id/verify"),
+	permission(models.RoleModerator, models.PermissionVerifyUser, http.MethodPost, "/api/v1/moderation/concessions/:id/reject"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPost, "/api/v1/transport/routes"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPut, "/api/v1/transport/routes/:id"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodDelete, "/api/v1/transport/routes/:id"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPost, "/api/v1/transport/vehicles"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPut, "/api/v1/transport/vehicles/:id"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodDelete, "/api/v1/transport/vehicles/:id"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPut, "/api/v1/transport/vehicles/:id/tracking"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodGet, "/api/v1/transport/gps/providers"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodGet, "/api/v1/transport/alerts/manage"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPut, "/api/v1/transport/alerts/:id"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPost, "/api/v1/transport/alerts/:id/publish"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPost, "/api/v1/transport/alerts/:id/dismiss"),
+	permission(models.RoleAdmin, models.PermissionManageTransport, http.MethodPost, "/api/v1/transport/alerts/:id/end"),
+
+	// ===== СПОВІЩЕННЯ =====
+	public(http.MethodGet, "/api/v1/notification-types"),
+	authenticated(http.MethodGet, "/api/v1/notifications"),
+	authenticated(http.MethodGet, "/api/v1/notifications/poll"),
+	authenticated(http.MethodPut, "/api/v1/notifications/:id/read"),
+	authenticated(http.MethodPut, "/api/v1/notifications/read-all"),
+	authenticated(http.MethodDelete, "/api/v1/notifications/:id"),
+	authenticated(http.MethodPost, "/api/v1/device-tokens"),
+	authenticated(http.MethodDelete, "/api/v1/device-tokens/:token"),
+	authenticated(http.MethodGet, "/api/v1/notification-preferences"),
+	authenticated(http.MethodPut, "/api/v1/notification-preferences"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodPost, "/api/v1/notifications/send"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodPost, "/api/v1/notifications/emergency"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodGet, "/api/v1/campaigns"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodPost, "/api/v1/campaigns"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodGet, "/api/v1/campaigns/:id"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodPost, "/api/v1/campaigns/:id/start"),
+	permission(models.RoleAdmin, models.PermissionSendNotifications, http.MethodPost, "/api/v1/campaigns/:id/cancel"),
+
+	// ===== FAQ / БАЗА ЗНАНЬ =====
+	public(http.MethodGet, "/api/v1/faq/categories"),
+	public(http.MethodGet, "/api/v1/faq/articles"),
+	public(http.MethodGet, "/api/v1/faq/articles/:id"),
+	public(http.MethodPost, "/api/v1/faq/articles/:id/feedback"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/faq/categories"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/faq/categories"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/faq/categories/:id"),
+	role(models.RoleModerator, http.MethodDelete, "/api/v1/moderation/faq/categories/:id"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/faq/articles"),
+	role(models.RoleModerator, http.MethodPost, "/api/v1/moderation/faq/articles"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/faq/articles/:id"),
+	role(models.RoleModerator, http.MethodDelete, "/api/v1/moderation/faq/articles/:id"),
+
+	// ===== ОСВІТА: НАБІР ДО ШКІЛ І САДКІВ =====
+	public(http.MethodGet, "/api/v1/education/institutions"),
+	public(http.MethodGet, "/api/v1/education/enrollments"),
+	public(http.MethodGet, "/api/v1/education/enrollments/:id"),
+	authenticated(http.MethodGet, "/api/v1/education/subscription"),
+	authenticated(http.MethodPut, "/api/v1/education/subscription"),
+	authenticated(http.MethodDelete, "/api/v1/education/subscription"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPost, "/api/v1/admin/education/institutions"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPut, "/api/v1/admin/education/institutions/:id"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodGet, "/api/v1/admin/education/enrollments"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPost, "/api/v1/admin/education/enrollments"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPut, "/api/v1/admin/education/enrollments/:id"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPut, "/api/v1/admin/education/enrollments/:id/places/:institution_id"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPost, "/api/v1/admin/education/enrollments/:id/publish"),
+	permission(models.RoleAdmin, models.PermissionManageEducation, http.MethodPost, "/api/v1/admin/education/enrollments/:id/archive"),
+
+	// ===== ГРОМАДСЬКІ ОБГОВОРЕННЯ =====
+	public(http.MethodGet, "/api/v1/consultations"),
+	public(http.MethodGet, "/api/v1/consultations/:id"),
+	public(http.MethodGet, "/api/v1/consultations/:id/comments"),
+	public(http.MethodGet, "/api/v1/consultations/:id/report"),
+	authenticated(http.MethodPost, "/api/v1/consultations/:id/comments"),
+	role(models.RoleModerator, http.MethodPut, "/api/v1/moderation/consultations/comments/:comment_id/resolution"),
+	role(models.RoleModerator, http.MethodGet, "/api/v1/moderation/consultations/:id/report"),
+	permission(models.RoleAdmin, models.PermissionManageConsultations, http.MethodGet, "/api/v1/admin/consultations"),
+	permission(models.RoleAdmin, models.PermissionManageConsultations, http.MethodPost, "/api/v1/admin/consultations"),
+	permission(models.RoleAdmin, models.PermissionManageConsultations, http.MethodPut, "/api/v1/admin/consultations/:id"),
+	permission(models.RoleAdmin, models.PermissionManageConsultations, http.MethodPost, "/api/v1/admin/consultations/:id/publish"),
+	permission(models.RoleAdmin, models.PermissionManageConsultations, http.MethodPost, "/api/v1/admin/consultations/:id/complete"),
+
+	// ===== ФАЙЛИ ЛОКАЛЬНОГО СХОВИЩА =====
+	public(http.MethodGet, "/media/*key"), // Підпис посилання перевіряє обробник
+
+	// ===== HEALTH CHECK =====
+	public(http.MethodGet, "/health"),
+	public(http.MethodGet, "/health/ready"),
+
+	// ===== ВЕРСІЇ API =====
+	public(http.MethodGet, "/api/versions"),
+}
+
+// public - маршрут без автентифікації
+func public(method, path string) middleware.RouteAccess {
+	return middleware.RouteAccess{Method: method, Path: path}
+}
+
+// authenticated - маршрут для будь-якого автентифікованого користувача
+func authenticated(method, path string) middleware.RouteAccess {
+	return middleware.RouteAccess{Method: method, Path: path, MinRole: models.RoleUser}
+}
+
+// role - маршрут з мінімальною роллю
+func role(minRole models.UserRole, method, path string) middleware.RouteAccess {
+	return middleware.RouteAccess{Method: method, Path: path, MinRole: minRole}
+}
+
+// permission - маршрут з мінімальною роллю та дозволом
+func permission(minRole models.UserRole, perm models.Permission, method, path string) middleware.RouteAccess {
+	return middleware.RouteAccess{Method: method, Path: path, MinRole: minRole, Permission: perm}
+}
+
+// routeGroups - групи маршрутів за рівнем доступу; кожна перевіряє автентифікацію окремо
+type routeGroups struct {
+	protected     *gin.RouterGroup // Потрібна автентифікація
+	moderator     *gin.RouterGroup // Модератор і вище
+	cityModerator *gin.RouterGroup // Модератор рівня всього міста: недоступні модераторам із зоною (moderation_scope)
+	admin         *gin.RouterGroup // Адміністратор і вище
+}
+
+// newRouteGroups створює групи маршрутів з перевірками доступу; authenticate - AuthMiddleware
+func newRouteGroups(api *gin.RouterGroup, authenticate gin.HandlerFunc) routeGroups {
+	groups := routeGroups{
+		protected:     api.Group(""),
+		moderator:     api.Group(""),
+		cityModerator: api.Group(""),
+		admin:         api.Group(""),
+	}
+	groups.protected.Use(authenticate)
+	groups.moderator.Use(authenticate, middleware.RequireMinimumRole(string(models.RoleModerator)))
+	groups.cityModerator.Use(authenticate, middleware.RequireMinimumRole(string(models.RoleModerator)), middleware.RequireCityWideModerator())
+	groups.admin.Use(authenticate, middleware.RequireMinimumRole(string(models.RoleAdmin)))
+	return groups
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testAccounts - стан акаунтів замість кешу users (services.UserStatusCache)
+type testAccounts map[primitive.ObjectID]models.AccountStatus
+
+func (a testAccounts) AccountStatus(ctx context.Context, userID primitive.ObjectID) (models.AccountStatus, error) {
+	return a[userID], nil
+}
+
+// accessTestServer - router з групами маршрутів newRouteGroups і справжнім AuthMiddleware
+type accessTestServer struct {
+	router     *gin.Engine
+	groups     routeGroups
+	jwtManager *auth.JWTManager
+	accounts   testAccounts
+}
+
+func newAccessTestServer() *accessTestServer {
+	gin.SetMode(gin.TestMode)
+	s := &accessTestServer{
+		router:     gin.New(),
+		jwtManager: auth.NewJWTManager("access-matrix-test-secret", time.Hour),
+		accounts:   testAccounts{},
+	}
+	s.router.Use(routeAccessMatrix.RouteRules())
+	s.groups = newRouteGroups(s.router.Group("/api/v1"), middleware.AuthMiddleware(s.jwtManager, s.accounts, nil, nil))
+	return s
+}
+
+// token видає підписаний токен користувачу з роллю в токені tokenRole і станом акаунта status
+func (s *accessTestServer) token(t *testing.T, tokenRole models.UserRole, tokenVersion int, status models.AccountStatus) string {
+	t.Helper()
+	userID := primitive.NewObjectID()
+	s.accounts[userID] = status
+	token, err := s.jwtManager.GenerateToken(userID.Hex(), "access-test@ecity.local", string(tokenRole),
+		tokenRole.IsHigherOrEqual(models.RoleModerator), tokenVersion, "")
+	if err != nil {
+		t.Fatalf("generate %s token: %v", tokenRole, err)
+	}
+	return token
+}
+
+func (s *accessTestServer) roleToken(t *testing.T, role models.UserRole) string {
+	return s.token(t, role, 0, models.AccountStatus{Exists: true, Role: role})
+}
+
+func (s *accessTestServer) do(method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// register підключає маршрут матриці до групи за мінімальною роллю, як у main.go;
+// обробник лише відповідає 200
+func (s *accessTestServer) register(rule middleware.RouteAccess) {
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	path, underAPI := strings.CutPrefix(rule.Path, "/api/v1")
+	if rule.MinRole == "" {
+		if underAPI {
+			s.router.Group("/api/v1").Handle(rule.Method, path, ok)
+		} else {
+			s.router.Handle(rule.Method, rule.Path, ok)
+		}
+		return
+	}
+
+	var chain []gin.HandlerFunc
+	group := s.groups.protected
+	switch rule.MinRole {
+	case models.RoleModerator:
+		group = s.groups.moderator
+	case models.RoleAdmin:
+		group = s.groups.admin
+	case models.RoleSuperAdmin:
+		group = s.groups.admin
+		chain = append(chain, middleware.RequireMinimumRole(string(models.RoleSuperAdmin)))
+	}
+	if rule.Permission != "" {
+		chain = append(chain, middleware.RequirePermission(string(rule.Permission)))
+	}
+	group.Handle(rule.Method, path, append(chain, ok)...)
+}
+
+// testPath підставляє ObjectID замість параметрів маршруту
+func testPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = primitive.NewObjectID().Hex()
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Кожен маршрут матриці проходиться анонімно і токеном кожної ролі:
+// дозволено - 200, без токена - 401, роль без доступу - 403
+func TestAccessMatrixRoles(t *testing.T) {
+	s := newAccessTestServer()
+	for _, rule := range routeAccessMatrix {
+		s.register(rule)
+	}
+
+	roles := []models.UserRole{models.RoleUser, models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin}
+	tokens := make(map[models.UserRole]string, len(roles))
+	for _, role := range roles {
+		tokens[role] = s.roleToken(t, role)
+	}
+
+	for _, rule := range routeAccessMatrix {
+		t.Run(rule.Method+" "+rule.Path, func(t *testing.T) {
+			path := testPath(rule.Path)
+			for _, role := range append([]models.UserRole{""}, roles...) {
+				want := http.StatusOK
+				switch {
+				case rule.Allows(role):
+				case role == "":
+					want = http.StatusUnauthorized
+				default:
+					want = http.StatusForbidden
+				}
+
+				if got := s.do(rule.Method, path, tokens[role]); got != want {
+					name := string(role)
+					if name == "" {
+						name = "anonymous"
+					}
+					t.Errorf("as %s: got %d, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// Доступ визначає стан акаунта з бази, а не лише роль у токені
+func TestRouteGroupsAccountState(t *testing.T) {
+	s := newAccessTestServer()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	s.groups.protected.GET("/profile", ok)
+	s.groups.moderator.GET("/moderation/queue", ok)
+	s.groups.cityModerator.POST("/moderation/users/:id/ban", ok)
+	s.groups.admin.GET("/admin/users", ok)
+
+	scope := &models.ModerationScope{Neighborhoods: []string{"Центр"}}
+	blockedUntil := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		tokenRole    models.UserRole
+		tokenVersion int
+		status       models.AccountStatus
+		want         int
+	}{
+		{
+			name:      "user",
+			method:    http.MethodGet,
+			path:      "/api/v1/profile",
+			tokenRole: models.RoleUser,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleUser},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "blocked user",
+			method:    http.MethodGet,
+			path:      "/api/v1/profile",
+			tokenRole: models.RoleUser,
+			status:    models.AccountStatus{Exists: true, IsBlocked: true, BlockedUntil: &blockedUntil, Role: models.RoleUser},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "deleted account",
+			method:    http.MethodGet,
+			path:      "/api/v1/profile",
+			tokenRole: models.RoleUser,
+			status:    models.AccountStatus{},
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:         "revoked token",
+			method:       http.MethodGet,
+			path:         "/api/v1/profile",
+			tokenRole:    models.RoleUser,
+			tokenVersion: 1,
+			status:       models.AccountStatus{Exists: true, Role: models.RoleUser, TokenVersion: 2},
+			want:         http.StatusUnauthorized,
+		},
+		{
+			name:      "admin demoted after the token was issued",
+			method:    http.MethodGet,
+			path:      "/api/v1/admin/users",
+			tokenRole: models.RoleAdmin,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleUser},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "user promoted after the token was issued",
+			method:    http.MethodGet,
+			path:      "/api/v1/moderation/queue",
+			tokenRole: models.RoleUser,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleModerator},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "city-wide moderator",
+			method:    http.MethodPost,
+			path:      "/api/v1/moderation/users/" + primitive.NewObjectID().Hex() + "/ban",
+			tokenRole: models.RoleModerator,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleModerator},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "scoped moderator on a city-wide route",
+			method:    http.MethodPost,
+			path:      "/api/v1/moderation/users/" + primitive.NewObjectID().Hex() + "/ban",
+			tokenRole: models.RoleModerator,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleModerator, ModerationScope: scope},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "scoped moderator on a moderator route",
+			method:    http.MethodGet,
+			path:      "/api/v1/moderation/queue",
+			tokenRole: models.RoleModerator,
+			status:    models.AccountStatus{Exists: true, Role: models.RoleModerator, ModerationScope: scope},
+			want:      http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := s.token(t, tt.tokenRole, tt.tokenVersion, tt.status)
+			if got := s.do(tt.method, tt.path, token); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// cmd/server/api_versions.go
+
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+)
+
+// apiVersions - версії API для клієнтів (GET /api/versions).
+// Застарілий маршрут або поле спершу описується тут: клієнти бачать дату вимкнення
+// в заголовку Sunset і в журналі змін. Нова версія (/api/v2) додається сюди разом
+// з групою маршрутів у main.go.
+var apiVersions = middleware.APIVersions{
+	Current: "v1",
+	Versions: []middleware.APIVersion{
+		{
+			Version:    "v1",
+			BasePath:   "/api/v1",
+			MediaType:  middleware.MediaTypeFor("v1"),
+			Status:     middleware.APIVersionStable,
+			ReleasedAt: apiDate("2026-01-05"),
+
+			DeprecatedEndpoints: []middleware.DeprecatedEndpoint{
+				{
+					Method:       http.MethodDelete,
+					Path:         "/api/v1/polls/:id/force",
+					Replacement:  "/api/v1/polls/:id",
+					DeprecatedAt: apiDate("2026-10-14"),
+					SunsetAt:     apiDate("2027-04-14"),
+					Note:         "DELETE /api/v1/polls/:id already allows moderators to delete any poll",
+				},
+			},
+			DeprecatedFields: []middleware.FieldChange{
+				{
+					Resource:    "user",
+					Field:       "is_moderator",
+					Replacement: "role",
+					Since:       apiDate("2026-10-14"),
+					Note:        "is_moderator is true for every role from moderator up; use role",
+				},
+				{
+					Resource:    "list responses",
+					Field:       "pagination, total, total_pages and named item arrays",
+					Replacement: "items, page_info",
+					Since:       apiDate("2026-10-14"),
+					Note:        "Send X-Pagination-Format: page_info to receive only the new envelope",
+				},
+				{
+					Resource:    "group messages (GET /api/v1/groups/:id/messages)",
+					Field:       "page",
+					Replacement: "before, after",
+					Since:       apiDate("2026-10-14"),
+					Note:        "Page offsets shift while the chat is active; use message ID cursors from cursor_info",
+				},
+			},
+			RemovedFields: []middleware.FieldChange{
+				{
+					Resource:    "poll",
+					Field:       "responses",
+					Replacement: "total_responses, GET /api/v1/polls/:id/results",
+					Since:       apiDate("2026-10-14"),
+					Note:        "Responses are stored separately and are no longer embedded in the poll",
+				},
+			},
+			Changelog: []middleware.APIChange{
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeAdded,
+					Description: "GET /api/versions, X-API-Version negotiation and Deprecation/Sunset headers",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeDeprecated,
+					Description: "DELETE /api/v1/polls/:id/force in favour of DELETE /api/v1/polls/:id",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeDeprecated,
+					Description: "user.is_moderator in favour of user.role; legacy pagination keys in favour of items/page_info",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeChanged,
+					Description: "GET /api/v1/groups/:id/messages pages by before/after message cursors; page is deprecated",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeRemoved,
+					Description: "poll.responses; poll documents carry total_responses and results only",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeChanged,
+					Description: "GET /api/v1/polls/:id/results reads cached tallies; text_answers lists the latest 100, newest first",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeAdded,
+					Description: "poll question show_if conditions; answers to questions skipped by a condition are rejected",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeAdded,
+					Description: "POST /api/v1/polls/:id/submit and the poll moderation queue GET /api/v1/moderation/polls with approve/reject",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeChanged,
+					Description: "polls created by regular users stay drafts until a moderator approves them",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeChanged,
+					Description: "poll target_groups are validated, restrict voting in non-public polls and are notified when voting opens",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeChanged,
+					Description: "poll age_restriction and location_required are enforced at vote time; profile birth_date and vote location added",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeAdded,
+					Description: "poll verified_voters_only mode (one vote per Diia/BankID-confirmed person); PUT /api/v1/users/:id/verify accepts provider and tax_number",
+				},
+				{
+					Date:        apiDate("2026-10-14"),
+					Type:        middleware.APIChangeAdded,
+					Description: "PUT /api/v1/polls/:id/respond changes the user's answers until the poll closes (polls without allow_multiple); voting eligibility is checked again",
+				},
+			},
+		},
+	},
+}
+
+// apiDate - дата реєстру версій (YYYY-MM-DD, UTC)
+func apiDate(value string) time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic("invalid API version date: " + value)
+	}
+	return date
+}
@@ -19,18 +19,22 @@ import (
 	"nova-kakhovka-ecity/internal/models"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Часові пояси тихих годин не залежать від tzdata у контейнері
 
 	"nova-kakhovka-ecity/internal/config"
 	"nova-kakhovka-ecity/internal/database"
 	"nova-kakhovka-ecity/internal/handlers"
 	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/modules"
 	"nova-kakhovka-ecity/internal/services"
 	"nova-kakhovka-ecity/pkg/auth"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func main() {
@@ -41,6 +45,13 @@ func main() {
 	// ========================================
 	cfg := config.Load()
 	log.Printf("📋 Configuration loaded (Environment: %s)", cfg.Env)
+	if cfg.SandboxMode {
+		// Скидання пісочниці видаляє всі дані без резервної копії
+		if cfg.Env == "production" {
+			log.Fatal("SANDBOX_MODE cannot be enabled in production")
+		}
+		log.Println("🧪 SANDBOX MODE: demo data, push, email, SMS and external APIs are disabled")
+	}
 
 	// ========================================
 	// 2. ПІДКЛЮЧЕННЯ ДО MONGODB
@@ -68,10 +79,11 @@ func main() {
 	// 3. ІНІЦІАЛІЗАЦІЯ JWT МЕНЕДЖЕРА
 	// ========================================
 	log.Println("🔐 Initializing JWT manager...")
-	jwtManager := auth.NewJWTManager(
-		cfg.JWTSecret,
-		time.Duration(cfg.JWTExpiration)*time.Hour,
-	)
+	accessTokenTTL := time.Duration(cfg.JWTExpiration) * time.Hour
+	if cfg.JWTAccessTTLMinutes > 0 {
+		accessTokenTTL = time.Duration(cfg.JWTAccessTTLMinutes) * time.Minute
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret, accessTokenTTL)
 	log.Println("✅ JWT manager initialized")
 
 	// ========================================
@@ -79,16 +91,66 @@ func main() {
 	// ========================================
 	userCollection := db.Database.Collection("users")
 	groupCollection := db.Database.Collection("groups")
+	groupReadStateCollection := db.Database.Collection("group_read_states")
+	groupDeliveryStateCollection := db.Database.Collection("group_delivery_states")
+	groupJoinRequestCollection := db.Database.Collection("group_join_requests")
+	groupInviteCollection := db.Database.Collection("group_invites")
+	chatUploadCollection := db.Database.Collection("chat_uploads")
 	messageCollection := db.Database.Collection("messages")
+	messageReportCollection := db.Database.Collection("message_reports")
+	bannedWordCollection := db.Database.Collection("banned_words")
+	conversationCollection := db.Database.Collection("conversations")
+	directMessageCollection := db.Database.Collection("direct_messages")
 	announcementCollection := db.Database.Collection("announcements")
 	eventCollection := db.Database.Collection("events")
 	notificationCollection := db.Database.Collection("notifications")
+	notificationOutboxCollection := db.Database.Collection("notification_outbox")
 	deviceTokenCollection := db.Database.Collection("device_tokens")
 	cityIssueCollection := db.Database.Collection("city_issues")
 	petitionCollection := db.Database.Collection("petitions")
 	pollCollection := db.Database.Collection("polls")
+	pollSummaryCollection := db.Database.Collection("poll_summaries")
+	pollResponseCollection := db.Database.Collection("poll_responses")
+	pollResultCollection := db.Database.Collection("poll_results")
 	transportRouteCollection := db.Database.Collection("transport_routes")
 	transportVehicleCollection := db.Database.Collection("transport_vehicles")
+	communityCollection := db.Database.Collection("communities")
+	emailQueueCollection := db.Database.Collection("email_queue")
+	emailLogCollection := db.Database.Collection("email_delivery_logs")
+	emailSuppressionCollection := db.Database.Collection("email_suppressions")
+	campaignCollection := db.Database.Collection("push_campaigns")
+	campaignRecipientCollection := db.Database.Collection("campaign_recipients")
+	moderationActionCollection := db.Database.Collection("moderation_actions")
+	moderatorNoteCollection := db.Database.Collection("moderator_notes")
+	auditLogCollection := db.Database.Collection("audit_logs")
+	faqCategoryCollection := db.Database.Collection("faq_categories")
+	faqArticleCollection := db.Database.Collection("faq_articles")
+	faqFeedbackCollection := db.Database.Collection("faq_feedback")
+	exportSaltCollection := db.Database.Collection("export_salts")
+	taxonomyCollection := db.Database.Collection("taxonomies")
+	tagCollection := db.Database.Collection("tags")
+	educationInstitutionCollection := db.Database.Collection("education_institutions")
+	enrollmentCollection := db.Database.Collection("enrollments")
+	enrollmentSubscriptionCollection := db.Database.Collection("enrollment_subscriptions")
+	consultationCollection := db.Database.Collection("consultations")
+	consultationCommentCollection := db.Database.Collection("consultation_comments")
+	userActivityCollection := db.Database.Collection("user_activity")
+	userSegmentCollection := db.Database.Collection("user_segments")
+	contentRevisionCollection := db.Database.Collection("content_revisions")
+	refreshTokenCollection := db.Database.Collection("refresh_tokens")
+	sessionCollection := db.Collection("sessions") // З повторами при збоях бази: перевіряється на кожному запиті
+	issueDigestCollection := db.Database.Collection("issue_digest_items")
+	calendarDayCollection := db.Database.Collection("calendar_days")
+	petitionThresholdCollection := db.Database.Collection("petition_thresholds")
+	relatedContentCollection := db.Database.Collection("related_content")
+	transportAlertCollection := db.Database.Collection("transport_alerts")
+	phoneCodeCollection := db.Database.Collection("phone_codes")
+	bannerCollection := db.Database.Collection("banners")
+	storageSnapshotCollection := db.Database.Collection("storage_snapshots")
+	statsHistoryCollection := db.Database.Collection("stats_history")
+	apiKeyCollection := db.Database.Collection("api_keys")
+	socialFeedCollection := db.Database.Collection("social_feeds")
+	socialImportCollection := db.Database.Collection("social_imports")
 
 	// ========================================
 	// 5. ІНІЦІАЛІЗАЦІЯ СЕРВІСІВ
@@ -98,7 +160,355 @@ func main() {
 		cfg,
 		userCollection,
 		notificationCollection,
+		notificationOutboxCollection,
+	)
+
+	// Campaign service - A/B тестування push-кампаній
+	campaignService := services.NewCampaignService(
+		campaignCollection,
+		campaignRecipientCollection,
+		userCollection,
+		notificationService,
+	)
+
+	// Email service - черга листів з failover між SMTP провайдерами
+	emailService := services.NewEmailService(
+		cfg,
+		emailQueueCollection,
+		emailSuppressionCollection,
+		emailLogCollection,
+	)
+
+	// Trust service - оцінка довіри до нових акаунтів (антиспам)
+	trustService := services.NewTrustService(userCollection)
+
+	// Moderation log - журнал рішень модераторів для аналітики навантаження
+	moderationLog := services.NewModerationLogService(moderationActionCollection)
+
+	// Audit log - журнал аудиту дій користувачів і фонових задач (видалення чернеток тощо)
+	auditLogService := services.NewAuditLogService(auditLogCollection)
+
+	// Activity service - активні тижні користувачів для когортної аналітики
+	activityService := services.NewActivityService(userActivityCollection)
+
+	// Presence service - хто онлайн у чаті та last_seen_at користувачів
+	presenceService := services.NewPresenceService(userCollection)
+
+	// Revision service - історія тексту опублікованих петицій та оголошень
+	revisionService := services.NewRevisionService(contentRevisionCollection)
+
+	// Pseudonymizer - псевдонімізація ідентифікаторів в аналітичних вивантаженнях
+	pseudonymizer := services.NewPseudonymizer(exportSaltCollection, cfg.ExportSaltRotationDays)
+
+	// GPS ingestion - позиції транспорту від зовнішніх провайдерів автопарку.
+	// Провайдер з URL опитується, з секретом - приймає webhook.
+	gpsIngestionService := services.NewGPSIngestionService(transportVehicleCollection)
+	gpsProviders := make(map[string]bool)
+	for name := range cfg.GPSProviderURLs {
+		gpsProviders[name] = true
+	}
+	for name := range cfg.GPSWebhookSecrets {
+		gpsProviders[name] = true
+	}
+	for name := range gpsProviders {
+		gpsIngestionService.Register(services.NewGenericGPSProvider(
+			name,
+			cfg.GPSProviderURLs[name],
+			cfg.GPSProviderTokens[name],
+			cfg.GPSWebhookSecrets[name],
+			time.Duration(cfg.GPSPollIntervalSec)*time.Second,
+		))
+	}
+
+	// Social import - пости публічних каналів VK/Facebook/Telegram як чернетки оголошень і подій.
+	// Telegram читається з публічної веб-сторінки каналу, VK і Facebook потребують токена.
+	socialImportService := services.NewSocialImportService(
+		socialFeedCollection,
+		socialImportCollection,
+		announcementCollection,
+		eventCollection,
+		time.Duration(cfg.SocialImportIntervalMin)*time.Minute,
+	)
+	socialImportService.Register(services.NewTelegramFeedProvider())
+	if cfg.VKServiceToken != "" {
+		socialImportService.Register(services.NewVKFeedProvider(cfg.VKServiceToken))
+	}
+	if cfg.FacebookAccessToken != "" {
+		socialImportService.Register(services.NewFacebookFeedProvider(cfg.FacebookAccessToken))
+	}
+
+	// Media service - перевірка посилань на зображення та відео
+	mediaService := services.NewMediaService(cfg.MediaBaseURL, cfg.VideoHosts)
+
+	// Refresh tokens - ротація refresh-токенів для короткоживучих access-токенів
+	refreshTokenService := services.NewRefreshTokenService(refreshTokenCollection, time.Duration(cfg.RefreshTokenTTLDays)*24*time.Hour)
+
+	// Sessions - активні входи користувача (пристрій, IP, остання активність) і їх відкликання
+	sessionService := services.NewSessionService(sessionCollection, time.Duration(cfg.SessionCheckIntervalSec)*time.Second)
+
+	// API keys - ключі X-API-Key для машинного доступу партнерів з дозволами ролі власника
+	apiKeyService := services.NewAPIKeyService(apiKeyCollection)
+
+	// File storage - завантажені файли на диску сервера або в S3/MinIO, видача за підписаними посиланнями
+	var fileStorage services.FileStorage
+	var localFileStorage *services.LocalFileStorage
+	switch cfg.FileStorageDriver {
+	case "s3":
+		fileStorage, err = services.NewS3FileStorage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PathStyle)
+	case "local":
+		localFileStorage, err = services.NewLocalFileStorage(cfg.FileStorageDir, cfg.FileURLSecret, "/media")
+		fileStorage = localFileStorage
+	default:
+		log.Fatalf("Unknown FILE_STORAGE_DRIVER %q (expected local or s3)", cfg.FileStorageDriver)
+	}
+	if err != nil {
+		log.Fatal("Failed to initialize file storage:", err)
+	}
+
+	// Embeds - підписані токени віджетів петицій та опитувань для сайтів громади
+	embedService := services.NewEmbedService(cfg.EmbedTokenSecret, cfg.EmbedAllowedOrigins, time.Duration(cfg.EmbedTokenTTLHours)*time.Hour)
+
+	// Avatars - мініатюри аватарів користувачів у файловому сховищі
+	avatarService := services.NewAvatarService(fileStorage, userCollection, time.Duration(cfg.SignedURLTTLMin)*time.Minute)
+
+	// Chat attachments - файли та зображення повідомлень груп з мініатюрами
+	chatAttachmentService := services.NewChatAttachmentService(
+		fileStorage,
+		chatUploadCollection,
+		int64(cfg.ChatAttachmentMaxMB)<<20,
+		time.Duration(cfg.SignedURLTTLMin)*time.Minute,
+	)
+
+	// Chat delivery - підтвердження доставки й офлайн-черга повідомлень груп для WebSocket
+	chatDeliveryService := services.NewChatDeliveryService(groupDeliveryStateCollection, groupReadStateCollection, messageCollection, chatAttachmentService)
+
+	// Chat filter - заборонені слова в повідомленнях груп (правила задає адміністратор)
+	chatFilterService := services.NewChatFilterService(bannedWordCollection)
+
+	// Data export - архів даних користувача (переносимість даних), готується у фоні
+	dataExportService := services.NewDataExportService(
+		db.Database,
+		fileStorage,
+		notificationService,
+		time.Duration(cfg.DataExportRetentionDays)*24*time.Hour,
+		time.Duration(cfg.SignedURLTTLMin)*time.Minute,
+	)
+
+	// Backups - резервні копії критичних колекцій у S3 за розкладом і вручну, з ротацією
+	backupStorage, err := services.NewBackupStorage(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize backup storage:", err)
+	}
+	backupService := services.NewBackupService(db.Database, backupStorage, services.BackupConfig{
+		Enabled:     cfg.BackupEnabled,
+		Hour:        cfg.BackupHour,
+		Retention:   cfg.BackupRetention,
+		Collections: cfg.BackupCollections,
+		MaxAge:      time.Duration(cfg.BackupMaxAgeHours) * time.Hour,
+	})
+
+	// Account erasure - видалення акаунта на вимогу користувача зі знеособленням даних у фоні
+	accountErasureService := services.NewAccountErasureService(db.Database, avatarService, chatAttachmentService, dataExportService, apiKeyService)
+
+	// Phone verification - підтвердження номера телефону одноразовим кодом з SMS
+	var smsProvider services.SMSProvider = services.LogSMSProvider{}
+	if cfg.SMSGatewayURL != "" {
+		smsProvider = services.NewHTTPSMSProvider(cfg.SMSGatewayURL, cfg.SMSGatewayToken, cfg.SMSSender)
+	} else {
+		log.Println("⚠️  Warning: SMS_GATEWAY_URL is not set, phone verification codes are written to the log")
+	}
+	phoneVerificationService := services.NewPhoneVerificationService(phoneCodeCollection, userCollection, smsProvider, services.PhoneVerificationConfig{
+		CodeTTL:        time.Duration(cfg.PhoneCodeTTLMin) * time.Minute,
+		MaxAttempts:    cfg.PhoneCodeMaxAttempts,
+		CodesPerHour:   cfg.PhoneCodesPerHour,
+		ResendInterval: time.Duration(cfg.PhoneCodeResendSec) * time.Second,
+	})
+
+	// Resident registry - перевірка карток жителя (без реєстру картки перевіряє адміністратор)
+	var residentRegistry services.ResidentRegistry = services.ManualResidentRegistry{}
+	if cfg.ResidentRegistryURL != "" {
+		residentRegistry = services.NewHTTPResidentRegistry(cfg.ResidentRegistryURL, cfg.ResidentRegistryToken)
+	} else {
+		log.Println("⚠️  Warning: RESIDENT_REGISTRY_URL is not set, resident cards are reviewed by administrators")
+	}
+
+	// SSO - вхід працівників міськради через Azure AD / Google Workspace з доменами та правилами ролей
+	ssoService := services.NewSSOService(
+		[]services.SSOProviderConfig{
+			services.AzureSSOProvider(cfg.SSOAzureTenantID, cfg.SSOAzureClientID, cfg.SSOAzureClientSecret),
+			services.GoogleSSOProvider(cfg.SSOGoogleClientID, cfg.SSOGoogleClientSecret),
+		},
+		cfg.SSOAllowedDomains,
+		cfg.SSORoleMapping,
+		cfg.SSOCallbackBaseURL,
+		cfg.SSOAppRedirectURLs,
+		cfg.SSOStateSecret,
 	)
+	if ssoService.Enabled() && len(cfg.SSOAllowedDomains) == 0 {
+		log.Println("⚠️  Warning: SSO_ALLOWED_DOMAINS is not set, SSO sign-in is rejected for all accounts")
+	}
+
+	// Issue digest - щоденний дайджест оновлень проблем для підписників у режимі digest
+	issueDigestService := services.NewIssueDigestService(issueDigestCollection, notificationService, cfg.IssueDigestHour)
+
+	// Calendar - державні свята та особливі дні громади (святковий розклад транспорту, неробочі дні установ)
+	calendarService := services.NewCalendarService(calendarDayCollection, cfg.CalendarHolidaysDayOff)
+
+	// Petition thresholds - пороги підписів за категоріями, застосовуються при публікації петиції
+	petitionThresholdService := services.NewPetitionThresholdService(petitionThresholdCollection)
+
+	// Transport incidents - критичні проблеми біля маршрутів: позначки маршрутів і чернетки оголошень про об'їзд
+	transportIncidentService := services.NewTransportIncidentService(
+		transportRouteCollection,
+		transportAlertCollection,
+		cityIssueCollection,
+		userCollection,
+		notificationService,
+		cfg.TransportIncidentRadius,
+	)
+
+	// Timetable - печатні розклади маршрутів (PDF) для зупинок
+	timetableService := services.NewTimetableService(cfg.TimetableFontPath)
+
+	// Storage - розміри колекцій, щоденні знімки для розрахунку приросту та ліміт тарифу Atlas
+	storageService := services.NewStorageService(db.Database, storageSnapshotCollection, services.StorageQuotaConfig{
+		LimitBytes:  int64(cfg.AtlasStorageLimitMB) << 20,
+		WarnPercent: cfg.StorageWarnPercent,
+	})
+
+	// Stats history - щоденні знімки ключових показників платформи для порівняння рік до року
+	statsHistoryService := services.NewStatsHistoryService(statsHistoryCollection, userCollection, cityIssueCollection, petitionCollection)
+
+	// User status cache - перевірка блокування та актуальної ролі на кожному автентифікованому запиті
+	userStatusCache := services.NewUserStatusCache(db.Collection("users"), time.Duration(cfg.UserStatusCacheTTLSec)*time.Second)
+
+	// Suspensions - автоматичне зняття тимчасових блокувань (PUT /users/:id/suspend)
+	suspensionService := services.NewSuspensionService(userCollection, userStatusCache)
+
+	// Connection guard - ліміти WebSocket-підключень з одного IP та бан за перепідключення в циклі
+	connectionGuard := services.NewConnectionGuard(services.ConnectionGuardConfig{
+		MaxConnectionsPerIP: cfg.WSMaxConnectionsPerIP,
+		HandshakesPerMinute: cfg.WSHandshakesPerMinute,
+		ChurnBanThreshold:   cfg.WSChurnBanThreshold,
+		BanDuration:         time.Duration(cfg.WSBanDurationSec) * time.Second,
+	})
+
+	// Chat limiter - ліміт повідомлень та slow mode груп
+	chatLimiter := services.NewChatLimiter(
+		cfg.ChatRateLimit,
+		time.Duration(cfg.ChatRateWindow)*time.Second,
+	)
+
+	// Module registry - увімкнені/вимкнені модулі деплойменту
+	moduleRegistry := modules.NewRegistry(cfg.DisabledModules)
+
+	// Community service - громади (multi-tenancy)
+	communityService := services.NewCommunityService(cfg, communityCollection)
+	if err := communityService.EnsureDefault(ctx); err != nil {
+		log.Printf("⚠️  Warning: Failed to initialize default community: %v", err)
+	}
+
+	// Taxonomy service - категорії модулів, якими керують адміністратори
+	taxonomyService := services.NewTaxonomyService(taxonomyCollection)
+	if err := taxonomyService.EnsureDefaults(ctx); err != nil {
+		log.Printf("⚠️  Warning: Failed to initialize default taxonomies: %v", err)
+	}
+	// Довільні інтереси, збережені до появи довідника, переводяться в коди таксономії
+	if updated, err := taxonomyService.NormalizeStoredInterests(ctx, userCollection); err != nil {
+		log.Printf("⚠️  Warning: Failed to normalize user interests: %v", err)
+	} else if updated > 0 {
+		log.Printf("✅ Normalized interests of %d users", updated)
+	}
+
+	// Slug для адрес публічного сайта: документи, створені раніше, отримують його при запуску
+	for collection, fallback := range map[*mongo.Collection]string{
+		petitionCollection:     "petition",
+		eventCollection:        "event",
+		announcementCollection: "announcement",
+	} {
+		if updated, err := services.BackfillSlugs(ctx, collection, fallback); err != nil {
+			log.Printf("⚠️  Warning: Failed to backfill %s slugs: %v", fallback, err)
+		} else if updated > 0 {
+			log.Printf("✅ Generated slugs for %d %s documents", updated, fallback)
+		}
+	}
+
+	// Мова контенту для фільтра ?lang=: документи, створені раніше, отримують її при запуску
+	pollLanguages := 0
+	for name, collection := range map[string]*mongo.Collection{
+		"announcement": announcementCollection,
+		"event":        eventCollection,
+		"petition":     petitionCollection,
+		"poll":         pollCollection,
+		"city issue":   cityIssueCollection,
+	} {
+		updated, err := services.BackfillLanguages(ctx, collection)
+		if err != nil {
+			log.Printf("⚠️  Warning: Failed to detect %s languages: %v", name, err)
+		} else if updated > 0 {
+			log.Printf("✅ Detected language of %d %s documents", updated, name)
+		}
+		if collection == pollCollection {
+			pollLanguages = updated
+		}
+	}
+
+	// Poll responses - відповіді опитувань в окремій колекції; відповіді, що зберігалися в документі опитування, переносяться при запуску
+	// Poll results - кеш підсумків опитувань: оновлюється з кожною відповіддю, за відсутності збирається агрегацією
+	pollResultService := services.NewPollResultService(pollResponseCollection, pollResultCollection)
+	pollResponseService := services.NewPollResponseService(pollCollection, pollResponseCollection, pollResultService)
+	movedResponses, err := pollResponseService.MigrateEmbedded(ctx)
+	if err != nil {
+		log.Printf("⚠️  Warning: Failed to move poll responses: %v", err)
+	} else if movedResponses > 0 {
+		log.Printf("✅ Moved %d poll responses to poll_responses", movedResponses)
+	}
+
+	// Poll summaries - картки опитувань для списків, перебудовуються, якщо розійшлися з опитуваннями
+	pollSummaryService := services.NewPollSummaryService(pollCollection, pollSummaryCollection)
+	ensurePollSummaries := pollSummaryService.EnsureBackfilled
+	if pollLanguages > 0 || movedResponses > 0 {
+		// Визначена при запуску мова та перераховані лічильники відповідей мають потрапити в картки
+		ensurePollSummaries = pollSummaryService.Rebuild
+	}
+	if rebuilt, err := ensurePollSummaries(ctx); err != nil {
+		log.Printf("⚠️  Warning: Failed to backfill poll summaries: %v", err)
+	} else if rebuilt > 0 {
+		log.Printf("✅ Rebuilt %d poll summaries", rebuilt)
+	}
+
+	// Draft cleanup - чернетки опитувань і петицій без змін видаляються після попередження автора
+	draftCleanupService := services.NewDraftCleanupService(
+		pollCollection,
+		petitionCollection,
+		notificationService,
+		auditLogService,
+		pollSummaryService,
+		time.Duration(cfg.DraftTTLDays)*24*time.Hour,
+		time.Duration(cfg.DraftWarningDays)*24*time.Hour,
+	)
+
+	// Related content - фонова підбірка пов'язаних проблем, петицій і оголошень для карток ("люди також повідомляли")
+	relatedContentService := services.NewRelatedContentService(
+		relatedContentCollection,
+		cityIssueCollection,
+		petitionCollection,
+		announcementCollection,
+		moduleRegistry.IsEnabled,
+	)
+
+	// Counters - нічна звірка лічильників (підписи, учасники, голоси) з джерелами та виправлення розбіжностей
+	counterService := services.NewCounterService(db.Database, pollSummaryService, cfg.CounterReconcileHour, pollResponseService, pollResultService)
+
+	// Sandbox - демо-дані для сторонніх розробників, скидаються через POST /sandbox/reset
+	sandboxService := services.NewSandboxService(db.Database, communityService, taxonomyService, pollSummaryService)
+
+	// Tag service - канонічні теги петицій, опитувань і подій
+	tagService := services.NewTagService(tagCollection, map[string]*mongo.Collection{
+		models.ModulePetitions: petitionCollection,
+		models.ModulePolls:     pollCollection,
+		models.ModuleEvents:    eventCollection,
+	})
 	log.Println("✅ Services initialized")
 
 	// ========================================
@@ -107,42 +517,238 @@ func main() {
 	log.Println("🎯 Initializing handlers...")
 
 	// Auth handler - авторизація та реєстрація
-	authHandler := handlers.NewAuthHandler(userCollection, jwtManager)
+	authHandler := handlers.NewAuthHandler(userCollection, jwtManager, emailService, refreshTokenService, sessionService, accountErasureService, phoneVerificationService, ssoService, userStatusCache)
+
+	// Community handler - громади (multi-tenancy)
+	communityHandler := handlers.NewCommunityHandler(
+		communityCollection,
+		userCollection,
+		communityService,
+		moduleRegistry,
+	)
+
+	// Email handler - черга листів та журнали доставки (ADMIN)
+	emailHandler := handlers.NewEmailHandler(
+		emailQueueCollection,
+		emailLogCollection,
+		emailSuppressionCollection,
+		emailService,
+	)
+
+	// Notification outbox handler - наміри сповіщень, що не вдалося доставити (ADMIN)
+	notificationOutboxHandler := handlers.NewNotificationOutboxHandler(notificationOutboxCollection, notificationService)
 
 	// Users handler - управління користувачами (ADMIN)
-	usersHandler := handlers.NewUsersHandler(userCollection)
+	usersHandler := handlers.NewUsersHandler(userCollection, userStatusCache, refreshTokenService, sessionService, cfg.IdentityHashKey)
+
+	// WebSocket handler - real-time чат
+	wsHandler := handlers.NewWebSocketHandler(
+		jwtManager,
+		groupCollection,
+		messageCollection,
+		chatLimiter,
+		trustService,
+		connectionGuard,
+		userStatusCache,
+		sessionService,
+		presenceService,
+		chatDeliveryService,
+		chatFilterService,
+	)
+	notificationService.OnNotificationStored(wsHandler.NotifyUser)
+	usersHandler.OnSessionsRevoked(wsHandler.DisconnectUser)
+	accountErasureService.OnAccountDeleted(userStatusCache.Invalidate)
+	accountErasureService.OnAccountDeleted(wsHandler.DisconnectUser)
+
+	// Admin realtime handler - лічильники адмін-панелі по WebSocket
+	adminRealtimeHandler := handlers.NewAdminRealtimeHandler(
+		jwtManager,
+		wsHandler,
+		cityIssueCollection,
+		notificationCollection,
+		emailQueueCollection,
+		transportVehicleCollection,
+		notificationService,
+		emailService,
+	)
+
+	// Long-poll handler - fallback для клієнтів без WebSocket
+	longPollHandler := handlers.NewLongPollHandler(
+		wsHandler,
+		groupCollection,
+		messageCollection,
+		notificationCollection,
+		chatAttachmentService,
+	)
 
 	// Group handler - групи та чати
 	groupHandler := handlers.NewGroupHandler(
 		groupCollection,
 		userCollection,
 		messageCollection,
+		groupReadStateCollection,
+		groupJoinRequestCollection,
+		groupInviteCollection,
+		wsHandler,
+		chatLimiter,
+		trustService,
+		chatAttachmentService,
+		cfg.PublicURL,
+		presenceService,
+		chatFilterService,
 	)
 
-	// WebSocket handler - real-time чат
-	wsHandler := handlers.NewWebSocketHandler(
-		jwtManager,
+	// Conversation handler - особисті повідомлення 1-на-1
+	conversationHandler := handlers.NewConversationHandler(
+		conversationCollection,
+		directMessageCollection,
+		userCollection,
+		wsHandler,
+		chatLimiter,
+		trustService,
+		notificationService,
+	)
+
+	// Trust handler - рівні довіри та утримані повідомлення (MODERATOR)
+	trustHandler := handlers.NewTrustHandler(
+		messageCollection,
+		trustService,
+		wsHandler,
+		moderationLog,
+		chatAttachmentService,
+	)
+
+	// Moderator note handler - внутрішні обговорення модераторів до контенту та користувачів (MODERATOR)
+	moderatorNoteHandler := handlers.NewModeratorNoteHandler(db.Database, moderatorNoteCollection, userCollection, notificationService)
+
+	// Chat moderation handler - скарги на повідомлення, черга перевірки та фільтр слів
+	chatModerationHandler := handlers.NewChatModerationHandler(
 		groupCollection,
 		messageCollection,
+		messageReportCollection,
+		chatFilterService,
+		trustService,
+		wsHandler,
+		moderationLog,
+		chatAttachmentService,
 	)
 
 	// Announcement handler - оголошення
 	announcementHandler := handlers.NewAnnouncementHandler(
 		announcementCollection,
 		userCollection,
+		trustService,
+		moderationLog,
+		taxonomyService,
+		revisionService,
+		relatedContentService,
 	)
 
 	// Event handler - події міста
 	eventHandler := handlers.NewEventHandler(
 		eventCollection,
 		userCollection,
+		trustService,
+		moderationLog,
+		tagService,
+		taxonomyService,
+		notificationService,
+	)
+
+	// Moderation analytics handler - навантаження на модераторів (ADMIN)
+	moderationAnalyticsHandler := handlers.NewModerationAnalyticsHandler(
+		moderationActionCollection,
+		userCollection,
+		announcementCollection,
+		eventCollection,
+		messageCollection,
 	)
 
+	// Cohort handler - когорти утримання та сегменти користувачів (ADMIN)
+	cohortHandler := handlers.NewCohortHandler(
+		userCollection,
+		userActivityCollection,
+		userSegmentCollection,
+	)
+
+	// Export handler - CSV-вивантаження для дослідників (ADMIN)
+	exportHandler := handlers.NewExportHandler(
+		userCollection,
+		cityIssueCollection,
+		petitionCollection,
+		pollCollection,
+		moderationActionCollection,
+		pseudonymizer,
+		pollResponseCollection,
+	)
+
+	// Taxonomy handler - довідник категорій
+	taxonomyHandler := handlers.NewTaxonomyHandler(taxonomyCollection, taxonomyService)
+
+	// Calendar handler - календар свят і особливих днів
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+
+	// Tag handler - теги міста
+	tagHandler := handlers.NewTagHandler(tagService)
+
+	// Interest handler - вибір інтересів користувача для рекомендацій
+	interestHandler := handlers.NewInterestHandler(userCollection, taxonomyService)
+
+	// Profile handler - публічні профілі та налаштування приватності
+	profileHandler := handlers.NewProfileHandler(userCollection, presenceService)
+
 	// Notification handler - сповіщення
 	notificationHandler := handlers.NewNotificationHandler(
 		notificationService,
 		notificationCollection,
 		deviceTokenCollection,
+		campaignService,
+	)
+
+	// FAQ handler - база знань міста (редагують модератори)
+	faqHandler := handlers.NewFAQHandler(
+		faqCategoryCollection,
+		faqArticleCollection,
+		faqFeedbackCollection,
+	)
+
+	// Banner handler - банери застосунків (технічні роботи, кампанії, онбординг)
+	bannerHandler := handlers.NewBannerHandler(bannerCollection)
+
+	// Avatar handler - завантаження аватарів і файли локального сховища
+	avatarHandler := handlers.NewAvatarHandler(userCollection, avatarService, localFileStorage, int64(cfg.AvatarMaxMB)<<20)
+
+	// Data export handler - вивантаження власних даних користувача
+	dataExportHandler := handlers.NewDataExportHandler(dataExportService)
+
+	// API key handler - випуск і відкликання ключів інтеграцій
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	// Embed handler - віджети петицій та опитувань для iframe
+	embedHandler := handlers.NewEmbedHandler(petitionCollection, pollCollection, embedService, cfg.AppBaseURL)
+
+	// Storage handler - використання сховища по модулях і повтори запитів до бази (ADMIN)
+	storageHandler := handlers.NewStorageHandler(storageService, db.Retrier)
+
+	// Stats history handler - публічна історія показників платформи
+	statsHistoryHandler := handlers.NewStatsHistoryHandler(statsHistoryCollection)
+
+	// Backup handler - ручний запуск та історія резервних копій (ADMIN)
+	backupHandler := handlers.NewBackupHandler(backupService)
+
+	// Counter handler - ручна звірка лічильників та її історія (ADMIN)
+	counterHandler := handlers.NewCounterHandler(counterService)
+
+	// Sandbox handler - скидання демо-даних пісочниці (лише при SANDBOX_MODE)
+	sandboxHandler := handlers.NewSandboxHandler(sandboxService)
+
+	// Health handler - готовність сервера: MongoDB і стан резервних копій
+	healthHandler := handlers.NewHealthHandler(db.Client, backupService, notificationService, emailService)
+
+	// Campaign handler - push-кампанії з A/B тестуванням (ADMIN)
+	campaignHandler := handlers.NewCampaignHandler(
+		campaignCollection,
+		campaignService,
 	)
 
 	// City Issue handler - проблеми міста
@@ -150,6 +756,10 @@ func main() {
 		cityIssueCollection,
 		userCollection,
 		notificationService,
+		taxonomyService,
+		issueDigestService,
+		transportIncidentService,
+		relatedContentService,
 	)
 
 	// Petition handler - петиції
@@ -157,12 +767,41 @@ func main() {
 		petitionCollection,
 		userCollection,
 		notificationService,
+		taxonomyService,
+		tagService,
+		revisionService,
+		petitionThresholdService,
+		relatedContentService,
 	)
 
+	// Petition threshold handler - пороги підписів петицій
+	petitionThresholdHandler := handlers.NewPetitionThresholdHandler(petitionThresholdService, taxonomyService)
+
 	// ✅ Poll handler - опитування (ВИПРАВЛЕНО)
 	pollHandler := handlers.NewPollHandler(
 		db.Database, // Передаємо весь database для доступу до колекції
 		notificationService,
+		mediaService,
+		taxonomyService,
+		tagService,
+		pollSummaryService,
+		pollResponseService,
+		pollResultService,
+		trustService,
+		moderationLog,
+		models.Geofence{
+			Latitude:  cfg.MunicipalityLatitude,
+			Longitude: cfg.MunicipalityLongitude,
+			RadiusKm:  cfg.MunicipalityRadiusKm,
+		},
+	)
+
+	// Feed handler - Atom-стрічки проблем міста та петицій
+	feedHandler := handlers.NewFeedHandler(
+		cfg,
+		cityIssueCollection,
+		petitionCollection,
+		communityService,
 	)
 
 	// Transport handler - громадський транспорт
@@ -170,8 +809,55 @@ func main() {
 		transportRouteCollection,
 		transportVehicleCollection,
 		userCollection,
+		calendarService,
+		timetableService,
+		transportAlertCollection,
+	)
+
+	// Transport alert handler - оголошення про об'їзди та зміну руху
+	transportAlertHandler := handlers.NewTransportAlertHandler(transportAlertCollection, transportRouteCollection)
+
+	// GPS handler - webhook провайдерів і джерело позицій транспорту
+	gpsHandler := handlers.NewGPSHandler(transportVehicleCollection, gpsIngestionService)
+
+	// Resident card handler - картка жителя, що відкриває функції лише для жителів
+	residentCardHandler := handlers.NewResidentCardHandler(userCollection, residentRegistry, notificationService, cfg.ResidentCardHashKey, cfg.ResidentCardValidityDays)
+
+	// Concession handler - пільги на проїзд з перевіркою документів
+	concessionHandler := handlers.NewConcessionHandler(
+		userCollection,
+		notificationService,
 	)
 
+	// Enrollment handler - набір до шкіл і садків (публікує управління освіти)
+	enrollmentHandler := handlers.NewEnrollmentHandler(
+		educationInstitutionCollection,
+		enrollmentCollection,
+		enrollmentSubscriptionCollection,
+		notificationService,
+	)
+
+	// Consultation handler - громадські обговорення проєктів документів
+	consultationHandler := handlers.NewConsultationHandler(
+		consultationCollection,
+		consultationCommentCollection,
+		userCollection,
+	)
+
+	// Social import handler - підключення каналів соцмереж модераторами
+	socialImportHandler := handlers.NewSocialImportHandler(
+		socialFeedCollection,
+		socialImportCollection,
+		socialImportService,
+		taxonomyService,
+	)
+
+	// Draft handler - продовження зберігання чернеток з попередження про видалення
+	draftHandler := handlers.NewDraftHandler(draftCleanupService)
+
+	// Audit log handler - перегляд журналу аудиту
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogCollection)
+
 	log.Println("✅ All handlers initialized")
 
 	// ========================================
@@ -182,9 +868,106 @@ func main() {
 	// WebSocket hub для управління з'єднаннями
 	go wsHandler.StartHub()
 
+	// Лічильники адмін-панелі (рахуються лише за наявності підключень)
+	go adminRealtimeHandler.Start()
+
+	// Очищення лічильників ліміту чату
+	go chatLimiter.StartCleanup()
+
+	// Очищення лічильників WebSocket-підключень
+	go connectionGuard.StartCleanup()
+
+	// Очищення кешу статусів користувачів
+	go userStatusCache.StartCleanup()
+	go sessionService.StartCleanup()
+
+	// Зняття тимчасових блокувань після закінчення строку
+	go suspensionService.StartWorker()
+
+	// Знеособлення даних видалених акаунтів
+	go accountErasureService.StartWorker()
+
+	// Підготовка архівів даних користувачів і видалення прострочених
+	go dataExportService.StartWorker()
+
+	// Видалення завантажених файлів чату, які не прикріпили до повідомлення
+	go chatAttachmentService.StartCleanup()
+
+	// Щоденні резервні копії критичних колекцій
+	if cfg.BackupEnabled {
+		go backupService.StartScheduler()
+		log.Printf("✅ Backup scheduler started (daily at %02d:00, keeping %d backups)", cfg.BackupHour, cfg.BackupRetention)
+	} else {
+		log.Println("⚠️  Warning: BACKUP_ENABLED is not set, scheduled backups are disabled")
+	}
+
+	// Щоденна звірка денормалізованих лічильників
+	go counterService.StartScheduler()
+	log.Printf("✅ Counter reconciliation scheduler started (daily at %02d:00)", cfg.CounterReconcileHour)
+
+	// Фонова відправка листів з черги
+	go emailService.StartWorker()
+	log.Println("✅ Email queue worker started")
+
+	// Повторна відправка push, відкладених під час недоступності FCM
+	if cfg.FirebaseKey != "" {
+		go notificationService.StartPushRetryWorker()
+		log.Println("✅ Push retry worker started")
+	}
+
+	// Доставка сповіщень з outbox: намірів, записаних разом зі зміною даних
+	go notificationService.StartOutboxWorker()
+	log.Println("✅ Notification outbox worker started")
+
 	// ✅ Cleanup старих опитувань (90+ днів)
-	go handlers.StartPollCleanupTask(pollCollection)
-	log.Println("✅ Poll cleanup task started")
+	if moduleRegistry.IsEnabled(models.ModulePolls) {
+		go handlers.StartPollCleanupTask(pollCollection, pollSummaryService, auditLogService, pollResponseService)
+		log.Println("✅ Poll cleanup task started")
+
+		// Повідомлення цільовим групам про відкриття запланованих опитувань
+		go handlers.StartPollOpeningTask(pollCollection, notificationService)
+		log.Println("✅ Poll opening task started")
+	}
+
+	// Попередження авторів і видалення покинутих чернеток
+	go draftCleanupService.StartWorker()
+	log.Printf("✅ Draft cleanup worker started (drafts kept %d days, authors warned %d days before)", cfg.DraftTTLDays, cfg.DraftWarningDays)
+
+	// Перерахунок пов'язаних матеріалів для карток проблем, петицій і оголошень
+	go relatedContentService.StartWorker()
+	log.Println("✅ Related content worker started")
+
+	// Підбиття підсумків A/B тестів та розсилка переможця
+	if moduleRegistry.IsEnabled(models.ModuleNotifications) {
+		go campaignService.StartEvaluator()
+		log.Println("✅ Campaign evaluator started")
+	}
+
+	// Щоденні знімки використання сховища
+	go storageService.StartSnapshots()
+	log.Println("✅ Storage snapshots started")
+
+	// Щоденні знімки статистики платформи
+	go statsHistoryService.StartSnapshots()
+	log.Println("✅ Stats snapshots started")
+
+	// Щоденний дайджест оновлень проблем міста
+	if moduleRegistry.IsEnabled(models.ModuleCityIssues) {
+		go issueDigestService.StartWorker()
+		log.Println("✅ Issue digest worker started")
+	}
+
+	// Опитування зовнішніх GPS-провайдерів
+	if moduleRegistry.IsEnabled(models.ModuleTransport) {
+		gpsIngestionService.StartPolling()
+		log.Println("✅ GPS provider polling started")
+	}
+
+	// Імпорт нових постів підключених каналів соцмереж (у пісочниці зовнішні канали не опитуються)
+	if !cfg.SandboxMode {
+		go socialImportService.StartWorker()
+		log.Printf("✅ Social import worker started (platforms: %v)", socialImportService.Platforms())
+	}
 
 	// Генерація розкладу транспорту (якщо є відповідний метод)
 	// go transportHandler.StartScheduleGenerator()
@@ -204,6 +987,11 @@ func main() {
 	}
 
 	router := gin.New()
+	// Без списку gin довіряє X-Forwarded-For від будь-якого клієнта, і ClientIP() можна підробити:
+	// за ним працюють ліміти й тимчасові бани за IP
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// ========================================
 	// 9. MIDDLEWARE
@@ -211,6 +999,8 @@ func main() {
 	// Базові middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	// Вимоги доступу маршруту з матриці: за ними обмежуються запити з X-API-Key
+	router.Use(routeAccessMatrix.RouteRules())
 
 	// ========================================
 	// 10. CORS CONFIGURATION
@@ -237,10 +1027,17 @@ func main() {
 			"Accept",
 			"Authorization",
 			"X-Requested-With",
+			"X-Community",
+			middleware.PaginationFormatHeader,
+			middleware.APIVersionHeader,
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			middleware.APIVersionHeader,
+			"Deprecation",
+			"Sunset",
+			"Link",
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -248,6 +1045,24 @@ func main() {
 	router.Use(cors.New(corsConfig))
 	log.Println("✅ CORS configured")
 
+	// Стиснення відповідей (списки маршрутів з геометрією, результати опитувань).
+	// WebSocket, long-poll та потокові CSV-вивантаження віддаються без стиснення.
+	router.Use(middleware.CompressionMiddleware(middleware.CompressionConfig{
+		MinSize:          cfg.CompressionMinSize,
+		Level:            cfg.CompressionLevel,
+		ExcludedPrefixes: []string{"/ws", "/api/v1/analytics/exports/"},
+		ExcludedSuffixes: []string{"/poll"},
+	}))
+
+	// Списки відповідають {items, page_info}; попередні ключі - поки клієнти не перейдуть на новий формат
+	router.Use(middleware.PaginationFormat(cfg.PaginationLegacyKeys))
+
+	// Узгодження версії API (X-API-Version, Accept) та заголовки Deprecation/Sunset застарілих маршрутів
+	router.Use(middleware.APIVersioning(&apiVersions))
+	if expired := apiVersions.Expired(time.Now()); len(expired) > 0 {
+		log.Printf("⚠️  Warning: deprecated routes are past their sunset date and should be removed: %s", strings.Join(expired, ", "))
+	}
+
 	// ========================================
 	// 11. API ROUTES
 	// ========================================
@@ -256,115 +1071,688 @@ func main() {
 	// API v1 base group
 	api := router.Group("/api/v1")
 
+	// Batch handler - кілька запитів за один round trip; виконує підзапити через router
+	batchHandler := handlers.NewBatchHandler(router, cfg.BatchMaxRequests)
+
+	// Визначаємо громаду (tenant) для кожного запиту за хостом або X-Community
+	api.Use(middleware.CommunityMiddleware(communityService))
+
+	// Модулі, вимкнені в налаштуваннях громади, відповідають 404 MODULE_DISABLED
+	api.Use(moduleRegistry.Middleware())
+
+	// Групи маршрутів за рівнем доступу (див. newRouteGroups)
+	groups := newRouteGroups(api, middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService))
+	protected, moderator, cityModerator, admin := groups.protected, groups.moderator, groups.cityModerator, groups.admin
+	protected.Use(middleware.ActivityMiddleware(activityService))
+
 	// ========================================
-	// 🔓 ПУБЛІЧНІ МАРШРУТИ (без автентифікації)
+	// 🧩 БАЗОВІ МАРШРУТИ (завжди увімкнені)
 	// ========================================
 	{
 		// ===== АВТОРИЗАЦІЯ =====
 		api.POST("/auth/register", authHandler.Register)
 		api.POST("/auth/login", authHandler.Login)
+		api.POST("/auth/refresh", authHandler.Refresh)
+		api.POST("/auth/logout", authHandler.Logout)
+		api.GET("/auth/account/deletions/:id", authHandler.GetAccountDeletion)
+		// SSO працівників міськради
+		api.GET("/auth/sso/providers", authHandler.GetSSOProviders)
+		api.GET("/auth/sso/:provider/start", authHandler.StartSSO)
+		api.GET("/auth/sso/:provider/callback", authHandler.SSOCallback)
+
+		// ===== ГРОМАДИ ТА БРЕНДИНГ =====
+		api.GET("/communities", communityHandler.GetCommunities)
+		api.GET("/public/settings", communityHandler.GetPublicSettings)
+		// Ролі впливають на вибірку, гість отримує загальні банери
+		api.GET("/public/banners",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			bannerHandler.GetPublicBanners)
+		api.GET("/public/stats/history", statsHistoryHandler.GetStatsHistory)
+
+		// ===== ВІДЖЕТИ ДЛЯ САЙТІВ ГРОМАДИ (iframe) =====
+		embedLimiter := middleware.NewIPRateLimiter(cfg.EmbedRateLimitPerMinute, time.Minute)
+		api.GET("/embed/:token", embedLimiter.Middleware(), embedHandler.GetEmbedFrame)
+		api.GET("/embed/:token/data", embedLimiter.Middleware(), embedHandler.GetEmbedData)
+
+		// ===== КАТЕГОРІЇ (ТАКСОНОМІЇ) =====
+		api.GET("/taxonomies", taxonomyHandler.GetTaxonomies)
+
+		// ===== КАЛЕНДАР (свята та особливі дні громади) =====
+		api.GET("/calendar", calendarHandler.GetCalendar)
+
+		// ===== BATCH (холодний старт мобільного застосунку) =====
+		// Підзапити проходять через роутер з власними middleware, тому окремої автентифікації тут немає
+		api.POST("/batch", batchHandler.ExecuteBatch)
+
+		// ===== ТЕГИ =====
+		api.GET("/tags/autocomplete", tagHandler.Autocomplete)
+		api.GET("/tags/trending", tagHandler.GetTrending)
+		cityModerator.POST("/moderation/tags/merge", tagHandler.MergeTags)
+		cityModerator.PUT("/moderation/tags/:name", tagHandler.RenameTag)
 
-		// ===== ПУБЛІЧНА ІНФОРМАЦІЯ =====
-		// Групи
-		api.GET("/groups/public", groupHandler.GetPublicGroups)
-		api.GET("/search/groups", groupHandler.SearchGroups)
-
-		// Оголошення
-		api.GET("/announcements", announcementHandler.GetAnnouncements)
-		api.GET("/announcements/:id", announcementHandler.GetAnnouncement)
+		// ===== ПРОФІЛЬ КОРИСТУВАЧА =====
+		protected.GET("/auth/profile", authHandler.GetProfile)
+		protected.PUT("/auth/profile", authHandler.UpdateProfile)
+		protected.PUT("/auth/password", authHandler.ChangePassword)
+		protected.GET("/auth/profile/interests", interestHandler.GetMyInterests)
+		protected.PUT("/auth/profile/interests", interestHandler.UpdateMyInterests)
+		protected.PUT("/auth/profile/privacy", profileHandler.UpdateMyPrivacy)
+		// Активні входи на пристроях та їх завершення
+		protected.GET("/auth/sessions", authHandler.GetSessions)
+		protected.DELETE("/auth/sessions/:id", authHandler.RevokeSession)
+		protected.DELETE("/auth/account", authHandler.DeleteAccount)
+		// Архів власних даних: перший запит ставить підготовку в чергу, готовий архів - за посиланням
+		protected.GET("/auth/export", dataExportHandler.GetExport)
+		// Ключі інтеграцій: створюються лише після входу, самим ключем керувати ключами не можна
+		protected.GET("/auth/api-keys", apiKeyHandler.GetAPIKeys)
+		protected.POST("/auth/api-keys", apiKeyHandler.CreateAPIKey)
+		protected.DELETE("/auth/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+		protected.POST("/auth/phone/request-code", authHandler.RequestPhoneCode)
+		protected.POST("/auth/phone/verify", authHandler.VerifyPhone)
+		protected.POST("/users/me/avatar", avatarHandler.UploadAvatar)
+		protected.DELETE("/users/me/avatar", avatarHandler.DeleteAvatar)
+
+		// Картка жителя (голосування за громадський бюджет, пільговий проїзд)
+		residentCardLimiter := middleware.NewGeneralRateLimiter(5, time.Hour)
+		protected.GET("/users/me/resident-card", residentCardHandler.GetMyResidentCard)
+		protected.POST("/users/me/resident-card",
+			residentCardLimiter.Middleware(),
+			residentCardHandler.LinkResidentCard)
+		protected.DELETE("/users/me/resident-card", residentCardHandler.UnlinkResidentCard)
+		// Постійне посилання на аватар, перенаправляє на підписане посилання файлу
+		api.GET("/users/:id/avatar", avatarHandler.GetAvatar)
+		// Публічний профіль: ім'я, аватар і поля, відкриті користувачем
+		api.GET("/users/:id/public", profileHandler.GetPublicProfile)
+
+		// ===== ГРОМАДИ =====
+		protected.POST("/communities/:code/join", communityHandler.JoinCommunity)
+		protected.POST("/communities/:code/leave", communityHandler.LeaveCommunity)
 
-		// Події
-		api.GET("/events", eventHandler.GetEvents)
-		api.GET("/events/:id", eventHandler.GetEvent)
-		api.GET("/events/nearby", eventHandler.GetNearbyEvents)
-		api.GET("/search/events", eventHandler.SearchEvents)
+		// ===== ПОШУК =====
+		protected.GET("/search/users", usersHandler.SearchUsers)
 
-		// Петиції
-		api.GET("/petitions", petitionHandler.GetPetitions)
-		api.GET("/petitions/:id", petitionHandler.GetPetition)
+		// ===== СТАТИСТИКА =====
+		protected.GET("/stats/user", usersHandler.GetUserStats)
+		moderator.GET("/stats/platform", eventHandler.GetContentStats)
 
-		// Опитування (публічні)
-		api.GET("/polls", pollHandler.GetAllPolls)
-		api.GET("/polls/:id", pollHandler.GetPoll)
-		api.GET("/polls/:id/results", pollHandler.GetPollResults)
+		// ===== МОДЕРАЦІЯ КОРИСТУВАЧІВ =====
+		cityModerator.POST("/moderation/users/:id/ban", usersHandler.BanUser)
+		cityModerator.POST("/moderation/users/:id/unban", usersHandler.UnbanUser)
+		cityModerator.GET("/moderation/users/:id/trust", trustHandler.GetUserTrust)
+
+		// ===== НОТАТКИ МОДЕРАТОРІВ =====
+		moderator.GET("/moderation/notes", moderatorNoteHandler.GetNotes)
+		moderator.GET("/moderation/notes/mentions", moderatorNoteHandler.GetMentions)
+		moderator.POST("/moderation/notes", moderatorNoteHandler.CreateNote)
+		moderator.PUT("/moderation/notes/:id", moderatorNoteHandler.UpdateNote)
+		moderator.DELETE("/moderation/notes/:id", moderatorNoteHandler.DeleteNote)
+
+		// ===== ІМПОРТ З СОЦМЕРЕЖ =====
+		// Пости каналів надходять у чергу модерації оголошень або подій
+		moderator.GET("/moderation/import/feeds", socialImportHandler.GetFeeds)
+		moderator.POST("/moderation/import/feeds", socialImportHandler.ConnectFeed)
+		moderator.PUT("/moderation/import/feeds/:id", socialImportHandler.UpdateFeed)
+		moderator.DELETE("/moderation/import/feeds/:id", socialImportHandler.DeleteFeed)
+		moderator.POST("/moderation/import/feeds/:id/run", socialImportHandler.RunFeed)
+		moderator.GET("/moderation/import/feeds/:id/imports", socialImportHandler.GetFeedImports)
+
+		// ===== УПРАВЛІННЯ КОРИСТУВАЧАМИ (ADMIN) =====
+		admin.GET("/users", usersHandler.GetAllUsers)
+		admin.GET("/users/:id", usersHandler.GetUser)
+		admin.PUT("/users/:id", usersHandler.UpdateUser)
+		admin.DELETE("/users/:id",
+			middleware.RequirePermission(string(models.PermissionManageUsers)),
+			usersHandler.DeleteUser)
+		admin.PUT("/users/:id/block", usersHandler.BlockUser)
+		admin.PUT("/users/:id/unblock", usersHandler.UnblockUser)
+		admin.PUT("/users/:id/suspend", usersHandler.SuspendUser)
+		admin.PUT("/users/:id/verify", usersHandler.VerifyUser)
+		admin.PUT("/users/:id/role", usersHandler.UpdateUserRole)
+		admin.GET("/admin/users/:id/role-history", usersHandler.GetUserRoleHistory)
+		admin.PUT("/admin/users/:id/moderation-scope", usersHandler.SetModerationScope)
+		admin.POST("/admin/users/bulk",
+			middleware.RequirePermission(string(models.PermissionManageUsers)),
+			usersHandler.BulkUsers)
+
+		// Перевірка карток жителя (:id - ID користувача)
+		admin.GET("/admin/resident-cards",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			residentCardHandler.GetResidentCards)
+		admin.POST("/admin/resident-cards/:id/verify",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			residentCardHandler.VerifyResidentCard)
+		admin.POST("/admin/resident-cards/:id/reject",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			residentCardHandler.RejectResidentCard)
+		admin.POST("/admin/resident-cards/:id/revoke",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			residentCardHandler.RevokeResidentCard)
 
-		// Проблеми міста
-		api.GET("/city-issues", cityIssueHandler.GetIssues)
-		api.GET("/city-issues/:id", cityIssueHandler.GetIssue)
+		// ===== АНАЛІТИКА =====
+		admin.GET("/analytics/users",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			usersHandler.GetUserStats)
+		admin.GET("/analytics/content", eventHandler.GetContentStats)
+		admin.GET("/analytics/moderation",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			moderationAnalyticsHandler.GetModerationStats)
+		admin.GET("/analytics/cohorts",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			cohortHandler.GetCohorts)
+
+		// Сегменти користувачів для когорт
+		segments := admin.Group("/analytics/segments")
+		segments.Use(middleware.RequirePermission(string(models.PermissionViewAnalytics)))
+		{
+			segments.GET("", cohortHandler.GetSegments)
+			segments.POST("", cohortHandler.CreateSegment)
+			segments.PUT("/:id", cohortHandler.UpdateSegment)
+			segments.DELETE("/:id", cohortHandler.DeleteSegment)
+		}
 
-		// Транспорт (публічна інформація)
-		api.GET("/transport/routes", transportHandler.GetRoutes)
-		api.GET("/transport/routes/:id", transportHandler.GetRoute)
-		api.GET("/transport/stops/nearby", transportHandler.GetNearbyStops)
-		api.GET("/transport/arrivals", transportHandler.GetArrivals)
-		api.GET("/transport/live", transportHandler.GetLiveTracking)
+		// ===== ВИВАНТАЖЕННЯ ДЛЯ ДОСЛІДНИКІВ =====
+		admin.GET("/analytics/exports",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			exportHandler.GetDatasets)
+		admin.POST("/analytics/exports/salt/rotate",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			exportHandler.RotateSalt)
+		admin.GET("/analytics/exports/:dataset",
+			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
+			exportHandler.Export)
+
+		// ===== ЖУРНАЛ АУДИТУ =====
+		admin.GET("/admin/audit-logs",
+			middleware.RequirePermission(string(models.PermissionViewAuditLogs)),
+			auditLogHandler.GetAuditLogs)
+
+		// ===== УПРАВЛІННЯ ГРОМАДАМИ (SUPER_ADMIN) =====
+		admin.POST("/communities",
+			middleware.RequireMinimumRole(string(models.RoleSuperAdmin)),
+			communityHandler.CreateCommunity)
+		admin.PUT("/communities/:id",
+			middleware.RequireMinimumRole(string(models.RoleSuperAdmin)),
+			communityHandler.UpdateCommunity)
+		admin.PUT("/admin/settings",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			communityHandler.UpdateSettings)
+
+		// ===== УПРАВЛІННЯ КАТЕГОРІЯМИ =====
+		admin.GET("/admin/taxonomies",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			taxonomyHandler.GetAllTaxonomies)
+		admin.POST("/admin/taxonomies",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			taxonomyHandler.CreateTaxonomy)
+		admin.PUT("/admin/taxonomies/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			taxonomyHandler.UpdateTaxonomy)
+
+		// ===== БАНЕРИ ЗАСТОСУНКІВ =====
+		admin.GET("/admin/banners",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			bannerHandler.GetBanners)
+		admin.POST("/admin/banners",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			bannerHandler.CreateBanner)
+		admin.PUT("/admin/banners/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			bannerHandler.UpdateBanner)
+		admin.DELETE("/admin/banners/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			bannerHandler.DeleteBanner)
+		admin.POST("/admin/embeds",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			embedHandler.CreateEmbed)
+
+		// ===== СХОВИЩЕ ДАНИХ =====
+		admin.GET("/admin/storage",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			storageHandler.GetStorageUsage)
+		admin.GET("/admin/database/retries",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			storageHandler.GetDatabaseRetries)
+
+		// ===== РЕЗЕРВНІ КОПІЇ =====
+		admin.GET("/admin/backups",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			backupHandler.GetBackups)
+		admin.POST("/admin/backups",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			backupHandler.TriggerBackup)
+
+		// ===== ЛІЧИЛЬНИКИ =====
+		admin.GET("/admin/counters/reconciliations",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			counterHandler.GetReconciliations)
+		admin.POST("/admin/counters/reconcile",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			counterHandler.TriggerReconciliation)
+
+		// ===== КАЛЕНДАР ГРОМАДИ =====
+		admin.POST("/admin/calendar/days",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			calendarHandler.DeclareDay)
+		admin.DELETE("/admin/calendar/days/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			calendarHandler.RemoveDay)
+
+		// ===== EMAIL =====
+		admin.GET("/admin/email/queue", emailHandler.GetQueueStats)
+		admin.GET("/admin/email/logs", emailHandler.GetDeliveryLogs)
+		admin.GET("/admin/email/suppressions", emailHandler.GetSuppressions)
+		admin.POST("/admin/email/suppressions", emailHandler.AddSuppression)
+		admin.DELETE("/admin/email/suppressions/:email", emailHandler.RemoveSuppression)
+
+		// ===== OUTBOX СПОВІЩЕНЬ =====
+		admin.GET("/admin/notifications/outbox", notificationOutboxHandler.GetOutbox)
+		admin.POST("/admin/notifications/outbox/:id/retry", notificationOutboxHandler.RetryIntent)
+
+		// ===== REALTIME-ЛІЧИЛЬНИКИ АДМІН-ПАНЕЛІ =====
+		admin.GET("/admin/realtime/counters", adminRealtimeHandler.GetCounters)
+		// ws://localhost:8080/ws/admin?token=... (токен адміністратора)
+		router.GET("/ws/admin", adminRealtimeHandler.HandleWebSocket)
+	}
 
-		// Типи сповіщень
-		api.GET("/notification-types", notificationHandler.GetNotificationTypes)
+	// ===== ПІСОЧНИЦЯ =====
+	// Скидання доступне без авторизації: демо-акаунти повертаються у відповіді
+	if cfg.SandboxMode {
+		api.POST("/sandbox/reset", sandboxHandler.Reset)
 	}
 
 	// ========================================
-	// 🔒 ЗАХИЩЕНІ МАРШРУТИ (потрібна автентифікація)
+	// 🧩 МОДУЛІ (можна вимкнути через DISABLED_MODULES)
 	// ========================================
-	protected := api.Group("")
-	protected.Use(middleware.AuthMiddleware(jwtManager))
-	{
-		// ===== ПРОФІЛЬ КОРИСТУВАЧА =====
-		protected.GET("/auth/profile", authHandler.GetProfile)
-		protected.PUT("/auth/profile", authHandler.UpdateProfile)
-		protected.PUT("/auth/password", authHandler.ChangePassword)
 
-		// ===== ГРУПИ ТА ЧАТИ =====
-		protected.POST("/groups", groupHandler.CreateGroup)
+	// ===== ГРУПИ ТА ЧАТИ =====
+	moduleRegistry.Add(models.ModuleGroups, []string{
+		"/api/v1/groups", "/api/v1/search/groups", "/api/v1/stats/groups", "/ws",
+		"/api/v1/moderation/messages", "/api/v1/conversations", "/api/v1/admin/chat",
+	}, func() {
+		api.GET("/groups/public", groupHandler.GetPublicGroups)
+		api.GET("/search/groups", groupHandler.SearchGroups)
+
+		protected.POST("/groups",
+			middleware.RequirePermission(string(models.PermissionCreateGroup)),
+			groupHandler.CreateGroup)
 		protected.GET("/groups", groupHandler.GetUserGroups)
 		protected.GET("/groups/:id", groupHandler.GetGroup)
 		protected.PUT("/groups/:id", groupHandler.UpdateGroup)
 		protected.DELETE("/groups/:id", groupHandler.DeleteGroup)
-		protected.POST("/groups/:id/join", groupHandler.JoinGroup)
+		protected.POST("/groups/:id/join",
+			middleware.RequirePermission(string(models.PermissionJoinGroup)),
+			groupHandler.JoinGroup)
 		protected.POST("/groups/:id/leave", groupHandler.LeaveGroup)
 
+		// Ролі та модерація учасників: права перевіряються за роллю в групі
+		protected.PUT("/groups/:id/members/:userId/role", groupHandler.SetMemberRole)
+		protected.DELETE("/groups/:id/members/:userId", groupHandler.RemoveMember)
+		protected.POST("/groups/:id/members/:userId/ban", groupHandler.BanMember)
+		protected.DELETE("/groups/:id/members/:userId/ban", groupHandler.UnbanMember)
+		protected.GET("/groups/:id/bans", groupHandler.GetBans)
+		// Заявки на вступ до груп за запрошенням
+		protected.GET("/groups/:id/join-requests", groupHandler.GetJoinRequests)
+		protected.POST("/groups/:id/join-requests/:requestId/approve", groupHandler.ApproveJoinRequest)
+		protected.POST("/groups/:id/join-requests/:requestId/reject", groupHandler.RejectJoinRequest)
+		// Посилання-запрошення: вступ без заявки, зокрема до приватних груп
+		protected.POST("/groups/:id/invites", groupHandler.CreateInvite)
+		protected.GET("/groups/:id/invites", groupHandler.GetInvites)
+		protected.DELETE("/groups/:id/invites/:inviteId", groupHandler.RevokeInvite)
+		// Ліміт спроб на користувача: коди не підбираються перебором
+		inviteLimiter := middleware.NewGeneralRateLimiter(10, time.Minute)
+		protected.POST("/groups/join-by-invite",
+			middleware.RequirePermission(string(models.PermissionJoinGroup)),
+			inviteLimiter.Middleware(),
+			groupHandler.JoinByInvite)
+
 		// Повідомлення в групах
-		protected.POST("/groups/:id/messages", groupHandler.SendMessage)
+		protected.POST("/groups/:id/messages",
+			middleware.RequirePermission(string(models.PermissionSendMessage)),
+			groupHandler.SendMessage)
+		// Файли для повідомлень: ID з відповіді передаються в attachment_ids
+		protected.POST("/groups/:id/attachments",
+			middleware.RequirePermission(string(models.PermissionSendMessage)),
+			groupHandler.UploadAttachment)
 		protected.GET("/groups/:id/messages", groupHandler.GetMessages)
+		protected.GET("/groups/:id/messages/poll", longPollHandler.PollMessages)
+		protected.GET("/groups/:id/messages/search", groupHandler.SearchMessages)
+		protected.PUT("/groups/:id/messages/:msgId", groupHandler.UpdateMessage)
+		protected.DELETE("/groups/:id/messages/:msgId", groupHandler.DeleteMessage)
+		protected.POST("/groups/:id/messages/:msgId/report", chatModerationHandler.ReportMessage)
+		protected.PUT("/groups/:id/slow-mode", groupHandler.SetSlowMode)
+		// Закріплені повідомлення та режим каналу оголошень (власник і адміністратори групи)
+		protected.GET("/groups/:id/messages/pinned", groupHandler.GetPinnedMessages)
+		protected.POST("/groups/:id/messages/:msgId/pin", groupHandler.PinMessage)
+		protected.DELETE("/groups/:id/messages/:msgId/pin", groupHandler.UnpinMessage)
+		protected.PUT("/groups/:id/announcements-only", groupHandler.SetAnnouncementsOnly)
+		// Позиція читання: лічильники непрочитаних у GET /groups і "переглянуто" для учасників
+		protected.POST("/groups/:id/read", groupHandler.MarkAsRead)
+		protected.GET("/groups/:id/read-receipts", groupHandler.GetReadReceipts)
+		protected.GET("/groups/:id/presence", groupHandler.GetGroupPresence)
+
+		// Особисті повідомлення; розмова створюється першим повідомленням
+		protected.GET("/conversations", conversationHandler.GetConversations)
+		protected.GET("/conversations/unread", conversationHandler.GetUnreadCount)
+		protected.GET("/conversations/:userId/messages", conversationHandler.GetMessages)
+		protected.POST("/conversations/:userId/messages",
+			middleware.RequirePermission(string(models.PermissionSendMessage)),
+			conversationHandler.SendMessage)
+		protected.POST("/conversations/:userId/read", conversationHandler.MarkAsRead)
+
+		// Повідомлення з посиланнями від акаунтів з низькою довірою
+		cityModerator.GET("/moderation/messages/held", trustHandler.GetHeldMessages)
+		cityModerator.POST("/moderation/messages/:id/approve", trustHandler.ApproveHeldMessage)
+		cityModerator.POST("/moderation/messages/:id/reject", trustHandler.RejectHeldMessage)
+
+		// Скарги учасників та спрацювання фільтра заборонених слів
+		cityModerator.GET("/moderation/messages/reported", chatModerationHandler.GetReviewQueue)
+		cityModerator.POST("/moderation/messages/:id/review", chatModerationHandler.ReviewMessage)
+		admin.GET("/admin/chat/banned-words",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			chatModerationHandler.GetBannedWords)
+		admin.POST("/admin/chat/banned-words",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			chatModerationHandler.CreateBannedWord)
+		admin.DELETE("/admin/chat/banned-words/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			chatModerationHandler.RemoveBannedWord)
+
+		protected.GET("/stats/groups/:id", groupHandler.GetGroupStats)
 
-		// ===== ОГОЛОШЕННЯ =====
-		protected.POST("/announcements", announcementHandler.CreateAnnouncement)
+		// WebSocket endpoint для real-time чату
+		// ws://localhost:8080/ws
+		router.GET("/ws", wsHandler.HandleWebSocket)
+	})
+
+	// ===== ОГОЛОШЕННЯ =====
+	moduleRegistry.Add(models.ModuleAnnouncements, []string{
+		"/api/v1/announcements", "/api/v1/moderation/posts",
+	}, func() {
+		api.GET("/announcements", announcementHandler.GetAnnouncements)
+		api.GET("/announcements/attributes", announcementHandler.GetAttributeSchemas)
+		api.GET("/announcements/:id", announcementHandler.GetAnnouncement)
+
+		protected.GET("/announcements/recommended", announcementHandler.GetRecommendedAnnouncements)
+		protected.POST("/announcements",
+			middleware.RequirePermission(string(models.PermissionCreateAnnouncement)),
+			announcementHandler.CreateAnnouncement)
 		protected.PUT("/announcements/:id", announcementHandler.UpdateAnnouncement)
 		protected.DELETE("/announcements/:id", announcementHandler.DeleteAnnouncement)
 
-		// ===== ПОДІЇ =====
-		protected.POST("/events", eventHandler.CreateEvent)
+		// Модерація оголошень
+		moderator.PUT("/announcements/:id/approve",
+			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
+			announcementHandler.ApproveAnnouncement)
+		moderator.PUT("/announcements/:id/reject",
+			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
+			announcementHandler.RejectAnnouncement)
+		moderator.GET("/announcements/:id/revisions", announcementHandler.GetRevisions)
+
+		// Модерація постів (оголошень)
+		moderator.GET("/moderation/posts/pending",
+			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
+			announcementHandler.GetPendingAnnouncements)
+		moderator.POST("/moderation/posts/:id/approve",
+			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
+			announcementHandler.ApproveAnnouncement)
+		moderator.POST("/moderation/posts/:id/reject",
+			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
+			announcementHandler.RejectAnnouncement)
+	})
+
+	// ===== ПОДІЇ =====
+	moduleRegistry.Add(models.ModuleEvents, []string{
+		"/api/v1/events", "/api/v1/search/events", "/api/v1/moderation/events",
+	}, func() {
+		api.GET("/events", eventHandler.GetEvents)
+		api.GET("/events/:id", eventHandler.GetEvent)
+		api.GET("/events/nearby", eventHandler.GetNearbyEvents)
+		api.GET("/search/events", eventHandler.SearchEvents)
+
+		protected.GET("/events/recommended", eventHandler.GetRecommendedEvents)
+		protected.POST("/events",
+			middleware.RequirePermission(string(models.PermissionCreateEvent)),
+			eventHandler.CreateEvent)
 		protected.PUT("/events/:id", eventHandler.UpdateEvent)
 		protected.DELETE("/events/:id", eventHandler.DeleteEvent)
 		protected.POST("/events/:id/attend", eventHandler.AttendEvent)
 		protected.POST("/events/:id/leave", eventHandler.LeaveEvent)
 
-		// ===== ПЕТИЦІЇ =====
-		protected.POST("/petitions", petitionHandler.CreatePetition)
+		moderator.PUT("/events/:id/moderate",
+			middleware.RequirePermission(string(models.PermissionModerateEvent)),
+			eventHandler.ModerateEvent)
+		moderator.GET("/moderation/events/pending",
+			middleware.RequirePermission(string(models.PermissionModerateEvent)),
+			eventHandler.GetPendingEvents)
+	})
+
+	// ===== ПЕТИЦІЇ =====
+	moduleRegistry.Add(models.ModulePetitions, []string{
+		"/api/v1/petitions", "/api/v1/feeds/petitions",
+	}, func() {
+		api.GET("/petitions", petitionHandler.GetPetitions)
+		api.GET("/petitions/similar", petitionHandler.FindSimilarPetitions)
+		// Пороги підписів громади з нормативною підставою
+		api.GET("/petitions/thresholds", petitionThresholdHandler.GetThresholds)
+		// Автор і співавтори бачать також чернетку
+		api.GET("/petitions/:id",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			petitionHandler.GetPetition)
+
+		protected.POST("/petitions",
+			middleware.RequirePermission(string(models.PermissionCreatePetition)),
+			petitionHandler.CreatePetition)
 		protected.POST("/petitions/:id/publish", petitionHandler.PublishPetition)
-		protected.PUT("/petitions/:id/status", petitionHandler.UpdatePetitionStatus)
-		protected.POST("/petitions/:id/sign", petitionHandler.SignPetition)
+		// Продовження зберігання чернетки (посилання з попередження про видалення)
+		protected.POST("/petitions/:id/extend-draft", draftHandler.ExtendPetitionDraft)
+		protected.POST("/petitions/:id/sign",
+			middleware.RequirePermission(string(models.PermissionSignPetition)),
+			petitionHandler.SignPetition)
 		protected.PUT("/petitions/:id", petitionHandler.UpdatePetition)
 		protected.DELETE("/petitions/:id", petitionHandler.DeletePetition)
+		// Власні петиції (?type=authored|signed|co_authored|invitations)
+		protected.GET("/petitions/my", petitionHandler.GetUserPetitions)
+
+		// Співавтори: запрошення автором, прийняття/відхилення запрошеним
+		protected.POST("/petitions/:id/co-authors", petitionHandler.InviteCoAuthor)
+		protected.POST("/petitions/:id/co-authors/accept", petitionHandler.AcceptCoAuthorInvitation)
+		protected.POST("/petitions/:id/co-authors/decline", petitionHandler.DeclineCoAuthorInvitation)
+		protected.DELETE("/petitions/:id/co-authors/:user_id", petitionHandler.RemoveCoAuthor)
+
+		// Модерація петицій (зміна статусу доступна лише модераторам)
+		moderator.PUT("/petitions/:id/status", petitionHandler.UpdatePetitionStatus)
+		// Історія редагувань тексту після публікації
+		moderator.GET("/petitions/:id/revisions", petitionHandler.GetRevisions)
+
+		// Пороги підписів за категоріями (адміністрація громади)
+		admin.POST("/admin/petition-thresholds",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			petitionThresholdHandler.CreateThreshold)
+		admin.PUT("/admin/petition-thresholds/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			petitionThresholdHandler.UpdateThreshold)
+		admin.DELETE("/admin/petition-thresholds/:id",
+			middleware.RequirePermission(string(models.PermissionManageSystemSettings)),
+			petitionThresholdHandler.RemoveThreshold)
+
+		// Atom-стрічки за категоріями (?category=)
+		api.GET("/feeds/petitions", feedHandler.PetitionsFeed)
+		api.GET("/feeds/petitions/responses", feedHandler.PetitionResponsesFeed)
+	})
+
+	// ===== ОПИТУВАННЯ =====
+	moduleRegistry.Add(models.ModulePolls, []string{
+		"/api/v1/polls", "/api/v1/analytics/polls", "/api/v1/moderation/polls",
+	}, func() {
+		api.GET("/polls", pollHandler.GetAllPolls)
+		// Автор і модератори бачать також опитування на модерації
+		api.GET("/polls/:id",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			pollHandler.GetPoll)
+		api.GET("/polls/:id/results", pollHandler.GetPollResults)
 
-		// ===== ОПИТУВАННЯ =====
 		// ✅ Створення опитування з rate limiting (5 хвилин між створенням)
-		protected.POST("/polls", middleware.RateLimitMiddleware(), pollHandler.CreatePoll)
+		protected.GET("/polls/recommended", pollHandler.GetRecommendedPolls)
+		protected.POST("/polls",
+			middleware.RequirePermission(string(models.PermissionCreatePoll)),
+			middleware.RateLimitMiddleware(),
+			pollHandler.CreatePoll)
 
 		// Голосування в опитуваннях
-		protected.POST("/polls/:id/respond", pollHandler.VotePoll)
+		protected.POST("/polls/:id/respond",
+			middleware.RequirePermission(string(models.PermissionVotePoll)),
+			pollHandler.VotePoll)
+		// Зміна своїх відповідей до закриття опитування (без allow_multiple)
+		protected.PUT("/polls/:id/respond",
+			middleware.RequirePermission(string(models.PermissionVotePoll)),
+			pollHandler.UpdatePollResponse)
 
 		// Редагування/видалення (тільки автор або модератор)
 		protected.PUT("/polls/:id", pollHandler.UpdatePoll)
 		protected.DELETE("/polls/:id", pollHandler.DeletePoll)
+		// Продовження зберігання чернетки (посилання з попередження про видалення)
+		protected.POST("/polls/:id/extend-draft", draftHandler.ExtendPollDraft)
+		// Відправка чернетки користувача на модерацію
+		protected.POST("/polls/:id/submit", pollHandler.SubmitPoll)
 
-		// ===== ПРОБЛЕМИ МІСТА =====
-		protected.POST("/city-issues", cityIssueHandler.CreateIssue)
+		// Модерація опитувань
+		moderator.PUT("/polls/:id/status", pollHandler.UpdatePollStatus)
+		moderator.GET("/moderation/polls", pollHandler.GetPendingPolls)
+		moderator.POST("/moderation/polls/:id/approve", pollHandler.ApprovePoll)
+		moderator.POST("/moderation/polls/:id/reject", pollHandler.RejectPoll)
+		// Застарілий: DELETE /polls/:id вже дозволяє модераторам (див. api_versions.go)
+		moderator.DELETE("/polls/:id/force", pollHandler.DeletePoll)
+
+		admin.GET("/analytics/polls", pollHandler.GetPollStats)
+	})
+
+	// ===== ПРОБЛЕМИ МІСТА =====
+	moduleRegistry.Add(models.ModuleCityIssues, []string{
+		"/api/v1/city-issues", "/api/v1/feeds/issues",
+	}, func() {
+		api.GET("/city-issues", cityIssueHandler.GetIssues)
+		api.GET("/city-issues/similar", cityIssueHandler.FindSimilarIssues)
+		api.GET("/city-issues/:id", cityIssueHandler.GetIssue)
+
+		protected.POST("/city-issues",
+			middleware.RequirePermission(string(models.PermissionReportCityIssue)),
+			cityIssueHandler.CreateIssue)
 		protected.PUT("/city-issues/:id", cityIssueHandler.UpdateIssue)
 		protected.POST("/city-issues/:id/upvote", cityIssueHandler.UpvoteIssue)
+		protected.POST("/city-issues/:id/comments", cityIssueHandler.AddComment)
+		protected.POST("/city-issues/:id/subscribe", cityIssueHandler.SubscribeToIssue)
+		protected.PUT("/city-issues/:id/subscription", cityIssueHandler.UpdateSubscription)
+
+		moderator.PUT("/city-issues/:id/status",
+			middleware.RequirePermission(string(models.PermissionModerateCityIssue)),
+			cityIssueHandler.UpdateIssueStatus)
+		moderator.PUT("/city-issues/:id/assign",
+			middleware.RequirePermission(string(models.PermissionModerateCityIssue)),
+			cityIssueHandler.AssignIssue)
+		// Критична проблема road/safety біля маршруту позначає маршрути транспорту
+		moderator.PUT("/city-issues/:id/priority",
+			middleware.RequirePermission(string(models.PermissionModerateCityIssue)),
+			cityIssueHandler.UpdateIssuePriority)
+
+		// Atom-стрічки за категоріями (?category=)
+		api.GET("/feeds/issues", feedHandler.IssuesFeed)
+		api.GET("/feeds/issues/responses", feedHandler.IssueResponsesFeed)
+	})
+
+	// ===== ГРОМАДСЬКИЙ ТРАНСПОРТ =====
+	moduleRegistry.Add(models.ModuleTransport, []string{
+		"/api/v1/transport", "/api/v1/moderation/concessions",
+	}, func() {
+		api.GET("/transport/routes", transportHandler.GetRoutes)
+		// Авторизованим пасажирам з пільгою показується пільговий тариф
+		api.GET("/transport/routes/:id",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			transportHandler.GetRoute)
+		// Розклад на дату: у свята - святковий (?date=, ?day_type=, ?stop=)
+		api.GET("/transport/routes/:id/schedule", transportHandler.GetRouteSchedule)
+		api.GET("/transport/routes/:id/timetable.pdf", transportHandler.GetRouteTimetablePDF)
+		api.GET("/transport/stops/nearby", transportHandler.GetNearbyStops)
+		api.GET("/transport/arrivals",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			transportHandler.GetArrivals)
+		api.GET("/transport/live", transportHandler.GetLiveTracking)
+
+		// Оголошення про об'їзди (чернетки створюються через критичні проблеми біля маршрутів)
+		api.GET("/transport/alerts", transportAlertHandler.GetAlerts)
+		api.GET("/transport/alerts/:id", transportAlertHandler.GetAlert)
+
+		// Webhook GPS-провайдерів (автентифікація підписом запиту)
+		api.POST("/transport/gps/:provider/webhook", gpsHandler.ReceiveWebhook)
+
+		// Пільги на проїзд
+		api.GET("/transport/concessions/categories", concessionHandler.GetCategories)
+		protected.GET("/transport/concession", concessionHandler.GetMyConcession)
+		protected.POST("/transport/concession", concessionHandler.SubmitConcession)
+		protected.DELETE("/transport/concession", concessionHandler.DeleteMyConcession)
+
+		cityModerator.GET("/moderation/concessions/pending",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			concessionHandler.GetPendingConcessions)
+		cityModerator.POST("/moderation/concessions/:id/verify",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			concessionHandler.VerifyConcession)
+		cityModerator.POST("/moderation/concessions/:id/reject",
+			middleware.RequirePermission(string(models.PermissionVerifyUser)),
+			concessionHandler.RejectConcession)
+
+		admin.POST("/transport/routes",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.CreateRoute)
+		admin.PUT("/transport/routes/:id",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.UpdateRoute)
+		admin.DELETE("/transport/routes/:id",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.DeleteRoute)
+
+		admin.POST("/transport/vehicles",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.CreateVehicle)
+		admin.PUT("/transport/vehicles/:id",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.UpdateVehicle)
+		admin.DELETE("/transport/vehicles/:id",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportHandler.DeleteVehicle)
+		admin.PUT("/transport/vehicles/:id/tracking",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			gpsHandler.UpdateVehicleTracking)
+		admin.GET("/transport/gps/providers",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			gpsHandler.GetProviders)
+
+		admin.GET("/transport/alerts/manage",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportAlertHandler.GetManagedAlerts)
+		admin.PUT("/transport/alerts/:id",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportAlertHandler.UpdateAlert)
+		admin.POST("/transport/alerts/:id/publish",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportAlertHandler.PublishAlert)
+		admin.POST("/transport/alerts/:id/dismiss",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportAlertHandler.DismissAlert)
+		admin.POST("/transport/alerts/:id/end",
+			middleware.RequirePermission(string(models.PermissionManageTransport)),
+			transportAlertHandler.EndAlert)
+	})
+
+	// ===== СПОВІЩЕННЯ =====
+	moduleRegistry.Add(models.ModuleNotifications, []string{
+		"/api/v1/notifications", "/api/v1/notification-types",
+		"/api/v1/notification-preferences", "/api/v1/device-tokens",
+		"/api/v1/campaigns",
+	}, func() {
+		api.GET("/notification-types", notificationHandler.GetNotificationTypes)
 
-		// ===== СПОВІЩЕННЯ =====
 		protected.GET("/notifications", notificationHandler.GetNotifications)
+		protected.GET("/notifications/poll", longPollHandler.PollNotifications)
 		protected.PUT("/notifications/:id/read", notificationHandler.MarkAsRead)
 		protected.PUT("/notifications/read-all", notificationHandler.MarkAllAsRead)
 		protected.DELETE("/notifications/:id", notificationHandler.DeleteNotification)
@@ -377,106 +1765,113 @@ func main() {
 		protected.GET("/notification-preferences", notificationHandler.GetPreferences)
 		protected.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
 
-		// ===== ПОШУК =====
-		protected.GET("/search/users", usersHandler.SearchUsers)
-
-		// ===== СТАТИСТИКА =====
-		protected.GET("/stats/user", usersHandler.GetUserStats)
-		protected.GET("/stats/groups/:id", groupHandler.GetGroupStats)
-	}
-
-	// ========================================
-	// 🔒 МОДЕРАТОРСЬКІ МАРШРУТИ
-	// ========================================
-	moderator := api.Group("")
-	moderator.Use(middleware.AuthMiddleware(jwtManager))
-	moderator.Use(middleware.RequireMinimumRole(string(models.RoleModerator)))
-	{
-		// Модерація оголошень
-		moderator.PUT("/announcements/:id/approve",
-			middleware.RequirePermission(string(models.PermissionModerateAnnouncement)),
-			announcementHandler.ApproveAnnouncement)
-		moderator.PUT("/announcements/:id/reject", announcementHandler.RejectAnnouncement)
-
-		// Модерація постів (оголошень)
-		moderator.GET("/moderation/posts/pending", announcementHandler.GetPendingAnnouncements)
-		moderator.POST("/moderation/posts/:id/approve", announcementHandler.ApproveAnnouncement)
-		moderator.POST("/moderation/posts/:id/reject", announcementHandler.RejectAnnouncement)
-
-		// Управління користувачами
-		moderator.POST("/moderation/users/:id/ban", usersHandler.BanUser)
-		moderator.POST("/moderation/users/:id/unban", usersHandler.UnbanUser)
-
-		// Управління подіями
-		moderator.PUT("/events/:id/moderate", eventHandler.ModerateEvent)
-
-		// Управління проблемами міста
-		moderator.PUT("/city-issues/:id/status", cityIssueHandler.UpdateIssueStatus)
-		moderator.PUT("/city-issues/:id/assign", cityIssueHandler.AssignIssue)
-
-		// Модерація опитувань
-		moderator.PUT("/polls/:id/status", pollHandler.UpdatePoll)
-		moderator.DELETE("/polls/:id/force", pollHandler.DeletePoll)
-
-		// Модерація петицій
-		moderator.PUT("/petitions/:id/status", petitionHandler.UpdatePetition)
-
-		// Статистика платформи
-		moderator.GET("/stats/platform", eventHandler.GetContentStats)
-	}
-
-	// ========================================
-	// 🔒 АДМІНІСТРАТОРСЬКІ МАРШРУТИ
-	// ========================================
-	admin := api.Group("")
-	admin.Use(middleware.AuthMiddleware(jwtManager))
-	admin.Use(middleware.RequireMinimumRole(string(models.RoleAdmin)))
-	{
-		// ===== УПРАВЛІННЯ КОРИСТУВАЧАМИ =====
-		admin.GET("/users", usersHandler.GetAllUsers)
-		admin.GET("/users/:id", usersHandler.GetUser)
-		admin.PUT("/users/:id", usersHandler.UpdateUser)
-		admin.DELETE("/users/:id",
-			middleware.RequirePermission(string(models.PermissionManageUsers)),
-			usersHandler.DeleteUser)
-		admin.PUT("/users/:id/block", usersHandler.BlockUser)
-		admin.PUT("/users/:id/unblock", usersHandler.UnblockUser)
-		admin.PUT("/users/:id/verify", usersHandler.VerifyUser)
-		admin.PUT("/users/:id/role", usersHandler.UpdateUserRole)
-
-		// ===== СПОВІЩЕННЯ =====
 		// Відправка сповіщень користувачам
-		admin.POST("/notifications/send", notificationHandler.SendNotification)
+		admin.POST("/notifications/send",
+			middleware.RequirePermission(string(models.PermissionSendNotifications)),
+			notificationHandler.SendNotification)
 
 		// Екстрені сповіщення (всім користувачам)
-		admin.POST("/notifications/emergency", notificationHandler.SendEmergencyNotification)
+		admin.POST("/notifications/emergency",
+			middleware.RequirePermission(string(models.PermissionSendNotifications)),
+			notificationHandler.SendEmergencyNotification)
+
+		// Push-кампанії з A/B тестуванням
+		campaigns := admin.Group("/campaigns")
+		campaigns.Use(middleware.RequirePermission(string(models.PermissionSendNotifications)))
+		{
+			campaigns.GET("", campaignHandler.GetCampaigns)
+			campaigns.POST("", campaignHandler.CreateCampaign)
+			campaigns.GET("/:id", campaignHandler.GetCampaign)
+			campaigns.POST("/:id/start", campaignHandler.StartCampaign)
+			campaigns.POST("/:id/cancel", campaignHandler.CancelCampaign)
+		}
+	})
 
-		// ===== УПРАВЛІННЯ ТРАНСПОРТОМ =====
-		admin.POST("/transport/routes",
-			middleware.RequirePermission(string(models.PermissionManageTransport)),
-			transportHandler.CreateRoute)
-		admin.PUT("/transport/routes/:id", transportHandler.UpdateRoute)
-		admin.DELETE("/transport/routes/:id", transportHandler.DeleteRoute)
+	// ===== FAQ / БАЗА ЗНАНЬ =====
+	moduleRegistry.Add(models.ModuleFAQ, []string{
+		"/api/v1/faq", "/api/v1/moderation/faq",
+	}, func() {
+		api.GET("/faq/categories", faqHandler.GetCategories)
+		api.GET("/faq/articles", faqHandler.GetArticles)
+		api.GET("/faq/articles/:id", faqHandler.GetArticle)
+		// Відгуки можуть залишати і гості (вебсайт, Telegram-бот)
+		api.POST("/faq/articles/:id/feedback",
+			middleware.OptionalAuth(jwtManager, userStatusCache, sessionService, apiKeyService),
+			faqHandler.SubmitFeedback)
+
+		// Редагування бази знань
+		cityModerator.GET("/moderation/faq/categories", faqHandler.GetAllCategories)
+		cityModerator.POST("/moderation/faq/categories", faqHandler.CreateCategory)
+		cityModerator.PUT("/moderation/faq/categories/:id", faqHandler.UpdateCategory)
+		cityModerator.DELETE("/moderation/faq/categories/:id", faqHandler.DeleteCategory)
+		cityModerator.GET("/moderation/faq/articles", faqHandler.GetAllArticles)
+		cityModerator.POST("/moderation/faq/articles", faqHandler.CreateArticle)
+		cityModerator.PUT("/moderation/faq/articles/:id", faqHandler.UpdateArticle)
+		cityModerator.DELETE("/moderation/faq/articles/:id", faqHandler.DeleteArticle)
+	})
 
-		admin.POST("/transport/vehicles", transportHandler.CreateVehicle)
-		admin.PUT("/transport/vehicles/:id", transportHandler.UpdateVehicle)
-		admin.DELETE("/transport/vehicles/:id", transportHandler.DeleteVehicle)
+	// ===== ОСВІТА: НАБІР ДО ШКІЛ І САДКІВ =====
+	moduleRegistry.Add(models.ModuleEducation, []string{
+		"/api/v1/education", "/api/v1/admin/education",
+	}, func() {
+		api.GET("/education/institutions", enrollmentHandler.GetInstitutions)
+		api.GET("/education/enrollments", enrollmentHandler.GetEnrollments)
+		api.GET("/education/enrollments/:id", enrollmentHandler.GetEnrollment)
+
+		// Підписка батьків на нові набори
+		protected.GET("/education/subscription", enrollmentHandler.GetSubscription)
+		protected.PUT("/education/subscription", enrollmentHandler.Subscribe)
+		protected.DELETE("/education/subscription", enrollmentHandler.Unsubscribe)
+
+		// Управління освіти
+		education := admin.Group("/admin/education")
+		education.Use(middleware.RequirePermission(string(models.PermissionManageEducation)))
+		{
+			education.POST("/institutions", enrollmentHandler.CreateInstitution)
+			education.PUT("/institutions/:id", enrollmentHandler.UpdateInstitution)
+			education.GET("/enrollments", enrollmentHandler.GetAllEnrollments)
+			education.POST("/enrollments", enrollmentHandler.CreateEnrollment)
+			education.PUT("/enrollments/:id", enrollmentHandler.UpdateEnrollment)
+			education.PUT("/enrollments/:id/places/:institution_id", enrollmentHandler.UpdatePlaces)
+			education.POST("/enrollments/:id/publish", enrollmentHandler.PublishEnrollment)
+			education.POST("/enrollments/:id/archive", enrollmentHandler.ArchiveEnrollment)
+		}
+	})
 
-		// ===== АНАЛІТИКА =====
-		// Статистика використання платформи
-		admin.GET("/analytics/users",
-			middleware.RequirePermission(string(models.PermissionViewAnalytics)),
-			usersHandler.GetUserStats)
-		admin.GET("/analytics/content", eventHandler.GetContentStats)
-		admin.GET("/analytics/polls", pollHandler.GetPollStats)
-	}
+	// ===== ГРОМАДСЬКІ ОБГОВОРЕННЯ =====
+	moduleRegistry.Add(models.ModuleConsultations, []string{
+		"/api/v1/consultations", "/api/v1/moderation/consultations", "/api/v1/admin/consultations",
+	}, func() {
+		api.GET("/consultations", consultationHandler.GetConsultations)
+		api.GET("/consultations/:id", consultationHandler.GetConsultation)
+		api.GET("/consultations/:id/comments", consultationHandler.GetComments)
+		api.GET("/consultations/:id/report", consultationHandler.GetReport)
+
+		protected.POST("/consultations/:id/comments", consultationHandler.AddComment)
+
+		// Рішення щодо пропозицій мешканців
+		cityModerator.PUT("/moderation/consultations/comments/:comment_id/resolution", consultationHandler.ResolveComment)
+		cityModerator.GET("/moderation/consultations/:id/report", consultationHandler.GetModerationReport)
+
+		// Розробники документів
+		consultations := admin.Group("/admin/consultations")
+		consultations.Use(middleware.RequirePermission(string(models.PermissionManageConsultations)))
+		{
+			consultations.GET("", consultationHandler.GetAllConsultations)
+			consultations.POST("", consultationHandler.CreateConsultation)
+			consultations.PUT("/:id", consultationHandler.UpdateConsultation)
+			consultations.POST("/:id/publish", consultationHandler.PublishConsultation)
+			consultations.POST("/:id/complete", consultationHandler.CompleteConsultation)
+		}
+	})
 
-	// ========================================
-	// 🔌 WEBSOCKET МАРШРУТ
-	// ========================================
-	// WebSocket endpoint для real-time чату
-	// ws://localhost:8080/ws
-	router.GET("/ws", wsHandler.HandleWebSocket)
+	// Маршрути вимкнених модулів відповідають 404 з кодом MODULE_DISABLED
+	router.NoRoute(moduleRegistry.NoRouteHandler())
+
+	// Файли локального сховища за підписаними посиланнями (у S3 посилання ведуть одразу в бакет)
+	if localFileStorage != nil {
+		router.GET("/media/*key", avatarHandler.ServeMedia)
+	}
 
 	// ========================================
 	// 🏥 HEALTH CHECK
@@ -489,9 +1884,31 @@ func main() {
 			"time":    time.Now().Format(time.RFC3339),
 		})
 	})
+	// Готовність: MongoDB доступна, остання резервна копія не застаріла
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// ========================================
+	// 🏷️ ВЕРСІЇ API
+	// ========================================
+	// Поза /api/v1: опис однаковий для всіх версій
+	router.GET("/api/versions", handlers.NewAPIVersionHandler(&apiVersions).GetVersions)
 
 	log.Println("✅ All routes configured")
 
+	// ========================================
+	// 🔐 МАТРИЦЯ ДОСТУПУ
+	// ========================================
+	// Кожен зареєстрований маршрут має бути описаний у routeAccessMatrix
+	if uncovered := routeAccessMatrix.Uncovered(router.Routes()); len(uncovered) > 0 {
+		log.Fatalf("❌ Routes missing from access matrix:\n  %s", strings.Join(uncovered, "\n  "))
+	}
+
+	// Відповідність матриці й middleware перевіряє access_matrix_test.go
+	for _, shared := range routeAccessMatrix.SharedHandlers(router.Routes()) {
+		log.Printf("⚠️  Handler shared by routes with different access: %s", shared)
+	}
+	log.Printf("✅ Access matrix covers %d routes", len(router.Routes()))
+
 	// ========================================
 	// 12. ЗАПУСК HTTP СЕРВЕРА
 	// ========================================
@@ -535,9 +1952,14 @@ func main() {
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Закриваємо WebSocket з'єднання
+	// Закриваємо WebSocket з'єднання: нові підключення відхиляються, клієнти отримують
+	// server_shutdown з підказкою перепідключення, черги дописуються до закриття
 	log.Println("📡 Closing WebSocket connections...")
-	// wsHandler.Shutdown() // Якщо є такий метод
+	if err := wsHandler.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  WebSocket connections did not close in time: %v", err)
+	} else {
+		log.Println("✅ WebSocket connections closed")
+	}
 
 	// Зупиняємо HTTP сервер
 	if err := srv.Shutdown(ctx); err != nil {
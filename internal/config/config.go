@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,15 +15,48 @@ type Config struct {
 	Host string
 	Env  string
 
+	// Адреса/подсети балансировщиков, которым доверяется X-Forwarded-For.
+	// Пусто - заголовок игнорируется, IP клиента берется из соединения
+	// (по нему работают лимиты и временные баны по IP)
+	TrustedProxies []string
+
+	// Публичный адрес веб-приложения (ссылки в лентах, письмах)
+	PublicURL string
+
 	// MongoDB настройки
 	MongoURI     string
 	DatabaseName string
 	MongoTimeout int
 
+	// Повторы при временных ошибках MongoDB: попыток всего, задержка первого повтора (мс),
+	// временных ошибок подряд до размыкания автомата и длительность паузы (сек)
+	MongoRetryAttempts    int
+	MongoRetryBaseDelayMs int
+	MongoBreakerThreshold int
+	MongoBreakerOpenSec   int
+
 	// JWT настройки
 	JWTSecret     string
 	JWTExpiration int
 
+	// Короткоживущий access-токен (минуты; 0 - JWTExpiration в часах) и refresh-токен (дни)
+	JWTAccessTTLMinutes int
+	RefreshTokenTTLDays int
+
+	// SSO для сотрудников горсовета (OIDC): Azure AD и Google Workspace.
+	// Провайдер включается, если задан client ID. Вход разрешен только с адресов доменов
+	// SSOAllowedDomains, роль назначается по правилам SSORoleMapping.
+	SSOAzureTenantID      string
+	SSOAzureClientID      string
+	SSOAzureClientSecret  string
+	SSOGoogleClientID     string
+	SSOGoogleClientSecret string
+	SSOAllowedDomains     []string
+	SSORoleMapping        map[string]string
+	SSOCallbackBaseURL    string   // Публичный адрес API для redirect_uri провайдера
+	SSOAppRedirectURLs    []string // Адреса админ-панели, куда возвращаются токены после входа
+	SSOStateSecret        string
+
 	// Firebase настройки
 	FirebaseKey string
 
@@ -38,6 +72,172 @@ type Config struct {
 	SMTPPort     int
 	SMTPUsername string
 	SMTPPassword string
+	SMTPFrom     string
+
+	// Резервный SMTP провайдер (failover)
+	SMTPBackupHost     string
+	SMTPBackupPort     int
+	SMTPBackupUsername string
+	SMTPBackupPassword string
+
+	// Автомат отключения провайдеров уведомлений (FCM, SMTP): после N ошибок подряд
+	// сообщения копятся в очереди, пробная отправка - через OpenSec секунд
+	ProviderBreakerThreshold int
+	ProviderBreakerOpenSec   int
+
+	// Multi-tenancy настройки
+	DefaultCommunity string            // Код громады по умолчанию
+	CommunityHosts   map[string]string // Статическое соответствие host -> код громады
+
+	// Модули, отключенные в данном развертывании (transport, petitions, ...)
+	DisabledModules []string
+
+	// Ограничение частоты сообщений в чатах
+	ChatRateLimit  int // Максимум сообщений пользователя за окно
+	ChatRateWindow int // Окно в секундах
+
+	// Период ротации соли псевдонимизации аналитических выгрузок (дни)
+	ExportSaltRotationDays int
+
+	// Медиахранилище (изображения) и разрешенные видеохостинги
+	MediaBaseURL string
+	VideoHosts   []string
+
+	// Защита WebSocket от переподключений в цикле (пределы на один IP)
+	WSMaxConnectionsPerIP int // Одновременно открытых соединений
+	WSHandshakesPerMinute int // Попыток подключения в минуту
+	WSChurnBanThreshold   int // Попыток за 10 секунд до временного бана
+	WSBanDurationSec      int
+
+	// Час (0-23, локальное время) ежедневной отправки дайджеста по проблемам города
+	IssueDigestHour int
+
+	// Тихие часы по умолчанию (HH:MM, часовой пояс IANA): неэкстренные push откладываются до
+	// утра. QUIET_HOURS_START=off отключает глобальные тихие часы; пользователь может задать свои
+	QuietHoursStart    string
+	QuietHoursEnd      string
+	QuietHoursTimezone string
+
+	// Лимит хранилища тарифа Atlas в МБ (512 - M0; 0 - без лимита) и порог предупреждения в процентах
+	AtlasStorageLimitMB int
+	StorageWarnPercent  int
+
+	// Хранилище загруженных файлов: драйвер local (каталог на диске) или s3 (AWS S3, MinIO)
+	FileStorageDriver string
+	FileStorageDir    string
+	S3Endpoint        string // Пусто - AWS S3 в регионе S3Region
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3PathStyle       bool // Адресация bucket в пути URL (MinIO)
+
+	// Секрет подписи ссылок локального хранилища, срок действия подписанных ссылок (минут)
+	// и максимальный размер загружаемого аватара (МБ)
+	FileURLSecret   string
+	SignedURLTTLMin int
+	AvatarMaxMB     int
+
+	// Максимальный размер вложения чата (МБ); лимиты по видам файлов - models.MaxAttachmentSizes
+	ChatAttachmentMaxMB int
+
+	// Сколько дней хранится архив выгрузки данных пользователя (GET /auth/export)
+	DataExportRetentionDays int
+
+	// Резервные копии критичных коллекций: ежедневно в BackupHour (локальное время сервера),
+	// хранятся BackupRetention последних копий. Пустой BackupS3Bucket - то же файловое хранилище,
+	// что и для загрузок (префикс backups/). /health/ready сообщает о копии старше BackupMaxAgeHours.
+	BackupEnabled     bool
+	BackupHour        int
+	BackupRetention   int
+	BackupCollections []string
+	BackupS3Bucket    string
+	BackupMaxAgeHours int
+
+	// Час (локальное время сервера) ежедневной сверки денормализованных счетчиков с источниками
+	CounterReconcileHour int
+
+	// Сколько секунд AuthMiddleware кэширует блокировку и роль пользователя
+	UserStatusCacheTTLSec int
+
+	// Как часто (секунд) проверяется отзыв сессии токена и обновляется ее last_seen_at
+	SessionCheckIntervalSec int
+
+	// Государственные праздники - выходные дни. При военном положении (ст. 73 КЗпП не действует)
+	// выключается: праздники остаются в календаре, но учреждения и транспорт работают как обычно.
+	CalendarHolidaysDayOff bool
+
+	// Радиус (метров) от критической проблемы road/safety до линии маршрута, в котором маршрут помечается
+	TransportIncidentRadius float64
+
+	// Шрифт TrueType с кириллицей для печатных расписаний транспорта (PDF)
+	TimetableFontPath string
+
+	// SMS-шлюз для кодов подтверждения телефона (пусто - сообщения пишутся в лог)
+	SMSGatewayURL   string
+	SMSGatewayToken string
+	SMSSender       string
+
+	// Коды подтверждения телефона: срок жизни (минут), попыток ввода,
+	// запросов на номер в час и минимальный интервал между запросами (секунд)
+	PhoneCodeTTLMin      int
+	PhoneCodeMaxAttempts int
+	PhoneCodesPerHour    int
+	PhoneCodeResendSec   int
+
+	// Реестр жителей громады для проверки карт жителя (пусто - карты проверяет администратор).
+	// Подтвержденная карта действует столько дней, если реестр не сообщил свой срок.
+	ResidentRegistryURL      string
+	ResidentRegistryToken    string
+	ResidentCardValidityDays int
+	ResidentCardHashKey      string // Ключ HMAC номеров карт
+
+	// Ключ HMAC РНОКПП, подтвержденных Дией или BankID (один голос на человека в официальных опросах)
+	IdentityHashKey string
+
+	// Территория громады для опросов с location_required: центр и радиус (км)
+	MunicipalityLatitude  float64
+	MunicipalityLongitude float64
+	MunicipalityRadiusKm  float64
+
+	// Максимум подзапросов в одном POST /batch
+	BatchMaxRequests int
+
+	// Виджеты петиций и опросов для iframe: адрес приложения для перехода из виджета,
+	// сайты, на которых разрешено встраивание (пусто - любые, указанные администратором),
+	// срок токена по умолчанию (часов) и запросов в минуту с одного IP
+	AppBaseURL              string
+	EmbedAllowedOrigins     []string
+	EmbedTokenSecret        string
+	EmbedTokenTTLHours      int
+	EmbedRateLimitPerMinute int
+
+	// Прежние ключи пагинации (events, pagination, total_pages и т.п.) рядом с {items, page_info}.
+	// Клиент может выбрать формат заголовком X-Pagination-Format.
+	PaginationLegacyKeys bool
+
+	// Сжатие ответов: минимальный размер (байт) и уровень gzip (1-9, -1 - по умолчанию)
+	CompressionMinSize int
+	CompressionLevel   int
+
+	// Внешние GPS-провайдеры автопарка (ключ - имя адаптера)
+	GPSProviderURLs    map[string]string // URL для опроса позиций
+	GPSProviderTokens  map[string]string // Bearer-токены для опроса
+	GPSWebhookSecrets  map[string]string // Секреты подписи webhook
+	GPSPollIntervalSec int
+
+	// Импорт постов публичных каналов в черновики объявлений и событий
+	SocialImportIntervalMin int    // Интервал опроса каждого канала
+	VKServiceToken          string // Сервисный ключ приложения VK (без него VK недоступен)
+	FacebookAccessToken     string // Токен Graph API (без него Facebook недоступен)
+
+	// Заброшенные черновики опросов и петиций
+	DraftTTLDays     int // Черновик без изменений удаляется через столько дней
+	DraftWarningDays int // За сколько дней до удаления автор получает уведомление
+
+	// Песочница для сторонних разработчиков: демо-данные, POST /sandbox/reset
+	// и никаких внешних побочных эффектов (push, письма, SMS, внешние API)
+	SandboxMode bool
 }
 
 func Load() *Config {
@@ -50,24 +250,182 @@ func Load() *Config {
 		Port:          getEnv("PORT", "8080"),
 		Host:          getEnv("HOST", "0.0.0.0"),
 		Env:           getEnv("ENV", "development"),
+		PublicURL:     strings.TrimRight(getEnv("PUBLIC_URL", "https://ecity.gov.ua"), "/"),
 		MongoURI:      getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		DatabaseName:  getEnv("DATABASE_NAME", "nova_kakhovka_ecity"),
 		MongoTimeout:  getEnvAsInt("MONGO_TIMEOUT", 10),
 		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
 		JWTExpiration: getEnvAsInt("JWT_EXPIRATION", 24), // часы
-		FirebaseKey:   getEnv("FIREBASE_KEY", ""),
-		GoogleMapsKey: getEnv("GOOGLE_MAPS_KEY", ""),
-		SMSProvider:   getEnv("SMS_PROVIDER", ""),
-		SMSKey:        getEnv("SMS_KEY", ""),
-		SMTPHost:      getEnv("SMTP_HOST", ""),
-		SMTPPort:      getEnvAsInt("SMTP_PORT", 587),
-		SMTPUsername:  getEnv("SMTP_USERNAME", ""),
-		SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+
+		TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES"), // формат: 10.0.0.0/8,192.168.1.10
+
+		MongoRetryAttempts:    getEnvAsInt("MONGO_RETRY_ATTEMPTS", 3),
+		MongoRetryBaseDelayMs: getEnvAsInt("MONGO_RETRY_BASE_DELAY_MS", 100),
+		MongoBreakerThreshold: getEnvAsInt("MONGO_BREAKER_THRESHOLD", 20),
+		MongoBreakerOpenSec:   getEnvAsInt("MONGO_BREAKER_OPEN_SEC", 10),
+
+		JWTAccessTTLMinutes: getEnvAsInt("JWT_ACCESS_TTL_MINUTES", 0),
+		RefreshTokenTTLDays: getEnvAsInt("REFRESH_TOKEN_TTL_DAYS", 30),
+		FirebaseKey:         getEnv("FIREBASE_KEY", ""),
+		GoogleMapsKey:       getEnv("GOOGLE_MAPS_KEY", ""),
+		SMSProvider:         getEnv("SMS_PROVIDER", ""),
+		SMSKey:              getEnv("SMS_KEY", ""),
+		SMTPHost:            getEnv("SMTP_HOST", ""),
+		SMTPPort:            getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:        getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:            getEnv("SMTP_FROM", "no-reply@ecity.gov.ua"),
+
+		SMTPBackupHost:     getEnv("SMTP_BACKUP_HOST", ""),
+		SMTPBackupPort:     getEnvAsInt("SMTP_BACKUP_PORT", 587),
+		SMTPBackupUsername: getEnv("SMTP_BACKUP_USERNAME", ""),
+		SMTPBackupPassword: getEnv("SMTP_BACKUP_PASSWORD", ""),
+
+		ProviderBreakerThreshold: getEnvAsInt("PROVIDER_BREAKER_THRESHOLD", 5),
+		ProviderBreakerOpenSec:   getEnvAsInt("PROVIDER_BREAKER_OPEN_SEC", 60),
+
+		DefaultCommunity: getEnv("DEFAULT_COMMUNITY", "nova-kakhovka"),
+		CommunityHosts:   getEnvAsMap("COMMUNITY_HOSTS"), // формат: host1=code1,host2=code2
+
+		DisabledModules: getEnvAsSlice("DISABLED_MODULES"), // формат: transport,petitions
+
+		ChatRateLimit:  getEnvAsInt("CHAT_RATE_LIMIT", 20),
+		ChatRateWindow: getEnvAsInt("CHAT_RATE_WINDOW", 60),
+
+		ExportSaltRotationDays: getEnvAsInt("EXPORT_SALT_ROTATION_DAYS", 90),
+
+		MediaBaseURL: getEnv("MEDIA_BASE_URL", "https://ecity.gov.ua/media"),
+		VideoHosts:   getEnvAsSlice("VIDEO_HOSTS"), // формат: youtube.com,youtu.be; пусто - список по умолчанию
+
+		WSMaxConnectionsPerIP: getEnvAsInt("WS_MAX_CONNECTIONS_PER_IP", 20),
+		WSHandshakesPerMinute: getEnvAsInt("WS_HANDSHAKES_PER_MINUTE", 30),
+		WSChurnBanThreshold:   getEnvAsInt("WS_CHURN_BAN_THRESHOLD", 15),
+		WSBanDurationSec:      getEnvAsInt("WS_BAN_DURATION", 600),
+
+		UserStatusCacheTTLSec:   getEnvAsInt("USER_STATUS_CACHE_TTL", 10),
+		SessionCheckIntervalSec: getEnvAsInt("SESSION_CHECK_INTERVAL", 60),
+
+		IssueDigestHour: getEnvAsInt("ISSUE_DIGEST_HOUR", 18),
+
+		QuietHoursStart:    getEnv("QUIET_HOURS_START", "22:00"),
+		QuietHoursEnd:      getEnv("QUIET_HOURS_END", "07:00"),
+		QuietHoursTimezone: getEnv("QUIET_HOURS_TIMEZONE", "Europe/Kyiv"),
+
+		AtlasStorageLimitMB: getEnvAsInt("ATLAS_STORAGE_LIMIT_MB", 512),
+		StorageWarnPercent:  getEnvAsInt("STORAGE_WARN_PERCENT", 80),
+
+		FileStorageDriver: getEnv("FILE_STORAGE_DRIVER", "local"),
+		FileStorageDir:    getEnv("FILE_STORAGE_DIR", "./uploads"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""), // например, http://minio:9000
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKey:       getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:       getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:       getEnvAsBool("S3_PATH_STYLE", true),
+
+		SignedURLTTLMin: getEnvAsInt("SIGNED_URL_TTL_MIN", 15),
+		AvatarMaxMB:     getEnvAsInt("AVATAR_MAX_MB", 5),
+
+		ChatAttachmentMaxMB: getEnvAsInt("CHAT_ATTACHMENT_MAX_MB", 50),
+
+		DataExportRetentionDays: getEnvAsInt("DATA_EXPORT_RETENTION_DAYS", 7),
+
+		BackupEnabled:     getEnvAsBool("BACKUP_ENABLED", false),
+		BackupHour:        getEnvAsInt("BACKUP_HOUR", 3),
+		BackupRetention:   getEnvAsInt("BACKUP_RETENTION", 14),
+		BackupCollections: getEnvAsSlice("BACKUP_COLLECTIONS"), // формат: users,petitions; пусто - список по умолчанию
+		BackupS3Bucket:    getEnv("BACKUP_S3_BUCKET", ""),
+		BackupMaxAgeHours: getEnvAsInt("BACKUP_MAX_AGE_HOURS", 26),
+
+		CounterReconcileHour: getEnvAsInt("COUNTER_RECONCILE_HOUR", 4),
+
+		CalendarHolidaysDayOff: getEnvAsBool("CALENDAR_HOLIDAYS_DAY_OFF", true),
+
+		TransportIncidentRadius: float64(getEnvAsInt("TRANSPORT_INCIDENT_RADIUS", 150)),
+
+		TimetableFontPath: getEnv("TIMETABLE_FONT_PATH", "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"),
+
+		SMSGatewayURL:        getEnv("SMS_GATEWAY_URL", ""),
+		SMSGatewayToken:      getEnv("SMS_GATEWAY_TOKEN", ""),
+		SMSSender:            getEnv("SMS_SENDER", "eMisto"),
+		PhoneCodeTTLMin:      getEnvAsInt("PHONE_CODE_TTL_MIN", 10),
+		PhoneCodeMaxAttempts: getEnvAsInt("PHONE_CODE_MAX_ATTEMPTS", 5),
+		PhoneCodesPerHour:    getEnvAsInt("PHONE_CODES_PER_HOUR", 3),
+		PhoneCodeResendSec:   getEnvAsInt("PHONE_CODE_RESEND_SEC", 60),
+
+		ResidentRegistryURL:      getEnv("RESIDENT_REGISTRY_URL", ""),
+		ResidentRegistryToken:    getEnv("RESIDENT_REGISTRY_TOKEN", ""),
+		ResidentCardValidityDays: getEnvAsInt("RESIDENT_CARD_VALIDITY_DAYS", 365),
+
+		MunicipalityLatitude:  getEnvAsFloat("MUNICIPALITY_LATITUDE", 46.7546),
+		MunicipalityLongitude: getEnvAsFloat("MUNICIPALITY_LONGITUDE", 33.3486),
+		MunicipalityRadiusKm:  getEnvAsFloat("MUNICIPALITY_RADIUS_KM", 20),
+
+		BatchMaxRequests: getEnvAsInt("BATCH_MAX_REQUESTS", 10),
+
+		SSOAzureTenantID:      getEnv("SSO_AZURE_TENANT_ID", ""),
+		SSOAzureClientID:      getEnv("SSO_AZURE_CLIENT_ID", ""),
+		SSOAzureClientSecret:  getEnv("SSO_AZURE_CLIENT_SECRET", ""),
+		SSOGoogleClientID:     getEnv("SSO_GOOGLE_CLIENT_ID", ""),
+		SSOGoogleClientSecret: getEnv("SSO_GOOGLE_CLIENT_SECRET", ""),
+		SSOAllowedDomains:     getEnvAsSlice("SSO_ALLOWED_DOMAINS"), // формат: nk.gov.ua,rada.nk.gov.ua
+		SSORoleMapping:        getEnvAsMap("SSO_ROLE_MAPPING"),      // формат: group:<id>=ADMIN,role:Moderators=MODERATOR,domain:nk.gov.ua=MODERATOR,email:mayor@nk.gov.ua=ADMIN
+		SSOCallbackBaseURL:    strings.TrimRight(getEnv("SSO_CALLBACK_BASE_URL", "https://api.ecity.gov.ua"), "/"),
+		SSOAppRedirectURLs:    getEnvAsSlice("SSO_APP_REDIRECT_URLS"), // формат: https://admin.ecity.gov.ua/auth/sso
+
+		PaginationLegacyKeys: getEnvAsBool("PAGINATION_LEGACY_KEYS", true),
+
+		AppBaseURL:              getEnv("APP_BASE_URL", "https://ecity.gov.ua"),
+		EmbedAllowedOrigins:     getEnvAsSlice("EMBED_ALLOWED_ORIGINS"), // формат: https://nk.gov.ua,https://rada.nk.gov.ua
+		EmbedTokenTTLHours:      getEnvAsInt("EMBED_TOKEN_TTL_HOURS", 24),
+		EmbedRateLimitPerMinute: getEnvAsInt("EMBED_RATE_LIMIT_PER_MINUTE", 120),
+
+		CompressionMinSize: getEnvAsInt("COMPRESSION_MIN_SIZE", 1024),
+		CompressionLevel:   getEnvAsInt("COMPRESSION_LEVEL", -1),
+
+		GPSProviderURLs:    getEnvAsMap("GPS_PROVIDER_URLS"),   // формат: fleetco=https://api.fleet.example/positions
+		GPSProviderTokens:  getEnvAsMap("GPS_PROVIDER_TOKENS"), // формат: fleetco=token
+		GPSWebhookSecrets:  getEnvAsMap("GPS_WEBHOOK_SECRETS"), // формат: fleetco=secret
+		GPSPollIntervalSec: getEnvAsInt("GPS_POLL_INTERVAL", 15),
+
+		SocialImportIntervalMin: getEnvAsInt("SOCIAL_IMPORT_INTERVAL_MIN", 30),
+		VKServiceToken:          getEnv("VK_SERVICE_TOKEN", ""),
+		FacebookAccessToken:     getEnv("FACEBOOK_ACCESS_TOKEN", ""),
+
+		DraftTTLDays:     getEnvAsInt("DRAFT_TTL_DAYS", 30),
+		DraftWarningDays: getEnvAsInt("DRAFT_WARNING_DAYS", 3),
+
+		SandboxMode: getEnvAsBool("SANDBOX_MODE", false),
+	}
+
+	// По умолчанию ссылки подписываются секретом JWT
+	config.FileURLSecret = getEnv("FILE_URL_SECRET", config.JWTSecret)
+	config.EmbedTokenSecret = getEnv("EMBED_TOKEN_SECRET", config.JWTSecret)
+	config.SSOStateSecret = getEnv("SSO_STATE_SECRET", config.JWTSecret)
+	config.ResidentCardHashKey = getEnv("RESIDENT_CARD_HASH_KEY", config.JWTSecret)
+	config.IdentityHashKey = getEnv("IDENTITY_HASH_KEY", config.JWTSecret)
+
+	if config.SandboxMode {
+		config.disableExternalServices()
 	}
 
 	return config
 }
 
+// disableExternalServices отключает все, что уходит за пределы сервера: в песочнице
+// push, письма и SMS остаются в базе и логах, внешние API не опрашиваются
+func (c *Config) disableExternalServices() {
+	c.FirebaseKey = ""
+	c.SMTPHost = ""
+	c.SMTPBackupHost = ""
+	c.SMSGatewayURL = ""
+	c.ResidentRegistryURL = ""
+	c.VKServiceToken = ""
+	c.FacebookAccessToken = ""
+	c.GPSProviderURLs = map[string]string{}
+	c.BackupEnabled = false
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -83,3 +441,50 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsMap разбирает переменную вида "key1=value1,key2=value2"
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	value := os.Getenv(key)
+	if value == "" {
+		return result
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+		result[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	return result
+}
+
+// getEnvAsSlice разбирает переменную вида "value1,value2"
+func getEnvAsSlice(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
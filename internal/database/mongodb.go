@@ -18,6 +18,7 @@ import (
 type MongoDB struct {
 	Client   *mongo.Client
 	Database *mongo.Database
+	Retrier  *Retrier // Повторы при временных ошибках для коллекций из Collection()
 }
 
 func NewMongoDB(cfg *config.Config) (*MongoDB, error) {
@@ -49,6 +50,13 @@ func NewMongoDB(cfg *config.Config) (*MongoDB, error) {
 	return &MongoDB{
 		Client:   client,
 		Database: database,
+		Retrier: NewRetrier(RetryPolicy{
+			MaxAttempts:      cfg.MongoRetryAttempts,
+			BaseDelay:        time.Duration(cfg.MongoRetryBaseDelayMs) * time.Millisecond,
+			MaxDelay:         2 * time.Second,
+			FailureThreshold: cfg.MongoBreakerThreshold,
+			OpenDuration:     time.Duration(cfg.MongoBreakerOpenSec) * time.Second,
+		}),
 	}, nil
 }
 
@@ -84,6 +92,44 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "created_at", Value: -1}},
 		},
+		{
+			// Очередь заявок на льготный проезд
+			Keys: bson.D{
+				{Key: "fare_concession.status", Value: 1},
+				{Key: "fare_concession.submitted_at", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Очередь проверки карт жителя
+			Keys: bson.D{
+				{Key: "resident_card.status", Value: 1},
+				{Key: "resident_card.submitted_at", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Одна карта жителя - один аккаунт (хэш удаляется при отклонении и отзыве карты)
+			Keys: bson.D{{Key: "resident_card.number_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"resident_card.number_hash": bson.M{"$exists": true},
+			}),
+		},
+		{
+			// Аккаунты сотрудников, привязанные к учетной записи SSO
+			Keys: bson.D{
+				{Key: "sso_provider", Value: 1},
+				{Key: "sso_subject", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"sso_subject": bson.M{"$exists": true},
+			}),
+		},
+		{
+			// Снятие истекших временных блокировок
+			Keys:    bson.D{{Key: "blocked_until", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	if _, err := userCollection.Indexes().CreateMany(ctx, userIndexes); err != nil {
@@ -93,6 +139,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для объявлений
 	announcementCollection := m.Database.Collection("announcements")
 	announcementIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			// Составной индекс для фильтрации по категории
 			Keys: bson.D{
@@ -116,6 +166,18 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 			// Индекс для срока действия
 			Keys: bson.D{{Key: "expires_at", Value: 1}},
 		},
+		{
+			// Очередь модерации модератора с зоной микрорайонов
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "neighborhood", Value: 1},
+			},
+		},
+		{
+			// Уникальный адрес для публичного сайта (документы до появления slug его не имеют)
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
 	}
 
 	if _, err := announcementCollection.Indexes().CreateMany(ctx, announcementIndexes); err != nil {
@@ -125,6 +187,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для событий
 	eventCollection := m.Database.Collection("events")
 	eventIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			// Составной индекс для фильтрации событий по дате
 			Keys: bson.D{
@@ -144,6 +210,18 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 			// Индекс для организатора
 			Keys: bson.D{{Key: "organizer_id", Value: 1}},
 		},
+		{
+			// Очередь модерации модератора с зоной микрорайонов
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "neighborhood", Value: 1},
+			},
+		},
+		{
+			// Уникальный адрес для публичного сайта (документы до появления slug его не имеют)
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
 	}
 
 	if _, err := eventCollection.Indexes().CreateMany(ctx, eventIndexes); err != nil {
@@ -153,6 +231,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для групп
 	groupCollection := m.Database.Collection("groups")
 	groupIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{{Key: "name", Value: 1}},
 		},
@@ -183,15 +265,254 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 			// Индекс для автора
 			Keys: bson.D{{Key: "author_id", Value: 1}},
 		},
+		{
+			// Непрочитанные сообщения группы после last_read_message_id
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			// Сообщения, удержанные до проверки модератором
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"is_held": true}).SetName("held_messages"),
+		},
+		{
+			// Очередь модератора: жалобы и срабатывания фильтра запрещенных слов
+			Keys:    bson.D{{Key: "review.reports", Value: -1}, {Key: "review.flagged_at", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"review": bson.M{"$exists": true}}).SetName("review_queue"),
+		},
+		{
+			// Закрепленные сообщения группы
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "pinned_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"pinned_at": bson.M{"$exists": true}}).SetName("pinned_messages"),
+		},
+		{
+			// Поиск по сообщениям группы. Для украинского в MongoDB нет стемминга, поэтому язык "none":
+			// слова сравниваются целиком без стоп-слов. Поле language в сообщениях не переопределяет язык.
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "content", Value: "text"},
+			},
+			Options: options.Index().
+				SetDefaultLanguage("none").
+				SetLanguageOverride("search_language").
+				SetName("group_content_text"),
+		},
 	}
 
 	if _, err := messageCollection.Indexes().CreateMany(ctx, messageIndexes); err != nil {
 		return fmt.Errorf("ошибка создания индексов для сообщений: %w", err)
 	}
 
+	// Позиции чтения участников групп
+	groupReadStateCollection := m.Database.Collection("group_read_states")
+	groupReadStateIndexes := []mongo.IndexModel{
+		{
+			// Одна позиция на участника группы
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Позиции пользователя для списка его групп
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	}
+
+	if _, err := groupReadStateCollection.Indexes().CreateMany(ctx, groupReadStateIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для позиций чтения: %w", err)
+	}
+
+	// Позиции доставки сообщений групп по сессиям участников (офлайн-очередь WebSocket)
+	groupDeliveryStateCollection := m.Database.Collection("group_delivery_states")
+	groupDeliveryStateIndexes := []mongo.IndexModel{
+		{
+			// Одна позиция на сессию участника группы
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "session_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Удаление позиций пользователя вместе с аккаунтом
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			// Позиция сессии, не подключавшейся 30 дней, удаляется: следующее подключение
+			// начинается с позиции чтения
+			Keys:    bson.D{{Key: "updated_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60),
+		},
+	}
+
+	if _, err := groupDeliveryStateCollection.Indexes().CreateMany(ctx, groupDeliveryStateIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для позиций доставки: %w", err)
+	}
+
+	// Жалобы на сообщения групп
+	messageReportIndexes := []mongo.IndexModel{
+		{
+			// Участник жалуется на сообщение один раз
+			Keys: bson.D{
+				{Key: "message_id", Value: 1},
+				{Key: "reporter_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Удаление жалоб пользователя вместе с аккаунтом
+			Keys: bson.D{{Key: "reporter_id", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("message_reports").Indexes().CreateMany(ctx, messageReportIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для жалоб на сообщения: %w", err)
+	}
+
+	// Внутренние заметки модераторов: обсуждение сущности, упоминания модератора, заметки автора
+	moderatorNoteIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "entity_type", Value: 1},
+				{Key: "entity_id", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "mentions", Value: 1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "author_id", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("moderator_notes").Indexes().CreateMany(ctx, moderatorNoteIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для заметок модераторов: %w", err)
+	}
+
+	// Фильтр запрещенных слов чата: правило для слова одно
+	bannedWordIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "word", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.Database.Collection("banned_words").Indexes().CreateMany(ctx, bannedWordIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для запрещенных слов: %w", err)
+	}
+
+	// Заявки на вступление в группы по приглашению
+	groupJoinRequestCollection := m.Database.Collection("group_join_requests")
+	groupJoinRequestIndexes := []mongo.IndexModel{
+		{
+			// Одна открытая заявка пользователя в группу
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": "pending",
+			}),
+		},
+		{
+			// Список заявок группы для администраторов
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := groupJoinRequestCollection.Indexes().CreateMany(ctx, groupJoinRequestIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для заявок на вступление в группы: %w", err)
+	}
+
+	// Ссылки-приглашения в группы
+	groupInviteCollection := m.Database.Collection("group_invites")
+	groupInviteIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Приглашения группы для администраторов
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			// Истекшие приглашения удаляются через 30 дней
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60),
+		},
+	}
+
+	if _, err := groupInviteCollection.Indexes().CreateMany(ctx, groupInviteIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для приглашений в группы: %w", err)
+	}
+
+	// Создание индексов для личных переписок
+	conversationCollection := m.Database.Collection("conversations")
+	conversationIndexes := []mongo.IndexModel{
+		{
+			// Одна переписка на пару пользователей
+			Keys:    bson.D{{Key: "participant_key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Список переписок пользователя
+			Keys: bson.D{
+				{Key: "participants", Value: 1},
+				{Key: "last_message_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := conversationCollection.Indexes().CreateMany(ctx, conversationIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для переписок: %w", err)
+	}
+
+	directMessageCollection := m.Database.Collection("direct_messages")
+	directMessageIndexes := []mongo.IndexModel{
+		{
+			// История переписки
+			Keys: bson.D{
+				{Key: "conversation_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			// Непрочитанные входящие
+			Keys: bson.D{
+				{Key: "recipient_id", Value: 1},
+				{Key: "read_at", Value: 1},
+			},
+		},
+		{
+			// Обезличивание и выгрузка сообщений автора
+			Keys: bson.D{{Key: "sender_id", Value: 1}},
+		},
+	}
+
+	if _, err := directMessageCollection.Indexes().CreateMany(ctx, directMessageIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для личных сообщений: %w", err)
+	}
+
 	// Создание индексов для петиций
 	petitionCollection := m.Database.Collection("petitions")
 	petitionIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "status", Value: 1},
@@ -208,6 +529,22 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 			// Индекс для подписей
 			Keys: bson.D{{Key: "signatures.user_id", Value: 1}},
 		},
+		{
+			// Индекс для петиций соавтора и приглашений
+			Keys: bson.D{{Key: "co_authors.user_id", Value: 1}},
+		},
+		{
+			// Уникальный адрес для публичного сайта (документы до появления slug его не имеют)
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			// Индекс для поиска заброшенных черновиков
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "updated_at", Value: 1},
+			},
+		},
 	}
 
 	if _, err := petitionCollection.Indexes().CreateMany(ctx, petitionIndexes); err != nil {
@@ -217,6 +554,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для опросов
 	pollCollection := m.Database.Collection("polls")
 	pollIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "status", Value: 1},
@@ -229,15 +570,102 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "category", Value: 1}},
 		},
+		{
+			// Индекс для поиска заброшенных черновиков
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "updated_at", Value: 1},
+			},
+		},
+		{
+			// Очередь модерации опросов (GET /moderation/polls)
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "submitted_at", Value: 1},
+			},
+		},
 	}
 
 	if _, err := pollCollection.Indexes().CreateMany(ctx, pollIndexes); err != nil {
 		return fmt.Errorf("ошибка создания индексов для опросов: %w", err)
 	}
 
+	// Создание индексов для ответов опросов
+	pollResponseCollection := m.Database.Collection("poll_responses")
+	pollResponseIndexes := []mongo.IndexModel{
+		{
+			// Один ответ пользователя в опросе без AllowMultiple
+			Keys: bson.D{
+				{Key: "poll_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"unique_vote": true}),
+		},
+		{
+			// Один ответ личности (Дия/BankID) в опросе VerifiedVotersOnly, с любого из ее аккаунтов
+			Keys: bson.D{
+				{Key: "poll_id", Value: 1},
+				{Key: "identity_hash", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identity_hash": bson.M{"$exists": true}}),
+		},
+		{
+			// Итоги и выгрузка ответов опроса
+			Keys: bson.D{
+				{Key: "poll_id", Value: 1},
+				{Key: "submitted_at", Value: 1},
+			},
+		},
+		{
+			// Ответы пользователя: рекомендации, выгрузка данных и обезличивание
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	}
+
+	if _, err := pollResponseCollection.Indexes().CreateMany(ctx, pollResponseIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для ответов опросов: %w", err)
+	}
+
+	// Создание индексов для карточек опросов (списки GET /polls)
+	pollSummaryCollection := m.Database.Collection("poll_summaries")
+	pollSummaryIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "community_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "creator_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "category", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}},
+		},
+		{
+			// Удаление карточек вместе с устаревшими опросами
+			Keys: bson.D{{Key: "end_date", Value: 1}},
+		},
+	}
+
+	if _, err := pollSummaryCollection.Indexes().CreateMany(ctx, pollSummaryIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для карточек опросов: %w", err)
+	}
+
 	// Создание индексов для городских проблем
 	cityIssueCollection := m.Database.Collection("city_issues")
 	cityIssueIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "status", Value: 1},
@@ -262,6 +690,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для транспортных маршрутов
 	transportRouteCollection := m.Database.Collection("transport_routes")
 	transportRouteIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{{Key: "route_number", Value: 1}},
 		},
@@ -281,6 +713,10 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 	// Создание индексов для транспортных средств
 	transportVehicleCollection := m.Database.Collection("transport_vehicles")
 	transportVehicleIndexes := []mongo.IndexModel{
+		{
+			// Индекс для разделения данных по громадам
+			Keys: bson.D{{Key: "community_id", Value: 1}},
+		},
 		{
 			Keys:    bson.D{{Key: "vehicle_number", Value: 1}},
 			Options: options.Index().SetUnique(true),
@@ -291,6 +727,16 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "current_location", Value: "2dsphere"}},
 		},
+		{
+			// Сопоставление позиций внешнего GPS-провайдера с транспортом
+			Keys: bson.D{
+				{Key: "tracking.provider", Value: 1},
+				{Key: "tracking.external_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"tracking.source": "provider",
+			}),
+		},
 	}
 
 	if _, err := transportVehicleCollection.Indexes().CreateMany(ctx, transportVehicleIndexes); err != nil {
@@ -312,14 +758,58 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 				{Key: "is_read", Value: 1},
 			},
 		},
-	}
-
-	if _, err := notificationCollection.Indexes().CreateMany(ctx, notificationIndexes); err != nil {
-		return fmt.Errorf("ошибка создания индексов для уведомлений: %w", err)
-	}
-
-	// Создание индексов для токенов устройств
-	deviceTokenCollection := m.Database.Collection("device_tokens")
+		{
+			// Индекс для вкладок инбокса
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "category", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			// Очередь push, отложенных при недоступности FCM
+			Keys:    bson.D{{Key: "push_queued_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Повторная доставка намерения outbox не создает уведомлению дубликат
+			Keys: bson.D{
+				{Key: "intent_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"intent_id": bson.M{"$exists": true}}),
+		},
+	}
+
+	if _, err := notificationCollection.Indexes().CreateMany(ctx, notificationIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для уведомлений: %w", err)
+	}
+
+	// Создание индексов для outbox уведомлений
+	notificationOutboxCollection := m.Database.Collection("notification_outbox")
+	notificationOutboxIndexes := []mongo.IndexModel{
+		{
+			// Выборка воркером готовых к доставке намерений
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "next_attempt_at", Value: 1},
+			},
+		},
+		{
+			// Доставленные намерения хранятся неделю
+			Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(7 * 24 * 60 * 60),
+		},
+	}
+
+	if _, err := notificationOutboxCollection.Indexes().CreateMany(ctx, notificationOutboxIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для outbox уведомлений: %w", err)
+	}
+
+	// Создание индексов для токенов устройств
+	deviceTokenCollection := m.Database.Collection("device_tokens")
 	deviceTokenIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{Key: "user_id", Value: 1}},
@@ -334,6 +824,635 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		return fmt.Errorf("ошибка создания индексов для токенов устройств: %w", err)
 	}
 
+	// Создание индексов для громад
+	communityCollection := m.Database.Collection("communities")
+	communityIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "hosts", Value: 1}},
+		},
+	}
+
+	if _, err := communityCollection.Indexes().CreateMany(ctx, communityIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для громад: %w", err)
+	}
+
+	// Индекс для участников громад
+	if _, err := userCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "community_ids", Value: 1}},
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индекса громад пользователей: %w", err)
+	}
+
+	// Создание индексов для очереди писем
+	emailQueueCollection := m.Database.Collection("email_queue")
+	emailQueueIndexes := []mongo.IndexModel{
+		{
+			// Индекс для выборки писем воркером
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "next_attempt_at", Value: 1},
+			},
+		},
+	}
+
+	if _, err := emailQueueCollection.Indexes().CreateMany(ctx, emailQueueIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для очереди писем: %w", err)
+	}
+
+	// Создание индексов для списка блокировки и журнала доставки
+	if _, err := m.Database.Collection("email_suppressions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для списка блокировки писем: %w", err)
+	}
+
+	emailLogIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "message_id", Value: 1}},
+		},
+		{
+			// Журнал доставки хранится 90 дней
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(90 * 24 * 60 * 60),
+		},
+	}
+
+	if _, err := m.Database.Collection("email_delivery_logs").Indexes().CreateMany(ctx, emailLogIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для журнала доставки писем: %w", err)
+	}
+
+	// Создание индексов для push-кампаний и их получателей
+	if _, err := m.Database.Collection("push_campaigns").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "status", Value: 1},
+			{Key: "evaluate_at", Value: 1},
+		},
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для push-кампаний: %w", err)
+	}
+
+	campaignRecipientIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Индекс для выборки получателей раскатки победителя
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+				{Key: "variant", Value: 1},
+				{Key: "sent_at", Value: 1},
+			},
+		},
+	}
+
+	if _, err := m.Database.Collection("campaign_recipients").Indexes().CreateMany(ctx, campaignRecipientIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для получателей кампаний: %w", err)
+	}
+
+	moderationActionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "decided_at", Value: -1}},
+		},
+		{
+			// Индекс для статистики по модераторам за период
+			Keys: bson.D{
+				{Key: "moderator_id", Value: 1},
+				{Key: "decided_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := m.Database.Collection("moderation_actions").Indexes().CreateMany(ctx, moderationActionIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для журнала модерации: %w", err)
+	}
+
+	chatUploadIndexes := []mongo.IndexModel{
+		{
+			// Индекс для вложений сообщения
+			Keys: bson.D{{Key: "message_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			// Индекс для удаления неиспользованных загрузок
+			Keys: bson.D{{Key: "created_at", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("chat_uploads").Indexes().CreateMany(ctx, chatUploadIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для вложений чата: %w", err)
+	}
+
+	auditLogIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "community_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			// Индекс для истории одного ресурса
+			Keys: bson.D{
+				{Key: "resource_type", Value: 1},
+				{Key: "resource_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "action", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := m.Database.Collection("audit_logs").Indexes().CreateMany(ctx, auditLogIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для журнала аудита: %w", err)
+	}
+
+	faqArticleIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "community_id", Value: 1},
+				{Key: "category_id", Value: 1},
+				{Key: "is_published", Value: 1},
+				{Key: "sort_order", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "keywords", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("faq_articles").Indexes().CreateMany(ctx, faqArticleIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для статей FAQ: %w", err)
+	}
+
+	// Один отзыв на статью от пользователя (или клиента)
+	faqFeedbackIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "article_id", Value: 1},
+				{Key: "voter_key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.Database.Collection("faq_feedback").Indexes().CreateMany(ctx, faqFeedbackIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для отзывов FAQ: %w", err)
+	}
+
+	// Поиск активной соли псевдонимизации выгрузок
+	exportSaltIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "expires_at", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	if _, err := m.Database.Collection("export_salts").Indexes().CreateMany(ctx, exportSaltIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для соли выгрузок: %w", err)
+	}
+
+	// Код категории уникален в пределах модуля
+	taxonomyIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "module", Value: 1},
+				{Key: "code", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.Database.Collection("taxonomies").Indexes().CreateMany(ctx, taxonomyIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для таксономий: %w", err)
+	}
+
+	// Теги: уникальное каноническое имя, поиск по алиасам и популярности
+	tagIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "aliases", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "usage_count", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("tags").Indexes().CreateMany(ctx, tagIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для тегов: %w", err)
+	}
+
+	// Популярные теги считаются по свежему контенту модулей
+	for _, collection := range []string{"petitions", "polls", "events"} {
+		if _, err := m.Database.Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "tags", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		}); err != nil {
+			return fmt.Errorf("ошибка создания индекса тегов для %s: %w", collection, err)
+		}
+	}
+
+	// Заведения образования: фильтр по типу и микрорайону
+	if _, err := m.Database.Collection("education_institutions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "community_id", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "neighborhood", Value: 1},
+		},
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для учебных заведений: %w", err)
+	}
+
+	// Наборы: публичный список по статусу и датам, фильтр по микрорайонам
+	enrollmentIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "community_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "start_date", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "neighborhoods", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("enrollments").Indexes().CreateMany(ctx, enrollmentIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для наборов: %w", err)
+	}
+
+	// Подписки на наборы: одна на пользователя в общине
+	if _, err := m.Database.Collection("enrollment_subscriptions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+			{Key: "community_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для подписок на наборы: %w", err)
+	}
+
+	// Общественные обсуждения: публичный список по статусу и датам
+	if _, err := m.Database.Collection("consultations").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "community_id", Value: 1},
+			{Key: "status", Value: 1},
+			{Key: "start_date", Value: -1},
+		},
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для обсуждений: %w", err)
+	}
+
+	// Комментарии к обсуждениям: по разделам и по решению модератора
+	consultationCommentIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "consultation_id", Value: 1},
+				{Key: "section_id", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "consultation_id", Value: 1},
+				{Key: "resolution", Value: 1},
+			},
+		},
+	}
+
+	if _, err := m.Database.Collection("consultation_comments").Indexes().CreateMany(ctx, consultationCommentIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для комментариев обсуждений: %w", err)
+	}
+
+	// Активность пользователей: одна запись на пользователя и неделю, выборка когорт по неделе
+	userActivityIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "week_start", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "week_start", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("user_activity").Indexes().CreateMany(ctx, userActivityIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для активности пользователей: %w", err)
+	}
+
+	// Сегменты пользователей: уникальное название
+	if _, err := m.Database.Collection("user_segments").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для сегментов: %w", err)
+	}
+
+	// Версии текста петиций и объявлений: номер версии уникален в пределах сущности
+	if _, err := m.Database.Collection("content_revisions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "entity_type", Value: 1},
+			{Key: "entity_id", Value: 1},
+			{Key: "version", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для версий контента: %w", err)
+	}
+
+	// Refresh-токены: поиск по хешу, отзыв по пользователю и цепочке, удаление истекших
+	refreshTokenIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "family_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	if _, err := m.Database.Collection("refresh_tokens").Indexes().CreateMany(ctx, refreshTokenIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для refresh-токенов: %w", err)
+	}
+
+	// Сессии: список входов пользователя; истекшие удаляются по expires_at
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	if _, err := m.Database.Collection("sessions").Indexes().CreateMany(ctx, sessionIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для сессий: %w", err)
+	}
+
+	// Дайджест проблем: выборка записей, еще не забранных рассылкой
+	if _, err := m.Database.Collection("issue_digest_items").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "batch_id", Value: 1}, {Key: "user_id", Value: 1}},
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для дайджеста проблем: %w", err)
+	}
+
+	// Календарь громады: один объявленный день на дату
+	if _, err := m.Database.Collection("calendar_days").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "community_id", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для календаря: %w", err)
+	}
+
+	// Пороги подписей петиций: одно правило на категорию громады
+	if _, err := m.Database.Collection("petition_thresholds").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "community_id", Value: 1}, {Key: "category", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для порогов петиций: %w", err)
+	}
+
+	// Связанные материалы: одна подборка на материал
+	if _, err := m.Database.Collection("related_content").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "module", Value: 1}, {Key: "item_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return fmt.Errorf("ошибка создания индексов для связанных материалов: %w", err)
+	}
+
+	// Объявления о изменении движения: публичный список по маршруту, чернетки для администраторов
+	transportAlertIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "route_ids", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("transport_alerts").Indexes().CreateMany(ctx, transportAlertIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для объявлений транспорта: %w", err)
+	}
+
+	// Удаление аккаунтов: очередь фоновой обработки
+	deletionIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "requested_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("account_deletions").Indexes().CreateMany(ctx, deletionIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для удаления аккаунтов: %w", err)
+	}
+
+	// Выгрузки данных пользователей: очередь, последний запрос пользователя, просроченные архивы
+	dataExportIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "requested_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "requested_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("data_exports").Indexes().CreateMany(ctx, dataExportIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для выгрузок данных: %w", err)
+	}
+
+	// Ключи интеграций: поиск по хэшу при каждом запросе, список ключей пользователя
+	apiKeyIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("api_keys").Indexes().CreateMany(ctx, apiKeyIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для ключей интеграций: %w", err)
+	}
+
+	// Резервные копии: одновременно выполняется только одна копия, плановая копия -
+	// одна за день на все экземпляры сервера; история и ротация по времени запуска
+	backupIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": "running",
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "slot", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"slot": bson.M{"$exists": true},
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "started_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("backups").Indexes().CreateMany(ctx, backupIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для резервных копий: %w", err)
+	}
+
+	// Сверки счетчиков: одновременно выполняется одна сверка, плановая - одна за день
+	// на все экземпляры сервера; история по времени запуска
+	counterReconciliationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": "running",
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "slot", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"slot": bson.M{"$exists": true},
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "started_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("counter_reconciliations").Indexes().CreateMany(ctx, counterReconciliationIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для сверок счетчиков: %w", err)
+	}
+
+	// Коды подтверждения телефона: лимит запросов на номер, поиск действующего кода,
+	// записи удаляются через сутки
+	phoneCodeIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "phone", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "phone", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60),
+		},
+	}
+
+	if _, err := m.Database.Collection("phone_codes").Indexes().CreateMany(ctx, phoneCodeIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для кодов подтверждения телефона: %w", err)
+	}
+
+	// Банеры: выборка действующих банеров громады и список в админке
+	bannerIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "is_active", Value: 1}, {Key: "ends_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "community_id", Value: 1}, {Key: "priority", Value: -1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("banners").Indexes().CreateMany(ctx, bannerIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для банеров: %w", err)
+	}
+
+	// Снимки использования хранилища: поиск базового снимка для прироста, хранение около года
+	storageSnapshotIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "taken_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(400 * 24 * 60 * 60),
+		},
+	}
+
+	if _, err := m.Database.Collection("storage_snapshots").Indexes().CreateMany(ctx, storageSnapshotIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для снимков хранилища: %w", err)
+	}
+
+	// История статистики платформы: один снимок в день, хранится бессрочно для сравнения год к году
+	statsHistoryIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "date", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	if _, err := m.Database.Collection("stats_history").Indexes().CreateMany(ctx, statsHistoryIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для истории статистики: %w", err)
+	}
+
+	// Каналы импорта: канал подключается к громаде один раз, воркер выбирает каналы по next_fetch_at
+	socialFeedIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "community_id", Value: 1}, {Key: "platform", Value: 1}, {Key: "channel", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "is_active", Value: 1}, {Key: "next_fetch_at", Value: 1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("social_feeds").Indexes().CreateMany(ctx, socialFeedIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для каналов импорта: %w", err)
+	}
+
+	// Журнал импорта: пост обрабатывается один раз, кросспосты находятся по хешу текста
+	socialImportIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "platform", Value: 1}, {Key: "post_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "content_hash", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "feed_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	if _, err := m.Database.Collection("social_imports").Indexes().CreateMany(ctx, socialImportIndexes); err != nil {
+		return fmt.Errorf("ошибка создания индексов для журнала импорта: %w", err)
+	}
+
 	log.Println("✅ Индексы успешно созданы для всех коллекций")
 	return nil
 }
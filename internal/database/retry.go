@@ -0,0 +1,292 @@
+// internal/database/retry.go
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCircuitOpen - база недоступна, запросы отклоняются без обращения к ней до конца паузы
+var ErrCircuitOpen = errors.New("mongodb circuit breaker is open")
+
+// Состояния автомата
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// transientErrorCodes - коды ошибок сервера, после которых повтор обычно проходит:
+// смена primary, перезапуск узла, обрыв соединения (см. коды ошибок MongoDB)
+var transientErrorCodes = map[int]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	262:   true, // ExceededTimeLimit
+	9001:  true, // SocketException
+	10107: true, // NotWritablePrimary
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotPrimaryNoSecondaryOk
+	13436: true, // NotPrimaryOrSecondary
+}
+
+// RetryPolicy - повторы с экспоненциальной задержкой и автомат отключения (circuit breaker).
+// Драйвер сам повторяет операцию один раз (retryReads/retryWrites); обертка переживает
+// более долгие сбои, например выборы нового primary в Atlas (до ~10-15 секунд).
+type RetryPolicy struct {
+	MaxAttempts int           // Всего попыток, включая первую
+	BaseDelay   time.Duration // Задержка перед первым повтором, дальше удваивается
+	MaxDelay    time.Duration
+
+	// Столько временных ошибок подряд размыкают автомат на OpenDuration
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+// RetryStats - метрики повторов и автомата отключения с момента запуска
+type RetryStats struct {
+	State             string     `json:"state"`
+	Calls             int64      `json:"calls"`
+	Retries           int64      `json:"retries"`
+	Recovered         int64      `json:"recovered"`          // Успех после одного или нескольких повторов
+	GaveUp            int64      `json:"gave_up"`            // Временная ошибка не прошла за все попытки
+	ShortCircuited    int64      `json:"short_circuited"`    // Отклонены разомкнутым автоматом
+	CircuitOpenings   int64      `json:"circuit_openings"`   // Сколько раз автомат размыкался
+	ConsecutiveErrors int        `json:"consecutive_errors"` // Временных ошибок подряд сейчас
+	OpenedAt          *time.Time `json:"opened_at,omitempty"`
+}
+
+// Retrier выполняет операции с базой с повторами при временных ошибках.
+// Один экземпляр на подключение: автомат отражает состояние кластера целиком.
+type Retrier struct {
+	policy RetryPolicy
+
+	calls, retries, recovered, gaveUp, shortCircuited, openings atomic.Int64
+
+	mu                sync.Mutex
+	consecutiveErrors int
+	openedAt          time.Time
+	probing           bool // В полуоткрытом состоянии пропускается один пробный запрос
+}
+
+func NewRetrier(policy RetryPolicy) *Retrier {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &Retrier{policy: policy}
+}
+
+// IsTransient - ошибка временная, и повтор той же операции безопасен и может пройти
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) && (labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError")) {
+		return true
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for code := range transientErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Do выполняет fn, повторяя ее при временных ошибках. fn должна быть идемпотентной.
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.calls.Add(1)
+	if !r.allow() {
+		r.shortCircuited.Add(1)
+		return ErrCircuitOpen
+	}
+
+	delay := r.policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err != nil && ctx.Err() != nil {
+			// Время запроса вышло: о доступности базы это ничего не говорит
+			r.releaseProbe()
+			return err
+		}
+		if !IsTransient(err) {
+			// Успех и постоянные ошибки (дубликат ключа, валидация) говорят о доступности базы
+			r.recordSuccess()
+			if err == nil && attempt > 1 {
+				r.recovered.Add(1)
+			}
+			return err
+		}
+
+		if r.recordFailure() || attempt >= r.policy.MaxAttempts {
+			r.gaveUp.Add(1)
+			return err
+		}
+
+		// Полная случайная задержка (full jitter), чтобы экземпляры сервера не повторяли синхронно
+		wait := time.Duration(rand.Int63n(int64(delay) + 1))
+		select {
+		case <-ctx.Done():
+			r.gaveUp.Add(1)
+			return err
+		case <-time.After(wait):
+		}
+		r.retries.Add(1)
+
+		delay *= 2
+		if r.policy.MaxDelay > 0 && delay > r.policy.MaxDelay {
+			delay = r.policy.MaxDelay
+		}
+	}
+}
+
+// allow - можно ли обратиться к базе с учетом состояния автомата
+func (r *Retrier) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.openedAt.IsZero() {
+		return true
+	}
+	if time.Since(r.openedAt) < r.policy.OpenDuration || r.probing {
+		return false
+	}
+	r.probing = true
+	return true
+}
+
+func (r *Retrier) recordSuccess() {
+	r.mu.Lock()
+	r.consecutiveErrors = 0
+	r.openedAt = time.Time{}
+	r.probing = false
+	r.mu.Unlock()
+}
+
+// releaseProbe позволяет следующему запросу стать пробным, не меняя состояние автомата
+func (r *Retrier) releaseProbe() {
+	r.mu.Lock()
+	r.probing = false
+	r.mu.Unlock()
+}
+
+// recordFailure учитывает временную ошибку; true - автомат разомкнут и повторять не нужно
+func (r *Retrier) recordFailure() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.consecutiveErrors++
+	if r.probing {
+		// Пробный запрос не прошел: пауза начинается заново
+		r.openedAt = time.Now()
+		r.probing = false
+		r.openings.Add(1)
+		return true
+	}
+	if r.policy.FailureThreshold > 0 && r.openedAt.IsZero() && r.consecutiveErrors >= r.policy.FailureThreshold {
+		r.openedAt = time.Now()
+		r.openings.Add(1)
+		return true
+	}
+	return false
+}
+
+// Stats - текущие метрики повторов
+func (r *Retrier) Stats() RetryStats {
+	stats := RetryStats{
+		State:           CircuitClosed,
+		Calls:           r.calls.Load(),
+		Retries:         r.retries.Load(),
+		Recovered:       r.recovered.Load(),
+		GaveUp:          r.gaveUp.Load(),
+		ShortCircuited:  r.shortCircuited.Load(),
+		CircuitOpenings: r.openings.Load(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats.ConsecutiveErrors = r.consecutiveErrors
+	if !r.openedAt.IsZero() {
+		openedAt := r.openedAt
+		stats.OpenedAt = &openedAt
+		stats.State = CircuitOpen
+		if r.probing || time.Since(r.openedAt) >= r.policy.OpenDuration {
+			stats.State = CircuitHalfOpen
+		}
+	}
+	return stats
+}
+
+// RetryCollection - коллекция, чтения которой повторяются при временных ошибках.
+// Записи повторяются только через методы, безопасные для повтора.
+type RetryCollection struct {
+	*mongo.Collection
+	retrier *Retrier
+}
+
+// Collection - коллекция с повторами через общий Retrier подключения
+func (m *MongoDB) Collection(name string) *RetryCollection {
+	return &RetryCollection{Collection: m.Database.Collection(name), retrier: m.Retrier}
+}
+
+// FindOne с повторами; ErrNoDocuments не считается ошибкой базы
+func (c *RetryCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	var result *mongo.SingleResult
+	err := c.retrier.Do(ctx, func(ctx context.Context) error {
+		result = c.Collection.FindOne(ctx, filter, opts...)
+		return result.Err()
+	})
+	if errors.Is(err, ErrCircuitOpen) {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return result
+}
+
+// Find с повторами открытия курсора; ошибки при чтении следующих пакетов не повторяются
+func (c *RetryCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	var cursor *mongo.Cursor
+	err := c.retrier.Do(ctx, func(ctx context.Context) (err error) {
+		cursor, err = c.Collection.Find(ctx, filter, opts...)
+		return err
+	})
+	return cursor, err
+}
+
+func (c *RetryCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	var count int64
+	err := c.retrier.Do(ctx, func(ctx context.Context) (err error) {
+		count, err = c.Collection.CountDocuments(ctx, filter, opts...)
+		return err
+	})
+	return count, err
+}
+
+// UpdateOneIdempotent повторяет обновление, повтор которого дает тот же результат:
+// только $set/$unset/$addToSet/$pull со значениями, вычисленными заранее (не $inc, не $push)
+func (c *RetryCollection) UpdateOneIdempotent(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	var result *mongo.UpdateResult
+	err := c.retrier.Do(ctx, func(ctx context.Context) (err error) {
+		result, err = c.Collection.UpdateOne(ctx, filter, update, opts...)
+		return err
+	})
+	return result, err
+}
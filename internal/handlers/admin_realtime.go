@@ -0,0 +1,292 @@
+// internal/handlers/admin_realtime.go
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+	"nova-kakhovka-ecity/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Як часто перераховуються лічильники адмін-панелі
+const adminCountersInterval = 5 * time.Second
+
+// AdminCounters - оперативні показники для адмін-панелі
+type AdminCounters struct {
+	OnlineUsers       int                       `json:"online_users"`         // Користувачі з відкритим WebSocket
+	NewIssuesLastHour int64                     `json:"new_issues_last_hour"` // Нові проблеми міста за годину
+	NotificationQueue AdminNotificationQueue    `json:"notification_queue"`
+	ActiveVehicles    int64                     `json:"active_vehicles"` // Транспорт на лінії з позицією за 5 хв
+	Providers         []services.ProviderHealth `json:"providers"`       // Стан FCM та SMTP провайдерів
+	GeneratedAt       time.Time                 `json:"generated_at"`
+}
+
+// AdminNotificationQueue - глибина черг доставки
+type AdminNotificationQueue struct {
+	Push  int64 `json:"push"`  // Сповіщення, ще не відправлені в FCM (разом з відкладеними)
+	Email int64 `json:"email"` // Листи в черзі або на відправці
+}
+
+// AdminRealtimeHandler транслює лічильники адмін-панелі по WebSocket,
+// щоб панель не опитувала кілька endpoints кожні кілька секунд.
+// Лічильники рахуються один раз за інтервал для всіх підключених адміністраторів.
+type AdminRealtimeHandler struct {
+	jwtManager             *auth.JWTManager
+	wsHandler              *WebSocketHandler
+	issueCollection        *mongo.Collection
+	notificationCollection *mongo.Collection
+	emailQueueCollection   *mongo.Collection
+	vehicleCollection      *mongo.Collection
+	providers              []services.ProviderHealthSource
+
+	mu          sync.RWMutex
+	subscribers map[chan []byte]struct{}
+}
+
+// NewAdminRealtimeHandler створює обробник realtime-лічильників
+func NewAdminRealtimeHandler(jwtManager *auth.JWTManager, wsHandler *WebSocketHandler, issueCollection, notificationCollection, emailQueueCollection, vehicleCollection *mongo.Collection, providers ...services.ProviderHealthSource) *AdminRealtimeHandler {
+	return &AdminRealtimeHandler{
+		jwtManager:             jwtManager,
+		wsHandler:              wsHandler,
+		issueCollection:        issueCollection,
+		notificationCollection: notificationCollection,
+		emailQueueCollection:   emailQueueCollection,
+		vehicleCollection:      vehicleCollection,
+		providers:              providers,
+		subscribers:            make(map[chan []byte]struct{}),
+	}
+}
+
+// Start запускає перерахунок лічильників. Поки немає підключень, запити до БД не виконуються.
+func (h *AdminRealtimeHandler) Start() {
+	ticker := time.NewTicker(adminCountersInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.mu.RLock()
+		idle := len(h.subscribers) == 0
+		h.mu.RUnlock()
+		if idle {
+			continue
+		}
+		h.refresh()
+	}
+}
+
+// refresh рахує лічильники та розсилає їх усім підключеним адміністраторам
+func (h *AdminRealtimeHandler) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), adminCountersInterval)
+	defer cancel()
+
+	payload, err := json.Marshal(WSMessage{Type: "admin_counters", Data: h.collect(ctx)})
+	if err != nil {
+		log.Printf("Error marshaling admin counters: %v", err)
+		return
+	}
+	h.publish(payload)
+}
+
+// GetCounters - GET /admin/realtime/counters - поточний знімок без WebSocket
+func (h *AdminRealtimeHandler) GetCounters(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c.JSON(http.StatusOK, h.collect(ctx))
+}
+
+// HandleWebSocket - ws://host/ws/admin?token=<JWT адміністратора>
+func (h *AdminRealtimeHandler) HandleWebSocket(c *gin.Context) {
+	// Той самий захист від перепідключень, що й для чату
+	guard := h.wsHandler.connectionGuard
+	ip := c.ClientIP()
+	if err := guard.Admit(ip); err != nil {
+		respondConnectionLimited(c, err)
+		return
+	}
+
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token is required",
+		})
+		return
+	}
+
+	claims, err := h.jwtManager.ValidateToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid token",
+		})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid token",
+		})
+		return
+	}
+
+	// Роль з бази, а не з токена: розжалуваний адміністратор втрачає доступ одразу
+	status, ok := h.wsHandler.checkAccount(c, userID, claims)
+	if !ok {
+		return
+	}
+
+	role := status.Role
+	if !role.IsValid() || !role.IsHigherOrEqual(models.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":         "Insufficient permissions",
+			"required_role": string(models.RoleAdmin),
+		})
+		return
+	}
+
+	if err := guard.Acquire(ip); err != nil {
+		respondConnectionLimited(c, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		guard.Release(ip)
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	send := h.subscribe()
+	go h.readLoop(conn, send, ip)
+	go h.writeLoop(conn, send)
+
+	// Нове підключення отримує свіжі лічильники, не чекаючи наступного тіку
+	go h.refresh()
+}
+
+// collect рахує лічильники; помилка окремого запиту не зупиняє решту
+func (h *AdminRealtimeHandler) collect(ctx context.Context) AdminCounters {
+	now := time.Now()
+	counters := AdminCounters{
+		OnlineUsers: h.wsHandler.OnlineUserCount(),
+		Providers:   services.CollectProviderHealth(h.providers...),
+		GeneratedAt: now,
+	}
+
+	count := func(collection *mongo.Collection, filter bson.M, name string) int64 {
+		n, err := collection.CountDocuments(ctx, filter)
+		if err != nil {
+			log.Printf("Error counting %s for admin dashboard: %v", name, err)
+		}
+		return n
+	}
+
+	counters.NewIssuesLastHour = count(h.issueCollection, bson.M{
+		"created_at": bson.M{"$gte": now.Add(-time.Hour)},
+	}, "new issues")
+	counters.NotificationQueue.Push = count(h.notificationCollection, bson.M{
+		"is_sent": false,
+	}, "push queue")
+	counters.NotificationQueue.Email = count(h.emailQueueCollection, bson.M{
+		"status": bson.M{"$in": []string{models.EmailStatusPending, models.EmailStatusSending}},
+	}, "email queue")
+	counters.ActiveVehicles = count(h.vehicleCollection, bson.M{
+		"status":      models.VehicleStatusActive,
+		"is_tracked":  true,
+		"last_update": bson.M{"$gte": now.Add(-5 * time.Minute)},
+	}, "active vehicles")
+
+	return counters
+}
+
+func (h *AdminRealtimeHandler) subscribe() chan []byte {
+	send := make(chan []byte, 8)
+	h.mu.Lock()
+	h.subscribers[send] = struct{}{}
+	h.mu.Unlock()
+	return send
+}
+
+func (h *AdminRealtimeHandler) unsubscribe(send chan []byte) {
+	h.mu.Lock()
+	if _, ok := h.subscribers[send]; ok {
+		delete(h.subscribers, send)
+		close(send)
+	}
+	h.mu.Unlock()
+}
+
+// publish розсилає знімок усім підключенням; повільне підключення пропускає знімок, а не блокує інших
+func (h *AdminRealtimeHandler) publish(payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for send := range h.subscribers {
+		select {
+		case send <- payload:
+		default:
+		}
+	}
+}
+
+// readLoop лише підтримує pong та виявляє закриття з'єднання - панель нічого не надсилає
+func (h *AdminRealtimeHandler) readLoop(conn *websocket.Conn, send chan []byte, ip string) {
+	defer func() {
+		h.unsubscribe(send)
+		conn.Close()
+		h.wsHandler.connectionGuard.Release(ip)
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *AdminRealtimeHandler) writeLoop(conn *websocket.Conn, send chan []byte) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -3,11 +3,16 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,28 +24,42 @@ import (
 type AnnouncementHandler struct {
 	announcementCollection *mongo.Collection
 	userCollection         *mongo.Collection
+	trustService           *services.TrustService
+	moderationLog          *services.ModerationLogService
+	taxonomyService        *services.TaxonomyService
+	revisionService        *services.RevisionService
+	relatedService         *services.RelatedContentService
 }
 
 type CreateAnnouncementRequest struct {
-	Title       string               `json:"title" validate:"required,min=5,max=200"`
-	Description string               `json:"description" validate:"required,min=10,max=2000"`
-	Category    string               `json:"category" validate:"required,oneof=work help services housing transport"`
-	Location    models.Location      `json:"location"`
-	Address     string               `json:"address"`
-	Employment  string               `json:"employment" validate:"oneof=once permanent partial"`
-	ContactInfo []models.ContactInfo `json:"contact_info" validate:"required,min=1"`
-	MediaFiles  []string             `json:"media_files"`
-	ExpiresAt   time.Time            `json:"expires_at"`
+	Title        string                 `json:"title" validate:"required,min=5,max=200"`
+	Description  string                 `json:"description" validate:"required,min=10,max=2000"`
+	Category     string                 `json:"category" validate:"required"` // Код із таксономії модуля announcements
+	Location     models.Location        `json:"location"`
+	Address      string                 `json:"address"`
+	Neighborhood string                 `json:"neighborhood"` // Микрорайон, до 100 символов
+	Employment   string                 `json:"employment" validate:"oneof=once permanent partial"`
+	ContactInfo  []models.ContactInfo   `json:"contact_info" validate:"required,min=1"`
+	MediaFiles   []string               `json:"media_files"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	Attributes   map[string]interface{} `json:"attributes"` // Поля категории, см. GET /announcements/attributes
+	// Необязательные plain_text_body и pronunciations; названия из словаря ударений добавляются автоматически
+	models.SpeechHints
 }
 
 type UpdateAnnouncementRequest struct {
-	Title       string               `json:"title,omitempty" validate:"omitempty,min=5,max=200"`
-	Description string               `json:"description,omitempty" validate:"omitempty,min=10,max=2000"`
-	Address     string               `json:"address,omitempty"`
-	Employment  string               `json:"employment,omitempty" validate:"omitempty,oneof=once permanent partial"`
-	ContactInfo []models.ContactInfo `json:"contact_info,omitempty"`
-	MediaFiles  []string             `json:"media_files,omitempty"`
-	IsActive    *bool                `json:"is_active,omitempty"`
+	Title        string                 `json:"title,omitempty" validate:"omitempty,min=5,max=200"`
+	Description  string                 `json:"description,omitempty" validate:"omitempty,min=10,max=2000"`
+	Category     string                 `json:"category,omitempty"`
+	Address      string                 `json:"address,omitempty"`
+	Neighborhood string                 `json:"neighborhood,omitempty"`
+	Employment   string                 `json:"employment,omitempty" validate:"omitempty,oneof=once permanent partial"`
+	ContactInfo  []models.ContactInfo   `json:"contact_info,omitempty"`
+	MediaFiles   []string               `json:"media_files,omitempty"`
+	IsActive     *bool                  `json:"is_active,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"` // Заменяет поля категории целиком
+	// pronunciations заменяет подсказки автора целиком, пустой список удаляет их
+	models.SpeechHints
 }
 
 type AnnouncementFilters struct {
@@ -55,10 +74,15 @@ type AnnouncementFilters struct {
 	SortOrder   string    `form:"sort_order"` // asc, desc
 }
 
-func NewAnnouncementHandler(announcementCollection, userCollection *mongo.Collection) *AnnouncementHandler {
+func NewAnnouncementHandler(announcementCollection, userCollection *mongo.Collection, trustService *services.TrustService, moderationLog *services.ModerationLogService, taxonomyService *services.TaxonomyService, revisionService *services.RevisionService, relatedService *services.RelatedContentService) *AnnouncementHandler {
 	return &AnnouncementHandler{
 		announcementCollection: announcementCollection,
 		userCollection:         userCollection,
+		trustService:           trustService,
+		moderationLog:          moderationLog,
+		taxonomyService:        taxonomyService,
+		revisionService:        revisionService,
+		relatedService:         relatedService,
 	}
 }
 
@@ -71,6 +95,25 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 		})
 		return
 	}
+	if !validateCategory(c, h.taxonomyService, models.ModuleAnnouncements, req.Category) {
+		return
+	}
+	attributes, ok := validateAnnouncementAttributes(c, req.Category, req.Employment, req.Attributes)
+	if !ok {
+		return
+	}
+	neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+	if !ok {
+		return
+	}
+	speech, err := services.NormalizeSpeechHints(req.SpeechHints)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid speech hints",
+			"details": err.Error(),
+		})
+		return
+	}
 
 	userID, _ := c.Get("user_id")
 	userIDObj, err := primitive.ObjectIDFromHex(userID.(string))
@@ -97,9 +140,12 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 		return
 	}
 
-	if activeCount >= 5 { // Лимит 5 активных объявлений
+	// Лимит активных объявлений зависит от уровня доверия к аккаунту (5 для проверенных)
+	quota := h.trustService.Quota(ctx, userIDObj)
+	if activeCount >= int64(quota.MaxActiveAnnouncements) {
 		c.JSON(http.StatusTooManyRequests, gin.H{
 			"error": "Too many active announcements. Please wait for some to expire or delete them.",
+			"limit": quota.MaxActiveAnnouncements,
 		})
 		return
 	}
@@ -111,13 +157,17 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 
 	now := time.Now()
 	announcement := models.Announcement{
+		CommunityID:   getCommunityID(c),
 		AuthorID:      userIDObj,
 		Title:         req.Title,
 		Description:   req.Description,
+		Language:      services.DetectLanguage(req.Title, req.Description),
 		Category:      req.Category,
 		Location:      req.Location,
 		Address:       req.Address,
+		Neighborhood:  neighborhood,
 		Employment:    req.Employment,
+		Attributes:    attributes,
 		ContactInfo:   req.ContactInfo,
 		MediaFiles:    req.MediaFiles,
 		IsActive:      true,
@@ -129,8 +179,11 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 		UpdatedAt:     now,
 		ExpiresAt:     req.ExpiresAt,
 	}
+	announcement.SpeechHints = services.AnnouncementSpeechHints(announcement, speech)
 
-	result, err := h.announcementCollection.InsertOne(ctx, announcement)
+	// ID назначается заранее: он входит в slug
+	announcement.ID = primitive.NewObjectID()
+	announcement.Slug, err = services.UniqueSlug(ctx, h.announcementCollection, announcement.Title, "announcement", announcement.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creating announcement",
@@ -138,7 +191,12 @@ func (h *AnnouncementHandler) CreateAnnouncement(c *gin.Context) {
 		return
 	}
 
-	announcement.ID = result.InsertedID.(primitive.ObjectID)
+	if _, err := h.announcementCollection.InsertOne(ctx, announcement); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating announcement",
+		})
+		return
+	}
 	c.JSON(http.StatusCreated, announcement)
 }
 
@@ -165,24 +223,45 @@ func (h *AnnouncementHandler) GetAnnouncements(c *gin.Context) {
 	defer cancel()
 
 	// Построение фильтра запроса
-	query := bson.M{
+	query := communityScope(c, bson.M{
 		"is_active":  true,
 		"expires_at": bson.M{"$gt": time.Now()},
-	}
+	})
 
 	// Показываем только верифицированные объявления обычным пользователям
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	if !middleware.UserHasPermission(c, models.PermissionModerateAnnouncement) {
 		query["is_verified"] = true
 		query["status"] = "approved"
+	} else if scope := moderationScope(c); scope != nil {
+		// Модератор с зоной видит непроверенные объявления только своей зоны
+		query["$or"] = []bson.M{
+			{"is_verified": true, "status": "approved"},
+			moderationScopeCondition(scope),
+		}
 	}
 
 	if filters.Category != "" {
 		query["category"] = filters.Category
 	}
+
+	// Фильтры по полям категории: attr.rooms=2, attr.price_max=8000, attr.salary_period=month
+	attributeQuery, err := announcementAttributeFilters(filters.Category, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid attribute filter",
+			"details": err.Error(),
+		})
+		return
+	}
+	for field, condition := range attributeQuery {
+		query[field] = condition
+	}
 	if filters.Employment != "" {
 		query["employment"] = filters.Employment
 	}
+	if !applyLanguageFilter(c, query) {
+		return
+	}
 	if !filters.CreatedFrom.IsZero() || !filters.CreatedTo.IsZero() {
 		dateQuery := bson.M{}
 		if !filters.CreatedFrom.IsZero() {
@@ -232,21 +311,14 @@ func (h *AnnouncementHandler) GetAnnouncements(c *gin.Context) {
 	// Подсчет общего количества
 	total, _ := h.announcementCollection.CountDocuments(ctx, query)
 
-	c.JSON(http.StatusOK, gin.H{
-		"announcements": announcements,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       total,
-			"total_pages": (total + int64(filters.Limit) - 1) / int64(filters.Limit),
-		},
-	})
+	info := newPageInfo(filters.Page, filters.Limit, total)
+	c.JSON(http.StatusOK, listResponse(c, announcements, info, nil, gin.H{"announcements": announcements, "pagination": info}))
 }
 
 // GetAnnouncement возвращает детальную информацию об объявлении
 func (h *AnnouncementHandler) GetAnnouncement(c *gin.Context) {
-	announcementID, err := primitive.ObjectIDFromHex(c.Param("id"))
-	if err != nil {
+	filter, ok := idOrSlugFilter(c.Param("id"))
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid announcement ID",
 		})
@@ -257,7 +329,7 @@ func (h *AnnouncementHandler) GetAnnouncement(c *gin.Context) {
 	defer cancel()
 
 	var announcement models.Announcement
-	err = h.announcementCollection.FindOne(ctx, bson.M{"_id": announcementID}).Decode(&announcement)
+	err := h.announcementCollection.FindOne(ctx, communityScope(c, filter)).Decode(&announcement)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -274,10 +346,12 @@ func (h *AnnouncementHandler) GetAnnouncement(c *gin.Context) {
 	// Увеличиваем счетчик просмотров
 	h.announcementCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": announcementID},
+		bson.M{"_id": announcement.ID},
 		bson.M{"$inc": bson.M{"view_count": 1}},
 	)
 
+	announcement.Related = h.relatedService.For(ctx, models.ModuleAnnouncements, announcement.ID)
+
 	c.JSON(http.StatusOK, announcement)
 }
 
@@ -299,13 +373,16 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 		})
 		return
 	}
+	if req.Category != "" && !validateCategory(c, h.taxonomyService, models.ModuleAnnouncements, req.Category) {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Проверяем существование и права доступа
 	var announcement models.Announcement
-	err = h.announcementCollection.FindOne(ctx, bson.M{"_id": announcementID}).Decode(&announcement)
+	err = h.announcementCollection.FindOne(ctx, communityScope(c, bson.M{"_id": announcementID})).Decode(&announcement)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -328,15 +405,37 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 		})
 		return
 	}
-	isModerator, _ := c.Get("is_moderator")
+	isModerator := middleware.UserHasPermission(c, models.PermissionModerateAnnouncement)
 
-	if announcement.AuthorID != userIDObj && !isModerator.(bool) {
+	if announcement.AuthorID != userIDObj && !isModerator {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "You don't have permission to update this announcement",
 		})
 		return
 	}
 
+	neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+	if !ok {
+		return
+	}
+
+	// Чужое объявление модератор правит только в своей зоне и не может вывести его из зоны
+	if announcement.AuthorID != userIDObj {
+		if !inModerationScope(c, announcement.Neighborhood, announcement.Category) {
+			return
+		}
+		targetNeighborhood, targetCategory := announcement.Neighborhood, announcement.Category
+		if neighborhood != "" {
+			targetNeighborhood = neighborhood
+		}
+		if req.Category != "" {
+			targetCategory = req.Category
+		}
+		if !inModerationScope(c, targetNeighborhood, targetCategory) {
+			return
+		}
+	}
+
 	// Подготавливаем обновления
 	updateFields := bson.M{"updated_at": time.Now()}
 
@@ -346,9 +445,18 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 	if req.Description != "" {
 		updateFields["description"] = req.Description
 	}
+	if req.Title != "" || req.Description != "" {
+		updateFields["language"] = detectEditedLanguage(announcement.Title, announcement.Description, req.Title, req.Description)
+	}
+	if req.Category != "" {
+		updateFields["category"] = req.Category
+	}
 	if req.Address != "" {
 		updateFields["address"] = req.Address
 	}
+	if neighborhood != "" {
+		updateFields["neighborhood"] = neighborhood
+	}
 	if req.Employment != "" {
 		updateFields["employment"] = req.Employment
 	}
@@ -362,15 +470,95 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 		updateFields["is_active"] = *req.IsActive
 	}
 
+	// При смене категории поля проверяются по новой схеме
+	if req.Category != "" || req.Attributes != nil {
+		category := announcement.Category
+		if req.Category != "" {
+			category = req.Category
+		}
+		employment := announcement.Employment
+		if req.Employment != "" {
+			employment = req.Employment
+		}
+		attributes := announcement.Attributes
+		if req.Attributes != nil {
+			attributes = req.Attributes
+		}
+
+		normalized, ok := validateAnnouncementAttributes(c, category, employment, attributes)
+		if !ok {
+			return
+		}
+		updateFields["attributes"] = normalized
+	}
+
+	// Подсказки для чтения вслух пересчитываются по новому тексту; подсказки автора сохраняются,
+	// а собственный plain_text_body заменяется вычисленным, если текст изменился без него
+	if req.Title != "" || req.Description != "" || req.Address != "" || req.PlainTextBody != "" || req.Pronunciations != nil {
+		requested, err := services.NormalizeSpeechHints(req.SpeechHints)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid speech hints",
+				"details": err.Error(),
+			})
+			return
+		}
+		custom := models.SpeechHints{
+			PlainTextBody:  requested.PlainTextBody,
+			Pronunciations: services.AuthorPronunciations(announcement.Pronunciations),
+		}
+		if req.PlainTextBody == "" && req.Description == "" {
+			custom.PlainTextBody = announcement.PlainTextBody
+		}
+		if req.Pronunciations != nil {
+			custom.Pronunciations = requested.Pronunciations
+		}
+
+		updated := announcement
+		if req.Title != "" {
+			updated.Title = req.Title
+		}
+		if req.Description != "" {
+			updated.Description = req.Description
+		}
+		if req.Address != "" {
+			updated.Address = req.Address
+		}
+		speech := services.AnnouncementSpeechHints(updated, custom)
+		updateFields["plain_text_body"] = speech.PlainTextBody
+		updateFields["pronunciations"] = speech.Pronunciations
+	}
+
+	// Опубликованное объявление уже видели и на него откликались - сохраняем прежний текст
+	if announcement.ApprovedAt != nil {
+		updated := announcementText(announcement)
+		for _, field := range []string{"title", "description", "address", "employment"} {
+			if value, ok := updateFields[field].(string); ok {
+				updated[field] = value
+			}
+		}
+		edit := revisionEdit{
+			entityType: models.RevisionEntityAnnouncement,
+			entityID:   announcementID,
+			version:    announcement.RevisionCount + 1,
+			current:    announcementText(announcement),
+			updated:    updated,
+			editorID:   userIDObj,
+		}
+		if !recordRevision(ctx, c, h.revisionService, edit, updateFields) {
+			return
+		}
+	}
+
 	// Если обновляет не модератор, сбрасываем верификацию
-	if !isModerator.(bool) && (req.Title != "" || req.Description != "") {
+	if !isModerator && (req.Title != "" || req.Description != "") {
 		updateFields["is_verified"] = false
 		updateFields["status"] = "pending"
 	}
 
 	result, err := h.announcementCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": announcementID},
+		communityScope(c, bson.M{"_id": announcementID}),
 		bson.M{"$set": updateFields},
 	)
 
@@ -393,6 +581,140 @@ func (h *AnnouncementHandler) UpdateAnnouncement(c *gin.Context) {
 	})
 }
 
+// GetAttributeSchemas возвращает схемы структурированных полей по категориям
+func (h *AnnouncementHandler) GetAttributeSchemas(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"categories": models.AnnouncementAttributeSchemas,
+	})
+}
+
+// validateAnnouncementAttributes проверяет поля категории; false - ответ с ошибкой уже отправлен
+func validateAnnouncementAttributes(c *gin.Context, category, employment string, attributes map[string]interface{}) (map[string]interface{}, bool) {
+	// Для вакансий тип занятости обязателен - по нему фильтруют соискатели
+	if category == models.AnnouncementCategoryWork && employment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid attributes",
+			"details": "employment is required for work announcements",
+		})
+		return nil, false
+	}
+
+	normalized, err := models.ValidateAnnouncementAttributes(category, attributes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid attributes",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	return normalized, true
+}
+
+// announcementAttributeFilters строит условия по параметрам attr.<поле>, attr.<поле>_min и attr.<поле>_max
+func announcementAttributeFilters(category string, params url.Values) (bson.M, error) {
+	query := bson.M{}
+	for param, values := range params {
+		key, ok := strings.CutPrefix(param, "attr.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if category == "" {
+			return nil, fmt.Errorf("attribute filters require category")
+		}
+
+		value := values[0]
+		bound := ""
+		schema, ok := models.AnnouncementAttributeSchema(category, key)
+		if !ok {
+			for _, suffix := range []string{"_min", "_max"} {
+				if base, cut := strings.CutSuffix(key, suffix); cut {
+					if schema, ok = models.AnnouncementAttributeSchema(category, base); ok {
+						key, bound = base, suffix
+						break
+					}
+				}
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("attribute %q is not supported for category %q", key, category)
+		}
+
+		field := "attributes." + key
+		if schema.Type != models.AttributeTypeNumber {
+			if bound != "" {
+				return nil, fmt.Errorf("range filter is only supported for number attributes")
+			}
+			query[field] = value
+			continue
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q filter must be a number", key)
+		}
+
+		condition, _ := query[field].(bson.M)
+		if condition == nil {
+			condition = bson.M{}
+		}
+		switch bound {
+		case "_min":
+			condition["$gte"] = number
+		case "_max":
+			condition["$lte"] = number
+		default:
+			condition["$eq"] = number
+		}
+		query[field] = condition
+	}
+	return query, nil
+}
+
+// GetRevisions - история текста объявления со сравнением версий (для модераторов)
+func (h *AnnouncementHandler) GetRevisions(c *gin.Context) {
+	announcementID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid announcement ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var announcement models.Announcement
+	err = h.announcementCollection.FindOne(ctx, communityScope(c, bson.M{"_id": announcementID})).Decode(&announcement)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Announcement not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching announcement",
+		})
+		return
+	}
+
+	if !inModerationScope(c, announcement.Neighborhood, announcement.Category) {
+		return
+	}
+
+	respondRevisions(ctx, c, h.revisionService, models.RevisionEntityAnnouncement, announcement.ID, announcementText(announcement), announcement.EditedAt)
+}
+
+// announcementText - текстовые поля объявления, изменения которых сохраняются в истории
+func announcementText(announcement models.Announcement) map[string]string {
+	return map[string]string{
+		"title":       announcement.Title,
+		"description": announcement.Description,
+		"address":     announcement.Address,
+		"employment":  announcement.Employment,
+	}
+}
+
 // DeleteAnnouncement удаляет объявление
 func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
 	announcementID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -408,7 +730,7 @@ func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
 
 	// Проверяем существование и права
 	var announcement models.Announcement
-	err = h.announcementCollection.FindOne(ctx, bson.M{"_id": announcementID}).Decode(&announcement)
+	err = h.announcementCollection.FindOne(ctx, communityScope(c, bson.M{"_id": announcementID})).Decode(&announcement)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -431,16 +753,19 @@ func (h *AnnouncementHandler) DeleteAnnouncement(c *gin.Context) {
 		})
 		return
 	}
-	isModerator, _ := c.Get("is_moderator")
-
-	if announcement.AuthorID != userIDObj && !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to delete this announcement",
-		})
-		return
+	if announcement.AuthorID != userIDObj {
+		if !middleware.UserHasPermission(c, models.PermissionModerateAnnouncement) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You don't have permission to delete this announcement",
+			})
+			return
+		}
+		if !inModerationScope(c, announcement.Neighborhood, announcement.Category) {
+			return
+		}
 	}
 
-	result, err := h.announcementCollection.DeleteOne(ctx, bson.M{"_id": announcementID})
+	result, err := h.announcementCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": announcementID}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error deleting announcement",
@@ -470,15 +795,6 @@ func (h *AnnouncementHandler) ApproveAnnouncement(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -491,9 +807,10 @@ func (h *AnnouncementHandler) ApproveAnnouncement(c *gin.Context) {
 		return
 	}
 
-	result, err := h.announcementCollection.UpdateOne(
+	var announcement models.Announcement
+	err = h.announcementCollection.FindOneAndUpdate(
 		ctx,
-		bson.M{"_id": announcementID},
+		withModerationScope(c, communityScope(c, bson.M{"_id": announcementID})),
 		bson.M{
 			"$set": bson.M{
 				"status":      "approved",
@@ -503,21 +820,33 @@ func (h *AnnouncementHandler) ApproveAnnouncement(c *gin.Context) {
 				"updated_at":  time.Now(),
 			},
 		},
-	)
+	).Decode(&announcement)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if respondOutOfScope(ctx, c, h.announcementCollection, announcementID) {
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Announcement not found",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error approving announcement",
 		})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Announcement not found",
-		})
-		return
-	}
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: announcement.CommunityID,
+		ModeratorID: userIDObj,
+		ContentType: models.ModerationContentAnnouncement,
+		ContentID:   announcement.ID,
+		AuthorID:    announcement.AuthorID,
+		Decision:    models.ModerationDecisionApproved,
+		SubmittedAt: announcement.CreatedAt,
+	})
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Announcement approved successfully",
@@ -534,15 +863,6 @@ func (h *AnnouncementHandler) RejectAnnouncement(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	var rejectionReq struct {
 		Reason string `json:"reason" validate:"required,min=10,max=500"`
 	}
@@ -566,9 +886,10 @@ func (h *AnnouncementHandler) RejectAnnouncement(c *gin.Context) {
 		return
 	}
 
-	result, err := h.announcementCollection.UpdateOne(
+	var announcement models.Announcement
+	err = h.announcementCollection.FindOneAndUpdate(
 		ctx,
-		bson.M{"_id": announcementID},
+		withModerationScope(c, communityScope(c, bson.M{"_id": announcementID})),
 		bson.M{
 			"$set": bson.M{
 				"status":           "rejected",
@@ -580,22 +901,40 @@ func (h *AnnouncementHandler) RejectAnnouncement(c *gin.Context) {
 				"updated_at":       time.Now(),
 			},
 		},
-	)
+	).Decode(&announcement)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if respondOutOfScope(ctx, c, h.announcementCollection, announcementID) {
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Announcement not found",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error rejecting announcement",
 		})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Announcement not found",
-		})
-		return
+	// Відхилений контент знижує довіру до автора (повторне відхилення не враховується)
+	if announcement.Status != "rejected" {
+		h.trustService.RecordRemoval(ctx, announcement.AuthorID)
 	}
 
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: announcement.CommunityID,
+		ModeratorID: userIDObj,
+		ContentType: models.ModerationContentAnnouncement,
+		ContentID:   announcement.ID,
+		AuthorID:    announcement.AuthorID,
+		Decision:    models.ModerationDecisionRejected,
+		Reason:      rejectionReq.Reason,
+		SubmittedAt: announcement.CreatedAt,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Announcement rejected successfully",
 	})
@@ -613,15 +952,7 @@ func (h *AnnouncementHandler) GetMyAnnouncements(c *gin.Context) {
 	}
 
 	// Получаем параметры пагинации
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
+	page, limit := pageParams(c, 20, 100)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -656,34 +987,18 @@ func (h *AnnouncementHandler) GetMyAnnouncements(c *gin.Context) {
 	// Подсчет общего количества
 	total, _ := h.announcementCollection.CountDocuments(ctx, bson.M{"author_id": userIDObj})
 
-	c.JSON(http.StatusOK, gin.H{
-		"announcements": announcements,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, announcements, info, nil, gin.H{"announcements": announcements, "pagination": info}))
 }
 
 // GetPendingAnnouncements возвращает объявления на модерации (для модераторов)
 func (h *AnnouncementHandler) GetPendingAnnouncements(c *gin.Context) {
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	cursor, err := h.announcementCollection.Find(
 		ctx,
-		bson.M{"status": "pending"},
+		communityScope(c, withModerationScope(c, bson.M{"status": "pending"})),
 		options.Find().SetSort(bson.D{{"created_at", 1}}), // Старые первыми
 	)
 	if err != nil {
@@ -723,7 +1038,7 @@ func (h *AnnouncementHandler) IncrementResponseCount(c *gin.Context) {
 
 	result, err := h.announcementCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": announcementID},
+		communityScope(c, bson.M{"_id": announcementID}),
 		bson.M{"$inc": bson.M{"response_count": 1}},
 	)
 
@@ -745,3 +1060,47 @@ func (h *AnnouncementHandler) IncrementResponseCount(c *gin.Context) {
 		"message": "Response count updated",
 	})
 }
+
+// GetRecommendedAnnouncements - GET /announcements/recommended
+// Свіжі схвалені оголошення за інтересами користувача
+func (h *AnnouncementHandler) GetRecommendedAnnouncements(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := communityScope(c, bson.M{
+		"is_active":   true,
+		"is_verified": true,
+		"status":      "approved",
+		"expires_at":  bson.M{"$gt": time.Now()},
+	})
+	personalized, ok := recommendationScope(ctx, c, h.userCollection, h.taxonomyService, models.ModuleAnnouncements, filter)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.announcementCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(recommendationLimit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching announcements",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	announcements := []models.Announcement{}
+	if err := cursor.All(ctx, &announcements); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error decoding announcements",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"announcements": announcements,
+		"personalized":  personalized,
+	})
+}
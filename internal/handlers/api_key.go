@@ -0,0 +1,166 @@
+// internal/handlers/api_key.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyHandler - ключі доступу для інтеграцій партнерів (X-API-Key)
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,min=2,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=730"` // 0 - безстроковий
+}
+
+// GetAPIKeys - GET /auth/api-keys
+// Дійсні ключі користувача та дозволи, які його роль може видати ключу
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := h.apiKeyService.List(ctx, user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching API keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys":         keys,
+		"available_scopes": services.AvailableScopes(user.Role),
+	})
+}
+
+// CreateAPIKey - POST /auth/api-keys
+// Ключ повертається лише в цій відповіді; дозволи ключа - частина дозволів ролі власника
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	scopes := make([]models.Permission, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, models.Permission(scope))
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &expires
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rawKey, key, err := h.apiKeyService.Create(ctx, user.UserID, user.Role, req.Name, scopes, expiresAt)
+	switch {
+	case errors.Is(err, services.ErrAPIKeyScope):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Requested scopes are not available for your role",
+			"available_scopes": services.AvailableScopes(user.Role),
+		})
+		return
+	case errors.Is(err, services.ErrAPIKeyLimit):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "API key limit reached, revoke an unused key first",
+			"limit": services.APIKeyMaxPerUser,
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creating API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Store it securely: it will not be shown again",
+		"key":     rawKey,
+		"api_key": key,
+	})
+}
+
+// RevokeAPIKey - DELETE /auth/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	keyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.apiKeyService.Revoke(ctx, user.UserID, keyID); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "API key not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking API key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked",
+	})
+}
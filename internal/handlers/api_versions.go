@@ -0,0 +1,28 @@
+// internal/handlers/api_versions.go
+
+package handlers
+
+import (
+	"net/http"
+
+	"nova-kakhovka-ecity/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionHandler - машиночитаний опис версій API для клієнтів
+type APIVersionHandler struct {
+	versions *middleware.APIVersions
+}
+
+// NewAPIVersionHandler створює обробник реєстру версій
+func NewAPIVersionHandler(versions *middleware.APIVersions) *APIVersionHandler {
+	return &APIVersionHandler{versions: versions}
+}
+
+// GetVersions - GET /api/versions
+// Підтримувані версії, застарілі маршрути й поля з датами вимкнення та журнал змін
+func (h *APIVersionHandler) GetVersions(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.versions)
+}
@@ -0,0 +1,84 @@
+// internal/handlers/audit_log.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditLogHandler - перегляд журналу аудиту
+type AuditLogHandler struct {
+	auditLogCollection *mongo.Collection
+}
+
+// NewAuditLogHandler створює обробник журналу аудиту
+func NewAuditLogHandler(auditLogCollection *mongo.Collection) *AuditLogHandler {
+	return &AuditLogHandler{auditLogCollection: auditLogCollection}
+}
+
+// GetAuditLogs - GET /admin/audit-logs
+// Фільтри: action, resource_type, resource_id, actor_id; нові записи першими
+func (h *AuditLogHandler) GetAuditLogs(c *gin.Context) {
+	page, limit := pageParams(c, 50, 200)
+
+	filter := bson.M{}
+	if action := c.Query("action"); action != "" {
+		filter["action"] = action
+	}
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		filter["resource_type"] = resourceType
+	}
+	for _, param := range []string{"resource_id", "actor_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid " + param,
+			})
+			return
+		}
+		filter[param] = id
+	}
+	filter = communityScope(c, filter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.auditLogCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching audit logs",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditLogEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding audit logs",
+		})
+		return
+	}
+
+	total, _ := h.auditLogCollection.CountDocuments(ctx, filter)
+	info := newPageInfo(page, limit, total)
+
+	c.JSON(http.StatusOK, listResponse(c, entries, info, nil, gin.H{"entries": entries, "pagination": info}))
+}
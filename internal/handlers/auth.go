@@ -4,10 +4,15 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 	"nova-kakhovka-ecity/pkg/auth"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +25,13 @@ import (
 type AuthHandler struct {
 	userCollection *mongo.Collection
 	jwtManager     *auth.JWTManager
+	emailService   *services.EmailService
+	refreshTokens  *services.RefreshTokenService
+	sessions       *services.SessionService
+	erasure        *services.AccountErasureService
+	phone          *services.PhoneVerificationService
+	sso            *services.SSOService
+	userStatus     *services.UserStatusCache
 }
 
 // Request structures
@@ -36,25 +48,137 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	All          bool   `json:"all"` // Вийти на всіх пристроях
+}
+
+// UpdateProfileRequest - поля профілю, які користувач змінює сам (PUT /auth/profile).
+// Інші поля запиту ігноруються: роль, блокування, верифікація, групи, лічильники довіри
+// та службові мітки змінює лише сервер. Телефон, аватар, інтереси й налаштування
+// приватності мають окремі ендпоінти.
+type UpdateProfileRequest struct {
+	FirstName         *string              `json:"first_name" binding:"omitempty,min=2,max=50"`
+	LastName          *string              `json:"last_name" binding:"omitempty,min=2,max=50"`
+	Profession        *string              `json:"profession" binding:"omitempty,max=100"`
+	RegisteredAddress *string              `json:"registered_address" binding:"omitempty,max=300"`
+	IsAddressVisible  *bool                `json:"is_address_visible"`
+	BirthDate         *string              `json:"birth_date"` // YYYY-MM-DD; порожній рядок видаляє дату
+	Location          *models.Location     `json:"location"`
+	Status            *models.UserStatus   `json:"status"`
+	BusinessInfo      *models.BusinessInfo `json:"business_info"`
+}
+
+// profileUpdate - документ оновлення користувача лише з переданих полів UpdateProfileRequest
+func profileUpdate(req UpdateProfileRequest, now time.Time) (bson.M, error) {
+	set := bson.M{"updated_at": now}
+	update := bson.M{"$set": set}
+
+	if req.FirstName != nil {
+		set["first_name"] = *req.FirstName
+	}
+	if req.LastName != nil {
+		set["last_name"] = *req.LastName
+	}
+	if req.Profession != nil {
+		set["profession"] = *req.Profession
+	}
+	if req.RegisteredAddress != nil {
+		set["registered_address"] = *req.RegisteredAddress
+	}
+	if req.IsAddressVisible != nil {
+		set["is_address_visible"] = *req.IsAddressVisible
+	}
+	if req.Location != nil {
+		set["current_location"] = req.Location
+	}
+	if req.Status != nil {
+		status := *req.Status
+		status.UpdatedAt = now
+		set["status"] = status
+	}
+	if req.BusinessInfo != nil {
+		set["business_info"] = req.BusinessInfo
+	}
+
+	// Дата народження зберігається датою - за нею перевіряються вікові обмеження опитувань
+	if req.BirthDate != nil {
+		if *req.BirthDate == "" {
+			update["$unset"] = bson.M{"birth_date": ""}
+		} else {
+			birthDate, err := models.ParseBirthDate(*req.BirthDate, now)
+			if err != nil {
+				return nil, err
+			}
+			set["birth_date"] = birthDate
+		}
+	}
+	return update, nil
+}
+
 // Response structures
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"` // Час життя access-токена, секунд
+	User         *models.User `json:"user"`
+}
+
+// TokenResponse - нова пара токенів після POST /auth/refresh
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 // ← ДОДАНО: Структура для відповіді при блокуванні
 type BlockedUserResponse struct {
-	Error       string     `json:"error"`
-	IsBlocked   bool       `json:"is_blocked"`
-	BlockReason string     `json:"block_reason,omitempty"`
-	BlockedAt   *time.Time `json:"blocked_at,omitempty"`
-	Message     string     `json:"message"`
+	Error        string     `json:"error"`
+	IsBlocked    bool       `json:"is_blocked"`
+	IsSuspended  bool       `json:"is_suspended,omitempty"` // Тимчасове блокування, знімається автоматично
+	BlockReason  string     `json:"block_reason,omitempty"`
+	BlockedAt    *time.Time `json:"blocked_at,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+	Message      string     `json:"message"`
+}
+
+// newBlockedUserResponse - відмова у вході для заблокованого користувача;
+// для призупинення повідомлення містить дату, коли вхід знову стане доступним
+func newBlockedUserResponse(user *models.User, message string) BlockedUserResponse {
+	response := BlockedUserResponse{
+		Error:     "Account is blocked",
+		IsBlocked: true,
+		BlockedAt: user.BlockedAt,
+		Message:   message,
+	}
+	if user.BlockReason != nil && *user.BlockReason != "" {
+		response.BlockReason = *user.BlockReason
+	}
+	if user.IsSuspended() {
+		response.Error = "Account is suspended"
+		response.IsSuspended = true
+		response.BlockedUntil = user.BlockedUntil
+		response.Message = fmt.Sprintf("Ваш акаунт тимчасово призупинено до %s (UTC).",
+			user.BlockedUntil.UTC().Format("02.01.2006 15:04"))
+	}
+	return response
 }
 
-func NewAuthHandler(userCollection *mongo.Collection, jwtManager *auth.JWTManager) *AuthHandler {
+func NewAuthHandler(userCollection *mongo.Collection, jwtManager *auth.JWTManager, emailService *services.EmailService, refreshTokens *services.RefreshTokenService, sessions *services.SessionService, erasure *services.AccountErasureService, phone *services.PhoneVerificationService, sso *services.SSOService, userStatus *services.UserStatusCache) *AuthHandler {
 	return &AuthHandler{
 		userCollection: userCollection,
 		jwtManager:     jwtManager,
+		emailService:   emailService,
+		refreshTokens:  refreshTokens,
+		sessions:       sessions,
+		erasure:        erasure,
+		phone:          phone,
+		sso:            sso,
+		userStatus:     userStatus,
 	}
 }
 
@@ -113,6 +237,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		IsBlocked:  false,
 		Groups:     []primitive.ObjectID{},
 		Interests:  []string{},
+
+		// Користувач автоматично приєднується до громади, в якій зареєструвався
+		CommunityIDs: []primitive.ObjectID{},
+
 		Status: models.UserStatus{
 			Message:   "",
 			IsVisible: false,
@@ -122,6 +250,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		UpdatedAt: now,
 	}
 
+	if communityID := getCommunityID(c); !communityID.IsZero() {
+		user.CommunityIDs = append(user.CommunityIDs, communityID)
+	}
+
 	// Зберігаємо користувача в базу даних
 	result, err := h.userCollection.InsertOne(ctx, user)
 	if err != nil {
@@ -133,24 +265,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user.ID = result.InsertedID.(primitive.ObjectID)
 
-	// Генеруємо JWT токен
-	token, err := h.jwtManager.GenerateToken(
-		user.ID.Hex(),
-		user.Email,
-		user.Role,
-		user.IsModerator,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Error generating token",
-		})
+	// Вітальний лист ставиться в чергу і не блокує відповідь
+	h.emailService.Enqueue(ctx, user.Email, models.EmailTemplateWelcome, map[string]interface{}{
+		"first_name": user.FirstName,
+	})
+
+	response, ok := h.issueTokens(ctx, c, &user)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  &user,
-	})
+	c.JSON(http.StatusCreated, response)
 }
 
 // Login handles user authentication
@@ -178,25 +303,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Перевіряємо чи користувач заблокований
-	if user.IsBlocked {
+	if user.IsBlockedAt(time.Now()) {
 		// Формуємо детальну відповідь для заблокованого користувача
-		response := BlockedUserResponse{
-			Error:     "Account is blocked",
-			IsBlocked: true,
-			Message:   "Ваш акаунт заблоковано. Будь ласка, зверніться до модератора для отримання додаткової інформації.",
-		}
-
-		// ← ВИПРАВЛЕНО: Перевіряємо чи BlockReason є вказівником
-		if user.BlockReason != nil && *user.BlockReason != "" {
-			response.BlockReason = *user.BlockReason
-		}
-
-		// Додаємо час блокування, якщо він є
-		if user.BlockedAt != nil {
-			response.BlockedAt = user.BlockedAt
-		}
+		c.JSON(http.StatusForbidden, newBlockedUserResponse(&user,
+			"Ваш акаунт заблоковано. Будь ласка, зверніться до модератора для отримання додаткової інформації."))
+		return
+	}
 
-		c.JSON(http.StatusForbidden, response)
+	// Працівники, прив'язані до SSO міськради, входять лише через провайдера
+	if user.SSOProvider != "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":        "This account must sign in with SSO",
+			"sso_provider": user.SSOProvider,
+		})
 		return
 	}
 
@@ -237,23 +356,189 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		bson.M{"$set": bson.M{"last_login_at": now}},
 	)
 
+	response, ok := h.issueTokens(ctx, c, &user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// issueTokens генерує access-токен і відкриває новий ланцюжок refresh-токенів.
+// Повертає false, якщо відповідь з помилкою вже надіслана.
+func (h *AuthHandler) issueTokens(ctx context.Context, c *gin.Context, user *models.User) (*AuthResponse, bool) {
+	refreshToken, stored, err := h.refreshTokens.Issue(ctx, user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error generating refresh token",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	// Сесія - ланцюжок refresh-токенів цього входу
+	err = h.sessions.Start(ctx, stored.FamilyID, user.ID, c.Request.UserAgent(), c.ClientIP(), stored.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error starting session",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
 	// Генеруємо JWT токен
 	token, err := h.jwtManager.GenerateToken(
 		user.ID.Hex(),
 		user.Email,
 		user.Role,
 		user.IsModerator,
+		user.TokenVersion,
+		stored.FamilyID.Hex(),
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error generating token",
 		})
+		return nil, false
+	}
+
+	return &AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.jwtManager.TokenDuration().Seconds()),
+		User:         user,
+	}, true
+}
+
+// Refresh видає нову пару токенів в обмін на refresh-токен (ротація)
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  &user,
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	refreshToken, stored, err := h.refreshTokens.Rotate(ctx, req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err == services.ErrRefreshTokenReused {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Refresh token has already been used, please log in again",
+			"code":  "REFRESH_TOKEN_REUSED",
+		})
+		return
+	}
+	if err == services.ErrRefreshTokenInvalid {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or expired refresh token",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error refreshing token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Роль і блокування перевіряються за поточними даними, а не за старим токеном
+	var user models.User
+	if err := h.userCollection.FindOne(ctx, bson.M{"_id": stored.UserID}).Decode(&user); err != nil {
+		h.revokeAllSessions(ctx, stored.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Account no longer exists",
+		})
+		return
+	}
+
+	if user.IsBlockedAt(time.Now()) {
+		h.revokeAllSessions(ctx, user.ID)
+		c.JSON(http.StatusForbidden, newBlockedUserResponse(&user,
+			"Ваш акаунт заблоковано. Будь ласка, зверніться до модератора для отримання додаткової інформації."))
+		return
+	}
+
+	token, err := h.jwtManager.GenerateToken(
+		user.ID.Hex(),
+		user.Email,
+		string(user.GetRole()),
+		user.IsModerator,
+		user.TokenVersion,
+		stored.FamilyID.Hex(),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error generating token",
+		})
+		return
+	}
+
+	if err := h.sessions.Extend(ctx, stored.FamilyID, c.Request.UserAgent(), c.ClientIP(), stored.ExpiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.jwtManager.TokenDuration().Seconds()),
+	})
+}
+
+// Logout завершує сесію пристрою або, з all=true, всі сесії користувача.
+// Разом із refresh-токенами відкликаються й access-токени цих сесій.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stored, err := h.refreshTokens.Revoke(ctx, req.RefreshToken)
+	if err == services.ErrRefreshTokenInvalid {
+		// Невідомий токен - вихід вже відбувся
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Logged out successfully",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking refresh token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.All {
+		err = h.revokeAllSessions(ctx, stored.UserID)
+	} else {
+		_, err = h.sessions.Revoke(ctx, stored.UserID, stored.FamilyID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
 	})
 }
 
@@ -320,8 +605,8 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// Парсимо request body
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request data",
 			"details": err.Error(),
@@ -329,17 +614,14 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Видаляємо поля які не можна оновлювати через цей endpoint
-	delete(updates, "email")
-	delete(updates, "password_hash")
-	delete(updates, "role")
-	delete(updates, "is_moderator")
-	delete(updates, "is_blocked")
-	delete(updates, "is_verified")
-	delete(updates, "_id")
-
-	// Додаємо updated_at
-	updates["updated_at"] = time.Now()
+	update, err := profileUpdate(req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid birth date",
+			"details": err.Error(),
+		})
+		return
+	}
 
 	// Оновлюємо користувача
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -348,7 +630,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	result, err := h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": objectID},
-		bson.M{"$set": updates},
+		update,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -386,22 +668,20 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 
 	// Отримуємо ID користувача з контексту
-	userID, exists := c.Get("user_id")
-	if !exists {
+	userIDObj, err := getUserID(c)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Unauthorized",
 		})
 		return
 	}
 
-	userIDObj := userID.(primitive.ObjectID)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Знаходимо користувача
 	var user models.User
-	err := h.userCollection.FindOne(ctx, bson.M{"_id": userIDObj}).Decode(&user)
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": userIDObj}).Decode(&user)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
@@ -409,6 +689,13 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	if user.SSOProvider != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Password is managed by the SSO provider",
+		})
+		return
+	}
+
 	// Перевіряємо старий пароль
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.OldPassword)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -444,7 +731,353 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Після зміни пароля всі пристрої мають увійти заново
+	if err := h.revokeAllSessions(ctx, userIDObj); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Повідомляємо користувача про зміну пароля
+	h.emailService.Enqueue(ctx, user.Email, models.EmailTemplatePasswordChanged, nil)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Password changed successfully",
 	})
 }
+
+// revokeAllSessions завершує всі сесії користувача разом з їх refresh-токенами
+func (h *AuthHandler) revokeAllSessions(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := h.refreshTokens.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	return h.sessions.RevokeAll(ctx, userID)
+}
+
+// GetSessions - GET /auth/sessions
+// Активні входи користувача: пристрій, IP та час останньої активності
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := h.sessions.List(ctx, user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == user.SessionID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  sessions,
+		"total": len(sessions),
+	})
+}
+
+// RevokeSession - DELETE /auth/sessions/:id
+// Завершує вхід на пристрої: refresh-токен більше не оновлюється, access-токен відкликається
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session ID",
+		})
+		return
+	}
+
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	revoked, err := h.sessions.Revoke(ctx, user.UserID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking session",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Session not found",
+		})
+		return
+	}
+
+	if err := h.refreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking refresh tokens",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked",
+		"current": sessionID == user.SessionID,
+	})
+}
+
+// DeleteAccount - DELETE /auth/account
+// Видалення акаунта на вимогу користувача: вхід блокується одразу, персональні дані
+// в профілі, повідомленнях, підписах петицій, відповідях опитувань і коментарях
+// знеособлюються у фоні. Повертає квитанцію для перевірки статусу видалення.
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	type DeleteAccountRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": userIDObj}).Decode(&user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	// Підтвердження паролем: викрадений access-токен не дає видалити акаунт
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Incorrect password",
+		})
+		return
+	}
+
+	// Суперадміністратор спочатку має передати роль, інакше система може лишитися без нього
+	if models.UserRole(user.Role) == models.RoleSuperAdmin {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Super admin account cannot be deleted, transfer the role first",
+		})
+		return
+	}
+
+	if err := h.revokeAllSessions(ctx, userIDObj); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error revoking sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	deletion, code, err := h.erasure.Request(ctx, userIDObj)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error requesting account deletion",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Account deletion requested",
+		"receipt": gin.H{
+			"deletion_id":  deletion.ID,
+			"receipt_code": code,
+			"status":       deletion.Status,
+			"requested_at": deletion.RequestedAt,
+			"status_url":   "/api/v1/auth/account/deletions/" + deletion.ID.Hex() + "?code=" + code,
+		},
+	})
+}
+
+// GetAccountDeletion - GET /auth/account/deletions/:id?code=
+// Статус видалення за квитанцією; акаунт уже недоступний, тому автентифікація не потрібна
+func (h *AuthHandler) GetAccountDeletion(c *gin.Context) {
+	deletionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid deletion ID",
+		})
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Receipt code is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deletion, err := h.erasure.Receipt(ctx, deletionID, code)
+	if err == services.ErrAccountDeletionNotFound {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Account deletion not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching account deletion",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": deletion,
+	})
+}
+
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type VerifyPhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// RequestPhoneCode - POST /auth/phone/request-code
+// Надсилає SMS з одноразовим кодом на номер, який користувач хоче підтвердити
+func (h *AuthHandler) RequestPhoneCode(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	expiresAt, err := h.phone.RequestCode(ctx, userID, req.Phone)
+	if err != nil {
+		respondPhoneError(c, err, "Error sending verification code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Verification code sent",
+		"expires_at": expiresAt,
+	})
+}
+
+// VerifyPhone - POST /auth/phone/verify
+// Перевіряє код і зберігає підтверджений номер у профілі
+func (h *AuthHandler) VerifyPhone(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	phone, err := h.phone.Verify(ctx, userID, req.Phone, req.Code)
+	if err != nil {
+		respondPhoneError(c, err, "Error verifying phone")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Phone verified",
+		"phone":   phone,
+	})
+}
+
+// respondPhoneError - відповідь на помилку підтвердження телефону
+func respondPhoneError(c *gin.Context, err error, fallback string) {
+	var limitErr *services.PhoneRateLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		c.Header("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":               "Too many verification codes requested for this phone",
+			"retry_after_seconds": limitErr.RetryAfterSeconds(),
+		})
+	case errors.Is(err, services.ErrInvalidPhone):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid phone number",
+		})
+	case errors.Is(err, services.ErrPhoneTaken):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Phone number is already used by another account",
+		})
+	case errors.Is(err, services.ErrPhoneCodeInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid or expired verification code",
+		})
+	case errors.Is(err, services.ErrPhoneCodeAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many attempts, request a new code",
+		})
+	case errors.Is(err, services.ErrSMSUnavailable):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "SMS delivery failed, try again later",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   fallback,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// bindProfileUpdate розбирає тіло PUT /auth/profile так само, як UpdateProfile
+func bindProfileUpdate(t *testing.T, body string, now time.Time) bson.M {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/auth/profile", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.Fatalf("bind %s: %v", body, err)
+	}
+	update, err := profileUpdate(req, now)
+	if err != nil {
+		t.Fatalf("profileUpdate %s: %v", body, err)
+	}
+	return update
+}
+
+func TestProfileUpdateSetsAllowedFields(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	update := bindProfileUpdate(t, `{"first_name":"Олена","profession":"Вчителька","birth_date":"1990-05-17"}`, now)
+
+	set := update["$set"].(bson.M)
+	if set["first_name"] != "Олена" || set["profession"] != "Вчителька" {
+		t.Errorf("profile fields not set: %v", set)
+	}
+	if set["birth_date"] != time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("birth_date = %v", set["birth_date"])
+	}
+	if set["updated_at"] != now {
+		t.Errorf("updated_at = %v, want %v", set["updated_at"], now)
+	}
+	if _, ok := set["last_name"]; ok {
+		t.Error("fields missing from the request must not be set")
+	}
+}
+
+func TestProfileUpdateRemovesBirthDate(t *testing.T) {
+	update := bindProfileUpdate(t, `{"birth_date":""}`, time.Now())
+	unset, _ := update["$unset"].(bson.M)
+	if _, ok := unset["birth_date"]; !ok {
+		t.Errorf("empty birth_date must unset it: %v", update)
+	}
+}
+
+// Поля, які змінює лише сервер: запит профілю не повинен потрапляти в них ні через $set, ні через $unset
+func TestProfileUpdateIgnoresServerFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{
+			name:   "moderation scope",
+			body:   `{"moderation_scope":null}`,
+			fields: []string{"moderation_scope"},
+		},
+		{
+			name:   "role and blocking",
+			body:   `{"role":"ADMIN","is_moderator":true,"is_blocked":false,"is_verified":true}`,
+			fields: []string{"role", "is_moderator", "is_blocked", "is_verified"},
+		},
+		{
+			// Вхідні дані оцінки довіри (models.CalculateTrust)
+			name:   "trust inputs",
+			body:   `{"removed_content_count":0,"created_at":"2000-01-01T00:00:00Z","email_verified_at":"2000-01-01T00:00:00Z"}`,
+			fields: []string{"removed_content_count", "created_at", "email_verified_at"},
+		},
+		{
+			// Особу (Дія/BankID) записує лише PUT /users/:id/verify
+			name:   "verified identity",
+			body:   `{"identity_hash":"forged","identity_provider":"diia"}`,
+			fields: []string{"identity_hash", "identity_provider"},
+		},
+		{
+			// Участь у групах - через вступ у групу; за нею діють цільові групи опитувань
+			name:   "group membership",
+			body:   `{"groups":["507f1f77bcf86cd799439011"],"community_ids":["507f1f77bcf86cd799439012"]}`,
+			fields: []string{"groups", "community_ids"},
+		},
+		{
+			// Скидання версії повернуло б відкликані токени
+			name:   "token version",
+			body:   `{"token_version":0}`,
+			fields: []string{"token_version"},
+		},
+		{
+			name:   "role history and presence",
+			body:   `{"role_history":[],"blocked_until":null,"last_seen_at":"2026-01-01T00:00:00Z"}`,
+			fields: []string{"role_history", "blocked_until", "last_seen_at"},
+		},
+		{
+			name:   "phone",
+			body:   `{"phone":"+380501234567","phone_verified_at":"2026-01-01T00:00:00Z"}`,
+			fields: []string{"phone", "phone_verified_at"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := bindProfileUpdate(t, tt.body, time.Now())
+			for _, operator := range []string{"$set", "$unset"} {
+				fields, _ := update[operator].(bson.M)
+				for _, field := range tt.fields {
+					if _, ok := fields[field]; ok {
+						t.Errorf("%s contains server-only field %q", operator, field)
+					}
+				}
+			}
+		})
+	}
+}
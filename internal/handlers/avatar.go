@@ -0,0 +1,234 @@
+// internal/handlers/avatar.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AvatarHandler - завантаження аватарів і видача файлів за підписаними посиланнями
+type AvatarHandler struct {
+	userCollection *mongo.Collection
+	avatarService  *services.AvatarService
+	localStorage   *services.LocalFileStorage // nil, якщо файли віддає S3
+	maxBytes       int64
+}
+
+func NewAvatarHandler(userCollection *mongo.Collection, avatarService *services.AvatarService, localStorage *services.LocalFileStorage, maxBytes int64) *AvatarHandler {
+	return &AvatarHandler{
+		userCollection: userCollection,
+		avatarService:  avatarService,
+		localStorage:   localStorage,
+		maxBytes:       maxBytes,
+	}
+}
+
+// UploadAvatar - POST /users/me/avatar (multipart/form-data, поле "avatar")
+// Приймає JPEG, PNG або GIF, зберігає квадратні мініатюри 512, 128 і 48 пікселів
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	// Запас на заголовки multipart понад розмір самого файлу
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+64*1024)
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":     "File is too large",
+				"max_bytes": h.maxBytes,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Avatar file is required",
+			"details": err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > h.maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":     "File is too large",
+			"max_bytes": h.maxBytes,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Error reading file",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Error reading file",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	avatarKey, err := h.avatarService.Upload(ctx, userID, data)
+	switch {
+	case errors.Is(err, services.ErrUnsupportedImage):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Only JPEG, PNG and GIF images are supported",
+		})
+		return
+	case errors.Is(err, services.ErrImageDimensions):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Image must be at least 32x32 and at most 40 megapixels",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error saving avatar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	urls, err := h.avatarService.SignedURLs(avatarKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error signing avatar URLs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Avatar updated successfully",
+		"avatar":     services.AvatarPath(userID),
+		"sizes":      urls,
+		"expires_in": int(h.avatarService.URLTTL().Seconds()),
+	})
+}
+
+// DeleteAvatar - DELETE /users/me/avatar
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := h.avatarService.Remove(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error deleting avatar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Avatar deleted successfully",
+	})
+}
+
+// GetAvatar - GET /users/:id/avatar?size=128
+// Перенаправляє на тимчасове підписане посилання мініатюри найближчого розміру
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+	size := services.AvatarSizes[0]
+	if raw := c.Query("size"); raw != "" {
+		size, err = strconv.Atoi(raw)
+		if err != nil || size <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid size",
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx,
+		bson.M{"_id": userID, "is_deleted": bson.M{"$ne": true}},
+		options.FindOne().SetProjection(bson.M{"avatar_key": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && user.AvatarKey == "") {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Avatar not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching avatar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	signedURL, err := h.avatarService.SignedURL(user.AvatarKey, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error signing avatar URL",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Перенаправлення кешується менше за строк дії посилання, щоб клієнт не отримав прострочене
+	maxAge := int(h.avatarService.URLTTL().Seconds()) / 2
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	c.Redirect(http.StatusFound, signedURL)
+}
+
+// ServeMedia - GET /media/*key?expires=...&signature=...
+// Віддає файл локального сховища, якщо підпис посилання дійсний (маршрут є лише з драйвером local)
+func (h *AvatarHandler) ServeMedia(c *gin.Context) {
+	filePath, err := h.localStorage.Open(strings.TrimPrefix(c.Param("key"), "/"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Invalid or expired link",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.File(filePath)
+}
@@ -0,0 +1,90 @@
+// internal/handlers/backup.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler - резервні копії критичних колекцій (ручний запуск та історія)
+type BackupHandler struct {
+	backupService *services.BackupService
+}
+
+func NewBackupHandler(backupService *services.BackupService) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+	}
+}
+
+// TriggerBackup - POST /admin/backups
+// Запускає резервну копію поза розкладом (наприклад, перед міграцією); копіювання йде у фоні
+func (h *BackupHandler) TriggerBackup(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backup, err := h.backupService.Trigger(ctx, adminID)
+	if errors.Is(err, services.ErrBackupRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Backup is already running",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error starting backup",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Backup started",
+		"backup":  backup,
+	})
+}
+
+// GetBackups - GET /admin/backups
+// Останні 30 копій і стан резервного копіювання (той самий, що в /health/ready)
+func (h *BackupHandler) GetBackups(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backups, err := h.backupService.List(ctx, 30)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching backups",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	health, err := h.backupService.Health(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching backup status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backups": backups,
+		"health":  health,
+	})
+}
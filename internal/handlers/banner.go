@@ -0,0 +1,376 @@
+// internal/handlers/banner.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BannerHandler - банери застосунків: технічні роботи, кампанії, онбординг
+type BannerHandler struct {
+	bannerCollection *mongo.Collection
+}
+
+type BannerRequest struct {
+	Title       string     `json:"title" binding:"max=100"`
+	Message     string     `json:"message" binding:"required,min=3,max=500"`
+	Severity    string     `json:"severity" binding:"omitempty,oneof=info promo warning critical"`
+	LinkURL     string     `json:"link_url" binding:"max=500"`
+	LinkLabel   string     `json:"link_label" binding:"max=50"`
+	Placement   string     `json:"placement" binding:"omitempty,oneof=global home onboarding"`
+	Audience    string     `json:"audience" binding:"omitempty,oneof=all guests authenticated"`
+	Platforms   []string   `json:"platforms" binding:"omitempty,dive,oneof=web android ios"`
+	Roles       []string   `json:"roles"`
+	Dismissible *bool      `json:"dismissible"`
+	Priority    int        `json:"priority"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	IsActive    *bool      `json:"is_active"`
+}
+
+func NewBannerHandler(bannerCollection *mongo.Collection) *BannerHandler {
+	return &BannerHandler{
+		bannerCollection: bannerCollection,
+	}
+}
+
+// GetPublicBanners - GET /public/banners?platform=android&placement=home
+// Діючі банери громади для поточного користувача (або гостя), найважливіші першими
+func (h *BannerHandler) GetPublicBanners(c *gin.Context) {
+	platform := c.Query("platform")
+	if platform != "" && platform != models.BannerPlatformWeb && platform != models.BannerPlatformAndroid && platform != models.BannerPlatformIOS {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid platform",
+		})
+		return
+	}
+
+	role := ""
+	if user, ok := middleware.CurrentUser(c); ok {
+		role = string(user.Role)
+	}
+
+	now := time.Now()
+	filter := communityScope(c, bson.M{
+		"is_active": true,
+		"$and": []bson.M{
+			{"$or": []bson.M{{"starts_at": bson.M{"$exists": false}}, {"starts_at": bson.M{"$lte": now}}}},
+			{"$or": []bson.M{{"ends_at": bson.M{"$exists": false}}, {"ends_at": bson.M{"$gt": now}}}},
+		},
+	})
+	if placement := c.Query("placement"); placement != "" {
+		// Глобальні банери показуються на кожному екрані
+		filter["placement"] = bson.M{"$in": []string{placement, models.BannerPlacementGlobal}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	banners, err := h.find(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching banners",
+		})
+		return
+	}
+
+	visible := []models.Banner{}
+	for _, banner := range banners {
+		if banner.VisibleTo(role, platform) {
+			visible = append(visible, banner)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		if visible[i].SeverityRank() != visible[j].SeverityRank() {
+			return visible[i].SeverityRank() > visible[j].SeverityRank()
+		}
+		return visible[i].Priority > visible[j].Priority
+	})
+
+	// Відповідь залежить від ролі, тому спільний кеш допустимий лише для гостей
+	if role == "" {
+		c.Header("Cache-Control", "public, max-age=60")
+	} else {
+		c.Header("Cache-Control", "private, max-age=60")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": visible,
+	})
+}
+
+// GetBanners - GET /admin/banners?active=true
+// Усі банери громади, включно з завершеними і вимкненими
+func (h *BannerHandler) GetBanners(c *gin.Context) {
+	filter := communityScope(c, bson.M{})
+	if active := c.Query("active"); active != "" {
+		filter["is_active"] = active == "true"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	banners, err := h.find(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching banners",
+		})
+		return
+	}
+
+	now := time.Now()
+	items := make([]gin.H, 0, len(banners))
+	for i := range banners {
+		items = append(items, gin.H{
+			"banner":  banners[i],
+			"is_live": banners[i].IsLive(now),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  items,
+		"total": len(items),
+	})
+}
+
+// CreateBanner - POST /admin/banners
+func (h *BannerHandler) CreateBanner(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateBannerRequest(c, &req) {
+		return
+	}
+
+	now := time.Now()
+	banner := models.Banner{
+		CommunityID: getCommunityID(c),
+		CreatedBy:   userID,
+		CreatedAt:   now,
+	}
+	applyBannerRequest(&banner, &req, userID, now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.bannerCollection.InsertOne(ctx, banner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creating banner",
+			"details": err.Error(),
+		})
+		return
+	}
+	banner.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, banner)
+}
+
+// UpdateBanner - PUT /admin/banners/:id
+// Повна заміна налаштувань банера
+func (h *BannerHandler) UpdateBanner(c *gin.Context) {
+	bannerID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid banner ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req BannerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateBannerRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var banner models.Banner
+	err = h.bannerCollection.FindOne(ctx, communityScope(c, bson.M{"_id": bannerID})).Decode(&banner)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Banner not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching banner",
+		})
+		return
+	}
+
+	applyBannerRequest(&banner, &req, userID, time.Now())
+
+	if _, err := h.bannerCollection.ReplaceOne(ctx, bson.M{"_id": bannerID}, banner); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating banner",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, banner)
+}
+
+// DeleteBanner - DELETE /admin/banners/:id
+func (h *BannerHandler) DeleteBanner(c *gin.Context) {
+	bannerID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid banner ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.bannerCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": bannerID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting banner",
+		})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Banner not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Banner deleted successfully",
+	})
+}
+
+func (h *BannerHandler) find(ctx context.Context, filter bson.M) ([]models.Banner, error) {
+	cursor, err := h.bannerCollection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	banners := []models.Banner{}
+	if err := cursor.All(ctx, &banners); err != nil {
+		return nil, err
+	}
+	return banners, nil
+}
+
+// validateBannerRequest перевіряє посилання, ролі та період показу
+func validateBannerRequest(c *gin.Context, req *BannerRequest) bool {
+	if req.LinkURL != "" {
+		// Відносний шлях відкривається всередині застосунку, інакше - лише http(s)
+		link, err := url.Parse(req.LinkURL)
+		if err != nil || (!strings.HasPrefix(req.LinkURL, "/") && link.Scheme != "https" && link.Scheme != "http") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "link_url must be an http(s) URL or an app path starting with /",
+			})
+			return false
+		}
+	}
+	for _, role := range req.Roles {
+		if !models.UserRole(role).IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown role: " + role,
+			})
+			return false
+		}
+	}
+	if len(req.Roles) > 0 && req.Audience == models.BannerAudienceGuests {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Roles cannot be combined with guests audience",
+		})
+		return false
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ends_at must be after starts_at",
+		})
+		return false
+	}
+	return true
+}
+
+// applyBannerRequest переносить налаштування із запиту, підставляючи значення за замовчуванням
+func applyBannerRequest(banner *models.Banner, req *BannerRequest, userID primitive.ObjectID, now time.Time) {
+	banner.Title = req.Title
+	banner.Message = req.Message
+	banner.Severity = req.Severity
+	if banner.Severity == "" {
+		banner.Severity = models.BannerSeverityInfo
+	}
+	banner.LinkURL = req.LinkURL
+	banner.LinkLabel = req.LinkLabel
+	banner.Placement = req.Placement
+	if banner.Placement == "" {
+		banner.Placement = models.BannerPlacementHome
+	}
+	banner.Audience = req.Audience
+	if banner.Audience == "" {
+		banner.Audience = models.BannerAudienceAll
+		if len(req.Roles) > 0 {
+			banner.Audience = models.BannerAudienceAuthenticated
+		}
+	}
+	banner.Platforms = req.Platforms
+	banner.Roles = req.Roles
+	// Критичні повідомлення за замовчуванням не можна закрити
+	banner.Dismissible = banner.Severity != models.BannerSeverityCritical
+	if req.Dismissible != nil {
+		banner.Dismissible = *req.Dismissible
+	}
+	banner.Priority = req.Priority
+	banner.StartsAt = req.StartsAt
+	banner.EndsAt = req.EndsAt
+	banner.IsActive = true
+	if req.IsActive != nil {
+		banner.IsActive = *req.IsActive
+	}
+	banner.UpdatedBy = userID
+	banner.UpdatedAt = now
+}
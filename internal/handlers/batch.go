@@ -0,0 +1,220 @@
+// internal/handlers/batch.go
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	batchPathPrefix = "/api/v1/"
+	batchPath       = "/api/v1/batch"
+	// Одночасно виконуваних підзапитів одного batch
+	batchParallelism = 4
+	// Загальний час на весь batch; long-poll та повільні запити обриваються
+	batchTimeout = 15 * time.Second
+)
+
+// Методи, дозволені в підзапитах
+var batchMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// BatchHandler виконує кілька API-запитів за один HTTP round trip.
+// Потрібен мобільному застосунку під час холодного старту на повільних мережах:
+// замість ~8 послідовних запитів клієнт надсилає один.
+type BatchHandler struct {
+	router      http.Handler
+	maxRequests int
+}
+
+// NewBatchHandler створює обробник batch-запитів поверх основного роутера
+func NewBatchHandler(router http.Handler, maxRequests int) *BatchHandler {
+	if maxRequests <= 0 {
+		maxRequests = 10
+	}
+	return &BatchHandler{
+		router:      router,
+		maxRequests: maxRequests,
+	}
+}
+
+type BatchSubRequest struct {
+	ID      string            `json:"id,omitempty"` // Ідентифікатор клієнта, повертається у відповіді
+	Method  string            `json:"method" binding:"required"`
+	Path    string            `json:"path" binding:"required"` // Разом із query, напр. /api/v1/announcements?limit=5
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type BatchRequest struct {
+	Requests []BatchSubRequest `json:"requests" binding:"required,min=1,dive"`
+}
+
+type BatchSubResponse struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// batchResponseWriter збирає відповідь підзапиту в пам'яті
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBatchResponseWriter() *batchResponseWriter {
+	return &batchResponseWriter{header: make(http.Header)}
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// ExecuteBatch - POST /batch
+// Підзапити незалежні й виконуються паралельно; кожен проходить ті самі middleware
+// (автентифікація, громада, модулі, ліміти), що й окремий запит, з заголовками зовнішнього.
+// Порядок відповідей збігається з порядком підзапитів.
+func (h *BatchHandler) ExecuteBatch(c *gin.Context) {
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Requests) > h.maxRequests {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Too many sub-requests",
+			"limit": h.maxRequests,
+		})
+		return
+	}
+
+	for i := range req.Requests {
+		sub := &req.Requests[i]
+		sub.Method = strings.ToUpper(sub.Method)
+		if !batchMethods[sub.Method] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unsupported method in sub-request",
+				"index": i,
+			})
+			return
+		}
+		path := strings.SplitN(sub.Path, "?", 2)[0]
+		if !strings.HasPrefix(path, batchPathPrefix) || strings.TrimRight(path, "/") == batchPath {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Sub-request path must be an API path other than /api/v1/batch",
+				"index": i,
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), batchTimeout)
+	defer cancel()
+
+	responses := make([]BatchSubResponse, len(req.Requests))
+	semaphore := make(chan struct{}, batchParallelism)
+	var wg sync.WaitGroup
+
+	for i := range req.Requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			responses[i] = h.execute(ctx, c.Request, req.Requests[i])
+		}(i)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{
+		"responses": responses,
+	})
+}
+
+// execute виконує один підзапит через роутер
+func (h *BatchHandler) execute(ctx context.Context, parent *http.Request, sub BatchSubRequest) BatchSubResponse {
+	response := BatchSubResponse{ID: sub.ID}
+
+	request, err := http.NewRequestWithContext(ctx, sub.Method, sub.Path, bytes.NewReader(sub.Body))
+	if err != nil {
+		response.Status = http.StatusBadRequest
+		response.Body = gin.H{"error": "Invalid sub-request"}
+		return response
+	}
+
+	// Наслідуємо заголовки зовнішнього запиту (Authorization, X-Community, Accept-Language...)
+	for key, values := range parent.Header {
+		if key == "Content-Length" || key == "Accept-Encoding" {
+			continue
+		}
+		request.Header[key] = values
+	}
+	for key, value := range sub.Headers {
+		request.Header.Set(key, value)
+	}
+	if len(sub.Body) > 0 {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Host = parent.Host
+	request.RemoteAddr = parent.RemoteAddr
+
+	writer := newBatchResponseWriter()
+	h.router.ServeHTTP(writer, request)
+
+	response.Status = writer.status
+	if response.Status == 0 {
+		response.Status = http.StatusOK
+	}
+	for _, key := range []string{"ETag", "Last-Modified", "Cache-Control", "Location"} {
+		if value := writer.header.Get(key); value != "" {
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			response.Headers[key] = value
+		}
+	}
+
+	// JSON вкладаємо як є, інші формати (CSV, Atom) повертаємо рядком
+	raw := writer.body.Bytes()
+	if len(raw) > 0 {
+		if strings.Contains(writer.header.Get("Content-Type"), "json") && json.Valid(raw) {
+			response.Body = json.RawMessage(raw)
+		} else {
+			response.Body = string(raw)
+		}
+	}
+	return response
+}
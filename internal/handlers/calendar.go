@@ -0,0 +1,206 @@
+// internal/handlers/calendar.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Максимальний діапазон календаря в одному запиті
+const maxCalendarRangeDays = 366
+
+// CalendarHandler - державні свята та особливі дні громади
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+// NewCalendarHandler створює обробник календаря
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+type DeclareCalendarDayRequest struct {
+	Date        string `json:"date" binding:"required"` // YYYY-MM-DD
+	Kind        string `json:"kind" binding:"required,oneof=local_holiday closed_day working_day holiday_off"`
+	Name        string `json:"name" binding:"required,min=3,max=200"`
+	ScheduleDay string `json:"schedule_day,omitempty" binding:"omitempty,oneof=weekday saturday sunday holiday"`
+}
+
+// GetCalendar - GET /calendar?from=YYYY-MM-DD&to=YYYY-MM-DD
+// За замовчуванням - поточний місяць. Повертає особливі дні діапазону та підсумок на сьогодні.
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 1, -1)
+
+	var ok bool
+	if from, ok = parseCalendarDate(c, "from", from); !ok {
+		return
+	}
+	if to, ok = parseCalendarDate(c, "to", to); !ok {
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Parameter 'to' must not be before 'from'",
+		})
+		return
+	}
+	if to.Sub(from) > maxCalendarRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Calendar range is limited to 366 days",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	scope := communityScope(c, bson.M{})
+	days, err := h.calendarService.Days(ctx, from, to, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching calendar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	today, err := h.calendarService.Day(ctx, now, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching calendar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  from.Format(models.CalendarDateLayout),
+		"to":    to.Format(models.CalendarDateLayout),
+		"days":  days,
+		"today": today,
+	})
+}
+
+// DeclareDay - POST /admin/calendar/days
+// Місцеве свято, неприйомний день установ або перенесення робочого дня в громаді
+func (h *CalendarHandler) DeclareDay(c *gin.Context) {
+	var req DeclareCalendarDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	date, err := time.ParseInLocation(models.CalendarDateLayout, req.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid date, expected YYYY-MM-DD",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	day := models.CalendarDay{
+		CommunityID: getCommunityID(c),
+		Date:        date.Format(models.CalendarDateLayout),
+		Kind:        req.Kind,
+		Name:        req.Name,
+		ScheduleDay: req.ScheduleDay,
+		CreatedBy:   &userID,
+	}
+
+	err = h.calendarService.Declare(ctx, &day)
+	if err == services.ErrCalendarDayExists {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "A day is already declared for this date",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error declaring calendar day",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, day)
+}
+
+// RemoveDay - DELETE /admin/calendar/days/:id
+func (h *CalendarHandler) RemoveDay(c *gin.Context) {
+	dayID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid calendar day ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	removed, err := h.calendarService.Remove(ctx, dayID, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error removing calendar day",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Calendar day not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Calendar day removed",
+	})
+}
+
+// parseCalendarDate читає дату з query-параметра; false - відповідь з помилкою вже надіслана
+func parseCalendarDate(c *gin.Context, param string, defaultValue time.Time) (time.Time, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return defaultValue, true
+	}
+
+	date, err := time.ParseInLocation(models.CalendarDateLayout, value, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid date, expected YYYY-MM-DD",
+			"details": err.Error(),
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
@@ -0,0 +1,276 @@
+// internal/handlers/campaign.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CampaignHandler - push-кампанії адміністраторів з A/B тестуванням
+// 🔒 Всі методи вимагають ролі ADMIN
+type CampaignHandler struct {
+	campaignCollection *mongo.Collection
+	campaignService    *services.CampaignService
+}
+
+// CampaignVariantRequest - текст одного варіанту
+type CampaignVariantRequest struct {
+	Title string `json:"title" binding:"required,max=100"`
+	Body  string `json:"body" binding:"required,max=500"`
+}
+
+// CreateCampaignRequest - запит на створення кампанії
+type CreateCampaignRequest struct {
+	Name                  string                 `json:"name" binding:"required,min=3,max=200"`
+	VariantA              CampaignVariantRequest `json:"variant_a" binding:"required"`
+	VariantB              CampaignVariantRequest `json:"variant_b" binding:"required"`
+	TestPercentage        int                    `json:"test_percentage" binding:"omitempty,min=2,max=100"`
+	EvaluationWindowHours int                    `json:"evaluation_window_hours" binding:"omitempty,min=1,max=168"`
+}
+
+// NewCampaignHandler створює новий обробник push-кампаній
+func NewCampaignHandler(campaignCollection *mongo.Collection, campaignService *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignCollection: campaignCollection,
+		campaignService:    campaignService,
+	}
+}
+
+// CreateCampaign створює чернетку кампанії з двома варіантами
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Значення за замовчуванням: 20% аудиторії на тест, оцінка через 4 години
+	if req.TestPercentage == 0 {
+		req.TestPercentage = 20
+	}
+	if req.EvaluationWindowHours == 0 {
+		req.EvaluationWindowHours = 4
+	}
+
+	now := time.Now()
+	campaign := models.PushCampaign{
+		CommunityID: getCommunityID(c),
+		CreatedBy:   userID,
+		Name:        req.Name,
+		Variants: []models.CampaignVariant{
+			{Key: models.CampaignVariantA, Title: req.VariantA.Title, Body: req.VariantA.Body},
+			{Key: models.CampaignVariantB, Title: req.VariantB.Title, Body: req.VariantB.Body},
+		},
+		TestPercentage:        req.TestPercentage,
+		EvaluationWindowHours: req.EvaluationWindowHours,
+		Status:                models.CampaignStatusDraft,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.campaignCollection.InsertOne(ctx, campaign)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creating campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+	campaign.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// GetCampaigns повертає список кампаній поточної громади
+func (h *CampaignHandler) GetCampaigns(c *gin.Context) {
+	page, limit := pageParams(c, 20, 100)
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	filter = communityScope(c, filter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.campaignCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching campaigns",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	campaigns := []models.PushCampaign{}
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding campaigns",
+		})
+		return
+	}
+
+	total, _ := h.campaignCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, campaigns, info, nil, gin.H{"campaigns": campaigns, "pagination": info}))
+}
+
+// GetCampaign повертає кампанію з порівнянням відкриттів варіантів
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	campaignID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid campaign ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var campaign models.PushCampaign
+	if err := h.campaignCollection.FindOne(ctx, communityScope(c, bson.M{"_id": campaignID})).Decode(&campaign); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Campaign not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching campaign",
+		})
+		return
+	}
+
+	variants := make([]gin.H, 0, len(campaign.Variants))
+	for i := range campaign.Variants {
+		variant := &campaign.Variants[i]
+		variants = append(variants, gin.H{
+			"key":        variant.Key,
+			"sent":       variant.SentCount,
+			"opened":     variant.OpenCount,
+			"open_rate":  variant.OpenRate(),
+			"is_winner":  variant.Key == campaign.WinnerKey,
+			"is_leading": campaign.Status == models.CampaignStatusTesting && services.PickWinner(&campaign).Key == variant.Key,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"campaign": campaign,
+		"results":  variants,
+	})
+}
+
+// StartCampaign запускає тест: розсилає варіанти A та B тестовій частині аудиторії
+func (h *CampaignHandler) StartCampaign(c *gin.Context) {
+	campaignID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid campaign ID",
+		})
+		return
+	}
+
+	// Розсилка великій аудиторії може тривати довше за звичайний запит
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	count, err := h.campaignCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": campaignID}))
+	if err != nil || count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Campaign not found",
+		})
+		return
+	}
+
+	campaign, err := h.campaignService.Start(ctx, campaignID, c.GetBool("community_is_default"))
+	if errors.Is(err, services.ErrCampaignNotDraft) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Campaign has already been started or cancelled",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Error starting campaign",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// CancelCampaign скасовує чернетку або тест до розсилки переможця
+func (h *CampaignHandler) CancelCampaign(c *gin.Context) {
+	campaignID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid campaign ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := communityScope(c, bson.M{
+		"_id":    campaignID,
+		"status": bson.M{"$in": []string{models.CampaignStatusDraft, models.CampaignStatusTesting}},
+	})
+	result, err := h.campaignCollection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":     models.CampaignStatusCancelled,
+			"updated_at": time.Now(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error cancelling campaign",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Campaign not found or already finished",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Campaign cancelled",
+	})
+}
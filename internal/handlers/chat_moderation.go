@@ -0,0 +1,535 @@
+// internal/handlers/chat_moderation.go
+
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChatModerationHandler - скарги на повідомлення груп, черга перевірки модератором
+// та фільтр заборонених слів
+type ChatModerationHandler struct {
+	groupCollection   *mongo.Collection
+	messageCollection *mongo.Collection
+	reportCollection  *mongo.Collection
+	chatFilter        *services.ChatFilterService
+	trustService      *services.TrustService
+	wsHandler         *WebSocketHandler
+	moderationLog     *services.ModerationLogService
+	attachments       *services.ChatAttachmentService
+}
+
+// NewChatModerationHandler створює обробник модерації чатів
+func NewChatModerationHandler(groupCollection, messageCollection, reportCollection *mongo.Collection, chatFilter *services.ChatFilterService, trustService *services.TrustService, wsHandler *WebSocketHandler, moderationLog *services.ModerationLogService, attachments *services.ChatAttachmentService) *ChatModerationHandler {
+	return &ChatModerationHandler{
+		groupCollection:   groupCollection,
+		messageCollection: messageCollection,
+		reportCollection:  reportCollection,
+		chatFilter:        chatFilter,
+		trustService:      trustService,
+		wsHandler:         wsHandler,
+		moderationLog:     moderationLog,
+		attachments:       attachments,
+	}
+}
+
+// applyChatFilter перевіряє текст нового повідомлення фільтром заборонених слів:
+// flag - повідомлення публікується та потрапляє до черги модератора, hide - утримується
+func applyChatFilter(ctx context.Context, chatFilter *services.ChatFilterService, message *models.Message) {
+	if chatFilter == nil || message.Content == "" {
+		return
+	}
+	result := chatFilter.Check(ctx, message.Content)
+	if !result.Matched() {
+		return
+	}
+
+	message.Review = &models.MessageReview{
+		Reasons:      []string{models.MessageReviewBannedWord},
+		MatchedWords: result.Matches,
+		FlaggedAt:    message.CreatedAt,
+	}
+	if result.Action == models.BannedWordHide {
+		message.IsHeld = true
+	}
+}
+
+// ReportMessageRequest - скарга учасника групи
+type ReportMessageRequest struct {
+	Reason  string `json:"reason" binding:"required,oneof=spam abuse hate_speech misinformation other"`
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// ReviewMessageRequest - рішення модератора щодо повідомлення з черги
+type ReviewMessageRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=keep remove"`
+	Reason   string `json:"reason" binding:"max=500"`
+}
+
+// ReportMessage - POST /groups/:id/messages/:msgId/report
+// Повідомлення потрапляє до черги модератора; учасник скаржиться на повідомлення один раз
+func (h *ChatModerationHandler) ReportMessage(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	messageID, err := primitive.ObjectIDFromHex(c.Param("msgId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	var req ReportMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Скаржитися можуть лише учасники групи, які бачать повідомлення
+	isMember, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": groupID, "members": userID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if isMember == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	var message models.Message
+	err = h.messageCollection.FindOne(ctx, bson.M{
+		"_id":        messageID,
+		"group_id":   groupID,
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+	}).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching message",
+		})
+		return
+	}
+	if message.IsFromUser(userID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "You can't report your own message",
+		})
+		return
+	}
+
+	now := time.Now()
+	report := models.MessageReport{
+		ID:         primitive.NewObjectID(),
+		MessageID:  messageID,
+		GroupID:    groupID,
+		AuthorID:   message.UserID,
+		ReporterID: userID,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+		CreatedAt:  now,
+	}
+	if _, err := h.reportCollection.InsertOne(ctx, report); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "You have already reported this message",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error saving report",
+		})
+		return
+	}
+
+	// Повідомлення залишається в черзі до рішення модератора; нові скарги після рішення
+	// повертають його до черги
+	_, err = h.messageCollection.UpdateOne(ctx,
+		bson.M{"_id": messageID},
+		bson.M{
+			"$inc":      bson.M{"review.reports": 1},
+			"$addToSet": bson.M{"review.reasons": models.MessageReviewReported},
+			"$min":      bson.M{"review.flagged_at": now},
+		},
+	)
+	if err != nil {
+		log.Printf("Error queueing reported message %s for review: %v", messageID.Hex(), err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Report submitted",
+		"report":  report,
+	})
+}
+
+// GetReviewQueue - GET /moderation/messages/reported?reason=reported|banned_word
+// Опубліковані повідомлення зі скаргами або позначені фільтром: спочатку з найбільшою
+// кількістю скарг. Утримані фільтром повідомлення - у /moderation/messages/held.
+func (h *ChatModerationHandler) GetReviewQueue(c *gin.Context) {
+	page, limit := pageParams(c, 20, 100)
+
+	filter := bson.M{
+		"review":     bson.M{"$exists": true},
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+	}
+	if reason := c.Query("reason"); reason != "" {
+		if reason != models.MessageReviewReported && reason != models.MessageReviewBannedWord {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Reason must be reported or banned_word",
+			})
+			return
+		}
+		filter["review.reasons"] = reason
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "review.reports", Value: -1}, {Key: "review.flagged_at", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching review queue",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var messages []models.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding messages",
+		})
+		return
+	}
+	h.attachments.SignMessages(messages)
+
+	items, err := h.withReports(ctx, messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching reports",
+		})
+		return
+	}
+
+	total, _ := h.messageCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, items, info, nil, gin.H{"messages": items, "pagination": info}))
+}
+
+// withReports додає до повідомлень черги невирішені скарги: кількість за причинами та останні
+func (h *ChatModerationHandler) withReports(ctx context.Context, messages []models.Message) ([]models.MessageUnderReview, error) {
+	items := make([]models.MessageUnderReview, len(messages))
+	if len(messages) == 0 {
+		return items, nil
+	}
+
+	index := make(map[primitive.ObjectID]*models.MessageUnderReview, len(messages))
+	messageIDs := make([]primitive.ObjectID, len(messages))
+	for i := range messages {
+		items[i] = models.MessageUnderReview{Message: messages[i], Review: messages[i].Review}
+		index[messages[i].ID] = &items[i]
+		messageIDs[i] = messages[i].ID
+	}
+
+	cursor, err := h.reportCollection.Find(ctx,
+		bson.M{"message_id": bson.M{"$in": messageIDs}, "resolved_at": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var reports []models.MessageReport
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+
+	for _, report := range reports {
+		item := index[report.MessageID]
+		if item.ReportReasons == nil {
+			item.ReportReasons = make(map[string]int)
+		}
+		item.ReportReasons[report.Reason]++
+		if len(item.Reports) < models.MaxReviewReportsShown {
+			item.Reports = append(item.Reports, report)
+		}
+	}
+	return items, nil
+}
+
+// ReviewMessage - POST /moderation/messages/:id/review
+// keep - повідомлення залишається та виходить із черги, remove - видаляється для всіх
+// учасників групи, довіра до автора знижується
+func (h *ChatModerationHandler) ReviewMessage(c *gin.Context) {
+	messageID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	var req ReviewMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	moderatorID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":        messageID,
+		"review":     bson.M{"$exists": true},
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set":   bson.M{"updated_at": now},
+		"$unset": bson.M{"review": ""},
+	}
+	decision, resolution := models.ModerationDecisionApproved, models.MessageReportKept
+	if req.Decision == models.MessageReviewRemove {
+		decision, resolution = models.ModerationDecisionRejected, models.MessageReportRemoved
+		update = bson.M{
+			"$set": bson.M{
+				"is_deleted": true,
+				"content":    "",
+				"deleted_at": now,
+				"deleted_by": moderatorID,
+				"updated_at": now,
+			},
+			"$unset": bson.M{"review": "", "media_url": "", "media_type": "", "media_size": "", "edit_history": "", "attachments": ""},
+		}
+	}
+
+	var message models.Message
+	err = h.messageCollection.FindOneAndUpdate(ctx, filter, update).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Message is not awaiting review",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error reviewing message",
+		})
+		return
+	}
+
+	if _, err := h.reportCollection.UpdateMany(ctx,
+		bson.M{"message_id": messageID, "resolved_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"resolution": resolution, "resolved_at": now}},
+	); err != nil {
+		log.Printf("Error resolving reports of message %s: %v", messageID.Hex(), err)
+	}
+
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: getCommunityID(c),
+		ModeratorID: moderatorID,
+		ContentType: models.ModerationContentMessage,
+		ContentID:   message.ID,
+		AuthorID:    message.UserID,
+		Decision:    decision,
+		Reason:      req.Reason,
+		SubmittedAt: message.Review.FlaggedAt,
+	})
+
+	if req.Decision == models.MessageReviewKeep {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Message kept",
+		})
+		return
+	}
+
+	if len(message.Attachments) > 0 {
+		if _, err := h.attachments.RemoveForMessage(ctx, messageID); err != nil {
+			log.Printf("Error removing attachments of message %s: %v", messageID.Hex(), err)
+		}
+	}
+	if err := h.trustService.RecordRemoval(ctx, message.UserID); err != nil {
+		log.Printf("Error recording removal of message %s: %v", messageID.Hex(), err)
+	}
+	h.wsHandler.SendSystemMessage(message.GroupID, "message_deleted", gin.H{
+		"id":         messageID,
+		"group_id":   message.GroupID,
+		"deleted_by": moderatorID,
+		"deleted_at": now,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message removed",
+	})
+}
+
+// BannedWordRequest - правило фільтра заборонених слів
+type BannedWordRequest struct {
+	Word   string `json:"word" binding:"required"`
+	Action string `json:"action" binding:"required,oneof=flag hide"`
+}
+
+// GetBannedWords - GET /admin/chat/banned-words
+func (h *ChatModerationHandler) GetBannedWords(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	words, err := h.chatFilter.List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching banned words",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"banned_words": words,
+	})
+}
+
+// CreateBannedWord - POST /admin/chat/banned-words
+// Правило застосовується до нових повідомлень; вже надіслані не перевіряються
+func (h *ChatModerationHandler) CreateBannedWord(c *gin.Context) {
+	var req BannedWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	word, ok := services.NormalizeBannedWord(req.Word)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid banned word",
+			"details": "A single word of letters and digits up to 50 characters, optionally ending with * to match word beginnings",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bannedWord := &models.BannedWord{
+		Word:      word,
+		Action:    req.Action,
+		CreatedBy: &userID,
+	}
+	err = h.chatFilter.Add(ctx, bannedWord)
+	if err == services.ErrBannedWordExists {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This word is already in the filter",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error saving banned word",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, bannedWord)
+}
+
+// RemoveBannedWord - DELETE /admin/chat/banned-words/:id
+func (h *ChatModerationHandler) RemoveBannedWord(c *gin.Context) {
+	wordID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid banned word ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	removed, err := h.chatFilter.Remove(ctx, wordID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error removing banned word",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Banned word not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Banned word removed",
+	})
+}
@@ -4,9 +4,13 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
 	"nova-kakhovka-ecity/internal/services"
 
@@ -21,17 +25,22 @@ type CityIssueHandler struct {
 	issueCollection     *mongo.Collection
 	userCollection      *mongo.Collection
 	notificationService *services.NotificationService
+	taxonomyService     *services.TaxonomyService
+	digestService       *services.IssueDigestService
+	incidentService     *services.TransportIncidentService
+	relatedService      *services.RelatedContentService
 }
 
 type CreateIssueRequest struct {
-	Title       string          `json:"title" validate:"required,min=5,max=200"`
-	Description string          `json:"description" validate:"required,min=10,max=1000"`
-	Category    string          `json:"category" validate:"required,oneof=road lighting water electricity waste transport building safety other"`
-	Priority    string          `json:"priority" validate:"oneof=low medium high critical"`
-	Location    models.Location `json:"location" validate:"required"`
-	Address     string          `json:"address" validate:"required"`
-	Photos      []string        `json:"photos"`
-	Videos      []string        `json:"videos"`
+	Title        string          `json:"title" validate:"required,min=5,max=200"`
+	Description  string          `json:"description" validate:"required,min=10,max=1000"`
+	Category     string          `json:"category" validate:"required"` // Код із таксономії модуля city_issues
+	Priority     string          `json:"priority" validate:"oneof=low medium high critical"`
+	Location     models.Location `json:"location" validate:"required"`
+	Address      string          `json:"address" validate:"required"`
+	Neighborhood string          `json:"neighborhood"` // Мікрорайон, до 100 символів
+	Photos       []string        `json:"photos"`
+	Videos       []string        `json:"videos"`
 }
 
 type UpdateIssueStatusRequest struct {
@@ -42,6 +51,10 @@ type UpdateIssueStatusRequest struct {
 	DuplicateOf    string `json:"duplicate_of,omitempty"`
 }
 
+type UpdateIssueSubscriptionRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=all official digest"`
+}
+
 type AddCommentRequest struct {
 	Content string `json:"content" validate:"required,min=1,max=500"`
 }
@@ -62,11 +75,15 @@ type IssueFilters struct {
 	SortOrder  string    `form:"sort_order"`
 }
 
-func NewCityIssueHandler(issueCollection, userCollection *mongo.Collection, notificationService *services.NotificationService) *CityIssueHandler {
+func NewCityIssueHandler(issueCollection, userCollection *mongo.Collection, notificationService *services.NotificationService, taxonomyService *services.TaxonomyService, digestService *services.IssueDigestService, incidentService *services.TransportIncidentService, relatedService *services.RelatedContentService) *CityIssueHandler {
 	return &CityIssueHandler{
 		issueCollection:     issueCollection,
 		userCollection:      userCollection,
 		notificationService: notificationService,
+		taxonomyService:     taxonomyService,
+		digestService:       digestService,
+		incidentService:     incidentService,
+		relatedService:      relatedService,
 	}
 }
 
@@ -79,6 +96,13 @@ func (h *CityIssueHandler) CreateIssue(c *gin.Context) {
 		})
 		return
 	}
+	if !validateCategory(c, h.taxonomyService, models.ModuleCityIssues, req.Category) {
+		return
+	}
+	neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+	if !ok {
+		return
+	}
 
 	userID, _ := c.Get("user_id")
 	userIDObj, err := primitive.ObjectIDFromHex(userID.(string))
@@ -116,17 +140,19 @@ func (h *CityIssueHandler) CreateIssue(c *gin.Context) {
 
 	now := time.Now()
 	issue := models.CityIssue{
-		ReporterID:  userIDObj,
-		Title:       req.Title,
-		Description: req.Description,
-		Category:    req.Category,
-		Status:      models.IssueStatusReported,
-		Priority:    req.Priority,
-		Location:    req.Location,
-		Address:     req.Address,
-		Photos:      req.Photos,
-		Videos:      req.Videos,
-		Comments:    []models.IssueComment{},
+		ReporterID:   userIDObj,
+		Title:        req.Title,
+		Description:  req.Description,
+		Language:     services.DetectLanguage(req.Title, req.Description),
+		Category:     req.Category,
+		Status:       models.IssueStatusReported,
+		Priority:     req.Priority,
+		Location:     req.Location,
+		Address:      req.Address,
+		Neighborhood: neighborhood,
+		Photos:       req.Photos,
+		Videos:       req.Videos,
+		Comments:     []models.IssueComment{},
 		StatusHistory: []models.IssueStatusChange{
 			{
 				Status:    models.IssueStatusReported,
@@ -142,9 +168,22 @@ func (h *CityIssueHandler) CreateIssue(c *gin.Context) {
 		UpdatedAt:   now,
 		ViewCount:   0,
 		Subscribers: []primitive.ObjectID{userIDObj},
+		CommunityID: getCommunityID(c),
 	}
 
-	result, err := h.issueCollection.InsertOne(ctx, issue)
+	// Про критичну проблему модератори дізнаються одразу: сповіщення записується разом із нею
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		result, err := h.issueCollection.InsertOne(ctx, issue)
+		if err != nil {
+			return err
+		}
+		issue.ID = result.InsertedID.(primitive.ObjectID)
+
+		if req.Priority != models.PriorityCritical {
+			return nil
+		}
+		return h.notifyModeratorsAboutNewIssue(ctx, issue)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creating issue",
@@ -152,11 +191,7 @@ func (h *CityIssueHandler) CreateIssue(c *gin.Context) {
 		return
 	}
 
-	issue.ID = result.InsertedID.(primitive.ObjectID)
-
-	if req.Priority == models.PriorityCritical {
-		h.notifyModeratorsAboutNewIssue(issue)
-	}
+	h.syncTransportIncident(ctx, &issue)
 
 	c.JSON(http.StatusCreated, issue)
 }
@@ -181,7 +216,7 @@ func (h *CityIssueHandler) GetIssues(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	query := bson.M{}
+	query := communityScope(c, bson.M{})
 
 	if filters.Category != "" {
 		query["category"] = filters.Category
@@ -213,6 +248,9 @@ func (h *CityIssueHandler) GetIssues(c *gin.Context) {
 	if filters.IsVerified != nil {
 		query["is_verified"] = *filters.IsVerified
 	}
+	if !applyLanguageFilter(c, query) {
+		return
+	}
 
 	if filters.Bounds != "" {
 		var lat1, lng1, lat2, lng2 float64
@@ -270,14 +308,88 @@ func (h *CityIssueHandler) GetIssues(c *gin.Context) {
 
 	total, _ := h.issueCollection.CountDocuments(ctx, query)
 
+	info := newPageInfo(filters.Page, filters.Limit, total)
+	c.JSON(http.StatusOK, listResponse(c, issues, info, nil, gin.H{"issues": issues, "pagination": info}))
+}
+
+// FindSimilarIssues - GET /city-issues/similar?title=...&description=...&lat=...&lng=...&radius=500
+// Пропонує відкриті проблеми, схожі на нову (зокрема описані іншою мовою); з координатами - лише поблизу.
+func (h *CityIssueHandler) FindSimilarIssues(c *gin.Context) {
+	query, ok := parseDuplicateQuery(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "title is required",
+		})
+		return
+	}
+
+	filter := communityScope(c, bson.M{
+		"status": bson.M{"$in": []string{models.IssueStatusReported, models.IssueStatusInProgress}},
+	})
+
+	var origin *models.Location
+	if c.Query("lat") != "" && c.Query("lng") != "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+		if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid coordinates",
+			})
+			return
+		}
+		radius, err := strconv.ParseFloat(c.DefaultQuery("radius", "500"), 64)
+		if err != nil || radius <= 0 || radius > 5000 {
+			radius = 500
+		}
+		origin = &models.Location{Type: "Point", Coordinates: []float64{lng, lat}}
+		filter["location"] = bson.M{
+			"$geoWithin": bson.M{
+				"$centerSphere": []interface{}{[]float64{lng, lat}, radius / 6371000},
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.issueCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"title": 1, "description": 1, "status": 1, "location": 1, "created_at": 1}).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(duplicateCandidateLimit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching issues",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var issues []models.CityIssue
+	if err := cursor.All(ctx, &issues); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding issues",
+		})
+		return
+	}
+
+	suggestions := make([]DuplicateSuggestion, 0, len(issues))
+	for _, issue := range issues {
+		suggestion := DuplicateSuggestion{
+			ID:        issue.ID,
+			Title:     issue.Title,
+			Status:    issue.Status,
+			Score:     query.score(issue.Title, issue.Description),
+			CreatedAt: issue.CreatedAt,
+		}
+		if origin != nil && len(issue.Location.Coordinates) == 2 {
+			distance := math.Round(calculateDistance(*origin, issue.Location) * 1000)
+			suggestion.DistanceMeters = &distance
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"issues": issues,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       total,
-			"total_pages": (total + int64(filters.Limit) - 1) / int64(filters.Limit),
-		},
+		"similar": topDuplicateSuggestions(suggestions),
 	})
 }
 
@@ -294,7 +406,7 @@ func (h *CityIssueHandler) GetIssue(c *gin.Context) {
 	defer cancel()
 
 	var issue models.CityIssue
-	err = h.issueCollection.FindOne(ctx, bson.M{"_id": issueID}).Decode(&issue)
+	err = h.issueCollection.FindOne(ctx, communityScope(c, bson.M{"_id": issueID})).Decode(&issue)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -314,6 +426,9 @@ func (h *CityIssueHandler) GetIssue(c *gin.Context) {
 		bson.M{"$inc": bson.M{"view_count": 1}},
 	)
 
+	// "Люди також повідомляли" - підбірка фонового завдання
+	issue.Related = h.relatedService.For(ctx, models.ModuleCityIssues, issueID)
+
 	c.JSON(http.StatusOK, issue)
 }
 
@@ -341,7 +456,7 @@ func (h *CityIssueHandler) UpvoteIssue(c *gin.Context) {
 	defer cancel()
 
 	var issue models.CityIssue
-	err = h.issueCollection.FindOne(ctx, bson.M{"_id": issueID}).Decode(&issue)
+	err = h.issueCollection.FindOne(ctx, communityScope(c, bson.M{"_id": issueID})).Decode(&issue)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -368,7 +483,7 @@ func (h *CityIssueHandler) UpvoteIssue(c *gin.Context) {
 	// Додаємо голос
 	_, err = h.issueCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": issueID},
+		communityScope(c, bson.M{"_id": issueID}),
 		bson.M{
 			"$push": bson.M{"upvotes": userIDObj},
 			"$inc":  bson.M{"upvote_count": 1},
@@ -413,7 +528,8 @@ func (h *CityIssueHandler) AddComment(c *gin.Context) {
 		})
 		return
 	}
-	isModerator, _ := c.Get("is_moderator")
+	// Комментарий модератора помечается как официальный ответ
+	isOfficial := middleware.UserHasPermission(c, models.PermissionModerateCityIssue)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -423,17 +539,26 @@ func (h *CityIssueHandler) AddComment(c *gin.Context) {
 		AuthorID:   userIDObj,
 		Content:    req.Content,
 		CreatedAt:  time.Now(),
-		IsOfficial: isModerator.(bool),
+		IsOfficial: isOfficial,
 	}
 
-	result, err := h.issueCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": issueID},
-		bson.M{
-			"$push": bson.M{"comments": comment},
-			"$set":  bson.M{"updated_at": time.Now()},
-		},
-	)
+	// Коментар і сповіщення підписникам записуються разом
+	var result *mongo.UpdateResult
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = h.issueCollection.UpdateOne(
+			ctx,
+			communityScope(c, bson.M{"_id": issueID}),
+			bson.M{
+				"$push": bson.M{"comments": comment},
+				"$set":  bson.M{"updated_at": time.Now()},
+			},
+		)
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
+		return h.notifySubscribersAboutComment(ctx, issueID, userIDObj, req.Content, isOfficial)
+	})
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -449,8 +574,6 @@ func (h *CityIssueHandler) AddComment(c *gin.Context) {
 		return
 	}
 
-	h.notifySubscribersAboutComment(issueID, userIDObj, req.Content, isModerator.(bool))
-
 	c.JSON(http.StatusCreated, comment)
 }
 
@@ -475,10 +598,10 @@ func (h *CityIssueHandler) SubscribeToIssue(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	count, err := h.issueCollection.CountDocuments(ctx, bson.M{
+	count, err := h.issueCollection.CountDocuments(ctx, communityScope(c, bson.M{
 		"_id":         issueID,
 		"subscribers": userIDObj,
-	})
+	}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Database error",
@@ -489,8 +612,11 @@ func (h *CityIssueHandler) SubscribeToIssue(c *gin.Context) {
 	if count > 0 {
 		result, err := h.issueCollection.UpdateOne(
 			ctx,
-			bson.M{"_id": issueID},
-			bson.M{"$pull": bson.M{"subscribers": userIDObj}},
+			communityScope(c, bson.M{"_id": issueID}),
+			bson.M{
+				"$pull":  bson.M{"subscribers": userIDObj},
+				"$unset": bson.M{"notification_modes." + userIDObj.Hex(): ""},
+			},
 		)
 		if err != nil || result.MatchedCount == 0 {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -505,7 +631,7 @@ func (h *CityIssueHandler) SubscribeToIssue(c *gin.Context) {
 	} else {
 		result, err := h.issueCollection.UpdateOne(
 			ctx,
-			bson.M{"_id": issueID},
+			communityScope(c, bson.M{"_id": issueID}),
 			bson.M{"$addToSet": bson.M{"subscribers": userIDObj}},
 		)
 		if err != nil || result.MatchedCount == 0 {
@@ -517,10 +643,71 @@ func (h *CityIssueHandler) SubscribeToIssue(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"message":    "Subscribed successfully",
 			"subscribed": true,
+			"mode":       models.IssueNotifyAll,
 		})
 	}
 }
 
+// UpdateSubscription задає режим сповіщень підписника: all, official або digest.
+// Якщо користувач ще не підписаний - підписує.
+func (h *CityIssueHandler) UpdateSubscription(c *gin.Context) {
+	issueID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid issue ID",
+		})
+		return
+	}
+
+	var req UpdateIssueSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$addToSet": bson.M{"subscribers": userIDObj}}
+	if req.Mode == models.IssueNotifyAll {
+		update["$unset"] = bson.M{"notification_modes." + userIDObj.Hex(): ""}
+	} else {
+		update["$set"] = bson.M{"notification_modes." + userIDObj.Hex(): req.Mode}
+	}
+
+	result, err := h.issueCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": issueID}), update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Issue not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Subscription updated successfully",
+		"subscribed": true,
+		"mode":       req.Mode,
+	})
+}
+
 func (h *CityIssueHandler) GetNearbyIssues(c *gin.Context) {
 	lat := c.DefaultQuery("lat", "")
 	lng := c.DefaultQuery("lng", "")
@@ -557,7 +744,7 @@ func (h *CityIssueHandler) GetNearbyIssues(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := h.issueCollection.Find(ctx, bson.M{
+	cursor, err := h.issueCollection.Find(ctx, communityScope(c, bson.M{
 		"location": bson.M{
 			"$near": bson.M{
 				"$geometry": bson.M{
@@ -568,7 +755,7 @@ func (h *CityIssueHandler) GetNearbyIssues(c *gin.Context) {
 			},
 		},
 		"status": bson.M{"$nin": []string{models.IssueStatusResolved, models.IssueStatusRejected}},
-	}, options.Find().SetLimit(50))
+	}), options.Find().SetLimit(50))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -656,8 +843,10 @@ func (h *CityIssueHandler) UpdateIssue(c *gin.Context) {
 	}
 
 	type UpdateIssueRequest struct {
-		Title       string `json:"title,omitempty"`
-		Description string `json:"description,omitempty"`
+		Title        string `json:"title,omitempty"`
+		Description  string `json:"description,omitempty"`
+		Category     string `json:"category,omitempty"`
+		Neighborhood string `json:"neighborhood,omitempty"`
 	}
 
 	var req UpdateIssueRequest
@@ -682,7 +871,7 @@ func (h *CityIssueHandler) UpdateIssue(c *gin.Context) {
 	defer cancel()
 
 	var issue models.CityIssue
-	err = h.issueCollection.FindOne(ctx, bson.M{"_id": issueID}).Decode(&issue)
+	err = h.issueCollection.FindOne(ctx, communityScope(c, bson.M{"_id": issueID})).Decode(&issue)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -713,10 +902,26 @@ func (h *CityIssueHandler) UpdateIssue(c *gin.Context) {
 	if req.Description != "" {
 		update["description"] = req.Description
 	}
+	if req.Title != "" || req.Description != "" {
+		update["language"] = detectEditedLanguage(issue.Title, issue.Description, req.Title, req.Description)
+	}
+	if req.Category != "" {
+		if !validateCategory(c, h.taxonomyService, models.ModuleCityIssues, req.Category) {
+			return
+		}
+		update["category"] = req.Category
+	}
+	if req.Neighborhood != "" {
+		neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+		if !ok {
+			return
+		}
+		update["neighborhood"] = neighborhood
+	}
 
 	_, err = h.issueCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": issueID},
+		communityScope(c, bson.M{"_id": issueID}),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -726,6 +931,11 @@ func (h *CityIssueHandler) UpdateIssue(c *gin.Context) {
 		return
 	}
 
+	if req.Category != "" && req.Category != issue.Category {
+		issue.Category = req.Category
+		h.syncTransportIncident(ctx, &issue)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Issue updated successfully",
 	})
@@ -786,14 +996,23 @@ func (h *CityIssueHandler) UpdateIssueStatus(c *gin.Context) {
 		update["resolved_at"] = time.Now()
 	}
 
-	result, err := h.issueCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": issueID},
-		bson.M{
-			"$set":  update,
-			"$push": bson.M{"status_history": statusChange},
-		},
-	)
+	// Статус і сповіщення підписникам записуються разом
+	var result *mongo.UpdateResult
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = h.issueCollection.UpdateOne(
+			ctx,
+			withModerationScope(c, communityScope(c, bson.M{"_id": issueID})),
+			bson.M{
+				"$set":  update,
+				"$push": bson.M{"status_history": statusChange},
+			},
+		)
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
+		return h.notifySubscribersAboutStatusChange(ctx, issueID, req.Status, req.Note)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error updating status",
@@ -802,14 +1021,20 @@ func (h *CityIssueHandler) UpdateIssueStatus(c *gin.Context) {
 	}
 
 	if result.MatchedCount == 0 {
+		if respondOutOfScope(ctx, c, h.issueCollection, issueID) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Issue not found",
 		})
 		return
 	}
 
-	// Сповіщаємо підписників
-	h.notifySubscribersAboutStatusChange(issueID, req.Status, req.Note)
+	// Закрита проблема знімає позначки з маршрутів, повторно відкрита - ставить знову
+	var issue models.CityIssue
+	if err := h.issueCollection.FindOne(ctx, communityScope(c, bson.M{"_id": issueID})).Decode(&issue); err == nil {
+		h.syncTransportIncident(ctx, &issue)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Issue status updated successfully",
@@ -817,6 +1042,76 @@ func (h *CityIssueHandler) UpdateIssueStatus(c *gin.Context) {
 	})
 }
 
+// UpdateIssuePriority - зміна пріоритету (модератор).
+// Критична проблема категорії road/safety біля маршруту позначає маршрути і створює чернетку оголошення про об'їзд.
+func (h *CityIssueHandler) UpdateIssuePriority(c *gin.Context) {
+	issueID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid issue ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	type PriorityUpdateRequest struct {
+		Priority string `json:"priority" binding:"required,oneof=low medium high critical"`
+	}
+
+	var req PriorityUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid priority",
+			"details": "Priority must be low, medium, high, or critical",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var issue models.CityIssue
+	err = h.issueCollection.FindOneAndUpdate(ctx,
+		withModerationScope(c, communityScope(c, bson.M{"_id": issueID})),
+		bson.M{"$set": bson.M{"priority": req.Priority, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&issue)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if respondOutOfScope(ctx, c, h.issueCollection, issueID) {
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Issue not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating priority",
+		})
+		return
+	}
+
+	h.syncTransportIncident(ctx, &issue)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Issue priority updated successfully",
+		"priority":           issue.Priority,
+		"affected_route_ids": issue.AffectedRouteIDs,
+		"transport_alert_id": issue.TransportAlertID,
+	})
+}
+
+// syncTransportIncident оновлює позначки маршрутів після зміни проблеми; помилка не скасовує саму зміну
+func (h *CityIssueHandler) syncTransportIncident(ctx context.Context, issue *models.CityIssue) {
+	if h.incidentService == nil {
+		return
+	}
+	if err := h.incidentService.Sync(ctx, issue); err != nil {
+		log.Printf("Error syncing transport incident for issue %s: %v", issue.ID.Hex(), err)
+	}
+}
+
 // AssignIssue - призначення відповідального
 func (h *CityIssueHandler) AssignIssue(c *gin.Context) {
 	issueID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -877,7 +1172,7 @@ func (h *CityIssueHandler) AssignIssue(c *gin.Context) {
 
 	result, err := h.issueCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": issueID},
+		withModerationScope(c, communityScope(c, bson.M{"_id": issueID})),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -888,6 +1183,9 @@ func (h *CityIssueHandler) AssignIssue(c *gin.Context) {
 	}
 
 	if result.MatchedCount == 0 {
+		if respondOutOfScope(ctx, c, h.issueCollection, issueID) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Issue not found",
 		})
@@ -900,14 +1198,11 @@ func (h *CityIssueHandler) AssignIssue(c *gin.Context) {
 	})
 }
 
-// Допоміжні функції для сповіщень
-func (h *CityIssueHandler) notifyModeratorsAboutNewIssue(issue models.CityIssue) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+// Допоміжні функції для сповіщень: ставлять намір в outbox у контексті транзакції обробника
+func (h *CityIssueHandler) notifyModeratorsAboutNewIssue(ctx context.Context, issue models.CityIssue) error {
 	cursor, err := h.userCollection.Find(ctx, bson.M{"is_moderator": true})
 	if err != nil {
-		return
+		return err
 	}
 	defer cursor.Close(ctx)
 
@@ -920,113 +1215,126 @@ func (h *CityIssueHandler) notifyModeratorsAboutNewIssue(issue models.CityIssue)
 		moderatorIDs = append(moderatorIDs, user.ID)
 	}
 
-	if len(moderatorIDs) > 0 {
-		data := map[string]interface{}{
+	return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+		Source:  "city_issue.critical_reported",
+		UserIDs: moderatorIDs,
+		Title:   "Новая проблема в городе",
+		Body:    fmt.Sprintf("Категория: %s - %s", issue.Category, issue.Title),
+		Type:    services.NotificationTypeSystem,
+		Data: map[string]interface{}{
 			"issue_id": issue.ID.Hex(),
 			"category": issue.Category,
 			"priority": issue.Priority,
-		}
-
-		h.notificationService.SendNotificationToUsers(
-			ctx,
-			moderatorIDs,
-			"Новая проблема в городе",
-			fmt.Sprintf("Категория: %s - %s", issue.Category, issue.Title),
-			services.NotificationTypeSystem,
-			data,
-			&issue.ID,
-		)
-	}
+		},
+		RelatedID: &issue.ID,
+	})
 }
 
-func (h *CityIssueHandler) notifySubscribersAboutComment(issueID, authorID primitive.ObjectID, commentText string, isOfficial bool) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (h *CityIssueHandler) notifySubscribersAboutComment(ctx context.Context, issueID, authorID primitive.ObjectID, commentText string, isOfficial bool) error {
 	var issue models.CityIssue
 	err := h.issueCollection.FindOne(ctx, bson.M{"_id": issueID}).Decode(&issue)
 	if err != nil {
-		return
+		return err
 	}
 
-	var subscribersToNotify []primitive.ObjectID
-	for _, subscriberID := range issue.Subscribers {
-		if subscriberID != authorID {
-			subscribersToNotify = append(subscribersToNotify, subscriberID)
-		}
+	preview := []rune(commentText)
+	if len(preview) > 50 {
+		preview = append(preview[:50], []rune("...")...)
+	}
+	update := models.IssueUpdate{
+		Kind:     models.IssueUpdateComment,
+		Official: isOfficial,
+		Preview:  string(preview),
 	}
 
-	if len(subscribersToNotify) > 0 {
-		var title string
-		if isOfficial {
-			title = "Официальный ответ по проблеме"
-		} else {
-			title = "Новый комментарий к проблеме"
-		}
+	subscribersToNotify := h.routeIssueUpdate(ctx, &issue, authorID, update)
 
-		data := map[string]interface{}{
+	var title string
+	if isOfficial {
+		title = "Официальный ответ по проблеме"
+	} else {
+		title = "Новый комментарий к проблеме"
+	}
+
+	return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+		Source:  "city_issue.comment_added",
+		UserIDs: subscribersToNotify,
+		Title:   title,
+		Body:    fmt.Sprintf("%s: %s", issue.Title, update.Preview),
+		Type:    services.NotificationTypeSystem,
+		Data: map[string]interface{}{
 			"issue_id":    issueID.Hex(),
 			"is_official": isOfficial,
-		}
+		},
+		RelatedID: &issueID,
+	})
+}
 
-		preview := commentText
-		if len(preview) > 50 {
-			preview = preview[:50] + "..."
+// routeIssueUpdate распределяет подписчиков по режимам уведомлений: несрочное для режима digest
+// откладывается в дайджест, в режиме official неофициальные комментарии не приходят.
+// Возвращает тех, кого нужно уведомить сразу.
+func (h *CityIssueHandler) routeIssueUpdate(ctx context.Context, issue *models.CityIssue, authorID primitive.ObjectID, update models.IssueUpdate) []primitive.ObjectID {
+	var immediate, digest []primitive.ObjectID
+	for _, subscriberID := range issue.Subscribers {
+		if subscriberID == authorID {
+			continue
+		}
+		now, later := update.Delivery(issue.NotificationMode(subscriberID))
+		if now {
+			immediate = append(immediate, subscriberID)
+		} else if later {
+			digest = append(digest, subscriberID)
 		}
+	}
 
-		h.notificationService.SendNotificationToUsers(
-			ctx,
-			subscribersToNotify,
-			title,
-			fmt.Sprintf("%s: %s", issue.Title, preview),
-			services.NotificationTypeSystem,
-			data,
-			&issueID,
-		)
+	if err := h.digestService.Queue(ctx, digest, issue, update); err != nil {
+		log.Printf("Error queueing issue digest for %s: %v", issue.ID.Hex(), err)
 	}
+	return immediate
 }
 
-func (h *CityIssueHandler) notifySubscribersAboutStatusChange(issueID primitive.ObjectID, newStatus, note string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (h *CityIssueHandler) notifySubscribersAboutStatusChange(ctx context.Context, issueID primitive.ObjectID, newStatus, note string) error {
 	var issue models.CityIssue
 	err := h.issueCollection.FindOne(ctx, bson.M{"_id": issueID}).Decode(&issue)
 	if err != nil {
-		return
+		return err
 	}
 
-	if len(issue.Subscribers) > 0 {
-		statusTranslations := map[string]string{
-			models.IssueStatusReported:   "зарегистрирована",
-			models.IssueStatusInProgress: "принята в работу",
-			models.IssueStatusResolved:   "решена",
-			models.IssueStatusRejected:   "отклонена",
-		}
+	update := models.IssueUpdate{
+		Kind:     models.IssueUpdateStatus,
+		Official: true,
+		Status:   newStatus,
+		Preview:  note,
+	}
+	subscribers := h.routeIssueUpdate(ctx, &issue, primitive.NilObjectID, update)
 
-		statusText := statusTranslations[newStatus]
-		if statusText == "" {
-			statusText = newStatus
-		}
+	statusTranslations := map[string]string{
+		models.IssueStatusReported:   "зарегистрирована",
+		models.IssueStatusInProgress: "принята в работу",
+		models.IssueStatusResolved:   "решена",
+		models.IssueStatusRejected:   "отклонена",
+	}
 
-		body := fmt.Sprintf("Проблема '%s' %s", issue.Title, statusText)
-		if note != "" {
-			body += ". " + note
-		}
+	statusText := statusTranslations[newStatus]
+	if statusText == "" {
+		statusText = newStatus
+	}
+
+	body := fmt.Sprintf("Проблема '%s' %s", issue.Title, statusText)
+	if note != "" {
+		body += ". " + note
+	}
 
-		data := map[string]interface{}{
+	return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+		Source:  "city_issue.status_changed",
+		UserIDs: subscribers,
+		Title:   "Изменение статуса проблемы",
+		Body:    body,
+		Type:    services.NotificationTypeSystem,
+		Data: map[string]interface{}{
 			"issue_id":   issueID.Hex(),
 			"new_status": newStatus,
-		}
-
-		h.notificationService.SendNotificationToUsers(
-			ctx,
-			issue.Subscribers,
-			"Изменение статуса проблемы",
-			body,
-			services.NotificationTypeSystem,
-			data,
-			&issueID,
-		)
-	}
+		},
+		RelatedID: &issueID,
+	})
 }
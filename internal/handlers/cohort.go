@@ -0,0 +1,479 @@
+// internal/handlers/cohort.go
+
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultCohortWeeks = 12
+	maxCohortWeeks     = 52
+)
+
+// CohortHandler - когорти реєстрацій, утримання та збережені сегменти користувачів (ADMIN)
+type CohortHandler struct {
+	userCollection     *mongo.Collection
+	activityCollection *mongo.Collection
+	segmentCollection  *mongo.Collection
+}
+
+// NewCohortHandler створює обробник когортної аналітики
+func NewCohortHandler(userCollection, activityCollection, segmentCollection *mongo.Collection) *CohortHandler {
+	return &CohortHandler{
+		userCollection:     userCollection,
+		activityCollection: activityCollection,
+		segmentCollection:  segmentCollection,
+	}
+}
+
+type SegmentRequest struct {
+	Name        string                `json:"name" binding:"required,min=2,max=100"`
+	Description string                `json:"description" binding:"max=500"`
+	Filters     models.SegmentFilters `json:"filters"`
+}
+
+// GetCohorts - GET /analytics/cohorts?weeks=12&metric=activity&segment=<id>
+// Повертає тижневі когорти реєстрацій і частку користувачів, що повернулися на кожному наступному тижні.
+func (h *CohortHandler) GetCohorts(c *gin.Context) {
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(defaultCohortWeeks)))
+	if weeks < 1 || weeks > maxCohortWeeks {
+		weeks = defaultCohortWeeks
+	}
+
+	metric := c.DefaultQuery("metric", models.RetentionMetricActivity)
+	if metric != models.RetentionMetricActivity && metric != models.RetentionMetricLogin {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "metric must be activity or login",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	currentWeek := models.WeekStart(time.Now())
+	since := currentWeek.AddDate(0, 0, -7*(weeks-1))
+
+	filter := bson.M{}
+	var segment *models.UserSegment
+	if segmentID := c.Query("segment"); segmentID != "" {
+		id, err := primitive.ObjectIDFromHex(segmentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid segment ID",
+			})
+			return
+		}
+		segment = &models.UserSegment{}
+		if err := h.segmentCollection.FindOne(ctx, bson.M{"_id": id}).Decode(segment); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Segment not found",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching segment",
+			})
+			return
+		}
+		filter = segmentUserFilter(segment.Filters)
+	}
+	filter = andCreatedSince(filter, since)
+
+	cursor, err := h.userCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"_id": 1, "created_at": 1, "last_login_at": 1}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching users",
+		})
+		return
+	}
+	var users []struct {
+		ID          primitive.ObjectID `bson:"_id"`
+		CreatedAt   time.Time          `bson:"created_at"`
+		LastLoginAt *time.Time         `bson:"last_login_at"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding users",
+		})
+		return
+	}
+
+	// Індекс тижня від since: 0..weeks-1
+	weekIndex := func(t time.Time) int {
+		return int(models.WeekStart(t).Sub(since).Hours() / (24 * 7))
+	}
+
+	cohorts := make([]models.CohortRow, weeks)
+	for i := range cohorts {
+		// Когорта i спостерігається тижні i..weeks-1
+		observed := weeks - i
+		cohorts[i] = models.CohortRow{
+			WeekStart:      since.AddDate(0, 0, 7*i),
+			Retained:       make([]int, observed),
+			RetentionRates: make([]float64, observed),
+		}
+	}
+
+	cohortOf := make(map[primitive.ObjectID]int, len(users))
+	for _, user := range users {
+		i := weekIndex(user.CreatedAt)
+		if i < 0 || i >= weeks {
+			continue
+		}
+		cohortOf[user.ID] = i
+		cohorts[i].Size++
+		cohorts[i].Retained[0]++ // Тиждень реєстрації
+
+		if metric == models.RetentionMetricLogin && user.LastLoginAt != nil {
+			// Вхід на тижні k означає, що користувач "дожив" до всіх тижнів 1..k
+			last := weekIndex(*user.LastLoginAt) - i
+			for k := 1; k <= last && k < len(cohorts[i].Retained); k++ {
+				cohorts[i].Retained[k]++
+			}
+		}
+	}
+
+	if metric == models.RetentionMetricActivity && len(cohortOf) > 0 {
+		activityCursor, err := h.activityCollection.Find(ctx,
+			bson.M{"week_start": bson.M{"$gte": since}},
+			options.Find().SetProjection(bson.M{"user_id": 1, "week_start": 1}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching activity",
+			})
+			return
+		}
+		defer activityCursor.Close(ctx)
+
+		for activityCursor.Next(ctx) {
+			var activity models.UserActivityWeek
+			if err := activityCursor.Decode(&activity); err != nil {
+				continue
+			}
+			i, ok := cohortOf[activity.UserID]
+			if !ok {
+				continue
+			}
+			k := weekIndex(activity.WeekStart) - i
+			if k >= 1 && k < len(cohorts[i].Retained) {
+				cohorts[i].Retained[k]++
+			}
+		}
+	}
+
+	for i := range cohorts {
+		for k, retained := range cohorts[i].Retained {
+			if cohorts[i].Size > 0 {
+				cohorts[i].RetentionRates[k] = math.Round(float64(retained)/float64(cohorts[i].Size)*1000) / 1000
+			}
+		}
+	}
+
+	response := gin.H{
+		"metric":  metric,
+		"weeks":   weeks,
+		"since":   since,
+		"cohorts": cohorts,
+		"average": averageRetention(cohorts, weeks),
+	}
+	if segment != nil {
+		response["segment"] = segment
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// averageRetention - середнє утримання на тижні k, зважене за розміром когорт, що вже спостерігаються k тижнів
+func averageRetention(cohorts []models.CohortRow, weeks int) []float64 {
+	average := make([]float64, weeks)
+	for k := 0; k < weeks; k++ {
+		size, retained := 0, 0
+		for _, cohort := range cohorts {
+			if k < len(cohort.Retained) {
+				size += cohort.Size
+				retained += cohort.Retained[k]
+			}
+		}
+		if size > 0 {
+			average[k] = math.Round(float64(retained)/float64(size)*1000) / 1000
+		}
+	}
+	return average
+}
+
+// ========================================
+// СЕГМЕНТИ
+// ========================================
+
+// GetSegments повертає збережені сегменти з поточною кількістю користувачів
+func (h *CohortHandler) GetSegments(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := h.segmentCollection.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching segments",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	segments := []models.UserSegment{}
+	if err := cursor.All(ctx, &segments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding segments",
+		})
+		return
+	}
+
+	result := make([]gin.H, 0, len(segments))
+	for _, segment := range segments {
+		size, _ := h.userCollection.CountDocuments(ctx, segmentUserFilter(segment.Filters))
+		result = append(result, gin.H{
+			"segment": segment,
+			"size":    size,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"segments": result,
+	})
+}
+
+// CreateSegment зберігає визначення сегмента
+func (h *CohortHandler) CreateSegment(c *gin.Context) {
+	var req SegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateSegmentFilters(c, req.Filters) {
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	segment := models.UserSegment{
+		Name:        req.Name,
+		Description: req.Description,
+		Filters:     req.Filters,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	result, err := h.segmentCollection.InsertOne(ctx, segment)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Segment with this name already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating segment",
+		})
+		return
+	}
+	segment.ID = result.InsertedID.(primitive.ObjectID)
+
+	size, _ := h.userCollection.CountDocuments(ctx, segmentUserFilter(segment.Filters))
+	c.JSON(http.StatusCreated, gin.H{
+		"segment": segment,
+		"size":    size,
+	})
+}
+
+// UpdateSegment змінює назву, опис або умови сегмента
+func (h *CohortHandler) UpdateSegment(c *gin.Context) {
+	segmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid segment ID",
+		})
+		return
+	}
+
+	var req SegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateSegmentFilters(c, req.Filters) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var segment models.UserSegment
+	err = h.segmentCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": segmentID},
+		bson.M{"$set": bson.M{
+			"name":        req.Name,
+			"description": req.Description,
+			"filters":     req.Filters,
+			"updated_at":  time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&segment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Segment not found",
+			})
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Segment with this name already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating segment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, segment)
+}
+
+// DeleteSegment видаляє сегмент
+func (h *CohortHandler) DeleteSegment(c *gin.Context) {
+	segmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid segment ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.segmentCollection.DeleteOne(ctx, bson.M{"_id": segmentID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting segment",
+		})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Segment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Segment deleted",
+	})
+}
+
+// validateSegmentFilters перевіряє ролі та межі дат реєстрації
+func validateSegmentFilters(c *gin.Context, filters models.SegmentFilters) bool {
+	for _, role := range filters.Roles {
+		if !models.UserRole(role).IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown role: " + role,
+			})
+			return false
+		}
+	}
+	if filters.RegisteredFrom != nil && filters.RegisteredTo != nil && filters.RegisteredTo.Before(*filters.RegisteredFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "registered_to must be after registered_from",
+		})
+		return false
+	}
+	return true
+}
+
+// segmentUserFilter перетворює умови сегмента на фільтр колекції users
+func segmentUserFilter(filters models.SegmentFilters) bson.M {
+	filter := bson.M{}
+	if len(filters.Roles) > 0 {
+		filter["role"] = bson.M{"$in": filters.Roles}
+	}
+	if filters.IsVerified != nil {
+		filter["is_verified"] = *filters.IsVerified
+	}
+	if len(filters.Interests) > 0 {
+		filter["interests"] = bson.M{"$in": filters.Interests}
+	}
+	if filters.HasGroups != nil {
+		if *filters.HasGroups {
+			filter["groups.0"] = bson.M{"$exists": true}
+		} else {
+			filter["groups.0"] = bson.M{"$exists": false}
+		}
+	}
+	if filters.HasConcession != nil {
+		if *filters.HasConcession {
+			filter["fare_concession.status"] = models.ConcessionStatusVerified
+		} else {
+			filter["fare_concession.status"] = bson.M{"$ne": models.ConcessionStatusVerified}
+		}
+	}
+	if filters.CommunityID != nil {
+		filter["community_ids"] = *filters.CommunityID
+	}
+
+	created := bson.M{}
+	if filters.RegisteredFrom != nil {
+		created["$gte"] = *filters.RegisteredFrom
+	}
+	if filters.RegisteredTo != nil {
+		created["$lte"] = *filters.RegisteredTo
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter
+}
+
+// andCreatedSince додає нижню межу дати реєстрації, не втрачаючи межі сегмента
+func andCreatedSince(filter bson.M, since time.Time) bson.M {
+	if _, ok := filter["created_at"]; ok {
+		return bson.M{"$and": []bson.M{
+			filter,
+			{"created_at": bson.M{"$gte": since}},
+		}}
+	}
+	filter["created_at"] = bson.M{"$gte": since}
+	return filter
+}
@@ -0,0 +1,484 @@
+// internal/handlers/community.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/modules"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommunityHandler обробляє запити для громад (multi-tenancy)
+type CommunityHandler struct {
+	communityCollection *mongo.Collection
+	userCollection      *mongo.Collection
+	communityService    *services.CommunityService
+	moduleRegistry      *modules.Registry
+}
+
+// CreateCommunityRequest - запит на створення громади
+type CreateCommunityRequest struct {
+	Code  string   `json:"code" binding:"required,min=2,max=50"`
+	Name  string   `json:"name" binding:"required,min=2,max=200"`
+	Hosts []string `json:"hosts"`
+}
+
+// UpdateSettingsRequest - запит на оновлення брендингу громади
+type UpdateSettingsRequest struct {
+	DisplayName    *string                  `json:"display_name,omitempty"`
+	LogoURL        *string                  `json:"logo_url,omitempty"`
+	FaviconURL     *string                  `json:"favicon_url,omitempty"`
+	PrimaryColor   *string                  `json:"primary_color,omitempty"`
+	SecondaryColor *string                  `json:"secondary_color,omitempty"`
+	Contact        *models.CommunityContact `json:"contact,omitempty"`
+	EnabledModules []string                 `json:"enabled_modules,omitempty"`
+}
+
+// PublicSettingsResponse - публічні налаштування громади для фронтенду
+type PublicSettingsResponse struct {
+	Community      string                  `json:"community"`
+	Name           string                  `json:"name"`
+	LogoURL        string                  `json:"logo_url"`
+	FaviconURL     string                  `json:"favicon_url,omitempty"`
+	Colors         map[string]string       `json:"colors"`
+	Contact        models.CommunityContact `json:"contact"`
+	EnabledModules []string                `json:"enabled_modules"`
+}
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// UpdateCommunityRequest - запит на оновлення громади
+type UpdateCommunityRequest struct {
+	Name     string   `json:"name,omitempty"`
+	Hosts    []string `json:"hosts,omitempty"`
+	IsActive *bool    `json:"is_active,omitempty"`
+}
+
+// NewCommunityHandler створює новий обробник громад
+func NewCommunityHandler(communityCollection, userCollection *mongo.Collection, communityService *services.CommunityService, moduleRegistry *modules.Registry) *CommunityHandler {
+	return &CommunityHandler{
+		communityCollection: communityCollection,
+		userCollection:      userCollection,
+		communityService:    communityService,
+		moduleRegistry:      moduleRegistry,
+	}
+}
+
+// ========================================
+// HELPERS
+// ========================================
+
+// getCommunityID отримує ID громади поточного запиту з контексту Gin
+func getCommunityID(c *gin.Context) primitive.ObjectID {
+	if communityID, exists := c.Get("community_id"); exists {
+		if id, ok := communityID.(primitive.ObjectID); ok {
+			return id
+		}
+	}
+	return primitive.NilObjectID
+}
+
+// communityScope додає до фільтра обмеження за громадою поточного запиту.
+// Документи без community_id (створені до multi-tenancy) належать громаді за замовчуванням.
+func communityScope(c *gin.Context, filter bson.M) bson.M {
+	communityID := getCommunityID(c)
+	if communityID.IsZero() {
+		return filter
+	}
+
+	if isDefault, _ := c.Get("community_is_default"); isDefault == true {
+		filter["community_id"] = bson.M{"$in": []interface{}{communityID, nil}}
+	} else {
+		filter["community_id"] = communityID
+	}
+	return filter
+}
+
+// ========================================
+// PUBLIC
+// ========================================
+
+// GetCommunities повертає список активних громад
+func (h *CommunityHandler) GetCommunities(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.communityCollection.Find(
+		ctx,
+		bson.M{"is_active": true},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching communities",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var communities []models.Community
+	if err := cursor.All(ctx, &communities); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding communities",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"communities": communities,
+		"current":     c.GetString("community_code"),
+	})
+}
+
+// GetPublicSettings повертає брендинг поточної громади
+// GET /public/settings
+func (h *CommunityHandler) GetPublicSettings(c *gin.Context) {
+	community, ok := h.communityService.GetByCode(c.GetString("community_code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Community not found",
+		})
+		return
+	}
+
+	settings := community.Settings
+	name := settings.DisplayName
+	if name == "" {
+		name = community.Name
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, PublicSettingsResponse{
+		Community:  community.Code,
+		Name:       name,
+		LogoURL:    settings.LogoURL,
+		FaviconURL: settings.FaviconURL,
+		Colors: map[string]string{
+			"primary":   settings.PrimaryColor,
+			"secondary": settings.SecondaryColor,
+		},
+		Contact: settings.Contact,
+		// Модулі, вимкнені в деплойменті, не показуємо навіть якщо громада їх увімкнула
+		EnabledModules: h.moduleRegistry.FilterEnabled(settings.GetEnabledModules()),
+	})
+}
+
+// UpdateSettings оновлює брендинг поточної громади
+// 🔒 Вимагає права: manage:system_settings (SUPER_ADMIN)
+func (h *CommunityHandler) UpdateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	now := time.Now()
+	updateFields := bson.M{
+		"settings.updated_by": userID,
+		"settings.updated_at": now,
+		"updated_at":          now,
+	}
+
+	if req.DisplayName != nil {
+		updateFields["settings.display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.LogoURL != nil {
+		updateFields["settings.logo_url"] = *req.LogoURL
+	}
+	if req.FaviconURL != nil {
+		updateFields["settings.favicon_url"] = *req.FaviconURL
+	}
+	for field, color := range map[string]*string{
+		"settings.primary_color":   req.PrimaryColor,
+		"settings.secondary_color": req.SecondaryColor,
+	} {
+		if color == nil {
+			continue
+		}
+		if !hexColorRegex.MatchString(*color) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid color format",
+				"details": "Expected #RRGGBB, got " + *color,
+			})
+			return
+		}
+		updateFields[field] = *color
+	}
+	if req.Contact != nil {
+		updateFields["settings.contact"] = *req.Contact
+	}
+	if req.EnabledModules != nil {
+		for _, module := range req.EnabledModules {
+			if !models.IsValidModule(module) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Unknown module",
+					"details": module,
+				})
+				return
+			}
+		}
+		updateFields["settings.enabled_modules"] = req.EnabledModules
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.communityCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": getCommunityID(c)},
+		bson.M{"$set": updateFields},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating settings",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Community not found",
+		})
+		return
+	}
+
+	h.communityService.Reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Settings updated successfully",
+	})
+}
+
+// ========================================
+// MEMBERSHIP
+// ========================================
+
+// JoinCommunity додає громаду до акаунту користувача
+func (h *CommunityHandler) JoinCommunity(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	community, ok := h.communityService.GetByCode(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Community not found",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = h.userCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$addToSet": bson.M{"community_ids": community.ID},
+			"$set":      bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error joining community",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Successfully joined community",
+		"community": community,
+	})
+}
+
+// LeaveCommunity прибирає громаду з акаунту користувача
+func (h *CommunityHandler) LeaveCommunity(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	community, ok := h.communityService.GetByCode(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Community not found",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = h.userCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$pull": bson.M{"community_ids": community.ID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error leaving community",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully left community",
+	})
+}
+
+// ========================================
+// SUPER_ADMIN
+// ========================================
+
+// CreateCommunity створює нову громаду
+func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
+	var req CreateCommunityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.communityCollection.CountDocuments(ctx, bson.M{"code": req.Code})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Community with this code already exists",
+		})
+		return
+	}
+
+	now := time.Now()
+	community := models.Community{
+		Code:      req.Code,
+		Name:      req.Name,
+		Hosts:     normalizeHosts(req.Hosts),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := h.communityCollection.InsertOne(ctx, community)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating community",
+		})
+		return
+	}
+	community.ID = result.InsertedID.(primitive.ObjectID)
+
+	h.communityService.Reload(ctx)
+
+	c.JSON(http.StatusCreated, community)
+}
+
+// UpdateCommunity оновлює назву, хости або статус громади
+func (h *CommunityHandler) UpdateCommunity(c *gin.Context) {
+	communityID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid community ID",
+		})
+		return
+	}
+
+	var req UpdateCommunityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updateFields := bson.M{"updated_at": time.Now()}
+	if req.Name != "" {
+		updateFields["name"] = req.Name
+	}
+	if req.Hosts != nil {
+		updateFields["hosts"] = normalizeHosts(req.Hosts)
+	}
+	if req.IsActive != nil {
+		updateFields["is_active"] = *req.IsActive
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.communityCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": communityID},
+		bson.M{"$set": updateFields},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating community",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Community not found",
+		})
+		return
+	}
+
+	h.communityService.Reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Community updated successfully",
+	})
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// communityRequest - запит від громади communityID до документа documentID чужої громади
+func communityRequest(method, body string, communityID, documentID primitive.ObjectID, user *middleware.UserClaims) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(method, "/api/v1/"+documentID.Hex(), strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: documentID.Hex()}}
+	c.Set("community_id", communityID)
+	c.Set("user_claims", user)
+	c.Set("user_id", user.UserID.Hex())
+	return c, rec
+}
+
+// commandFilter - фільтр запиту до MongoDB для команд, якими обробники шукають або змінюють документ за ID
+func commandFilter(command bson.Raw) (string, bson.Raw, bool) {
+	first := command.Index(0)
+	switch first.Key() {
+	case "find":
+		return first.Value().StringValue(), command.Lookup("filter").Document(), true
+	case "findAndModify":
+		return first.Value().StringValue(), command.Lookup("query").Document(), true
+	case "update":
+		return first.Value().StringValue(), command.Lookup("updates", "0", "q").Document(), true
+	case "delete":
+		return first.Value().StringValue(), command.Lookup("deletes", "0", "q").Document(), true
+	case "aggregate":
+		return first.Value().StringValue(), command.Lookup("pipeline", "0", "$match").Document(), true
+	}
+	return "", nil, false
+}
+
+// hasCommunityFilter перевіряє умову community_id, зокрема всередині $and від withModerationScope
+func hasCommunityFilter(filter bson.Raw, communityID primitive.ObjectID) bool {
+	if id, ok := filter.Lookup("community_id").ObjectIDOK(); ok && id == communityID {
+		return true
+	}
+	conditions, ok := filter.Lookup("$and").ArrayOK()
+	if !ok {
+		return false
+	}
+	values, _ := conditions.Values()
+	for _, value := range values {
+		if condition, ok := value.DocumentOK(); ok && hasCommunityFilter(condition, communityID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Документ іншої громади за ID не знаходиться: фільтри читання, голосування, змін і модерації
+// містять community_id громади запиту, а відповідь - 404, а не 403 чи дані документа
+func TestByIDRequestsAreScopedToCommunity(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	communityID := primitive.NewObjectID()
+	user := &middleware.UserClaims{UserID: primitive.NewObjectID(), Role: models.RoleUser}
+	moderator := &middleware.UserClaims{
+		UserID:          primitive.NewObjectID(),
+		Role:            models.RoleModerator,
+		ModerationScope: &models.ModerationScope{Neighborhoods: []string{"Центр"}},
+	}
+
+	emptyCursor := func(mt *mtest.T, collection string) bson.D {
+		return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+collection, mtest.FirstBatch)
+	}
+	notMatched := func(*mtest.T, string) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0})
+	}
+	notModified := func(*mtest.T, string) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil})
+	}
+	voter := func(mt *mtest.T, collection string) bson.D {
+		return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+collection, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: user.UserID}, {Key: "is_verified", Value: true}})
+	}
+
+	type response struct {
+		collection string
+		create     func(*mtest.T, string) bson.D
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		body      string
+		user      *middleware.UserClaims
+		responses []response
+		handler   func(mt *mtest.T) gin.HandlerFunc
+	}{
+		{
+			name:      "get poll",
+			method:    http.MethodGet,
+			user:      user,
+			responses: []response{{"polls", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPollHandler(mt).GetPoll },
+		},
+		{
+			name:      "vote poll",
+			method:    http.MethodPost,
+			body:      `{"answers":[{"question_id":"q1","option_ids":["o1"]}]}`,
+			user:      user,
+			responses: []response{{"polls", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPollHandler(mt).VotePoll },
+		},
+		{
+			name:      "update poll status",
+			method:    http.MethodPatch,
+			body:      `{"status":"active"}`,
+			user:      moderator,
+			responses: []response{{"polls", notMatched}, {"polls", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPollHandler(mt).UpdatePollStatus },
+		},
+		{
+			name:      "approve poll",
+			method:    http.MethodPost,
+			body:      `{}`,
+			user:      moderator,
+			responses: []response{{"polls", notModified}, {"polls", emptyCursor}, {"polls", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPollHandler(mt).ApprovePoll },
+		},
+		{
+			name:      "get petition",
+			method:    http.MethodGet,
+			user:      user,
+			responses: []response{{"petitions", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPetitionHandler(mt).GetPetition },
+		},
+		{
+			name:      "sign petition",
+			method:    http.MethodPost,
+			body:      `{}`,
+			user:      user,
+			responses: []response{{"users", voter}, {"petitions", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPetitionHandler(mt).SignPetition },
+		},
+		{
+			name:      "update petition",
+			method:    http.MethodPut,
+			body:      `{}`,
+			user:      user,
+			responses: []response{{"petitions", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPetitionHandler(mt).UpdatePetition },
+		},
+		{
+			name:      "delete petition",
+			method:    http.MethodDelete,
+			user:      user,
+			responses: []response{{"petitions", notMatched}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPetitionHandler(mt).DeletePetition },
+		},
+		{
+			name:      "update petition status",
+			method:    http.MethodPatch,
+			body:      `{"status":"accepted"}`,
+			user:      moderator,
+			responses: []response{{"petitions", emptyCursor}},
+			handler:   func(mt *mtest.T) gin.HandlerFunc { return testPetitionHandler(mt).UpdatePetitionStatus },
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			for _, r := range tt.responses {
+				mt.AddMockResponses(r.create(mt, r.collection))
+			}
+
+			c, rec := communityRequest(tt.method, tt.body, communityID, primitive.NewObjectID(), tt.user)
+			tt.handler(mt)(c)
+
+			if rec.Code != http.StatusNotFound {
+				mt.Errorf("got %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body.String())
+			}
+			for _, event := range mt.GetAllStartedEvents() {
+				collection, filter, ok := commandFilter(event.Command)
+				if !ok || collection == "users" {
+					continue
+				}
+				if !hasCommunityFilter(filter, communityID) {
+					mt.Errorf("%s on %s without community_id: %s", event.CommandName, collection, filter)
+				}
+			}
+		})
+	}
+}
+
+func testPollHandler(mt *mtest.T) *PollHandler {
+	return &PollHandler{
+		pollCollection: mt.DB.Collection("polls"),
+		userCollection: mt.DB.Collection("users"),
+	}
+}
+
+func testPetitionHandler(mt *mtest.T) *PetitionHandler {
+	return &PetitionHandler{
+		petitionCollection: mt.DB.Collection("petitions"),
+		userCollection:     mt.DB.Collection("users"),
+	}
+}
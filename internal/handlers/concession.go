@@ -0,0 +1,371 @@
+// internal/handlers/concession.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConcessionHandler - пільги на проїзд (студенти, пенсіонери, ВПО)
+type ConcessionHandler struct {
+	userCollection      *mongo.Collection
+	notificationService *services.NotificationService
+}
+
+// NewConcessionHandler створює обробник пільг на проїзд
+func NewConcessionHandler(userCollection *mongo.Collection, notificationService *services.NotificationService) *ConcessionHandler {
+	return &ConcessionHandler{
+		userCollection:      userCollection,
+		notificationService: notificationService,
+	}
+}
+
+type SubmitConcessionRequest struct {
+	Category       string `json:"category" binding:"required,oneof=student pensioner idp"`
+	DocumentType   string `json:"document_type" binding:"required,max=100"` // student_card, pension_certificate, idp_certificate
+	DocumentNumber string `json:"document_number" binding:"required,max=50"`
+	// Скан документа, завантажений через е-сервіси
+	DocumentURL string `json:"document_url" binding:"required,url"`
+}
+
+type VerifyConcessionRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RejectConcessionRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// GetCategories повертає пільгові категорії проїзду
+func (h *ConcessionHandler) GetCategories(c *gin.Context) {
+	categories := make([]gin.H, 0, len(models.ConcessionCategories()))
+	for _, category := range models.ConcessionCategories() {
+		categories = append(categories, gin.H{
+			"code":  category,
+			"label": models.GetConcessionTranslation(category),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+	})
+}
+
+// GetMyConcession повертає пільгу поточного користувача
+func (h *ConcessionHandler) GetMyConcession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx,
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"fare_concession": 1, "verification_level": 1, "resident_card": 1}),
+	).Decode(&user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	// Пільга діє, лише поки підтверджено картку жителя
+	now := time.Now()
+	resident := user.IsVerifiedResident(now)
+	c.JSON(http.StatusOK, gin.H{
+		"concession":  user.FareConcession,
+		"is_active":   user.FareConcession.IsActive(now) && resident,
+		"is_resident": resident,
+	})
+}
+
+// SubmitConcession подає документ для підтвердження пільги.
+// Повторна подача замінює попередню заявку і потребує нової перевірки.
+func (h *ConcessionHandler) SubmitConcession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req SubmitConcessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resident, err := isVerifiedResident(ctx, h.userCollection, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if !resident {
+		residentRequired(c, "Link a resident card before applying for a fare concession")
+		return
+	}
+
+	now := time.Now()
+	concession := models.FareConcession{
+		Category:       req.Category,
+		Status:         models.ConcessionStatusPending,
+		DocumentType:   req.DocumentType,
+		DocumentNumber: req.DocumentNumber,
+		DocumentURL:    req.DocumentURL,
+		SubmittedAt:    now,
+	}
+
+	result, err := h.userCollection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{
+			"fare_concession": concession,
+			"updated_at":      now,
+		}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error submitting concession",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Concession submitted for verification",
+		"concession": concession,
+	})
+}
+
+// DeleteMyConcession відкликає пільгу або заявку
+func (h *ConcessionHandler) DeleteMyConcession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.userCollection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$unset": bson.M{"fare_concession": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error removing concession",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Concession removed",
+	})
+}
+
+// GetPendingConcessions повертає заявки на перевірку документів (від найстаріших)
+func (h *ConcessionHandler) GetPendingConcessions(c *gin.Context) {
+	page, limit := pageParams(c, 20, 100)
+
+	filter := bson.M{"fare_concession.status": models.ConcessionStatusPending}
+	if category := c.Query("category"); category != "" {
+		filter["fare_concession.category"] = category
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "fare_concession.submitted_at", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{
+			"first_name":      1,
+			"last_name":       1,
+			"email":           1,
+			"fare_concession": 1,
+		})
+
+	cursor, err := h.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching concessions",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding concessions",
+		})
+		return
+	}
+
+	// Модераторам потрібне посилання на документ, яке приховане в JSON користувача
+	applications := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		applications = append(applications, gin.H{
+			"user_id":      user.ID,
+			"name":         user.GetFullName(),
+			"email":        user.Email,
+			"concession":   user.FareConcession,
+			"document_url": user.FareConcession.DocumentURL,
+		})
+	}
+
+	total, _ := h.userCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, applications, info, nil, gin.H{"applications": applications, "pagination": info}))
+}
+
+// VerifyConcession підтверджує пільгу користувача
+func (h *ConcessionHandler) VerifyConcession(c *gin.Context) {
+	// Тіло запиту необов'язкове (пільга без терміну дії)
+	var req VerifyConcessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Expiration date must be in the future",
+		})
+		return
+	}
+
+	moderatorID, _ := getUserID(c)
+	now := time.Now()
+	update := bson.M{
+		"fare_concession.status":      models.ConcessionStatusVerified,
+		"fare_concession.verified_at": now,
+		"fare_concession.verified_by": moderatorID,
+		"updated_at":                  now,
+	}
+	if req.ExpiresAt != nil {
+		update["fare_concession.expires_at"] = req.ExpiresAt
+	}
+
+	h.decide(c, bson.M{
+		"$set":   update,
+		"$unset": bson.M{"fare_concession.rejection_reason": ""},
+	}, "Пільгу на проїзд підтверджено", "Тепер у розкладі та маршрутах показується ваш пільговий тариф")
+}
+
+// RejectConcession відхиляє заявку на пільгу
+func (h *ConcessionHandler) RejectConcession(c *gin.Context) {
+	var req RejectConcessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.decide(c, bson.M{"$set": bson.M{
+		"fare_concession.status":           models.ConcessionStatusRejected,
+		"fare_concession.rejection_reason": req.Reason,
+		"updated_at":                       time.Now(),
+	}}, "Пільгу на проїзд не підтверджено", req.Reason)
+}
+
+// decide застосовує рішення модератора до заявки, що очікує перевірки, та сповіщає користувача
+func (h *ConcessionHandler) decide(c *gin.Context, update bson.M, title, body string) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Рішення і сповіщення про нього записуються разом: сповіщення не загубиться після збою
+	var user models.User
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		err := h.userCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": userID, "fare_concession.status": models.ConcessionStatusPending},
+			update,
+			options.FindOneAndUpdate().
+				SetReturnDocument(options.After).
+				SetProjection(bson.M{"fare_concession": 1}),
+		).Decode(&user)
+		if err != nil {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+			Source:  "concession.decided",
+			UserIDs: []primitive.ObjectID{userID},
+			Title:   title,
+			Body:    body,
+			Type:    services.NotificationTypeSystem,
+			Data: map[string]interface{}{
+				"concession": user.FareConcession.Category,
+				"status":     user.FareConcession.Status,
+			},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Pending concession not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating concession",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Concession updated",
+		"concession": user.FareConcession,
+	})
+}
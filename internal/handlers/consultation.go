@@ -0,0 +1,893 @@
+// internal/handlers/consultation.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConsultationHandler - громадські обговорення проєктів документів з коментарями до розділів
+type ConsultationHandler struct {
+	consultationCollection *mongo.Collection
+	commentCollection      *mongo.Collection
+	userCollection         *mongo.Collection
+}
+
+// NewConsultationHandler створює обробник громадських обговорень
+func NewConsultationHandler(consultationCollection, commentCollection, userCollection *mongo.Collection) *ConsultationHandler {
+	return &ConsultationHandler{
+		consultationCollection: consultationCollection,
+		commentCollection:      commentCollection,
+		userCollection:         userCollection,
+	}
+}
+
+type ConsultationRequest struct {
+	Title       string                       `json:"title" binding:"required,min=5,max=300"`
+	Description string                       `json:"description" binding:"max=10000"`
+	Department  string                       `json:"department" binding:"max=200"`
+	DocumentURL string                       `json:"document_url" binding:"omitempty,url,max=500"`
+	Sections    []models.ConsultationSection `json:"sections" binding:"required,min=1,max=500"`
+	StartDate   time.Time                    `json:"start_date" binding:"required"`
+	EndDate     time.Time                    `json:"end_date" binding:"required"`
+}
+
+type ConsultationCommentRequest struct {
+	SectionID string `json:"section_id" binding:"required"`
+	Content   string `json:"content" binding:"required,min=5,max=5000"`
+}
+
+type ResolveCommentRequest struct {
+	Resolution string `json:"resolution" binding:"required,oneof=accepted considered rejected"`
+	Note       string `json:"note" binding:"max=2000"`
+}
+
+type CompleteConsultationRequest struct {
+	Conclusion string `json:"conclusion" binding:"required,min=10,max=10000"`
+}
+
+// ========================================
+// PUBLIC
+// ========================================
+
+// GetConsultations - GET /consultations?status=published&open=true
+func (h *ConsultationHandler) GetConsultations(c *gin.Context) {
+	page, limit := pageParams(c, 20, 50)
+
+	filter := communityScope(c, bson.M{
+		"status": bson.M{"$in": []string{models.ConsultationStatusPublished, models.ConsultationStatusCompleted}},
+	})
+	if status := c.Query("status"); status == models.ConsultationStatusPublished || status == models.ConsultationStatusCompleted {
+		filter["status"] = status
+	}
+	if c.Query("open") == "true" {
+		now := time.Now()
+		filter["status"] = models.ConsultationStatusPublished
+		filter["start_date"] = bson.M{"$lte": now}
+		filter["end_date"] = bson.M{"$gte": now}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := h.consultationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting consultations",
+		})
+		return
+	}
+
+	// Тексти розділів у списку не потрібні
+	cursor, err := h.consultationCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"sections": 0}).
+		SetSort(bson.D{{Key: "start_date", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching consultations",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	consultations := []models.Consultation{}
+	if err := cursor.All(ctx, &consultations); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding consultations",
+		})
+		return
+	}
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, consultations, info, nil, gin.H{"consultations": consultations, "pagination": info}))
+}
+
+// GetConsultation повертає документ з розділами та кількістю коментарів до кожного
+func (h *ConsultationHandler) GetConsultation(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findPublicConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+
+	cursor, err := h.commentCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"consultation_id": consultationID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$section_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting comments",
+		})
+		return
+	}
+	var counts []struct {
+		SectionID string `bson:"_id"`
+		Count     int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting comments",
+		})
+		return
+	}
+	sectionComments := make(map[string]int, len(counts))
+	for _, count := range counts {
+		sectionComments[count.SectionID] = count.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consultation":      consultation,
+		"section_comments":  sectionComments,
+		"open_for_comments": consultation.IsOpenForComments(time.Now()),
+	})
+}
+
+// GetComments - GET /consultations/:id/comments?section=2.1&resolution=accepted
+func (h *ConsultationHandler) GetComments(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	page, limit := pageParams(c, 50, 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findPublicConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+
+	filter := bson.M{"consultation_id": consultationID}
+	if section := c.Query("section"); section != "" {
+		filter["section_id"] = section
+	}
+	if resolution := c.Query("resolution"); resolution != "" {
+		filter["resolution"] = resolution
+	}
+
+	total, err := h.commentCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting comments",
+		})
+		return
+	}
+
+	cursor, err := h.commentCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching comments",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	comments := []models.ConsultationComment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding comments",
+		})
+		return
+	}
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, comments, info, nil, gin.H{"comments": comments, "pagination": info}))
+}
+
+// GetReport - GET /consultations/:id/report
+// Публічний звіт доступний після завершення вікна коментування; модератори бачать його будь-коли.
+func (h *ConsultationHandler) GetReport(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findPublicConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+	if consultation.Status != models.ConsultationStatusCompleted && time.Now().Before(consultation.EndDate) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Report is available after the commenting window closes",
+			"end_date": consultation.EndDate,
+		})
+		return
+	}
+
+	h.writeReport(ctx, c, consultation)
+}
+
+// ========================================
+// КОМЕНТУВАННЯ (PROTECTED)
+// ========================================
+
+// AddComment додає коментар мешканця до розділу в межах вікна коментування
+func (h *ConsultationHandler) AddComment(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	var req ConsultationCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findPublicConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+	if !consultation.IsOpenForComments(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "Consultation is not open for comments",
+			"start_date": consultation.StartDate,
+			"end_date":   consultation.EndDate,
+		})
+		return
+	}
+	if _, ok := consultation.FindSection(req.SectionID); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Section not found",
+		})
+		return
+	}
+
+	var user models.User
+	if err := h.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching user",
+		})
+		return
+	}
+
+	comment := models.ConsultationComment{
+		ConsultationID: consultationID,
+		SectionID:      req.SectionID,
+		AuthorID:       userID,
+		AuthorName:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Content:        strings.TrimSpace(req.Content),
+		Resolution:     models.CommentResolutionPending,
+		CreatedAt:      time.Now(),
+	}
+
+	result, err := h.commentCollection.InsertOne(ctx, comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error adding comment",
+		})
+		return
+	}
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+
+	h.consultationCollection.UpdateOne(ctx,
+		bson.M{"_id": consultationID},
+		bson.M{"$inc": bson.M{"comments_count": 1}},
+	)
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ========================================
+// МОДЕРАЦІЯ
+// ========================================
+
+// ResolveComment позначає коментар як врахований, взятий до уваги або відхилений
+func (h *ConsultationHandler) ResolveComment(c *gin.Context) {
+	commentID, err := primitive.ObjectIDFromHex(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid comment ID",
+		})
+		return
+	}
+
+	var req ResolveCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	// Відхилення без обґрунтування у звіті не приймається
+	if req.Resolution == models.CommentResolutionRejected && strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Note is required when rejecting a comment",
+		})
+		return
+	}
+
+	moderatorID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var comment models.ConsultationComment
+	if err := h.commentCollection.FindOne(ctx, bson.M{"_id": commentID}).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Comment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching comment",
+		})
+		return
+	}
+
+	var consultation models.Consultation
+	if !h.findConsultation(ctx, c, comment.ConsultationID, &consultation) {
+		return
+	}
+	if consultation.Status == models.ConsultationStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Consultation is already completed",
+		})
+		return
+	}
+
+	now := time.Now()
+	err = h.commentCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": commentID},
+		bson.M{"$set": bson.M{
+			"resolution":      req.Resolution,
+			"resolution_note": strings.TrimSpace(req.Note),
+			"resolved_by":     moderatorID,
+			"resolved_at":     now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating comment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// GetModerationReport повертає проміжний звіт незалежно від стану вікна коментування
+func (h *ConsultationHandler) GetModerationReport(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+
+	h.writeReport(ctx, c, consultation)
+}
+
+// ========================================
+// УПРАВЛІННЯ ОБГОВОРЕННЯМИ (ADMIN)
+// ========================================
+
+// GetAllConsultations повертає всі обговорення громади, включно з чернетками
+func (h *ConsultationHandler) GetAllConsultations(c *gin.Context) {
+	filter := communityScope(c, bson.M{})
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.consultationCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"sections": 0}).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(200))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching consultations",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	consultations := []models.Consultation{}
+	if err := cursor.All(ctx, &consultations); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding consultations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consultations": consultations,
+	})
+}
+
+// CreateConsultation створює чернетку обговорення
+func (h *ConsultationHandler) CreateConsultation(c *gin.Context) {
+	var req ConsultationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateConsultationRequest(c, req) {
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	consultation := models.Consultation{
+		CommunityID: getCommunityID(c),
+		Title:       req.Title,
+		Description: req.Description,
+		Department:  req.Department,
+		DocumentURL: req.DocumentURL,
+		Sections:    req.Sections,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Status:      models.ConsultationStatusDraft,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	result, err := h.consultationCollection.InsertOne(ctx, consultation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating consultation",
+		})
+		return
+	}
+	consultation.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, consultation)
+}
+
+// UpdateConsultation оновлює обговорення. Після публікації розділи з коментарями видаляти не можна.
+func (h *ConsultationHandler) UpdateConsultation(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	var req ConsultationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !validateConsultationRequest(c, req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+	if consultation.Status == models.ConsultationStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Consultation is already completed",
+		})
+		return
+	}
+
+	if consultation.Status == models.ConsultationStatusPublished {
+		keep := make(map[string]bool, len(req.Sections))
+		for _, section := range req.Sections {
+			keep[section.ID] = true
+		}
+		commented, err := h.commentCollection.Distinct(ctx, "section_id", bson.M{"consultation_id": consultationID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error checking comments",
+			})
+			return
+		}
+		for _, sectionID := range commented {
+			if id, ok := sectionID.(string); ok && !keep[id] {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":      "Cannot remove a section that already has comments",
+					"section_id": id,
+				})
+				return
+			}
+		}
+	}
+
+	update := bson.M{
+		"title":        req.Title,
+		"description":  req.Description,
+		"department":   req.Department,
+		"document_url": req.DocumentURL,
+		"sections":     req.Sections,
+		"start_date":   req.StartDate,
+		"end_date":     req.EndDate,
+		"updated_at":   time.Now(),
+	}
+
+	err = h.consultationCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": consultationID},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&consultation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating consultation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, consultation)
+}
+
+// PublishConsultation публікує чернетку; коментування відкривається з дати початку
+func (h *ConsultationHandler) PublishConsultation(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+	if consultation.Status != models.ConsultationStatusDraft {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only drafts can be published",
+		})
+		return
+	}
+	now := time.Now()
+	if consultation.EndDate.Before(now) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Commenting window has already ended",
+		})
+		return
+	}
+
+	if _, err := h.consultationCollection.UpdateOne(ctx,
+		bson.M{"_id": consultationID},
+		bson.M{"$set": bson.M{
+			"status":       models.ConsultationStatusPublished,
+			"published_at": now,
+			"updated_at":   now,
+		}},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error publishing consultation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Consultation published",
+		"status":  models.ConsultationStatusPublished,
+	})
+}
+
+// CompleteConsultation затверджує підсумковий звіт після закриття вікна коментування
+func (h *ConsultationHandler) CompleteConsultation(c *gin.Context) {
+	consultationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consultation ID",
+		})
+		return
+	}
+
+	var req CompleteConsultationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var consultation models.Consultation
+	if !h.findConsultation(ctx, c, consultationID, &consultation) {
+		return
+	}
+	if consultation.Status != models.ConsultationStatusPublished {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only published consultations can be completed",
+		})
+		return
+	}
+	now := time.Now()
+	if now.Before(consultation.EndDate) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Commenting window is still open",
+			"end_date": consultation.EndDate,
+		})
+		return
+	}
+
+	// Усі пропозиції мають отримати рішення до затвердження звіту
+	pending, err := h.commentCollection.CountDocuments(ctx, bson.M{
+		"consultation_id": consultationID,
+		"resolution":      models.CommentResolutionPending,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting comments",
+		})
+		return
+	}
+	if pending > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Some comments have no resolution yet",
+			"pending": pending,
+		})
+		return
+	}
+
+	err = h.consultationCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": consultationID},
+		bson.M{"$set": bson.M{
+			"status":       models.ConsultationStatusCompleted,
+			"conclusion":   strings.TrimSpace(req.Conclusion),
+			"completed_at": now,
+			"updated_at":   now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&consultation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error completing consultation",
+		})
+		return
+	}
+
+	h.writeReport(ctx, c, consultation)
+}
+
+// ========================================
+// HELPERS
+// ========================================
+
+// writeReport формує підсумковий звіт: рішення щодо коментарів у розрізі розділів документа
+func (h *ConsultationHandler) writeReport(ctx context.Context, c *gin.Context, consultation models.Consultation) {
+	cursor, err := h.commentCollection.Find(ctx,
+		bson.M{"consultation_id": consultation.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching comments",
+		})
+		return
+	}
+	var comments []models.ConsultationComment
+	if err := cursor.All(ctx, &comments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding comments",
+		})
+		return
+	}
+
+	report := models.ConsultationReport{
+		ConsultationID: consultation.ID,
+		Title:          consultation.Title,
+		Status:         consultation.Status,
+		StartDate:      consultation.StartDate,
+		EndDate:        consultation.EndDate,
+		Conclusion:     consultation.Conclusion,
+		TotalComments:  len(comments),
+		Resolutions:    newResolutionCounts(),
+		Sections:       make([]models.ConsultationSectionReport, 0, len(consultation.Sections)),
+		GeneratedAt:    time.Now(),
+	}
+
+	sectionIndex := make(map[string]int, len(consultation.Sections))
+	for i, section := range consultation.Sections {
+		sectionIndex[section.ID] = i
+		report.Sections = append(report.Sections, models.ConsultationSectionReport{
+			SectionID:   section.ID,
+			Title:       section.Title,
+			Resolutions: newResolutionCounts(),
+			Comments:    []models.ConsultationComment{},
+		})
+	}
+
+	participants := make(map[primitive.ObjectID]bool)
+	for _, comment := range comments {
+		participants[comment.AuthorID] = true
+		report.Resolutions[comment.Resolution]++
+
+		i, ok := sectionIndex[comment.SectionID]
+		if !ok {
+			continue
+		}
+		section := &report.Sections[i]
+		section.Total++
+		section.Resolutions[comment.Resolution]++
+		section.Comments = append(section.Comments, comment)
+	}
+	report.Participants = len(participants)
+
+	c.JSON(http.StatusOK, report)
+}
+
+func newResolutionCounts() map[string]int {
+	return map[string]int{
+		models.CommentResolutionPending:    0,
+		models.CommentResolutionAccepted:   0,
+		models.CommentResolutionConsidered: 0,
+		models.CommentResolutionRejected:   0,
+	}
+}
+
+// validateConsultationRequest перевіряє вікно коментування та унікальність розділів
+func validateConsultationRequest(c *gin.Context, req ConsultationRequest) bool {
+	if !req.EndDate.After(req.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "End date must be after start date",
+		})
+		return false
+	}
+
+	seen := make(map[string]bool, len(req.Sections))
+	for _, section := range req.Sections {
+		if strings.TrimSpace(section.ID) == "" || strings.TrimSpace(section.Title) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Each section must have an id and a title",
+			})
+			return false
+		}
+		if seen[section.ID] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Duplicate section ID",
+				"section_id": section.ID,
+			})
+			return false
+		}
+		seen[section.ID] = true
+	}
+	return true
+}
+
+// findConsultation завантажує обговорення поточної громади будь-якого статусу
+func (h *ConsultationHandler) findConsultation(ctx context.Context, c *gin.Context, consultationID primitive.ObjectID, consultation *models.Consultation) bool {
+	return h.findConsultationBy(ctx, c, communityScope(c, bson.M{"_id": consultationID}), consultation)
+}
+
+// findPublicConsultation завантажує опубліковане обговорення (чернетки мешканцям не видно)
+func (h *ConsultationHandler) findPublicConsultation(ctx context.Context, c *gin.Context, consultationID primitive.ObjectID, consultation *models.Consultation) bool {
+	return h.findConsultationBy(ctx, c, communityScope(c, bson.M{
+		"_id":    consultationID,
+		"status": bson.M{"$ne": models.ConsultationStatusDraft},
+	}), consultation)
+}
+
+func (h *ConsultationHandler) findConsultationBy(ctx context.Context, c *gin.Context, filter bson.M, consultation *models.Consultation) bool {
+	err := h.consultationCollection.FindOne(ctx, filter).Decode(consultation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Consultation not found",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching consultation",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,559 @@
+// internal/handlers/conversation.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConversationHandler - особисті повідомлення (1-на-1). Розмова створюється першим
+// повідомленням; нові повідомлення доставляються по WebSocket обом учасникам.
+type ConversationHandler struct {
+	conversationCollection  *mongo.Collection
+	directMessageCollection *mongo.Collection
+	userCollection          *mongo.Collection
+	wsHandler               *WebSocketHandler
+	chatLimiter             *services.ChatLimiter
+	trustService            *services.TrustService
+	notificationService     *services.NotificationService
+}
+
+func NewConversationHandler(conversationCollection, directMessageCollection, userCollection *mongo.Collection, wsHandler *WebSocketHandler, chatLimiter *services.ChatLimiter, trustService *services.TrustService, notificationService *services.NotificationService) *ConversationHandler {
+	return &ConversationHandler{
+		conversationCollection:  conversationCollection,
+		directMessageCollection: directMessageCollection,
+		userCollection:          userCollection,
+		wsHandler:               wsHandler,
+		chatLimiter:             chatLimiter,
+		trustService:            trustService,
+		notificationService:     notificationService,
+	}
+}
+
+type SendDirectMessageRequest struct {
+	Content  string `json:"content" binding:"required,max=1000"`
+	Type     string `json:"type" binding:"omitempty,oneof=text image video file link"`
+	MediaURL string `json:"media_url" binding:"omitempty,url,max=2048"`
+}
+
+// ConversationView - розмова у списку: співрозмовник і непрочитані поточного користувача
+type ConversationView struct {
+	models.Conversation
+	Participant *PublicProfile `json:"participant,omitempty"`
+	UnreadCount int            `json:"unread_count"`
+}
+
+// GetConversations - GET /conversations
+// Розмови користувача, спочатку з найновішими повідомленнями
+func (h *ConversationHandler) GetConversations(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, limit := pageParams(c, 20, 100)
+	filter := bson.M{
+		"participants":    user.UserID,
+		"last_message_at": bson.M{"$exists": true},
+	}
+
+	cursor, err := h.conversationCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "last_message_at", Value: -1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching conversations",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []models.Conversation
+	if err := cursor.All(ctx, &conversations); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error decoding conversations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	total, err := h.conversationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error counting conversations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Профілі співрозмовників одним запитом
+	otherIDs := make([]primitive.ObjectID, 0, len(conversations))
+	for i := range conversations {
+		otherIDs = append(otherIDs, conversations[i].OtherParticipant(user.UserID))
+	}
+	profiles := h.participantProfiles(ctx, otherIDs)
+
+	views := make([]ConversationView, 0, len(conversations))
+	for i := range conversations {
+		view := ConversationView{
+			Conversation: conversations[i],
+			UnreadCount:  conversations[i].UnreadFor(user.UserID),
+		}
+		if profile, ok := profiles[conversations[i].OtherParticipant(user.UserID)]; ok {
+			view.Participant = &profile
+		}
+		views = append(views, view)
+	}
+
+	c.JSON(http.StatusOK, listResponse(c, views, newPageInfo(page, limit, total), nil, nil))
+}
+
+// GetUnreadCount - GET /conversations/unread
+// Загальна кількість непрочитаних особистих повідомлень (бейдж у застосунку)
+func (h *ConversationHandler) GetUnreadCount(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	unreadField := "unread_counts." + user.UserID.Hex()
+	cursor, err := h.conversationCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"participants": user.UserID, unreadField: bson.M{"$gt": 0}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"unread_total":  bson.M{"$sum": "$" + unreadField},
+			"conversations": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error counting unread messages",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		UnreadTotal   int `bson:"unread_total"`
+		Conversations int `bson:"conversations"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error counting unread messages",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"unread_total":         result.UnreadTotal,
+		"unread_conversations": result.Conversations,
+	})
+}
+
+// GetMessages - GET /conversations/:userId/messages
+// Історія розмови з користувачем; сторінка 1 - останні повідомлення в хронологічному порядку
+func (h *ConversationHandler) GetMessages(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	otherID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, limit := pageParams(c, 50, 100)
+
+	var conversation models.Conversation
+	err = h.conversationCollection.FindOne(ctx, bson.M{
+		"participant_key": models.ConversationKey(user.UserID, otherID),
+	}).Decode(&conversation)
+	if err == mongo.ErrNoDocuments {
+		// Розмови ще немає: порожня історія, а не помилка
+		c.JSON(http.StatusOK, listResponse(c, []models.DirectMessage{}, newPageInfo(page, limit, 0), gin.H{"conversation": nil}, nil))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filter := bson.M{
+		"conversation_id": conversation.ID,
+		"is_deleted":      false,
+	}
+	cursor, err := h.directMessageCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching messages",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var messages []models.DirectMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error decoding messages",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Хронологічний порядок, як у групових чатах
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	total, _ := h.directMessageCollection.CountDocuments(ctx, filter)
+	view := ConversationView{
+		Conversation: conversation,
+		UnreadCount:  conversation.UnreadFor(user.UserID),
+	}
+	c.JSON(http.StatusOK, listResponse(c, messages, newPageInfo(page, limit, total), gin.H{"conversation": view}, nil))
+}
+
+// SendMessage - POST /conversations/:userId/messages
+// Створює розмову з користувачем, якщо її ще немає, і доставляє повідомлення обом учасникам
+func (h *ConversationHandler) SendMessage(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	recipientID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+	if recipientID == user.UserID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Cannot send a direct message to yourself",
+		})
+		return
+	}
+
+	var req SendDirectMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Message content is required",
+		})
+		return
+	}
+	if req.Type == "" {
+		req.Type = models.MessageTypeText
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var recipient models.User
+	err = h.userCollection.FindOne(ctx, bson.M{
+		"_id":        recipientID,
+		"is_deleted": bson.M{"$ne": true},
+	}).Decode(&recipient)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Ліміт частоти - спільний з груповими чатами; акаунти з низькою довірою пишуть рідше
+	if h.chatLimiter != nil {
+		quota := h.trustService.Quota(ctx, user.UserID)
+		minInterval := time.Duration(quota.ChatMinIntervalSeconds) * time.Second
+		if err := h.chatLimiter.Allow(user.UserID, recipientID, minInterval); err != nil {
+			respondChatLimited(c, err)
+			return
+		}
+	}
+
+	conversation, err := h.ensureConversation(ctx, user.UserID, recipientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creating conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	message := models.DirectMessage{
+		ConversationID: conversation.ID,
+		SenderID:       user.UserID,
+		RecipientID:    recipientID,
+		Content:        req.Content,
+		Type:           req.Type,
+		MediaURL:       req.MediaURL,
+		CreatedAt:      time.Now(),
+	}
+	result, err := h.directMessageCollection.InsertOne(ctx, message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error sending message",
+			"details": err.Error(),
+		})
+		return
+	}
+	message.ID = result.InsertedID.(primitive.ObjectID)
+
+	preview := message.Preview()
+	_, err = h.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversation.ID}, bson.M{
+		"$set": bson.M{
+			"last_message":    preview,
+			"last_message_at": message.CreatedAt,
+			"updated_at":      message.CreatedAt,
+		},
+		"$inc": bson.M{"unread_counts." + recipientID.Hex(): 1},
+	})
+	if err != nil {
+		// Повідомлення збережене; список розмов оновиться з наступним повідомленням
+		log.Printf("Error updating conversation %s: %v", conversation.ID.Hex(), err)
+	}
+
+	h.deliver(user.UserID, recipientID, "direct_message", &message, message.ID.Hex())
+
+	if h.notificationService != nil {
+		go h.notifyRecipient(user.UserID, &recipient, &message, preview.Preview)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         message,
+		"conversation_id": conversation.ID,
+	})
+}
+
+// MarkAsRead - POST /conversations/:userId/read
+// Позначає вхідні повідомлення розмови прочитаними та скидає лічильник непрочитаних
+func (h *ConversationHandler) MarkAsRead(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	otherID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var conversation models.Conversation
+	err = h.conversationCollection.FindOne(ctx, bson.M{
+		"participant_key": models.ConversationKey(user.UserID, otherID),
+	}).Decode(&conversation)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Conversation not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	result, err := h.directMessageCollection.UpdateMany(ctx, bson.M{
+		"conversation_id": conversation.ID,
+		"recipient_id":    user.UserID,
+		"read_at":         nil,
+	}, bson.M{"$set": bson.M{"read_at": now}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error marking messages as read",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	_, err = h.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversation.ID}, bson.M{
+		"$set": bson.M{"unread_counts." + user.UserID.Hex(): 0},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating conversation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Співрозмовник бачить, що повідомлення прочитані; інші пристрої користувача скидають бейдж
+	if result.ModifiedCount > 0 {
+		h.deliver(user.UserID, otherID, "direct_messages_read", gin.H{
+			"conversation_id": conversation.ID,
+			"reader_id":       user.UserID,
+			"read_at":         now,
+		}, "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Conversation marked as read",
+		"conversation_id": conversation.ID,
+		"marked_count":    result.ModifiedCount,
+	})
+}
+
+// ensureConversation повертає розмову пари користувачів, створюючи її за потреби.
+// Унікальний індекс participant_key не дає створити дві розмови при одночасних повідомленнях.
+func (h *ConversationHandler) ensureConversation(ctx context.Context, senderID, recipientID primitive.ObjectID) (*models.Conversation, error) {
+	now := time.Now()
+	filter := bson.M{"participant_key": models.ConversationKey(senderID, recipientID)}
+	update := bson.M{"$setOnInsert": bson.M{
+		"participants":    models.ConversationParticipants(senderID, recipientID),
+		"participant_key": models.ConversationKey(senderID, recipientID),
+		"created_at":      now,
+		"updated_at":      now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var conversation models.Conversation
+	err := h.conversationCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		// Розмову щойно створив паралельний запит
+		err = h.conversationCollection.FindOne(ctx, filter).Decode(&conversation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// deliver надсилає подію обом учасникам розмови на всі їхні WebSocket-з'єднання
+func (h *ConversationHandler) deliver(senderID, recipientID primitive.ObjectID, eventType string, data interface{}, cursor string) {
+	if h.wsHandler == nil {
+		return
+	}
+	frame := WSMessage{Type: eventType, Data: data, Cursor: cursor}
+	h.wsHandler.SendToUser(recipientID, frame)
+	h.wsHandler.SendToUser(senderID, frame)
+}
+
+// notifyRecipient - push-сповіщення для отримувача, який зараз не в застосунку
+func (h *ConversationHandler) notifyRecipient(senderID primitive.ObjectID, recipient *models.User, message *models.DirectMessage, preview string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var sender models.User
+	if err := h.userCollection.FindOne(ctx, bson.M{"_id": senderID}).Decode(&sender); err != nil {
+		log.Printf("Error loading direct message sender %s: %v", senderID.Hex(), err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"type":            services.NotificationTypeMessage,
+		"conversation_id": message.ConversationID.Hex(),
+		"sender_id":       senderID.Hex(),
+		"action":          "open_conversation",
+	}
+	title := fmt.Sprintf("%s %s", sender.FirstName, sender.LastName)
+	err := h.notificationService.SendNotificationToUser(ctx, recipient.ID, strings.TrimSpace(title), preview,
+		services.NotificationTypeMessage, data, &message.ConversationID)
+	if err != nil {
+		log.Printf("Error sending direct message notification: %v", err)
+	}
+}
+
+// participantProfiles - публічні профілі співрозмовників за ID
+func (h *ConversationHandler) participantProfiles(ctx context.Context, userIDs []primitive.ObjectID) map[primitive.ObjectID]PublicProfile {
+	profiles := make(map[primitive.ObjectID]PublicProfile, len(userIDs))
+	if len(userIDs) == 0 {
+		return profiles
+	}
+
+	cursor, err := h.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		log.Printf("Error loading conversation participants: %v", err)
+		return profiles
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+		profiles[user.ID] = publicProfile(&user)
+	}
+	return profiles
+}
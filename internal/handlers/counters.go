@@ -0,0 +1,82 @@
+// internal/handlers/counters.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CounterHandler - звірка денормалізованих лічильників (ручний запуск та історія)
+type CounterHandler struct {
+	counterService *services.CounterService
+}
+
+func NewCounterHandler(counterService *services.CounterService) *CounterHandler {
+	return &CounterHandler{
+		counterService: counterService,
+	}
+}
+
+// TriggerReconciliation - POST /admin/counters/reconcile
+// Запускає звірку лічильників поза розкладом; звірка йде у фоні
+func (h *CounterHandler) TriggerReconciliation(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	run, err := h.counterService.Trigger(ctx, adminID)
+	if errors.Is(err, services.ErrCounterReconciliationRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Counter reconciliation is already running",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error starting counter reconciliation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":        "Counter reconciliation started",
+		"reconciliation": run,
+	})
+}
+
+// GetReconciliations - GET /admin/counters/reconciliations
+// Останні 30 звірок, список лічильників і метрики розбіжностей з запуску сервера
+func (h *CounterHandler) GetReconciliations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runs, err := h.counterService.List(ctx, 30)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching counter reconciliations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reconciliations": runs,
+		"counters":        h.counterService.Counters(),
+		"metrics":         h.counterService.Metrics(),
+	})
+}
@@ -0,0 +1,77 @@
+// internal/handlers/data_export.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataExportHandler - вивантаження власних даних користувача (переносимість даних)
+type DataExportHandler struct {
+	dataExportService *services.DataExportService
+}
+
+func NewDataExportHandler(dataExportService *services.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportService: dataExportService,
+	}
+}
+
+// GetExport - GET /auth/export
+// Перший запит ставить підготовку ZIP-архіву в чергу (202), після готовності користувач
+// отримує сповіщення, а повторний запит повертає тимчасове посилання на архів (200)
+func (h *DataExportHandler) GetExport(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	export, created, err := h.dataExportService.Request(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error requesting data export",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if export.Status != models.DataExportCompleted {
+		message := "Data export is being prepared"
+		if created {
+			message = "Data export requested, you will be notified when it is ready"
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": message,
+			"export":  export,
+		})
+		return
+	}
+
+	downloadURL, ttl, err := h.dataExportService.DownloadURL(export)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error signing download URL",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"export":       export,
+		"download_url": downloadURL,
+		"expires_in":   int(ttl.Seconds()),
+	})
+}
@@ -0,0 +1,83 @@
+// internal/handlers/draft.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DraftHandler - продовження зберігання чернеток, які не змінювалися (посилання з попередження про видалення)
+type DraftHandler struct {
+	draftCleanup *services.DraftCleanupService
+}
+
+// NewDraftHandler створює обробник чернеток
+func NewDraftHandler(draftCleanup *services.DraftCleanupService) *DraftHandler {
+	return &DraftHandler{draftCleanup: draftCleanup}
+}
+
+// ExtendPollDraft - POST /polls/:id/extend-draft
+func (h *DraftHandler) ExtendPollDraft(c *gin.Context) {
+	h.extend(c, models.AuditResourcePoll)
+}
+
+// ExtendPetitionDraft - POST /petitions/:id/extend-draft
+func (h *DraftHandler) ExtendPetitionDraft(c *gin.Context) {
+	h.extend(c, models.AuditResourcePetition)
+}
+
+// extend відраховує строк зберігання чернетки автора заново
+func (h *DraftHandler) extend(c *gin.Context, resource string) {
+	draftID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid draft ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keepUntil, err := h.draftCleanup.Extend(ctx, resource, draftID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDraftNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Draft not found",
+			})
+		case errors.Is(err, services.ErrDraftForbidden):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Only the author can extend the draft",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error extending draft",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Draft extended successfully",
+		"id":         draftID,
+		"keep_until": keepUntil,
+	})
+}
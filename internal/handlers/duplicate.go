@@ -0,0 +1,69 @@
+// internal/handlers/duplicate.go
+
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// Скільки останніх записів перевіряємо на схожість
+	duplicateCandidateLimit = 300
+	// Мінімальна схожість, з якої запис пропонується як можливий дублікат
+	duplicateMinScore       = 0.45
+	duplicateMaxSuggestions = 5
+)
+
+// DuplicateSuggestion - можливий дублікат (зокрема той самий текст іншою мовою)
+type DuplicateSuggestion struct {
+	ID             primitive.ObjectID `json:"id"`
+	Title          string             `json:"title"`
+	Status         string             `json:"status"`
+	Score          float64            `json:"score"` // 0..1
+	DistanceMeters *float64           `json:"distance_meters,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// duplicateQuery - текст нового запису, який порівнюємо з наявними
+type duplicateQuery struct {
+	title       []string
+	description []string
+}
+
+// parseDuplicateQuery читає ?title= та ?description=; повертає false, якщо в заголовку немає значущих слів
+func parseDuplicateQuery(c *gin.Context) (duplicateQuery, bool) {
+	query := duplicateQuery{
+		title:       services.NormalizeCrossLanguage(strings.TrimSpace(c.Query("title"))),
+		description: services.NormalizeCrossLanguage(strings.TrimSpace(c.Query("description"))),
+	}
+	return query, len(query.title) > 0
+}
+
+// score - схожість запису з текстом запиту
+func (q duplicateQuery) score(title, description string) float64 {
+	return services.DuplicateScore(q.title, q.description,
+		services.NormalizeCrossLanguage(title), services.NormalizeCrossLanguage(description))
+}
+
+// topDuplicateSuggestions відкидає слабкі збіги та повертає найсхожіші записи
+func topDuplicateSuggestions(suggestions []DuplicateSuggestion) []DuplicateSuggestion {
+	result := make([]DuplicateSuggestion, 0, duplicateMaxSuggestions)
+	for _, suggestion := range suggestions {
+		if suggestion.Score >= duplicateMinScore {
+			suggestion.Score = float64(int(suggestion.Score*100)) / 100
+			result = append(result, suggestion)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	if len(result) > duplicateMaxSuggestions {
+		result = result[:duplicateMaxSuggestions]
+	}
+	return result
+}
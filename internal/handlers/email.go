@@ -0,0 +1,209 @@
+// internal/handlers/email.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EmailHandler - адміністрування черги листів, журналів доставки та списку блокування
+// 🔒 Всі методи вимагають ролі ADMIN
+type EmailHandler struct {
+	queueCollection       *mongo.Collection
+	logCollection         *mongo.Collection
+	suppressionCollection *mongo.Collection
+	emailService          *services.EmailService
+}
+
+// SuppressEmailRequest - запит на додавання адреси до списку блокування
+type SuppressEmailRequest struct {
+	Email   string `json:"email" binding:"required,email"`
+	Details string `json:"details"`
+}
+
+// NewEmailHandler створює новий обробник для адміністрування email
+func NewEmailHandler(queueCollection, logCollection, suppressionCollection *mongo.Collection, emailService *services.EmailService) *EmailHandler {
+	return &EmailHandler{
+		queueCollection:       queueCollection,
+		logCollection:         logCollection,
+		suppressionCollection: suppressionCollection,
+		emailService:          emailService,
+	}
+}
+
+// GetDeliveryLogs повертає журнал спроб доставки листів
+func (h *EmailHandler) GetDeliveryLogs(c *gin.Context) {
+	page, limit := pageParams(c, 50, 200)
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if to := c.Query("to"); to != "" {
+		filter["to"] = strings.ToLower(to)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.logCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching delivery logs",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	logs := []models.EmailDeliveryLog{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding delivery logs",
+		})
+		return
+	}
+
+	total, _ := h.logCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, logs, info, nil, gin.H{"logs": logs, "pagination": info}))
+}
+
+// GetQueueStats повертає кількість листів у черзі за статусами
+func (h *EmailHandler) GetQueueStats(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.queueCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error calculating queue stats",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	stats := map[string]int{
+		models.EmailStatusPending:    0,
+		models.EmailStatusSending:    0,
+		models.EmailStatusSent:       0,
+		models.EmailStatusFailed:     0,
+		models.EmailStatusSuppressed: 0,
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err == nil {
+			stats[row.ID] = row.Count
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queue":     stats,
+		"providers": h.emailService.ProviderHealth(),
+	})
+}
+
+// GetSuppressions повертає список заблокованих адрес
+func (h *EmailHandler) GetSuppressions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.suppressionCollection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(500))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching suppression list",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	suppressions := []models.EmailSuppression{}
+	if err := cursor.All(ctx, &suppressions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding suppression list",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suppressions": suppressions,
+		"count":        len(suppressions),
+	})
+}
+
+// AddSuppression вручну блокує адресу
+func (h *EmailHandler) AddSuppression(c *gin.Context) {
+	var req SuppressEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.emailService.Suppress(ctx, req.Email, models.EmailSuppressionManual, req.Details); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating suppression list",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email suppressed",
+	})
+}
+
+// RemoveSuppression знімає блокування з адреси
+func (h *EmailHandler) RemoveSuppression(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	removed, err := h.emailService.Unsuppress(ctx, c.Param("email"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating suppression list",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Email is not suppressed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email removed from suppression list",
+	})
+}
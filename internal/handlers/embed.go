@@ -0,0 +1,377 @@
+// internal/handlers/embed.go
+
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EmbedHandler - віджети петицій та опитувань для вбудовування в iframe на сайтах громади
+type EmbedHandler struct {
+	petitionCollection *mongo.Collection
+	pollCollection     *mongo.Collection
+	embedService       *services.EmbedService
+	appBaseURL         string // Адреса застосунку для переходу з віджета (universal/app links)
+}
+
+type CreateEmbedRequest struct {
+	ResourceType string   `json:"resource_type" binding:"required,oneof=petition poll"`
+	ResourceID   string   `json:"resource_id" binding:"required"`
+	Origins      []string `json:"origins" binding:"required,min=1,max=10"` // Сайти, де дозволено показ
+	TTLHours     int      `json:"ttl_hours" binding:"omitempty,min=1,max=168"`
+}
+
+// EmbedOption - варіант відповіді з посиланням на голосування в застосунку
+type EmbedOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// EmbedWidget - дані віджета: лічильник і посилання, без персональних даних
+type EmbedWidget struct {
+	Type   string    `json:"type"`
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	Status string    `json:"status"`
+	IsOpen bool      `json:"is_open"` // Збір підписів або голосування триває
+	EndsAt time.Time `json:"ends_at"`
+	URL    string    `json:"url"`
+
+	// Петиція
+	SignatureCount     int `json:"signature_count,omitempty"`
+	RequiredSignatures int `json:"required_signatures,omitempty"`
+	Progress           int `json:"progress,omitempty"` // Відсоток зібраних підписів (не більше 100)
+
+	// Опитування: перше питання з варіантами відповіді
+	TotalResponses int           `json:"total_responses,omitempty"`
+	Question       string        `json:"question,omitempty"`
+	Options        []EmbedOption `json:"options,omitempty"`
+}
+
+func NewEmbedHandler(petitionCollection, pollCollection *mongo.Collection, embedService *services.EmbedService, appBaseURL string) *EmbedHandler {
+	return &EmbedHandler{
+		petitionCollection: petitionCollection,
+		pollCollection:     pollCollection,
+		embedService:       embedService,
+		appBaseURL:         strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+// CreateEmbed - POST /admin/embeds
+// Видає підписаний токен віджета для вказаних сайтів
+func (h *EmbedHandler) CreateEmbed(c *gin.Context) {
+	var req CreateEmbedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	resourceID, err := primitive.ObjectIDFromHex(req.ResourceID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid resource ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Вбудовується лише опублікований ресурс поточної громади
+	if _, err := h.loadWidget(ctx, communityScope(c, bson.M{}), req.ResourceType, resourceID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Resource not found or not published",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Database error",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	token, claims, err := h.embedService.Issue(req.ResourceType, resourceID, req.Origins, time.Duration(req.TTLHours)*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Cannot issue embed token",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":       token,
+		"origins":     claims.Origins,
+		"expires_at":  time.Unix(claims.ExpiresAt, 0),
+		"iframe_path": "/api/v1/embed/" + token,
+		"data_path":   "/api/v1/embed/" + token + "/data",
+	})
+}
+
+// GetEmbedData - GET /embed/:token/data
+// Актуальні лічильники для віджета (оновлюються сторінкою віджета)
+func (h *EmbedHandler) GetEmbedData(c *gin.Context) {
+	claims, ok := h.verify(c, c.GetHeader("Origin"))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	widget, ok := h.widget(ctx, c, claims)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=15")
+	c.JSON(http.StatusOK, widget)
+}
+
+// GetEmbedFrame - GET /embed/:token
+// HTML-сторінка віджета для iframe; показ дозволено лише на сайтах із токена (frame-ancestors)
+func (h *EmbedHandler) GetEmbedFrame(c *gin.Context) {
+	// Браузер передає сайт, що вбудовує, у Referer (щонайменше origin)
+	claims, ok := h.verify(c, refererOrigin(c.GetHeader("Referer")))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	widget, ok := h.widget(ctx, c, claims)
+	if !ok {
+		return
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error rendering widget",
+		})
+		return
+	}
+	nonce := base64.StdEncoding.EncodeToString(nonceBytes)
+
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; script-src 'nonce-"+nonce+"'; "+
+		"connect-src 'self'; frame-ancestors "+strings.Join(claims.Origins, " "))
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := embedTemplate.Execute(c.Writer, gin.H{
+		"Widget":   widget,
+		"DataPath": "/api/v1/embed/" + c.Param("token") + "/data",
+		"Nonce":    nonce,
+	}); err != nil {
+		c.Error(err)
+	}
+}
+
+// verify перевіряє токен і сайт запиту; порожній origin (запит того ж сайту, не браузер) дозволений
+func (h *EmbedHandler) verify(c *gin.Context, origin string) (*services.EmbedClaims, bool) {
+	claims, err := h.embedService.Verify(c.Param("token"))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, services.ErrEmbedTokenExpired) {
+			status = http.StatusGone
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	if origin != "" && !claims.AllowsOrigin(origin) && !sameHost(origin, c.Request.Host) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": services.ErrEmbedOrigin.Error(),
+		})
+		return nil, false
+	}
+	return claims, true
+}
+
+// widget завантажує дані віджета або відповідає 404, якщо ресурс знято з публікації
+func (h *EmbedHandler) widget(ctx context.Context, c *gin.Context, claims *services.EmbedClaims) (*EmbedWidget, bool) {
+	widget, err := h.loadWidget(ctx, bson.M{}, claims.ResourceType, claims.ResourceID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Resource not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Database error",
+			})
+		}
+		return nil, false
+	}
+	return widget, true
+}
+
+func (h *EmbedHandler) loadWidget(ctx context.Context, filter bson.M, resourceType string, resourceID primitive.ObjectID) (*EmbedWidget, error) {
+	filter["_id"] = resourceID
+	now := time.Now()
+
+	switch resourceType {
+	case services.EmbedResourcePetition:
+		filter["status"] = bson.M{"$ne": models.PetitionStatusDraft}
+		var petition models.Petition
+		err := h.petitionCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{
+			"slug": 1, "title": 1, "status": 1, "end_date": 1, "signature_count": 1, "required_signatures": 1,
+		})).Decode(&petition)
+		if err != nil {
+			return nil, err
+		}
+
+		path := petition.Slug
+		if path == "" {
+			path = petition.ID.Hex()
+		}
+		widget := &EmbedWidget{
+			Type:               resourceType,
+			ID:                 petition.ID.Hex(),
+			Title:              petition.Title,
+			Status:             petition.Status,
+			IsOpen:             petition.Status == models.PetitionStatusActive && now.Before(petition.EndDate),
+			EndsAt:             petition.EndDate,
+			URL:                h.appBaseURL + "/petitions/" + url.PathEscape(path),
+			SignatureCount:     petition.SignatureCount,
+			RequiredSignatures: petition.RequiredSignatures,
+		}
+		if petition.RequiredSignatures > 0 {
+			widget.Progress = min(100, petition.SignatureCount*100/petition.RequiredSignatures)
+		}
+		return widget, nil
+
+	case services.EmbedResourcePoll:
+		filter["status"] = bson.M{"$nin": append([]string{models.PollStatusDraft}, models.PollModerationStatuses...)}
+		filter["is_public"] = true
+		var poll models.Poll
+		err := h.pollCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{
+			"title": 1, "status": 1, "end_date": 1, "total_responses": 1, "questions": 1,
+		})).Decode(&poll)
+		if err != nil {
+			return nil, err
+		}
+
+		pollURL := h.appBaseURL + "/polls/" + poll.ID.Hex()
+		widget := &EmbedWidget{
+			Type:           resourceType,
+			ID:             poll.ID.Hex(),
+			Title:          poll.Title,
+			Status:         poll.Status,
+			IsOpen:         poll.Status == models.PollStatusActive && now.Before(poll.EndDate),
+			EndsAt:         poll.EndDate,
+			URL:            pollURL,
+			TotalResponses: poll.TotalResponses,
+		}
+		// Кнопки голосування - лише для питання з вибором однієї відповіді
+		if len(poll.Questions) > 0 {
+			question := poll.Questions[0]
+			widget.Question = question.Text
+			if question.Type == "single_choice" || question.Type == "yes_no" {
+				for _, option := range question.Options {
+					widget.Options = append(widget.Options, EmbedOption{
+						ID:   option.ID.Hex(),
+						Text: option.Text,
+						URL:  pollURL + "?question=" + question.ID.Hex() + "&option=" + option.ID.Hex(),
+					})
+				}
+			}
+		}
+		return widget, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// refererOrigin - origin сторінки з Referer ("https://site/page" -> "https://site")
+func refererOrigin(referer string) string {
+	parsed, err := url.Parse(referer)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+func sameHost(origin, host string) bool {
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, host)
+}
+
+var embedTemplate = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html lang="uk">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Widget.Title}}</title>
+<style>
+body{margin:0;font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif;color:#1a1a1a}
+.widget{padding:16px;border:1px solid #d9d9d9;border-radius:8px;max-width:480px}
+h1{font-size:17px;margin:0 0 12px}
+.bar{height:8px;background:#eee;border-radius:4px;overflow:hidden;margin:8px 0}
+.bar div{height:100%;background:#0057b7}
+.count{font-size:14px;color:#555}
+.button{display:block;margin-top:8px;padding:10px 12px;border-radius:6px;background:#0057b7;color:#fff;text-decoration:none;text-align:center;font-size:15px}
+.button.secondary{background:#fff;color:#0057b7;border:1px solid #0057b7}
+.closed{font-size:14px;color:#a00;margin-top:8px}
+</style>
+</head>
+<body>
+<div class="widget">
+<h1>{{.Widget.Title}}</h1>
+{{if eq .Widget.Type "petition"}}
+<div class="bar"><div id="progress" style="width:{{.Widget.Progress}}%"></div></div>
+<div class="count"><span id="signatures">{{.Widget.SignatureCount}}</span> з {{.Widget.RequiredSignatures}} підписів</div>
+{{if .Widget.IsOpen}}<a class="button" href="{{.Widget.URL}}" target="_blank" rel="noopener">Підписати в застосунку</a>
+{{else}}<div class="closed">Збір підписів завершено</div><a class="button secondary" href="{{.Widget.URL}}" target="_blank" rel="noopener">Переглянути</a>{{end}}
+{{else}}
+{{if .Widget.Question}}<div>{{.Widget.Question}}</div>{{end}}
+<div class="count">Проголосували: <span id="responses">{{.Widget.TotalResponses}}</span></div>
+{{if .Widget.IsOpen}}{{range .Widget.Options}}<a class="button secondary" href="{{.URL}}" target="_blank" rel="noopener">{{.Text}}</a>
+{{end}}<a class="button" href="{{.Widget.URL}}" target="_blank" rel="noopener">Голосувати в застосунку</a>
+{{else}}<div class="closed">Голосування завершено</div><a class="button secondary" href="{{.Widget.URL}}" target="_blank" rel="noopener">Результати</a>{{end}}
+{{end}}
+</div>
+<script nonce="{{.Nonce}}">
+(function(){
+  var dataPath = {{.DataPath}};
+  function refresh(){
+    fetch(dataPath).then(function(r){ return r.ok ? r.json() : null; }).then(function(w){
+      if(!w) return;
+      var s = document.getElementById("signatures");
+      if(s) s.textContent = w.signature_count || 0;
+      var p = document.getElementById("progress");
+      if(p) p.style.width = (w.progress || 0) + "%";
+      var v = document.getElementById("responses");
+      if(v) v.textContent = w.total_responses || 0;
+    }).catch(function(){});
+  }
+  setInterval(refresh, 30000);
+})();
+</script>
+</body>
+</html>
+`))
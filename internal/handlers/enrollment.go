@@ -0,0 +1,882 @@
+// internal/handlers/enrollment.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnrollmentHandler - набір до шкіл і дитячих садків: заклади, періоди набору, місця, документи
+type EnrollmentHandler struct {
+	institutionCollection  *mongo.Collection
+	enrollmentCollection   *mongo.Collection
+	subscriptionCollection *mongo.Collection
+	notificationService    *services.NotificationService
+}
+
+// NewEnrollmentHandler створює обробник модуля освіти
+func NewEnrollmentHandler(institutionCollection, enrollmentCollection, subscriptionCollection *mongo.Collection, notificationService *services.NotificationService) *EnrollmentHandler {
+	return &EnrollmentHandler{
+		institutionCollection:  institutionCollection,
+		enrollmentCollection:   enrollmentCollection,
+		subscriptionCollection: subscriptionCollection,
+		notificationService:    notificationService,
+	}
+}
+
+type InstitutionRequest struct {
+	Name         string           `json:"name" binding:"required,min=3,max=200"`
+	Type         string           `json:"type" binding:"required,oneof=school kindergarten"`
+	Address      string           `json:"address" binding:"required,max=300"`
+	Neighborhood string           `json:"neighborhood" binding:"required,max=100"`
+	Location     *models.Location `json:"location"`
+	Phone        string           `json:"phone" binding:"max=50"`
+	Website      string           `json:"website" binding:"omitempty,url,max=300"`
+	IsActive     *bool            `json:"is_active"`
+}
+
+type EnrollmentPlacesRequest struct {
+	InstitutionID   string `json:"institution_id" binding:"required"`
+	Group           string `json:"group" binding:"max=100"`
+	TotalPlaces     int    `json:"total_places" binding:"min=0"`
+	AvailablePlaces int    `json:"available_places" binding:"min=0"`
+}
+
+type EnrollmentRequest struct {
+	Title           string                      `json:"title" binding:"required,min=5,max=300"`
+	Description     string                      `json:"description" binding:"max=10000"`
+	InstitutionType string                      `json:"institution_type" binding:"required,oneof=school kindergarten"`
+	AcademicYear    string                      `json:"academic_year" binding:"required,max=20"`
+	StartDate       time.Time                   `json:"start_date" binding:"required"`
+	EndDate         time.Time                   `json:"end_date" binding:"required"`
+	Places          []EnrollmentPlacesRequest   `json:"places" binding:"max=200,dive"`
+	Documents       []models.EnrollmentDocument `json:"documents" binding:"max=50"`
+}
+
+type UpdatePlacesRequest struct {
+	Group           string `json:"group" binding:"max=100"`
+	AvailablePlaces int    `json:"available_places" binding:"min=0"`
+}
+
+type EnrollmentSubscriptionRequest struct {
+	InstitutionTypes []string `json:"institution_types" binding:"max=2"`
+	Neighborhoods    []string `json:"neighborhoods" binding:"max=50"`
+}
+
+// ========================================
+// PUBLIC
+// ========================================
+
+// GetInstitutions - GET /education/institutions?type=school&neighborhood=Центр
+func (h *EnrollmentHandler) GetInstitutions(c *gin.Context) {
+	filter := communityScope(c, bson.M{"is_active": true})
+	if institutionType := c.Query("type"); institutionType != "" {
+		filter["type"] = institutionType
+	}
+	if neighborhood := c.Query("neighborhood"); neighborhood != "" {
+		filter["neighborhood"] = neighborhood
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.institutionCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "type", Value: 1}, {Key: "name", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching institutions",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	institutions := []models.EducationInstitution{}
+	if err := cursor.All(ctx, &institutions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding institutions",
+		})
+		return
+	}
+
+	neighborhoods, _ := h.institutionCollection.Distinct(ctx, "neighborhood", communityScope(c, bson.M{"is_active": true}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"institutions":  institutions,
+		"neighborhoods": neighborhoods,
+	})
+}
+
+// GetEnrollments - GET /education/enrollments?type=kindergarten&neighborhood=Центр&phase=open
+func (h *EnrollmentHandler) GetEnrollments(c *gin.Context) {
+	page, limit := pageParams(c, 20, 50)
+
+	now := time.Now()
+	filter := communityScope(c, bson.M{"status": models.EnrollmentStatusPublished})
+	if institutionType := c.Query("type"); institutionType != "" {
+		filter["institution_type"] = institutionType
+	}
+	if neighborhood := c.Query("neighborhood"); neighborhood != "" {
+		filter["neighborhoods"] = neighborhood
+	}
+	switch c.Query("phase") {
+	case "":
+	case models.EnrollmentPhaseUpcoming:
+		filter["start_date"] = bson.M{"$gt": now}
+	case models.EnrollmentPhaseOpen:
+		filter["start_date"] = bson.M{"$lte": now}
+		filter["end_date"] = bson.M{"$gte": now}
+	case models.EnrollmentPhaseClosed:
+		filter["end_date"] = bson.M{"$lt": now}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "phase must be one of upcoming, open, closed",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := h.enrollmentCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting enrollments",
+		})
+		return
+	}
+
+	cursor, err := h.enrollmentCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "start_date", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching enrollments",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	enrollments := []models.EnrollmentAnnouncement{}
+	if err := cursor.All(ctx, &enrollments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding enrollments",
+		})
+		return
+	}
+	for i := range enrollments {
+		enrollments[i].Phase = enrollments[i].GetPhase(now)
+	}
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, enrollments, info, nil, gin.H{"enrollments": enrollments, "pagination": info}))
+}
+
+// GetEnrollment повертає оголошення про набір з місцями та переліком документів
+func (h *EnrollmentHandler) GetEnrollment(c *gin.Context) {
+	enrollmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid enrollment ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var enrollment models.EnrollmentAnnouncement
+	err = h.enrollmentCollection.FindOne(ctx, communityScope(c, bson.M{
+		"_id":    enrollmentID,
+		"status": bson.M{"$ne": models.EnrollmentStatusDraft},
+	})).Decode(&enrollment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Enrollment not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching enrollment",
+		})
+		return
+	}
+	enrollment.Phase = enrollment.GetPhase(time.Now())
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrollment":       enrollment,
+		"available_places": enrollment.TotalAvailablePlaces(),
+	})
+}
+
+// ========================================
+// ПІДПИСКА БАТЬКІВ
+// ========================================
+
+// GetSubscription повертає підписку поточного користувача (або subscribed=false)
+func (h *EnrollmentHandler) GetSubscription(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var subscription models.EnrollmentSubscription
+	err = h.subscriptionCollection.FindOne(ctx, bson.M{
+		"user_id":      userID,
+		"community_id": getCommunityID(c),
+	}).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusOK, gin.H{
+			"subscribed": false,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching subscription",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscribed":   true,
+		"subscription": subscription,
+	})
+}
+
+// Subscribe створює або оновлює підписку на нові набори за типом закладу та мікрорайонами
+func (h *EnrollmentHandler) Subscribe(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req EnrollmentSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	for _, institutionType := range req.InstitutionTypes {
+		if !models.IsValidInstitutionType(institutionType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Unknown institution type: %s", institutionType),
+			})
+			return
+		}
+	}
+	if req.InstitutionTypes == nil {
+		req.InstitutionTypes = []string{}
+	}
+	if req.Neighborhoods == nil {
+		req.Neighborhoods = []string{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var subscription models.EnrollmentSubscription
+	err = h.subscriptionCollection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": userID, "community_id": getCommunityID(c)},
+		bson.M{
+			"$set": bson.M{
+				"institution_types": req.InstitutionTypes,
+				"neighborhoods":     req.Neighborhoods,
+				"updated_at":        now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&subscription)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error saving subscription",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscribed":   true,
+		"subscription": subscription,
+	})
+}
+
+// Unsubscribe видаляє підписку поточного користувача
+func (h *EnrollmentHandler) Unsubscribe(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.subscriptionCollection.DeleteOne(ctx, bson.M{
+		"user_id":      userID,
+		"community_id": getCommunityID(c),
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error unsubscribing",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscribed": false,
+	})
+}
+
+// ========================================
+// УПРАВЛІННЯ ОСВІТИ (ADMIN)
+// ========================================
+
+// CreateInstitution додає заклад освіти
+func (h *EnrollmentHandler) CreateInstitution(c *gin.Context) {
+	var req InstitutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	institution := models.EducationInstitution{
+		CommunityID:  getCommunityID(c),
+		Name:         req.Name,
+		Type:         req.Type,
+		Address:      req.Address,
+		Neighborhood: req.Neighborhood,
+		Location:     req.Location,
+		Phone:        req.Phone,
+		Website:      req.Website,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	result, err := h.institutionCollection.InsertOne(ctx, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating institution",
+		})
+		return
+	}
+	institution.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, institution)
+}
+
+// UpdateInstitution оновлює дані закладу
+func (h *EnrollmentHandler) UpdateInstitution(c *gin.Context) {
+	institutionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid institution ID",
+		})
+		return
+	}
+
+	var req InstitutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	update := bson.M{
+		"name":         req.Name,
+		"type":         req.Type,
+		"address":      req.Address,
+		"neighborhood": req.Neighborhood,
+		"location":     req.Location,
+		"phone":        req.Phone,
+		"website":      req.Website,
+		"updated_at":   time.Now(),
+	}
+	if req.IsActive != nil {
+		update["is_active"] = *req.IsActive
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var institution models.EducationInstitution
+	err = h.institutionCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": institutionID}),
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&institution)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Institution not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating institution",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, institution)
+}
+
+// GetAllEnrollments повертає всі набори громади, включно з чернетками
+func (h *EnrollmentHandler) GetAllEnrollments(c *gin.Context) {
+	filter := communityScope(c, bson.M{})
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.enrollmentCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(200))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching enrollments",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	enrollments := []models.EnrollmentAnnouncement{}
+	if err := cursor.All(ctx, &enrollments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding enrollments",
+		})
+		return
+	}
+	now := time.Now()
+	for i := range enrollments {
+		enrollments[i].Phase = enrollments[i].GetPhase(now)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrollments": enrollments,
+	})
+}
+
+// CreateEnrollment створює чернетку оголошення про набір
+func (h *EnrollmentHandler) CreateEnrollment(c *gin.Context) {
+	var req EnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	enrollment := models.EnrollmentAnnouncement{
+		CommunityID: getCommunityID(c),
+		Status:      models.EnrollmentStatusDraft,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+	}
+	if !h.applyEnrollmentRequest(ctx, c, &enrollment, req) {
+		return
+	}
+
+	result, err := h.enrollmentCollection.InsertOne(ctx, enrollment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating enrollment",
+		})
+		return
+	}
+	enrollment.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// UpdateEnrollment оновлює набір; після публікації зміни бачать усі одразу
+func (h *EnrollmentHandler) UpdateEnrollment(c *gin.Context) {
+	enrollmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid enrollment ID",
+		})
+		return
+	}
+
+	var req EnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var enrollment models.EnrollmentAnnouncement
+	if !h.findEnrollment(ctx, c, enrollmentID, &enrollment) {
+		return
+	}
+	if !h.applyEnrollmentRequest(ctx, c, &enrollment, req) {
+		return
+	}
+
+	if _, err := h.enrollmentCollection.ReplaceOne(ctx, bson.M{"_id": enrollmentID}, enrollment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating enrollment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// UpdatePlaces оновлює кількість вільних місць закладу в наборі
+// PUT /education/enrollments/:id/places/:institution_id
+func (h *EnrollmentHandler) UpdatePlaces(c *gin.Context) {
+	enrollmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid enrollment ID",
+		})
+		return
+	}
+	institutionID, err := primitive.ObjectIDFromHex(c.Param("institution_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid institution ID",
+		})
+		return
+	}
+
+	var req UpdatePlacesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var enrollment models.EnrollmentAnnouncement
+	if !h.findEnrollment(ctx, c, enrollmentID, &enrollment) {
+		return
+	}
+
+	found := false
+	for i := range enrollment.Places {
+		place := &enrollment.Places[i]
+		if place.InstitutionID != institutionID || place.Group != req.Group {
+			continue
+		}
+		if req.AvailablePlaces > place.TotalPlaces {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Available places cannot exceed total places",
+				"total": place.TotalPlaces,
+			})
+			return
+		}
+		place.AvailablePlaces = req.AvailablePlaces
+		found = true
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Institution is not part of this enrollment",
+		})
+		return
+	}
+
+	if _, err := h.enrollmentCollection.UpdateOne(ctx,
+		bson.M{"_id": enrollmentID},
+		bson.M{"$set": bson.M{"places": enrollment.Places, "updated_at": time.Now()}},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating places",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"places":           enrollment.Places,
+		"available_places": enrollment.TotalAvailablePlaces(),
+	})
+}
+
+// PublishEnrollment публікує набір і сповіщає підписаних батьків за типом закладу та мікрорайоном
+func (h *EnrollmentHandler) PublishEnrollment(c *gin.Context) {
+	h.setEnrollmentStatus(c, models.EnrollmentStatusPublished)
+}
+
+// ArchiveEnrollment знімає набір з публікації
+func (h *EnrollmentHandler) ArchiveEnrollment(c *gin.Context) {
+	h.setEnrollmentStatus(c, models.EnrollmentStatusArchived)
+}
+
+func (h *EnrollmentHandler) setEnrollmentStatus(c *gin.Context, status string) {
+	enrollmentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid enrollment ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var enrollment models.EnrollmentAnnouncement
+	if !h.findEnrollment(ctx, c, enrollmentID, &enrollment) {
+		return
+	}
+	if enrollment.Status == status {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Enrollment already has this status",
+		})
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{"status": status, "updated_at": now}
+	// Повторна публікація після архівації не розсилає сповіщення вдруге
+	firstPublication := status == models.EnrollmentStatusPublished && enrollment.PublishedAt == nil
+	if firstPublication {
+		if len(enrollment.Places) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Enrollment must list at least one institution before publishing",
+			})
+			return
+		}
+		update["published_at"] = now
+	}
+
+	// Публікація і сповіщення підписаним батькам записуються разом
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := h.enrollmentCollection.UpdateOne(ctx, bson.M{"_id": enrollmentID}, bson.M{"$set": update}); err != nil {
+			return err
+		}
+		if !firstPublication {
+			return nil
+		}
+		return h.notifySubscribers(ctx, enrollment)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating enrollment",
+		})
+		return
+	}
+	enrollment.Status = status
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Enrollment status updated",
+		"status":  status,
+	})
+}
+
+// notifySubscribers сповіщає підписників, чиї фільтри збігаються з набором
+func (h *EnrollmentHandler) notifySubscribers(ctx context.Context, enrollment models.EnrollmentAnnouncement) error {
+	communityFilter := bson.M{"community_id": enrollment.CommunityID}
+	filter := bson.M{
+		"$and": []bson.M{
+			communityFilter,
+			{"$or": []bson.M{
+				{"institution_types": bson.M{"$size": 0}},
+				{"institution_types": enrollment.InstitutionType},
+			}},
+			{"$or": []bson.M{
+				{"neighborhoods": bson.M{"$size": 0}},
+				{"neighborhoods": bson.M{"$in": enrollment.Neighborhoods}},
+			}},
+		},
+	}
+
+	cursor, err := h.subscriptionCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"user_id": 1}))
+	if err != nil {
+		return err
+	}
+	var subscriptions []models.EnrollmentSubscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return err
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		userIDs = append(userIDs, subscription.UserID)
+	}
+
+	body := fmt.Sprintf("Прийом заяв %s - %s", enrollment.StartDate.Format("02.01.2006"), enrollment.EndDate.Format("02.01.2006"))
+	data := map[string]interface{}{
+		"enrollment_id":    enrollment.ID.Hex(),
+		"institution_type": enrollment.InstitutionType,
+	}
+	return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+		Source:    "enrollment.published",
+		UserIDs:   userIDs,
+		Title:     enrollment.Title,
+		Body:      body,
+		Type:      models.NotificationTypeEnrollment,
+		Data:      data,
+		RelatedID: &enrollment.ID,
+	})
+}
+
+// findEnrollment завантажує набір поточної громади та відповідає 404/500 у разі помилки
+func (h *EnrollmentHandler) findEnrollment(ctx context.Context, c *gin.Context, enrollmentID primitive.ObjectID, enrollment *models.EnrollmentAnnouncement) bool {
+	err := h.enrollmentCollection.FindOne(ctx, communityScope(c, bson.M{"_id": enrollmentID})).Decode(enrollment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Enrollment not found",
+			})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching enrollment",
+		})
+		return false
+	}
+	return true
+}
+
+// applyEnrollmentRequest переносить дані запиту в набір, підтягуючи назви та мікрорайони закладів
+func (h *EnrollmentHandler) applyEnrollmentRequest(ctx context.Context, c *gin.Context, enrollment *models.EnrollmentAnnouncement, req EnrollmentRequest) bool {
+	if !req.EndDate.After(req.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "End date must be after start date",
+		})
+		return false
+	}
+
+	institutionIDs := make([]primitive.ObjectID, 0, len(req.Places))
+	for _, place := range req.Places {
+		id, err := primitive.ObjectIDFromHex(place.InstitutionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid institution ID",
+			})
+			return false
+		}
+		if place.AvailablePlaces > place.TotalPlaces {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Available places cannot exceed total places",
+			})
+			return false
+		}
+		institutionIDs = append(institutionIDs, id)
+	}
+
+	institutions := make(map[primitive.ObjectID]models.EducationInstitution)
+	if len(institutionIDs) > 0 {
+		cursor, err := h.institutionCollection.Find(ctx, communityScope(c, bson.M{"_id": bson.M{"$in": institutionIDs}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching institutions",
+			})
+			return false
+		}
+		var found []models.EducationInstitution
+		if err := cursor.All(ctx, &found); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error decoding institutions",
+			})
+			return false
+		}
+		for _, institution := range found {
+			institutions[institution.ID] = institution
+		}
+	}
+
+	places := make([]models.InstitutionPlaces, 0, len(req.Places))
+	for i, place := range req.Places {
+		institution, ok := institutions[institutionIDs[i]]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":          "Institution not found",
+				"institution_id": place.InstitutionID,
+			})
+			return false
+		}
+		if institution.Type != req.InstitutionType {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":          "Institution type does not match enrollment type",
+				"institution_id": place.InstitutionID,
+			})
+			return false
+		}
+		places = append(places, models.InstitutionPlaces{
+			InstitutionID:   institution.ID,
+			InstitutionName: institution.Name,
+			Neighborhood:    institution.Neighborhood,
+			Group:           place.Group,
+			TotalPlaces:     place.TotalPlaces,
+			AvailablePlaces: place.AvailablePlaces,
+		})
+	}
+
+	enrollment.Title = req.Title
+	enrollment.Description = req.Description
+	enrollment.InstitutionType = req.InstitutionType
+	enrollment.AcademicYear = req.AcademicYear
+	enrollment.StartDate = req.StartDate
+	enrollment.EndDate = req.EndDate
+	enrollment.Places = places
+	enrollment.Documents = req.Documents
+	if enrollment.Documents == nil {
+		enrollment.Documents = []models.EnrollmentDocument{}
+	}
+	enrollment.CollectNeighborhoods()
+	enrollment.UpdatedAt = time.Now()
+	return true
+}
@@ -3,11 +3,14 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,6 +22,12 @@ import (
 type EventHandler struct {
 	eventCollection *mongo.Collection
 	userCollection  *mongo.Collection
+	trustService    *services.TrustService
+	moderationLog   *services.ModerationLogService
+	tagService      *services.TagService
+
+	taxonomyService     *services.TaxonomyService
+	notificationService *services.NotificationService
 }
 
 type CreateEventRequest struct {
@@ -28,9 +37,12 @@ type CreateEventRequest struct {
 	EndDate         *time.Time      `json:"end_date,omitempty"`
 	Location        models.Location `json:"location"`
 	Address         string          `json:"address"`
+	Neighborhood    string          `json:"neighborhood"` // Мікрорайон, до 100 символів
 	IsOnline        bool            `json:"is_online"`
 	MaxParticipants int             `json:"max_participants"`
 	IsPublic        bool            `json:"is_public"`
+	Category        string          `json:"category" binding:"omitempty,oneof=cultural educational social business sports charity meeting workshop conference"`
+	Tags            []string        `json:"tags"`
 }
 
 type UpdateEventRequest struct {
@@ -39,9 +51,12 @@ type UpdateEventRequest struct {
 	StartDate       *time.Time `json:"start_date,omitempty"`
 	EndDate         *time.Time `json:"end_date,omitempty"`
 	Address         string     `json:"address,omitempty"`
+	Neighborhood    string     `json:"neighborhood,omitempty"`
 	IsOnline        *bool      `json:"is_online,omitempty"`
 	MaxParticipants *int       `json:"max_participants,omitempty"`
 	IsPublic        *bool      `json:"is_public,omitempty"`
+	Category        string     `json:"category,omitempty" binding:"omitempty,oneof=cultural educational social business sports charity meeting workshop conference"`
+	Tags            []string   `json:"tags,omitempty"`
 }
 
 type EventFilters struct {
@@ -57,10 +72,15 @@ type EventFilters struct {
 	Organizer string    `form:"organizer"`  // filter by organizer
 }
 
-func NewEventHandler(eventCollection, userCollection *mongo.Collection) *EventHandler {
+func NewEventHandler(eventCollection, userCollection *mongo.Collection, trustService *services.TrustService, moderationLog *services.ModerationLogService, tagService *services.TagService, taxonomyService *services.TaxonomyService, notificationService *services.NotificationService) *EventHandler {
 	return &EventHandler{
-		eventCollection: eventCollection,
-		userCollection:  userCollection,
+		eventCollection:     eventCollection,
+		userCollection:      userCollection,
+		trustService:        trustService,
+		moderationLog:       moderationLog,
+		tagService:          tagService,
+		taxonomyService:     taxonomyService,
+		notificationService: notificationService,
 	}
 }
 
@@ -99,27 +119,70 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tags, ok := canonicalizeTags(ctx, c, h.tagService, req.Tags)
+	if !ok {
+		return
+	}
+	neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+	if !ok {
+		return
+	}
+
+	// Денний ліміт подій залежить від рівня довіри до акаунту
+	quota := h.trustService.Quota(ctx, userIDObj)
+	createdToday, err := h.eventCollection.CountDocuments(ctx, bson.M{
+		"organizer_id": userIDObj,
+		"created_at":   bson.M{"$gte": time.Now().Add(-24 * time.Hour)},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if createdToday >= int64(quota.MaxEventsPerDay) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "Daily event limit reached",
+			"limit": quota.MaxEventsPerDay,
+		})
+		return
+	}
+
+	// Події нових/неперевірених акаунтів спочатку проходять модерацію
+	status := ""
+	if quota.PremoderateContent {
+		status = models.EventStatusPending
+	}
+
 	now := time.Now()
 	event := models.Event{
 		OrganizerID:     userIDObj,
 		Title:           req.Title,
 		Description:     req.Description,
+		Language:        services.DetectLanguage(req.Title, req.Description),
 		StartDate:       req.StartDate,
 		EndDate:         req.EndDate,
 		Location:        req.Location,
 		Address:         req.Address,
+		Neighborhood:    neighborhood,
 		IsOnline:        req.IsOnline,
 		Participants:    []primitive.ObjectID{userIDObj}, // Организатор автоматически участник
 		MaxParticipants: req.MaxParticipants,
 		IsPublic:        req.IsPublic,
+		Status:          status,
+		Category:        req.Category,
+		Tags:            tags,
+		CommunityID:     getCommunityID(c),
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	result, err := h.eventCollection.InsertOne(ctx, event)
+	// ID призначається заздалегідь: він входить у slug
+	event.ID = primitive.NewObjectID()
+	event.Slug, err = services.UniqueSlug(ctx, h.eventCollection, event.Title, "event", event.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creating event",
@@ -127,7 +190,21 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	event.ID = result.InsertedID.(primitive.ObjectID)
+	if _, err := h.eventCollection.InsertOne(ctx, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating event",
+		})
+		return
+	}
+
+	if h.tagService != nil {
+		h.tagService.Track(ctx, models.ModuleEvents, nil, tags)
+	}
+
+	// Подію на модерації анонсуємо після схвалення
+	if event.IsPublic && event.Status != models.EventStatusPending {
+		h.notifyInterested(c, &event)
+	}
 
 	c.JSON(http.StatusCreated, event)
 }
@@ -157,16 +234,23 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	}
 
 	// Строим фильтр для запроса
-	filter := bson.M{}
+	filter := communityScope(c, bson.M{})
 
 	if filters.IsPublic == nil || *filters.IsPublic {
 		filter["is_public"] = true
 	}
 
+	// Події на модерації не показуємо у публічних списках
+	filter["status"] = bson.M{"$ne": models.EventStatusPending}
+
 	if filters.IsOnline != nil {
 		filter["is_online"] = *filters.IsOnline
 	}
 
+	if !applyLanguageFilter(c, filter) {
+		return
+	}
+
 	if !filters.StartDate.IsZero() || !filters.EndDate.IsZero() {
 		dateFilter := bson.M{}
 		if !filters.StartDate.IsZero() {
@@ -233,17 +317,8 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 		totalCount = 0
 	}
 
-	totalPages := (totalCount + int64(filters.Limit) - 1) / int64(filters.Limit)
-
-	c.JSON(http.StatusOK, gin.H{
-		"events": events,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-		},
-	})
+	info := newPageInfo(filters.Page, filters.Limit, totalCount)
+	c.JSON(http.StatusOK, listResponse(c, events, info, nil, gin.H{"events": events, "pagination": info}))
 }
 
 func (h *EventHandler) getEventsWithAggregation(c *gin.Context, filter bson.M, filters EventFilters, sortOrder int) {
@@ -285,37 +360,25 @@ func (h *EventHandler) getEventsWithAggregation(c *gin.Context, filter bson.M, f
 		totalCount = 0
 	}
 
-	totalPages := (totalCount + int64(filters.Limit) - 1) / int64(filters.Limit)
-
-	c.JSON(http.StatusOK, gin.H{
-		"events": events,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-		},
-	})
+	info := newPageInfo(filters.Page, filters.Limit, totalCount)
+	c.JSON(http.StatusOK, listResponse(c, events, info, nil, gin.H{"events": events, "pagination": info}))
 }
 
 func (h *EventHandler) GetEvent(c *gin.Context) {
-	eventID := c.Param("id")
-	eventIDObj, err := primitive.ObjectIDFromHex(eventID)
-	if err != nil {
+	filter, ok := idOrSlugFilter(c.Param("id"))
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid event ID",
 		})
 		return
 	}
+	filter["is_public"] = true
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{
-		"_id":       eventIDObj,
-		"is_public": true,
-	}).Decode(&event)
+	err := h.eventCollection.FindOne(ctx, communityScope(c, filter)).Decode(&event)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -344,15 +407,7 @@ func (h *EventHandler) GetUserEvents(c *gin.Context) {
 	}
 
 	eventType := c.DefaultQuery("type", "organized") // organized, participating, all
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 50 {
-		limit = 20
-	}
+	page, limit := pageParams(c, 20, 50)
 
 	var filter bson.M
 	switch eventType {
@@ -397,7 +452,13 @@ func (h *EventHandler) GetUserEvents(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, events)
+	// Раньше endpoint возвращал массив без метаданных
+	if middleware.LegacyPagination(c) {
+		c.JSON(http.StatusOK, events)
+		return
+	}
+	total, _ := h.eventCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, events, newPageInfo(page, limit, total), nil, nil))
 }
 
 func (h *EventHandler) UpdateEvent(c *gin.Context) {
@@ -433,10 +494,10 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 
 	// Проверяем, что пользователь является организатором события
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{
+	err = h.eventCollection.FindOne(ctx, communityScope(c, bson.M{
 		"_id":          eventIDObj,
 		"organizer_id": userIDObj,
-	}).Decode(&event)
+	})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -461,6 +522,9 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	if req.Description != "" {
 		updateData["description"] = req.Description
 	}
+	if req.Title != "" || req.Description != "" {
+		updateData["language"] = detectEditedLanguage(event.Title, event.Description, req.Title, req.Description)
+	}
 	if req.StartDate != nil {
 		if req.StartDate.Before(time.Now()) {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -476,6 +540,13 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	if req.Address != "" {
 		updateData["address"] = req.Address
 	}
+	if req.Neighborhood != "" {
+		neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+		if !ok {
+			return
+		}
+		updateData["neighborhood"] = neighborhood
+	}
 	if req.IsOnline != nil {
 		updateData["is_online"] = *req.IsOnline
 	}
@@ -485,8 +556,19 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	if req.IsPublic != nil {
 		updateData["is_public"] = *req.IsPublic
 	}
+	if req.Category != "" {
+		updateData["category"] = req.Category
+	}
+	var tags []string
+	if req.Tags != nil {
+		var ok bool
+		if tags, ok = canonicalizeTags(ctx, c, h.tagService, req.Tags); !ok {
+			return
+		}
+		updateData["tags"] = tags
+	}
 
-	result, err := h.eventCollection.UpdateOne(ctx, bson.M{"_id": eventIDObj}, bson.M{
+	result, err := h.eventCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": eventIDObj}), bson.M{
 		"$set": updateData,
 	})
 	if err != nil {
@@ -503,6 +585,10 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
+	if req.Tags != nil && h.tagService != nil {
+		h.tagService.Track(ctx, models.ModuleEvents, event.Tags, tags)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Event updated successfully",
 	})
@@ -531,10 +617,10 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	defer cancel()
 
 	// Удаляем событие (только организатор может удалить)
-	result, err := h.eventCollection.DeleteOne(ctx, bson.M{
+	result, err := h.eventCollection.DeleteOne(ctx, communityScope(c, bson.M{
 		"_id":          eventIDObj,
 		"organizer_id": userIDObj,
-	})
+	}))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -579,11 +665,11 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 
 	// Проверяем существование события и возможность присоединения
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{
+	err = h.eventCollection.FindOne(ctx, communityScope(c, bson.M{
 		"_id":        eventIDObj,
 		"is_public":  true,
 		"start_date": bson.M{"$gt": time.Now()}, // Только будущие события
-	}).Decode(&event)
+	})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -616,7 +702,7 @@ func (h *EventHandler) JoinEvent(c *gin.Context) {
 	}
 
 	// Добавляем пользователя в участники
-	result, err := h.eventCollection.UpdateOne(ctx, bson.M{"_id": eventIDObj}, bson.M{
+	result, err := h.eventCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": eventIDObj}), bson.M{
 		"$push": bson.M{"participants": userIDObj},
 		"$set":  bson.M{"updated_at": time.Now()},
 	})
@@ -663,7 +749,7 @@ func (h *EventHandler) LeaveEvent(c *gin.Context) {
 
 	// Проверяем, что пользователь не является организатором (организатор не может покинуть событие)
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{"_id": eventIDObj}).Decode(&event)
+	err = h.eventCollection.FindOne(ctx, communityScope(c, bson.M{"_id": eventIDObj})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -685,7 +771,7 @@ func (h *EventHandler) LeaveEvent(c *gin.Context) {
 	}
 
 	// Убираем пользователя из участников
-	result, err := h.eventCollection.UpdateOne(ctx, bson.M{"_id": eventIDObj}, bson.M{
+	result, err := h.eventCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": eventIDObj}), bson.M{
 		"$pull": bson.M{"participants": userIDObj},
 		"$set":  bson.M{"updated_at": time.Now()},
 	})
@@ -723,7 +809,7 @@ func (h *EventHandler) GetEventParticipants(c *gin.Context) {
 
 	// Проверяем существование события
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{"_id": eventIDObj}).Decode(&event)
+	err = h.eventCollection.FindOne(ctx, communityScope(c, bson.M{"_id": eventIDObj})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -777,22 +863,20 @@ func (h *EventHandler) AttendEvent(c *gin.Context) {
 	}
 
 	// Отримуємо ID користувача
-	userID, exists := c.Get("user_id")
-	if !exists {
+	userIDObj, err := getUserID(c)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "Unauthorized",
 		})
 		return
 	}
 
-	userIDObj := userID.(primitive.ObjectID)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Перевіряємо чи подія існує
 	var event models.Event
-	err = h.eventCollection.FindOne(ctx, bson.M{"_id": eventID}).Decode(&event)
+	err = h.eventCollection.FindOne(ctx, communityScope(c, bson.M{"_id": eventID})).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -819,7 +903,7 @@ func (h *EventHandler) AttendEvent(c *gin.Context) {
 	// Додаємо користувача до списку учасників
 	_, err = h.eventCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": eventID},
+		communityScope(c, bson.M{"_id": eventID}),
 		bson.M{
 			"$push": bson.M{"attendees": userIDObj},
 			"$inc":  bson.M{"attendee_count": 1},
@@ -876,9 +960,10 @@ func (h *EventHandler) ModerateEvent(c *gin.Context) {
 	}
 
 	// Оновлюємо подію
-	result, err := h.eventCollection.UpdateOne(
+	var event models.Event
+	err = h.eventCollection.FindOneAndUpdate(
 		ctx,
-		bson.M{"_id": eventID},
+		withModerationScope(c, communityScope(c, bson.M{"_id": eventID})),
 		bson.M{
 			"$set": bson.M{
 				"status":            newStatus,
@@ -887,8 +972,17 @@ func (h *EventHandler) ModerateEvent(c *gin.Context) {
 				"updated_at":        time.Now(),
 			},
 		},
-	)
+	).Decode(&event)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if respondOutOfScope(ctx, c, h.eventCollection, eventID) {
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Event not found",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error moderating event",
 			"details": err.Error(),
@@ -896,13 +990,28 @@ func (h *EventHandler) ModerateEvent(c *gin.Context) {
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Event not found",
-		})
-		return
+	// Відхилена подія знижує довіру до організатора
+	if newStatus == "rejected" && event.Status != "rejected" {
+		h.trustService.RecordRemoval(ctx, event.OrganizerID)
+	}
+
+	// Схвалену після премодерації подію анонсуємо зацікавленим користувачам
+	if newStatus == "approved" && event.Status == models.EventStatusPending && event.IsPublic {
+		h.notifyInterested(c, &event)
 	}
 
+	moderatorID, _ := getUserID(c)
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: event.CommunityID,
+		ModeratorID: moderatorID,
+		ContentType: models.ModerationContentEvent,
+		ContentID:   event.ID,
+		AuthorID:    event.OrganizerID,
+		Decision:    newStatus,
+		Reason:      req.Reason,
+		SubmittedAt: event.CreatedAt,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Event moderated successfully",
 		"status":  newStatus,
@@ -1021,7 +1130,7 @@ func (h *EventHandler) GetNearbyEvents(c *gin.Context) {
 	defer cancel()
 
 	// Используем гео-запрос MongoDB для поиска событий поблизости
-	cursor, err := h.eventCollection.Find(ctx, bson.M{
+	cursor, err := h.eventCollection.Find(ctx, communityScope(c, bson.M{
 		"location": bson.M{
 			"$near": bson.M{
 				"$geometry": bson.M{
@@ -1031,9 +1140,10 @@ func (h *EventHandler) GetNearbyEvents(c *gin.Context) {
 				"$maxDistance": radiusMeters,
 			},
 		},
-		"is_public": true,
+		"is_public":  true,
+		"status":     bson.M{"$ne": models.EventStatusPending},
 		"start_date": bson.M{"$gte": time.Now()}, // Только будущие события
-	}, options.Find().SetLimit(50).SetSort(bson.D{{Key: "start_date", Value: 1}}))
+	}), options.Find().SetLimit(50).SetSort(bson.D{{Key: "start_date", Value: 1}}))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1068,22 +1178,15 @@ func (h *EventHandler) SearchEvents(c *gin.Context) {
 	category := c.Query("category")
 	dateFromStr := c.Query("date_from")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 50 {
-		limit = 20
-	}
+	page, limit := pageParams(c, 20, 50)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
+	filter := communityScope(c, bson.M{
 		"is_public": true,
-	}
+		"status":    bson.M{"$ne": models.EventStatusPending},
+	})
 
 	// Текстовый поиск по названию и описанию
 	if query != "" {
@@ -1133,13 +1236,105 @@ func (h *EventHandler) SearchEvents(c *gin.Context) {
 	}
 
 	total, _ := h.eventCollection.CountDocuments(ctx, filter)
-	totalPages := (int(total) + limit - 1) / limit
+	info := newPageInfo(page, limit, total)
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, listResponse(c, events, info, nil, gin.H{
 		"events":      events,
 		"total":       total,
 		"page":        page,
 		"limit":       limit,
-		"total_pages": totalPages,
+		"total_pages": info.TotalPages,
+	}))
+}
+
+// GetPendingEvents повертає події, що очікують модерації (від акаунтів з низькою довірою)
+func (h *EventHandler) GetPendingEvents(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.eventCollection.Find(ctx,
+		communityScope(c, withModerationScope(c, bson.M{"status": models.EventStatusPending})),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(100),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching pending events",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.Event{}
+	if err := cursor.All(ctx, &events); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+// GetRecommendedEvents - GET /events/recommended
+// Найближчі події за інтересами користувача; без вибраних інтересів - найближчі події громади
+func (h *EventHandler) GetRecommendedEvents(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := communityScope(c, bson.M{
+		"is_public":  true,
+		"status":     bson.M{"$nin": []string{models.EventStatusPending, models.EventStatusDraft, models.EventStatusCancelled, "rejected"}},
+		"start_date": bson.M{"$gte": time.Now()},
+	})
+	personalized, ok := recommendationScope(ctx, c, h.userCollection, h.taxonomyService, models.ModuleEvents, filter)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.eventCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "start_date", Value: 1}}).
+		SetLimit(recommendationLimit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching events",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.Event{}
+	if err := cursor.All(ctx, &events); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":       events,
+		"personalized": personalized,
 	})
 }
+
+// notifyInterested анонсує публічну подію користувачам з відповідними інтересами
+func (h *EventHandler) notifyInterested(c *gin.Context, event *models.Event) {
+	notifyInterested(c, h.notificationService, h.taxonomyService, models.ModuleEvents, event.Category, event.Tags,
+		services.InterestAudience{
+			CommunityID: event.CommunityID,
+			Preference:  "events",
+			ExcludeID:   event.OrganizerID,
+		},
+		"Нова подія за вашими інтересами",
+		fmt.Sprintf("%s - %s", event.Title, event.StartDate.Format("02.01.2006 15:04")),
+		services.NotificationTypeEvent,
+		map[string]interface{}{
+			"type":     "event",
+			"event_id": event.ID.Hex(),
+			"action":   "open_event",
+		},
+		event.ID,
+	)
+}
@@ -0,0 +1,570 @@
+// internal/handlers/export.go
+
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Вивантаження можуть бути великими, тому тайм-аут більший за звичайні 10 секунд
+const exportTimeout = 2 * time.Minute
+
+// ExportHandler - CSV-вивантаження аналітичних даних для дослідників
+type ExportHandler struct {
+	userCollection             *mongo.Collection
+	cityIssueCollection        *mongo.Collection
+	petitionCollection         *mongo.Collection
+	pollCollection             *mongo.Collection
+	moderationActionCollection *mongo.Collection
+	pseudonymizer              *services.Pseudonymizer
+	pollResponseCollection     *mongo.Collection
+}
+
+// NewExportHandler створює обробник аналітичних вивантажень
+func NewExportHandler(userCollection, cityIssueCollection, petitionCollection, pollCollection, moderationActionCollection *mongo.Collection, pseudonymizer *services.Pseudonymizer, pollResponseCollection *mongo.Collection) *ExportHandler {
+	return &ExportHandler{
+		userCollection:             userCollection,
+		cityIssueCollection:        cityIssueCollection,
+		petitionCollection:         petitionCollection,
+		pollCollection:             pollCollection,
+		moderationActionCollection: moderationActionCollection,
+		pseudonymizer:              pseudonymizer,
+		pollResponseCollection:     pollResponseCollection,
+	}
+}
+
+type RotateExportSaltRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// exportWriter пише рядки CSV і замінює ідентифікатори відповідно до режиму
+type exportWriter struct {
+	csv    *csv.Writer
+	salt   *models.ExportSalt
+	raw    bool
+	saltID string
+}
+
+// id повертає ідентифікатор користувача: справжній у режимі raw, інакше псевдонім
+func (w *exportWriter) id(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	if w.raw {
+		return id.Hex()
+	}
+	return services.Pseudonym(w.salt, id)
+}
+
+// row додає до рядка ідентифікатор солі, за яким дослідники визначають, які вивантаження можна поєднувати
+func (w *exportWriter) row(values ...string) error {
+	return w.csv.Write(append([]string{w.saltID}, values...))
+}
+
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func exportTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return exportTime(*t)
+}
+
+// GetDatasets повертає перелік наборів даних та поточну сіль (без секрету)
+func (h *ExportHandler) GetDatasets(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	salt, err := h.pseudonymizer.Current(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error loading export salt",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"datasets": models.ExportDatasets(),
+		"modes":    []string{models.ExportModePseudonymized, models.ExportModeRaw},
+		"salt":     salt,
+	})
+}
+
+// RotateSalt достроково змінює сіль. Псевдоніми в нових вивантаженнях
+// не збігатимуться з попередніми (наприклад, після витоку набору даних).
+func (h *ExportHandler) RotateSalt(c *gin.Context) {
+	var req RotateExportSaltRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	salt, err := h.pseudonymizer.Rotate(ctx, adminID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error rotating export salt",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Export salt rotated",
+		"salt":    salt,
+	})
+}
+
+// Export - GET /analytics/exports/:dataset?from=2024-01-01&to=2024-01-31&mode=pseudonymized
+// За замовчуванням ідентифікатори псевдонімізовані, а вільний текст, IP-адреси та адреси не вивантажуються.
+// Режим raw доступний лише SUPER_ADMIN.
+func (h *ExportHandler) Export(c *gin.Context) {
+	dataset := c.Param("dataset")
+	valid := false
+	for _, d := range models.ExportDatasets() {
+		if d == dataset {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":    "Unknown dataset",
+			"datasets": models.ExportDatasets(),
+		})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", models.ExportModePseudonymized)
+	if mode != models.ExportModePseudonymized && mode != models.ExportModeRaw {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid mode",
+		})
+		return
+	}
+	raw := mode == models.ExportModeRaw
+	if raw && !models.UserRole(c.GetString("user_role")).IsHigherOrEqual(models.RoleSuperAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Raw exports require super admin role",
+		})
+		return
+	}
+
+	// Період за датою створення; без параметрів - усі дані
+	dateFilter := bson.M{}
+	if value := c.Query("from"); value != "" {
+		from, err := parseNotificationDate(value, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date",
+			})
+			return
+		}
+		dateFilter["$gte"] = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := parseNotificationDate(value, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date",
+			})
+			return
+		}
+		dateFilter["$lt"] = to
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	w := &exportWriter{raw: raw}
+	if !raw {
+		salt, err := h.pseudonymizer.Current(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error loading export salt",
+			})
+			return
+		}
+		w.salt = salt
+		w.saltID = salt.ID.Hex()
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.csv", dataset, mode, time.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("X-Export-Mode", mode)
+	if w.salt != nil {
+		c.Header("X-Export-Salt-ID", w.saltID)
+	}
+	c.Status(http.StatusOK)
+
+	w.csv = csv.NewWriter(c.Writer)
+
+	var err error
+	switch dataset {
+	case models.ExportDatasetUsers:
+		err = h.exportUsers(ctx, c, w, dateFilter)
+	case models.ExportDatasetCityIssues:
+		err = h.exportCityIssues(ctx, c, w, dateFilter)
+	case models.ExportDatasetPetitionSignatures:
+		err = h.exportPetitionSignatures(ctx, c, w, dateFilter)
+	case models.ExportDatasetPollResponses:
+		err = h.exportPollResponses(ctx, c, w, dateFilter)
+	case models.ExportDatasetModerationActions:
+		err = h.exportModerationActions(ctx, c, w, dateFilter)
+	}
+
+	w.csv.Flush()
+	if err == nil {
+		err = w.csv.Error()
+	}
+	if err != nil {
+		// Заголовки вже надіслані, тому статус змінити неможливо
+		log.Printf("Export %s failed: %v", dataset, err)
+	}
+}
+
+func (h *ExportHandler) exportUsers(ctx context.Context, c *gin.Context, w *exportWriter, dateFilter bson.M) error {
+	filter := bson.M{}
+	if len(dateFilter) > 0 {
+		filter["created_at"] = dateFilter
+	}
+	if communityID := getCommunityID(c); !communityID.IsZero() {
+		filter["community_ids"] = communityID
+	}
+
+	header := []string{"salt_id", "user_id", "role", "is_verified", "is_blocked", "interests", "groups_count", "created_at", "last_login_at"}
+	if w.raw {
+		header = append(header, "email", "full_name")
+	}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+
+	cursor, err := h.userCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"password_hash": 0}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		values := []string{
+			w.id(user.ID),
+			user.Role,
+			strconv.FormatBool(user.IsVerified),
+			strconv.FormatBool(user.IsBlocked),
+			strings.Join(user.Interests, ";"),
+			strconv.Itoa(len(user.Groups)),
+			exportTime(user.CreatedAt),
+			exportTimePtr(user.LastLoginAt),
+		}
+		if w.raw {
+			values = append(values, user.Email, user.GetFullName())
+		}
+		if err := w.row(values...); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (h *ExportHandler) exportCityIssues(ctx context.Context, c *gin.Context, w *exportWriter, dateFilter bson.M) error {
+	filter := communityScope(c, bson.M{})
+	if len(dateFilter) > 0 {
+		filter["created_at"] = dateFilter
+	}
+
+	header := []string{"salt_id", "issue_id", "reporter_id", "category", "priority", "status", "assigned_dept", "upvote_count", "comment_count", "created_at", "resolved_at"}
+	if w.raw {
+		header = append(header, "address", "longitude", "latitude")
+	}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+
+	cursor, err := h.cityIssueCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var issue models.CityIssue
+		if err := cursor.Decode(&issue); err != nil {
+			return err
+		}
+		values := []string{
+			issue.ID.Hex(),
+			w.id(issue.ReporterID),
+			issue.Category,
+			issue.Priority,
+			issue.Status,
+			issue.AssignedDept,
+			strconv.Itoa(issue.UpVoteCount),
+			strconv.Itoa(len(issue.Comments)),
+			exportTime(issue.CreatedAt),
+			exportTimePtr(issue.ResolvedAt),
+		}
+		if w.raw {
+			lng, lat := "", ""
+			if len(issue.Location.Coordinates) == 2 {
+				lng = strconv.FormatFloat(issue.Location.Coordinates[0], 'f', -1, 64)
+				lat = strconv.FormatFloat(issue.Location.Coordinates[1], 'f', -1, 64)
+			}
+			values = append(values, issue.Address, lng, lat)
+		}
+		if err := w.row(values...); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (h *ExportHandler) exportPetitionSignatures(ctx context.Context, c *gin.Context, w *exportWriter, dateFilter bson.M) error {
+	filter := communityScope(c, bson.M{})
+	if len(dateFilter) > 0 {
+		filter["signatures.signed_at"] = dateFilter
+	}
+
+	header := []string{"salt_id", "petition_id", "petition_category", "petition_status", "user_id", "is_verified", "signed_at"}
+	if w.raw {
+		header = append(header, "full_name", "comment")
+	}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+
+	cursor, err := h.petitionCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"category": 1, "status": 1, "signatures": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	from, hasFrom := dateFilter["$gte"].(time.Time)
+	to, hasTo := dateFilter["$lt"].(time.Time)
+
+	for cursor.Next(ctx) {
+		var petition models.Petition
+		if err := cursor.Decode(&petition); err != nil {
+			return err
+		}
+		for _, signature := range petition.Signatures {
+			if (hasFrom && signature.SignedAt.Before(from)) || (hasTo && !signature.SignedAt.Before(to)) {
+				continue
+			}
+			values := []string{
+				petition.ID.Hex(),
+				petition.Category,
+				petition.Status,
+				w.id(signature.UserID),
+				strconv.FormatBool(signature.IsVerified),
+				exportTime(signature.SignedAt),
+			}
+			if w.raw {
+				values = append(values, signature.FullName, signature.Comment)
+			}
+			if err := w.row(values...); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// exportPollResponses - один рядок на відповідь на питання
+func (h *ExportHandler) exportPollResponses(ctx context.Context, c *gin.Context, w *exportWriter, dateFilter bson.M) error {
+	filter := communityScope(c, bson.M{})
+
+	header := []string{"salt_id", "poll_id", "poll_category", "response_id", "user_id", "question_id", "question_type", "question_media", "option_ids", "number_answer", "bool_answer", "submitted_at"}
+	if w.raw {
+		header = append(header, "text_answer")
+	}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+
+	cursor, err := h.pollCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"category": 1, "is_anonymous": 1, "questions": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var poll models.Poll
+		if err := cursor.Decode(&poll); err != nil {
+			return err
+		}
+
+		// Тип питання та його медіа (зображення, відео), щоб дослідники бачили, на що відповідали учасники
+		questionTypes := make(map[primitive.ObjectID]string, len(poll.Questions))
+		questionMedia := make(map[primitive.ObjectID]string, len(poll.Questions))
+		for _, question := range poll.Questions {
+			questionTypes[question.ID] = question.Type
+			if !question.Media.IsEmpty() {
+				media := append([]string{}, question.Media.Images...)
+				if question.Media.VideoURL != "" {
+					media = append(media, question.Media.VideoURL)
+				}
+				questionMedia[question.ID] = strings.Join(media, ";")
+			}
+		}
+
+		if err := h.exportPollResponseRows(ctx, w, &poll, questionTypes, questionMedia, dateFilter); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// exportPollResponseRows - рядки відповідей одного опитування з poll_responses
+func (h *ExportHandler) exportPollResponseRows(ctx context.Context, w *exportWriter, poll *models.Poll, questionTypes, questionMedia map[primitive.ObjectID]string, dateFilter bson.M) error {
+	filter := bson.M{"poll_id": poll.ID}
+	if len(dateFilter) > 0 {
+		filter["submitted_at"] = dateFilter
+	}
+
+	cursor, err := h.pollResponseCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var response models.PollResponse
+		if err := cursor.Decode(&response); err != nil {
+			return err
+		}
+
+		// Анонімні опитування не розкривають учасника навіть у режимі raw
+		userID := ""
+		if !poll.IsAnonymous {
+			userID = w.id(response.UserID)
+		}
+
+		for _, answer := range response.Answers {
+			optionIDs := make([]string, 0, len(answer.OptionIDs))
+			for _, optionID := range answer.OptionIDs {
+				optionIDs = append(optionIDs, optionID.Hex())
+			}
+			numberAnswer := ""
+			if answer.NumberAnswer != nil {
+				numberAnswer = strconv.Itoa(*answer.NumberAnswer)
+			}
+			boolAnswer := ""
+			if answer.BoolAnswer != nil {
+				boolAnswer = strconv.FormatBool(*answer.BoolAnswer)
+			}
+
+			values := []string{
+				poll.ID.Hex(),
+				poll.Category,
+				response.ID.Hex(),
+				userID,
+				answer.QuestionID.Hex(),
+				questionTypes[answer.QuestionID],
+				questionMedia[answer.QuestionID],
+				strings.Join(optionIDs, ";"),
+				numberAnswer,
+				boolAnswer,
+				exportTime(response.SubmittedAt),
+			}
+			if w.raw {
+				values = append(values, answer.TextAnswer)
+			}
+			if err := w.row(values...); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+func (h *ExportHandler) exportModerationActions(ctx context.Context, c *gin.Context, w *exportWriter, dateFilter bson.M) error {
+	filter := communityScope(c, bson.M{})
+	if len(dateFilter) > 0 {
+		filter["decided_at"] = dateFilter
+	}
+
+	header := []string{"salt_id", "action_id", "moderator_id", "author_id", "content_type", "content_id", "decision", "decision_seconds", "submitted_at", "decided_at"}
+	if w.raw {
+		header = append(header, "reason")
+	}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+
+	cursor, err := h.moderationActionCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "decided_at", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var action models.ModerationAction
+		if err := cursor.Decode(&action); err != nil {
+			return err
+		}
+		values := []string{
+			action.ID.Hex(),
+			w.id(action.ModeratorID),
+			w.id(action.AuthorID),
+			action.ContentType,
+			action.ContentID.Hex(),
+			action.Decision,
+			strconv.FormatInt(action.DecisionSeconds, 10),
+			exportTime(action.SubmittedAt),
+			exportTime(action.DecidedAt),
+		}
+		if w.raw {
+			values = append(values, action.Reason)
+		}
+		if err := w.row(values...); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
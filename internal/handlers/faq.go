@@ -0,0 +1,745 @@
+// internal/handlers/faq.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FAQHandler - база знань міста (довідка вебсайту, застосунку та Telegram-бота)
+type FAQHandler struct {
+	categoryCollection *mongo.Collection
+	articleCollection  *mongo.Collection
+	feedbackCollection *mongo.Collection
+}
+
+// NewFAQHandler створює обробник бази знань
+func NewFAQHandler(categoryCollection, articleCollection, feedbackCollection *mongo.Collection) *FAQHandler {
+	return &FAQHandler{
+		categoryCollection: categoryCollection,
+		articleCollection:  articleCollection,
+		feedbackCollection: feedbackCollection,
+	}
+}
+
+type FAQCategoryRequest struct {
+	Title       string `json:"title" binding:"required,min=2,max=200"`
+	Description string `json:"description" binding:"max=1000"`
+	Icon        string `json:"icon" binding:"max=100"`
+	SortOrder   int    `json:"sort_order"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+type FAQArticleRequest struct {
+	CategoryID  string   `json:"category_id" binding:"required"`
+	Question    string   `json:"question" binding:"required,min=5,max=300"`
+	Summary     string   `json:"summary" binding:"max=500"`
+	Body        string   `json:"body" binding:"required,min=10,max=50000"`
+	BodyFormat  string   `json:"body_format" binding:"omitempty,oneof=markdown html"`
+	Keywords    []string `json:"keywords" binding:"max=20"`
+	SortOrder   int      `json:"sort_order"`
+	IsPublished bool     `json:"is_published"`
+}
+
+type FAQFeedbackRequest struct {
+	Helpful *bool  `json:"helpful" binding:"required"`
+	Comment string `json:"comment" binding:"max=1000"`
+	// Ідентифікатор клієнта без акаунту (наприклад, tg:<chat_id> для Telegram-бота)
+	ClientID string `json:"client_id" binding:"max=100"`
+}
+
+// ========================================
+// PUBLIC
+// ========================================
+
+// GetCategories повертає активні розділи з кількістю опублікованих статей
+func (h *FAQHandler) GetCategories(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categories, err := h.findCategories(ctx, communityScope(c, bson.M{"is_active": true}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching FAQ categories",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+	})
+}
+
+// GetArticles - GET /faq/articles?category_id=&q=&page=&limit=
+func (h *FAQHandler) GetArticles(c *gin.Context) {
+	h.listArticles(c, communityScope(c, bson.M{"is_published": true}))
+}
+
+// GetArticle повертає опубліковану статтю та враховує перегляд
+func (h *FAQHandler) GetArticle(c *gin.Context) {
+	articleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid article ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var article models.FAQArticle
+	err = h.articleCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": articleID, "is_published": true}),
+		bson.M{"$inc": bson.M{"view_count": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&article)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Article not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching article",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+// SubmitFeedback - відгук "корисно / не корисно" до статті.
+// Повторний відгук того ж користувача (або клієнта) замінює попередній.
+func (h *FAQHandler) SubmitFeedback(c *gin.Context) {
+	articleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid article ID",
+		})
+		return
+	}
+
+	var req FAQFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	voterKey := "ip:" + c.ClientIP()
+	if userID, err := getUserID(c); err == nil {
+		voterKey = "user:" + userID.Hex()
+	} else if req.ClientID != "" {
+		voterKey = "client:" + req.ClientID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.articleCollection.CountDocuments(ctx, communityScope(c, bson.M{
+		"_id":          articleID,
+		"is_published": true,
+	}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Article not found",
+		})
+		return
+	}
+
+	now := time.Now()
+	var previous models.FAQFeedback
+	err = h.feedbackCollection.FindOneAndUpdate(ctx,
+		bson.M{"article_id": articleID, "voter_key": voterKey},
+		bson.M{
+			"$set": bson.M{
+				"helpful":    *req.Helpful,
+				"comment":    req.Comment,
+				"updated_at": now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&previous)
+
+	inc := bson.M{}
+	switch {
+	case err == mongo.ErrNoDocuments:
+		// Перший відгук
+		inc[feedbackCounter(*req.Helpful)] = 1
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error saving feedback",
+		})
+		return
+	case previous.Helpful != *req.Helpful:
+		// Зміна оцінки
+		inc[feedbackCounter(*req.Helpful)] = 1
+		inc[feedbackCounter(previous.Helpful)] = -1
+	}
+
+	if len(inc) > 0 {
+		if _, err := h.articleCollection.UpdateOne(ctx,
+			bson.M{"_id": articleID},
+			bson.M{"$inc": inc},
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error updating feedback counters",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Thank you for your feedback",
+	})
+}
+
+func feedbackCounter(helpful bool) string {
+	if helpful {
+		return "helpful_count"
+	}
+	return "not_helpful_count"
+}
+
+// ========================================
+// MODERATOR
+// ========================================
+
+// GetAllCategories повертає всі розділи, включно з неактивними
+func (h *FAQHandler) GetAllCategories(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categories, err := h.findCategories(ctx, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching FAQ categories",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+	})
+}
+
+// CreateCategory створює розділ довідки
+func (h *FAQHandler) CreateCategory(c *gin.Context) {
+	var req FAQCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	category := models.FAQCategory{
+		CommunityID: getCommunityID(c),
+		Title:       req.Title,
+		Description: req.Description,
+		Icon:        req.Icon,
+		SortOrder:   req.SortOrder,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	result, err := h.categoryCollection.InsertOne(ctx, category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating FAQ category",
+		})
+		return
+	}
+	category.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory оновлює розділ довідки
+func (h *FAQHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid category ID",
+		})
+		return
+	}
+
+	var req FAQCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"title":       req.Title,
+		"description": req.Description,
+		"icon":        req.Icon,
+		"sort_order":  req.SortOrder,
+		"updated_at":  time.Now(),
+	}
+	if req.IsActive != nil {
+		update["is_active"] = *req.IsActive
+	}
+
+	var category models.FAQCategory
+	err = h.categoryCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": categoryID}),
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&category)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Category not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating FAQ category",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory видаляє порожній розділ довідки
+func (h *FAQHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid category ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	articles, err := h.articleCollection.CountDocuments(ctx, bson.M{"category_id": categoryID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if articles > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Category has articles. Move or delete them first.",
+			"article_count": articles,
+		})
+		return
+	}
+
+	result, err := h.categoryCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": categoryID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting FAQ category",
+		})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Category not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category deleted successfully",
+	})
+}
+
+// GetAllArticles повертає всі статті, включно з чернетками
+func (h *FAQHandler) GetAllArticles(c *gin.Context) {
+	filter := communityScope(c, bson.M{})
+	if published := c.Query("is_published"); published != "" {
+		filter["is_published"] = published == "true"
+	}
+	h.listArticles(c, filter)
+}
+
+// CreateArticle створює статтю бази знань
+func (h *FAQHandler) CreateArticle(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req FAQArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categoryID, ok := h.resolveCategory(ctx, c, req.CategoryID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	article := models.FAQArticle{
+		CommunityID: getCommunityID(c),
+		CategoryID:  categoryID,
+		Question:    req.Question,
+		Summary:     req.Summary,
+		Body:        req.Body,
+		BodyFormat:  req.BodyFormat,
+		Keywords:    normalizeFAQKeywords(req.Keywords),
+		SortOrder:   req.SortOrder,
+		IsPublished: req.IsPublished,
+		AuthorID:    userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if article.BodyFormat == "" {
+		article.BodyFormat = models.FAQBodyFormatMarkdown
+	}
+	if article.IsPublished {
+		article.PublishedAt = &now
+	}
+
+	result, err := h.articleCollection.InsertOne(ctx, article)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating article",
+		})
+		return
+	}
+	article.ID = result.InsertedID.(primitive.ObjectID)
+
+	c.JSON(http.StatusCreated, article)
+}
+
+// UpdateArticle оновлює статтю. Лічильники переглядів та відгуків зберігаються.
+func (h *FAQHandler) UpdateArticle(c *gin.Context) {
+	articleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid article ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req FAQArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categoryID, ok := h.resolveCategory(ctx, c, req.CategoryID)
+	if !ok {
+		return
+	}
+
+	bodyFormat := req.BodyFormat
+	if bodyFormat == "" {
+		bodyFormat = models.FAQBodyFormatMarkdown
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"category_id":  categoryID,
+		"question":     req.Question,
+		"summary":      req.Summary,
+		"body":         req.Body,
+		"body_format":  bodyFormat,
+		"keywords":     normalizeFAQKeywords(req.Keywords),
+		"sort_order":   req.SortOrder,
+		"is_published": req.IsPublished,
+		"updated_by":   userID,
+		"updated_at":   now,
+	}
+
+	var previous models.FAQArticle
+	err = h.articleCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": articleID}),
+		bson.M{"$set": update},
+	).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Article not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating article",
+		})
+		return
+	}
+
+	// Дата першої публікації
+	if req.IsPublished && previous.PublishedAt == nil {
+		h.articleCollection.UpdateOne(ctx,
+			bson.M{"_id": articleID},
+			bson.M{"$set": bson.M{"published_at": now}},
+		)
+	}
+
+	var article models.FAQArticle
+	if err := h.articleCollection.FindOne(ctx, bson.M{"_id": articleID}).Decode(&article); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching updated article",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, article)
+}
+
+// DeleteArticle видаляє статтю разом з відгуками
+func (h *FAQHandler) DeleteArticle(c *gin.Context) {
+	articleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid article ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.articleCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": articleID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting article",
+		})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Article not found",
+		})
+		return
+	}
+
+	h.feedbackCollection.DeleteMany(ctx, bson.M{"article_id": articleID})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Article deleted successfully",
+	})
+}
+
+// ========================================
+// HELPERS
+// ========================================
+
+func (h *FAQHandler) findCategories(ctx context.Context, filter bson.M) ([]models.FAQCategory, error) {
+	cursor, err := h.categoryCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "sort_order", Value: 1}, {Key: "title", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []models.FAQCategory{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return categories, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(categories))
+	for i := range categories {
+		ids[i] = categories[i].ID
+	}
+
+	countCursor, err := h.articleCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"category_id":  bson.M{"$in": ids},
+			"is_published": true,
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$category_id",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer countCursor.Close(ctx)
+
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := countCursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[primitive.ObjectID]int64, len(counts))
+	for _, count := range counts {
+		byCategory[count.ID] = count.Count
+	}
+	for i := range categories {
+		categories[i].ArticleCount = byCategory[categories[i].ID]
+	}
+
+	return categories, nil
+}
+
+func (h *FAQHandler) listArticles(c *gin.Context, filter bson.M) {
+	page, limit := pageParams(c, 20, 100)
+
+	if categoryParam := c.Query("category_id"); categoryParam != "" {
+		categoryID, err := primitive.ObjectIDFromHex(categoryParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid category ID",
+			})
+			return
+		}
+		filter["category_id"] = categoryID
+	}
+
+	// Пошук за питанням, текстом та ключовими словами
+	query := strings.TrimSpace(c.Query("q"))
+	if query != "" {
+		pattern := regexp.QuoteMeta(query)
+		filter["$or"] = []bson.M{
+			{"question": bson.M{"$regex": pattern, "$options": "i"}},
+			{"summary": bson.M{"$regex": pattern, "$options": "i"}},
+			{"body": bson.M{"$regex": pattern, "$options": "i"}},
+			{"keywords": strings.ToLower(query)},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// При пошуку спочатку найкорисніші статті, інакше - порядок, заданий модераторами
+	sort := bson.D{{Key: "sort_order", Value: 1}, {Key: "created_at", Value: -1}}
+	if query != "" {
+		sort = bson.D{{Key: "helpful_count", Value: -1}, {Key: "view_count", Value: -1}}
+	}
+
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.articleCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching articles",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	articles := []models.FAQArticle{}
+	if err := cursor.All(ctx, &articles); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding articles",
+		})
+		return
+	}
+
+	total, _ := h.articleCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, articles, info, nil, gin.H{"articles": articles, "pagination": info}))
+}
+
+// resolveCategory перевіряє, що розділ існує в поточній громаді
+func (h *FAQHandler) resolveCategory(ctx context.Context, c *gin.Context, categoryParam string) (primitive.ObjectID, bool) {
+	categoryID, err := primitive.ObjectIDFromHex(categoryParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid category ID",
+		})
+		return primitive.NilObjectID, false
+	}
+
+	count, err := h.categoryCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": categoryID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return primitive.NilObjectID, false
+	}
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Category not found",
+		})
+		return primitive.NilObjectID, false
+	}
+
+	return categoryID, true
+}
+
+func normalizeFAQKeywords(keywords []string) []string {
+	result := make([]string, 0, len(keywords))
+	seen := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		result = append(result, keyword)
+	}
+	return result
+}
@@ -0,0 +1,442 @@
+// internal/handlers/feed.go
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	feedCacheTTL   = 5 * time.Minute
+	feedEntryLimit = 50
+	// Дата в tag: URI не змінюється, щоб GUID записів залишались стабільними
+	feedTagDate = "2024"
+)
+
+// Atom 1.0 (RFC 4287)
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published,omitempty"`
+	Links     []atomLink    `xml:"link"`
+	Author    *atomAuthor   `xml:"author,omitempty"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   *atomText     `xml:"summary,omitempty"`
+}
+
+type cachedFeed struct {
+	body      []byte
+	etag      string
+	updated   time.Time
+	expiresAt time.Time
+}
+
+// FeedHandler - Atom-стрічки проблем міста та петицій для журналістів і громадських організацій
+type FeedHandler struct {
+	cityIssueCollection *mongo.Collection
+	petitionCollection  *mongo.Collection
+	communityService    *services.CommunityService
+	publicURL           string
+
+	cacheMutex sync.Mutex
+	cache      map[string]cachedFeed
+}
+
+// NewFeedHandler створює обробник Atom-стрічок
+func NewFeedHandler(cfg *config.Config, cityIssueCollection, petitionCollection *mongo.Collection, communityService *services.CommunityService) *FeedHandler {
+	return &FeedHandler{
+		cityIssueCollection: cityIssueCollection,
+		petitionCollection:  petitionCollection,
+		communityService:    communityService,
+		publicURL:           cfg.PublicURL,
+		cache:               make(map[string]cachedFeed),
+	}
+}
+
+// IssuesFeed - GET /feeds/issues?category=road - нові проблеми міста
+func (h *FeedHandler) IssuesFeed(c *gin.Context) {
+	h.serve(c, "issues", func(ctx context.Context, feed *atomFeed, base, tag string) error {
+		category := c.Query("category")
+		feed.Title = h.feedTitle(c, "Проблемы города", category, models.GetCategoryTranslation)
+
+		filter := communityScope(c, bson.M{"is_public": bson.M{"$ne": false}})
+		if category != "" {
+			filter["category"] = category
+		}
+
+		cursor, err := h.cityIssueCollection.Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(feedEntryLimit).
+			SetProjection(bson.M{"comments": 0, "upvotes": 0, "subscribers": 0, "status_history": 0}))
+		if err != nil {
+			return err
+		}
+		var issues []models.CityIssue
+		if err := cursor.All(ctx, &issues); err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			link := fmt.Sprintf("%s/city-issues/%s", base, issue.ID.Hex())
+			feed.Entries = append(feed.Entries, atomEntry{
+				ID:        fmt.Sprintf("%s:issue/%s", tag, issue.ID.Hex()),
+				Title:     issue.Title,
+				Updated:   atomTime(issue.CreatedAt),
+				Published: atomTime(issue.CreatedAt),
+				Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+				Category: &atomCategory{
+					Term:  issue.Category,
+					Label: models.GetCategoryTranslation(issue.Category),
+				},
+				Summary: &atomText{
+					Type: "text",
+					Body: fmt.Sprintf("%s\n\n%s", issue.Description, issue.Address),
+				},
+			})
+		}
+		return nil
+	})
+}
+
+// IssueResponsesFeed - GET /feeds/issues/responses?category=road - офіційні відповіді міських служб
+func (h *FeedHandler) IssueResponsesFeed(c *gin.Context) {
+	h.serve(c, "issue-responses", func(ctx context.Context, feed *atomFeed, base, tag string) error {
+		category := c.Query("category")
+		feed.Title = h.feedTitle(c, "Официальные ответы по проблемам города", category, models.GetCategoryTranslation)
+
+		match := communityScope(c, bson.M{"is_public": bson.M{"$ne": false}})
+		if category != "" {
+			match["category"] = category
+		}
+
+		cursor, err := h.cityIssueCollection.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$unwind", Value: "$comments"}},
+			{{Key: "$match", Value: bson.M{"comments.is_official": true}}},
+			{{Key: "$sort", Value: bson.D{{Key: "comments.created_at", Value: -1}}}},
+			{{Key: "$limit", Value: feedEntryLimit}},
+			{{Key: "$project", Value: bson.M{
+				"title":    1,
+				"category": 1,
+				"status":   1,
+				"comment":  "$comments",
+			}}},
+		})
+		if err != nil {
+			return err
+		}
+
+		var responses []struct {
+			ID       primitive.ObjectID  `bson:"_id"`
+			Title    string              `bson:"title"`
+			Category string              `bson:"category"`
+			Status   string              `bson:"status"`
+			Comment  models.IssueComment `bson:"comment"`
+		}
+		if err := cursor.All(ctx, &responses); err != nil {
+			return err
+		}
+
+		for _, response := range responses {
+			issueID := response.ID.Hex()
+			link := fmt.Sprintf("%s/city-issues/%s", base, issueID)
+			feed.Entries = append(feed.Entries, atomEntry{
+				ID:        fmt.Sprintf("%s:issue/%s/response/%s", tag, issueID, response.Comment.ID.Hex()),
+				Title:     "Ответ: " + response.Title,
+				Updated:   atomTime(response.Comment.UpdatedAt, response.Comment.CreatedAt),
+				Published: atomTime(response.Comment.CreatedAt),
+				Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+				Category: &atomCategory{
+					Term:  response.Category,
+					Label: models.GetCategoryTranslation(response.Category),
+				},
+				Summary: &atomText{
+					Type: "text",
+					Body: fmt.Sprintf("%s\n\nСтатус: %s", response.Comment.Content, models.GetStatusTranslation(response.Status)),
+				},
+			})
+		}
+		return nil
+	})
+}
+
+// PetitionsFeed - GET /feeds/petitions?category=transport - нові петиції
+func (h *FeedHandler) PetitionsFeed(c *gin.Context) {
+	h.serve(c, "petitions", func(ctx context.Context, feed *atomFeed, base, tag string) error {
+		category := c.Query("category")
+		feed.Title = h.feedTitle(c, "Петиции", category, models.GetPetitionCategoryTranslation)
+
+		filter := communityScope(c, bson.M{"status": bson.M{"$ne": models.PetitionStatusDraft}})
+		if category != "" {
+			filter["category"] = category
+		}
+
+		cursor, err := h.petitionCollection.Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "start_date", Value: -1}}).
+			SetLimit(feedEntryLimit).
+			SetProjection(bson.M{"signatures": 0}))
+		if err != nil {
+			return err
+		}
+		var petitions []models.Petition
+		if err := cursor.All(ctx, &petitions); err != nil {
+			return err
+		}
+
+		for _, petition := range petitions {
+			link := fmt.Sprintf("%s/petitions/%s", base, petition.ID.Hex())
+			feed.Entries = append(feed.Entries, atomEntry{
+				ID:        fmt.Sprintf("%s:petition/%s", tag, petition.ID.Hex()),
+				Title:     petition.Title,
+				Updated:   atomTime(petition.StartDate, petition.CreatedAt),
+				Published: atomTime(petition.StartDate, petition.CreatedAt),
+				Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+				Category: &atomCategory{
+					Term:  petition.Category,
+					Label: models.GetPetitionCategoryTranslation(petition.Category),
+				},
+				Summary: &atomText{
+					Type: "text",
+					Body: fmt.Sprintf("%s\n\nТребования: %s\nНеобходимо подписей: %d",
+						petition.Description, petition.Demands, petition.RequiredSignatures),
+				},
+			})
+		}
+		return nil
+	})
+}
+
+// PetitionResponsesFeed - GET /feeds/petitions/responses?category=transport - офіційні відповіді на петиції
+func (h *FeedHandler) PetitionResponsesFeed(c *gin.Context) {
+	h.serve(c, "petition-responses", func(ctx context.Context, feed *atomFeed, base, tag string) error {
+		category := c.Query("category")
+		feed.Title = h.feedTitle(c, "Официальные ответы на петиции", category, models.GetPetitionCategoryTranslation)
+
+		filter := communityScope(c, bson.M{"official_response": bson.M{"$exists": true}})
+		if category != "" {
+			filter["category"] = category
+		}
+
+		cursor, err := h.petitionCollection.Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "official_response.responded_at", Value: -1}}).
+			SetLimit(feedEntryLimit).
+			SetProjection(bson.M{"signatures": 0}))
+		if err != nil {
+			return err
+		}
+		var petitions []models.Petition
+		if err := cursor.All(ctx, &petitions); err != nil {
+			return err
+		}
+
+		for _, petition := range petitions {
+			response := petition.OfficialResponse
+			link := fmt.Sprintf("%s/petitions/%s", base, petition.ID.Hex())
+			feed.Entries = append(feed.Entries, atomEntry{
+				// Одна офіційна відповідь на петицію, тому GUID прив'язаний до петиції
+				ID:        fmt.Sprintf("%s:petition/%s/response", tag, petition.ID.Hex()),
+				Title:     "Ответ: " + petition.Title,
+				Updated:   atomTime(response.RespondedAt),
+				Published: atomTime(response.RespondedAt),
+				Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+				Author:    &atomAuthor{Name: response.ResponderName + ", " + response.Position},
+				Category: &atomCategory{
+					Term:  petition.Category,
+					Label: models.GetPetitionCategoryTranslation(petition.Category),
+				},
+				Summary: &atomText{
+					Type: "text",
+					Body: fmt.Sprintf("Решение: %s\n\n%s", response.Decision, response.Response),
+				},
+			})
+		}
+		return nil
+	})
+}
+
+type feedBuilder func(ctx context.Context, feed *atomFeed, base, tag string) error
+
+// serve віддає стрічку з кешу або будує нову. Підтримує If-None-Match для зменшення трафіку агрегаторів.
+func (h *FeedHandler) serve(c *gin.Context, name string, build feedBuilder) {
+	cacheKey := fmt.Sprintf("%s|%s|%s", c.GetString("community_code"), name, c.Query("category"))
+
+	h.cacheMutex.Lock()
+	cached, ok := h.cache[cacheKey]
+	h.cacheMutex.Unlock()
+
+	if !ok || time.Now().After(cached.expiresAt) {
+		base, tag := h.feedBase(c)
+
+		feed := atomFeed{
+			Author: atomAuthor{Name: h.communityName(c)},
+		}
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		selfURL := fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, c.Request.URL.Path)
+		if category := c.Query("category"); category != "" {
+			selfURL += "?category=" + url.QueryEscape(category)
+		}
+		feed.ID = fmt.Sprintf("%s:feed/%s", tag, name)
+		if category := c.Query("category"); category != "" {
+			feed.ID += "/" + category
+		}
+		feed.Links = []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: base, Rel: "alternate", Type: "text/html"},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := build(ctx, &feed, base, tag)
+		cancel()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error building feed",
+			})
+			return
+		}
+
+		// updated стрічки - час найновішого запису
+		updated := time.Unix(0, 0).UTC()
+		for _, entry := range feed.Entries {
+			if t, err := time.Parse(time.RFC3339, entry.Updated); err == nil && t.After(updated) {
+				updated = t
+			}
+		}
+		feed.Updated = atomTime(updated)
+
+		body, err := xml.MarshalIndent(feed, "", "  ")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error encoding feed",
+			})
+			return
+		}
+		body = append([]byte(xml.Header), body...)
+
+		sum := sha1.Sum(body)
+		cached = cachedFeed{
+			body:      body,
+			etag:      `"` + hex.EncodeToString(sum[:]) + `"`,
+			updated:   updated,
+			expiresAt: time.Now().Add(feedCacheTTL),
+		}
+
+		h.cacheMutex.Lock()
+		// Категорія надходить із запиту, тому обмежуємо розмір кешу
+		if len(h.cache) > 1000 {
+			h.cache = make(map[string]cachedFeed)
+		}
+		h.cache[cacheKey] = cached
+		h.cacheMutex.Unlock()
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedCacheTTL.Seconds())))
+	c.Header("ETag", cached.etag)
+	c.Header("Last-Modified", cached.updated.Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") == cached.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", cached.body)
+}
+
+// feedBase повертає адресу вебзастосунку громади та префікс tag: URI для GUID
+func (h *FeedHandler) feedBase(c *gin.Context) (string, string) {
+	base := h.publicURL
+	if community, ok := h.communityService.GetByCode(c.GetString("community_code")); ok {
+		if website := community.Settings.Contact.Website; website != "" {
+			base = website
+		}
+	}
+	for len(base) > 0 && base[len(base)-1] == '/' {
+		base = base[:len(base)-1]
+	}
+
+	host := base
+	if parsed, err := url.Parse(base); err == nil && parsed.Host != "" {
+		host = parsed.Hostname()
+	}
+	return base, fmt.Sprintf("tag:%s,%s", host, feedTagDate)
+}
+
+func (h *FeedHandler) communityName(c *gin.Context) string {
+	if community, ok := h.communityService.GetByCode(c.GetString("community_code")); ok {
+		if community.Settings.DisplayName != "" {
+			return community.Settings.DisplayName
+		}
+		return community.Name
+	}
+	return "eCity"
+}
+
+func (h *FeedHandler) feedTitle(c *gin.Context, title, category string, translate func(string) string) string {
+	title = h.communityName(c) + " - " + title
+	if category != "" {
+		title += ": " + translate(category)
+	}
+	return title
+}
+
+// atomTime форматує першу ненульову дату у форматі RFC 3339
+func atomTime(times ...time.Time) string {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return time.Unix(0, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,187 @@
+// internal/handlers/gps.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Максимальний розмір тіла webhook від GPS-провайдера
+const gpsWebhookMaxBody = 5 << 20
+
+// GPSHandler - прийом позицій від зовнішніх GPS-провайдерів і налаштування джерела для транспорту
+type GPSHandler struct {
+	vehicleCollection *mongo.Collection
+	ingestionService  *services.GPSIngestionService
+}
+
+// NewGPSHandler створює обробник GPS-інтеграцій
+func NewGPSHandler(vehicleCollection *mongo.Collection, ingestionService *services.GPSIngestionService) *GPSHandler {
+	return &GPSHandler{
+		vehicleCollection: vehicleCollection,
+		ingestionService:  ingestionService,
+	}
+}
+
+type UpdateVehicleTrackingRequest struct {
+	Source     string `json:"source" binding:"required,oneof=driver_app provider"`
+	Provider   string `json:"provider"`
+	ExternalID string `json:"external_id" binding:"max=100"`
+}
+
+// ReceiveWebhook - POST /transport/gps/:provider/webhook
+// Автентифікація - підпис запиту, який перевіряє адаптер провайдера.
+func (h *GPSHandler) ReceiveWebhook(c *gin.Context) {
+	provider, ok := h.ingestionService.Provider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Unknown GPS provider",
+		})
+		return
+	}
+	webhookProvider, ok := provider.(services.GPSWebhookProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Provider does not accept webhooks",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, gpsWebhookMaxBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Error reading request body",
+		})
+		return
+	}
+
+	positions, err := webhookProvider.ParseWebhook(c.Request, body)
+	if err != nil {
+		if errors.Is(err, services.ErrGPSSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid signature",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stats := h.ingestionService.Ingest(ctx, provider.Name(), positions)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetProviders повертає підключені GPS-провайдери (ADMIN)
+func (h *GPSHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"providers": h.ingestionService.Providers(),
+	})
+}
+
+// UpdateVehicleTracking задає джерело позицій транспорту (ADMIN)
+func (h *GPSHandler) UpdateVehicleTracking(c *gin.Context) {
+	vehicleID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid vehicle ID",
+		})
+		return
+	}
+
+	var req UpdateVehicleTrackingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tracking := models.VehicleTracking{Source: req.Source}
+	if req.Source == models.TrackingSourceProvider {
+		if _, ok := h.ingestionService.Provider(req.Provider); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Unknown GPS provider",
+				"providers": h.ingestionService.Providers(),
+			})
+			return
+		}
+		if req.ExternalID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "external_id is required for provider tracking",
+			})
+			return
+		}
+		tracking.Provider = req.Provider
+		tracking.ExternalID = req.ExternalID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Один ID провайдера - один транспорт, інакше позиції потраплятимуть не туди
+	if tracking.Source == models.TrackingSourceProvider {
+		count, err := h.vehicleCollection.CountDocuments(ctx, bson.M{
+			"_id":                  bson.M{"$ne": vehicleID},
+			"tracking.provider":    tracking.Provider,
+			"tracking.external_id": tracking.ExternalID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Database error",
+			})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "External ID is already assigned to another vehicle",
+			})
+			return
+		}
+	}
+
+	var vehicle models.TransportVehicle
+	err = h.vehicleCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": vehicleID}),
+		bson.M{"$set": bson.M{
+			"tracking":   tracking,
+			"is_tracked": true,
+			"updated_at": time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&vehicle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vehicle not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating vehicle tracking",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, vehicle)
+}
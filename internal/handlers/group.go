@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,8 +24,37 @@ type GroupHandler struct {
 	groupCollection   *mongo.Collection
 	userCollection    *mongo.Collection
 	messageCollection *mongo.Collection
+	// Позиції читання учасників (group_read_states)
+	readStateCollection *mongo.Collection
+	// Заявки на вступ до груп за запрошенням (group_join_requests)
+	joinRequestCollection *mongo.Collection
+	// Посилання-запрошення (group_invites)
+	inviteCollection *mongo.Collection
+	wsHandler        *WebSocketHandler
+	chatLimiter      *services.ChatLimiter
+	trustService     *services.TrustService
+	attachments      *services.ChatAttachmentService
+	// Адреса веб-застосунку для посилань-запрошень
+	publicURL string
+	// Хто з учасників онлайн (GET /groups/:id/presence)
+	presence *services.PresenceService
+	// Фільтр заборонених слів у нових повідомленнях
+	chatFilter *services.ChatFilterService
 }
 
+// SetSlowModeRequest - налаштування slow mode групи
+type SetSlowModeRequest struct {
+	Seconds int `json:"seconds" binding:"min=0,max=3600"`
+}
+
+// SetAnnouncementsOnlyRequest - режим каналу оголошень
+type SetAnnouncementsOnlyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// Код відмови в каналі оголошень (REST і WebSocket)
+const chatErrorAnnouncementsOnly = "ANNOUNCEMENTS_ONLY"
+
 type CreateGroupRequest struct {
 	Name           string   `json:"name" validate:"required,min=3,max=100"`
 	Description    string   `json:"description" validate:"max=500"`
@@ -30,6 +64,9 @@ type CreateGroupRequest struct {
 	IsPublic       bool     `json:"is_public"`
 	AutoJoin       bool     `json:"auto_join"`
 	MaxMembers     int      `json:"max_members"`
+	InviteOnly     bool     `json:"invite_only"`
+	// Канал оголошень: писати можуть лише створювач і адміністратори
+	AnnouncementsOnly bool `json:"announcements_only"`
 }
 
 type SendMessageRequest struct {
@@ -37,13 +74,30 @@ type SendMessageRequest struct {
 	Type      string              `json:"type" validate:"required,oneof=text image video file link"`
 	MediaURL  string              `json:"media_url,omitempty"`
 	ReplyToID *primitive.ObjectID `json:"reply_to_id,omitempty"`
+	// ID файлів з POST /groups/:id/attachments
+	AttachmentIDs []string `json:"attachment_ids,omitempty"`
+}
+
+// UpdateMessageRequest - новий текст повідомлення
+type UpdateMessageRequest struct {
+	Content string `json:"content" binding:"required,max=1000"`
 }
 
-func NewGroupHandler(groupCollection, userCollection, messageCollection *mongo.Collection) *GroupHandler {
+func NewGroupHandler(groupCollection, userCollection, messageCollection, readStateCollection, joinRequestCollection, inviteCollection *mongo.Collection, wsHandler *WebSocketHandler, chatLimiter *services.ChatLimiter, trustService *services.TrustService, attachments *services.ChatAttachmentService, publicURL string, presence *services.PresenceService, chatFilter *services.ChatFilterService) *GroupHandler {
 	return &GroupHandler{
-		groupCollection:   groupCollection,
-		userCollection:    userCollection,
-		messageCollection: messageCollection,
+		groupCollection:       groupCollection,
+		userCollection:        userCollection,
+		messageCollection:     messageCollection,
+		readStateCollection:   readStateCollection,
+		joinRequestCollection: joinRequestCollection,
+		inviteCollection:      inviteCollection,
+		wsHandler:             wsHandler,
+		chatLimiter:           chatLimiter,
+		trustService:          trustService,
+		attachments:           attachments,
+		publicURL:             publicURL,
+		presence:              presence,
+		chatFilter:            chatFilter,
 	}
 }
 
@@ -68,20 +122,25 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 
 	now := time.Now()
 	group := models.Group{
-		Name:           req.Name,
-		Description:    req.Description,
-		Type:           req.Type,
-		LocationFilter: req.LocationFilter,
-		InterestFilter: req.InterestFilter,
-		Members:        []primitive.ObjectID{userIDObj},
-		Admins:         []primitive.ObjectID{userIDObj},
-		Moderators:     []primitive.ObjectID{},
-		IsPublic:       req.IsPublic,
-		AutoJoin:       req.AutoJoin,
-		MaxMembers:     req.MaxMembers,
-		CreatedAt:      now,
-		UpdatedAt:      now,
-		CreatedBy:      userIDObj,
+		Name:              req.Name,
+		Description:       req.Description,
+		Type:              req.Type,
+		CreatorID:         userIDObj,
+		LocationFilter:    req.LocationFilter,
+		InterestFilter:    req.InterestFilter,
+		Members:           []primitive.ObjectID{userIDObj},
+		Admins:            []primitive.ObjectID{userIDObj},
+		Moderators:        []primitive.ObjectID{},
+		IsPublic:          req.IsPublic,
+		AutoJoin:          req.AutoJoin,
+		MaxMembers:        req.MaxMembers,
+		InviteOnly:        req.InviteOnly,
+		AnnouncementsOnly: req.AnnouncementsOnly,
+		MemberCount:       1,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		CreatedBy:         userIDObj,
+		CommunityID:       getCommunityID(c),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -143,7 +202,16 @@ func (h *GroupHandler) GetUserGroups(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, groups)
+	// Непрочитанные сообщения для бейджей в списке групп
+	views, err := h.groupViews(ctx, userIDObj, groups)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting unread messages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, views)
 }
 
 func (h *GroupHandler) GetPublicGroups(c *gin.Context) {
@@ -151,16 +219,15 @@ func (h *GroupHandler) GetPublicGroups(c *gin.Context) {
 	defer cancel()
 
 	// Параметры пагинации
-	page := 1
-	limit := 20
-	skip := (page - 1) * limit
+	page, limit := pageParams(c, 20, 50)
+	filter := communityScope(c, bson.M{"is_public": true})
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
+		SetSkip(pageSkip(page, limit)).
 		SetSort(bson.D{{"created_at", -1}})
 
-	cursor, err := h.groupCollection.Find(ctx, bson.M{"is_public": true}, opts)
+	cursor, err := h.groupCollection.Find(ctx, filter, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error fetching groups",
@@ -177,7 +244,13 @@ func (h *GroupHandler) GetPublicGroups(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, groups)
+	// Раньше endpoint возвращал массив без метаданных
+	if middleware.LegacyPagination(c) {
+		c.JSON(http.StatusOK, groups)
+		return
+	}
+	total, _ := h.groupCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, groups, newPageInfo(page, limit, total), nil, nil))
 }
 
 func (h *GroupHandler) JoinGroup(c *gin.Context) {
@@ -204,7 +277,7 @@ func (h *GroupHandler) JoinGroup(c *gin.Context) {
 
 	// Проверяем существование группы и её настройки
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupIDObj}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupIDObj})).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -228,6 +301,14 @@ func (h *GroupHandler) JoinGroup(c *gin.Context) {
 		}
 	}
 
+	// Заблокированный в группе не может вступить или подать заявку
+	if group.IsBanned(userIDObj) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are banned in this group",
+		})
+		return
+	}
+
 	// Проверяем лимит участников
 	if group.MaxMembers > 0 && len(group.Members) >= group.MaxMembers {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -236,28 +317,25 @@ func (h *GroupHandler) JoinGroup(c *gin.Context) {
 		return
 	}
 
-	now := time.Now()
+	// В группу по приглашению вступают через заявку, которую одобряет администратор
+	if group.InviteOnly {
+		h.requestJoin(ctx, c, &group, userIDObj)
+		return
+	}
 
 	// Добавляем пользователя в группу
-	_, err = h.groupCollection.UpdateOne(ctx, bson.M{"_id": groupIDObj}, bson.M{
-		"$push": bson.M{"members": userIDObj},
-		"$set":  bson.M{"updated_at": now},
-	})
+	joined, err := h.addMember(ctx, &group, userIDObj, time.Now())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error joining group",
 		})
 		return
 	}
-
-	// Добавляем группу в список групп пользователя
-	_, err = h.userCollection.UpdateOne(ctx, bson.M{"_id": userIDObj}, bson.M{
-		"$push": bson.M{"groups": groupIDObj},
-		"$set":  bson.M{"updated_at": now},
-	})
-	if err != nil {
-		// Логируем ошибку, но не отменяем присоединение к группе
-		// log.Printf("Error adding group to user: %v", err)
+	if !joined {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Unable to join group",
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -284,6 +362,27 @@ func (h *GroupHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	attachmentIDs, ok := parseAttachmentIDs(req.AttachmentIDs)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid attachment ID",
+		})
+		return
+	}
+	if len(attachmentIDs) > models.MaxMessageAttachments {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":           "Too many attachments",
+			"max_attachments": models.MaxMessageAttachments,
+		})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" && len(attachmentIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Message content or attachments are required",
+		})
+		return
+	}
+
 	userID, _ := c.Get("user_id")
 	userIDObj, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
@@ -298,10 +397,10 @@ func (h *GroupHandler) SendMessage(c *gin.Context) {
 
 	// Проверяем, является ли пользователь участником группы
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{
 		"_id":     groupIDObj,
 		"members": bson.M{"$in": []primitive.ObjectID{userIDObj}},
-	}).Decode(&group)
+	})).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusForbidden, gin.H{
@@ -315,8 +414,23 @@ func (h *GroupHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	if !group.CanSendMessages(userIDObj) {
+		c.JSON(http.StatusForbidden, announcementsOnlyPayload())
+		return
+	}
+
+	// Ліміт частоти повідомлень та slow mode групи (жорсткіший для акаунтів з низькою довірою)
+	quota := h.trustService.Quota(ctx, userIDObj)
+	if h.chatLimiter != nil {
+		if err := h.chatLimiter.Allow(userIDObj, groupIDObj, chatSlowMode(&group, userIDObj, quota)); err != nil {
+			respondChatLimited(c, err)
+			return
+		}
+	}
+
 	now := time.Now()
 	message := models.Message{
+		ID:        primitive.NewObjectID(),
 		GroupID:   groupIDObj,
 		UserID:    userIDObj,
 		Content:   req.Content,
@@ -325,19 +439,55 @@ func (h *GroupHandler) SendMessage(c *gin.Context) {
 		ReplyToID: req.ReplyToID,
 		IsEdited:  false,
 		IsDeleted: false,
+		IsHeld:    quota.HoldLinks && models.ContainsLink(req.Content),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	applyChatFilter(ctx, h.chatFilter, &message)
 
-	result, err := h.messageCollection.InsertOne(ctx, message)
-	if err != nil {
+	// Кожне завантаження прикріплюється лише до одного повідомлення свого автора в цій групі
+	if len(attachmentIDs) > 0 {
+		message.Attachments, err = h.attachments.Claim(ctx, groupIDObj, userIDObj, message.ID, attachmentIDs)
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Attachment not found",
+				"details": "Upload files to this group first; each upload can be sent once",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error attaching files",
+			})
+			return
+		}
+		if message.Type == "" || message.Type == models.MessageTypeText {
+			message.Type = messageTypeForAttachments(message.Attachments)
+		}
+	}
+
+	if _, err := h.messageCollection.InsertOne(ctx, message); err != nil {
+		if len(attachmentIDs) > 0 {
+			h.attachments.Release(ctx, message.ID)
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error sending message",
 		})
 		return
 	}
 
-	message.ID = result.InsertedID.(primitive.ObjectID)
+	h.attachments.Sign(message.Attachments)
+
+	// Повідомлення з посиланнями або забороненими словами утримується до перевірки модератором
+	if message.IsHeld {
+		c.JSON(http.StatusAccepted, message)
+		return
+	}
+
+	// Повідомлення через REST отримують і WebSocket, і long-poll клієнти групи
+	if h.wsHandler != nil {
+		h.wsHandler.BroadcastMessage(&message)
+	}
 
 	c.JSON(http.StatusCreated, message)
 }
@@ -365,10 +515,10 @@ func (h *GroupHandler) GetMessages(c *gin.Context) {
 	defer cancel()
 
 	// Проверяем, является ли пользователь участником группы
-	count, err := h.groupCollection.CountDocuments(ctx, bson.M{
+	count, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{
 		"_id":     groupIDObj,
 		"members": bson.M{"$in": []primitive.ObjectID{userIDObj}},
-	})
+	}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Database error",
@@ -382,20 +532,102 @@ func (h *GroupHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	// Параметры пагинации
-	page := 1
-	limit := 50
-	skip := (page - 1) * limit
+	before, after, ok := cursorParams(c)
+	if !ok {
+		return
+	}
+	_, limit := pageParams(c, 50, 100)
+
+	// Утримані повідомлення бачить лише автор
+	filter := bson.M{
+		"group_id":   groupIDObj,
+		"is_deleted": false,
+		"$or": []bson.M{
+			{"is_held": bson.M{"$ne": true}},
+			{"user_id": userIDObj},
+		},
+	}
+
+	// page залишено для старих версій застосунку: під час активного чату нові повідомлення
+	// зсувають сторінки, і частина історії повторюється або пропадає
+	if before == nil && after == nil && c.Query("page") != "" {
+		h.getMessagesPage(ctx, c, filter)
+		return
+	}
+
+	// Сторінка за курсором: порядок за _id, тому повідомлення з однаковим часом не
+	// переставляються. Без курсора - останні повідомлення, before - старші, after - новіші.
+	// Зайвий документ показує, чи є ще повідомлення за межею сторінки.
+	sortOrder := -1
+	switch {
+	case before != nil:
+		filter["_id"] = bson.M{"$lt": *before}
+	case after != nil:
+		filter["_id"] = bson.M{"$gt": *after}
+		sortOrder = 1
+	}
+	opts := options.Find().
+		SetLimit(int64(limit + 1)).
+		SetSort(bson.D{{Key: "_id", Value: sortOrder}})
+
+	cursor, err := h.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching messages",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding messages",
+		})
+		return
+	}
+
+	more := len(messages) > limit
+	if more {
+		messages = messages[:limit]
+	}
+	info := CursorInfo{Limit: limit}
+	if after != nil {
+		info.HasOlder, info.HasNewer = true, more
+	} else {
+		info.HasOlder, info.HasNewer = more, before != nil
+		// Реверсируем массив, чтобы показать сообщения в хронологическом порядке
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	if len(messages) > 0 {
+		info.Before = messages[0].ID.Hex()
+		info.After = messages[len(messages)-1].ID.Hex()
+	}
+	h.attachments.SignMessages(messages)
+
+	if middleware.LegacyPagination(c) {
+		c.JSON(http.StatusOK, messages)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":       messages,
+		"cursor_info": info,
+	})
+}
+
+// getMessagesPage - історія групи за номером сторінки (?page=), як до появи курсорів;
+// сторінка 1 - останні повідомлення
+func (h *GroupHandler) getMessagesPage(ctx context.Context, c *gin.Context, filter bson.M) {
+	page, limit := pageParams(c, 50, 100)
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
+		SetSkip(pageSkip(page, limit)).
 		SetSort(bson.D{{"created_at", -1}})
 
-	cursor, err := h.messageCollection.Find(ctx, bson.M{
-		"group_id":   groupIDObj,
-		"is_deleted": false,
-	}, opts)
+	cursor, err := h.messageCollection.Find(ctx, filter, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error fetching messages",
@@ -416,8 +648,315 @@ func (h *GroupHandler) GetMessages(c *gin.Context) {
 	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
 		messages[i], messages[j] = messages[j], messages[i]
 	}
+	h.attachments.SignMessages(messages)
 
-	c.JSON(http.StatusOK, messages)
+	if middleware.LegacyPagination(c) {
+		c.JSON(http.StatusOK, messages)
+		return
+	}
+	total, _ := h.messageCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, messages, newPageInfo(page, limit, total), nil, nil))
+}
+
+// UpdateMessage редагує текст власного повідомлення протягом models.MessageEditWindow.
+// Попередня версія зберігається в edit_history, учасники групи отримують message_updated.
+// Метод: PUT /api/v1/groups/:id/messages/:msgId
+func (h *GroupHandler) UpdateMessage(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	messageID, err := primitive.ObjectIDFromHex(c.Param("msgId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	var req UpdateMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Message content is required",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Редагувати можна лише в групі, учасником якої користувач залишається
+	isMember, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": groupID, "members": userID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if isMember == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	var message models.Message
+	err = h.messageCollection.FindOne(ctx, bson.M{"_id": messageID, "group_id": groupID, "is_deleted": false}).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching message",
+		})
+		return
+	}
+
+	if !message.IsFromUser(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the author can edit the message",
+		})
+		return
+	}
+	if message.IsHeld {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Message is awaiting moderation",
+		})
+		return
+	}
+	if !message.CanBeEditedBy(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Edit window has expired",
+			"details": "Messages can be edited within " + models.MessageEditWindow.String() + " after sending",
+		})
+		return
+	}
+	if message.Content == content {
+		h.attachments.Sign(message.Attachments)
+		c.JSON(http.StatusOK, message)
+		return
+	}
+
+	// Посилання від акаунтів з низькою довірою перевіряє модератор: правка не повинна це обходити
+	quota := h.trustService.Quota(ctx, userID)
+	if quota.HoldLinks && models.ContainsLink(content) && !models.ContainsLink(message.Content) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Links can't be added by editing",
+			"details": "Send a new message with the link, it will be reviewed by a moderator",
+		})
+		return
+	}
+
+	// Правка не повинна обходити фільтр заборонених слів: слово, яке утримує повідомлення,
+	// додати правкою не можна, а позначене - відправляє повідомлення до черги модератора
+	var filtered services.ChatFilterResult
+	if h.chatFilter != nil {
+		filtered = h.chatFilter.Check(ctx, content)
+	}
+	if filtered.Action == models.BannedWordHide {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Message contains words that are not allowed",
+			"details": "Remove the words blocked by the chat filter",
+		})
+		return
+	}
+
+	// Фільтр за поточним текстом: паралельна правка не загубить версію в історії
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"content":    content,
+			"is_edited":  true,
+			"edited_at":  now,
+			"updated_at": now,
+		},
+		"$push": bson.M{"edit_history": bson.M{
+			"$each":  []models.MessageEdit{{Content: message.Content, EditedAt: now}},
+			"$slice": -models.MaxMessageEditHistory,
+		}},
+	}
+	if filtered.Matched() {
+		update["$addToSet"] = bson.M{
+			"review.reasons":       models.MessageReviewBannedWord,
+			"review.matched_words": bson.M{"$each": filtered.Matches},
+		}
+		update["$min"] = bson.M{"review.flagged_at": now}
+	}
+
+	var updated models.Message
+	err = h.messageCollection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":        messageID,
+			"user_id":    userID,
+			"is_deleted": false,
+			"is_held":    bson.M{"$ne": true},
+			"content":    message.Content,
+		},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Message was changed concurrently, reload and try again",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating message",
+		})
+		return
+	}
+
+	h.attachments.Sign(updated.Attachments)
+	if h.wsHandler != nil {
+		h.wsHandler.SendSystemMessage(groupID, "message_updated", &updated)
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteMessage видаляє повідомлення: автор - своє, адміністратори й модератори групи та
+// модератори платформи - будь-яке. Текст та історія правок стираються, учасники групи
+// отримують message_deleted.
+// Метод: DELETE /api/v1/groups/:id/messages/:msgId
+func (h *GroupHandler) DeleteMessage(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	messageID, err := primitive.ObjectIDFromHex(c.Param("msgId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var message models.Message
+	err = h.messageCollection.FindOne(ctx, bson.M{"_id": messageID, "group_id": groupID, "is_deleted": false}).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching message",
+		})
+		return
+	}
+
+	isModerator := false
+	if !message.IsFromUser(userID) {
+		var group models.Group
+		if err := h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Group not found",
+			})
+			return
+		}
+		// Модератор платформы с зоной не модерирует чаты: у сообщений нет микрорайона
+		isModerator = group.IsOwner(userID) || group.IsAdmin(userID) || group.IsModerator(userID) ||
+			(middleware.UserHasPermission(c, models.PermissionModerateGroup) && moderationScope(c) == nil)
+	}
+	if !message.CanBeDeletedBy(userID, isModerator) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the author or group moderators can delete the message",
+		})
+		return
+	}
+
+	now := time.Now()
+	result, err := h.messageCollection.UpdateOne(ctx,
+		bson.M{"_id": messageID, "is_deleted": false},
+		bson.M{
+			"$set": bson.M{
+				"is_deleted": true,
+				"content":    "",
+				"deleted_at": now,
+				"deleted_by": userID,
+				"updated_at": now,
+			},
+			"$unset": bson.M{"media_url": "", "media_type": "", "media_size": "", "edit_history": "", "attachments": ""},
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting message",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Message not found",
+		})
+		return
+	}
+
+	if len(message.Attachments) > 0 {
+		if _, err := h.attachments.RemoveForMessage(ctx, messageID); err != nil {
+			log.Printf("Error removing attachments of message %s: %v", messageID.Hex(), err)
+		}
+	}
+
+	// Видалення модератором знижує довіру до автора, як і відхилення утриманого повідомлення
+	if isModerator {
+		if err := h.trustService.RecordRemoval(ctx, message.UserID); err != nil {
+			log.Printf("Error recording removal of message %s: %v", messageID.Hex(), err)
+		}
+	}
+
+	// Утримане повідомлення бачив лише автор
+	if h.wsHandler != nil && !message.IsHeld {
+		h.wsHandler.SendSystemMessage(groupID, "message_deleted", gin.H{
+			"id":         messageID,
+			"group_id":   groupID,
+			"deleted_by": userID,
+			"deleted_at": now,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message deleted",
+	})
 }
 
 // GetGroup повертає детальну інформацію про групу
@@ -434,7 +973,7 @@ func (h *GroupHandler) GetGroup(c *gin.Context) {
 	defer cancel()
 
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -492,6 +1031,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		Name        string `json:"name,omitempty"`
 		Description string `json:"description,omitempty"`
 		IsPublic    *bool  `json:"is_public,omitempty"`
+		InviteOnly  *bool  `json:"invite_only,omitempty"`
 	}
 
 	var req UpdateGroupRequest
@@ -507,7 +1047,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 
 	// Перевіряємо чи користувач є адміном групи
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Group not found",
@@ -524,7 +1064,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	if group.CreatorID != userIDObj {
+	if !group.IsOwner(userIDObj) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Only group creator can update the group",
 		})
@@ -545,10 +1085,13 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 	if req.IsPublic != nil {
 		update["is_public"] = *req.IsPublic
 	}
+	if req.InviteOnly != nil {
+		update["invite_only"] = *req.InviteOnly
+	}
 
 	_, err = h.groupCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": groupID},
+		communityScope(c, bson.M{"_id": groupID}),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -578,7 +1121,7 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 
 	// Перевіряємо права
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Group not found",
@@ -595,7 +1138,7 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	if group.CreatorID != userIDObj {
+	if !group.IsOwner(userIDObj) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Only group creator can delete the group",
 		})
@@ -603,7 +1146,7 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 	}
 
 	// Видаляємо групу
-	_, err = h.groupCollection.DeleteOne(ctx, bson.M{"_id": groupID})
+	_, err = h.groupCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": groupID}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error deleting group",
@@ -611,8 +1154,14 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	// Видаляємо всі повідомлення групи
+	// Видаляємо всі повідомлення групи, їх вкладення, позиції читання та заявки на вступ
 	h.messageCollection.DeleteMany(ctx, bson.M{"group_id": groupID})
+	h.readStateCollection.DeleteMany(ctx, bson.M{"group_id": groupID})
+	h.joinRequestCollection.DeleteMany(ctx, bson.M{"group_id": groupID})
+	h.inviteCollection.DeleteMany(ctx, bson.M{"group_id": groupID})
+	if _, err := h.attachments.RemoveForGroup(ctx, groupID); err != nil {
+		log.Printf("Error removing attachments of group %s: %v", groupID.Hex(), err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Group deleted successfully",
@@ -643,7 +1192,7 @@ func (h *GroupHandler) LeaveGroup(c *gin.Context) {
 
 	// Перевіряємо чи користувач є членом групи
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Group not found",
@@ -652,24 +1201,15 @@ func (h *GroupHandler) LeaveGroup(c *gin.Context) {
 	}
 
 	// Творець групи не може її покинути
-	if group.CreatorID == userIDObj {
+	if group.IsOwner(userIDObj) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Group creator cannot leave the group",
 		})
 		return
 	}
 
-	// Видаляємо користувача зі списку членів
-	_, err = h.groupCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": groupID},
-		bson.M{
-			"$pull": bson.M{"members": userIDObj},
-			"$inc":  bson.M{"member_count": -1},
-			"$set":  bson.M{"updated_at": time.Now()},
-		},
-	)
-	if err != nil {
+	// Видаляємо користувача зі списку членів разом з його роллю
+	if _, err := h.removeMember(ctx, groupID, userIDObj, nil); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error leaving group",
 		})
@@ -694,9 +1234,9 @@ func (h *GroupHandler) SearchGroups(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
+	filter := communityScope(c, bson.M{
 		"is_public": true,
-	}
+	})
 
 	// Текстовый поиск по названию и описанию
 	if query != "" {
@@ -753,7 +1293,7 @@ func (h *GroupHandler) GetGroupStats(c *gin.Context) {
 
 	// Проверяем существование группы
 	var group models.Group
-	err = h.groupCollection.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+	err = h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -780,3 +1320,178 @@ func (h *GroupHandler) GetGroupStats(c *gin.Context) {
 		"is_public":     group.IsPublic,
 	})
 }
+
+// SetSlowMode вмикає або вимикає slow mode групи (0 - вимкнено)
+// 🔒 Тільки створювач або адміністратори групи
+func (h *GroupHandler) SetSlowMode(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	var req SetSlowModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var group models.Group
+	if err := h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Group not found",
+		})
+		return
+	}
+
+	if !group.IsOwner(userID) && !group.IsAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group admins can change slow mode",
+		})
+		return
+	}
+
+	_, err = h.groupCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": groupID}), bson.M{
+		"$set": bson.M{
+			"slow_mode_seconds": req.Seconds,
+			"updated_at":        time.Now(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating slow mode",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Slow mode updated",
+		"slow_mode_seconds": req.Seconds,
+	})
+}
+
+// SetAnnouncementsOnly вмикає або вимикає режим каналу оголошень: учасники читають,
+// пишуть лише створювач і адміністратори. Учасники групи отримують group_settings_changed.
+// 🔒 Тільки створювач або адміністратори групи
+func (h *GroupHandler) SetAnnouncementsOnly(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	var req SetAnnouncementsOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsOwner(userID) && !group.IsAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group admins can change the announcements mode",
+		})
+		return
+	}
+
+	_, err = h.groupCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": groupID}), bson.M{
+		"$set": bson.M{
+			"announcements_only": *req.Enabled,
+			"updated_at":         time.Now(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating announcements mode",
+		})
+		return
+	}
+
+	// Клієнти ховають або показують поле введення без перезавантаження групи
+	if h.wsHandler != nil && group.AnnouncementsOnly != *req.Enabled {
+		h.wsHandler.SendSystemMessage(groupID, "group_settings_changed", gin.H{
+			"group_id":           groupID,
+			"announcements_only": *req.Enabled,
+			"changed_by":         userID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Announcements mode updated",
+		"announcements_only": *req.Enabled,
+	})
+}
+
+// announcementsOnlyPayload - тіло відмови учаснику каналу оголошень (спільне для REST та WebSocket)
+func announcementsOnlyPayload() gin.H {
+	return gin.H{
+		"error":   "Announcements channel",
+		"code":    chatErrorAnnouncementsOnly,
+		"details": "Only group admins can post in this group",
+	}
+}
+
+// chatLimitPayload формує тіло помилки ліміту чату (спільне для REST та WebSocket)
+func chatLimitPayload(limitErr *services.ChatLimitError) gin.H {
+	details := "Too many messages, please slow down"
+	if limitErr.Code == services.ChatLimitCodeSlowMode {
+		details = "Slow mode is enabled in this group"
+	}
+
+	return gin.H{
+		"error":               "Rate limit exceeded",
+		"code":                limitErr.Code,
+		"details":             details,
+		"retry_after_seconds": limitErr.RetryAfterSeconds(),
+		"retry_after":         limitErr.RetryAfter.Round(time.Second).String(),
+	}
+}
+
+func respondChatLimited(c *gin.Context, err error) {
+	var limitErr *services.ChatLimitError
+	if !errors.As(err, &limitErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error sending message",
+		})
+		return
+	}
+
+	c.Header("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+	c.JSON(http.StatusTooManyRequests, chatLimitPayload(limitErr))
+}
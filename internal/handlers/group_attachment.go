@@ -0,0 +1,156 @@
+// internal/handlers/group_attachment.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UploadAttachment - POST /groups/:id/attachments (multipart/form-data, поле "file")
+// Зберігає файл для наступного повідомлення: ID з відповіді передається в attachment_ids
+// SendMessage протягом models.ChatUploadTTL. Тип перевіряється за вмістом файлу.
+func (h *GroupHandler) UploadAttachment(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	maxBytes := h.attachments.MaxBytes()
+	// Запас на заголовки multipart понад розмір самого файлу
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+64*1024)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":     "File is too large",
+				"max_bytes": maxBytes,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "File is required",
+			"details": err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":     "File is too large",
+			"max_bytes": maxBytes,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Error reading file",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Error reading file",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !h.isGroupMember(ctx, c, groupID, userID) {
+		return
+	}
+
+	upload, err := h.attachments.Upload(ctx, groupID, userID, fileHeader.Filename, data)
+	var tooLarge *services.AttachmentTooLargeError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":     "File is too large",
+			"kind":      tooLarge.Kind,
+			"max_bytes": tooLarge.MaxBytes,
+		})
+		return
+	case errors.Is(err, services.ErrUnsupportedAttachment), errors.Is(err, services.ErrUnsupportedImage):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error":   "Unsupported file type",
+			"details": "Images (JPEG, PNG, GIF, WebP), MP4/WebM video, MP3/OGG/WAV audio, PDF, plain text and office documents are accepted",
+		})
+		return
+	case errors.Is(err, services.ErrImageDimensions):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Image is larger than 40 megapixels",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error saving attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attachments := []models.MessageAttachment{upload.MessageAttachment}
+	h.attachments.Sign(attachments)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"attachment": attachments[0],
+		"expires_at": upload.CreatedAt.Add(models.ChatUploadTTL),
+	})
+}
+
+// parseAttachmentIDs - ID вкладень повідомлення без повторів
+func parseAttachmentIDs(raw []string) ([]primitive.ObjectID, bool) {
+	ids := make([]primitive.ObjectID, 0, len(raw))
+	seen := make(map[primitive.ObjectID]bool, len(raw))
+	for _, value := range raw {
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// messageTypeForAttachments - тип повідомлення за першим вкладенням
+func messageTypeForAttachments(attachments []models.MessageAttachment) string {
+	switch attachments[0].Kind {
+	case models.MediaTypeImage:
+		return models.MessageTypeImage
+	case models.MediaTypeVideo:
+		return models.MessageTypeVideo
+	default:
+		return models.MessageTypeFile
+	}
+}
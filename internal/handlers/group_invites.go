@@ -0,0 +1,396 @@
+// internal/handlers/group_invites.go
+
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Код запрошення: без схожих символів (0/O, 1/I/L), щоб його можна було продиктувати
+const (
+	groupInviteAlphabet   = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	groupInviteCodeLength = 10
+)
+
+// CreateGroupInviteRequest - параметри посилання-запрошення
+type CreateGroupInviteRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"min=0,max=720"` // 0 - 7 днів
+	MaxUses        int `json:"max_uses" binding:"min=0,max=1000"`        // 0 - без обмеження
+}
+
+// JoinByInviteRequest - код з посилання-запрошення
+type JoinByInviteRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// generateInviteCode - випадковий код запрошення
+func generateInviteCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(groupInviteAlphabet)))
+	code := make([]byte, groupInviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = groupInviteAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// inviteURL - посилання для поширення в месенджерах
+func (h *GroupHandler) inviteURL(code string) string {
+	return h.publicURL + "/groups/invite/" + code
+}
+
+// groupManagerParams - ID групи з шляху та поточного користувача
+func groupManagerParams(c *gin.Context) (groupID, userID primitive.ObjectID, ok bool) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	userID, err = getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+	return groupID, userID, true
+}
+
+// CreateInvite - POST /groups/:id/invites
+// Створює посилання-запрошення з терміном дії та лімітом використань
+// (власник, адміністратори й модератори групи)
+func (h *GroupHandler) CreateInvite(c *gin.Context) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return
+	}
+
+	var req CreateGroupInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.CanInvite(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group moderators can create invites",
+		})
+		return
+	}
+
+	now := time.Now()
+	active, err := h.inviteCollection.CountDocuments(ctx, activeInviteFilter(groupID, now))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if active >= models.GroupInviteMaxActive {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Too many active invites. Revoke unused invites first.",
+			"limit": models.GroupInviteMaxActive,
+		})
+		return
+	}
+
+	ttl := models.GroupInviteDefaultTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating invite",
+		})
+		return
+	}
+	invite := models.GroupInvite{
+		ID:        primitive.NewObjectID(),
+		GroupID:   groupID,
+		Code:      code,
+		CreatedBy: userID,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if _, err := h.inviteCollection.InsertOne(ctx, invite); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating invite",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invite": invite,
+		"url":    h.inviteURL(invite.Code),
+	})
+}
+
+// activeInviteFilter - не відкликані й не прострочені запрошення групи
+func activeInviteFilter(groupID primitive.ObjectID, now time.Time) bson.M {
+	return bson.M{
+		"group_id":   groupID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+}
+
+// GetInvites - GET /groups/:id/invites
+// Діючі запрошення групи з кількістю використань, нові першими
+func (h *GroupHandler) GetInvites(c *gin.Context) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.CanInvite(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group moderators can view invites",
+		})
+		return
+	}
+
+	now := time.Now()
+	cursor, err := h.inviteCollection.Find(ctx, activeInviteFilter(groupID, now),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching invites",
+		})
+		return
+	}
+	var invites []models.GroupInvite
+	err = cursor.All(ctx, &invites)
+	cursor.Close(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding invites",
+		})
+		return
+	}
+
+	// Вичерпані запрошення не показуємо: ними вже не вступити
+	items := []gin.H{}
+	for i := range invites {
+		if !invites[i].IsUsable(now) {
+			continue
+		}
+		items = append(items, gin.H{
+			"invite": invites[i],
+			"url":    h.inviteURL(invites[i].Code),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invites": items,
+		"count":   len(items),
+	})
+}
+
+// RevokeInvite - DELETE /groups/:id/invites/:inviteId
+// Відкликає запрошення; учасники, що вже вступили за ним, залишаються в групі
+func (h *GroupHandler) RevokeInvite(c *gin.Context) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return
+	}
+	inviteID, err := primitive.ObjectIDFromHex(c.Param("inviteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid invite ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.CanInvite(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group moderators can revoke invites",
+		})
+		return
+	}
+
+	result, err := h.inviteCollection.UpdateOne(ctx,
+		bson.M{"_id": inviteID, "group_id": groupID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error revoking invite",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invite not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invite revoked",
+	})
+}
+
+// JoinByInvite - POST /groups/join-by-invite
+// Вступ за кодом запрошення: без заявки, зокрема до приватних груп і груп за запрошенням.
+// Блокування в групі та ліміт учасників діють як і при звичайному вступі.
+func (h *GroupHandler) JoinByInvite(c *gin.Context) {
+	var req JoinByInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var invite models.GroupInvite
+	err = h.inviteCollection.FindOne(ctx, bson.M{"code": code}).Decode(&invite)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invite not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	now := time.Now()
+	if !invite.IsUsable(now) {
+		c.JSON(http.StatusGone, gin.H{
+			"error": "Invite has expired or is no longer valid",
+		})
+		return
+	}
+
+	group, ok := h.findGroup(ctx, c, invite.GroupID)
+	if !ok {
+		return
+	}
+	if group.IsMember(userID) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "User is already a member of this group",
+			"group_id": group.ID,
+		})
+		return
+	}
+	if group.IsBanned(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are banned in this group",
+		})
+		return
+	}
+
+	// Використання резервується до вступу: паралельні запити не перевищать max_uses
+	reserved, err := h.inviteCollection.UpdateOne(ctx, bson.M{
+		"_id":        invite.ID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"max_uses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+		},
+	}, bson.M{"$inc": bson.M{"uses": 1}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error joining group",
+		})
+		return
+	}
+	if reserved.ModifiedCount == 0 {
+		c.JSON(http.StatusGone, gin.H{
+			"error": "Invite has expired or is no longer valid",
+		})
+		return
+	}
+
+	joined, err := h.addMember(ctx, group, userID, now)
+	if err != nil || !joined {
+		// Вступ не відбувся - повертаємо використання
+		h.inviteCollection.UpdateOne(ctx, bson.M{"_id": invite.ID}, bson.M{"$inc": bson.M{"uses": -1}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error joining group",
+			})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Unable to join group",
+		})
+		return
+	}
+
+	// Відкрита заявка більше не потрібна: вважаємо її схваленою автором запрошення
+	h.joinRequestCollection.UpdateMany(ctx,
+		bson.M{"group_id": group.ID, "user_id": userID, "status": models.GroupJoinRequestPending},
+		bson.M{"$set": bson.M{
+			"status":      models.GroupJoinRequestApproved,
+			"reviewed_by": invite.CreatedBy,
+			"reviewed_at": now,
+		}},
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Successfully joined group",
+		"group_id": group.ID,
+	})
+}
@@ -0,0 +1,688 @@
+// internal/handlers/group_members.go
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SetMemberRoleRequest - нова роль учасника групи
+type SetMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// BanMemberRequest - причина блокування в групі
+type BanMemberRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// JoinGroupRequest - супровідний текст заявки на вступ до групи за запрошенням
+type JoinGroupRequest struct {
+	Message string `json:"message" binding:"max=500"`
+}
+
+// findGroup завантажує групу; при помилці відповідь уже записана
+func (h *GroupHandler) findGroup(ctx context.Context, c *gin.Context, groupID primitive.ObjectID) (*models.Group, bool) {
+	var group models.Group
+	err := h.groupCollection.FindOne(ctx, communityScope(c, bson.M{"_id": groupID})).Decode(&group)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Group not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return nil, false
+	}
+	return &group, true
+}
+
+// memberParams - ID групи, учасника з шляху та поточного користувача
+func memberParams(c *gin.Context) (groupID, targetID, userID primitive.ObjectID, ok bool) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	targetID, err = primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+	userID, err = getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+	return groupID, targetID, userID, true
+}
+
+// addMember додає користувача до групи. false - він уже учасник або група заповнена.
+func (h *GroupHandler) addMember(ctx context.Context, group *models.Group, userID primitive.ObjectID, now time.Time) (bool, error) {
+	filter := bson.M{"_id": group.ID, "members": bson.M{"$ne": userID}, "bans.user_id": bson.M{"$ne": userID}}
+	if group.MaxMembers > 0 {
+		// Ліміт перевіряється в самому оновленні: паралельні вступи не перевищать його
+		filter["$expr"] = bson.M{"$lt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$members", bson.A{}}}}, group.MaxMembers}}
+	}
+	result, err := h.groupCollection.UpdateOne(ctx, filter, bson.M{
+		"$push": bson.M{"members": userID},
+		"$inc":  bson.M{"member_count": 1},
+		"$set":  bson.M{"updated_at": now},
+	})
+	if err != nil || result.ModifiedCount == 0 {
+		return false, err
+	}
+
+	// Додаємо групу до списку груп користувача
+	if _, err := h.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$addToSet": bson.M{"groups": group.ID},
+		"$set":      bson.M{"updated_at": now},
+	}); err != nil {
+		log.Printf("Error adding group %s to user %s: %v", group.ID.Hex(), userID.Hex(), err)
+	}
+
+	// Історія до вступу не вважається непрочитаною
+	h.initReadState(ctx, group.ID, userID)
+	return true, nil
+}
+
+// removeMember виключає користувача з групи разом з його роллю.
+// ban - запис блокування, який додається тим самим оновленням.
+func (h *GroupHandler) removeMember(ctx context.Context, groupID, userID primitive.ObjectID, ban *models.GroupBan) (bool, error) {
+	update := bson.M{
+		"$pull": bson.M{"members": userID, "admins": userID, "moderators": userID},
+		"$inc":  bson.M{"member_count": -1},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	if ban != nil {
+		update["$push"] = bson.M{"bans": ban}
+	}
+	result, err := h.groupCollection.UpdateOne(ctx, bson.M{"_id": groupID, "members": userID}, update)
+	if err != nil || result.ModifiedCount == 0 {
+		return false, err
+	}
+
+	if _, err := h.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$pull": bson.M{"groups": groupID},
+	}); err != nil {
+		log.Printf("Error removing group %s from user %s: %v", groupID.Hex(), userID.Hex(), err)
+	}
+
+	// Колишній учасник не з'являється серед тих, хто переглянув повідомлення
+	h.readStateCollection.DeleteOne(ctx, bson.M{"group_id": groupID, "user_id": userID})
+	return true, nil
+}
+
+// SetMemberRole - PUT /groups/:id/members/:userId/role
+// Призначає роль admin, moderator або member. Адміністраторів призначає лише власник,
+// модераторів - власник і адміністратори.
+func (h *GroupHandler) SetMemberRole(c *gin.Context) {
+	groupID, targetID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req SetMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !models.IsGroupRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid role",
+			"details": "Role must be one of: admin, moderator, member",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.IsMember(targetID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+	if !group.CanAssignRole(userID, targetID, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot assign this role to this member",
+		})
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	switch req.Role {
+	case models.GroupRoleAdmin:
+		update["$addToSet"] = bson.M{"admins": targetID}
+		update["$pull"] = bson.M{"moderators": targetID}
+	case models.GroupRoleModerator:
+		update["$addToSet"] = bson.M{"moderators": targetID}
+		update["$pull"] = bson.M{"admins": targetID}
+	default:
+		update["$pull"] = bson.M{"admins": targetID, "moderators": targetID}
+	}
+
+	result, err := h.groupCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": groupID, "members": targetID}), update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating member role",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	if h.wsHandler != nil {
+		h.wsHandler.SendSystemMessage(groupID, "member_role_changed", gin.H{
+			"group_id":   groupID,
+			"user_id":    targetID,
+			"role":       req.Role,
+			"changed_by": userID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member role updated",
+		"user_id": targetID,
+		"role":    req.Role,
+	})
+}
+
+// RemoveMember - DELETE /groups/:id/members/:userId
+// Виключає учасника: модератори - учасників, адміністратори - ще й модераторів, власник - будь-кого.
+// Виключений може вступити знову; щоб заборонити вступ, використовується блокування.
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	groupID, targetID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.IsMember(targetID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+	if !group.CanManage(userID, targetID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot remove this member",
+		})
+		return
+	}
+
+	removed, err := h.removeMember(ctx, groupID, targetID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error removing member",
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	h.notifyMemberRemoved(groupID, targetID, userID, "removed")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed",
+	})
+}
+
+// BanMember - POST /groups/:id/members/:userId/ban
+// Виключає учасника (якщо він у групі) і забороняє вступ та заявки, доки блокування не знято.
+// Права ті самі, що й для виключення.
+func (h *GroupHandler) BanMember(c *gin.Context) {
+	groupID, targetID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req BanMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if group.IsBanned(targetID) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User is already banned in this group",
+		})
+		return
+	}
+	if !group.CanManage(userID, targetID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot ban this user",
+		})
+		return
+	}
+
+	ban := models.GroupBan{
+		UserID:   targetID,
+		Reason:   req.Reason,
+		BannedBy: userID,
+		BannedAt: time.Now(),
+	}
+
+	wasMember, err := h.removeMember(ctx, groupID, targetID, &ban)
+	if err == nil && !wasMember {
+		// Не учасник (наприклад, автор заявки): лише запис блокування
+		_, err = h.groupCollection.UpdateOne(ctx,
+			communityScope(c, bson.M{"_id": groupID, "members": bson.M{"$ne": targetID}, "bans.user_id": bson.M{"$ne": targetID}}),
+			bson.M{"$push": bson.M{"bans": ban}, "$set": bson.M{"updated_at": ban.BannedAt}},
+		)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error banning user",
+		})
+		return
+	}
+
+	// Заявка заблокованого більше не розглядається
+	h.joinRequestCollection.UpdateMany(ctx,
+		bson.M{"group_id": groupID, "user_id": targetID, "status": models.GroupJoinRequestPending},
+		bson.M{"$set": bson.M{
+			"status":      models.GroupJoinRequestRejected,
+			"reviewed_by": userID,
+			"reviewed_at": ban.BannedAt,
+		}},
+	)
+
+	if wasMember {
+		h.notifyMemberRemoved(groupID, targetID, userID, "banned")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User banned",
+		"ban":     ban,
+	})
+}
+
+// UnbanMember - DELETE /groups/:id/members/:userId/ban
+// Знімає блокування; користувач може знову вступити або подати заявку
+func (h *GroupHandler) UnbanMember(c *gin.Context) {
+	groupID, targetID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !group.CanManage(userID, targetID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot unban this user",
+		})
+		return
+	}
+
+	result, err := h.groupCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": groupID}), bson.M{
+		"$pull": bson.M{"bans": bson.M{"user_id": targetID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error unbanning user",
+		})
+		return
+	}
+	if result.ModifiedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User is not banned in this group",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unbanned",
+	})
+}
+
+// GetBans - GET /groups/:id/bans
+// Заблоковані користувачі групи (власник, адміністратори й модератори)
+func (h *GroupHandler) GetBans(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if role := group.RoleOf(userID); role == "" || role == models.GroupRoleMember {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group moderators can view bans",
+		})
+		return
+	}
+
+	bans := group.Bans
+	if bans == nil {
+		bans = []models.GroupBan{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"bans":  bans,
+		"count": len(bans),
+	})
+}
+
+// notifyMemberRemoved повідомляє групу та закриває чат-з'єднання виключеного
+func (h *GroupHandler) notifyMemberRemoved(groupID, targetID, userID primitive.ObjectID, reason string) {
+	if h.wsHandler == nil {
+		return
+	}
+	h.wsHandler.SendSystemMessage(groupID, "member_removed", gin.H{
+		"group_id":   groupID,
+		"user_id":    targetID,
+		"removed_by": userID,
+		"reason":     reason,
+	})
+	h.wsHandler.DisconnectFromGroup(targetID, groupID)
+}
+
+// requestJoin подає заявку на вступ до групи за запрошенням
+func (h *GroupHandler) requestJoin(ctx context.Context, c *gin.Context, group *models.Group, userID primitive.ObjectID) {
+	var req JoinGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request := models.GroupJoinRequest{
+		ID:        primitive.NewObjectID(),
+		GroupID:   group.ID,
+		UserID:    userID,
+		Message:   req.Message,
+		Status:    models.GroupJoinRequestPending,
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.joinRequestCollection.InsertOne(ctx, request); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Join request is already pending",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating join request",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Join request sent",
+		"request": request,
+	})
+}
+
+// canReviewJoinRequests - заявки розглядають власник і адміністратори групи
+func canReviewJoinRequests(group *models.Group, userID primitive.ObjectID) bool {
+	role := group.RoleOf(userID)
+	return role == models.GroupRoleOwner || role == models.GroupRoleAdmin
+}
+
+// GetJoinRequests - GET /groups/:id/join-requests?status=pending
+// Заявки на вступ (власник і адміністратори групи), нові першими
+func (h *GroupHandler) GetJoinRequests(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	status := c.DefaultQuery("status", models.GroupJoinRequestPending)
+	if status != models.GroupJoinRequestPending && status != models.GroupJoinRequestApproved && status != models.GroupJoinRequestRejected {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !canReviewJoinRequests(group, userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group admins can view join requests",
+		})
+		return
+	}
+
+	page, limit := pageParams(c, 20, 100)
+	filter := bson.M{"group_id": groupID, "status": status}
+	cursor, err := h.joinRequestCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching join requests",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	requests := []models.GroupJoinRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding join requests",
+		})
+		return
+	}
+
+	total, _ := h.joinRequestCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, requests, newPageInfo(page, limit, total), nil, nil))
+}
+
+// ApproveJoinRequest - POST /groups/:id/join-requests/:requestId/approve
+func (h *GroupHandler) ApproveJoinRequest(c *gin.Context) {
+	h.reviewJoinRequest(c, models.GroupJoinRequestApproved)
+}
+
+// RejectJoinRequest - POST /groups/:id/join-requests/:requestId/reject
+func (h *GroupHandler) RejectJoinRequest(c *gin.Context) {
+	h.reviewJoinRequest(c, models.GroupJoinRequestRejected)
+}
+
+// reviewJoinRequest розглядає заявку; при схваленні автор стає учасником групи
+// і отримує join_request_reviewed у свої з'єднання
+func (h *GroupHandler) reviewJoinRequest(c *gin.Context, status string) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+	requestID, err := primitive.ObjectIDFromHex(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request ID",
+		})
+		return
+	}
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, ok := h.findGroup(ctx, c, groupID)
+	if !ok {
+		return
+	}
+	if !canReviewJoinRequests(group, userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group admins can review join requests",
+		})
+		return
+	}
+
+	var request models.GroupJoinRequest
+	err = h.joinRequestCollection.FindOne(ctx, bson.M{
+		"_id":      requestID,
+		"group_id": groupID,
+		"status":   models.GroupJoinRequestPending,
+	}).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Pending join request not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching join request",
+		})
+		return
+	}
+
+	now := time.Now()
+	if status == models.GroupJoinRequestApproved {
+		if group.MaxMembers > 0 && len(group.Members) >= group.MaxMembers {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Group has reached maximum number of members",
+			})
+			return
+		}
+		added, err := h.addMember(ctx, group, request.UserID, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error adding member",
+			})
+			return
+		}
+		if !added && !group.IsMember(request.UserID) {
+			// Група заповнилася або автора заблокували після завантаження групи
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User cannot join the group",
+			})
+			return
+		}
+	}
+
+	request.Status = status
+	request.ReviewedBy = &userID
+	request.ReviewedAt = &now
+	if _, err := h.joinRequestCollection.UpdateOne(ctx, bson.M{"_id": requestID}, bson.M{
+		"$set": bson.M{
+			"status":      status,
+			"reviewed_by": userID,
+			"reviewed_at": now,
+		},
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating join request",
+		})
+		return
+	}
+
+	if h.wsHandler != nil {
+		h.wsHandler.SendToUser(request.UserID, WSMessage{
+			Type:    "join_request_reviewed",
+			GroupID: groupID.Hex(),
+			Data: gin.H{
+				"request_id": requestID,
+				"group_id":   groupID,
+				"status":     status,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Join request " + status,
+		"request": request,
+	})
+}
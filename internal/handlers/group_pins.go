@@ -0,0 +1,223 @@
+// internal/handlers/group_pins.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pinnedMessagesFilter - закріплені повідомлення групи, які бачать учасники
+func pinnedMessagesFilter(groupID primitive.ObjectID) bson.M {
+	return bson.M{
+		"group_id":   groupID,
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+		"pinned_at":  bson.M{"$exists": true},
+	}
+}
+
+// pinParams - ID групи й повідомлення з шляху та група, в якій користувач може закріплювати.
+// При помилці відповідь уже записана.
+func (h *GroupHandler) pinParams(ctx context.Context, c *gin.Context) (group *models.Group, messageID, userID primitive.ObjectID, ok bool) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return nil, messageID, userID, false
+	}
+	messageID, err := primitive.ObjectIDFromHex(c.Param("msgId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return nil, messageID, userID, false
+	}
+
+	group, ok = h.findGroup(ctx, c, groupID)
+	if !ok {
+		return nil, messageID, userID, false
+	}
+	if !group.CanPin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only group admins can pin messages",
+		})
+		return nil, messageID, userID, false
+	}
+	return group, messageID, userID, true
+}
+
+// PinMessage - POST /groups/:id/messages/:msgId/pin
+// Закріплює повідомлення (власник і адміністратори групи, до models.MaxPinnedMessages).
+// Учасники групи отримують message_pinned.
+func (h *GroupHandler) PinMessage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, messageID, userID, ok := h.pinParams(ctx, c)
+	if !ok {
+		return
+	}
+
+	var message models.Message
+	err := h.messageCollection.FindOne(ctx, bson.M{
+		"_id":        messageID,
+		"group_id":   group.ID,
+		"is_deleted": false,
+	}).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Message not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if message.IsHeld {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Message is awaiting moderation and cannot be pinned",
+		})
+		return
+	}
+
+	// Повторне закріплення нічого не змінює
+	if message.PinnedAt != nil {
+		h.attachments.Sign(message.Attachments)
+		c.JSON(http.StatusOK, message)
+		return
+	}
+
+	pinned, err := h.messageCollection.CountDocuments(ctx, pinnedMessagesFilter(group.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if pinned >= models.MaxPinnedMessages {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Too many pinned messages, unpin one first",
+			"max_pinned": models.MaxPinnedMessages,
+		})
+		return
+	}
+
+	now := time.Now()
+	result, err := h.messageCollection.UpdateOne(ctx,
+		bson.M{"_id": messageID, "is_deleted": false, "pinned_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"pinned_at": now, "pinned_by": userID}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error pinning message",
+		})
+		return
+	}
+	message.PinnedAt = &now
+	message.PinnedBy = &userID
+
+	if h.wsHandler != nil && result.ModifiedCount > 0 {
+		h.wsHandler.SendSystemMessage(group.ID, "message_pinned", gin.H{
+			"id":        messageID,
+			"group_id":  group.ID,
+			"pinned_by": userID,
+			"pinned_at": now,
+		})
+	}
+
+	h.attachments.Sign(message.Attachments)
+	c.JSON(http.StatusOK, message)
+}
+
+// UnpinMessage - DELETE /groups/:id/messages/:msgId/pin
+// Знімає закріплення; учасники групи отримують message_unpinned
+func (h *GroupHandler) UnpinMessage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, messageID, userID, ok := h.pinParams(ctx, c)
+	if !ok {
+		return
+	}
+
+	result, err := h.messageCollection.UpdateOne(ctx,
+		bson.M{"_id": messageID, "group_id": group.ID, "pinned_at": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"pinned_at": "", "pinned_by": ""}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error unpinning message",
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Pinned message not found",
+		})
+		return
+	}
+
+	if h.wsHandler != nil {
+		h.wsHandler.SendSystemMessage(group.ID, "message_unpinned", gin.H{
+			"id":          messageID,
+			"group_id":    group.ID,
+			"unpinned_by": userID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message unpinned",
+	})
+}
+
+// GetPinnedMessages - GET /groups/:id/messages/pinned
+// Закріплені повідомлення групи, останні закріплені першими (для учасників)
+func (h *GroupHandler) GetPinnedMessages(c *gin.Context) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !h.isGroupMember(ctx, c, groupID, userID) {
+		return
+	}
+
+	cursor, err := h.messageCollection.Find(ctx, pinnedMessagesFilter(groupID), options.Find().
+		SetSort(bson.D{{Key: "pinned_at", Value: -1}}).
+		SetLimit(models.MaxPinnedMessages))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching pinned messages",
+		})
+		return
+	}
+	messages := []models.Message{}
+	err = cursor.All(ctx, &messages)
+	cursor.Close(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding pinned messages",
+		})
+		return
+	}
+	h.attachments.SignMessages(messages)
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
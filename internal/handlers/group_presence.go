@@ -0,0 +1,121 @@
+// internal/handlers/group_presence.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MemberPresence - чи учасник онлайн у чаті і коли був востаннє
+type MemberPresence struct {
+	UserID     primitive.ObjectID `json:"user_id"`
+	IsOnline   bool               `json:"is_online"`
+	LastSeenAt *time.Time         `json:"last_seen_at,omitempty"`
+}
+
+// GetGroupPresence - GET /groups/:id/presence
+// Присутність учасників групи: спершу онлайн, далі за last_seen_at. Учасники, які приховали
+// присутність у налаштуваннях приватності, до списку не потрапляють.
+func (h *GroupHandler) GetGroupPresence(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var group models.Group
+	err = h.groupCollection.FindOne(ctx,
+		communityScope(c, bson.M{"_id": groupID, "members": user.UserID}),
+		options.FindOne().SetProjection(bson.M{"members": 1}),
+	).Decode(&group)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+
+	cursor, err := h.userCollection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": group.Members}, "is_deleted": bson.M{"$ne": true}},
+		options.Find().SetProjection(bson.M{"last_seen_at": 1, "privacy": 1}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching presence",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var members []models.User
+	if err := cursor.All(ctx, &members); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding presence",
+		})
+		return
+	}
+
+	presence := make([]MemberPresence, 0, len(members))
+	online := 0
+	for _, member := range members {
+		if !member.ShowsPresence() {
+			continue
+		}
+		entry := MemberPresence{
+			UserID:     member.ID,
+			IsOnline:   h.presence.IsOnline(member.ID),
+			LastSeenAt: member.LastSeenAt,
+		}
+		if entry.IsOnline {
+			online++
+		}
+		presence = append(presence, entry)
+	}
+
+	sort.Slice(presence, func(i, j int) bool {
+		if presence[i].IsOnline != presence[j].IsOnline {
+			return presence[i].IsOnline
+		}
+		if presence[i].LastSeenAt == nil || presence[j].LastSeenAt == nil {
+			return presence[j].LastSeenAt == nil && presence[i].LastSeenAt != nil
+		}
+		return presence[i].LastSeenAt.After(*presence[j].LastSeenAt)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_id":     groupID,
+		"online_count": online,
+		"members":      presence,
+	})
+}
@@ -0,0 +1,354 @@
+// internal/handlers/group_read.go
+
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MarkGroupReadRequest - до якого повідомлення прочитано; без message_id - до останнього
+type MarkGroupReadRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// GroupView - група у списку користувача з його непрочитаними повідомленнями
+type GroupView struct {
+	models.Group
+	UnreadCount       int                 `json:"unread_count"` // Не більше models.MaxGroupUnreadCount
+	LastReadMessageID *primitive.ObjectID `json:"last_read_message_id,omitempty"`
+}
+
+// unreadFilter - повідомлення групи, які користувач ще не прочитав: чужі, не видалені й не утримані
+func unreadFilter(groupID, userID primitive.ObjectID, lastRead *primitive.ObjectID) bson.M {
+	filter := bson.M{
+		"group_id":   groupID,
+		"user_id":    bson.M{"$ne": userID},
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+	}
+	if lastRead != nil {
+		filter["_id"] = bson.M{"$gt": *lastRead}
+	}
+	return filter
+}
+
+// countUnread рахує непрочитані повідомлення до models.MaxGroupUnreadCount
+func (h *GroupHandler) countUnread(ctx context.Context, groupID, userID primitive.ObjectID, lastRead *primitive.ObjectID) (int, error) {
+	count, err := h.messageCollection.CountDocuments(ctx,
+		unreadFilter(groupID, userID, lastRead),
+		options.Count().SetLimit(models.MaxGroupUnreadCount),
+	)
+	return int(count), err
+}
+
+// groupViews додає до груп непрочитані повідомлення користувача
+func (h *GroupHandler) groupViews(ctx context.Context, userID primitive.ObjectID, groups []models.Group) ([]GroupView, error) {
+	views := make([]GroupView, 0, len(groups))
+	if len(groups) == 0 {
+		return views, nil
+	}
+
+	groupIDs := make([]primitive.ObjectID, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	cursor, err := h.readStateCollection.Find(ctx, bson.M{
+		"user_id":  userID,
+		"group_id": bson.M{"$in": groupIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var states []models.GroupReadState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	lastRead := make(map[primitive.ObjectID]primitive.ObjectID, len(states))
+	for _, state := range states {
+		lastRead[state.GroupID] = state.LastReadMessageID
+	}
+
+	for _, group := range groups {
+		view := GroupView{Group: group}
+		if messageID, ok := lastRead[group.ID]; ok {
+			view.LastReadMessageID = &messageID
+		}
+		if view.UnreadCount, err = h.countUnread(ctx, group.ID, userID, view.LastReadMessageID); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// latestMessageID - останнє повідомлення групи, видиме користувачу; nil - повідомлень немає
+func (h *GroupHandler) latestMessageID(ctx context.Context, groupID, userID primitive.ObjectID) (*primitive.ObjectID, error) {
+	var message struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := h.messageCollection.FindOne(ctx,
+		bson.M{
+			"group_id":   groupID,
+			"is_deleted": false,
+			"$or": []bson.M{
+				{"is_held": bson.M{"$ne": true}},
+				{"user_id": userID},
+			},
+		},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}).SetProjection(bson.M{"_id": 1}),
+	).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message.ID, nil
+}
+
+// advanceReadState переносить позицію читання вперед; позиція ніколи не повертається назад.
+// advanced=false - позиція вже була на цьому або пізнішому повідомленні.
+func (h *GroupHandler) advanceReadState(ctx context.Context, groupID, userID, messageID primitive.ObjectID, now time.Time) (bool, error) {
+	_, err := h.readStateCollection.UpdateOne(ctx,
+		bson.M{
+			"group_id": groupID,
+			"user_id":  userID,
+			"$or": []bson.M{
+				{"last_read_message_id": bson.M{"$lt": messageID}},
+				{"last_read_message_id": bson.M{"$exists": false}},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"last_read_message_id": messageID,
+			"last_read_at":         now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	// Позиція пізніша: фільтр не збігся, а вставка впирається в унікальний індекс
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// initReadState - новий учасник починає з останнього повідомлення, історія не рахується непрочитаною
+func (h *GroupHandler) initReadState(ctx context.Context, groupID, userID primitive.ObjectID) {
+	messageID, err := h.latestMessageID(ctx, groupID, userID)
+	if err == nil && messageID != nil {
+		_, err = h.advanceReadState(ctx, groupID, userID, *messageID, time.Now())
+	}
+	if err != nil {
+		log.Printf("Error initializing read state of group %s for user %s: %v", groupID.Hex(), userID.Hex(), err)
+	}
+}
+
+// isGroupMember - чи є користувач учасником групи
+func (h *GroupHandler) isGroupMember(ctx context.Context, c *gin.Context, groupID, userID primitive.ObjectID) bool {
+	count, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": groupID, "members": userID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return false
+	}
+	return true
+}
+
+// MarkAsRead - POST /groups/:id/read
+// Переносить позицію читання до повідомлення (за замовчуванням - останнього) і повертає
+// залишок непрочитаних. Учасники групи отримують messages_read, щоб показати "переглянуто".
+func (h *GroupHandler) MarkAsRead(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	var req MarkGroupReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !h.isGroupMember(ctx, c, groupID, user.UserID) {
+		return
+	}
+
+	var messageID *primitive.ObjectID
+	if req.MessageID != "" {
+		id, err := primitive.ObjectIDFromHex(req.MessageID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid message ID",
+			})
+			return
+		}
+		count, err := h.messageCollection.CountDocuments(ctx, bson.M{"_id": id, "group_id": groupID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Database error",
+			})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Message not found",
+			})
+			return
+		}
+		messageID = &id
+	} else if messageID, err = h.latestMessageID(ctx, groupID, user.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching messages",
+		})
+		return
+	}
+
+	// Порожня група: читати нічого
+	if messageID == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"group_id":     groupID,
+			"unread_count": 0,
+		})
+		return
+	}
+
+	now := time.Now()
+	advanced, err := h.advanceReadState(ctx, groupID, user.UserID, *messageID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating read state",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var state models.GroupReadState
+	err = h.readStateCollection.FindOne(ctx, bson.M{"group_id": groupID, "user_id": user.UserID}).Decode(&state)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching read state",
+		})
+		return
+	}
+
+	unread, err := h.countUnread(ctx, groupID, user.UserID, &state.LastReadMessageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting unread messages",
+		})
+		return
+	}
+
+	if advanced && h.wsHandler != nil {
+		h.wsHandler.SendSystemMessage(groupID, "messages_read", gin.H{
+			"group_id":             groupID,
+			"user_id":              user.UserID,
+			"last_read_message_id": state.LastReadMessageID,
+			"read_at":              state.LastReadAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_id":             groupID,
+		"last_read_message_id": state.LastReadMessageID,
+		"last_read_at":         state.LastReadAt,
+		"unread_count":         unread,
+	})
+}
+
+// GetReadReceipts - GET /groups/:id/read-receipts
+// Позиції читання учасників: повідомлення "переглянуто" тими, чий last_read_message_id не менший за його ID
+func (h *GroupHandler) GetReadReceipts(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !h.isGroupMember(ctx, c, groupID, user.UserID) {
+		return
+	}
+
+	cursor, err := h.readStateCollection.Find(ctx,
+		bson.M{"group_id": groupID},
+		options.Find().SetSort(bson.D{{Key: "last_read_message_id", Value: -1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching read receipts",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	receipts := []models.GroupReadState{}
+	if err := cursor.All(ctx, &receipts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding read receipts",
+		})
+		return
+	}
+
+	// Власна позиція не потрібна для "переглянуто"
+	filtered := receipts[:0]
+	for _, receipt := range receipts {
+		if receipt.UserID != user.UserID {
+			filtered = append(filtered, receipt)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_id": groupID,
+		"receipts": filtered,
+	})
+}
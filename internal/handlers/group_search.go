@@ -0,0 +1,157 @@
+// internal/handlers/group_search.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SearchMessages - GET /groups/:id/messages/search?q=
+// Повнотекстовий пошук у повідомленнях групи (текстовий індекс на content).
+// Фільтри: sender - ID автора, from/to - RFC3339 або YYYY-MM-DD (to включає весь день).
+// Сортування: sort=relevance (за замовчуванням) або sort=date - нові першими.
+func (h *GroupHandler) SearchMessages(c *gin.Context) {
+	groupID, userID, ok := groupManagerParams(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if length := utf8.RuneCountInString(query); length < models.MinMessageSearchQueryLength || length > models.MaxMessageSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid search query",
+			"details": "q must be between 2 and 200 characters",
+		})
+		return
+	}
+
+	// Утримані повідомлення бачить лише автор, як і у стрічці групи
+	filter := bson.M{
+		"group_id":   groupID,
+		"$text":      bson.M{"$search": query},
+		"is_deleted": false,
+		"$or": []bson.M{
+			{"is_held": bson.M{"$ne": true}},
+			{"user_id": userID},
+		},
+	}
+
+	if sender := c.Query("sender"); sender != "" {
+		senderID, err := primitive.ObjectIDFromHex(sender)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid sender ID",
+			})
+			return
+		}
+		filter["user_id"] = senderID
+	}
+
+	createdAt := bson.M{}
+	for param, op := range map[string]string{"from": "$gte", "to": "$lt"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		date, err := parseNotificationDate(value, param == "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid " + param + " date",
+				"details": "Use RFC3339 or YYYY-MM-DD",
+			})
+			return
+		}
+		createdAt[op] = date
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	sortBy := c.DefaultQuery("sort", "relevance")
+	sort := bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "created_at", Value: -1}}
+	switch sortBy {
+	case "relevance":
+	case "date":
+		sort = bson.D{{Key: "created_at", Value: -1}}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid sort. Use relevance or date",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	isMember, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": groupID, "members": userID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if isMember == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	page, limit := pageParams(c, 20, 50)
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(sort).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error searching messages",
+		})
+		return
+	}
+	var hits []models.MessageSearchHit
+	err = cursor.All(ctx, &hits)
+	cursor.Close(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding messages",
+		})
+		return
+	}
+
+	terms := services.MessageSearchTerms(query)
+	for i := range hits {
+		hits[i].Snippet, hits[i].Highlights = services.MessageSnippet(hits[i].Content, terms)
+		if hits[i].Highlights == nil {
+			hits[i].Highlights = []models.TextRange{}
+		}
+		h.attachments.Sign(hits[i].Attachments)
+	}
+
+	total, err := h.messageCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error counting messages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse(c, hits, newPageInfo(page, limit, total), gin.H{
+		"query": query,
+		"sort":  sortBy,
+	}, nil))
+}
@@ -0,0 +1,76 @@
+// internal/handlers/health.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// HealthHandler - готовність сервера приймати трафік (для балансувальника та моніторингу)
+type HealthHandler struct {
+	client        *mongo.Client
+	backupService *services.BackupService
+	providers     []services.ProviderHealthSource
+}
+
+func NewHealthHandler(client *mongo.Client, backupService *services.BackupService, providers ...services.ProviderHealthSource) *HealthHandler {
+	return &HealthHandler{
+		client:        client,
+		backupService: backupService,
+		providers:     providers,
+	}
+}
+
+// Ready - GET /health/ready
+// 503, якщо MongoDB недоступна. Застаріла або відсутня резервна копія не знімає сервер
+// з балансування: відповідь 200 зі статусом "degraded", щоб моніторинг підняв тривогу.
+// Так само і недоступні провайдери сповіщень (FCM, SMTP): повідомлення чекають у черзі.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := h.client.Ping(ctx, readpref.Primary()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"mongodb": "unreachable",
+			"details": err.Error(),
+			"time":    time.Now().Format(time.RFC3339),
+		})
+		return
+	}
+
+	status := "ready"
+	response := gin.H{
+		"mongodb": "ok",
+		"time":    time.Now().Format(time.RFC3339),
+	}
+	backup, err := h.backupService.Health(ctx)
+	if err != nil {
+		status = "degraded"
+		response["backup"] = gin.H{"status": "unknown", "details": err.Error()}
+	} else {
+		if !backup.Healthy() {
+			status = "degraded"
+		}
+		response["backup"] = backup
+	}
+
+	providers := services.CollectProviderHealth(h.providers...)
+	for _, provider := range providers {
+		if provider.Degraded() {
+			status = "degraded"
+		}
+	}
+	response["providers"] = providers
+	response["status"] = status
+
+	c.JSON(http.StatusOK, response)
+}
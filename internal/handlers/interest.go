@@ -0,0 +1,223 @@
+// internal/handlers/interest.go
+
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Максимальна кількість вибраних інтересів
+const maxUserInterests = 20
+
+// Розмір добірки рекомендацій
+const recommendationLimit = 20
+
+// InterestHandler - вибір інтересів користувача з довідника interests
+type InterestHandler struct {
+	userCollection  *mongo.Collection
+	taxonomyService *services.TaxonomyService
+}
+
+type UpdateInterestsRequest struct {
+	Interests []string `json:"interests" binding:"required"`
+}
+
+func NewInterestHandler(userCollection *mongo.Collection, taxonomyService *services.TaxonomyService) *InterestHandler {
+	return &InterestHandler{
+		userCollection:  userCollection,
+		taxonomyService: taxonomyService,
+	}
+}
+
+// GetMyInterests - GET /auth/profile/interests?lang=en
+// Довідник інтересів з позначкою вибраних користувачем
+func (h *InterestHandler) GetMyInterests(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	selected, err := userInterests(ctx, h.userCollection, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching interests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.respond(c, selected)
+}
+
+// UpdateMyInterests - PUT /auth/profile/interests
+// Замінює вибрані інтереси; приймаються коди або назви з довідника
+func (h *InterestHandler) UpdateMyInterests(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req UpdateInterestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	codes, unknown := h.taxonomyService.NormalizeInterests(req.Interests)
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Unknown interests",
+			"unknown": unknown,
+		})
+		return
+	}
+	if len(codes) > maxUserInterests {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Too many interests selected",
+			"limit": maxUserInterests,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"interests":  codes,
+			"updated_at": time.Now(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating interests",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	h.respond(c, codes)
+}
+
+func (h *InterestHandler) respond(c *gin.Context, selected []string) {
+	lang := c.DefaultQuery("lang", "uk")
+
+	isSelected := make(map[string]bool, len(selected))
+	for _, code := range selected {
+		isSelected[code] = true
+	}
+
+	items := []gin.H{}
+	for _, interest := range h.taxonomyService.List(models.TaxonomyInterests, false) {
+		view := taxonomyView(interest, lang)
+		view["selected"] = isSelected[interest.Code]
+		items = append(items, view)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interests": selected,
+		"available": items,
+	})
+}
+
+// userInterests - коди інтересів користувача
+func userInterests(ctx context.Context, userCollection *mongo.Collection, userID primitive.ObjectID) ([]string, error) {
+	var user models.User
+	err := userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"interests": 1})).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	if user.Interests == nil {
+		return []string{}, nil
+	}
+	return user.Interests, nil
+}
+
+// recommendationScope додає до фільтра контенту модуля умову відповідності інтересам користувача.
+// personalized = false, якщо інтереси не вибрано: тоді добірка - звичайний список модуля.
+func recommendationScope(ctx context.Context, c *gin.Context, userCollection *mongo.Collection, taxonomyService *services.TaxonomyService, module string, filter bson.M) (personalized bool, ok bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return false, false
+	}
+
+	interests, err := userInterests(ctx, userCollection, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching interests",
+			"details": err.Error(),
+		})
+		return false, false
+	}
+
+	interestFilter := taxonomyService.InterestFilter(module, interests)
+	if interestFilter == nil {
+		return false, true
+	}
+
+	// Фільтр модуля вже може містити $or (наприклад, громада), тому поєднуємо через $and
+	and, _ := filter["$and"].([]bson.M)
+	filter["$and"] = append(and, interestFilter)
+	return true, true
+}
+
+// notifyInterested надсилає сповіщення про новий контент користувачам з відповідними інтересами
+func notifyInterested(c *gin.Context, notificationService *services.NotificationService, taxonomyService *services.TaxonomyService, module, category string, tags []string, audience services.InterestAudience, title, body, notificationType string, data map[string]interface{}, relatedID primitive.ObjectID) {
+	if notificationService == nil || taxonomyService == nil {
+		return
+	}
+	audience.Interests = taxonomyService.InterestsForContent(module, category, tags)
+	if len(audience.Interests) == 0 {
+		return
+	}
+	audience.IsDefaultCommunity = c.GetBool("community_is_default")
+
+	// Розсилка за аудиторією може бути довгою, тому в запиті лише ставиться намір в outbox
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := notificationService.Enqueue(ctx, services.NotificationIntent{
+		Source:    module + ".published",
+		Audience:  &audience,
+		Title:     title,
+		Body:      body,
+		Type:      notificationType,
+		Data:      data,
+		RelatedID: &relatedID,
+	}); err != nil {
+		log.Printf("Error notifying interested users about %s %s: %v", module, relatedID.Hex(), err)
+	}
+}
@@ -0,0 +1,55 @@
+// internal/handlers/language.go
+
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// applyLanguageFilter додає до запиту списку фільтр ?lang=uk або ?lang=uk,en за мовою
+// контенту. Некоректне значення - 400 і false.
+func applyLanguageFilter(c *gin.Context, query bson.M) bool {
+	param := strings.TrimSpace(c.Query("lang"))
+	if param == "" {
+		return true
+	}
+
+	languages := []string{}
+	for _, lang := range strings.Split(param, ",") {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if !models.IsContentLanguage(lang) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid lang filter",
+				"details": "Allowed values: " + strings.Join(models.ContentLanguages, ", "),
+			})
+			return false
+		}
+		languages = append(languages, lang)
+	}
+
+	if len(languages) == 1 {
+		query["language"] = languages[0]
+	} else {
+		query["language"] = bson.M{"$in": languages}
+	}
+	return true
+}
+
+// detectEditedLanguage - мова тексту після редагування; порожні нові значення
+// означають, що поле не змінюється
+func detectEditedLanguage(title, description, newTitle, newDescription string) string {
+	if newTitle != "" {
+		title = newTitle
+	}
+	if newDescription != "" {
+		description = newDescription
+	}
+	return services.DetectLanguage(title, description)
+}
@@ -0,0 +1,268 @@
+// internal/handlers/longpoll.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	longPollDefaultTimeout = 25 * time.Second
+	longPollMaxTimeout     = 25 * time.Second
+	// Періодична перевірка бази на випадок подій з інших інстансів сервера
+	longPollRecheckInterval = 5 * time.Second
+	longPollBatchSize       = 100
+)
+
+// LongPollHandler - fallback для клієнтів без WebSocket (старі браузери, шкільні планшети за проксі).
+// Події мають той самий формат WSMessage та курсори, що й WebSocket hub.
+type LongPollHandler struct {
+	hub                    *Hub
+	groupCollection        *mongo.Collection
+	messageCollection      *mongo.Collection
+	notificationCollection *mongo.Collection
+	attachments            *services.ChatAttachmentService
+}
+
+// NewLongPollHandler створює long-poll обробник поверх hub WebSocket
+func NewLongPollHandler(wsHandler *WebSocketHandler, groupCollection, messageCollection, notificationCollection *mongo.Collection, attachments *services.ChatAttachmentService) *LongPollHandler {
+	return &LongPollHandler{
+		hub:                    wsHandler.hub,
+		groupCollection:        groupCollection,
+		messageCollection:      messageCollection,
+		notificationCollection: notificationCollection,
+		attachments:            attachments,
+	}
+}
+
+// longPollFetch повертає нові події після курсора та новий курсор
+type longPollFetch func(ctx context.Context, cursor primitive.ObjectID) ([]WSMessage, primitive.ObjectID, error)
+
+// PollMessages - GET /groups/:id/messages/poll?cursor=<message_id>&timeout=25
+// Без курсора одразу повертає поточний курсор, з якого клієнт починає опитування.
+func (h *LongPollHandler) PollMessages(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid group ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	count, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{
+		"_id":     groupID,
+		"members": bson.M{"$in": []primitive.ObjectID{userID}},
+	}))
+	cancel()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "User is not a member of this group",
+		})
+		return
+	}
+
+	h.poll(c, groupTopic(groupID), h.messageCollection, bson.M{
+		"group_id":   groupID,
+		"is_deleted": false,
+	}, func(ctx context.Context, cursor primitive.ObjectID) ([]WSMessage, primitive.ObjectID, error) {
+		var messages []models.Message
+		if err := h.findAfter(ctx, h.messageCollection, bson.M{
+			"group_id":   groupID,
+			"is_deleted": false,
+			"is_held":    bson.M{"$ne": true},
+		}, cursor, &messages); err != nil {
+			return nil, cursor, err
+		}
+		h.attachments.SignMessages(messages)
+
+		events := make([]WSMessage, 0, len(messages))
+		for i := range messages {
+			cursor = messages[i].ID
+			events = append(events, WSMessage{
+				Type:    "new_message",
+				GroupID: groupID.Hex(),
+				Data:    messages[i],
+				Cursor:  cursor.Hex(),
+			})
+		}
+		return events, cursor, nil
+	})
+}
+
+// PollNotifications - GET /notifications/poll?cursor=<notification_id>&timeout=25
+func (h *LongPollHandler) PollNotifications(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	h.poll(c, userTopic(userID), h.notificationCollection, bson.M{
+		"user_id": userID,
+	}, func(ctx context.Context, cursor primitive.ObjectID) ([]WSMessage, primitive.ObjectID, error) {
+		var notifications []services.StoredNotification
+		if err := h.findAfter(ctx, h.notificationCollection, bson.M{
+			"user_id": userID,
+		}, cursor, &notifications); err != nil {
+			return nil, cursor, err
+		}
+
+		events := make([]WSMessage, 0, len(notifications))
+		for i := range notifications {
+			cursor = notifications[i].ID
+			events = append(events, WSMessage{
+				Type:   "notification",
+				Data:   notifications[i],
+				Cursor: cursor.Hex(),
+			})
+		}
+		return events, cursor, nil
+	})
+}
+
+// poll утримує запит, доки не з'являться події після курсора або не мине timeout
+func (h *LongPollHandler) poll(c *gin.Context, topic string, collection *mongo.Collection, baseFilter bson.M, fetch longPollFetch) {
+	timeout := longPollDefaultTimeout
+	if seconds, err := strconv.Atoi(c.Query("timeout")); err == nil && seconds >= 0 {
+		timeout = time.Duration(seconds) * time.Second
+		if timeout > longPollMaxTimeout {
+			timeout = longPollMaxTimeout
+		}
+	}
+
+	// Перший запит без курсора: повертаємо точку відліку замість всієї історії
+	cursorParam := c.Query("cursor")
+	if cursorParam == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cursor, err := h.latestID(ctx, collection, baseFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching cursor",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"events": []WSMessage{},
+			"cursor": cursor.Hex(),
+		})
+		return
+	}
+
+	cursor, err := primitive.ObjectIDFromHex(cursorParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cursor",
+		})
+		return
+	}
+
+	// WriteTimeout сервера коротший за long-poll, тому продовжуємо його для цього запиту
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second))
+
+	deadline := time.Now().Add(timeout)
+	for {
+		// Реєструємось до запиту в базу, щоб не пропустити подію між запитом і очікуванням
+		wake, stopWaiting := h.hub.wait(topic)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		events, newCursor, err := fetch(ctx, cursor)
+		cancel()
+		if err != nil {
+			stopWaiting()
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching events",
+			})
+			return
+		}
+
+		remaining := time.Until(deadline)
+		if len(events) > 0 || remaining <= 0 {
+			stopWaiting()
+			c.JSON(http.StatusOK, gin.H{
+				"events": events,
+				"cursor": newCursor.Hex(),
+			})
+			return
+		}
+
+		if remaining > longPollRecheckInterval {
+			remaining = longPollRecheckInterval
+		}
+
+		select {
+		case <-wake:
+		case <-time.After(remaining):
+		case <-h.hub.stop:
+			// Сервер зупиняється: відповідаємо одразу, наступний запит прийде на інший екземпляр
+			stopWaiting()
+			c.JSON(http.StatusOK, gin.H{
+				"events": events,
+				"cursor": newCursor.Hex(),
+			})
+			return
+		case <-c.Request.Context().Done():
+			stopWaiting()
+			return
+		}
+		stopWaiting()
+	}
+}
+
+func (h *LongPollHandler) findAfter(ctx context.Context, collection *mongo.Collection, filter bson.M, cursor primitive.ObjectID, results interface{}) error {
+	filter["_id"] = bson.M{"$gt": cursor}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(longPollBatchSize)
+
+	mongoCursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	return mongoCursor.All(ctx, results)
+}
+
+func (h *LongPollHandler) latestID(ctx context.Context, collection *mongo.Collection, filter bson.M) (primitive.ObjectID, error) {
+	var latest struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err := collection.FindOne(ctx, filter, options.FindOne().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetProjection(bson.M{"_id": 1}),
+	).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, nil
+	}
+	return latest.ID, err
+}
@@ -0,0 +1,288 @@
+// internal/handlers/moderation_analytics.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Інтервали віку черги модерації
+var moderationBacklogBuckets = []struct {
+	Label string
+	Max   time.Duration // 0 - без верхньої межі
+}{
+	{Label: "under_1h", Max: time.Hour},
+	{Label: "1h_24h", Max: 24 * time.Hour},
+	{Label: "1d_3d", Max: 3 * 24 * time.Hour},
+	{Label: "3d_7d", Max: 7 * 24 * time.Hour},
+	{Label: "over_7d"},
+}
+
+// ModerationAnalyticsHandler - навантаження на команду модерації
+type ModerationAnalyticsHandler struct {
+	actionCollection       *mongo.Collection
+	userCollection         *mongo.Collection
+	announcementCollection *mongo.Collection
+	eventCollection        *mongo.Collection
+	messageCollection      *mongo.Collection
+}
+
+// NewModerationAnalyticsHandler створює обробник аналітики модерації
+func NewModerationAnalyticsHandler(actionCollection, userCollection, announcementCollection, eventCollection, messageCollection *mongo.Collection) *ModerationAnalyticsHandler {
+	return &ModerationAnalyticsHandler{
+		actionCollection:       actionCollection,
+		userCollection:         userCollection,
+		announcementCollection: announcementCollection,
+		eventCollection:        eventCollection,
+		messageCollection:      messageCollection,
+	}
+}
+
+// ModeratorWorkload - показники окремого модератора за період
+type ModeratorWorkload struct {
+	ModeratorID         primitive.ObjectID `bson:"_id" json:"moderator_id"`
+	Name                string             `bson:"-" json:"name"`
+	Reviewed            int64              `bson:"reviewed" json:"reviewed"`
+	Approved            int64              `bson:"approved" json:"approved"`
+	Rejected            int64              `bson:"rejected" json:"rejected"`
+	ApprovalRatio       float64            `bson:"-" json:"approval_ratio"`
+	AvgDecisionSeconds  float64            `bson:"avg_decision_seconds" json:"avg_decision_seconds"`
+	ByContentType       map[string]int64   `bson:"-" json:"by_content_type"`
+	ContentTypeCounters []struct {
+		Type  string `bson:"type"`
+		Count int64  `bson:"count"`
+	} `bson:"content_types" json:"-"`
+}
+
+// GetModerationStats - GET /analytics/moderation?from=2024-01-01&to=2024-01-31
+// Без параметрів повертає статистику за останні 30 днів.
+func (h *ModerationAnalyticsHandler) GetModerationStats(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseNotificationDate(value, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date",
+			})
+			return
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseNotificationDate(value, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date",
+			})
+			return
+		}
+		to = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must be before to",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	moderators, err := h.moderatorWorkload(ctx, communityScope(c, bson.M{
+		"decided_at": bson.M{"$gte": from, "$lt": to},
+	}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching moderation statistics",
+		})
+		return
+	}
+
+	var totals struct {
+		Reviewed int64 `json:"reviewed"`
+		Approved int64 `json:"approved"`
+		Rejected int64 `json:"rejected"`
+	}
+	for _, m := range moderators {
+		totals.Reviewed += m.Reviewed
+		totals.Approved += m.Approved
+		totals.Rejected += m.Rejected
+	}
+
+	backlog, err := h.backlogAges(ctx, c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching moderation backlog",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period": gin.H{
+			"from": from,
+			"to":   to,
+		},
+		"totals":     totals,
+		"moderators": moderators,
+		"backlog":    backlog,
+		"timestamp":  time.Now(),
+	})
+}
+
+func (h *ModerationAnalyticsHandler) moderatorWorkload(ctx context.Context, filter bson.M) ([]ModeratorWorkload, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"moderator_id": "$moderator_id", "type": "$content_type"},
+			"reviewed": bson.M{"$sum": 1},
+			"approved": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$decision", models.ModerationDecisionApproved}}, 1, 0,
+			}}},
+			"rejected": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$decision", models.ModerationDecisionRejected}}, 1, 0,
+			}}},
+			"decision_seconds": bson.M{"$sum": "$decision_seconds"},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              "$_id.moderator_id",
+			"reviewed":         bson.M{"$sum": "$reviewed"},
+			"approved":         bson.M{"$sum": "$approved"},
+			"rejected":         bson.M{"$sum": "$rejected"},
+			"decision_seconds": bson.M{"$sum": "$decision_seconds"},
+			"content_types":    bson.M{"$push": bson.M{"type": "$_id.type", "count": "$reviewed"}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"avg_decision_seconds": bson.M{"$divide": bson.A{"$decision_seconds", "$reviewed"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "reviewed", Value: -1}}}},
+	}
+
+	cursor, err := h.actionCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	moderators := []ModeratorWorkload{}
+	if err := cursor.All(ctx, &moderators); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(moderators))
+	for i := range moderators {
+		m := &moderators[i]
+		ids = append(ids, m.ModeratorID)
+		if m.Reviewed > 0 {
+			m.ApprovalRatio = float64(m.Approved) / float64(m.Reviewed)
+		}
+		m.ByContentType = make(map[string]int64, len(m.ContentTypeCounters))
+		for _, counter := range m.ContentTypeCounters {
+			m.ByContentType[counter.Type] = counter.Count
+		}
+	}
+
+	// Імена модераторів
+	if len(ids) > 0 {
+		userCursor, err := h.userCollection.Find(ctx,
+			bson.M{"_id": bson.M{"$in": ids}},
+			options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1}),
+		)
+		if err == nil {
+			var users []models.User
+			if err := userCursor.All(ctx, &users); err == nil {
+				names := make(map[primitive.ObjectID]string, len(users))
+				for _, user := range users {
+					names[user.ID] = user.GetFullName()
+				}
+				for i := range moderators {
+					moderators[i].Name = names[moderators[i].ModeratorID]
+				}
+			}
+		}
+	}
+
+	return moderators, nil
+}
+
+// backlogAges розподіляє контент, що очікує модерації, за віком
+func (h *ModerationAnalyticsHandler) backlogAges(ctx context.Context, c *gin.Context) (gin.H, error) {
+	queues := []struct {
+		Name       string
+		Collection *mongo.Collection
+		Filter     bson.M
+	}{
+		{Name: "announcements", Collection: h.announcementCollection, Filter: bson.M{"status": "pending"}},
+		{Name: "events", Collection: h.eventCollection, Filter: bson.M{"status": models.EventStatusPending}},
+		{Name: "messages", Collection: h.messageCollection, Filter: bson.M{"is_held": true, "is_deleted": false}},
+	}
+
+	now := time.Now()
+	total := make(map[string]int64, len(moderationBacklogBuckets))
+	byQueue := gin.H{}
+	var pending int64
+	var oldest *time.Time
+
+	for _, queue := range queues {
+		// Повідомлення не мають community_id, вони належать групам громади
+		filter := queue.Filter
+		if queue.Name != "messages" {
+			filter = communityScope(c, queue.Filter)
+		}
+
+		buckets := make(map[string]int64, len(moderationBacklogBuckets))
+		var lower time.Duration
+		for _, bucket := range moderationBacklogBuckets {
+			createdAt := bson.M{"$lte": now.Add(-lower)}
+			if bucket.Max > 0 {
+				createdAt["$gt"] = now.Add(-bucket.Max)
+			}
+
+			bucketFilter := bson.M{"created_at": createdAt}
+			for key, value := range filter {
+				bucketFilter[key] = value
+			}
+
+			count, err := queue.Collection.CountDocuments(ctx, bucketFilter)
+			if err != nil {
+				return nil, err
+			}
+			buckets[bucket.Label] = count
+			total[bucket.Label] += count
+			pending += count
+			lower = bucket.Max
+		}
+		byQueue[queue.Name] = buckets
+
+		var first struct {
+			CreatedAt time.Time `bson:"created_at"`
+		}
+		err := queue.Collection.FindOne(ctx, filter, options.FindOne().
+			SetSort(bson.D{{Key: "created_at", Value: 1}}).
+			SetProjection(bson.M{"created_at": 1}),
+		).Decode(&first)
+		if err == nil && (oldest == nil || first.CreatedAt.Before(*oldest)) {
+			oldest = &first.CreatedAt
+		}
+	}
+
+	backlog := gin.H{
+		"pending":  pending,
+		"buckets":  total,
+		"by_queue": byQueue,
+	}
+	if oldest != nil {
+		backlog["oldest_age_seconds"] = int64(now.Sub(*oldest).Seconds())
+	}
+	return backlog, nil
+}
@@ -0,0 +1,213 @@
+// internal/handlers/moderation_scope.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ModerationScopeRequest - зона відповідальності модератора; порожні списки знімають обмеження
+type ModerationScopeRequest struct {
+	Neighborhoods []string `json:"neighborhoods"`
+	Categories    []string `json:"categories"` // Коди категорій таксономії
+}
+
+// moderationScope - зона поточного модератора; nil - модератор діє в усьому місті
+func moderationScope(c *gin.Context) *models.ModerationScope {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return nil
+	}
+	return user.Scope()
+}
+
+// inModerationScope перевіряє, що модераторська дія стосується контенту з зони модератора.
+// Поза зоною відповідає 403 з кодом OUT_OF_MODERATION_SCOPE.
+func inModerationScope(c *gin.Context, neighborhood, category string) bool {
+	if moderationScope(c).Allows(neighborhood, category) {
+		return true
+	}
+	respondOutOfModerationScope(c)
+	return false
+}
+
+func respondOutOfModerationScope(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "Content is outside your moderation scope",
+		"code":  middleware.OutOfModerationScopeCode,
+	})
+}
+
+// moderationScopeCondition - умова запиту, яку задовольняє контент із зони
+func moderationScopeCondition(scope *models.ModerationScope) bson.M {
+	condition := bson.M{}
+	if len(scope.Neighborhoods) > 0 {
+		condition["neighborhood"] = bson.M{"$in": scope.Neighborhoods}
+	}
+	if len(scope.Categories) > 0 {
+		condition["category"] = bson.M{"$in": scope.Categories}
+	}
+	return condition
+}
+
+// withModerationScope обмежує фільтр черги модерації або модераторської дії зоною
+// поточного модератора. Умова додається через $and, щоб не перекрити фільтр категорії з запиту.
+func withModerationScope(c *gin.Context, filter bson.M) bson.M {
+	scope := moderationScope(c)
+	if scope == nil {
+		return filter
+	}
+	return bson.M{"$and": []bson.M{filter, moderationScopeCondition(scope)}}
+}
+
+// respondOutOfScope розрізняє причину ErrNoDocuments для фільтра withModerationScope:
+// якщо документ існує, але поза зоною, відповідає 403 і повертає true
+func respondOutOfScope(ctx context.Context, c *gin.Context, collection *mongo.Collection, id primitive.ObjectID) bool {
+	if moderationScope(c) == nil {
+		return false
+	}
+	count, err := collection.CountDocuments(ctx, communityScope(c, bson.M{"_id": id}), options.Count().SetLimit(1))
+	if err != nil || count == 0 {
+		return false
+	}
+	respondOutOfModerationScope(c)
+	return true
+}
+
+// normalizeNeighborhood обрізає пробіли в назві мікрорайону та перевіряє довжину
+func normalizeNeighborhood(c *gin.Context, neighborhood string) (string, bool) {
+	neighborhood = strings.TrimSpace(neighborhood)
+	if len([]rune(neighborhood)) > models.MaxNeighborhoodLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": fmt.Sprintf("neighborhood must be at most %d characters", models.MaxNeighborhoodLength),
+		})
+		return "", false
+	}
+	return neighborhood, true
+}
+
+// normalizeScopeValues обрізає пробіли, прибирає порожні значення та дублікати
+func normalizeScopeValues(values []string, maxLength int) ([]string, error) {
+	if len(values) > models.MaxModerationScopeEntries {
+		return nil, fmt.Errorf("at most %d entries are allowed", models.MaxModerationScopeEntries)
+	}
+
+	seen := make(map[string]bool, len(values))
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		if len([]rune(value)) > maxLength {
+			return nil, fmt.Errorf("%q is longer than %d characters", value, maxLength)
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// SetModerationScope обмежує модератора мікрорайонами та/або категоріями.
+// Зона діє лише для ролі MODERATOR і застосовується з наступного запиту модератора.
+// 🔒 Вимагає права: ADMIN+
+// Метод: PUT /api/v1/admin/users/:id/moderation-scope
+func (h *UsersHandler) SetModerationScope(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req ModerationScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	neighborhoods, err := normalizeScopeValues(req.Neighborhoods, models.MaxNeighborhoodLength)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid neighborhoods",
+			"details": err.Error(),
+		})
+		return
+	}
+	categories, err := normalizeScopeValues(req.Categories, 50) // Як код у POST /admin/taxonomies
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid categories",
+			"details": err.Error(),
+		})
+		return
+	}
+	scope := &models.ModerationScope{Neighborhoods: neighborhoods, Categories: categories}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"moderation_scope": scope,
+			"updated_at":       time.Now(),
+		},
+	}
+	if scope.IsEmpty() {
+		update = bson.M{
+			"$unset": bson.M{"moderation_scope": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		}
+	}
+
+	var user models.User
+	err = h.userCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": userID},
+		update,
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"role": 1, "moderation_scope": 1}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating moderation scope",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// AuthMiddleware бере зону з кешу статусів
+	h.userStatus.Invalidate(userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Moderation scope updated successfully",
+		"moderation_scope": scope,
+		"applies":          user.GetRole() == models.RoleModerator, // Для інших ролей зона не діє
+	})
+}
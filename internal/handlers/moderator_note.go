@@ -0,0 +1,440 @@
+// internal/handlers/moderator_note.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ModeratorNoteHandler - внутрішні нотатки модераторів до контенту та користувачів
+// 🔒 Всі методи вимагають ролі MODERATOR
+type ModeratorNoteHandler struct {
+	db                  *mongo.Database
+	noteCollection      *mongo.Collection
+	userCollection      *mongo.Collection
+	notificationService *services.NotificationService
+}
+
+// NewModeratorNoteHandler створює обробник нотаток модераторів
+func NewModeratorNoteHandler(db *mongo.Database, noteCollection, userCollection *mongo.Collection, notificationService *services.NotificationService) *ModeratorNoteHandler {
+	return &ModeratorNoteHandler{
+		db:                  db,
+		noteCollection:      noteCollection,
+		userCollection:      userCollection,
+		notificationService: notificationService,
+	}
+}
+
+// CreateModeratorNoteRequest - нова нотатка до сутності
+type CreateModeratorNoteRequest struct {
+	EntityType string   `json:"entity_type" binding:"required,oneof=city_issue petition announcement event poll message user"`
+	EntityID   string   `json:"entity_id" binding:"required"`
+	Body       string   `json:"body" binding:"required,max=2000"`
+	Mentions   []string `json:"mentions" binding:"max=20"` // ID модераторів та адміністраторів
+}
+
+// UpdateModeratorNoteRequest - правка нотатки автором
+type UpdateModeratorNoteRequest struct {
+	Body     string   `json:"body" binding:"required,max=2000"`
+	Mentions []string `json:"mentions" binding:"max=20"`
+}
+
+// GetNotes - GET /moderation/notes?entity_type=&entity_id=
+// Обговорення сутності від старих нотаток до нових
+func (h *ModeratorNoteHandler) GetNotes(c *gin.Context) {
+	entityType := c.Query("entity_type")
+	if _, ok := models.NoteEntityCollections[entityType]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid entity type",
+		})
+		return
+	}
+	entityID, err := primitive.ObjectIDFromHex(c.Query("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid entity ID",
+		})
+		return
+	}
+
+	page, limit := pageParams(c, 50, 100)
+	filter := communityScope(c, bson.M{"entity_type": entityType, "entity_id": entityID})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit))
+
+	h.writeNotes(ctx, c, filter, opts, page, limit)
+}
+
+// GetMentions - GET /moderation/notes/mentions
+// Нотатки, в яких згадано поточного модератора, від нових до старих
+func (h *ModeratorNoteHandler) GetMentions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	page, limit := pageParams(c, 20, 100)
+	filter := communityScope(c, bson.M{"mentions": userID})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit))
+
+	h.writeNotes(ctx, c, filter, opts, page, limit)
+}
+
+func (h *ModeratorNoteHandler) writeNotes(ctx context.Context, c *gin.Context, filter bson.M, opts *options.FindOptions, page, limit int) {
+	cursor, err := h.noteCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching notes",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	notes := []models.ModeratorNote{}
+	if err := cursor.All(ctx, &notes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding notes",
+		})
+		return
+	}
+
+	total, _ := h.noteCollection.CountDocuments(ctx, filter)
+
+	c.JSON(http.StatusOK, listResponse(c, notes, newPageInfo(page, limit, total), nil, nil))
+}
+
+// CreateNote - POST /moderation/notes
+// Згадані модератори отримують сповіщення з посиланням на сутність
+func (h *ModeratorNoteHandler) CreateNote(c *gin.Context) {
+	var req CreateModeratorNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	entityID, err := primitive.ObjectIDFromHex(req.EntityID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid entity ID",
+		})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Note body is required",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := h.db.Collection(models.NoteEntityCollections[req.EntityType]).CountDocuments(ctx, communityScope(c, bson.M{"_id": entityID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Entity not found",
+		})
+		return
+	}
+
+	mentions, ok := h.resolveMentions(ctx, c, req.Mentions, userID)
+	if !ok {
+		return
+	}
+
+	var author models.User
+	if err := h.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&author); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching user",
+		})
+		return
+	}
+
+	note := models.ModeratorNote{
+		ID:          primitive.NewObjectID(),
+		CommunityID: getCommunityID(c),
+		EntityType:  req.EntityType,
+		EntityID:    entityID,
+		AuthorID:    userID,
+		AuthorName:  strings.TrimSpace(author.FirstName + " " + author.LastName),
+		Body:        body,
+		Mentions:    mentions,
+		CreatedAt:   time.Now(),
+	}
+	// Сповіщення згаданим записуються в outbox разом із нотаткою
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := h.noteCollection.InsertOne(ctx, note); err != nil {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, mentionIntent(note, mentions))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error saving note",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// UpdateNote - PUT /moderation/notes/:id
+// Править лише автор; сповіщення отримують тільки нові згадані
+func (h *ModeratorNoteHandler) UpdateNote(c *gin.Context) {
+	noteID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note ID",
+		})
+		return
+	}
+
+	var req UpdateModeratorNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Note body is required",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mentions, ok := h.resolveMentions(ctx, c, req.Mentions, userID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	var note models.ModeratorNote
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var previous models.ModeratorNote
+		err := h.noteCollection.FindOneAndUpdate(ctx,
+			communityScope(c, bson.M{"_id": noteID, "author_id": userID}),
+			bson.M{"$set": bson.M{
+				"body":      body,
+				"mentions":  mentions,
+				"edited_at": now,
+			}},
+		).Decode(&previous)
+		if err != nil {
+			return err
+		}
+
+		already := make(map[primitive.ObjectID]bool, len(previous.Mentions))
+		for _, id := range previous.Mentions {
+			already[id] = true
+		}
+		var added []primitive.ObjectID
+		for _, id := range mentions {
+			if !already[id] {
+				added = append(added, id)
+			}
+		}
+
+		note = previous
+		note.Body = body
+		note.Mentions = mentions
+		note.EditedAt = &now
+		return h.notificationService.Enqueue(ctx, mentionIntent(note, added))
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Note not found or you are not its author",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating note",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNote - DELETE /moderation/notes/:id
+// Видаляє автор або адміністратор
+func (h *ModeratorNoteHandler) DeleteNote(c *gin.Context) {
+	noteID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid note ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	filter := communityScope(c, bson.M{"_id": noteID})
+	if claims, ok := middleware.CurrentUser(c); !ok || !claims.Role.IsHigherOrEqual(models.RoleAdmin) {
+		filter["author_id"] = userID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.noteCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting note",
+		})
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Note not found or you are not its author",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Note deleted",
+	})
+}
+
+// resolveMentions перевіряє, що згадані користувачі - модератори або адміністратори.
+// Автор себе не згадує. false - відповідь з помилкою вже надіслана.
+func (h *ModeratorNoteHandler) resolveMentions(ctx context.Context, c *gin.Context, raw []string, authorID primitive.ObjectID) ([]primitive.ObjectID, bool) {
+	seen := make(map[primitive.ObjectID]bool, len(raw))
+	mentions := []primitive.ObjectID{}
+	for _, value := range raw {
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid mentioned user ID",
+			})
+			return nil, false
+		}
+		if id == authorID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		mentions = append(mentions, id)
+	}
+	if len(mentions) == 0 {
+		return mentions, true
+	}
+
+	var staffRoles []string
+	for _, role := range models.AllRoles() {
+		if role.IsHigherOrEqual(models.RoleModerator) {
+			staffRoles = append(staffRoles, string(role))
+		}
+	}
+
+	count, err := h.userCollection.CountDocuments(ctx, bson.M{
+		"_id":        bson.M{"$in": mentions},
+		"role":       bson.M{"$in": staffRoles},
+		"is_blocked": bson.M{"$ne": true},
+		"is_deleted": bson.M{"$ne": true},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error checking mentioned users",
+		})
+		return nil, false
+	}
+	if count != int64(len(mentions)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Mentioned users must be moderators or admins",
+			"details": "Only active moderators and admins can be mentioned in internal notes",
+		})
+		return nil, false
+	}
+	return mentions, true
+}
+
+// mentionIntent - сповіщення згаданим модераторам з посиланням на сутність
+func mentionIntent(note models.ModeratorNote, userIDs []primitive.ObjectID) services.NotificationIntent {
+	preview := []rune(note.Body)
+	if len(preview) > 100 {
+		preview = append(preview[:97], []rune("...")...)
+	}
+
+	return services.NotificationIntent{
+		Source:  "moderator_note.mentioned",
+		UserIDs: userIDs,
+		Title:   fmt.Sprintf("%s згадує вас у нотатці модераторів", note.AuthorName),
+		Body:    string(preview),
+		Type:    services.NotificationTypeSystem,
+		Data: map[string]interface{}{
+			"note_id":     note.ID.Hex(),
+			"entity_type": note.EntityType,
+			"entity_id":   note.EntityID.Hex(),
+			"action":      "open_moderator_note",
+		},
+		RelatedID: &note.EntityID,
+	}
+}
@@ -3,11 +3,13 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"nova-kakhovka-ecity/internal/models"
-	"strconv"
+	"strings"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,7 @@ type NotificationHandler struct {
 	notificationCollection *mongo.Collection
 	deviceTokenCollection  *mongo.Collection
 	userCollection         *mongo.Collection
+	campaignService        *services.CampaignService
 }
 
 type RegisterDeviceTokenRequest struct {
@@ -35,23 +38,33 @@ type SendNotificationRequest struct {
 	Body    string                 `json:"body" validate:"required,max=500"`
 	Type    string                 `json:"type" validate:"required,oneof=message event announcement system emergency"`
 	Data    map[string]interface{} `json:"data,omitempty"`
+	// Надіслати push одразу, попри тихі години отримувачів (лише для type=emergency)
+	OverrideQuietHours bool `json:"override_quiet_hours"`
+	// Необов'язкові plain_text_body і pronunciations для читачів екрана
+	models.SpeechHints
 }
 
 type SendEmergencyNotificationRequest struct {
 	Title string                 `json:"title" validate:"required,max=100"`
 	Body  string                 `json:"body" validate:"required,max=500"`
 	Data  map[string]interface{} `json:"data,omitempty"`
+	// За замовчуванням екстрене сповіщення надсилається попри тихі години;
+	// false - push мешканцям у тихі години надійде зранку
+	OverrideQuietHours *bool `json:"override_quiet_hours"`
+	models.SpeechHints
 }
 
 func NewNotificationHandler(
 	notificationService *services.NotificationService,
 	notificationCollection, deviceTokenCollection *mongo.Collection,
+	campaignService *services.CampaignService,
 ) *NotificationHandler {
 	return &NotificationHandler{
 		notificationService:    notificationService,
 		notificationCollection: notificationCollection,
 		deviceTokenCollection:  deviceTokenCollection,
 		userCollection:         notificationCollection.Database().Collection("users"),
+		campaignService:        campaignService,
 	}
 }
 
@@ -65,26 +78,17 @@ func (h *NotificationHandler) GetUserNotifications(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit := pageParams(c, 20, 50)
 	unreadOnly := c.DefaultQuery("unread_only", "false") == "true"
 
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 50 {
-		limit = 20
-	}
-
 	filter := bson.M{"user_id": userIDObj}
 	if unreadOnly {
 		filter["is_read"] = false
 	}
 
-	skip := (page - 1) * limit
 	opts := options.Find().
 		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
+		SetSkip(pageSkip(page, limit)).
 		SetSort(bson.D{{"created_at", -1}})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -116,14 +120,16 @@ func (h *NotificationHandler) GetUserNotifications(c *gin.Context) {
 		unreadCount = 0
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notifications,
-		"unread_count":  unreadCount,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-		},
-	})
+	total, err := h.notificationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		total = 0
+	}
+	info := newPageInfo(page, limit, total)
+
+	c.JSON(http.StatusOK, listResponse(c, notifications, info,
+		gin.H{"unread_count": unreadCount},
+		gin.H{"notifications": notifications, "pagination": info},
+	))
 }
 
 func (h *NotificationHandler) MarkNotificationAsRead(c *gin.Context) {
@@ -226,15 +232,6 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	// Преобразуем строки в ObjectID
 	var userIDs []primitive.ObjectID
 	for _, userIDStr := range req.UserIDs {
@@ -252,10 +249,30 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 		return
 	}
 
+	if req.OverrideQuietHours && req.Type != services.NotificationTypeEmergency {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Quiet hours can be overridden only for emergency notifications",
+		})
+		return
+	}
+
+	hints, err := services.NormalizeSpeechHints(req.SpeechHints)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid speech hints",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	err := h.notificationService.SendNotificationToUsers(ctx, userIDs, req.Title, req.Body, req.Type, req.Data, nil)
+	if req.OverrideQuietHours {
+		err = h.notificationService.SendNotificationIgnoringQuietHours(ctx, userIDs, req.Title, req.Body, req.Type, req.Data, hints)
+	} else {
+		err = h.notificationService.SendNotificationToUsersWithHints(ctx, userIDs, req.Title, req.Body, req.Type, req.Data, nil, hints)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error sending notification",
@@ -280,11 +297,11 @@ func (h *NotificationHandler) SendEmergencyNotification(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
+	hints, err := services.NormalizeSpeechHints(req.SpeechHints)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid speech hints",
+			"details": err.Error(),
 		})
 		return
 	}
@@ -292,7 +309,8 @@ func (h *NotificationHandler) SendEmergencyNotification(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	err := h.notificationService.SendEmergencyNotification(ctx, req.Title, req.Body, req.Data)
+	overrideQuietHours := req.OverrideQuietHours == nil || *req.OverrideQuietHours
+	err = h.notificationService.SendEmergencyNotification(ctx, req.Title, req.Body, req.Data, hints, overrideQuietHours)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error sending emergency notification",
@@ -317,17 +335,17 @@ func (h *NotificationHandler) GetNotificationTypes(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"notification_types": types,
+		"categories":         models.NotificationCategories(),
 	})
 }
 
 // Дополнительные методы для работы с уведомлениями
 
 func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	// Проверяем право на отправку уведомлений
+	if !middleware.UserHasPermission(c, models.PermissionSendNotifications) {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
+			"error": "Insufficient permissions",
 		})
 		return
 	}
@@ -389,11 +407,10 @@ func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 }
 
 func (h *NotificationHandler) CleanupOldNotifications(c *gin.Context) {
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	// Проверяем право на отправку уведомлений
+	if !middleware.UserHasPermission(c, models.PermissionSendNotifications) {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
+			"error": "Insufficient permissions",
 		})
 		return
 	}
@@ -424,11 +441,10 @@ func (h *NotificationHandler) CleanupOldNotifications(c *gin.Context) {
 }
 
 func (h *NotificationHandler) SendTestNotification(c *gin.Context) {
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	// Проверяем право на отправку уведомлений
+	if !middleware.UserHasPermission(c, models.PermissionSendNotifications) {
 		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
+			"error": "Insufficient permissions",
 		})
 		return
 	}
@@ -493,16 +509,17 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	}
 
 	// Параметри запиту
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, limit := pageParams(c, 20, 100)
 	unreadOnly := c.Query("unread_only") == "true"
 	notificationType := c.Query("type")
+	module := c.Query("module")
+	category := c.Query("category")
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+	if category != "" && !models.IsValidNotificationCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid notification category",
+		})
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -517,8 +534,48 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		filter["is_read"] = false
 	}
 
+	// Кілька типів можна передати через кому: ?type=event,poll
 	if notificationType != "" {
-		filter["type"] = notificationType
+		filter["type"] = bson.M{"$in": strings.Split(notificationType, ",")}
+	}
+
+	var conditions []bson.M
+	if module != "" {
+		conditions = append(conditions, notificationModuleFilter(module))
+	}
+	if category != "" {
+		conditions = append(conditions, notificationCategoryFilter(category))
+	}
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
+	}
+
+	// Фільтр за датою: ?from=2024-01-01&to=2024-01-31 (або RFC3339)
+	dateFilter := bson.M{}
+	if from := c.Query("from"); from != "" {
+		fromTime, err := parseNotificationDate(from, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid 'from' date",
+				"details": err.Error(),
+			})
+			return
+		}
+		dateFilter["$gte"] = fromTime
+	}
+	if to := c.Query("to"); to != "" {
+		toTime, err := parseNotificationDate(to, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid 'to' date",
+				"details": err.Error(),
+			})
+			return
+		}
+		dateFilter["$lt"] = toTime
+	}
+	if len(dateFilter) > 0 {
+		filter["created_at"] = dateFilter
 	}
 
 	// Підрахунок загальної кількості
@@ -555,7 +612,7 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	}
 	defer cursor.Close(ctx)
 
-	var notifications []models.Notification
+	notifications := []models.Notification{}
 	if err := cursor.All(ctx, &notifications); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error decoding notifications",
@@ -564,16 +621,134 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notifications,
-		"unread_count":  unreadCount,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + int64(limit) - 1) / int64(limit),
+	// Сповіщення, збережені до появи категорій, класифікуємо на льоту
+	for i := range notifications {
+		if notifications[i].Module == "" {
+			notifications[i].Module = models.ResolveNotificationModule(notifications[i].Type, notifications[i].Data)
+		}
+		if notifications[i].Category == "" {
+			notifications[i].Category = models.ResolveNotificationCategory(notifications[i].Type, notifications[i].Module)
+		}
+	}
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, notifications, info,
+		gin.H{
+			"unread_count": unreadCount,
+			"categories":   h.getCategoryCounts(ctx, userIDObj),
 		},
+		gin.H{"notifications": notifications, "pagination": info},
+	))
+}
+
+// getCategoryCounts повертає вкладки інбоксу з кількістю всіх та непрочитаних сповіщень
+func (h *NotificationHandler) getCategoryCounts(ctx context.Context, userID primitive.ObjectID) []gin.H {
+	totals := map[string]int{}
+	unread := map[string]int{}
+
+	cursor, err := h.notificationCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"category": "$category",
+				"type":     "$type",
+				"module":   "$module",
+			},
+			"total": bson.M{"$sum": 1},
+			"unread": bson.M{"$sum": bson.M{
+				"$cond": bson.A{"$is_read", 0, 1},
+			}},
+		}}},
 	})
+	if err == nil {
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var row struct {
+				ID struct {
+					Category string `bson:"category"`
+					Type     string `bson:"type"`
+					Module   string `bson:"module"`
+				} `bson:"_id"`
+				Total  int `bson:"total"`
+				Unread int `bson:"unread"`
+			}
+			if err := cursor.Decode(&row); err != nil {
+				continue
+			}
+			category := row.ID.Category
+			if category == "" {
+				category = models.ResolveNotificationCategory(row.ID.Type, row.ID.Module)
+			}
+			totals[category] += row.Total
+			unread[category] += row.Unread
+		}
+	}
+
+	categories := []gin.H{}
+	for _, category := range models.NotificationCategories() {
+		categories = append(categories, gin.H{
+			"key":          category.Key,
+			"label":        category.Label,
+			"total":        totals[category.Key],
+			"unread_count": unread[category.Key],
+		})
+	}
+	return categories
+}
+
+// notificationCategoryFilter - фільтр за категорією з урахуванням старих сповіщень без поля category
+func notificationCategoryFilter(category string) bson.M {
+	var legacyTypes []string
+	for _, c := range models.NotificationCategories() {
+		if c.Key == category {
+			legacyTypes = c.Types
+		}
+	}
+
+	return bson.M{"$or": []bson.M{
+		{"category": category},
+		{
+			"category": bson.M{"$exists": false},
+			"type":     bson.M{"$in": legacyTypes},
+		},
+	}}
+}
+
+// notificationModuleFilter - фільтр за модулем з урахуванням старих сповіщень без поля module
+func notificationModuleFilter(module string) bson.M {
+	var legacyTypes []string
+	for _, c := range models.NotificationCategories() {
+		for _, t := range c.Types {
+			if models.ResolveNotificationModule(t, nil) == module {
+				legacyTypes = append(legacyTypes, t)
+			}
+		}
+	}
+
+	return bson.M{"$or": []bson.M{
+		{"module": module},
+		{
+			"module": bson.M{"$exists": false},
+			"type":   bson.M{"$in": legacyTypes},
+		},
+	}}
+}
+
+// parseNotificationDate розбирає дату у форматі RFC3339 або YYYY-MM-DD.
+// Для кінця періоду дата без часу включає весь день.
+func parseNotificationDate(value string, endOfPeriod bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfPeriod {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
 }
 
 // MarkAsRead позначає сповіщення як прочитане
@@ -608,7 +783,8 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 	defer cancel()
 
 	// Оновлюємо тільки якщо сповіщення належить користувачу
-	result, err := h.notificationCollection.UpdateOne(
+	var notification services.StoredNotification
+	err = h.notificationCollection.FindOneAndUpdate(
 		ctx,
 		bson.M{
 			"_id":     notificationID,
@@ -620,8 +796,14 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 				"read_at": time.Now(),
 			},
 		},
-	)
+	).Decode(&notification)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Notification not found or access denied",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error marking notification as read",
 			"details": err.Error(),
@@ -629,11 +811,15 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Notification not found or access denied",
-		})
-		return
+	// Відкриття сповіщення кампанії враховується в A/B тесті (лише перше прочитання)
+	if !notification.IsRead && notification.Type == services.NotificationTypeCampaign && h.campaignService != nil {
+		if campaignHex, ok := notification.Data["campaign_id"].(string); ok {
+			if campaignID, err := primitive.ObjectIDFromHex(campaignHex); err == nil {
+				if err := h.campaignService.TrackOpen(ctx, campaignID, userIDObj); err != nil {
+					log.Printf("Error tracking campaign open: %v", err)
+				}
+			}
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -975,8 +1161,10 @@ func (h *NotificationHandler) GetPreferences(c *gin.Context) {
 		}
 	}
 
+	// Тихі години міста діють, поки користувач не задав власні
 	c.JSON(http.StatusOK, gin.H{
-		"preferences": preferences,
+		"preferences":         preferences,
+		"default_quiet_hours": h.notificationService.DefaultQuietHours(),
 	})
 }
 
@@ -992,6 +1180,9 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 		CityIssues    *bool `json:"city_issues,omitempty"`
 		Polls         *bool `json:"polls,omitempty"`
 		Petitions     *bool `json:"petitions,omitempty"`
+		// Власні тихі години; use_default_quiet_hours=true повертає тихі години міста
+		QuietHours           *models.QuietHours `json:"quiet_hours,omitempty"`
+		UseDefaultQuietHours bool               `json:"use_default_quiet_hours,omitempty"`
 	}
 
 	var req PreferencesRequest
@@ -1020,11 +1211,28 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 		return
 	}
 
+	if req.QuietHours != nil && req.UseDefaultQuietHours {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Use either quiet_hours or use_default_quiet_hours",
+		})
+		return
+	}
+	if req.QuietHours != nil {
+		if err := req.QuietHours.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid quiet hours",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Формуємо оновлення
 	update := bson.M{}
+	unset := bson.M{}
 
 	if req.Email != nil {
 		update["notification_preferences.email"] = *req.Email
@@ -1053,8 +1261,14 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 	if req.Petitions != nil {
 		update["notification_preferences.petitions"] = *req.Petitions
 	}
+	if req.QuietHours != nil {
+		update["notification_preferences.quiet_hours"] = req.QuietHours
+	}
+	if req.UseDefaultQuietHours {
+		unset["notification_preferences.quiet_hours"] = ""
+	}
 
-	if len(update) == 0 {
+	if len(update) == 0 && len(unset) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "No preferences to update",
 		})
@@ -1062,12 +1276,16 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 	}
 
 	update["updated_at"] = time.Now()
+	changes := bson.M{"$set": update}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
 
 	// Оновлюємо налаштування
 	_, err = h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": userIDObj},
-		bson.M{"$set": update},
+		changes,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,152 @@
+// internal/handlers/notification_outbox.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationOutboxHandler - перегляд і повторна постановка намірів сповіщень з outbox
+// 🔒 Всі методи вимагають ролі ADMIN
+type NotificationOutboxHandler struct {
+	outboxCollection    *mongo.Collection
+	notificationService *services.NotificationService
+}
+
+// NewNotificationOutboxHandler створює обробник outbox сповіщень
+func NewNotificationOutboxHandler(outboxCollection *mongo.Collection, notificationService *services.NotificationService) *NotificationOutboxHandler {
+	return &NotificationOutboxHandler{
+		outboxCollection:    outboxCollection,
+		notificationService: notificationService,
+	}
+}
+
+// GetOutbox повертає кількість намірів за статусами та список за фільтром.
+// За замовчуванням (status=stuck) - наміри, що потребують уваги: відмова, повтори, завислі.
+func (h *NotificationOutboxHandler) GetOutbox(c *gin.Context) {
+	page, limit := pageParams(c, 50, 200)
+
+	now := time.Now()
+	var filter bson.M
+	switch status := c.DefaultQuery("status", "stuck"); status {
+	case "stuck":
+		filter = services.OutboxStuckFilter(now)
+	case services.OutboxStatusPending, services.OutboxStatusProcessing,
+		services.OutboxStatusDelivered, services.OutboxStatusFailed:
+		filter = bson.M{"status": status}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Status must be stuck, pending, processing, delivered or failed",
+		})
+		return
+	}
+	if source := c.Query("source"); source != "" {
+		filter = bson.M{"$and": []bson.M{filter, {"source": source}}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.outboxCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error calculating outbox stats",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	stats := map[string]int{
+		services.OutboxStatusPending:    0,
+		services.OutboxStatusProcessing: 0,
+		services.OutboxStatusDelivered:  0,
+		services.OutboxStatusFailed:     0,
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err == nil {
+			stats[row.ID] = row.Count
+		}
+	}
+	stuck, _ := h.outboxCollection.CountDocuments(ctx, services.OutboxStuckFilter(now))
+
+	// Найстаріші наміри першими: вони чекають найдовше
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	listCursor, err := h.outboxCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching outbox",
+		})
+		return
+	}
+	defer listCursor.Close(ctx)
+
+	intents := []services.NotificationIntent{}
+	if err := listCursor.All(ctx, &intents); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding outbox",
+		})
+		return
+	}
+
+	total, _ := h.outboxCollection.CountDocuments(ctx, filter)
+
+	c.JSON(http.StatusOK, listResponse(c, intents, newPageInfo(page, limit, total), gin.H{
+		"stats": stats,
+		"stuck": stuck,
+	}, nil))
+}
+
+// RetryIntent повертає відмовлений або завислий намір у чергу з новим лічильником спроб
+func (h *NotificationOutboxHandler) RetryIntent(c *gin.Context) {
+	intentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid intent ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	requeued, err := h.notificationService.RequeueIntent(ctx, intentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error requeueing intent",
+		})
+		return
+	}
+	if !requeued {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Failed or stuck intent not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Intent requeued",
+	})
+}
@@ -0,0 +1,124 @@
+// internal/handlers/pagination.go
+
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"nova-kakhovka-ecity/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PageInfo - метадані сторінки у відповідях списків ({items, page_info})
+type PageInfo struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
+}
+
+func newPageInfo(page, limit int, total int64) PageInfo {
+	totalPages := int64(0)
+	if limit > 0 {
+		totalPages = (total + int64(limit) - 1) / int64(limit)
+	}
+	return PageInfo{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    int64(page) < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+// CursorInfo - метадані сторінки за курсором ({items, cursor_info}). Сторінка не зсувається,
+// коли з'являються нові елементи: before і after передаються в наступний запит.
+type CursorInfo struct {
+	Limit    int    `json:"limit"`
+	Before   string `json:"before,omitempty"` // ID найстарішого елемента сторінки - для старших
+	After    string `json:"after,omitempty"`  // ID найновішого елемента сторінки - для новіших
+	HasOlder bool   `json:"has_older"`
+	HasNewer bool   `json:"has_newer"`
+}
+
+// cursorParams читає курсори before і after (ID елементів); разом їх передавати не можна.
+// При помилці відповідь уже записана.
+func cursorParams(c *gin.Context) (before, after *primitive.ObjectID, ok bool) {
+	if before, ok = cursorParam(c, "before"); !ok {
+		return nil, nil, false
+	}
+	if after, ok = cursorParam(c, "after"); !ok {
+		return nil, nil, false
+	}
+	if before != nil && after != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Use either before or after, not both",
+		})
+		return nil, nil, false
+	}
+	return before, after, true
+}
+
+// cursorParam - курсор name із запиту; nil, якщо його не передано
+func cursorParam(c *gin.Context, name string) (*primitive.ObjectID, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + name + " cursor",
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+// pageParams читає page і limit із запиту; некоректні значення замінюються на 1 і defaultLimit
+func pageParams(c *gin.Context, defaultLimit, maxLimit int) (page, limit int) {
+	page, _ = strconv.Atoi(c.Query("page"))
+	limit, _ = strconv.Atoi(c.Query("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxLimit {
+		limit = defaultLimit
+	}
+	return page, limit
+}
+
+// pageSkip - кількість документів, які пропускаються до сторінки page
+func pageSkip(page, limit int) int64 {
+	return int64((page - 1) * limit)
+}
+
+// listResponse - відповідь списку {items, page_info, ...extra}.
+// Ключі legacy (попередній формат endpoint'а) додаються, поки клієнт не перейшов на page_info.
+func listResponse(c *gin.Context, items interface{}, info PageInfo, extra, legacy gin.H) gin.H {
+	// Порожній список - [] замість null
+	if value := reflect.ValueOf(items); value.Kind() == reflect.Slice && value.IsNil() {
+		items = []interface{}{}
+	}
+
+	response := gin.H{
+		"items":     items,
+		"page_info": info,
+	}
+	for key, value := range extra {
+		response[key] = value
+	}
+	if middleware.LegacyPagination(c) {
+		for key, value := range legacy {
+			response[key] = value
+		}
+	}
+	return response
+}
@@ -4,10 +4,11 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
-	"strconv"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
 	"nova-kakhovka-ecity/internal/services"
 
@@ -22,12 +23,17 @@ type PetitionHandler struct {
 	petitionCollection  *mongo.Collection
 	userCollection      *mongo.Collection
 	notificationService *services.NotificationService
+	taxonomyService     *services.TaxonomyService
+	tagService          *services.TagService
+	revisionService     *services.RevisionService
+	thresholdService    *services.PetitionThresholdService
+	relatedService      *services.RelatedContentService
 }
 
 type CreatePetitionRequest struct {
 	Title              string    `json:"title" validate:"required,min=10,max=300"`
 	Description        string    `json:"description" validate:"required,min=50,max=5000"`
-	Category           string    `json:"category" validate:"required,oneof=infrastructure social environment economy governance safety transport education healthcare"`
+	Category           string    `json:"category" validate:"required"` // Код із таксономії модуля petitions
 	RequiredSignatures int       `json:"required_signatures" validate:"min=100"`
 	Demands            string    `json:"demands" validate:"required,min=20,max=2000"`
 	EndDate            time.Time `json:"end_date" validate:"required"`
@@ -68,11 +74,16 @@ type PetitionFilters struct {
 	GoalReached   *bool     `form:"goal_reached"`
 }
 
-func NewPetitionHandler(petitionCollection, userCollection *mongo.Collection, notificationService *services.NotificationService) *PetitionHandler {
+func NewPetitionHandler(petitionCollection, userCollection *mongo.Collection, notificationService *services.NotificationService, taxonomyService *services.TaxonomyService, tagService *services.TagService, revisionService *services.RevisionService, thresholdService *services.PetitionThresholdService, relatedService *services.RelatedContentService) *PetitionHandler {
 	return &PetitionHandler{
 		petitionCollection:  petitionCollection,
 		userCollection:      userCollection,
 		notificationService: notificationService,
+		taxonomyService:     taxonomyService,
+		tagService:          tagService,
+		revisionService:     revisionService,
+		thresholdService:    thresholdService,
+		relatedService:      relatedService,
 	}
 }
 
@@ -85,6 +96,9 @@ func (h *PetitionHandler) CreatePetition(c *gin.Context) {
 		})
 		return
 	}
+	if !validateCategory(c, h.taxonomyService, models.ModulePetitions, req.Category) {
+		return
+	}
 
 	userID, _ := c.Get("user_id")
 	userIDObj, err := primitive.ObjectIDFromHex(userID.(string))
@@ -112,6 +126,11 @@ func (h *PetitionHandler) CreatePetition(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	tags, ok := canonicalizeTags(ctx, c, h.tagService, req.Tags)
+	if !ok {
+		return
+	}
+
 	activeCount, err := h.petitionCollection.CountDocuments(ctx, bson.M{
 		"author_id": userIDObj,
 		"status":    bson.M{"$in": []string{models.PetitionStatusDraft, models.PetitionStatusActive}},
@@ -132,9 +151,11 @@ func (h *PetitionHandler) CreatePetition(c *gin.Context) {
 
 	now := time.Now()
 	petition := models.Petition{
+		CommunityID:        getCommunityID(c),
 		AuthorID:           userIDObj,
 		Title:              req.Title,
 		Description:        req.Description,
+		Language:           services.DetectLanguage(req.Title, req.Description),
 		Category:           req.Category,
 		RequiredSignatures: req.RequiredSignatures,
 		Demands:            req.Demands,
@@ -146,13 +167,15 @@ func (h *PetitionHandler) CreatePetition(c *gin.Context) {
 		EndDate:            req.EndDate,
 		CreatedAt:          now,
 		UpdatedAt:          now,
-		Tags:               req.Tags,
+		Tags:               tags,
 		ViewCount:          0,
 		ShareCount:         0,
 		AttachmentURLs:     req.AttachmentURLs,
 	}
 
-	result, err := h.petitionCollection.InsertOne(ctx, petition)
+	// ID призначається заздалегідь: він входить у slug
+	petition.ID = primitive.NewObjectID()
+	petition.Slug, err = services.UniqueSlug(ctx, h.petitionCollection, petition.Title, "petition", petition.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error creating petition",
@@ -160,7 +183,16 @@ func (h *PetitionHandler) CreatePetition(c *gin.Context) {
 		return
 	}
 
-	petition.ID = result.InsertedID.(primitive.ObjectID)
+	if _, err := h.petitionCollection.InsertOne(ctx, petition); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating petition",
+		})
+		return
+	}
+
+	if h.tagService != nil {
+		h.tagService.Track(ctx, models.ModulePetitions, nil, tags)
+	}
 
 	c.JSON(http.StatusCreated, petition)
 }
@@ -189,11 +221,11 @@ func (h *PetitionHandler) PublishPetition(c *gin.Context) {
 
 	// Проверяем, что пользователь является автором петиции
 	var petition models.Petition
-	err = h.petitionCollection.FindOne(ctx, bson.M{
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{
 		"_id":       petitionIDObj,
 		"author_id": userIDObj,
 		"status":    models.PetitionStatusDraft,
-	}).Decode(&petition)
+	})).Decode(&petition)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -209,12 +241,29 @@ func (h *PetitionHandler) PublishPetition(c *gin.Context) {
 
 	// Обновляем статус на активный
 	now := time.Now()
-	result, err := h.petitionCollection.UpdateOne(ctx, bson.M{"_id": petitionIDObj}, bson.M{
-		"$set": bson.M{
-			"status":     models.PetitionStatusActive,
-			"start_date": now,
-			"updated_at": now,
-		},
+	update := bson.M{
+		"status":     models.PetitionStatusActive,
+		"start_date": now,
+		"updated_at": now,
+	}
+
+	// Порог администрации для категории заменяет количество подписей, указанное автором
+	threshold, err := h.thresholdService.Resolve(ctx, petition.Category, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error resolving petition threshold",
+		})
+		return
+	}
+	if threshold != nil {
+		petition.Threshold = threshold.Applied(petition.RequiredSignatures, now)
+		petition.RequiredSignatures = petition.Threshold.RequiredSignatures
+		update["required_signatures"] = petition.RequiredSignatures
+		update["threshold"] = petition.Threshold
+	}
+
+	result, err := h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": petitionIDObj, "status": models.PetitionStatusDraft}), bson.M{
+		"$set": update,
 	})
 
 	if err != nil {
@@ -232,7 +281,9 @@ func (h *PetitionHandler) PublishPetition(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Petition published successfully",
+		"message":             "Petition published successfully",
+		"required_signatures": petition.RequiredSignatures,
+		"threshold":           petition.Threshold,
 	})
 }
 
@@ -247,15 +298,6 @@ func (h *PetitionHandler) UpdatePetitionStatus(c *gin.Context) {
 		return
 	}
 
-	// Перевіряємо права модератора
-	isModerator, exists := c.Get("is_moderator")
-	if !exists || !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Only moderators can update petition status",
-		})
-		return
-	}
-
 	var req UpdateStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -270,7 +312,7 @@ func (h *PetitionHandler) UpdatePetitionStatus(c *gin.Context) {
 
 	// Перевіряємо, чи петиція існує
 	var petition models.Petition
-	err = h.petitionCollection.FindOne(ctx, bson.M{"_id": petitionIDObj}).Decode(&petition)
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionIDObj})).Decode(&petition)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -284,6 +326,11 @@ func (h *PetitionHandler) UpdatePetitionStatus(c *gin.Context) {
 		return
 	}
 
+	// Петиції не прив'язані до мікрорайону: зона модератора перевіряється за категорією
+	if !inModerationScope(c, "", petition.Category) {
+		return
+	}
+
 	// Оновлюємо статус
 	now := time.Now()
 	updateData := bson.M{
@@ -296,11 +343,20 @@ func (h *PetitionHandler) UpdatePetitionStatus(c *gin.Context) {
 		updateData["completed_at"] = now
 	}
 
-	result, err := h.petitionCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": petitionIDObj},
-		bson.M{"$set": updateData},
-	)
+	// Статус і сповіщення автору та співавторам про його зміну записуються разом
+	var result *mongo.UpdateResult
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = h.petitionCollection.UpdateOne(
+			ctx,
+			communityScope(c, bson.M{"_id": petitionIDObj}),
+			bson.M{"$set": updateData},
+		)
+		if err != nil || result.MatchedCount == 0 || req.Status == petition.Status {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, statusIntent(petition, req.Status))
+	})
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -316,11 +372,6 @@ func (h *PetitionHandler) UpdatePetitionStatus(c *gin.Context) {
 		return
 	}
 
-	// Надсилаємо сповіщення автору про зміну статусу
-	if h.notificationService != nil {
-		// TODO: Реалізувати сповіщення
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Petition status updated successfully",
 		"status":  req.Status,
@@ -352,9 +403,9 @@ func (h *PetitionHandler) GetPetitions(c *gin.Context) {
 	}
 
 	// Строим фильтр для запроса
-	filter := bson.M{
+	filter := communityScope(c, bson.M{
 		"status": bson.M{"$ne": models.PetitionStatusDraft}, // Исключаем черновики
-	}
+	})
 
 	if filters.Category != "" {
 		filter["category"] = filters.Category
@@ -380,6 +431,9 @@ func (h *PetitionHandler) GetPetitions(c *gin.Context) {
 	if len(filters.Tags) > 0 {
 		filter["tags"] = bson.M{"$in": filters.Tags}
 	}
+	if !applyLanguageFilter(c, filter) {
+		return
+	}
 	if filters.GoalReached != nil {
 		// Используем агрегацию для сравнения signature_count с required_signatures
 		if *filters.GoalReached {
@@ -443,30 +497,84 @@ func (h *PetitionHandler) GetPetitions(c *gin.Context) {
 		return
 	}
 
+	// Публично показываем только подтвердивших соавторство
+	for i := range petitions {
+		petitions[i].CoAuthors = petitions[i].AcceptedCoAuthors()
+	}
+
 	// Получаем общее количество для пагинации
 	totalCount, err := h.petitionCollection.CountDocuments(ctx, filter)
 	if err != nil {
 		totalCount = 0
 	}
 
-	totalPages := (totalCount + int64(filters.Limit) - 1) / int64(filters.Limit)
+	info := newPageInfo(filters.Page, filters.Limit, totalCount)
+	c.JSON(http.StatusOK, listResponse(c, petitions, info, nil, gin.H{"data": petitions, "pagination": info}))
+}
+
+// FindSimilarPetitions - GET /petitions/similar?title=...&description=...
+// Пропонує наявні петиції до створення нової, зокрема ту саму петицію, подану іншою мовою (UA/RU).
+func (h *PetitionHandler) FindSimilarPetitions(c *gin.Context) {
+	query, ok := parseDuplicateQuery(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "title is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Відхилені та прострочені петиції не заважають подати нову
+	filter := communityScope(c, bson.M{
+		"status": bson.M{"$in": []string{
+			models.PetitionStatusActive,
+			models.PetitionStatusCompleted,
+			models.PetitionStatusUnderReview,
+			models.PetitionStatusAccepted,
+		}},
+	})
+
+	cursor, err := h.petitionCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"title": 1, "description": 1, "status": 1, "created_at": 1}).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(duplicateCandidateLimit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching petitions",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var petitions []models.Petition
+	if err := cursor.All(ctx, &petitions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding petitions",
+		})
+		return
+	}
+
+	suggestions := make([]DuplicateSuggestion, 0, len(petitions))
+	for _, petition := range petitions {
+		suggestions = append(suggestions, DuplicateSuggestion{
+			ID:        petition.ID,
+			Title:     petition.Title,
+			Status:    petition.Status,
+			Score:     query.score(petition.Title, petition.Description),
+			CreatedAt: petition.CreatedAt,
+		})
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		//"petitions": petitions,
-		"data": petitions,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-		},
+		"similar": topDuplicateSuggestions(suggestions),
 	})
 }
 
 func (h *PetitionHandler) GetPetition(c *gin.Context) {
-	petitionID := c.Param("id")
-	petitionIDObj, err := primitive.ObjectIDFromHex(petitionID)
-	if err != nil {
+	filter, ok := idOrSlugFilter(c.Param("id"))
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid petition ID",
 		})
@@ -477,10 +585,7 @@ func (h *PetitionHandler) GetPetition(c *gin.Context) {
 	defer cancel()
 
 	var petition models.Petition
-	err = h.petitionCollection.FindOne(ctx, bson.M{
-		"_id":    petitionIDObj,
-		"status": bson.M{"$ne": models.PetitionStatusDraft},
-	}).Decode(&petition)
+	err := h.petitionCollection.FindOne(ctx, communityScope(c, filter)).Decode(&petition)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -495,15 +600,32 @@ func (h *PetitionHandler) GetPetition(c *gin.Context) {
 		return
 	}
 
+	// Чернетку бачать лише автор і співавтори (зокрема запрошені, щоб прочитати текст перед прийняттям)
+	viewerID, authErr := getUserID(c)
+	isAuthor := authErr == nil && (petition.AuthorID == viewerID || petition.GetCoAuthor(viewerID) != nil)
+	if petition.Status == models.PetitionStatusDraft && !isAuthor {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Petition not found",
+		})
+		return
+	}
+	if !isAuthor {
+		petition.CoAuthors = petition.AcceptedCoAuthors()
+	}
+
 	// Увеличиваем счетчик просмотров
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		h.petitionCollection.UpdateOne(ctx, bson.M{"_id": petitionIDObj}, bson.M{
+		h.petitionCollection.UpdateOne(ctx, bson.M{"_id": petition.ID}, bson.M{
 			"$inc": bson.M{"view_count": 1},
 		})
 	}()
 
+	if petition.Status != models.PetitionStatusDraft {
+		petition.Related = h.relatedService.For(ctx, models.ModulePetitions, petition.ID)
+	}
+
 	c.JSON(http.StatusOK, petition)
 }
 
@@ -550,11 +672,11 @@ func (h *PetitionHandler) SignPetition(c *gin.Context) {
 
 	// Проверяем существование петиции и возможность подписи
 	var petition models.Petition
-	err = h.petitionCollection.FindOne(ctx, bson.M{
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{
 		"_id":      petitionIDObj,
 		"status":   models.PetitionStatusActive,
 		"end_date": bson.M{"$gt": time.Now()},
-	}).Decode(&petition)
+	})).Decode(&petition)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -590,7 +712,7 @@ func (h *PetitionHandler) SignPetition(c *gin.Context) {
 	}
 
 	// Добавляем подпись
-	result, err := h.petitionCollection.UpdateOne(ctx, bson.M{"_id": petitionIDObj}, bson.M{
+	result, err := h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": petitionIDObj}), bson.M{
 		"$push": bson.M{"signatures": signature},
 		"$inc":  bson.M{"signature_count": 1},
 		"$set":  bson.M{"updated_at": now},
@@ -613,16 +735,26 @@ func (h *PetitionHandler) SignPetition(c *gin.Context) {
 	// Проверяем, достигнуто ли необходимое количество подписей
 	newSignatureCount := petition.SignatureCount + 1
 	if newSignatureCount >= petition.RequiredSignatures {
-		// Обновляем статус на "completed"
-		h.petitionCollection.UpdateOne(ctx, bson.M{"_id": petitionIDObj}, bson.M{
-			"$set": bson.M{
-				"status":       models.PetitionStatusCompleted,
-				"completed_at": now,
-			},
+		// Обновляем статус на "completed" и уведомляем автора о достижении цели.
+		// Фильтр по статусу: уведомление отправляется один раз, а не на каждую следующую подпись
+		err := h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+			result, err := h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{
+				"_id":    petitionIDObj,
+				"status": models.PetitionStatusActive,
+			}), bson.M{
+				"$set": bson.M{
+					"status":       models.PetitionStatusCompleted,
+					"completed_at": now,
+				},
+			})
+			if err != nil || result.MatchedCount == 0 {
+				return err
+			}
+			return h.notificationService.Enqueue(ctx, completionIntent(petition))
 		})
-
-		// Уведомляем автора о достижении цели
-		go h.notifyAuthorAboutCompletion(petition.AuthorID, petition.Title, petitionIDObj)
+		if err != nil {
+			log.Printf("Error completing petition %s: %v", petitionIDObj.Hex(), err)
+		}
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -642,16 +774,8 @@ func (h *PetitionHandler) GetUserPetitions(c *gin.Context) {
 		return
 	}
 
-	petitionType := c.DefaultQuery("type", "authored") // authored, signed
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 50 {
-		limit = 20
-	}
+	petitionType := c.DefaultQuery("type", "authored") // authored, signed, co_authored, invitations
+	page, limit := pageParams(c, 20, 50)
 
 	var filter bson.M
 	switch petitionType {
@@ -659,6 +783,16 @@ func (h *PetitionHandler) GetUserPetitions(c *gin.Context) {
 		filter = bson.M{"author_id": userIDObj}
 	case "signed":
 		filter = bson.M{"signatures.user_id": userIDObj}
+	case "co_authored":
+		filter = bson.M{"co_authors": bson.M{"$elemMatch": bson.M{
+			"user_id": userIDObj,
+			"status":  models.PetitionCoAuthorAccepted,
+		}}}
+	case "invitations":
+		filter = bson.M{"co_authors": bson.M{"$elemMatch": bson.M{
+			"user_id": userIDObj,
+			"status":  models.PetitionCoAuthorInvited,
+		}}}
 	default:
 		filter = bson.M{"author_id": userIDObj}
 	}
@@ -689,7 +823,13 @@ func (h *PetitionHandler) GetUserPetitions(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, petitions)
+	// Раньше endpoint возвращал массив без метаданных
+	if middleware.LegacyPagination(c) {
+		c.JSON(http.StatusOK, petitions)
+		return
+	}
+	total, _ := h.petitionCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, petitions, newPageInfo(page, limit, total), nil, nil))
 }
 
 func (h *PetitionHandler) DeletePetition(c *gin.Context) {
@@ -715,11 +855,11 @@ func (h *PetitionHandler) DeletePetition(c *gin.Context) {
 	defer cancel()
 
 	// Можно удалить только свои петиции в статусе черновика
-	result, err := h.petitionCollection.DeleteOne(ctx, bson.M{
+	result, err := h.petitionCollection.DeleteOne(ctx, communityScope(c, bson.M{
 		"_id":       petitionIDObj,
 		"author_id": userIDObj,
 		"status":    models.PetitionStatusDraft,
-	})
+	}))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -761,8 +901,7 @@ func (h *PetitionHandler) AddOfficialResponse(c *gin.Context) {
 	}
 
 	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	if !checkModerator(c) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Moderator access required",
 		})
@@ -814,15 +953,29 @@ func (h *PetitionHandler) AddOfficialResponse(c *gin.Context) {
 		newStatus = models.PetitionStatusAccepted
 	}
 
-	result, err := h.petitionCollection.UpdateOne(ctx, bson.M{
-		"_id":    petitionIDObj,
-		"status": bson.M{"$in": []string{models.PetitionStatusCompleted, models.PetitionStatusUnderReview}},
-	}, bson.M{
-		"$set": bson.M{
-			"official_response": officialResponse,
-			"status":            newStatus,
-			"updated_at":        now,
-		},
+	// Ответ и уведомление авторам о нем записываются вместе
+	var result *mongo.UpdateResult
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = h.petitionCollection.UpdateOne(ctx, withModerationScope(c, communityScope(c, bson.M{
+			"_id":    petitionIDObj,
+			"status": bson.M{"$in": []string{models.PetitionStatusCompleted, models.PetitionStatusUnderReview}},
+		})), bson.M{
+			"$set": bson.M{
+				"official_response": officialResponse,
+				"status":            newStatus,
+				"updated_at":        now,
+			},
+		})
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
+
+		var petition models.Petition
+		if err := h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionIDObj})).Decode(&petition); err != nil {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, responseIntent(petition, req.Decision))
 	})
 
 	if err != nil {
@@ -833,15 +986,15 @@ func (h *PetitionHandler) AddOfficialResponse(c *gin.Context) {
 	}
 
 	if result.MatchedCount == 0 {
+		if respondOutOfScope(ctx, c, h.petitionCollection, petitionIDObj) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Petition not found or not ready for response",
 		})
 		return
 	}
 
-	// Уведомляем автора петиции об официальном ответе
-	go h.notifyAuthorAboutResponse(petitionIDObj, req.Decision)
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Official response added successfully",
 	})
@@ -849,8 +1002,7 @@ func (h *PetitionHandler) AddOfficialResponse(c *gin.Context) {
 
 func (h *PetitionHandler) GetPetitionStats(c *gin.Context) {
 	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
+	if !checkModerator(c) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "Moderator access required",
 		})
@@ -928,38 +1080,79 @@ func (h *PetitionHandler) GetPetitionStats(c *gin.Context) {
 	})
 }
 
-// Вспомогательные функции для уведомлений
-func (h *PetitionHandler) notifyAuthorAboutCompletion(authorID primitive.ObjectID, petitionTitle string, petitionID primitive.ObjectID) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// Вспомогательные функции для уведомлений (намерения outbox - см. NotificationService.Enqueue)
 
+// authorsIntent - уведомление автору и принявшим приглашение соавторам
+func authorsIntent(petition models.Petition, source, title, body string, data map[string]interface{}) services.NotificationIntent {
+	return services.NotificationIntent{
+		Source:    source,
+		UserIDs:   petition.NotificationRecipients(),
+		Title:     title,
+		Body:      body,
+		Type:      services.NotificationTypeSystem,
+		Data:      data,
+		RelatedID: &petition.ID,
+	}
+}
+
+func completionIntent(petition models.Petition) services.NotificationIntent {
 	data := map[string]interface{}{
-		"petition_id": petitionID.Hex(),
+		"petition_id": petition.ID.Hex(),
 		"action":      "view_petition",
 	}
 
-	h.notificationService.SendNotificationToUser(
-		ctx,
-		authorID,
+	return authorsIntent(petition, "petition.goal_reached",
 		"Петиция набрала необходимое количество подписей",
-		fmt.Sprintf("Ваша петиция '%s' успешно набрала необходимое количество подписей и будет рассмотрена администрацией", petitionTitle),
-		services.NotificationTypeSystem,
+		fmt.Sprintf("Ваша петиция '%s' успешно набрала необходимое количество подписей и будет рассмотрена администрацией", petition.Title),
 		data,
-		&petitionID,
 	)
 }
 
-func (h *PetitionHandler) notifyAuthorAboutResponse(petitionID primitive.ObjectID, decision string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func statusIntent(petition models.Petition, status string) services.NotificationIntent {
+	statusTexts := map[string]string{
+		models.PetitionStatusDraft:       "возвращена в черновики",
+		models.PetitionStatusActive:      "открыта для подписания",
+		models.PetitionStatusCompleted:   "набрала необходимое количество подписей",
+		models.PetitionStatusExpired:     "завершена по истечении срока",
+		models.PetitionStatusUnderReview: "передана на рассмотрение",
+		models.PetitionStatusAccepted:    "принята",
+		models.PetitionStatusRejected:    "отклонена",
+	}
 
-	// Получаем петицию
-	var petition models.Petition
-	err := h.petitionCollection.FindOne(ctx, bson.M{"_id": petitionID}).Decode(&petition)
-	if err != nil {
-		return
+	statusText := statusTexts[status]
+	if statusText == "" {
+		statusText = status
+	}
+
+	data := map[string]interface{}{
+		"petition_id": petition.ID.Hex(),
+		"status":      status,
+		"action":      "view_petition",
+	}
+
+	return authorsIntent(petition, "petition.status_changed",
+		"Статус петиции изменен",
+		fmt.Sprintf("Ваша петиция '%s' %s", petition.Title, statusText),
+		data,
+	)
+}
+
+func coAuthorInvitationIntent(petition models.Petition, inviteeID primitive.ObjectID) services.NotificationIntent {
+	return services.NotificationIntent{
+		Source:  "petition.co_author_invited",
+		UserIDs: []primitive.ObjectID{inviteeID},
+		Title:   "Приглашение в соавторы петиции",
+		Body:    fmt.Sprintf("Вас пригласили стать соавтором петиции '%s'", petition.Title),
+		Type:    services.NotificationTypeSystem,
+		Data: map[string]interface{}{
+			"petition_id": petition.ID.Hex(),
+			"action":      "view_petition_invitation",
+		},
+		RelatedID: &petition.ID,
 	}
+}
 
+func responseIntent(petition models.Petition, decision string) services.NotificationIntent {
 	decisionTexts := map[string]string{
 		models.PetitionDecisionAccepted:          "принята к исполнению",
 		models.PetitionDecisionRejected:          "отклонена",
@@ -972,23 +1165,21 @@ func (h *PetitionHandler) notifyAuthorAboutResponse(petitionID primitive.ObjectI
 	}
 
 	data := map[string]interface{}{
-		"petition_id": petitionID.Hex(),
+		"petition_id": petition.ID.Hex(),
 		"decision":    decision,
 		"action":      "view_petition",
 	}
 
-	h.notificationService.SendNotificationToUser(
-		ctx,
-		petition.AuthorID,
+	return authorsIntent(petition, "petition.official_response",
 		"Официальный ответ на петицию",
 		fmt.Sprintf("По вашей петиции '%s' получен официальный ответ: %s", petition.Title, decisionText),
-		services.NotificationTypeSystem,
 		data,
-		&petitionID,
 	)
 }
 
 // UpdatePetition - оновлення петиції (автором або модератором)
+// Співавтори можуть редагувати лише текст чернетки.
+// Зміна тексту опублікованої петиції зберігає попередню версію в історії
 func (h *PetitionHandler) UpdatePetition(c *gin.Context) {
 	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
@@ -1000,8 +1191,11 @@ func (h *PetitionHandler) UpdatePetition(c *gin.Context) {
 	}
 
 	type UpdatePetitionRequest struct {
-		Status   string `json:"status,omitempty" binding:"omitempty,oneof=open closed under_review approved rejected"`
-		Response string `json:"response,omitempty"` // Офіційна відповідь
+		Title       string `json:"title,omitempty" binding:"omitempty,min=10,max=300"`
+		Description string `json:"description,omitempty" binding:"omitempty,min=50,max=5000"`
+		Demands     string `json:"demands,omitempty" binding:"omitempty,min=20,max=2000"`
+		Status      string `json:"status,omitempty" binding:"omitempty,oneof=open closed under_review approved rejected"`
+		Response    string `json:"response,omitempty"` // Офіційна відповідь
 	}
 
 	var req UpdatePetitionRequest
@@ -1013,14 +1207,93 @@ func (h *PetitionHandler) UpdatePetition(c *gin.Context) {
 		return
 	}
 
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	var petition models.Petition
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionID})).Decode(&petition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Petition not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+
+	if petition.AuthorID != userID && !checkModerator(c) {
+		if !petition.IsCoAuthor(userID) || petition.Status != models.PetitionStatusDraft {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "You don't have permission to update this petition",
+			})
+			return
+		}
+		if req.Status != "" || req.Response != "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Co-authors can only edit the petition text",
+			})
+			return
+		}
+	}
+
+	// Чужу петицію модератор з зоною змінює лише в межах своїх категорій
+	if petition.AuthorID != userID && !petition.IsCoAuthor(userID) && !inModerationScope(c, "", petition.Category) {
+		return
+	}
+
 	// Формуємо оновлення
 	update := bson.M{
 		"updated_at": time.Now(),
 	}
 
+	current := petitionText(petition)
+	updated := petitionText(petition)
+	if req.Title != "" {
+		updated["title"] = req.Title
+	}
+	if req.Description != "" {
+		updated["description"] = req.Description
+	}
+	if req.Demands != "" {
+		updated["demands"] = req.Demands
+	}
+	for field, value := range updated {
+		if value != current[field] {
+			update[field] = value
+		}
+	}
+	if updated["title"] != current["title"] || updated["description"] != current["description"] {
+		update["language"] = services.DetectLanguage(updated["title"], updated["description"])
+	}
+
+	// Після публікації під текстом вже є підписи - зберігаємо попередню версію
+	if petition.Status != models.PetitionStatusDraft {
+		edit := revisionEdit{
+			entityType: models.RevisionEntityPetition,
+			entityID:   petitionID,
+			version:    petition.RevisionCount + 1,
+			current:    current,
+			updated:    updated,
+			editorID:   userID,
+		}
+		if !recordRevision(ctx, c, h.revisionService, edit, update) {
+			return
+		}
+	}
+
 	if req.Status != "" {
 		update["status"] = req.Status
 	}
@@ -1033,7 +1306,7 @@ func (h *PetitionHandler) UpdatePetition(c *gin.Context) {
 	// Оновлюємо петицію
 	result, err := h.petitionCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": petitionID},
+		communityScope(c, bson.M{"_id": petitionID}),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -1054,3 +1327,390 @@ func (h *PetitionHandler) UpdatePetition(c *gin.Context) {
 		"message": "Petition updated successfully",
 	})
 }
+
+// GetRevisions - історія тексту петиції з порівнянням версій (для модераторів)
+func (h *PetitionHandler) GetRevisions(c *gin.Context) {
+	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid petition ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var petition models.Petition
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionID})).Decode(&petition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Petition not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+
+	if !inModerationScope(c, "", petition.Category) {
+		return
+	}
+
+	respondRevisions(ctx, c, h.revisionService, models.RevisionEntityPetition, petition.ID, petitionText(petition), petition.EditedAt)
+}
+
+// petitionText - текстові поля петиції, зміни яких зберігаються в історії
+func petitionText(petition models.Petition) map[string]string {
+	return map[string]string{
+		"title":       petition.Title,
+		"description": petition.Description,
+		"demands":     petition.Demands,
+	}
+}
+
+// ========================================
+// СПІВАВТОРИ ПЕТИЦІЇ
+// ========================================
+
+type InviteCoAuthorRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// InviteCoAuthor - POST /petitions/:id/co-authors
+// Автор запрошує співавтора до чернетки; права з'являються після прийняття запрошення
+func (h *PetitionHandler) InviteCoAuthor(c *gin.Context) {
+	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid petition ID",
+		})
+		return
+	}
+
+	var req InviteCoAuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	inviteeID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if inviteeID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Author cannot be invited as a co-author",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var petition models.Petition
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionID})).Decode(&petition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Petition not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+
+	if petition.AuthorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the petition author can invite co-authors",
+		})
+		return
+	}
+	if petition.Status != models.PetitionStatusDraft {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Co-authors can only be invited while the petition is a draft",
+		})
+		return
+	}
+	if petition.GetCoAuthor(inviteeID) != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User is already invited",
+		})
+		return
+	}
+	if len(petition.CoAuthors) >= models.MaxPetitionCoAuthors {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("A petition can have at most %d co-authors", models.MaxPetitionCoAuthors),
+		})
+		return
+	}
+
+	var invitee models.User
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": inviteeID}).Decode(&invitee)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error getting user info",
+		})
+		return
+	}
+	if invitee.IsBlocked {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Blocked users cannot be invited",
+		})
+		return
+	}
+
+	coAuthor := models.PetitionCoAuthor{
+		UserID:    inviteeID,
+		FullName:  invitee.FirstName + " " + invitee.LastName,
+		Status:    models.PetitionCoAuthorInvited,
+		InvitedAt: time.Now(),
+	}
+
+	// Умови повторюють перевірки вище, щоб паралельні запрошення не перевищили ліміт.
+	// Запрошення і сповіщення запрошеному записуються разом
+	var result *mongo.UpdateResult
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{
+			"_id":                petitionID,
+			"author_id":          userID,
+			"status":             models.PetitionStatusDraft,
+			"co_authors.user_id": bson.M{"$ne": inviteeID},
+			fmt.Sprintf("co_authors.%d", models.MaxPetitionCoAuthors-1): bson.M{"$exists": false},
+		}), bson.M{
+			"$push": bson.M{"co_authors": coAuthor},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, coAuthorInvitationIntent(petition, inviteeID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error inviting co-author",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Petition was changed concurrently, reload and try again",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Co-author invited successfully",
+		"co_author": coAuthor,
+	})
+}
+
+// AcceptCoAuthorInvitation - POST /petitions/:id/co-authors/accept
+func (h *PetitionHandler) AcceptCoAuthorInvitation(c *gin.Context) {
+	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid petition ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var petition models.Petition
+	err = h.petitionCollection.FindOneAndUpdate(ctx, communityScope(c, bson.M{
+		"_id": petitionID,
+		"co_authors": bson.M{"$elemMatch": bson.M{
+			"user_id": userID,
+			"status":  models.PetitionCoAuthorInvited,
+		}},
+	}), bson.M{
+		"$set": bson.M{
+			"co_authors.$.status":      models.PetitionCoAuthorAccepted,
+			"co_authors.$.accepted_at": now,
+			"updated_at":               now,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&petition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Invitation not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error accepting invitation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Invitation accepted",
+		"co_authors": petition.AcceptedCoAuthors(),
+	})
+}
+
+// DeclineCoAuthorInvitation - POST /petitions/:id/co-authors/decline
+func (h *PetitionHandler) DeclineCoAuthorInvitation(c *gin.Context) {
+	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid petition ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": petitionID}), bson.M{
+		"$pull": bson.M{"co_authors": bson.M{
+			"user_id": userID,
+			"status":  models.PetitionCoAuthorInvited,
+		}},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error declining invitation",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.ModifiedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invitation not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation declined",
+	})
+}
+
+// RemoveCoAuthor - DELETE /petitions/:id/co-authors/:user_id
+// Автор прибирає співавтора (або відкликає запрошення), співавтор може вийти сам
+func (h *PetitionHandler) RemoveCoAuthor(c *gin.Context) {
+	petitionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid petition ID",
+		})
+		return
+	}
+
+	coAuthorID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var petition models.Petition
+	err = h.petitionCollection.FindOne(ctx, communityScope(c, bson.M{"_id": petitionID})).Decode(&petition)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Petition not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+
+	if petition.AuthorID != userID && coAuthorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the petition author can remove co-authors",
+		})
+		return
+	}
+	if petition.GetCoAuthor(coAuthorID) == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Co-author not found",
+		})
+		return
+	}
+
+	_, err = h.petitionCollection.UpdateOne(ctx, communityScope(c, bson.M{"_id": petitionID}), bson.M{
+		"$pull": bson.M{"co_authors": bson.M{"user_id": coAuthorID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error removing co-author",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Co-author removed",
+	})
+}
@@ -0,0 +1,236 @@
+// internal/handlers/petition_threshold.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PetitionThresholdHandler - пороги підписів петицій, встановлені адміністрацією
+type PetitionThresholdHandler struct {
+	thresholdService *services.PetitionThresholdService
+	taxonomyService  *services.TaxonomyService
+}
+
+// NewPetitionThresholdHandler створює обробник порогів петицій
+func NewPetitionThresholdHandler(thresholdService *services.PetitionThresholdService, taxonomyService *services.TaxonomyService) *PetitionThresholdHandler {
+	return &PetitionThresholdHandler{
+		thresholdService: thresholdService,
+		taxonomyService:  taxonomyService,
+	}
+}
+
+// PetitionThresholdRequest - правило порогу. Категорія задається лише при створенні.
+type PetitionThresholdRequest struct {
+	Category          string  `json:"category" binding:"max=50"` // Порожньо - для всіх категорій
+	Mode              string  `json:"mode" binding:"required,oneof=fixed population"`
+	Signatures        int     `json:"signatures" binding:"min=0,max=10000000"`
+	Population        int     `json:"population" binding:"min=0,max=100000000"`
+	PopulationPercent float64 `json:"population_percent" binding:"min=0,max=100"`
+	MinSignatures     int     `json:"min_signatures" binding:"min=0,max=10000000"`
+	MaxSignatures     int     `json:"max_signatures" binding:"min=0,max=10000000"`
+	LegalBasis        string  `json:"legal_basis" binding:"required,min=5,max=500"`
+	LegalBasisURL     string  `json:"legal_basis_url" binding:"omitempty,url,max=500"`
+}
+
+// bindThreshold перевіряє правило; false - відповідь з помилкою вже надіслана
+func bindThreshold(c *gin.Context) (*PetitionThresholdRequest, bool) {
+	var req PetitionThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	details := ""
+	switch {
+	case req.Mode == models.PetitionThresholdFixed && req.Signatures < models.MinPetitionSignatures:
+		details = "Fixed threshold requires at least 100 signatures"
+	case req.Mode == models.PetitionThresholdPopulation && (req.Population == 0 || req.PopulationPercent <= 0):
+		details = "Population threshold requires population and population_percent"
+	case req.MaxSignatures > 0 && req.MaxSignatures < req.MinSignatures:
+		details = "max_signatures must not be less than min_signatures"
+	}
+	if details != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid threshold",
+			"details": details,
+		})
+		return nil, false
+	}
+	return &req, true
+}
+
+// threshold переносить параметри правила з запиту
+func (req *PetitionThresholdRequest) threshold(userID primitive.ObjectID) *models.PetitionThreshold {
+	threshold := &models.PetitionThreshold{
+		Category:      req.Category,
+		Mode:          req.Mode,
+		MinSignatures: req.MinSignatures,
+		MaxSignatures: req.MaxSignatures,
+		LegalBasis:    req.LegalBasis,
+		LegalBasisURL: req.LegalBasisURL,
+		UpdatedBy:     &userID,
+	}
+	if req.Mode == models.PetitionThresholdFixed {
+		threshold.Signatures = req.Signatures
+	} else {
+		threshold.Population = req.Population
+		threshold.PopulationPercent = req.PopulationPercent
+	}
+	return threshold
+}
+
+// GetThresholds - GET /petitions/thresholds
+// Пороги громади: автор бачить, скільки підписів знадобиться після публікації
+func (h *PetitionThresholdHandler) GetThresholds(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	thresholds, err := h.thresholdService.List(ctx, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching petition thresholds",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"thresholds": thresholds,
+	})
+}
+
+// CreateThreshold - POST /admin/petition-thresholds
+func (h *PetitionThresholdHandler) CreateThreshold(c *gin.Context) {
+	req, ok := bindThreshold(c)
+	if !ok {
+		return
+	}
+	if req.Category != "" && !validateCategory(c, h.taxonomyService, models.ModulePetitions, req.Category) {
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	threshold := req.threshold(userID)
+	threshold.CommunityID = getCommunityID(c)
+
+	err = h.thresholdService.Create(ctx, threshold)
+	if err == services.ErrPetitionThresholdExists {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "A threshold is already defined for this category",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error creating petition threshold",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, threshold)
+}
+
+// UpdateThreshold - PUT /admin/petition-thresholds/:id
+// Новий поріг діє для петицій, опублікованих після зміни
+func (h *PetitionThresholdHandler) UpdateThreshold(c *gin.Context) {
+	thresholdID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid threshold ID",
+		})
+		return
+	}
+	req, ok := bindThreshold(c)
+	if !ok {
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	threshold := req.threshold(userID)
+	updated, err := h.thresholdService.Update(ctx, thresholdID, threshold, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating petition threshold",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Petition threshold not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, threshold)
+}
+
+// RemoveThreshold - DELETE /admin/petition-thresholds/:id
+// Без правила знову діє кількість підписів, яку вказав автор
+func (h *PetitionThresholdHandler) RemoveThreshold(c *gin.Context) {
+	thresholdID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid threshold ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	removed, err := h.thresholdService.Remove(ctx, thresholdID, communityScope(c, bson.M{}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error removing petition threshold",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Petition threshold not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Petition threshold removed",
+	})
+}
@@ -3,10 +3,15 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
 	"nova-kakhovka-ecity/internal/services"
 
@@ -21,58 +26,127 @@ import (
 type PollHandler struct {
 	pollCollection      *mongo.Collection
 	userCollection      *mongo.Collection
+	groupCollection     *mongo.Collection
 	notificationService *services.NotificationService
+	mediaService        *services.MediaService
+	taxonomyService     *services.TaxonomyService
+	tagService          *services.TagService
+	pollSummaries       *services.PollSummaryService
+	pollResponses       *services.PollResponseService
+	pollResults         *services.PollResultService
+	trustService        *services.TrustService
+	moderationLog       *services.ModerationLogService
+	municipality        models.Geofence
 }
 
 // NewPollHandler створює новий екземпляр PollHandler
-func NewPollHandler(db *mongo.Database, notificationService *services.NotificationService) *PollHandler {
+func NewPollHandler(db *mongo.Database, notificationService *services.NotificationService, mediaService *services.MediaService, taxonomyService *services.TaxonomyService, tagService *services.TagService, pollSummaries *services.PollSummaryService, pollResponses *services.PollResponseService, pollResults *services.PollResultService, trustService *services.TrustService, moderationLog *services.ModerationLogService, municipality models.Geofence) *PollHandler {
 	return &PollHandler{
 		pollCollection:      db.Collection("polls"),
 		userCollection:      db.Collection("users"),
+		groupCollection:     db.Collection("groups"),
 		notificationService: notificationService,
+		mediaService:        mediaService,
+		taxonomyService:     taxonomyService,
+		tagService:          tagService,
+		pollSummaries:       pollSummaries,
+		pollResponses:       pollResponses,
+		pollResults:         pollResults,
+		trustService:        trustService,
+		moderationLog:       moderationLog,
+		municipality:        municipality,
 	}
 }
 
+// Поля картки, за якими можна сортувати список опитувань
+var pollSummarySortFields = map[string]bool{
+	"created_at":      true,
+	"updated_at":      true,
+	"start_date":      true,
+	"end_date":        true,
+	"title":           true,
+	"total_responses": true,
+	"view_count":      true,
+}
+
 // ========================================
 // REQUEST/RESPONSE STRUCTURES
 // ========================================
 
 // CreatePollRequest структура запиту для створення опроса
 type CreatePollRequest struct {
-	Title            string                 `json:"title" validate:"required,min=5,max=300"`
-	Description      string                 `json:"description" validate:"required,min=10,max=2000"`
-	Category         string                 `json:"category" validate:"required,oneof=city_planning transport infrastructure social environment governance budget education healthcare"`
-	Questions        []CreatePollQuestion   `json:"questions" validate:"required,min=1,max=20"`
-	AllowMultiple    bool                   `json:"allow_multiple"`
-	IsAnonymous      bool                   `json:"is_anonymous"`
-	IsPublic         bool                   `json:"is_public"`
-	TargetGroups     []string               `json:"target_groups,omitempty"`
-	AgeRestriction   *models.AgeRestriction `json:"age_restriction,omitempty"`
-	LocationRequired bool                   `json:"location_required"`
-	StartDate        time.Time              `json:"start_date"`
-	EndDate          time.Time              `json:"end_date" validate:"required"`
-	Tags             []string               `json:"tags"`
+	Title              string                 `json:"title" validate:"required,min=5,max=300"`
+	Description        string                 `json:"description" validate:"required,min=10,max=2000"`
+	Category           string                 `json:"category" validate:"required"` // Код із таксономії модуля polls
+	Questions          []CreatePollQuestion   `json:"questions" validate:"required,min=1,max=20"`
+	AllowMultiple      bool                   `json:"allow_multiple"`
+	IsAnonymous        bool                   `json:"is_anonymous"`
+	IsPublic           bool                   `json:"is_public"`
+	TargetGroups       []string               `json:"target_groups,omitempty"`
+	AgeRestriction     *models.AgeRestriction `json:"age_restriction,omitempty"`
+	LocationRequired   bool                   `json:"location_required"`
+	VerifiedVotersOnly bool                   `json:"verified_voters_only"` // Один голос на особу, підтверджену Дією або BankID
+	StartDate          time.Time              `json:"start_date"`
+	EndDate            time.Time              `json:"end_date" validate:"required"`
+	Tags               []string               `json:"tags"`
 }
 
 // CreatePollQuestion структура питання для створення опроса
 type CreatePollQuestion struct {
-	Text       string             `json:"text" validate:"required,min=5,max=500"`
-	Type       string             `json:"type" validate:"required,oneof=single_choice multiple_choice rating text scale yes_no"`
-	IsRequired bool               `json:"is_required"`
-	Options    []CreatePollOption `json:"options"`
-	MinRating  int                `json:"min_rating,omitempty"`
-	MaxRating  int                `json:"max_rating,omitempty"`
-	MaxLength  int                `json:"max_length,omitempty"`
+	Text       string                   `json:"text" validate:"required,min=5,max=500"`
+	Type       string                   `json:"type" validate:"required,oneof=single_choice multiple_choice rating text scale yes_no ranking"`
+	IsRequired bool                     `json:"is_required"`
+	Options    []CreatePollOption       `json:"options"`
+	MinRating  int                      `json:"min_rating,omitempty"`
+	MaxRating  int                      `json:"max_rating,omitempty"`
+	MaxLength  int                      `json:"max_length,omitempty"`
+	RankLimit  int                      `json:"rank_limit,omitempty"` // Для ranking: скільки позицій ранжувати (0 - всі)
+	Media      *models.QuestionMedia    `json:"media,omitempty"`      // Зображення або коротке відео до питання
+	ShowIf     *CreateQuestionCondition `json:"show_if,omitempty"`    // Питання показується, лише якщо виконано умову
+}
+
+// CreateQuestionCondition умова показу питання. ID питань і варіантів створюються сервером,
+// тому умова посилається на попереднє питання та його варіанти за індексами з запиту (з 0)
+type CreateQuestionCondition struct {
+	Question      int   `json:"question"`
+	OptionIndexes []int `json:"option_indexes,omitempty"` // Вибір: обрано будь-який з варіантів
+	BoolAnswer    *bool `json:"bool_answer,omitempty"`    // Так/ні
+	MinValue      *int  `json:"min_value,omitempty"`      // Оцінка/шкала: не менше
+	MaxValue      *int  `json:"max_value,omitempty"`      // Оцінка/шкала: не більше
+}
+
+// questionCondition перетворює індекси умови питання index на ID створених питань і варіантів
+func questionCondition(req *CreateQuestionCondition, questions []models.PollQuestion, index int) (*models.QuestionCondition, error) {
+	if req.Question < 0 || req.Question >= index {
+		return nil, fmt.Errorf("condition must refer to a previous question")
+	}
+	source := questions[req.Question]
+
+	condition := &models.QuestionCondition{
+		QuestionID: source.ID,
+		BoolAnswer: req.BoolAnswer,
+		MinValue:   req.MinValue,
+		MaxValue:   req.MaxValue,
+	}
+	for _, i := range req.OptionIndexes {
+		if i < 0 || i >= len(source.Options) {
+			return nil, fmt.Errorf("condition option index %d is out of range", i)
+		}
+		condition.OptionIDs = append(condition.OptionIDs, source.Options[i].ID)
+	}
+	return condition, nil
 }
 
 // CreatePollOption структура опції відповіді для питання
 type CreatePollOption struct {
-	Text string `json:"text" validate:"required,min=1,max=200"`
+	Text  string `json:"text" validate:"required,min=1,max=200"`
+	Image string `json:"image,omitempty"`
 }
 
 // SubmitPollResponseRequest структура відповіді користувача на опитування
 type SubmitPollResponseRequest struct {
-	Answers []PollAnswerRequest `json:"answers" validate:"required,min=1"`
+	Answers  []PollAnswerRequest `json:"answers" validate:"required,min=1"`
+	Location *models.Location    `json:"location,omitempty"` // Для опитувань з location_required, якщо голосує не підтверджений житель
 }
 
 // PollAnswerRequest структура одної відповіді на питання
@@ -84,6 +158,11 @@ type PollAnswerRequest struct {
 	BoolAnswer   *bool    `json:"bool_answer,omitempty"`
 }
 
+// UpdatePollStatusRequest структура зміни статусу опитування модератором
+type UpdatePollStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft active completed cancelled"`
+}
+
 // PollFilters структура для фільтрації опросів
 type PollFilters struct {
 	Status    string `form:"status"`
@@ -101,18 +180,55 @@ type PollFilters struct {
 // HELPER FUNCTIONS
 // ========================================
 
-// checkModerator безпечно перевіряє, чи є користувач модератором
-func checkModerator(c *gin.Context) bool {
-	if isMod, exists := c.Get("is_moderator"); exists {
-		if modBool, ok := isMod.(bool); ok {
-			return modBool
+// validateQuestionMedia перевіряє медіа питання та зображення опцій через медіасервіс
+func (h *PollHandler) validateQuestionMedia(q CreatePollQuestion) error {
+	if !q.Media.IsEmpty() {
+		if len(q.Media.Images) > models.MaxQuestionImages {
+			return fmt.Errorf("too many images (max %d)", models.MaxQuestionImages)
+		}
+		for _, image := range q.Media.Images {
+			if err := h.mediaService.ValidateImageURL(image); err != nil {
+				return err
+			}
+		}
+		if q.Media.VideoURL != "" {
+			if err := h.mediaService.ValidateVideoURL(q.Media.VideoURL); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, opt := range q.Options {
+		if opt.Image != "" {
+			if err := h.mediaService.ValidateImageURL(opt.Image); err != nil {
+				return err
+			}
 		}
 	}
-	return false
+	return nil
+}
+
+// checkModerator перевіряє за роллю з claims, чи є користувач модератором (MODERATOR і вище).
+// Для модулів з окремим дозволом модерації використовуйте middleware.UserHasPermission.
+func checkModerator(c *gin.Context) bool {
+	user, exists := middleware.CurrentUser(c)
+	return exists && user.Role.IsHigherOrEqual(models.RoleModerator)
+}
+
+// syncPollSummary оновлює картку опитування для списків; помилка не скасовує сам запис
+func (h *PollHandler) syncPollSummary(ctx context.Context, pollID primitive.ObjectID) {
+	if err := h.pollSummaries.Sync(ctx, pollID); err != nil {
+		log.Printf("Error syncing poll summary %s: %v", pollID.Hex(), err)
+	}
 }
 
 // getUserID отримує ID користувача з контексту Gin
 func getUserID(c *gin.Context) (primitive.ObjectID, error) {
+	// AuthMiddleware вже розібрав ID в ObjectID
+	if user, ok := middleware.CurrentUser(c); ok {
+		return user.UserID, nil
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		return primitive.NilObjectID, fmt.Errorf("user_id not found in context")
@@ -156,6 +272,9 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 		})
 		return
 	}
+	if !validateCategory(c, h.taxonomyService, models.ModulePolls, req.Category) {
+		return
+	}
 
 	// Отримання ID користувача
 	userIDObj, err := getUserID(c)
@@ -193,9 +312,15 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	tags, ok := canonicalizeTags(ctx, c, h.tagService, req.Tags)
+	if !ok {
+		return
+	}
+
+	// Опитування на модерації теж займають місце в ліміті
 	activeCount, err := h.pollCollection.CountDocuments(ctx, bson.M{
 		"creator_id": userIDObj,
-		"status":     models.PollStatusActive,
+		"status":     bson.M{"$in": []string{models.PollStatusActive, models.PollStatusPending}},
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -230,23 +355,43 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 		}
 	}
 
-	// Перетворення груп
-	var targetGroupIDs []primitive.ObjectID
-	for _, groupIDStr := range req.TargetGroups {
-		groupID, err := primitive.ObjectIDFromHex(groupIDStr)
-		if err != nil {
+	if req.VerifiedVotersOnly && req.AllowMultiple {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid voting settings",
+			"details": "verified_voters_only polls accept one vote per person, allow_multiple must be false",
+		})
+		return
+	}
+
+	if req.AgeRestriction != nil {
+		if err := req.AgeRestriction.Validate(); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid target group ID",
-				"details": fmt.Sprintf("Group ID '%s' is not valid", groupIDStr),
+				"error":   "Invalid age restriction",
+				"details": err.Error(),
 			})
 			return
 		}
-		targetGroupIDs = append(targetGroupIDs, groupID)
+	}
+
+	moderator := checkModerator(c)
+
+	// Перетворення груп
+	targetGroupIDs, ok := h.targetGroups(ctx, c, req.TargetGroups, userIDObj, moderator)
+	if !ok {
+		return
 	}
 
 	// Створення питань з опціями
 	var questions []models.PollQuestion
 	for _, q := range req.Questions {
+		if err := h.validateQuestionMedia(q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid question media",
+				"details": fmt.Sprintf("Question '%s': %v", q.Text, err),
+			})
+			return
+		}
+
 		question := models.PollQuestion{
 			ID:         primitive.NewObjectID(),
 			Text:       q.Text,
@@ -256,10 +401,14 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 			MinRating:  q.MinRating,
 			MaxRating:  q.MaxRating,
 			MaxLength:  q.MaxLength,
+			RankLimit:  q.RankLimit,
+		}
+		if !q.Media.IsEmpty() {
+			question.Media = q.Media
 		}
 
-		// Додавання опцій для питань з вибором
-		if q.Type == models.QuestionTypeSingleChoice || q.Type == models.QuestionTypeMultipleChoice {
+		// Додавання опцій для питань з вибором та ранжуванням
+		if q.Type == models.QuestionTypeSingleChoice || q.Type == models.QuestionTypeMultipleChoice || q.Type == models.QuestionTypeRanking {
 			if len(q.Options) == 0 {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error":   "Invalid question options",
@@ -270,8 +419,9 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 
 			for _, opt := range q.Options {
 				option := models.PollOption{
-					ID:   primitive.NewObjectID(),
-					Text: opt.Text,
+					ID:    primitive.NewObjectID(),
+					Text:  opt.Text,
+					Image: opt.Image,
 				}
 				question.Options = append(question.Options, option)
 			}
@@ -288,35 +438,73 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 			}
 		}
 
+		// Валідація ranking питань
+		if q.Type == models.QuestionTypeRanking {
+			if err := question.ValidateQuestion(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid ranking question",
+					"details": fmt.Sprintf("Question '%s': %v", q.Text, err),
+				})
+				return
+			}
+		}
+
 		questions = append(questions, question)
 	}
 
+	// Умови показу питань (розгалуження) - після створення ID всіх питань
+	for i, q := range req.Questions {
+		if q.ShowIf == nil {
+			continue
+		}
+		condition, err := questionCondition(q.ShowIf, questions, i)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid question condition",
+				"details": fmt.Sprintf("Question '%s': %v", q.Text, err),
+			})
+			return
+		}
+		questions[i].ShowIf = condition
+	}
+
 	// Створення об'єкту опросу
 	poll := models.Poll{
-		ID:               primitive.NewObjectID(),
-		Title:            req.Title,
-		Description:      req.Description,
-		Category:         req.Category,
-		CreatorID:        userIDObj,
-		Questions:        questions,
-		Responses:        []models.PollResponse{},
-		Status:           models.PollStatusDraft, // За замовчуванням Draft
-		AllowMultiple:    req.AllowMultiple,
-		IsAnonymous:      req.IsAnonymous,
-		IsPublic:         req.IsPublic,
-		TargetGroups:     targetGroupIDs,
-		AgeRestriction:   req.AgeRestriction,
-		LocationRequired: req.LocationRequired,
-		StartDate:        req.StartDate,
-		EndDate:          req.EndDate,
-		Tags:             req.Tags,
-		ViewCount:        0,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-	}
-
-	// Якщо StartDate настав, змінюємо статус на Active
-	if !poll.StartDate.After(time.Now()) {
+		ID:                 primitive.NewObjectID(),
+		Title:              req.Title,
+		Description:        req.Description,
+		Language:           services.DetectLanguage(req.Title, req.Description),
+		Category:           req.Category,
+		CommunityID:        getCommunityID(c),
+		CreatorID:          userIDObj,
+		Questions:          questions,
+		Status:             models.PollStatusDraft, // За замовчуванням Draft
+		AllowMultiple:      req.AllowMultiple,
+		IsAnonymous:        req.IsAnonymous,
+		IsPublic:           req.IsPublic,
+		TargetGroups:       targetGroupIDs,
+		AgeRestriction:     req.AgeRestriction,
+		LocationRequired:   req.LocationRequired,
+		VerifiedVotersOnly: req.VerifiedVotersOnly,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		Tags:               tags,
+		ViewCount:          0,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	if err := poll.ValidateBranching(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid question condition",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Опитування модератора публікується одразу (Active; голосування відкривається в StartDate).
+	// Опитування користувача лишається чернеткою до схвалення: POST /polls/:id/submit відправляє його на модерацію
+	if moderator {
 		poll.Status = models.PollStatusActive
 	}
 
@@ -330,15 +518,142 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 		return
 	}
 
-	// Надсилання повідомлень цільовим групам
-	if len(poll.TargetGroups) > 0 {
-		go h.notificationService.NotifyNewPoll(poll.ID, poll.TargetGroups)
+	h.syncPollSummary(ctx, poll.ID)
+
+	if h.tagService != nil {
+		h.tagService.Track(ctx, models.ModulePolls, nil, tags)
+	}
+
+	// Опитування користувача анонсується після схвалення модератором (ApprovePoll)
+	if moderator {
+		h.announcePoll(c, &poll)
 	}
 
 	c.JSON(http.StatusCreated, poll)
 }
 
+// targetGroups перетворює ID цільових груп з запиту і перевіряє, що групи існують.
+// Користувач без прав модератора адресує опитування лише групам, в яких сам є учасником.
+// При помилці відповідь уже записана.
+func (h *PollHandler) targetGroups(ctx context.Context, c *gin.Context, raw []string, userID primitive.ObjectID, moderator bool) ([]primitive.ObjectID, bool) {
+	groupIDs := make([]primitive.ObjectID, 0, len(raw))
+	seen := make(map[primitive.ObjectID]bool, len(raw))
+	for _, groupIDStr := range raw {
+		groupID, err := primitive.ObjectIDFromHex(groupIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid target group ID",
+				"details": fmt.Sprintf("Group ID '%s' is not valid", groupIDStr),
+			})
+			return nil, false
+		}
+		if !seen[groupID] {
+			seen[groupID] = true
+			groupIDs = append(groupIDs, groupID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return groupIDs, true
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": groupIDs}}
+	if !moderator {
+		filter["members"] = userID
+	}
+	count, err := h.groupCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error checking target groups",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	if count != int64(len(groupIDs)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid target groups",
+			"details": "Target groups must exist and you must be a member of each of them",
+		})
+		return nil, false
+	}
+	return groupIDs, true
+}
+
+// claimPollAnnouncement позначає, що учасники цільових груп повідомлені про відкриття опитування.
+// Повертає true лише для першого виклику: схвалення й фонова задача не надсилають повідомлення двічі
+func claimPollAnnouncement(ctx context.Context, pollCollection *mongo.Collection, pollID primitive.ObjectID) (bool, error) {
+	result, err := pollCollection.UpdateOne(ctx,
+		bson.M{"_id": pollID, "announced_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"announced_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// enqueuePollAnnouncement записує повідомлення учасникам цільових груп в outbox у тій самій транзакції,
+// що й announced_at: позначене опитування не залишиться без повідомлення, якщо процес впаде
+func enqueuePollAnnouncement(ctx context.Context, pollCollection *mongo.Collection, notificationService *services.NotificationService, poll *models.Poll) error {
+	return notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		claimed, err := claimPollAnnouncement(ctx, pollCollection, poll.ID)
+		if err != nil || !claimed {
+			return err
+		}
+		userIDs, err := notificationService.TargetGroupMemberIDs(ctx, poll.TargetGroups)
+		if err != nil {
+			return err
+		}
+		return notificationService.Enqueue(ctx, services.NotificationIntent{
+			Source:  "poll.opened",
+			UserIDs: userIDs,
+			Title:   "Нове опитування",
+			Body:    fmt.Sprintf("Доступне нове опитування: %s", poll.Title),
+			Type:    "poll",
+			Data: map[string]interface{}{
+				"type":    "poll",
+				"poll_id": poll.ID.Hex(),
+				"action":  "open_poll",
+			},
+			RelatedID: &poll.ID,
+		})
+	})
+}
+
+// announcePoll надсилає повідомлення цільовим групам, якщо голосування вже відкрилося
+// (інакше - StartPollOpeningTask у StartDate); публічне опитування анонсуємо за інтересами
+func (h *PollHandler) announcePoll(c *gin.Context, poll *models.Poll) {
+	if len(poll.TargetGroups) > 0 {
+		if poll.StartDate.After(time.Now()) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := enqueuePollAnnouncement(ctx, h.pollCollection, h.notificationService, poll); err != nil {
+			log.Printf("Error announcing poll %s: %v", poll.ID.Hex(), err)
+		}
+	} else if poll.IsPublic && poll.Status == models.PollStatusActive {
+		notifyInterested(c, h.notificationService, h.taxonomyService, models.ModulePolls, poll.Category, poll.Tags,
+			services.InterestAudience{
+				CommunityID: poll.CommunityID,
+				Preference:  "polls",
+				ExcludeID:   poll.CreatorID,
+			},
+			"Нове опитування за вашими інтересами",
+			fmt.Sprintf("Доступне нове опитування: %s", poll.Title),
+			"poll",
+			map[string]interface{}{
+				"type":    "poll",
+				"poll_id": poll.ID.Hex(),
+				"action":  "open_poll",
+			},
+			poll.ID,
+		)
+	}
+}
+
 // GetAllPolls повертає список всіх опросів з фільтрацією та пагінацією
+// Список читається з карток poll_summaries: без питань і відповідей, лише лічильники.
+// Повне опитування - GET /polls/:id
 // @Summary Отримати список опросів
 // @Tags polls
 // @Accept json
@@ -369,12 +684,14 @@ func (h *PollHandler) GetAllPolls(c *gin.Context) {
 	}
 
 	// Побудова запиту
-	query := bson.M{}
+	query := communityScope(c, bson.M{})
 
-	// Фільтр за статусом
+	// Фільтр за статусом; черга модерації - GET /moderation/polls
+	status := bson.M{"$nin": models.PollModerationStatuses}
 	if filters.Status != "" {
-		query["status"] = filters.Status
+		status["$eq"] = filters.Status
 	}
+	query["status"] = status
 
 	// Фільтр за категорією
 	if filters.Category != "" {
@@ -399,28 +716,28 @@ func (h *PollHandler) GetAllPolls(c *gin.Context) {
 		query["is_public"] = *filters.IsPublic
 	}
 
+	// Фільтр за мовою: ?lang=uk або ?lang=uk,en
+	if !applyLanguageFilter(c, query) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Налаштування сортування
-	sortOptions := options.Find()
-	if filters.SortBy != "" {
+	// Налаштування сортування (лише за полями картки)
+	sort := bson.D{{Key: "created_at", Value: -1}}
+	if pollSummarySortFields[filters.SortBy] {
 		sortOrder := 1
 		if filters.SortOrder == "desc" {
 			sortOrder = -1
 		}
-		sortOptions.SetSort(bson.D{{Key: filters.SortBy, Value: sortOrder}})
-	} else {
-		sortOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+		sort = bson.D{{Key: filters.SortBy, Value: sortOrder}, {Key: "_id", Value: sortOrder}}
 	}
 
 	// Пагінація
 	skip := (filters.Page - 1) * filters.Limit
-	sortOptions.SetLimit(int64(filters.Limit))
-	sortOptions.SetSkip(int64(skip))
 
-	// Виконання запиту
-	cursor, err := h.pollCollection.Find(ctx, query, sortOptions)
+	polls, total, err := h.pollSummaries.List(ctx, query, sort, int64(skip), int64(filters.Limit))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error fetching polls",
@@ -428,32 +745,9 @@ func (h *PollHandler) GetAllPolls(c *gin.Context) {
 		})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var polls []models.Poll
-	if err := cursor.All(ctx, &polls); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error decoding polls",
-			"details": err.Error(),
-		})
-		return
-	}
-
-	// Підрахунок загальної кількості
-	total, err := h.pollCollection.CountDocuments(ctx, query)
-	if err != nil {
-		total = 0
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"polls": polls,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       total,
-			"total_pages": (total + int64(filters.Limit) - 1) / int64(filters.Limit),
-		},
-	})
+	info := newPageInfo(filters.Page, filters.Limit, total)
+	c.JSON(http.StatusOK, listResponse(c, polls, info, nil, gin.H{"polls": polls, "pagination": info}))
 }
 
 // GetPoll повертає детальну інформацію про конкретний опрос
@@ -480,7 +774,7 @@ func (h *PollHandler) GetPoll(c *gin.Context) {
 	defer cancel()
 
 	var poll models.Poll
-	err = h.pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -495,6 +789,17 @@ func (h *PollHandler) GetPoll(c *gin.Context) {
 		return
 	}
 
+	// Опитування на модерації та відхилені бачать лише автор і модератори
+	if poll.InModeration() {
+		viewerID, authErr := getUserID(c)
+		if (authErr != nil || poll.CreatorID != viewerID) && !checkModerator(c) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Poll not found",
+			})
+			return
+		}
+	}
+
 	// Збільшення лічильника переглядів
 	go func() {
 		updateCtx, updateCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -504,6 +809,7 @@ func (h *PollHandler) GetPoll(c *gin.Context) {
 			bson.M{"_id": pollID},
 			bson.M{"$inc": bson.M{"view_count": 1}},
 		)
+		h.pollSummaries.IncrementViews(updateCtx, pollID)
 	}()
 
 	c.JSON(http.StatusOK, poll)
@@ -545,7 +851,7 @@ func (h *PollHandler) UpdatePoll(c *gin.Context) {
 
 	// Перевірка існування опроса та прав доступу
 	var poll models.Poll
-	err = h.pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -578,18 +884,160 @@ func (h *PollHandler) UpdatePoll(c *gin.Context) {
 		return
 	}
 
+	// Чуже опитування модератор з зоною змінює лише в межах своїх категорій
+	if poll.CreatorID != userIDObj && !inModerationScope(c, "", poll.Category) {
+		return
+	}
+
+	// Автор без прав модератора змінює опитування лише до схвалення: зміни опублікованого пройшли б повз модерацію
+	moderator := checkModerator(c)
+	if !moderator && !poll.CanSubmitForModeration() && poll.Status != models.PollStatusPending {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Poll is published",
+			"details": "A published poll can only be changed by a moderator",
+		})
+		return
+	}
+
+	// Поля модерації змінюють лише POST /polls/:id/submit і рішення модератора
+	if !moderator {
+		delete(updateReq, "status")
+	}
+	for _, field := range []string{"is_verified", "moderator_note", "submitted_at", "moderated_by", "moderated_at", "published_at", "announced_at"} {
+		delete(updateReq, field)
+	}
+
 	// Видалення полів, які не повинні оновлюватися
 	delete(updateReq, "_id")
 	delete(updateReq, "creator_id")
 	delete(updateReq, "responses")
+	delete(updateReq, "total_responses")
+	delete(updateReq, "response_count")
 	delete(updateReq, "created_at")
 	delete(updateReq, "view_count")
 
+	if category, ok := updateReq["category"]; ok {
+		code, _ := category.(string)
+		if !validateCategory(c, h.taxonomyService, models.ModulePolls, code) {
+			return
+		}
+		if poll.CreatorID != userIDObj && !inModerationScope(c, "", code) {
+			return
+		}
+	}
+
+	var tags []string
+	if rawTags, ok := updateReq["tags"]; ok {
+		items, _ := rawTags.([]interface{})
+		requested := make([]string, 0, len(items))
+		for _, item := range items {
+			if tag, ok := item.(string); ok {
+				requested = append(requested, tag)
+			}
+		}
+		if tags, ok = canonicalizeTags(ctx, c, h.tagService, requested); !ok {
+			return
+		}
+		updateReq["tags"] = tags
+	}
+
+	// Цільові групи зберігаються як ObjectID, щоб перевірка участі та розсилка їх знаходили
+	if rawGroups, ok := updateReq["target_groups"]; ok {
+		items, _ := rawGroups.([]interface{})
+		requested := make([]string, 0, len(items))
+		for _, item := range items {
+			groupID, _ := item.(string)
+			requested = append(requested, groupID)
+		}
+		groupIDs, ok := h.targetGroups(ctx, c, requested, userIDObj, moderator)
+		if !ok {
+			return
+		}
+		updateReq["target_groups"] = groupIDs
+	}
+
+	// Питання зберігаються з типізованими ID, щоб умови показу посилалися на них так само, як після створення
+	if rawQuestions, ok := updateReq["questions"]; ok {
+		var questions []models.PollQuestion
+		encoded, err := json.Marshal(rawQuestions)
+		if err == nil {
+			err = json.Unmarshal(encoded, &questions)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid questions",
+				"details": err.Error(),
+			})
+			return
+		}
+		edited := models.Poll{Questions: questions}
+		if err := edited.ValidateBranching(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid question condition",
+				"details": err.Error(),
+			})
+			return
+		}
+		updateReq["questions"] = questions
+	}
+
+	// Відповіді, подані до ввімкнення режиму, не мають особи - режим змінюється лише до першого голосу
+	verifiedOnly, allowMultiple := poll.VerifiedVotersOnly, poll.AllowMultiple
+	if value, ok := updateReq["verified_voters_only"]; ok {
+		verifiedOnly, _ = value.(bool)
+		if verifiedOnly != poll.VerifiedVotersOnly && poll.TotalResponses > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Poll has responses",
+				"details": "verified_voters_only cannot be changed after voting has started",
+			})
+			return
+		}
+		updateReq["verified_voters_only"] = verifiedOnly
+	}
+	if value, ok := updateReq["allow_multiple"]; ok {
+		allowMultiple, _ = value.(bool)
+	}
+	if verifiedOnly && allowMultiple {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid voting settings",
+			"details": "verified_voters_only polls accept one vote per person, allow_multiple must be false",
+		})
+		return
+	}
+
+	// Вікове обмеження перевіряється при голосуванні - межі мають бути коректними
+	if rawRestriction, ok := updateReq["age_restriction"]; ok && rawRestriction != nil {
+		var restriction models.AgeRestriction
+		encoded, err := json.Marshal(rawRestriction)
+		if err == nil {
+			err = json.Unmarshal(encoded, &restriction)
+		}
+		if err == nil {
+			err = restriction.Validate()
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid age restriction",
+				"details": err.Error(),
+			})
+			return
+		}
+		updateReq["age_restriction"] = restriction
+	}
+
+	// Мова визначається за текстом опитування, а не приймається з запиту
+	delete(updateReq, "language")
+	title, _ := updateReq["title"].(string)
+	description, _ := updateReq["description"].(string)
+	if title != "" || description != "" {
+		updateReq["language"] = detectEditedLanguage(poll.Title, poll.Description, title, description)
+	}
+
 	updateReq["updated_at"] = time.Now()
 
 	result, err := h.pollCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": pollID},
+		communityScope(c, bson.M{"_id": pollID}),
 		bson.M{"$set": updateReq},
 	)
 	if err != nil {
@@ -607,11 +1055,92 @@ func (h *PollHandler) UpdatePoll(c *gin.Context) {
 		return
 	}
 
+	h.syncPollSummary(ctx, pollID)
+
+	// Лічильники кешу підсумків прив'язані до типів питань і варіантів - кеш збереться заново
+	if _, ok := updateReq["questions"]; ok {
+		if err := h.pollResults.Invalidate(ctx, pollID); err != nil {
+			log.Printf("Error invalidating results of poll %s: %v", pollID.Hex(), err)
+		}
+	}
+
+	if _, ok := updateReq["tags"]; ok && h.tagService != nil {
+		h.tagService.Track(ctx, models.ModulePolls, poll.Tags, tags)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Poll updated successfully",
 	})
 }
 
+// UpdatePollStatus змінює статус опитування (модерація)
+// Окремо від UpdatePoll, який доступний автору і приймає довільні поля
+// @Summary Змінити статус опитування
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "ID опроса"
+// @Param status body UpdatePollStatusRequest true "Новий статус"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/polls/{id}/status [put]
+func (h *PollHandler) UpdatePollStatus(c *gin.Context) {
+	pollID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid poll ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req UpdatePollStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.pollCollection.UpdateOne(
+		ctx,
+		withModerationScope(c, communityScope(c, bson.M{"_id": pollID})),
+		bson.M{"$set": bson.M{
+			"status":     req.Status,
+			"updated_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating poll status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		if respondOutOfScope(ctx, c, h.pollCollection, pollID) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Poll not found",
+		})
+		return
+	}
+
+	h.syncPollSummary(ctx, pollID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Poll status updated successfully",
+		"status":  req.Status,
+	})
+}
+
 // DeletePoll видаляє опрос
 // @Summary Видалити опрос
 // @Tags polls
@@ -633,82 +1162,347 @@ func (h *PollHandler) DeletePoll(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Перевірка існування та прав
-	var poll models.Poll
-	err = h.pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Poll not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error fetching poll",
-			"details": err.Error(),
-		})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Перевірка існування та прав
+	var poll models.Poll
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Poll not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching poll",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// ✅ Перевірка прав (тільки створювач або модератор)
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if poll.CreatorID != userIDObj && !checkModerator(c) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Access denied",
+			"details": "You don't have permission to delete this poll",
+		})
+		return
+	}
+	if poll.CreatorID != userIDObj && !inModerationScope(c, "", poll.Category) {
+		return
+	}
+
+	result, err := h.pollCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": pollID}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error deleting poll",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Poll not found",
+		})
+		return
+	}
+
+	if err := h.pollSummaries.Remove(ctx, pollID); err != nil {
+		log.Printf("Error removing poll summary %s: %v", pollID.Hex(), err)
+	}
+	if err := h.pollResponses.RemoveForPolls(ctx, []primitive.ObjectID{pollID}); err != nil {
+		log.Printf("Error removing responses of poll %s: %v", pollID.Hex(), err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Poll deleted successfully",
+	})
+}
+
+// ========================================
+// VOTING OPERATIONS
+// ========================================
+
+// checkAgeRestriction перевіряє вік голосуючого за датою народження з профілю.
+// При відмові відповідь уже записана.
+func (h *PollHandler) checkAgeRestriction(c *gin.Context, poll *models.Poll, voter *models.User, now time.Time) bool {
+	if poll.AgeRestriction == nil || (poll.AgeRestriction.MinAge == 0 && poll.AgeRestriction.MaxAge == 0) {
+		return true
+	}
+	age, ok := voter.AgeAt(now)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Birth date required",
+			"code":    "BIRTH_DATE_REQUIRED",
+			"details": "This poll has an age restriction, add your birth date to the profile to vote",
+		})
+		return false
+	}
+	if !poll.AgeRestriction.Allows(age) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":           "Not eligible to vote",
+			"code":            "AGE_RESTRICTED",
+			"details":         "Your age is outside the age range of this poll",
+			"age_restriction": poll.AgeRestriction,
+		})
+		return false
+	}
+	return true
+}
+
+// checkVoteLocation перевіряє, що голос надіслано з території громади.
+// При відмові відповідь уже записана.
+func (h *PollHandler) checkVoteLocation(c *gin.Context, location *models.Location) bool {
+	if location == nil || len(location.Coordinates) != 2 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Location required",
+			"code":    "LOCATION_REQUIRED",
+			"details": "This poll is limited to residents, send your location as [longitude, latitude] or verify your resident card",
+		})
+		return false
+	}
+	lng, lat := location.Coordinates[0], location.Coordinates[1]
+	if lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid location",
+			"details": "Coordinates must be [longitude, latitude]",
+		})
+		return false
+	}
+	if !h.municipality.Contains(*location) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Not eligible to vote",
+			"code":    "OUTSIDE_MUNICIPALITY",
+			"details": "Your location is outside the municipality",
+		})
+		return false
+	}
+	return true
+}
+
+// checkVoterEligibility перевіряє право голосу: цільові групи, вік, підтверджену особу, картку жителя
+// й геолокацію. Викликається і при першому голосі, і при зміні відповідей. При відмові відповідь уже записана.
+func (h *PollHandler) checkVoterEligibility(ctx context.Context, c *gin.Context, poll *models.Poll, userID primitive.ObjectID, location *models.Location, now time.Time) (*models.User, bool) {
+	var voter models.User
+	err := h.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{
+			"groups":             1,
+			"birth_date":         1,
+			"verification_level": 1,
+			"resident_card":      1,
+			"is_verified":        1,
+			"identity_hash":      1,
+		})).Decode(&voter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching user",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	// Непублічне опитування з цільовими групами - лише для учасників цих груп
+	if !poll.CanUserParticipate(voter) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Not eligible to vote",
+			"details": "This poll is limited to members of its target groups",
+		})
+		return nil, false
+	}
+
+	if !h.checkAgeRestriction(c, poll, &voter, now) {
+		return nil, false
+	}
+
+	// Офіційна консультація: лише особи, підтверджені Дією або BankID
+	if poll.VerifiedVotersOnly && !voter.HasVerifiedIdentity() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Identity verification required",
+			"code":    "IDENTITY_VERIFICATION_REQUIRED",
+			"details": "This poll is limited to users whose identity is confirmed with Diia or BankID",
+		})
+		return nil, false
+	}
+
+	// За громадський бюджет голосують лише підтверджені жителі (картка жителя)
+	resident := voter.IsVerifiedResident(now)
+	if poll.Category == models.PollCategoryBudget && !resident {
+		residentRequired(c, "Participatory budget voting is available to verified residents only")
+		return nil, false
+	}
+
+	// Підтверджений житель голосує без геолокації, решта - з точкою в межах громади
+	if poll.LocationRequired && !resident && !h.checkVoteLocation(c, location) {
+		return nil, false
+	}
+
+	return &voter, true
+}
+
+// VotePoll дозволяє користувачу проголосувати в опросі
+// @Summary Проголосувати в опросі
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "ID опроса"
+// @Param response body SubmitPollResponseRequest true "Відповіді користувача"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/polls/{id}/respond [post]
+func (h *PollHandler) VotePoll(c *gin.Context) {
+	pollID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid poll ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var req SubmitPollResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Отримання опроса
+	var poll models.Poll
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Poll not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching poll",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Перевірка статусу опроса
+	if poll.Status != models.PollStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Poll not available",
+			"details": "Poll is not active",
+		})
+		return
+	}
+
+	// Перевірка дат
+	now := time.Now()
+	if now.Before(poll.StartDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Poll not started",
+			"details": fmt.Sprintf("Poll will start at %s", poll.StartDate.Format(time.RFC3339)),
+		})
+		return
+	}
+	if now.After(poll.EndDate) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Poll ended",
+			"details": fmt.Sprintf("Poll ended at %s", poll.EndDate.Format(time.RFC3339)),
+		})
+		return
+	}
+
+	voter, ok := h.checkVoterEligibility(ctx, c, &poll, userIDObj, req.Location, now)
+	if !ok {
 		return
 	}
 
-	// ✅ Перевірка прав (тільки створювач або модератор)
-	userIDObj, err := getUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "User not authenticated",
-			"details": err.Error(),
-		})
-		return
+	// Створення відповіді. ID користувача зберігається і в анонімних опитуваннях:
+	// повторне голосування відхиляє унікальний індекс poll_responses, у результати й вивантаження ID не потрапляє
+	response := models.PollResponse{
+		ID:          primitive.NewObjectID(),
+		PollID:      pollID,
+		UserID:      userIDObj,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		SubmittedAt: now,
+	}
+	// Голос рахується на особу: другий акаунт тієї ж особи відхилить унікальний індекс (poll_id, identity_hash)
+	if poll.VerifiedVotersOnly {
+		response.IdentityHash = voter.IdentityHash
 	}
 
-	if poll.CreatorID != userIDObj && !checkModerator(c) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "Access denied",
-			"details": "You don't have permission to delete this poll",
-		})
+	answers, ok := h.parseAnswers(c, &poll, req.Answers)
+	if !ok {
 		return
 	}
+	response.Answers = answers
 
-	result, err := h.pollCollection.DeleteOne(ctx, bson.M{"_id": pollID})
-	if err != nil {
+	// Вставка відповіді та $inc лічильників: одночасні голоси не перезаписують один одного
+	if err := h.pollResponses.Submit(ctx, &poll, &response); err != nil {
+		if errors.Is(err, services.ErrAlreadyResponded) {
+			details := "You have already voted in this poll"
+			if poll.VerifiedVotersOnly {
+				details = "A vote with your verified identity has already been cast in this poll"
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Already voted",
+				"details": details,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error deleting poll",
+			"error":   "Error saving vote",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Poll not found",
-		})
-		return
-	}
+	h.syncPollSummary(ctx, pollID)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Poll deleted successfully",
+		"message": "Vote submitted successfully",
 	})
 }
 
-// ========================================
-// VOTING OPERATIONS
-// ========================================
-
-// VotePoll дозволяє користувачу проголосувати в опросі
-// @Summary Проголосувати в опросі
+// UpdatePollResponse дозволяє змінити свої відповіді до закриття опитування
+// @Summary Змінити відповіді в опросі
 // @Tags polls
 // @Accept json
 // @Produce json
 // @Param id path string true "ID опроса"
-// @Param response body SubmitPollResponseRequest true "Відповіді користувача"
+// @Param response body SubmitPollResponseRequest true "Нові відповіді користувача"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
 // @Failure 404 {object} gin.H
 // @Failure 409 {object} gin.H
-// @Router /api/v1/polls/{id}/respond [post]
-func (h *PollHandler) VotePoll(c *gin.Context) {
+// @Router /api/v1/polls/{id}/respond [put]
+func (h *PollHandler) UpdatePollResponse(c *gin.Context) {
 	pollID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -739,9 +1533,8 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Отримання опроса
 	var poll models.Poll
-	err = h.pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -756,71 +1549,73 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 		return
 	}
 
-	// Перевірка статусу опроса
-	if poll.Status != models.PollStatusActive {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Poll not available",
-			"details": "Poll is not active",
+	// Кожен голос в опитуванні з allow_multiple - окрема відповідь, змінювати нічого
+	if poll.AllowMultiple {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Responses cannot be edited",
+			"details": "This poll accepts multiple responses, submit a new one instead",
 		})
 		return
 	}
 
-	// Перевірка дат
+	// Відповіді змінюються, поки опитування приймає голоси
 	now := time.Now()
-	if now.Before(poll.StartDate) {
+	if poll.Status != models.PollStatusActive || now.After(poll.EndDate) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Poll not started",
-			"details": fmt.Sprintf("Poll will start at %s", poll.StartDate.Format(time.RFC3339)),
+			"error":   "Poll closed",
+			"details": "Responses can only be changed while the poll is active",
 		})
 		return
 	}
-	if now.After(poll.EndDate) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Poll ended",
-			"details": fmt.Sprintf("Poll ended at %s", poll.EndDate.Format(time.RFC3339)),
-		})
+
+	// Право голосу перевіряється знову: користувач міг вийти з цільової групи, втратити картку жителя
+	// чи підтвердження особи після першого голосу
+	if _, ok := h.checkVoterEligibility(ctx, c, &poll, userIDObj, req.Location, now); !ok {
 		return
 	}
 
-	// Перевірка, чи користувач вже голосував
-	if !poll.AllowMultiple {
-		for _, response := range poll.Responses {
-			if response.UserID == userIDObj { // ✅ UserID НЕ вказівник
-				c.JSON(http.StatusConflict, gin.H{
-					"error":   "Already voted",
-					"details": "You have already voted in this poll",
-				})
-				return
-			}
-		}
+	answers, ok := h.parseAnswers(c, &poll, req.Answers)
+	if !ok {
+		return
 	}
 
-	// Створення відповіді
-	response := models.PollResponse{
-		ID:          primitive.NewObjectID(),
-		PollID:      pollID,
-		Answers:     []models.PollAnswer{},
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		SubmittedAt: now,
+	// Заміна відповідей і перенесення голосів у кеші підсумків - атомарні оновлення, лічильники відповідей не змінюються
+	response, err := h.pollResponses.Update(ctx, &poll, userIDObj, answers)
+	if err != nil {
+		if errors.Is(err, services.ErrResponseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Response not found",
+				"details": "You have not voted in this poll yet",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating vote",
+			"details": err.Error(),
+		})
+		return
 	}
 
-	// Якщо опрос не анонімний, зберігаємо ID користувача
-	if !poll.IsAnonymous {
-		response.UserID = userIDObj
-	} else {
-		response.UserID = primitive.NilObjectID // ✅ Для анонімних
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Vote updated successfully",
+		"updated_at": response.UpdatedAt,
+	})
+}
+
+// parseAnswers перевіряє відповіді запиту за типами питань, умовами показу та обов'язковістю.
+// При помилці відповідь уже записана.
+func (h *PollHandler) parseAnswers(c *gin.Context, poll *models.Poll, requested []PollAnswerRequest) ([]models.PollAnswer, bool) {
+	answers := []models.PollAnswer{}
 
 	// Обробка кожної відповіді
-	for _, answer := range req.Answers {
+	for _, answer := range requested {
 		questionID, err := primitive.ObjectIDFromHex(answer.QuestionID)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Invalid question ID",
 				"details": fmt.Sprintf("Question ID '%s' is not valid", answer.QuestionID),
 			})
-			return
+			return nil, false
 		}
 
 		// Пошук питання
@@ -837,7 +1632,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 				"error":   "Question not found",
 				"details": fmt.Sprintf("Question with ID '%s' not found in this poll", answer.QuestionID),
 			})
-			return
+			return nil, false
 		}
 
 		pollAnswer := models.PollAnswer{
@@ -852,14 +1647,14 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 					"error":   "Missing required answer",
 					"details": fmt.Sprintf("Question '%s' is required", question.Text),
 				})
-				return
+				return nil, false
 			}
 			if len(answer.OptionIDs) > 1 {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error":   "Too many options",
 					"details": fmt.Sprintf("Question '%s' allows only one option", question.Text),
 				})
-				return
+				return nil, false
 			}
 
 			// Перевірка існування опції
@@ -870,7 +1665,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Invalid option ID",
 						"details": err.Error(),
 					})
-					return
+					return nil, false
 				}
 
 				optionExists := false
@@ -886,7 +1681,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Invalid option",
 						"details": "Selected option not found in question",
 					})
-					return
+					return nil, false
 				}
 
 				pollAnswer.OptionIDs = []primitive.ObjectID{optionID}
@@ -898,7 +1693,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 					"error":   "Missing required answer",
 					"details": fmt.Sprintf("Question '%s' is required", question.Text),
 				})
-				return
+				return nil, false
 			}
 
 			// Перевірка всіх вибраних опцій
@@ -910,7 +1705,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Invalid option ID",
 						"details": err.Error(),
 					})
-					return
+					return nil, false
 				}
 
 				optionExists := false
@@ -926,7 +1721,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Invalid option",
 						"details": "Selected option not found in question",
 					})
-					return
+					return nil, false
 				}
 
 				optionIDs = append(optionIDs, optionID)
@@ -941,7 +1736,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Missing required answer",
 						"details": fmt.Sprintf("Question '%s' is required", question.Text),
 					})
-					return
+					return nil, false
 				}
 				pollAnswer.TextAnswer = ""
 			} else {
@@ -952,7 +1747,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Text too long",
 						"details": fmt.Sprintf("Answer exceeds maximum length of %d", question.MaxLength),
 					})
-					return
+					return nil, false
 				}
 
 				pollAnswer.TextAnswer = textValue // ✅ Присвоїти string
@@ -964,7 +1759,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 					"error":   "Missing required answer",
 					"details": fmt.Sprintf("Question '%s' is required", question.Text),
 				})
-				return
+				return nil, false
 			}
 			if answer.NumberAnswer != nil {
 				if *answer.NumberAnswer < question.MinRating || *answer.NumberAnswer > question.MaxRating {
@@ -972,7 +1767,7 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 						"error":   "Invalid rating",
 						"details": fmt.Sprintf("Rating must be between %d and %d", question.MinRating, question.MaxRating),
 					})
-					return
+					return nil, false
 				}
 			}
 			pollAnswer.NumberAnswer = answer.NumberAnswer
@@ -983,19 +1778,65 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 					"error":   "Missing required answer",
 					"details": fmt.Sprintf("Question '%s' is required", question.Text),
 				})
-				return
+				return nil, false
 			}
 			pollAnswer.BoolAnswer = answer.BoolAnswer
+
+		case models.QuestionTypeRanking:
+			if len(answer.OptionIDs) == 0 {
+				if question.IsRequired {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":   "Missing required answer",
+						"details": fmt.Sprintf("Question '%s' is required", question.Text),
+					})
+					return nil, false
+				}
+				break
+			}
+
+			// Порядок option_ids зберігається: перший елемент - перше місце
+			for _, optIDStr := range answer.OptionIDs {
+				optionID, err := primitive.ObjectIDFromHex(optIDStr)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":   "Invalid option ID",
+						"details": err.Error(),
+					})
+					return nil, false
+				}
+				pollAnswer.OptionIDs = append(pollAnswer.OptionIDs, optionID)
+			}
+
+			if err := question.ValidateAnswer(pollAnswer); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid ranking",
+					"details": err.Error(),
+				})
+				return nil, false
+			}
 		}
 
-		response.Answers = append(response.Answers, pollAnswer)
+		answers = append(answers, pollAnswer)
+	}
+
+	// Розгалуження: відповідь на приховане умовою питання відхиляється,
+	// обов'язковими є лише показані учаснику питання
+	visible := poll.VisibleQuestions(answers)
+	for _, answer := range answers {
+		if !visible[answer.QuestionID] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Question not shown",
+				"details": fmt.Sprintf("Question '%s' is skipped by its condition and cannot be answered", answer.QuestionID.Hex()),
+			})
+			return nil, false
+		}
 	}
 
 	// Перевірка, що всі обов'язкові питання мають відповіді
 	for _, question := range poll.Questions {
-		if question.IsRequired {
+		if question.IsRequired && visible[question.ID] {
 			found := false
-			for _, answer := range response.Answers {
+			for _, answer := range answers {
 				if answer.QuestionID == question.ID {
 					found = true
 					break
@@ -1006,52 +1847,15 @@ func (h *PollHandler) VotePoll(c *gin.Context) {
 					"error":   "Missing required answers",
 					"details": fmt.Sprintf("Question '%s' is required but not answered", question.Text),
 				})
-				return
+				return nil, false
 			}
 		}
 	}
-
-	// Оновлення лічильників голосів для вибраних опцій
-	//for _, answer := range response.Answers {
-	//	for _, optionID := range answer.OptionIDs {
-	//		// Пошук питання та опції
-	//		for i, question := range poll.Questions {
-	//			if question.ID == answer.QuestionID {
-	//				for j, option := range question.Options {
-	//					if option.ID == optionID {
-	//						poll.Questions[i].Options[j].Votes++
-	//						break
-	//					}
-	//				}
-	//				break
-	//			}
-	//		}
-	//	}
-	//}
-
-	// Додавання відповіді до опроса
-	poll.Responses = append(poll.Responses, response)
-
-	// Збереження оновленого опроса
-	_, err = h.pollCollection.ReplaceOne(
-		ctx,
-		bson.M{"_id": pollID},
-		poll,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Error saving vote",
-			"details": err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Vote submitted successfully",
-	})
+	return answers, true
 }
 
-// GetPollResults повертає результати опросу з використанням MongoDB aggregation
+// GetPollResults повертає результати опитування з кешу підсумків (poll_results).
+// Лічильники оновлюються з кожною відповіддю, відсутній кеш збирається агрегацією MongoDB.
 // @Summary Отримати результати опросу
 // @Tags polls
 // @Accept json
@@ -1075,7 +1879,7 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 	defer cancel()
 
 	var poll models.Poll
-	err = h.pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID})).Decode(&poll)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -1090,47 +1894,47 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 		return
 	}
 
-	// ✅ ВИКОРИСТАННЯ MongoDB AGGREGATION для ефективного підрахунку
+	tally, err := h.pollResults.Get(ctx, &poll)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching poll results",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	results := gin.H{
 		"poll_id":         poll.ID,
 		"title":           poll.Title,
-		"total_responses": len(poll.Responses),
+		"total_responses": tally.TotalResponses,
+		"updated_at":      tally.UpdatedAt,
 		"questions":       []gin.H{},
 	}
 
 	// Обробка кожного питання
 	for _, question := range poll.Questions {
+		counts := tally.Questions[question.ID.Hex()]
 		questionResult := gin.H{
 			"question_id":   question.ID,
 			"text":          question.Text,
 			"type":          question.Type,
+			"media":         question.Media,
 			"total_answers": 0,
 		}
 
 		switch question.Type {
 		case models.QuestionTypeSingleChoice, models.QuestionTypeMultipleChoice:
-			// Підрахунок голосів для кожної опції
+			// Голоси за кожну опцію
 			options := []gin.H{}
 			totalVotes := 0
 
 			for _, option := range question.Options {
-				optionVotes := 0
-				for _, response := range poll.Responses {
-					for _, answer := range response.Answers {
-						if answer.QuestionID == question.ID {
-							for _, optID := range answer.OptionIDs {
-								if optID == option.ID {
-									optionVotes++
-									break
-								}
-							}
-						}
-					}
-				}
+				optionVotes := counts.Options[option.ID.Hex()]
 				totalVotes += optionVotes
 				options = append(options, gin.H{
 					"option_id":  option.ID,
 					"text":       option.Text,
+					"image":      option.Image,
 					"votes":      optionVotes,
 					"percentage": 0.0, // Буде обчислено пізніше
 				})
@@ -1149,36 +1953,32 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 			questionResult["total_answers"] = totalVotes
 
 		case models.QuestionTypeText:
-			// Збір текстових відповідей
-			textAnswers := []gin.H{}
-			for _, response := range poll.Responses {
-				for _, answer := range response.Answers {
-					if answer.QuestionID == question.ID && answer.TextAnswer != "" {
-						textAnswers = append(textAnswers, gin.H{
-							"text":       answer.TextAnswer,
-							"created_at": response.CreatedAt,
-						})
-					}
-				}
+			// Останні текстові відповіді, нові першими
+			textAnswers, err := h.pollResults.TextAnswers(ctx, poll.ID, question.ID, models.MaxResultTextAnswers)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error fetching text answers",
+					"details": err.Error(),
+				})
+				return
 			}
 			questionResult["text_answers"] = textAnswers
-			questionResult["total_answers"] = len(textAnswers)
+			questionResult["total_answers"] = counts.Answers
 
-		case models.QuestionTypeRating:
-			// Підрахунок середнього рейтингу
+		case models.QuestionTypeRating, models.QuestionTypeScale:
+			// Середня оцінка за розподілом значень
 			var sum int
 			var count int
-			ratings := make(map[int]int)
-
-			for _, response := range poll.Responses {
-				for _, answer := range response.Answers {
-					if answer.QuestionID == question.ID && answer.NumberAnswer != nil {
-						rating := *answer.NumberAnswer
-						sum += rating
-						count++
-						ratings[rating]++
-					}
+			ratings := make(map[int]int, len(counts.Values))
+
+			for value, votes := range counts.Values {
+				rating, err := strconv.Atoi(value)
+				if err != nil {
+					continue
 				}
+				sum += rating * votes
+				count += votes
+				ratings[rating] = votes
 			}
 
 			var average float64
@@ -1192,20 +1992,8 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 
 		case models.QuestionTypeYesNo:
 			// Підрахунок Так/Ні
-			yesCount := 0
-			noCount := 0
-
-			for _, response := range poll.Responses {
-				for _, answer := range response.Answers {
-					if answer.QuestionID == question.ID && answer.BoolAnswer != nil {
-						if *answer.BoolAnswer {
-							yesCount++
-						} else {
-							noCount++
-						}
-					}
-				}
-			}
+			yesCount := counts.Yes
+			noCount := counts.No
 
 			total := yesCount + noCount
 			var yesPercentage, noPercentage float64
@@ -1219,6 +2007,14 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 			questionResult["yes_percentage"] = fmt.Sprintf("%.2f", yesPercentage)
 			questionResult["no_percentage"] = fmt.Sprintf("%.2f", noPercentage)
 			questionResult["total_answers"] = total
+
+		case models.QuestionTypeRanking:
+			// Підсумок за методом Борда та середнім місцем
+			ranking, total := question.RankingFromTally(counts)
+			questionResult["ranking"] = ranking
+			questionResult["rank_positions"] = question.RankPositions()
+			questionResult["scoring"] = "borda"
+			questionResult["total_answers"] = total
 		}
 
 		results["questions"] = append(results["questions"].([]gin.H), questionResult)
@@ -1232,41 +2028,114 @@ func (h *PollHandler) GetPollResults(c *gin.Context) {
 // ========================================
 
 // StartPollCleanupTask запускає фонову задачу для видалення старих опросів
-func StartPollCleanupTask(pollCollection *mongo.Collection) {
+func StartPollCleanupTask(pollCollection *mongo.Collection, pollSummaries *services.PollSummaryService, auditLog *services.AuditLogService, pollResponses *services.PollResponseService) {
 	ticker := time.NewTicker(24 * time.Hour)
 
 	// Перший запуск відразу
 	go func() {
-		cleanupOldPolls(pollCollection)
+		cleanupOldPolls(pollCollection, pollSummaries, auditLog, pollResponses)
 	}()
 
 	// Регулярне виконання
 	go func() {
 		for range ticker.C {
-			cleanupOldPolls(pollCollection)
+			cleanupOldPolls(pollCollection, pollSummaries, auditLog, pollResponses)
+		}
+	}()
+}
+
+// StartPollOpeningTask повідомляє учасників цільових груп, коли відкривається голосування
+// в запланованому опитуванні (StartDate настав після публікації)
+func StartPollOpeningTask(pollCollection *mongo.Collection, notificationService *services.NotificationService) {
+	ticker := time.NewTicker(5 * time.Minute)
+
+	go func() {
+		announceOpenedPolls(pollCollection, notificationService)
+		for range ticker.C {
+			announceOpenedPolls(pollCollection, notificationService)
 		}
 	}()
 }
 
-// cleanupOldPolls видаляє опроси старші 90 днів
-func cleanupOldPolls(pollCollection *mongo.Collection) {
+// announceOpenedPolls розсилає повідомлення про відкриті опитування, учасники груп яких ще не повідомлені
+func announceOpenedPolls(pollCollection *mongo.Collection, notificationService *services.NotificationService) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	cursor, err := pollCollection.Find(ctx, bson.M{
+		"status":          models.PollStatusActive,
+		"start_date":      bson.M{"$lte": now},
+		"end_date":        bson.M{"$gt": now},
+		"target_groups.0": bson.M{"$exists": true},
+		"announced_at":    bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"title": 1, "target_groups": 1}))
+	if err != nil {
+		log.Printf("Error fetching opened polls: %v", err)
+		return
+	}
+	var polls []models.Poll
+	err = cursor.All(ctx, &polls)
+	cursor.Close(ctx)
+	if err != nil {
+		log.Printf("Error decoding opened polls: %v", err)
+		return
+	}
+
+	for i := range polls {
+		if err := enqueuePollAnnouncement(ctx, pollCollection, notificationService, &polls[i]); err != nil {
+			log.Printf("Error notifying target groups of poll %s: %v", polls[i].ID.Hex(), err)
+		}
+	}
+}
+
+// cleanupOldPolls видаляє опроси старші 90 днів разом з відповідями; кількість видалених записується в журнал аудиту
+func cleanupOldPolls(pollCollection *mongo.Collection, pollSummaries *services.PollSummaryService, auditLog *services.AuditLogService, pollResponses *services.PollResponseService) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	// Видалення опросів старших 90 днів
 	cutoffDate := time.Now().AddDate(0, 0, -90)
 
-	result, err := pollCollection.DeleteMany(ctx, bson.M{
+	filter := bson.M{
 		"end_date": bson.M{"$lt": cutoffDate},
-	})
+	}
+	values, err := pollCollection.Distinct(ctx, "_id", filter)
+	if err != nil {
+		log.Printf("Error cleaning up old polls: %v", err)
+		return
+	}
+	pollIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if pollID, ok := value.(primitive.ObjectID); ok {
+			pollIDs = append(pollIDs, pollID)
+		}
+	}
 
+	result, err := pollCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": pollIDs}})
 	if err != nil {
-		fmt.Printf("Error cleaning up old polls: %v\n", err)
+		log.Printf("Error cleaning up old polls: %v", err)
 		return
 	}
 
+	// Картки видаляються тим самим фільтром: end_date є в обох колекціях
+	if err := pollSummaries.RemoveMatching(ctx, filter); err != nil {
+		log.Printf("Error cleaning up old poll summaries: %v", err)
+	}
+	if err := pollResponses.RemoveForPolls(ctx, pollIDs); err != nil {
+		log.Printf("Error cleaning up responses of old polls: %v", err)
+	}
+
 	if result.DeletedCount > 0 {
-		fmt.Printf("Deleted %d old polls (older than 90 days)\n", result.DeletedCount)
+		auditLog.Record(ctx, models.AuditLogEntry{
+			Actor:        models.AuditActorPollCleanup,
+			Action:       models.AuditActionPollsPurged,
+			ResourceType: models.AuditResourcePoll,
+			Details: map[string]interface{}{
+				"deleted_count": result.DeletedCount,
+				"ended_before":  cutoffDate,
+			},
+		})
 	}
 }
 
@@ -1434,3 +2303,66 @@ func (h *PollHandler) GetPollStats(c *gin.Context) {
 		"timestamp":         time.Now(),
 	})
 }
+
+// GetRecommendedPolls - GET /polls/recommended
+// Активні публічні опитування за інтересами користувача, у яких він ще не брав участі
+func (h *PollHandler) GetRecommendedPolls(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	responded, err := h.pollResponses.RespondedPollIDs(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching poll responses",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	filter := communityScope(c, bson.M{
+		"status":     models.PollStatusActive,
+		"is_public":  true,
+		"start_date": bson.M{"$lte": now},
+		"end_date":   bson.M{"$gt": now},
+		"_id":        bson.M{"$nin": responded},
+	})
+	personalized, ok := recommendationScope(ctx, c, h.userCollection, h.taxonomyService, models.ModulePolls, filter)
+	if !ok {
+		return
+	}
+
+	cursor, err := h.pollCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "end_date", Value: 1}}).
+		SetLimit(recommendationLimit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching polls",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	polls := []models.Poll{}
+	if err := cursor.All(ctx, &polls); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error decoding polls",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"polls":        polls,
+		"personalized": personalized,
+	})
+}
@@ -0,0 +1,291 @@
+// internal/handlers/poll_moderation.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PollModerationRequest - рішення модератора щодо опитування
+type PollModerationRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// PollRejectionRequest - відхилення опитування; автор бачить примітку в moderator_note
+type PollRejectionRequest struct {
+	Note string `json:"note" binding:"required,min=10,max=500"`
+}
+
+// SubmitPoll - POST /polls/:id/submit
+// Автор відправляє чернетку або відхилене опитування на модерацію (pending)
+func (h *PollHandler) SubmitPoll(c *gin.Context) {
+	pollID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid poll ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var poll models.Poll
+	err = h.pollCollection.FindOne(ctx, communityScope(c, bson.M{"_id": pollID, "creator_id": userIDObj})).Decode(&poll)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Poll not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching poll",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !poll.CanSubmitForModeration() {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Poll cannot be submitted",
+			"details": fmt.Sprintf("Poll in status '%s' cannot be submitted for moderation", poll.Status),
+		})
+		return
+	}
+	now := time.Now()
+	if !poll.EndDate.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Poll ended",
+			"details": "Change the end date before submitting the poll",
+		})
+		return
+	}
+
+	// Статус у фільтрі: одночасна відправка або рішення модератора не перезаписуються
+	result, err := h.pollCollection.UpdateOne(ctx,
+		communityScope(c, bson.M{"_id": pollID, "status": poll.Status}),
+		bson.M{"$set": bson.M{
+			"status":       models.PollStatusPending,
+			"submitted_at": now,
+			"updated_at":   now,
+		}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error submitting poll",
+			"details": err.Error(),
+		})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Poll cannot be submitted",
+			"details": "Poll status has changed, reload it",
+		})
+		return
+	}
+
+	h.syncPollSummary(ctx, pollID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Poll submitted for moderation",
+		"status":       models.PollStatusPending,
+		"submitted_at": now,
+	})
+}
+
+// GetPendingPolls - GET /moderation/polls
+// Опитування, що очікують модерації, спершу давніше відправлені
+func (h *PollHandler) GetPendingPolls(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.pollCollection.Find(ctx,
+		communityScope(c, withModerationScope(c, bson.M{"status": models.PollStatusPending})),
+		options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}).SetLimit(100),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching pending polls",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	polls := []models.Poll{}
+	if err := cursor.All(ctx, &polls); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding polls",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"polls": polls,
+		"count": len(polls),
+	})
+}
+
+// ApprovePoll - POST /moderation/polls/:id/approve
+// Публікує опитування (active); цільові групи та зацікавлені користувачі отримують анонс
+func (h *PollHandler) ApprovePoll(c *gin.Context) {
+	var req PollModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	h.moderatePoll(c, models.ModerationDecisionApproved, req.Note)
+}
+
+// RejectPoll - POST /moderation/polls/:id/reject
+// Повертає опитування автору з приміткою; виправлене опитування можна відправити знову
+func (h *PollHandler) RejectPoll(c *gin.Context) {
+	var req PollRejectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	h.moderatePoll(c, models.ModerationDecisionRejected, req.Note)
+}
+
+// moderatePoll застосовує рішення до опитування в статусі pending
+func (h *PollHandler) moderatePoll(c *gin.Context, decision, note string) {
+	pollID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid poll ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	moderatorID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not authenticated",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{
+		"moderator_note": note,
+		"moderated_by":   moderatorID,
+		"moderated_at":   now,
+		"updated_at":     now,
+	}
+	if decision == models.ModerationDecisionApproved {
+		set["status"] = models.PollStatusActive
+		set["is_verified"] = true
+		set["published_at"] = now
+	} else {
+		set["status"] = models.PollStatusRejected
+	}
+
+	var poll models.Poll
+	err = h.pollCollection.FindOneAndUpdate(ctx,
+		withModerationScope(c, communityScope(c, bson.M{"_id": pollID, "status": models.PollStatusPending})),
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&poll)
+	if err == mongo.ErrNoDocuments {
+		if respondOutOfScope(ctx, c, h.pollCollection, pollID) {
+			return
+		}
+		count, countErr := h.pollCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": pollID}), options.Count().SetLimit(1))
+		if countErr == nil && count > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Poll is not awaiting moderation",
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Poll not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error moderating poll",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.syncPollSummary(ctx, pollID)
+
+	submittedAt := poll.CreatedAt
+	if poll.SubmittedAt != nil {
+		submittedAt = *poll.SubmittedAt
+	}
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: poll.CommunityID,
+		ModeratorID: moderatorID,
+		ContentType: models.ModerationContentPoll,
+		ContentID:   poll.ID,
+		AuthorID:    poll.CreatorID,
+		Decision:    decision,
+		Reason:      note,
+		SubmittedAt: submittedAt,
+	})
+
+	title, body := "Опитування схвалено", fmt.Sprintf("Ваше опитування «%s» опубліковано", poll.Title)
+	if decision == models.ModerationDecisionRejected {
+		// Відхилений контент знижує довіру до автора
+		h.trustService.RecordRemoval(ctx, poll.CreatorID)
+		title, body = "Опитування відхилено", fmt.Sprintf("Опитування «%s» відхилено модератором: %s", poll.Title, note)
+	} else {
+		h.announcePoll(c, &poll)
+	}
+
+	err = h.notificationService.SendNotificationToUser(ctx, poll.CreatorID, title, body, models.NotificationTypePoll,
+		map[string]interface{}{
+			"type":    "poll",
+			"poll_id": poll.ID.Hex(),
+			"action":  "open_poll",
+			"status":  poll.Status,
+		}, &poll.ID)
+	if err != nil {
+		log.Printf("Error notifying author of poll %s: %v", poll.ID.Hex(), err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Poll moderated successfully",
+		"status":  poll.Status,
+		"poll":    poll,
+	})
+}
@@ -0,0 +1,204 @@
+// internal/handlers/profile.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProfileHandler - публічні профілі користувачів і налаштування приватності
+type ProfileHandler struct {
+	userCollection *mongo.Collection
+	presence       *services.PresenceService
+}
+
+// UpdatePrivacyRequest - змінюються лише передані поля
+type UpdatePrivacyRequest struct {
+	ShowProfession   *bool `json:"show_profession"`
+	ShowInterests    *bool `json:"show_interests"`
+	ShowBusinessInfo *bool `json:"show_business_info"`
+	HidePresence     *bool `json:"hide_presence"`
+}
+
+// PublicProfile - профіль, який бачать інші мешканці.
+// Ім'я та аватар показуються завжди, решта полів - лише за згодою користувача.
+// Присутність показується, якщо користувач її не приховав.
+type PublicProfile struct {
+	ID           primitive.ObjectID   `json:"id"`
+	FirstName    string               `json:"first_name"`
+	LastName     string               `json:"last_name"`
+	Avatar       string               `json:"avatar,omitempty"`
+	IsVerified   bool                 `json:"is_verified"`
+	Profession   string               `json:"profession,omitempty"`
+	Interests    []string             `json:"interests,omitempty"`
+	BusinessInfo *models.BusinessInfo `json:"business_info,omitempty"`
+	IsOnline     *bool                `json:"is_online,omitempty"`
+	LastSeenAt   *time.Time           `json:"last_seen_at,omitempty"`
+}
+
+func NewProfileHandler(userCollection *mongo.Collection, presence *services.PresenceService) *ProfileHandler {
+	return &ProfileHandler{
+		userCollection: userCollection,
+		presence:       presence,
+	}
+}
+
+// GetPublicProfile - GET /users/:id/public
+func (h *ProfileHandler) GetPublicProfile(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Зчитуються лише поля, які можуть потрапити в публічний профіль
+	var user models.User
+	err = h.userCollection.FindOne(ctx,
+		bson.M{"_id": userID, "is_deleted": bson.M{"$ne": true}},
+		options.FindOne().SetProjection(bson.M{
+			"first_name":    1,
+			"last_name":     1,
+			"avatar":        1,
+			"is_verified":   1,
+			"profession":    1,
+			"interests":     1,
+			"business_info": 1,
+			"privacy":       1,
+			"last_seen_at":  1,
+		}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching profile",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	profile := publicProfile(&user)
+	if user.ShowsPresence() {
+		online := h.presence.IsOnline(user.ID)
+		profile.IsOnline = &online
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateMyPrivacy - PUT /auth/profile/privacy
+// Вибір полів, які показуються в публічному профілі
+func (h *ProfileHandler) UpdateMyPrivacy(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if req.ShowProfession != nil {
+		update["privacy.show_profession"] = *req.ShowProfession
+	}
+	if req.ShowInterests != nil {
+		update["privacy.show_interests"] = *req.ShowInterests
+	}
+	if req.ShowBusinessInfo != nil {
+		update["privacy.show_business_info"] = *req.ShowBusinessInfo
+	}
+	if req.HidePresence != nil {
+		update["privacy.hide_presence"] = *req.HidePresence
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetProjection(bson.M{"privacy": 1}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating privacy settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	privacy := models.PrivacySettings{}
+	if user.Privacy != nil {
+		privacy = *user.Privacy
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Privacy settings updated successfully",
+		"privacy": privacy,
+	})
+}
+
+// publicProfile залишає в профілі лише поля, дозволені налаштуваннями приватності
+func publicProfile(user *models.User) PublicProfile {
+	profile := PublicProfile{
+		ID:         user.ID,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Avatar:     user.Avatar,
+		IsVerified: user.IsVerified,
+	}
+	if user.ShowsPresence() {
+		profile.LastSeenAt = user.LastSeenAt
+	}
+
+	privacy := user.Privacy
+	if privacy == nil {
+		return profile
+	}
+	if privacy.ShowProfession {
+		profile.Profession = user.Profession
+	}
+	if privacy.ShowInterests {
+		profile.Interests = user.Interests
+	}
+	if privacy.ShowBusinessInfo {
+		profile.BusinessInfo = user.BusinessInfo
+	}
+	return profile
+}
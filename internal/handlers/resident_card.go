@@ -0,0 +1,486 @@
+// internal/handlers/resident_card.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ResidentCardHandler - прив'язка картки жителя та її перевірка адміністрацією
+type ResidentCardHandler struct {
+	userCollection      *mongo.Collection
+	registry            services.ResidentRegistry
+	notificationService *services.NotificationService
+	hashKey             string
+	validity            time.Duration
+}
+
+// NewResidentCardHandler створює обробник карток жителя
+func NewResidentCardHandler(userCollection *mongo.Collection, registry services.ResidentRegistry, notificationService *services.NotificationService, hashKey string, validityDays int) *ResidentCardHandler {
+	if validityDays <= 0 {
+		validityDays = 365
+	}
+	return &ResidentCardHandler{
+		userCollection:      userCollection,
+		registry:            registry,
+		notificationService: notificationService,
+		hashKey:             hashKey,
+		validity:            time.Duration(validityDays) * 24 * time.Hour,
+	}
+}
+
+type LinkResidentCardRequest struct {
+	CardNumber string `json:"card_number" binding:"required,max=40"`
+	LastName   string `json:"last_name" binding:"required,min=2,max=100"`
+}
+
+type VerifyResidentCardRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RejectResidentCardRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// residentRequired - відповідь для функцій лише для підтверджених жителів
+func residentRequired(c *gin.Context, details string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Resident verification required",
+		"code":    "RESIDENT_VERIFICATION_REQUIRED",
+		"details": details,
+	})
+}
+
+// isVerifiedResident перевіряє рівень верифікації користувача
+func isVerifiedResident(ctx context.Context, userCollection *mongo.Collection, userID primitive.ObjectID) (bool, error) {
+	var user models.User
+	err := userCollection.FindOne(ctx,
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"verification_level": 1, "resident_card": 1}),
+	).Decode(&user)
+	if err != nil {
+		return false, err
+	}
+	return user.IsVerifiedResident(time.Now()), nil
+}
+
+// GetMyResidentCard - GET /users/me/resident-card
+func (h *ResidentCardHandler) GetMyResidentCard(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx,
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"verification_level": 1, "resident_card": 1}),
+	).Decode(&user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	level := user.VerificationLevel
+	if level == "" {
+		level = models.VerificationLevelBasic
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"resident_card":      user.ResidentCard,
+		"verification_level": level,
+		"is_resident":        user.IsVerifiedResident(time.Now()),
+	})
+}
+
+// LinkResidentCard - POST /users/me/resident-card
+// Прив'язує картку жителя. Якщо реєстр підтверджує картку, рівень resident надається одразу;
+// інакше (картку не знайдено, реєстр недоступний чи не підключений) заявку перевіряє адміністратор.
+// Повторна прив'язка замінює попередню картку.
+func (h *ResidentCardHandler) LinkResidentCard(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req LinkResidentCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	number := models.NormalizeResidentCardNumber(req.CardNumber)
+	if number == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid card number",
+			"details": "Card number must contain 6-20 letters or digits",
+		})
+		return
+	}
+	lastName := strings.TrimSpace(req.LastName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	hash := services.ResidentCardHash(number, h.hashKey)
+	taken, err := h.userCollection.CountDocuments(ctx, bson.M{
+		"_id":                       bson.M{"$ne": userID},
+		"resident_card.number_hash": hash,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Database error",
+		})
+		return
+	}
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This card is already linked to another account",
+		})
+		return
+	}
+
+	now := time.Now()
+	card := models.ResidentCard{
+		NumberHash:   hash,
+		NumberMasked: models.MaskResidentCardNumber(number),
+		LastName:     lastName,
+		Status:       models.ResidentCardPending,
+		Registry:     h.registry.Name(),
+		SubmittedAt:  now,
+	}
+
+	result, err := h.registry.Verify(ctx, services.ResidentCardCheck{CardNumber: number, LastName: lastName})
+	if err != nil {
+		log.Printf("Resident registry check failed: %v", err)
+		card.RegistryResult = services.ResidentRegistryError
+	} else {
+		card.RegistryResult = result.Status
+		card.RegistryReference = result.Reference
+	}
+
+	level := models.VerificationLevelBasic
+	// Картка, строк дії якої за реєстром минув, перевіряється адміністратором
+	if card.RegistryResult == services.ResidentRegistryMatched && (result.ValidUntil == nil || result.ValidUntil.After(now)) {
+		expiresAt := now.Add(h.validity)
+		if result.ValidUntil != nil {
+			expiresAt = *result.ValidUntil
+		}
+		card.Status = models.ResidentCardVerified
+		card.VerifiedAt = &now
+		card.ExpiresAt = &expiresAt
+		level = models.VerificationLevelResident
+	}
+
+	update, err := h.userCollection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{
+			"resident_card":      card,
+			"verification_level": level,
+			"updated_at":         now,
+		}},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "This card is already linked to another account",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error linking resident card",
+		})
+		return
+	}
+	if update.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	if card.Status == models.ResidentCardVerified {
+		c.JSON(http.StatusOK, gin.H{
+			"message":            "Resident card verified",
+			"resident_card":      card,
+			"verification_level": level,
+		})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":            "Resident card submitted for review",
+		"resident_card":      card,
+		"verification_level": level,
+	})
+}
+
+// UnlinkResidentCard - DELETE /users/me/resident-card
+func (h *ResidentCardHandler) UnlinkResidentCard(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.userCollection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$unset": bson.M{"resident_card": ""},
+			"$set": bson.M{
+				"verification_level": models.VerificationLevelBasic,
+				"updated_at":         time.Now(),
+			},
+		},
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error removing resident card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Resident card removed",
+	})
+}
+
+// GetResidentCards - GET /admin/resident-cards?status=pending
+// Картки жителів за статусом (за замовчуванням - очікують перевірки), від найстаріших заявок
+func (h *ResidentCardHandler) GetResidentCards(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ResidentCardPending)
+	switch status {
+	case models.ResidentCardPending, models.ResidentCardVerified, models.ResidentCardRejected, models.ResidentCardRevoked:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status",
+		})
+		return
+	}
+	page, limit := pageParams(c, 20, 100)
+
+	filter := bson.M{"resident_card.status": status}
+	if registryResult := c.Query("registry_result"); registryResult != "" {
+		filter["resident_card.registry_result"] = registryResult
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "resident_card.submitted_at", Value: 1}}).
+		SetSkip(pageSkip(page, limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{
+			"first_name":         1,
+			"last_name":          1,
+			"email":              1,
+			"phone":              1,
+			"verification_level": 1,
+			"resident_card":      1,
+		})
+
+	cursor, err := h.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching resident cards",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding resident cards",
+		})
+		return
+	}
+
+	// Адміністратору потрібне посилання на запис реєстру, приховане в JSON користувача
+	items := make([]gin.H, 0, len(users))
+	for _, user := range users {
+		items = append(items, gin.H{
+			"user_id":            user.ID,
+			"name":               user.GetFullName(),
+			"email":              user.Email,
+			"phone":              user.Phone,
+			"verification_level": user.VerificationLevel,
+			"resident_card":      user.ResidentCard,
+			"registry_reference": user.ResidentCard.RegistryReference,
+		})
+	}
+
+	total, _ := h.userCollection.CountDocuments(ctx, filter)
+	c.JSON(http.StatusOK, listResponse(c, items, newPageInfo(page, limit, total), nil, nil))
+}
+
+// VerifyResidentCard - POST /admin/resident-cards/:id/verify
+// Підтверджує картку, що очікує перевірки; :id - ID користувача
+func (h *ResidentCardHandler) VerifyResidentCard(c *gin.Context) {
+	// Тіло запиту необов'язкове (строк дії за замовчуванням)
+	var req VerifyResidentCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	now := time.Now()
+	expiresAt := now.Add(h.validity)
+	if req.ExpiresAt != nil {
+		if req.ExpiresAt.Before(now) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Expiration date must be in the future",
+			})
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	adminID, _ := getUserID(c)
+	h.decide(c, models.ResidentCardPending, bson.M{
+		"$set": bson.M{
+			"resident_card.status":      models.ResidentCardVerified,
+			"resident_card.verified_at": now,
+			"resident_card.verified_by": adminID,
+			"resident_card.expires_at":  expiresAt,
+			"verification_level":        models.VerificationLevelResident,
+			"updated_at":                now,
+		},
+		"$unset": bson.M{"resident_card.rejection_reason": ""},
+	}, "Картку жителя підтверджено", "Тепер вам доступні голосування за громадський бюджет і пільговий проїзд")
+}
+
+// RejectResidentCard - POST /admin/resident-cards/:id/reject
+// Відхиляє заявку; картку можна прив'язати знову
+func (h *ResidentCardHandler) RejectResidentCard(c *gin.Context) {
+	var req RejectResidentCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.decide(c, models.ResidentCardPending, h.withdrawUpdate(models.ResidentCardRejected, req.Reason),
+		"Картку жителя не підтверджено", req.Reason)
+}
+
+// RevokeResidentCard - POST /admin/resident-cards/:id/revoke
+// Відкликає підтверджену картку (наприклад, житель виїхав або картку анульовано)
+func (h *ResidentCardHandler) RevokeResidentCard(c *gin.Context) {
+	var req RejectResidentCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.decide(c, models.ResidentCardVerified, h.withdrawUpdate(models.ResidentCardRevoked, req.Reason),
+		"Картку жителя відкликано", req.Reason)
+}
+
+// withdrawUpdate - відхилення чи відкликання: рівень resident знімається, а номер
+// звільняється, щоб картку міг прив'язати її справжній власник
+func (h *ResidentCardHandler) withdrawUpdate(status, reason string) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"resident_card.status":           status,
+			"resident_card.rejection_reason": reason,
+			"verification_level":             models.VerificationLevelBasic,
+			"updated_at":                     time.Now(),
+		},
+		"$unset": bson.M{"resident_card.number_hash": ""},
+	}
+}
+
+// decide застосовує рішення адміністратора до картки в очікуваному статусі та сповіщає користувача
+func (h *ResidentCardHandler) decide(c *gin.Context, fromStatus string, update bson.M, title, body string) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.notificationService.WithTransaction(ctx, func(ctx context.Context) error {
+		err := h.userCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": userID, "resident_card.status": fromStatus},
+			update,
+			options.FindOneAndUpdate().
+				SetReturnDocument(options.After).
+				SetProjection(bson.M{"verification_level": 1, "resident_card": 1}),
+		).Decode(&user)
+		if err != nil {
+			return err
+		}
+		return h.notificationService.Enqueue(ctx, services.NotificationIntent{
+			Source:  "resident_card.decided",
+			UserIDs: []primitive.ObjectID{userID},
+			Title:   title,
+			Body:    body,
+			Type:    services.NotificationTypeSystem,
+			Data: map[string]interface{}{
+				"resident_card": user.ResidentCard.Status,
+			},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Resident card not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating resident card",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Resident card updated",
+		"resident_card":      user.ResidentCard,
+		"verification_level": user.VerificationLevel,
+	})
+}
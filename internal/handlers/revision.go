@@ -0,0 +1,102 @@
+// internal/handlers/revision.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionView - версія тексту разом зі змінами, внесеними наступною версією
+type RevisionView struct {
+	models.ContentRevision
+	Diff []models.FieldDiff `json:"diff"`
+}
+
+// revisionEdit - правка опублікованого контенту, яку треба зберегти в історії
+type revisionEdit struct {
+	entityType string
+	entityID   primitive.ObjectID
+	version    int               // Номер поточної версії (RevisionCount+1)
+	current    map[string]string // Текстові поля до правки
+	updated    map[string]string // Текстові поля після правки
+	editorID   primitive.ObjectID
+}
+
+// recordRevision зберігає поточний текст перед правкою та додає в update позначку "відредаговано".
+// Повертає false, якщо відповідь з помилкою вже надіслана.
+func recordRevision(ctx context.Context, c *gin.Context, revisionService *services.RevisionService, edit revisionEdit, update bson.M) bool {
+	changed := services.ChangedFields(edit.current, edit.updated)
+	if len(changed) == 0 {
+		return true
+	}
+
+	now := time.Now()
+	err := revisionService.Record(ctx, models.ContentRevision{
+		EntityType: edit.entityType,
+		EntityID:   edit.entityID,
+		Version:    edit.version,
+		Fields:     edit.current,
+		EditedBy:   edit.editorID,
+		ReplacedAt: now,
+		Changed:    changed,
+	})
+	if err == services.ErrRevisionConflict {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Content was edited concurrently, reload and try again",
+		})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error saving revision",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	update["revision_count"] = edit.version
+	update["edited_at"] = now
+	return true
+}
+
+// respondRevisions віддає історію версій з порівнянням кожної версії з наступною
+func respondRevisions(ctx context.Context, c *gin.Context, revisionService *services.RevisionService, entityType string, entityID primitive.ObjectID, current map[string]string, editedAt *time.Time) {
+	revisions, err := revisionService.List(ctx, entityType, entityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching revisions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	views := make([]RevisionView, 0, len(revisions))
+	for i, revision := range revisions {
+		next := current
+		if i+1 < len(revisions) {
+			next = revisions[i+1].Fields
+		}
+		views = append(views, RevisionView{
+			ContentRevision: revision,
+			Diff:            services.DiffFields(revision.Fields, next),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entity_type":     entityType,
+		"entity_id":       entityID,
+		"current_version": len(revisions) + 1,
+		"current":         current,
+		"edited_at":       editedAt,
+		"revisions":       views,
+	})
+}
@@ -0,0 +1,53 @@
+// internal/handlers/sandbox.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SandboxHandler - пісочниця для сторонніх розробників (лише при SANDBOX_MODE)
+type SandboxHandler struct {
+	sandboxService *services.SandboxService
+}
+
+func NewSandboxHandler(sandboxService *services.SandboxService) *SandboxHandler {
+	return &SandboxHandler{
+		sandboxService: sandboxService,
+	}
+}
+
+// Reset - POST /sandbox/reset
+// Видаляє всі дані пісочниці та заповнює її демо-даними; у відповіді - демо-акаунти.
+// Токени попередніх користувачів стають недійсними разом із сесіями.
+func (h *SandboxHandler) Reset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	summary, err := h.sandboxService.Reset(ctx)
+	if errors.Is(err, services.ErrSandboxResetTooSoon) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "Sandbox was reset less than a minute ago",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error resetting sandbox",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sandbox reset",
+		"sandbox": summary,
+	})
+}
@@ -0,0 +1,21 @@
+// internal/handlers/slug.go
+
+package handlers
+
+import (
+	"nova-kakhovka-ecity/internal/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// idOrSlugFilter - фільтр документа за параметром маршруту: ObjectID або slug (/petitions/remont-mostu-3f9a1c)
+func idOrSlugFilter(param string) (bson.M, bool) {
+	if id, err := primitive.ObjectIDFromHex(param); err == nil {
+		return bson.M{"_id": id}, true
+	}
+	if services.IsSlug(param) {
+		return bson.M{"slug": param}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,418 @@
+// internal/handlers/social_import.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Запит до платформи може тривати довше за звичайний запит до бази
+const socialFeedRequestTimeout = 30 * time.Second
+
+// Категорії подій (у подій немає таксономії)
+var eventCategories = []string{
+	models.EventCategoryCultural,
+	models.EventCategoryEducational,
+	models.EventCategorySocial,
+	models.EventCategoryBusiness,
+	models.EventCategorySports,
+	models.EventCategoryCharity,
+	models.EventCategoryMeeting,
+	models.EventCategoryWorkshop,
+	models.EventCategoryConference,
+}
+
+// SocialImportHandler - канали VK/Facebook/Telegram, з яких модератори імпортують
+// пости як чернетки оголошень і подій
+type SocialImportHandler struct {
+	feedCollection   *mongo.Collection
+	importCollection *mongo.Collection
+	importService    *services.SocialImportService
+	taxonomyService  *services.TaxonomyService
+}
+
+// NewSocialImportHandler створює обробник імпорту з соцмереж
+func NewSocialImportHandler(feedCollection, importCollection *mongo.Collection, importService *services.SocialImportService, taxonomyService *services.TaxonomyService) *SocialImportHandler {
+	return &SocialImportHandler{
+		feedCollection:   feedCollection,
+		importCollection: importCollection,
+		importService:    importService,
+		taxonomyService:  taxonomyService,
+	}
+}
+
+type ConnectSocialFeedRequest struct {
+	Platform     string `json:"platform" binding:"required,oneof=vk facebook telegram"`
+	Channel      string `json:"channel" binding:"required,max=200"` // Посилання, @ім'я або ID каналу
+	Target       string `json:"target" binding:"required,oneof=announcement event"`
+	Category     string `json:"category" binding:"required"` // Категорія чернеток
+	Neighborhood string `json:"neighborhood,omitempty"`
+}
+
+type UpdateSocialFeedRequest struct {
+	Category     string  `json:"category,omitempty"`
+	Neighborhood *string `json:"neighborhood,omitempty"` // Порожній рядок прибирає мікрорайон
+	IsActive     *bool   `json:"is_active,omitempty"`
+}
+
+// canModerateTarget - чи може модератор розглядати чернетки цього типу
+func canModerateTarget(c *gin.Context, target string) bool {
+	permission := models.PermissionModerateAnnouncement
+	if target == models.SocialImportTargetEvent {
+		permission = models.PermissionModerateEvent
+	}
+	if middleware.UserHasPermission(c, permission) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":               "Insufficient permissions",
+		"required_permission": permission,
+	})
+	return false
+}
+
+// validateFeedCategory перевіряє категорію чернеток: оголошення - за таксономією, події - за списком
+func (h *SocialImportHandler) validateFeedCategory(c *gin.Context, target, category string) bool {
+	if target == models.SocialImportTargetAnnouncement {
+		return validateCategory(c, h.taxonomyService, models.ModuleAnnouncements, category)
+	}
+	for _, code := range eventCategories {
+		if code == category {
+			return true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid category",
+		"details": "Event category must be one of the event categories",
+		"allowed": eventCategories,
+	})
+	return false
+}
+
+// loadFeed знаходить канал громади, доступний поточному модератору
+func (h *SocialImportHandler) loadFeed(ctx context.Context, c *gin.Context) (*models.SocialFeed, bool) {
+	feedID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid feed ID",
+		})
+		return nil, false
+	}
+
+	var feed models.SocialFeed
+	err = h.feedCollection.FindOne(ctx, communityScope(c, bson.M{"_id": feedID})).Decode(&feed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Feed not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching feed",
+		})
+		return nil, false
+	}
+
+	if !canModerateTarget(c, feed.Target) || !inModerationScope(c, feed.Neighborhood, feed.Category) {
+		return nil, false
+	}
+	return &feed, true
+}
+
+// GetFeeds - GET /moderation/import/feeds
+// Канали громади в зоні модератора та доступні платформи
+func (h *SocialImportHandler) GetFeeds(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.feedCollection.Find(ctx,
+		communityScope(c, withModerationScope(c, bson.M{})),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching feeds",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	feeds := []models.SocialFeed{}
+	if err := cursor.All(ctx, &feeds); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding feeds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feeds":                  feeds,
+		"platforms":              h.importService.Platforms(),
+		"fetch_interval_minutes": int(h.importService.FetchEvery().Minutes()),
+	})
+}
+
+// ConnectFeed - POST /moderation/import/feeds
+// Канал перевіряється запитом до платформи; імпортуються пости, опубліковані після підключення.
+// Автором чернеток стає модератор, який підключив канал.
+func (h *SocialImportHandler) ConnectFeed(c *gin.Context) {
+	var req ConnectSocialFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	provider, ok := h.importService.Provider(req.Platform)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Platform is not configured",
+			"platforms": h.importService.Platforms(),
+		})
+		return
+	}
+	channel, err := provider.NormalizeChannel(req.Channel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid channel",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !canModerateTarget(c, req.Target) || !h.validateFeedCategory(c, req.Target, req.Category) {
+		return
+	}
+	neighborhood, ok := normalizeNeighborhood(c, req.Neighborhood)
+	if !ok {
+		return
+	}
+	if !inModerationScope(c, neighborhood, req.Category) {
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), socialFeedRequestTimeout)
+	defer cancel()
+
+	page, err := provider.Fetch(ctx, channel)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Channel feed is not available",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	feed := &models.SocialFeed{
+		CommunityID:  getCommunityID(c),
+		Platform:     req.Platform,
+		Channel:      channel,
+		Title:        page.Title,
+		URL:          provider.ChannelURL(channel),
+		Target:       req.Target,
+		Category:     req.Category,
+		Neighborhood: neighborhood,
+		ConnectedBy:  userID,
+	}
+	if err := h.importService.Connect(ctx, feed); err != nil {
+		if errors.Is(err, services.ErrSocialFeedExists) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Channel is already connected",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error connecting feed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// UpdateFeed - PUT /moderation/import/feeds/:id
+// Категорія та мікрорайон застосовуються до нових чернеток; is_active=false зупиняє імпорт
+func (h *SocialImportHandler) UpdateFeed(c *gin.Context) {
+	var req UpdateSocialFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	feed, ok := h.loadFeed(ctx, c)
+	if !ok {
+		return
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	category, neighborhood := feed.Category, feed.Neighborhood
+	if req.Category != "" {
+		if !h.validateFeedCategory(c, feed.Target, req.Category) {
+			return
+		}
+		category = req.Category
+		update["category"] = category
+	}
+	if req.Neighborhood != nil {
+		if neighborhood, ok = normalizeNeighborhood(c, *req.Neighborhood); !ok {
+			return
+		}
+		update["neighborhood"] = neighborhood
+	}
+	// Модератор з зоною не може вивести канал за її межі
+	if !inModerationScope(c, neighborhood, category) {
+		return
+	}
+	if req.IsActive != nil {
+		update["is_active"] = *req.IsActive
+		if *req.IsActive && !feed.IsActive {
+			// Пости, опубліковані поки канал був вимкнений, не імпортуються
+			update["last_post_at"] = time.Now()
+			update["next_fetch_at"] = time.Now()
+		}
+	}
+
+	var updated models.SocialFeed
+	err := h.feedCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": feed.ID},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Feed not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating feed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteFeed - DELETE /moderation/import/feeds/:id
+// Імпортовані чернетки та журнал дедуплікації залишаються
+func (h *SocialImportHandler) DeleteFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	feed, ok := h.loadFeed(ctx, c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.feedCollection.DeleteOne(ctx, bson.M{"_id": feed.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error deleting feed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Feed disconnected successfully",
+	})
+}
+
+// RunFeed - POST /moderation/import/feeds/:id/run
+// Імпорт нових постів зараз, не чекаючи планового опитування
+func (h *SocialImportHandler) RunFeed(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), socialFeedRequestTimeout)
+	defer cancel()
+
+	feed, ok := h.loadFeed(ctx, c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.importService.ImportFeed(ctx, feed)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Error importing feed",
+			"details": err.Error(),
+			"stats":   stats,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Feed imported successfully",
+		"stats":   stats,
+	})
+}
+
+// GetFeedImports - GET /moderation/import/feeds/:id/imports
+// Журнал оброблених постів каналу: створені чернетки, дублікати, пропущені пости
+func (h *SocialImportHandler) GetFeedImports(c *gin.Context) {
+	page, limit := pageParams(c, 20, 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	feed, ok := h.loadFeed(ctx, c)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"feed_id": feed.ID}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := h.importCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching imports",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	imports := []models.SocialImport{}
+	if err := cursor.All(ctx, &imports); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding imports",
+		})
+		return
+	}
+
+	total, _ := h.importCollection.CountDocuments(ctx, filter)
+	info := newPageInfo(page, limit, total)
+
+	c.JSON(http.StatusOK, listResponse(c, imports, info, nil, gin.H{"imports": imports, "pagination": info}))
+}
@@ -0,0 +1,242 @@
+// internal/handlers/sso.go
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Cookie з nonce входу: прив'язує повернення від провайдера до браузера, який почав вхід
+const ssoNonceCookie = "sso_nonce"
+
+const ssoCookiePath = "/api/v1/auth/sso"
+
+var errSSOAccountLinked = errors.New("account is linked to another SSO identity")
+
+// GetSSOProviders - GET /auth/sso/providers
+// Провайдери SSO для кнопок входу в адмін-панелі
+func (h *AuthHandler) GetSSOProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"providers": h.sso.Providers(),
+	})
+}
+
+// StartSSO - GET /auth/sso/:provider/start?redirect_uri=https://admin.ecity.gov.ua/auth/sso
+// Перенаправляє працівника на сторінку входу Azure AD або Google Workspace
+func (h *AuthHandler) StartSSO(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	authURL, nonce, err := h.sso.Begin(ctx, c.Param("provider"), c.Query("redirect_uri"))
+	switch {
+	case errors.Is(err, services.ErrSSOUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "SSO provider is not configured",
+		})
+		return
+	case errors.Is(err, services.ErrSSORedirect):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Redirect URL is not allowed",
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "SSO provider is unavailable",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoNonceCookie, nonce, int(services.SSOStateTTL.Seconds()), ssoCookiePath, "", true, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// SSOCallback - GET /auth/sso/:provider/callback?code=...&state=...
+// Створює або оновлює акаунт працівника (роль за правилами зіставлення) і видає токени.
+// Якщо вхід почато з redirect_uri, токени передаються у фрагменті адреси адмін-панелі.
+func (h *AuthHandler) SSOCallback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "SSO sign-in was cancelled or failed",
+			"details": providerError + ": " + c.Query("error_description"),
+		})
+		return
+	}
+
+	nonce, _ := c.Cookie(ssoNonceCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoNonceCookie, "", -1, ssoCookiePath, "", true, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	identity, redirect, err := h.sso.Complete(ctx, c.Param("provider"), c.Query("code"), c.Query("state"), nonce)
+	switch {
+	case errors.Is(err, services.ErrSSOUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "SSO provider is not configured",
+		})
+		return
+	case errors.Is(err, services.ErrSSOInvalidState):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "SSO sign-in has expired, please start again",
+		})
+		return
+	case errors.Is(err, services.ErrSSOEmail), errors.Is(err, services.ErrSSODomain), errors.Is(err, services.ErrSSONoRole):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "This account is not allowed to sign in with SSO",
+			"details": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "SSO sign-in failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.provisionSSOUser(ctx, identity)
+	if errors.Is(err, errSSOAccountLinked) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Account with this email is linked to another SSO identity",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error provisioning account",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if user.IsBlockedAt(time.Now()) {
+		c.JSON(http.StatusForbidden, newBlockedUserResponse(user,
+			"Ваш акаунт заблоковано. Будь ласка, зверніться до адміністратора."))
+		return
+	}
+
+	response, ok := h.issueTokens(ctx, c, user)
+	if !ok {
+		return
+	}
+
+	if redirect == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	// Фрагмент не надсилається серверам, тому токени не потрапляють у журнали запитів
+	fragment := url.Values{}
+	fragment.Set("token", response.Token)
+	fragment.Set("refresh_token", response.RefreshToken)
+	fragment.Set("expires_in", strconv.FormatInt(response.ExpiresIn, 10))
+	c.Redirect(http.StatusFound, redirect+"#"+fragment.Encode())
+}
+
+// provisionSSOUser знаходить акаунт працівника за ідентифікатором SSO або email і синхронізує роль.
+// Новий працівник створюється одразу (just-in-time). Прив'язаний акаунт входить лише через SSO:
+// пароль видаляється, а сесії, відкриті паролем, завершуються.
+func (h *AuthHandler) provisionSSOUser(ctx context.Context, identity *services.SSOIdentity) (*models.User, error) {
+	now := time.Now()
+
+	var user models.User
+	err := h.userCollection.FindOne(ctx, bson.M{
+		"sso_provider": identity.Provider,
+		"sso_subject":  identity.Subject,
+	}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		err = h.userCollection.FindOne(ctx, bson.M{"email": identity.Email}).Decode(&user)
+	}
+
+	if err == mongo.ErrNoDocuments {
+		user = models.User{
+			Email:           identity.Email,
+			FirstName:       identity.FirstName,
+			LastName:        identity.LastName,
+			Role:            string(identity.Role),
+			IsVerified:      true,
+			SSOProvider:     identity.Provider,
+			SSOSubject:      identity.Subject,
+			Groups:          []primitive.ObjectID{},
+			Interests:       []string{},
+			CommunityIDs:    []primitive.ObjectID{},
+			Status:          models.UserStatus{UpdatedAt: now},
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			LastLoginAt:     &now,
+			EmailVerifiedAt: &now,
+		}
+		result, err := h.userCollection.InsertOne(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		user.ID = result.InsertedID.(primitive.ObjectID)
+		return &user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.SSOSubject != "" && (user.SSOProvider != identity.Provider || user.SSOSubject != identity.Subject) {
+		return nil, errSSOAccountLinked
+	}
+	newlyLinked := user.SSOSubject == ""
+
+	set := bson.M{
+		"sso_provider":  identity.Provider,
+		"sso_subject":   identity.Subject,
+		"password_hash": "",
+		"is_verified":   true,
+		"last_login_at": now,
+		"updated_at":    now,
+	}
+	if user.EmailVerifiedAt == nil {
+		set["email_verified_at"] = now
+	}
+	update := bson.M{"$set": set}
+
+	// Роль визначає каталог організації; SUPER_ADMIN через SSO не змінюється
+	roleChanged := user.GetRole() != identity.Role && user.GetRole() != models.RoleSuperAdmin
+	if roleChanged {
+		set["role"] = string(identity.Role)
+		update["$inc"] = bson.M{"token_version": 1}
+		update["$push"] = roleHistoryPush(models.RoleChange{
+			FromRole:  string(user.GetRole()),
+			ToRole:    string(identity.Role),
+			Source:    models.RoleChangeSourceSSO,
+			Reason:    "Role mapped from " + identity.Provider + " directory",
+			ChangedAt: now,
+		})
+	}
+
+	err = h.userCollection.FindOneAndUpdate(ctx, bson.M{"_id": user.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	if newlyLinked || roleChanged {
+		if err := h.revokeAllSessions(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		h.userStatus.Invalidate(user.ID)
+	}
+	return &user, nil
+}
@@ -0,0 +1,211 @@
+// internal/handlers/stats_history.go
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Максимальний діапазон історії статистики в одному запиті (5 років)
+const maxStatsHistoryRangeDays = 5*366 + 1
+
+// Знімок минулого року шукаємо не раніше ніж за тиждень до тієї ж дати
+const statsYearOverYearToleranceDays = 7
+
+// StatsHistoryHandler - щоденні знімки показників платформи (stats_history)
+type StatsHistoryHandler struct {
+	historyCollection *mongo.Collection
+}
+
+// NewStatsHistoryHandler створює обробник історії статистики
+func NewStatsHistoryHandler(historyCollection *mongo.Collection) *StatsHistoryHandler {
+	return &StatsHistoryHandler{
+		historyCollection: historyCollection,
+	}
+}
+
+// StatsHistoryPoint - значення показників на кінець періоду (останній знімок періоду)
+type StatsHistoryPoint struct {
+	Period string `json:"period"` // YYYY-MM-DD, YYYY-Www або YYYY-MM
+	models.StatsSnapshot
+}
+
+// StatsChange - зміна показника відносно того ж дня минулого року
+type StatsChange struct {
+	Current       int64    `json:"current"`
+	Previous      int64    `json:"previous"`
+	Change        int64    `json:"change"`
+	ChangePercent *float64 `json:"change_percent"` // Немає, якщо минулого року було 0
+}
+
+// GetStatsHistory - GET /public/stats/history?from=YYYY-MM-DD&to=YYYY-MM-DD&interval=day|week|month
+// За замовчуванням - останній рік по місяцях. year_over_year порівнює останній знімок
+// діапазону зі знімком того ж дня минулого року.
+func (h *StatsHistoryHandler) GetStatsHistory(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "month")
+	if interval != "day" && interval != "week" && interval != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Interval must be day, week or month",
+		})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(-1, 0, 0)
+
+	var ok bool
+	if from, ok = parseCalendarDate(c, "from", from); !ok {
+		return
+	}
+	if to, ok = parseCalendarDate(c, "to", to); !ok {
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Parameter 'to' must not be before 'from'",
+		})
+		return
+	}
+	if to.Sub(from) > maxStatsHistoryRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Stats history range is limited to 5 years",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Дата у форматі YYYY-MM-DD порівнюється як рядок у хронологічному порядку
+	cursor, err := h.historyCollection.Find(ctx,
+		bson.M{"date": bson.M{
+			"$gte": from.Format(models.CalendarDateLayout),
+			"$lte": to.Format(models.CalendarDateLayout),
+		}},
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}}),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching stats history",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []models.StatsSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding stats history",
+		})
+		return
+	}
+
+	// Кожен період представляє його останній знімок
+	points := []StatsHistoryPoint{}
+	for _, snapshot := range snapshots {
+		period := statsPeriod(snapshot.Date, interval)
+		if n := len(points); n > 0 && points[n-1].Period == period {
+			points[n-1].StatsSnapshot = snapshot
+			continue
+		}
+		points = append(points, StatsHistoryPoint{Period: period, StatsSnapshot: snapshot})
+	}
+
+	response := gin.H{
+		"interval": interval,
+		"from":     from.Format(models.CalendarDateLayout),
+		"to":       to.Format(models.CalendarDateLayout),
+		"items":    points,
+	}
+
+	if len(snapshots) > 0 {
+		latest := snapshots[len(snapshots)-1]
+		previous, err := h.yearAgo(ctx, latest.Date)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error fetching stats history",
+			})
+			return
+		}
+		if previous != nil {
+			changes := make(map[string]StatsChange, len(models.StatsMetrics))
+			for _, metric := range models.StatsMetrics {
+				changes[metric] = statsChange(latest.Metric(metric), previous.Metric(metric))
+			}
+			response["year_over_year"] = gin.H{
+				"date":        latest.Date,
+				"compared_to": previous.Date,
+				"metrics":     changes,
+			}
+		}
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, response)
+}
+
+// yearAgo - знімок на ту ж дату минулого року або найближчий попередній у межах тижня
+func (h *StatsHistoryHandler) yearAgo(ctx context.Context, date string) (*models.StatsSnapshot, error) {
+	day, err := time.ParseInLocation(models.CalendarDateLayout, date, time.Local)
+	if err != nil {
+		return nil, nil
+	}
+	target := day.AddDate(-1, 0, 0)
+
+	var snapshot models.StatsSnapshot
+	err = h.historyCollection.FindOne(ctx,
+		bson.M{"date": bson.M{
+			"$lte": target.Format(models.CalendarDateLayout),
+			"$gte": target.AddDate(0, 0, -statsYearOverYearToleranceDays).Format(models.CalendarDateLayout),
+		}},
+		options.FindOne().SetSort(bson.D{{Key: "date", Value: -1}}),
+	).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// statsPeriod - ключ періоду, до якого належить день знімка
+func statsPeriod(date, interval string) string {
+	day, err := time.ParseInLocation(models.CalendarDateLayout, date, time.Local)
+	if err != nil {
+		return date
+	}
+	switch interval {
+	case "week":
+		year, week := day.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return day.Format("2006-01")
+	}
+	return date
+}
+
+func statsChange(current, previous int64) StatsChange {
+	change := StatsChange{
+		Current:  current,
+		Previous: previous,
+		Change:   current - previous,
+	}
+	if previous != 0 {
+		percent := math.Round(float64(current-previous)/float64(previous)*1000) / 10
+		change.ChangePercent = &percent
+	}
+	return change
+}
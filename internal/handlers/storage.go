@@ -0,0 +1,52 @@
+// internal/handlers/storage.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/database"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorageHandler - використання сховища для планування ресурсів і стійкість запитів до бази
+type StorageHandler struct {
+	storageService *services.StorageService
+	retrier        *database.Retrier
+}
+
+func NewStorageHandler(storageService *services.StorageService, retrier *database.Retrier) *StorageHandler {
+	return &StorageHandler{
+		storageService: storageService,
+		retrier:        retrier,
+	}
+}
+
+// GetStorageUsage - GET /admin/storage
+// Кількість і розмір документів, індекси та медіафайли по колекціях і модулях,
+// приріст за останні 30 днів і попередження про наближення до ліміту тарифу Atlas
+func (h *StorageHandler) GetStorageUsage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report, err := h.storageService.Report(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error collecting storage usage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDatabaseRetries - GET /admin/database/retries
+// Повтори при тимчасових помилках MongoDB і стан автомата відключення (closed, open, half_open)
+func (h *StorageHandler) GetDatabaseRetries(c *gin.Context) {
+	c.JSON(http.StatusOK, h.retrier.Stats())
+}
@@ -0,0 +1,207 @@
+// internal/handlers/tag.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TagHandler - теги міста: автодоповнення, популярні теги, злиття
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+// NewTagHandler створює обробник тегів
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+type MergeTagsRequest struct {
+	Sources []string `json:"sources" binding:"required,min=1,max=50"`
+	Target  string   `json:"target" binding:"required,max=100"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// parseTagModule перевіряє необов'язковий параметр module
+func parseTagModule(c *gin.Context) (string, bool) {
+	module := c.Query("module")
+	if module != "" && !models.IsTagModule(module) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Unknown module",
+			"modules": models.TagModules,
+		})
+		return "", false
+	}
+	return module, true
+}
+
+// Autocomplete - GET /tags/autocomplete?q=парк&module=polls&limit=10
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	module, ok := parseTagModule(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tags, err := h.tagService.Autocomplete(ctx, c.Query("q"), module, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching tags",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+// GetTrending - GET /tags/trending?module=petitions&days=7&limit=10
+// Без module повертає популярні теги окремо для кожного модуля.
+func (h *TagHandler) GetTrending(c *gin.Context) {
+	module, ok := parseTagModule(c)
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days < 1 || days > 90 {
+		days = 7
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	modules := models.TagModules
+	if module != "" {
+		modules = []string{module}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	since := time.Now().AddDate(0, 0, -days)
+	trending := gin.H{}
+	for _, m := range modules {
+		tags, err := h.tagService.Trending(ctx, m, since, limit, communityScope(c, bson.M{}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error calculating trending tags",
+			})
+			return
+		}
+		trending[m] = tags
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trending": trending,
+		"days":     days,
+	})
+}
+
+// MergeTags зливає кілька тегів в один у всьому контенті (MODERATOR)
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var req MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.merge(c, req.Sources, req.Target)
+}
+
+// RenameTag перейменовує тег; якщо нова назва вже існує, теги зливаються (MODERATOR)
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.merge(c, []string{c.Param("name")}, req.Name)
+}
+
+func (h *TagHandler) merge(c *gin.Context, sources []string, target string) {
+	canonicalTarget := models.CanonicalTag(target)
+	if canonicalTarget == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Target tag is empty",
+		})
+		return
+	}
+	hasSource := false
+	for _, source := range models.NormalizeTags(sources) {
+		if source != canonicalTarget {
+			hasSource = true
+			break
+		}
+	}
+	if !hasSource {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Source tags must differ from target",
+		})
+		return
+	}
+
+	// Злиття переписує контент усіх модулів, тому тайм-аут більший
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tag, modified, err := h.tagService.Merge(ctx, sources, target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error merging tags",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Tags merged",
+		"tag":               tag,
+		"updated_documents": modified,
+	})
+}
+
+// canonicalizeTags нормалізує теги запиту та відповідає 400 у разі помилки
+func canonicalizeTags(ctx context.Context, c *gin.Context, tagService *services.TagService, tags []string) ([]string, bool) {
+	if tagService == nil {
+		return models.NormalizeTags(tags), true
+	}
+
+	canonical, err := tagService.Canonicalize(ctx, tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tags",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	return canonical, true
+}
@@ -0,0 +1,293 @@
+// internal/handlers/taxonomy.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Код категорії зберігається в документах, тому обмежуємо його латиницею
+var taxonomyCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// TaxonomyHandler - довідник категорій модулів
+type TaxonomyHandler struct {
+	taxonomyCollection *mongo.Collection
+	taxonomyService    *services.TaxonomyService
+}
+
+// NewTaxonomyHandler створює обробник таксономій
+func NewTaxonomyHandler(taxonomyCollection *mongo.Collection, taxonomyService *services.TaxonomyService) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		taxonomyCollection: taxonomyCollection,
+		taxonomyService:    taxonomyService,
+	}
+}
+
+type CreateTaxonomyRequest struct {
+	Module    string              `json:"module" binding:"required,oneof=city_issues announcements polls petitions interests"`
+	Code      string              `json:"code" binding:"required,min=2,max=50"`
+	Names     map[string]string   `json:"names" binding:"required"`
+	Icon      string              `json:"icon" binding:"max=50"`
+	SortOrder int                 `json:"sort_order"`
+	Matches   map[string][]string `json:"matches,omitempty"` // Лише для interests
+}
+
+// UpdateTaxonomyRequest - модуль і код змінити не можна, бо на них посилаються документи
+type UpdateTaxonomyRequest struct {
+	Names     map[string]string `json:"names,omitempty"`
+	Icon      *string           `json:"icon,omitempty" binding:"omitempty,max=50"`
+	SortOrder *int              `json:"sort_order,omitempty"`
+	IsActive  *bool             `json:"is_active,omitempty"`
+	// Лише для interests: категорії контенту модулів, що відповідають інтересу
+	Matches map[string][]string `json:"matches,omitempty"`
+}
+
+// taxonomyView - категорія з назвою потрібною мовою
+func taxonomyView(taxonomy models.Taxonomy, lang string) gin.H {
+	view := gin.H{
+		"id":         taxonomy.ID,
+		"module":     taxonomy.Module,
+		"code":       taxonomy.Code,
+		"name":       taxonomy.Name(lang),
+		"names":      taxonomy.Names,
+		"icon":       taxonomy.Icon,
+		"sort_order": taxonomy.SortOrder,
+		"is_active":  taxonomy.IsActive,
+	}
+	if len(taxonomy.Matches) > 0 {
+		view["matches"] = taxonomy.Matches
+	}
+	return view
+}
+
+// GetTaxonomies - GET /taxonomies?module=city_issues&lang=en
+// Повертає активні категорії; без module - згруповані за всіма модулями.
+func (h *TaxonomyHandler) GetTaxonomies(c *gin.Context) {
+	h.list(c, false)
+}
+
+// GetAllTaxonomies повертає категорії разом із неактивними (ADMIN)
+func (h *TaxonomyHandler) GetAllTaxonomies(c *gin.Context) {
+	h.list(c, true)
+}
+
+func (h *TaxonomyHandler) list(c *gin.Context, includeInactive bool) {
+	lang := c.DefaultQuery("lang", "uk")
+
+	modules := models.TaxonomyModules
+	if module := c.Query("module"); module != "" {
+		if !models.IsTaxonomyModule(module) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown module",
+			})
+			return
+		}
+		modules = []string{module}
+	}
+
+	result := gin.H{}
+	for _, module := range modules {
+		items := []gin.H{}
+		for _, taxonomy := range h.taxonomyService.List(module, includeInactive) {
+			items = append(items, taxonomyView(taxonomy, lang))
+		}
+		result[module] = items
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"taxonomies": result,
+	})
+}
+
+// CreateTaxonomy додає категорію до модуля
+func (h *TaxonomyHandler) CreateTaxonomy(c *gin.Context) {
+	var req CreateTaxonomyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !taxonomyCodePattern.MatchString(req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Code must contain only lowercase latin letters, digits and underscores",
+		})
+		return
+	}
+	if req.Names["uk"] == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ukrainian name (names.uk) is required",
+		})
+		return
+	}
+	if req.Matches != nil {
+		if req.Module != models.TaxonomyInterests {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Matches are supported only for interests",
+			})
+			return
+		}
+		if !validateInterestMatches(c, req.Matches) {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	taxonomy := models.Taxonomy{
+		Module:    req.Module,
+		Code:      req.Code,
+		Names:     req.Names,
+		Icon:      req.Icon,
+		SortOrder: req.SortOrder,
+		Matches:   req.Matches,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := h.taxonomyCollection.InsertOne(ctx, taxonomy)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Category with this code already exists in module",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error creating category",
+		})
+		return
+	}
+	taxonomy.ID = result.InsertedID.(primitive.ObjectID)
+
+	h.taxonomyService.Reload(ctx)
+
+	c.JSON(http.StatusCreated, taxonomy)
+}
+
+// UpdateTaxonomy змінює назви, іконку, порядок або активність категорії.
+// Деактивована категорія лишається в наявному контенті, але недоступна для нового.
+func (h *TaxonomyHandler) UpdateTaxonomy(c *gin.Context) {
+	taxonomyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid taxonomy ID",
+		})
+		return
+	}
+
+	var req UpdateTaxonomyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if req.Names != nil {
+		if req.Names["uk"] == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Ukrainian name (names.uk) is required",
+			})
+			return
+		}
+		update["names"] = req.Names
+	}
+	if req.Icon != nil {
+		update["icon"] = *req.Icon
+	}
+	if req.SortOrder != nil {
+		update["sort_order"] = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		update["is_active"] = *req.IsActive
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if req.Matches != nil {
+		var current models.Taxonomy
+		if err := h.taxonomyCollection.FindOne(ctx, bson.M{"_id": taxonomyID}).Decode(&current); err == nil && current.Module != models.TaxonomyInterests {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Matches are supported only for interests",
+			})
+			return
+		}
+		if !validateInterestMatches(c, req.Matches) {
+			return
+		}
+		update["matches"] = req.Matches
+	}
+
+	var taxonomy models.Taxonomy
+	err = h.taxonomyCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": taxonomyID},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&taxonomy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Category not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error updating category",
+		})
+		return
+	}
+
+	h.taxonomyService.Reload(ctx)
+
+	c.JSON(http.StatusOK, taxonomy)
+}
+
+// validateInterestMatches перевіряє, що інтерес посилається лише на модулі з рекомендаціями
+func validateInterestMatches(c *gin.Context, matches map[string][]string) bool {
+	for module := range matches {
+		if !slices.Contains(models.InterestContentModules, module) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Matches support only modules: " + strings.Join(models.InterestContentModules, ", "),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// validateCategory перевіряє категорію за таксономією модуля та відповідає 400 у разі помилки
+func validateCategory(c *gin.Context, taxonomyService *services.TaxonomyService, module, category string) bool {
+	if taxonomyService == nil {
+		return true
+	}
+	if err := taxonomyService.Validate(module, category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid category",
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
+}
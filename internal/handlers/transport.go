@@ -3,14 +3,17 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -23,6 +26,9 @@ type TransportHandler struct {
 	routeCollection   *mongo.Collection
 	vehicleCollection *mongo.Collection
 	userCollection    *mongo.Collection
+	calendarService   *services.CalendarService
+	timetableService  *services.TimetableService
+	alertCollection   *mongo.Collection
 }
 
 type CreateRouteRequest struct {
@@ -36,6 +42,7 @@ type CreateRouteRequest struct {
 	Schedule    []models.TransportSchedule `json:"schedule"`
 	IsActive    bool                       `json:"is_active"`
 	Fare        float64                    `json:"fare"`
+	FareRules   []models.FareRule          `json:"fare_rules"`
 }
 
 type CreateVehicleRequest struct {
@@ -66,11 +73,82 @@ type RouteFilters struct {
 	Search       string `form:"search"`
 }
 
-func NewTransportHandler(routeCollection, vehicleCollection, userCollection *mongo.Collection) *TransportHandler {
+// Широта міста для розрахунку допуску за zoom, якщо маршрут без точок
+const defaultMapLatitude = 46.76
+
+// routeGeometryOptions - параметри спрощення геометрії маршрутів для карти
+type routeGeometryOptions struct {
+	zoom      int
+	hasZoom   bool
+	tolerance float64
+	encode    bool
+}
+
+// parseRouteGeometryOptions розбирає ?zoom=, ?tolerance= (метри) та ?geometry=polyline|coordinates.
+// tolerance має пріоритет над zoom; без параметрів геометрія повертається повністю.
+func parseRouteGeometryOptions(c *gin.Context) (routeGeometryOptions, bool) {
+	var opts routeGeometryOptions
+
+	if value := c.Query("zoom"); value != "" {
+		zoom, err := strconv.Atoi(value)
+		if err != nil || zoom < models.MinMapZoom || zoom > models.MaxMapZoom {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("zoom must be an integer between %d and %d", models.MinMapZoom, models.MaxMapZoom),
+			})
+			return opts, false
+		}
+		opts.zoom, opts.hasZoom = zoom, true
+	}
+
+	if value := c.Query("tolerance"); value != "" {
+		tolerance, err := strconv.ParseFloat(value, 64)
+		if err != nil || tolerance < 0 || tolerance > models.MaxSimplifyTolerance {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("tolerance must be between 0 and %.0f meters", models.MaxSimplifyTolerance),
+			})
+			return opts, false
+		}
+		opts.tolerance = tolerance
+	}
+
+	switch c.DefaultQuery("geometry", "coordinates") {
+	case "coordinates":
+	case "polyline":
+		opts.encode = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "geometry must be 'coordinates' or 'polyline'",
+		})
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// applyRouteGeometry спрощує та за потреби кодує геометрію маршруту
+func applyRouteGeometry(route *models.TransportRoute, opts routeGeometryOptions) {
+	tolerance := opts.tolerance
+	if tolerance == 0 && opts.hasZoom {
+		latitude := defaultMapLatitude
+		if len(route.RoutePoints) > 0 && len(route.RoutePoints[0].Coordinates) == 2 {
+			latitude = route.RoutePoints[0].Coordinates[1]
+		}
+		tolerance = models.ToleranceForZoom(opts.zoom, latitude)
+	}
+	if tolerance == 0 && !opts.encode {
+		return
+	}
+	route.SimplifyGeometry(tolerance, opts.encode)
+}
+
+func NewTransportHandler(routeCollection, vehicleCollection, userCollection *mongo.Collection, calendarService *services.CalendarService, timetableService *services.TimetableService, alertCollection *mongo.Collection) *TransportHandler {
 	return &TransportHandler{
 		routeCollection:   routeCollection,
 		vehicleCollection: vehicleCollection,
 		userCollection:    userCollection,
+		calendarService:   calendarService,
+		timetableService:  timetableService,
+		alertCollection:   alertCollection,
 	}
 }
 
@@ -109,17 +187,21 @@ func (h *TransportHandler) CreateRoute(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
+	if err := models.ValidateFareRules(req.FareRules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid fare rules",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	userIDObj := userID.(primitive.ObjectID)
+	userIDObj, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -155,7 +237,9 @@ func (h *TransportHandler) CreateRoute(c *gin.Context) {
 		Schedule:      req.Schedule,
 		IsActive:      req.IsActive,
 		Fare:          req.Fare,
+		FareRules:     req.FareRules,
 		CreatedBy:     userIDObj,
+		CommunityID:   getCommunityID(c),
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -198,11 +282,16 @@ func (h *TransportHandler) GetRoutes(c *gin.Context) {
 		filters.Limit = 20
 	}
 
+	geometry, ok := parseRouteGeometryOptions(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Построение фильтра запроса
-	query := bson.M{}
+	query := communityScope(c, bson.M{})
 
 	if filters.Type != "" {
 		query["type"] = filters.Type
@@ -266,18 +355,15 @@ func (h *TransportHandler) GetRoutes(c *gin.Context) {
 		return
 	}
 
+	for i := range routes {
+		applyRouteGeometry(&routes[i], geometry)
+	}
+
 	// Подсчет общего количества
 	total, _ := h.routeCollection.CountDocuments(ctx, query)
 
-	c.JSON(http.StatusOK, gin.H{
-		"routes": routes,
-		"pagination": gin.H{
-			"page":        filters.Page,
-			"limit":       filters.Limit,
-			"total":       total,
-			"total_pages": (total + int64(filters.Limit) - 1) / int64(filters.Limit),
-		},
-	})
+	info := newPageInfo(filters.Page, filters.Limit, total)
+	c.JSON(http.StatusOK, listResponse(c, routes, info, nil, gin.H{"routes": routes, "pagination": info}))
 }
 
 // GetRoute возвращает детальную информацию о маршруте
@@ -290,11 +376,16 @@ func (h *TransportHandler) GetRoute(c *gin.Context) {
 		return
 	}
 
+	geometry, ok := parseRouteGeometryOptions(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	var route models.TransportRoute
-	err = h.routeCollection.FindOne(ctx, bson.M{"_id": routeID}).Decode(&route)
+	err = h.routeCollection.FindOne(ctx, communityScope(c, bson.M{"_id": routeID})).Decode(&route)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -307,6 +398,7 @@ func (h *TransportHandler) GetRoute(c *gin.Context) {
 		})
 		return
 	}
+	applyRouteGeometry(&route, geometry)
 
 	// Получаем активные транспортные средства на маршруте
 	cursor, err := h.vehicleCollection.Find(ctx, bson.M{
@@ -318,10 +410,12 @@ func (h *TransportHandler) GetRoute(c *gin.Context) {
 		cursor.All(ctx, &vehicles)
 		cursor.Close(ctx)
 
-		// Добавляем информацию о транспортных средствах к ответу
+		// Добавляем информацию о транспортных средствах и объявлениях об изменении движения
 		c.JSON(http.StatusOK, gin.H{
 			"route":    route,
 			"vehicles": vehicles,
+			"fare":     route.CalculateFare(h.passengerConcession(ctx, c)),
+			"alerts":   h.routeAlerts(ctx, routeID),
 		})
 		return
 	}
@@ -329,6 +423,41 @@ func (h *TransportHandler) GetRoute(c *gin.Context) {
 	c.JSON(http.StatusOK, route)
 }
 
+// routeAlerts - опубліковані оголошення про зміну руху маршруту
+func (h *TransportHandler) routeAlerts(ctx context.Context, routeID primitive.ObjectID) []models.TransportAlert {
+	alerts := []models.TransportAlert{}
+	cursor, err := h.alertCollection.Find(ctx, bson.M{
+		"route_ids": routeID,
+		"status":    models.TransportAlertPublished,
+	}, options.Find().SetSort(bson.D{{Key: "published_at", Value: -1}}))
+	if err != nil {
+		return alerts
+	}
+	defer cursor.Close(ctx)
+	cursor.All(ctx, &alerts)
+	return alerts
+}
+
+// passengerConcession повертає підтверджену пільгу авторизованого пасажира (порожній рядок - повний тариф).
+// Пільговий тариф діє лише для підтверджених жителів громади.
+func (h *TransportHandler) passengerConcession(ctx context.Context, c *gin.Context) string {
+	userID, err := getUserID(c)
+	if err != nil {
+		return ""
+	}
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx,
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"fare_concession": 1, "verification_level": 1, "resident_card": 1}),
+	).Decode(&user)
+	now := time.Now()
+	if err != nil || !user.FareConcession.IsActive(now) || !user.IsVerifiedResident(now) {
+		return ""
+	}
+	return user.FareConcession.Category
+}
+
 // UpdateRoute обновляет информацию о маршруте
 func (h *TransportHandler) UpdateRoute(c *gin.Context) {
 	routeID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -339,15 +468,6 @@ func (h *TransportHandler) UpdateRoute(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	var updateReq map[string]interface{}
 	if err := c.ShouldBindJSON(&updateReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -367,6 +487,27 @@ func (h *TransportHandler) UpdateRoute(c *gin.Context) {
 
 	updateReq["updated_at"] = time.Now()
 
+	// Пільгові тарифи перевіряємо і зберігаємо в типізованому вигляді
+	if rawRules, ok := updateReq["fare_rules"]; ok {
+		var fareRules []models.FareRule
+		data, _ := json.Marshal(rawRules)
+		if err := json.Unmarshal(data, &fareRules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid fare rules",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err := models.ValidateFareRules(fareRules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid fare rules",
+				"details": err.Error(),
+			})
+			return
+		}
+		updateReq["fare_rules"] = fareRules
+	}
+
 	// Если обновляются точки маршрута, пересчитываем расстояние
 	if routePoints, ok := updateReq["route_points"].([]interface{}); ok && len(routePoints) > 1 {
 		totalDistance := 0.0
@@ -379,7 +520,7 @@ func (h *TransportHandler) UpdateRoute(c *gin.Context) {
 
 	result, err := h.routeCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": routeID},
+		communityScope(c, bson.M{"_id": routeID}),
 		bson.M{"$set": updateReq},
 	)
 
@@ -412,15 +553,6 @@ func (h *TransportHandler) DeleteRoute(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -443,7 +575,7 @@ func (h *TransportHandler) DeleteRoute(c *gin.Context) {
 		return
 	}
 
-	result, err := h.routeCollection.DeleteOne(ctx, bson.M{"_id": routeID})
+	result, err := h.routeCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": routeID}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error deleting route",
@@ -474,15 +606,6 @@ func (h *TransportHandler) CreateVehicle(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	routeID, err := primitive.ObjectIDFromHex(req.RouteID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -495,7 +618,7 @@ func (h *TransportHandler) CreateVehicle(c *gin.Context) {
 	defer cancel()
 
 	// Проверяем существование маршрута
-	count, err := h.routeCollection.CountDocuments(ctx, bson.M{"_id": routeID})
+	count, err := h.routeCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": routeID}))
 	if err != nil || count == 0 {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Route not found",
@@ -523,6 +646,7 @@ func (h *TransportHandler) CreateVehicle(c *gin.Context) {
 
 	now := time.Now()
 	vehicle := models.TransportVehicle{
+		CommunityID:       getCommunityID(c),
 		RouteID:           routeID,
 		VehicleNumber:     req.VehicleNumber,
 		TransportType:     req.Type,
@@ -562,7 +686,7 @@ func (h *TransportHandler) GetVehicles(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	query := bson.M{}
+	query := communityScope(c, bson.M{})
 
 	if routeIDStr != "" {
 		if routeID, err := primitive.ObjectIDFromHex(routeIDStr); err == nil {
@@ -613,15 +737,6 @@ func (h *TransportHandler) UpdateVehicle(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	var updateReq map[string]interface{}
 	if err := c.ShouldBindJSON(&updateReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -642,7 +757,7 @@ func (h *TransportHandler) UpdateVehicle(c *gin.Context) {
 
 	result, err := h.vehicleCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": vehicleID},
+		communityScope(c, bson.M{"_id": vehicleID}),
 		bson.M{"$set": updateReq},
 	)
 
@@ -675,19 +790,10 @@ func (h *TransportHandler) DeleteVehicle(c *gin.Context) {
 		return
 	}
 
-	// Проверяем права модератора
-	isModerator, _ := c.Get("is_moderator")
-	if !isModerator.(bool) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Moderator access required",
-		})
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := h.vehicleCollection.DeleteOne(ctx, bson.M{"_id": vehicleID})
+	result, err := h.vehicleCollection.DeleteOne(ctx, communityScope(c, bson.M{"_id": vehicleID}))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Error deleting vehicle",
@@ -742,9 +848,13 @@ func (h *TransportHandler) UpdateVehicleLocation(c *gin.Context) {
 		},
 	}
 
+	// Транспорт, що відстежується зовнішнім провайдером, не приймає позиції із застосунку водія
 	result, err := h.vehicleCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": vehicleID},
+		communityScope(c, bson.M{
+			"_id":             vehicleID,
+			"tracking.source": bson.M{"$ne": models.TrackingSourceProvider},
+		}),
 		update,
 	)
 
@@ -757,7 +867,7 @@ func (h *TransportHandler) UpdateVehicleLocation(c *gin.Context) {
 
 	if result.MatchedCount == 0 {
 		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Vehicle not found",
+			"error": "Vehicle not found or tracked by external GPS provider",
 		})
 		return
 	}
@@ -775,14 +885,14 @@ func (h *TransportHandler) GetLiveVehicles(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	query := bson.M{
+	query := communityScope(c, bson.M{
 		"is_active": true,
 		"is_online": true,
 		// Только транспорт, который обновлялся в последние 5 минут
 		"last_update": bson.M{
 			"$gte": time.Now().Add(-5 * time.Minute),
 		},
-	}
+	})
 
 	if routeIDStr != "" {
 		if routeID, err := primitive.ObjectIDFromHex(routeIDStr); err == nil {
@@ -838,7 +948,9 @@ func (h *TransportHandler) GetLiveVehicles(c *gin.Context) {
 	})
 }
 
-// GetRouteSchedule возвращает расписание маршрута
+// GetRouteSchedule возвращает расписание маршрута.
+// Без day_type тип дня берется из календаря на дату ?date= (по умолчанию сегодня):
+// в праздники - праздничное расписание, при его отсутствии - воскресное.
 func (h *TransportHandler) GetRouteSchedule(c *gin.Context) {
 	routeID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
@@ -848,14 +960,49 @@ func (h *TransportHandler) GetRouteSchedule(c *gin.Context) {
 		return
 	}
 
-	stopName := c.Query("stop")                      // Опционально: расписание для конкретной остановки
-	dayType := c.DefaultQuery("day_type", "weekday") // weekday, saturday, sunday
+	stopName := c.Query("stop")    // Опционально: расписание для конкретной остановки
+	dayType := c.Query("day_type") // weekday, saturday, sunday, holiday
+	if dayType != "" && !models.IsValidScheduleDay(dayType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid day_type",
+		})
+		return
+	}
+
+	date := time.Now()
+	if dateParam := c.Query("date"); dateParam != "" {
+		date, err = time.ParseInLocation(models.CalendarDateLayout, dateParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid date, expected YYYY-MM-DD",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	var calendarDay *models.CalendarDayInfo
+	if dayType == "" {
+		dayType = models.ScheduleDayForWeekday(date.Weekday())
+		if h.calendarService != nil {
+			info, err := h.calendarService.Day(ctx, date, communityScope(c, bson.M{}))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Error fetching calendar",
+					"details": err.Error(),
+				})
+				return
+			}
+			dayType = info.ScheduleDay
+			calendarDay = &info
+		}
+	}
+
 	var route models.TransportRoute
-	err = h.routeCollection.FindOne(ctx, bson.M{"_id": routeID}).Decode(&route)
+	err = h.routeCollection.FindOne(ctx, communityScope(c, bson.M{"_id": routeID})).Decode(&route)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -870,24 +1017,122 @@ func (h *TransportHandler) GetRouteSchedule(c *gin.Context) {
 	}
 
 	// Фильтруем расписание по типу дня и остановке
-	var filteredSchedule []models.TransportSchedule
-	for _, schedule := range route.Schedule {
-		if schedule.DayType == dayType {
-			if stopName == "" || schedule.StopName == stopName {
-				filteredSchedule = append(filteredSchedule, schedule)
+	filterSchedule := func(dayType string) []models.TransportSchedule {
+		var filtered []models.TransportSchedule
+		for _, schedule := range route.Schedule {
+			if schedule.DayType == dayType {
+				if stopName == "" || schedule.StopName == stopName {
+					filtered = append(filtered, schedule)
+				}
 			}
 		}
+		return filtered
+	}
+
+	filteredSchedule := filterSchedule(dayType)
+	if len(filteredSchedule) == 0 && dayType == models.ScheduleDayHoliday {
+		// Праздничного расписания нет - транспорт ходит по воскресному
+		dayType = models.ScheduleDaySunday
+		filteredSchedule = filterSchedule(dayType)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"route_number": route.RouteNumber,
 		"route_name":   route.RouteName,
 		"day_type":     dayType,
+		"date":         date.Format(models.CalendarDateLayout),
+		"calendar":     calendarDay,
 		"stop":         stopName,
 		"schedule":     filteredSchedule,
 	})
 }
 
+// GetRouteTimetablePDF - GET /transport/routes/:id/timetable.pdf
+// Печатное расписание для размещения на остановках: по странице на остановку,
+// колонки по типам дней. ?stop= - только одна остановка, ?day_type= - только один тип дня.
+func (h *TransportHandler) GetRouteTimetablePDF(c *gin.Context) {
+	routeID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid route ID",
+		})
+		return
+	}
+
+	dayType := c.Query("day_type")
+	if dayType != "" && !models.IsValidScheduleDay(dayType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid day_type",
+		})
+		return
+	}
+
+	var stopID primitive.ObjectID
+	if stopParam := c.Query("stop"); stopParam != "" {
+		stopID, err = primitive.ObjectIDFromHex(stopParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid stop ID",
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var route models.TransportRoute
+	err = h.routeCollection.FindOne(ctx, communityScope(c, bson.M{"_id": routeID})).Decode(&route)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Route not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching route",
+		})
+		return
+	}
+
+	timetables := services.BuildTimetable(&route)
+	if !stopID.IsZero() {
+		var selected []models.StopTimetable
+		for _, timetable := range timetables {
+			if timetable.StopID == stopID {
+				selected = append(selected, timetable)
+			}
+		}
+		if len(selected) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Stop not found on route",
+			})
+			return
+		}
+		timetables = selected
+	}
+
+	document, err := h.timetableService.RenderPDF(&route, timetables, dayType, time.Now())
+	if err == services.ErrTimetableEmpty {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Route has no timetable",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error generating timetable",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("route-%s-timetable.pdf", url.PathEscape(route.RouteNumber))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
 // GetNearestStops возвращает ближайшие остановки
 func (h *TransportHandler) GetNearestStops(c *gin.Context) {
 	lat := c.Query("lat")
@@ -1128,10 +1373,10 @@ func (h *TransportHandler) GetArrivals(c *gin.Context) {
 	defer cancel()
 
 	// Знаходимо маршрути, що проходять через цю зупинку
-	query := bson.M{
+	query := communityScope(c, bson.M{
 		"stops.name": stopName,
 		"is_active":  true,
-	}
+	})
 
 	if routeNumber != "" {
 		query["number"] = routeNumber
@@ -1159,6 +1404,7 @@ func (h *TransportHandler) GetArrivals(c *gin.Context) {
 	// Формуємо список прибуттів
 	var arrivals []gin.H
 	now := time.Now()
+	concession := h.passengerConcession(ctx, c)
 
 	for _, route := range routes {
 		// Знаходимо зупинку в маршруті
@@ -1205,6 +1451,7 @@ func (h *TransportHandler) GetArrivals(c *gin.Context) {
 				"estimated_time": nextArrivalTime,
 				"minutes_away":   int(nextArrivalTime.Sub(now).Minutes()),
 				"stop_name":      stopName,
+				"fare":           route.CalculateFare(concession),
 			})
 		}
 	}
@@ -1234,13 +1481,13 @@ func (h *TransportHandler) GetLiveTracking(c *gin.Context) {
 	defer cancel()
 
 	// Базовий запит: тільки активний та онлайн транспорт
-	query := bson.M{
+	query := communityScope(c, bson.M{
 		"is_active": true,
 		// Транспорт, який оновлювався протягом останніх 5 хвилин
 		"last_update": bson.M{
 			"$gte": time.Now().Add(-5 * time.Minute),
 		},
-	}
+	})
 
 	// Фільтр за маршрутом
 	if routeIDStr != "" {
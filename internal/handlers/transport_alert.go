@@ -0,0 +1,294 @@
+// internal/handlers/transport_alert.go
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TransportAlertHandler - оголошення про об'їзди та зміну руху маршрутів
+type TransportAlertHandler struct {
+	alertCollection *mongo.Collection
+	routeCollection *mongo.Collection
+}
+
+type UpdateTransportAlertRequest struct {
+	Title    string   `json:"title" binding:"omitempty,min=5,max=200"`
+	Message  string   `json:"message" binding:"omitempty,min=10,max=2000"`
+	RouteIDs []string `json:"route_ids"`
+}
+
+func NewTransportAlertHandler(alertCollection, routeCollection *mongo.Collection) *TransportAlertHandler {
+	return &TransportAlertHandler{
+		alertCollection: alertCollection,
+		routeCollection: routeCollection,
+	}
+}
+
+// GetAlerts - GET /transport/alerts
+// Опубліковані оголошення громади (?route_id= - лише для маршруту)
+func (h *TransportAlertHandler) GetAlerts(c *gin.Context) {
+	filter := communityScope(c, bson.M{"status": models.TransportAlertPublished})
+	if routeParam := c.Query("route_id"); routeParam != "" {
+		routeID, err := primitive.ObjectIDFromHex(routeParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid route ID",
+			})
+			return
+		}
+		filter["route_ids"] = routeID
+	}
+
+	h.listAlerts(c, filter)
+}
+
+// GetManagedAlerts - GET /transport/alerts/manage
+// Усі оголошення для адміністраторів транспорту (?status=draft - чернетки на перевірку)
+func (h *TransportAlertHandler) GetManagedAlerts(c *gin.Context) {
+	filter := communityScope(c, bson.M{})
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	h.listAlerts(c, filter)
+}
+
+func (h *TransportAlertHandler) listAlerts(c *gin.Context, filter bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.alertCollection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(100))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching alerts",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	alerts := []models.TransportAlert{}
+	if err := cursor.All(ctx, &alerts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error decoding alerts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  alerts,
+		"total": len(alerts),
+	})
+}
+
+// GetAlert - GET /transport/alerts/:id
+// Опубліковане або завершене оголошення разом з посиланням на проблему міста
+func (h *TransportAlertHandler) GetAlert(c *gin.Context) {
+	alertID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid alert ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var alert models.TransportAlert
+	err = h.alertCollection.FindOne(ctx, communityScope(c, bson.M{
+		"_id":    alertID,
+		"status": bson.M{"$in": []string{models.TransportAlertPublished, models.TransportAlertEnded}},
+	})).Decode(&alert)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Alert not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching alert",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// UpdateAlert - PUT /transport/alerts/:id
+// Редагування чернетки або опублікованого оголошення (текст, перелік маршрутів)
+func (h *TransportAlertHandler) UpdateAlert(c *gin.Context) {
+	alertID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid alert ID",
+		})
+		return
+	}
+
+	var req UpdateTransportAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if req.Title != "" {
+		update["title"] = req.Title
+	}
+	if req.Message != "" {
+		update["message"] = req.Message
+	}
+	if req.RouteIDs != nil {
+		routeIDs := make([]primitive.ObjectID, 0, len(req.RouteIDs))
+		for _, id := range req.RouteIDs {
+			routeID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid route ID",
+				})
+				return
+			}
+			routeIDs = append(routeIDs, routeID)
+		}
+		if len(routeIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Alert must reference at least one route",
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		count, err := h.routeCollection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": routeIDs}})
+		cancel()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Error checking routes",
+			})
+			return
+		}
+		if int(count) != len(routeIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Route not found",
+			})
+			return
+		}
+		update["route_ids"] = routeIDs
+	}
+
+	h.transition(c, alertID, []string{models.TransportAlertDraft, models.TransportAlertPublished}, update)
+}
+
+// PublishAlert - POST /transport/alerts/:id/publish
+func (h *TransportAlertHandler) PublishAlert(c *gin.Context) {
+	alertID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid alert ID",
+		})
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	now := time.Now()
+	h.transition(c, alertID, []string{models.TransportAlertDraft}, bson.M{
+		"status":       models.TransportAlertPublished,
+		"published_by": userID,
+		"published_at": now,
+		"updated_at":   now,
+	})
+}
+
+// DismissAlert - POST /transport/alerts/:id/dismiss
+// Чернетка відхилена: зміна руху не потрібна
+func (h *TransportAlertHandler) DismissAlert(c *gin.Context) {
+	alertID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid alert ID",
+		})
+		return
+	}
+
+	h.transition(c, alertID, []string{models.TransportAlertDraft}, bson.M{
+		"status":     models.TransportAlertDismissed,
+		"updated_at": time.Now(),
+	})
+}
+
+// EndAlert - POST /transport/alerts/:id/end
+// Рух відновлено: оголошення більше не показується пасажирам
+func (h *TransportAlertHandler) EndAlert(c *gin.Context) {
+	alertID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid alert ID",
+		})
+		return
+	}
+
+	now := time.Now()
+	h.transition(c, alertID, []string{models.TransportAlertPublished}, bson.M{
+		"status":     models.TransportAlertEnded,
+		"ended_at":   now,
+		"updated_at": now,
+	})
+}
+
+// transition змінює оголошення, якщо воно в одному з дозволених статусів
+func (h *TransportAlertHandler) transition(c *gin.Context, alertID primitive.ObjectID, from []string, update bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var alert models.TransportAlert
+	err := h.alertCollection.FindOneAndUpdate(ctx,
+		communityScope(c, bson.M{"_id": alertID, "status": bson.M{"$in": from}}),
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&alert)
+	if err == mongo.ErrNoDocuments {
+		count, countErr := h.alertCollection.CountDocuments(ctx, communityScope(c, bson.M{"_id": alertID}))
+		if countErr == nil && count > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Alert status does not allow this action",
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Alert not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error updating alert",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
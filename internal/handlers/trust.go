@@ -0,0 +1,228 @@
+// internal/handlers/trust.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrustHandler - рівні довіри акаунтів та утримані повідомлення
+// 🔒 Всі методи вимагають ролі MODERATOR
+type TrustHandler struct {
+	messageCollection *mongo.Collection
+	trustService      *services.TrustService
+	wsHandler         *WebSocketHandler
+	moderationLog     *services.ModerationLogService
+	attachments       *services.ChatAttachmentService
+}
+
+// NewTrustHandler створює обробник для модерації за рівнем довіри
+func NewTrustHandler(messageCollection *mongo.Collection, trustService *services.TrustService, wsHandler *WebSocketHandler, moderationLog *services.ModerationLogService, attachments *services.ChatAttachmentService) *TrustHandler {
+	return &TrustHandler{
+		messageCollection: messageCollection,
+		trustService:      trustService,
+		wsHandler:         wsHandler,
+		moderationLog:     moderationLog,
+		attachments:       attachments,
+	}
+}
+
+func (h *TrustHandler) recordMessageDecision(ctx context.Context, c *gin.Context, message *models.Message, decision string) {
+	moderatorID, _ := getUserID(c)
+	h.moderationLog.Record(ctx, models.ModerationAction{
+		CommunityID: getCommunityID(c),
+		ModeratorID: moderatorID,
+		ContentType: models.ModerationContentMessage,
+		ContentID:   message.ID,
+		AuthorID:    message.UserID,
+		Decision:    decision,
+		SubmittedAt: message.CreatedAt,
+	})
+}
+
+// chatSlowMode - інтервал між повідомленнями з урахуванням slow mode групи та рівня довіри автора
+func chatSlowMode(group *models.Group, userID primitive.ObjectID, quota models.TrustQuota) time.Duration {
+	slowMode := group.SlowModeFor(userID)
+	if group.IsOwner(userID) || group.IsAdmin(userID) || group.IsModerator(userID) {
+		return slowMode
+	}
+
+	if minInterval := time.Duration(quota.ChatMinIntervalSeconds) * time.Second; minInterval > slowMode {
+		return minInterval
+	}
+	return slowMode
+}
+
+// GetUserTrust повертає рівень довіри користувача та чинники оцінки
+func (h *TrustHandler) GetUserTrust(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assessment, err := h.trustService.Evaluate(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error evaluating trust",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, assessment)
+}
+
+// GetHeldMessages повертає повідомлення з посиланнями або забороненими словами, утримані до перевірки
+func (h *TrustHandler) GetHeldMessages(c *gin.Context) {
+	page, limit := pageParams(c, 20, 100)
+
+	filter := bson.M{
+		"is_held":    true,
+		"is_deleted": false,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := h.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error fetching held messages",
+		})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error decoding messages",
+		})
+		return
+	}
+	h.attachments.SignMessages(messages)
+
+	// Для утриманих фільтром повідомлень модератор бачить знайдені заборонені слова
+	items := make([]models.MessageUnderReview, len(messages))
+	for i := range messages {
+		items[i] = models.MessageUnderReview{Message: messages[i], Review: messages[i].Review}
+	}
+
+	total, _ := h.messageCollection.CountDocuments(ctx, filter)
+
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, items, info, nil, gin.H{"messages": items, "pagination": info}))
+}
+
+// ApproveHeldMessage публікує утримане повідомлення в групі
+func (h *TrustHandler) ApproveHeldMessage(c *gin.Context) {
+	messageID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var message models.Message
+	err = h.messageCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": messageID, "is_held": true},
+		bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"is_held": "", "review": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Held message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error approving message",
+		})
+		return
+	}
+
+	h.recordMessageDecision(ctx, c, &message, models.ModerationDecisionApproved)
+	h.attachments.Sign(message.Attachments)
+	h.wsHandler.BroadcastMessage(&message)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message approved",
+	})
+}
+
+// RejectHeldMessage видаляє утримане повідомлення та знижує довіру до автора
+func (h *TrustHandler) RejectHeldMessage(c *gin.Context) {
+	messageID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid message ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var message models.Message
+	err = h.messageCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": messageID, "is_held": true, "is_deleted": false},
+		bson.M{"$set": bson.M{
+			"is_deleted": true,
+			"updated_at": time.Now(),
+		}},
+	).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Held message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error rejecting message",
+		})
+		return
+	}
+
+	h.trustService.RecordRemoval(ctx, message.UserID)
+	h.recordMessageDecision(ctx, c, &message, models.ModerationDecisionRejected)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Message rejected",
+	})
+}
@@ -4,11 +4,16 @@ package handlers
 
 import (
 	"context"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"nova-kakhovka-ecity/internal/middleware"
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,10 +27,23 @@ import (
 // 🔒 Всі методи вимагають автентифікації та відповідних прав доступу
 type UsersHandler struct {
 	userCollection *mongo.Collection
+	userStatus     *services.UserStatusCache // Кеш блокувань для AuthMiddleware
+	refreshTokens  *services.RefreshTokenService
+	sessions       *services.SessionService
+	identityKey    string // Ключ HMAC РНОКПП (services.IdentityHash)
+
+	sessionsRevokedListeners []func(userID primitive.ObjectID)
 }
 
 // Request/Response структури
 
+// VerifyUserRequest - необов'язкове підтвердження особи під час верифікації.
+// РНОКПП не зберігається: лишається HMAC, за яким опитування рахують один голос на особу.
+type VerifyUserRequest struct {
+	Provider  string `json:"provider" binding:"omitempty,oneof=diia bankid"`
+	TaxNumber string `json:"tax_number"`
+}
+
 // UpdatePasswordRequest - запит на зміну пароля
 type UpdatePasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8,max=100"`
@@ -37,6 +55,12 @@ type BlockUserRequest struct {
 	Reason    string `json:"reason,omitempty"`
 }
 
+// SuspendUserRequest - тимчасове блокування з автоматичним зняттям
+type SuspendUserRequest struct {
+	DurationHours int    `json:"duration_hours" binding:"required,min=1,max=8760"` // До року
+	Reason        string `json:"reason,omitempty" binding:"max=500"`
+}
+
 // BlockUserResponse - відповідь на блокування користувача
 type BlockUserResponse struct {
 	Message   string `json:"message"`
@@ -44,16 +68,6 @@ type BlockUserResponse struct {
 	IsBlocked bool   `json:"is_blocked"`
 }
 
-// UsersListResponse - відповідь зі списком користувачів
-type UsersListResponse struct {
-	Data       []models.User `json:"data"`  // ✅ Основні дані в полі data
-	Users      []models.User `json:"users"` // Legacy підтримка
-	Total      int64         `json:"total"`
-	Page       int           `json:"page"`
-	Limit      int           `json:"limit"`
-	TotalPages int           `json:"total_pages"`
-}
-
 // UserStatsData - вкладений об'єкт зі статистикою
 type UserStatsData struct {
 	Total         int64 `json:"total"`
@@ -70,9 +84,36 @@ type UserStatsResponse struct {
 }
 
 // NewUsersHandler створює новий обробник користувачів
-func NewUsersHandler(userCollection *mongo.Collection) *UsersHandler {
+func NewUsersHandler(userCollection *mongo.Collection, userStatus *services.UserStatusCache, refreshTokens *services.RefreshTokenService, sessions *services.SessionService, identityKey string) *UsersHandler {
 	return &UsersHandler{
 		userCollection: userCollection,
+		userStatus:     userStatus,
+		refreshTokens:  refreshTokens,
+		sessions:       sessions,
+		identityKey:    identityKey,
+	}
+}
+
+// OnSessionsRevoked реєструє обробник примусового виходу користувача (закриття WebSocket тощо).
+// Реєстрація виконується при старті, до початку обробки запитів.
+func (h *UsersHandler) OnSessionsRevoked(listener func(userID primitive.ObjectID)) {
+	h.sessionsRevokedListeners = append(h.sessionsRevokedListeners, listener)
+}
+
+// revokeSessions завершує всі сесії користувача після блокування, зміни ролі чи видалення.
+// token_version вже збільшено в тому ж оновленні; скидання кешу робить це помітним AuthMiddleware одразу.
+func (h *UsersHandler) revokeSessions(ctx context.Context, userID primitive.ObjectID) {
+	h.userStatus.Invalidate(userID)
+
+	if _, err := h.refreshTokens.RevokeAll(ctx, userID); err != nil {
+		log.Printf("Error revoking refresh tokens of user %s: %v", userID.Hex(), err)
+	}
+	if err := h.sessions.RevokeAll(ctx, userID); err != nil {
+		log.Printf("Error revoking sessions of user %s: %v", userID.Hex(), err)
+	}
+
+	for _, listener := range h.sessionsRevokedListeners {
+		listener(userID)
 	}
 }
 
@@ -81,22 +122,11 @@ func NewUsersHandler(userCollection *mongo.Collection) *UsersHandler {
 // Метод: GET /api/v1/users
 func (h *UsersHandler) GetAllUsers(c *gin.Context) {
 	// Отримуємо параметри запиту
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "20")
+	page, limit := pageParams(c, 20, 100)
 	search := c.Query("search")
 	role := c.Query("role")
 	isBlockedStr := c.Query("is_blocked")
 
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
 	// Будуємо фільтр
 	filter := bson.M{}
 
@@ -156,22 +186,15 @@ func (h *UsersHandler) GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	// Обчислюємо загальну кількість сторінок
-	totalPages := int(total) / limit
-	if int(total)%limit != 0 {
-		totalPages++
-	}
-
-	response := UsersListResponse{
-		Data:       users, // ✅ Основні дані
-		Users:      users, // Legacy підтримка
-		Total:      total,
-		Page:       page,
-		Limit:      limit,
-		TotalPages: totalPages,
-	}
-
-	c.JSON(http.StatusOK, response)
+	info := newPageInfo(page, limit, total)
+	c.JSON(http.StatusOK, listResponse(c, users, info, nil, gin.H{
+		"data":        users,
+		"users":       users,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": info.TotalPages,
+	}))
 }
 
 // GetUserByID отримує користувача за ID
@@ -305,8 +328,9 @@ func (h *UsersHandler) BlockUser(c *gin.Context) {
 
 	// Підготовка оновлення
 	update := bson.M{
-		"is_blocked": req.IsBlocked,
-		"updated_at": time.Now(),
+		"is_blocked":    req.IsBlocked,
+		"blocked_until": nil, // Блокування безстрокове, попереднє призупинення скасовується
+		"updated_at":    time.Now(),
 	}
 
 	if req.IsBlocked {
@@ -319,11 +343,17 @@ func (h *UsersHandler) BlockUser(c *gin.Context) {
 		update["blocked_at"] = nil
 	}
 
+	changes := bson.M{"$set": update}
+	if req.IsBlocked {
+		// Збільшення token_version відкликає всі видані користувачу токени
+		changes["$inc"] = bson.M{"token_version": 1}
+	}
+
 	// Оновлюємо користувача
 	result, err := h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": objectID},
-		bson.M{"$set": update},
+		changes,
 	)
 
 	if err != nil {
@@ -340,6 +370,13 @@ func (h *UsersHandler) BlockUser(c *gin.Context) {
 		return
 	}
 
+	// Блокування діє з наступного запиту, а не після закінчення терміну токена
+	if req.IsBlocked {
+		h.revokeSessions(ctx, objectID)
+	} else {
+		h.userStatus.Invalidate(objectID)
+	}
+
 	response := BlockUserResponse{
 		Message:   "User status updated successfully",
 		UserID:    userID,
@@ -349,6 +386,106 @@ func (h *UsersHandler) BlockUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// SuspendUser тимчасово блокує користувача; блокування знімається автоматично
+// 🔒 Вимагає права: Permission.BLOCK_USER (ADMIN+)
+// Метод: PUT /api/v1/users/:id/suspend
+func (h *UsersHandler) SuspendUser(c *gin.Context) {
+	actor, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"role": 1, "is_moderator": 1, "is_blocked": 1, "blocked_until": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if user.ID == actor.UserID || !actor.Role.CanManageUser(user.GetRole()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Insufficient role to suspend this user",
+		})
+		return
+	}
+
+	now := time.Now()
+	// Призупинення не скорочує безстрокове блокування
+	if user.IsBlockedAt(now) && !user.IsSuspended() {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User is blocked permanently, unblock first",
+		})
+		return
+	}
+
+	until := now.Add(time.Duration(req.DurationHours) * time.Hour)
+	_, err = h.userCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$set": bson.M{
+				"is_blocked":    true,
+				"block_reason":  strings.TrimSpace(req.Reason),
+				"blocked_at":    now,
+				"blocked_until": until,
+				"updated_at":    now,
+			},
+			// Збільшення token_version відкликає всі видані користувачу токени
+			"$inc": bson.M{"token_version": 1},
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to suspend user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.revokeSessions(ctx, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "User suspended successfully",
+		"user_id":        userID.Hex(),
+		"blocked_until":  until,
+		"duration_hours": req.DurationHours,
+	})
+}
+
 // GetUser повертає детальну інформацію про користувача
 func (h *UsersHandler) GetUser(c *gin.Context) {
 	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -442,11 +579,23 @@ func (h *UsersHandler) UpdateUser(c *gin.Context) {
 	if req.FullName != "" {
 		update["full_name"] = req.FullName
 	}
-	if req.Phone != "" {
+	unset := bson.M{}
+	if req.Phone != "" && req.Phone != existingUser.Phone {
 		update["phone"] = req.Phone
+		// Новий номер ще не підтверджено
+		unset["phone_verified_at"] = ""
 	}
+	// Дата народження зберігається там само, де її задає користувач (birth_date), - для вікових обмежень опитувань
 	if req.DateOfBirth != "" {
-		update["date_of_birth"] = req.DateOfBirth
+		birthDate, err := models.ParseBirthDate(req.DateOfBirth, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid birth date",
+				"details": err.Error(),
+			})
+			return
+		}
+		update["birth_date"] = birthDate
 	}
 	if req.Gender != "" {
 		update["gender"] = req.Gender
@@ -455,11 +604,16 @@ func (h *UsersHandler) UpdateUser(c *gin.Context) {
 		update["address"] = req.Address
 	}
 
+	updateDoc := bson.M{"$set": update}
+	if len(unset) > 0 {
+		updateDoc["$unset"] = unset
+	}
+
 	// Оновлюємо користувача
 	_, err = h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": userID},
-		bson.M{"$set": update},
+		updateDoc,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -469,6 +623,8 @@ func (h *UsersHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	h.userStatus.Invalidate(userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
 	})
@@ -515,6 +671,7 @@ func (h *UsersHandler) DeleteUser(c *gin.Context) {
 				"deleted_at": time.Now(),
 				"is_blocked": true, // Також блокуємо
 			},
+			"$inc": bson.M{"token_version": 1},
 		},
 	)
 	if err != nil {
@@ -525,6 +682,8 @@ func (h *UsersHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	h.revokeSessions(ctx, userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User deleted successfully",
 	})
@@ -554,10 +713,11 @@ func (h *UsersHandler) UnblockUser(c *gin.Context) {
 		bson.M{"_id": userID},
 		bson.M{
 			"$set": bson.M{
-				"is_blocked":   false,
-				"block_reason": "",
-				"blocked_at":   nil,
-				"updated_at":   time.Now(),
+				"is_blocked":    false,
+				"block_reason":  "",
+				"blocked_at":    nil,
+				"blocked_until": nil,
+				"updated_at":    time.Now(),
 			},
 		},
 	)
@@ -576,6 +736,8 @@ func (h *UsersHandler) UnblockUser(c *gin.Context) {
 		return
 	}
 
+	h.userStatus.Invalidate(userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User unblocked successfully",
 	})
@@ -592,6 +754,33 @@ func (h *UsersHandler) VerifyUser(c *gin.Context) {
 		return
 	}
 
+	var req VerifyUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	set := bson.M{
+		"is_verified": true,
+		"verified_at": time.Now(),
+		"updated_at":  time.Now(),
+	}
+	if req.Provider != "" || req.TaxNumber != "" {
+		taxNumber := models.NormalizeTaxNumber(req.TaxNumber)
+		if req.Provider == "" || taxNumber == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid identity",
+				"details": "Identity confirmation needs provider (diia or bankid) and a 10-digit tax_number",
+			})
+			return
+		}
+		set["identity_provider"] = req.Provider
+		set["identity_hash"] = services.IdentityHash(taxNumber, h.identityKey)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -599,13 +788,7 @@ func (h *UsersHandler) VerifyUser(c *gin.Context) {
 	result, err := h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": userID},
-		bson.M{
-			"$set": bson.M{
-				"is_verified": true,
-				"verified_at": time.Now(),
-				"updated_at":  time.Now(),
-			},
-		},
+		bson.M{"$set": set},
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -639,7 +822,8 @@ func (h *UsersHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	type UpdateRoleRequest struct {
-		Role string `json:"role" binding:"required,oneof=USER MODERATOR ADMIN"`
+		Role   string `json:"role" binding:"required,oneof=USER MODERATOR ADMIN"`
+		Reason string `json:"reason,omitempty" binding:"max=500"` // Потрапляє в історію ролей
 	}
 
 	var req UpdateRoleRequest
@@ -654,15 +838,61 @@ func (h *UsersHandler) UpdateUserRole(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Оновлюємо роль
+	// Поточна роль потрібна для запису в історії
+	var user models.User
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"role": 1, "is_moderator": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	fromRole := string(user.GetRole())
+	if fromRole == req.Role {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "User already has this role",
+			"role":    req.Role,
+		})
+		return
+	}
+
+	now := time.Now()
+	change := models.RoleChange{
+		FromRole:  fromRole,
+		ToRole:    req.Role,
+		Source:    models.RoleChangeSourceAdmin,
+		Reason:    strings.TrimSpace(req.Reason),
+		ChangedAt: now,
+	}
+	if actor, ok := middleware.CurrentUser(c); ok {
+		change.ChangedBy = &actor.UserID
+	}
+
+	// Оновлюємо роль; фільтр за старою роллю, щоб історія не розійшлася з паралельною зміною
+	var currentRole interface{} = user.Role
+	if user.Role == "" {
+		currentRole = bson.M{"$in": []interface{}{"", nil}} // legacy users без поля role
+	}
 	result, err := h.userCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": userID},
+		bson.M{"_id": userID, "role": currentRole},
 		bson.M{
 			"$set": bson.M{
 				"role":       req.Role,
-				"updated_at": time.Now(),
+				"updated_at": now,
 			},
+			"$inc":  bson.M{"token_version": 1},
+			"$push": roleHistoryPush(change),
 		},
 	)
 	if err != nil {
@@ -674,18 +904,125 @@ func (h *UsersHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User role was changed concurrently, reload and try again",
 		})
 		return
 	}
 
+	// Токени зі старою роллю відкликаються, клієнт входить заново
+	h.revokeSessions(ctx, userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User role updated successfully",
 		"role":    req.Role,
 	})
 }
 
+// GetUserRoleHistory повертає історію змін ролі користувача, нові першими
+// Метод: GET /api/v1/admin/users/:id/role-history
+func (h *UsersHandler) GetUserRoleHistory(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = h.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"role": 1, "is_moderator": 1, "role_history": 1}),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching user",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Імена тих, хто змінював роль; видалені акаунти лишаються лише з ID
+	actorIDs := []primitive.ObjectID{}
+	for _, change := range user.RoleHistory {
+		if change.ChangedBy != nil {
+			actorIDs = append(actorIDs, *change.ChangedBy)
+		}
+	}
+	actors := map[primitive.ObjectID]gin.H{}
+	if len(actorIDs) > 0 {
+		cursor, err := h.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": actorIDs}},
+			options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1, "email": 1}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error fetching role history",
+				"details": err.Error(),
+			})
+			return
+		}
+		var actorUsers []models.User
+		if err := cursor.All(ctx, &actorUsers); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error decoding role history",
+				"details": err.Error(),
+			})
+			return
+		}
+		for _, actor := range actorUsers {
+			actors[actor.ID] = gin.H{
+				"id":         actor.ID,
+				"first_name": actor.FirstName,
+				"last_name":  actor.LastName,
+				"email":      actor.Email,
+			}
+		}
+	}
+
+	history := make([]gin.H, 0, len(user.RoleHistory))
+	for i := len(user.RoleHistory) - 1; i >= 0; i-- {
+		change := user.RoleHistory[i]
+		entry := gin.H{
+			"from_role":  change.FromRole,
+			"to_role":    change.ToRole,
+			"source":     change.Source,
+			"reason":     change.Reason,
+			"changed_at": change.ChangedAt,
+		}
+		if change.ChangedBy != nil {
+			entry["changed_by"] = change.ChangedBy
+			if actor, ok := actors[*change.ChangedBy]; ok {
+				entry["changed_by_user"] = actor
+			}
+		}
+		history = append(history, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": userID,
+		"role":    user.GetRole(),
+		"history": history,
+		"limit":   models.MaxRoleHistory,
+	})
+}
+
+// roleHistoryPush - $push запису в історію ролей; зберігаються лише останні MaxRoleHistory
+func roleHistoryPush(change models.RoleChange) bson.M {
+	return bson.M{"role_history": bson.M{
+		"$each":  []models.RoleChange{change},
+		"$slice": -models.MaxRoleHistory,
+	}}
+}
+
 // GetUserStats отримує статистику користувачів
 // 🔒 Вимагає права: Permission.VIEW_ANALYTICS або Permission.USERS_MANAGE
 // Метод: GET /api/v1/users/stats
@@ -820,13 +1157,14 @@ func (h *UsersHandler) BanUser(c *gin.Context) {
 	update := bson.M{
 		"is_blocked":  true,
 		"blocked_at":  time.Now(),
+		"blocked_until": nil,
 		"updated_at":  time.Now(),
 	}
 
 	result, err := h.userCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": objectID},
-		bson.M{"$set": update},
+		bson.M{"$set": update, "$inc": bson.M{"token_version": 1}},
 	)
 
 	if err != nil {
@@ -843,6 +1181,8 @@ func (h *UsersHandler) BanUser(c *gin.Context) {
 		return
 	}
 
+	h.revokeSessions(ctx, objectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User banned successfully",
 		"user_id": userID,
@@ -865,10 +1205,11 @@ func (h *UsersHandler) UnbanUser(c *gin.Context) {
 
 	// Разблокируем пользователя
 	update := bson.M{
-		"is_blocked":   false,
-		"block_reason": "",
-		"blocked_at":   nil,
-		"updated_at":   time.Now(),
+		"is_blocked":    false,
+		"block_reason":  "",
+		"blocked_at":    nil,
+		"blocked_until": nil,
+		"updated_at":    time.Now(),
 	}
 
 	result, err := h.userCollection.UpdateOne(
@@ -891,6 +1232,8 @@ func (h *UsersHandler) UnbanUser(c *gin.Context) {
 		return
 	}
 
+	h.userStatus.Invalidate(objectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User unbanned successfully",
 		"user_id": userID,
@@ -0,0 +1,237 @@
+// internal/handlers/users_bulk.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/middleware"
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Дії масової обробки користувачів
+const (
+	BulkUserActionBlock   = "block"
+	BulkUserActionUnblock = "unblock"
+	BulkUserActionVerify  = "verify"
+	BulkUserActionRole    = "role"
+)
+
+// Результат дії для окремого користувача
+const (
+	BulkUserUpdated   = "updated"
+	BulkUserUnchanged = "unchanged" // Користувач уже в потрібному стані
+	BulkUserFailed    = "failed"
+)
+
+// BulkUsersRequest - запит на масову дію над користувачами
+type BulkUsersRequest struct {
+	Action  string   `json:"action" binding:"required,oneof=block unblock verify role"`
+	UserIDs []string `json:"user_ids" binding:"required,min=1,max=200"`                     // Не більше 200 за запит
+	Reason  string   `json:"reason,omitempty" binding:"max=500"`                            // Причина блокування або зміни ролі
+	Role    string   `json:"role,omitempty" binding:"omitempty,oneof=USER MODERATOR ADMIN"` // Нова роль для action=role
+}
+
+// BulkUserResult - результат для одного користувача
+type BulkUserResult struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkUsers виконує блокування, розблокування, верифікацію або зміну ролі для списку користувачів.
+// Кожен користувач обробляється окремо: помилка одного не скасовує дію для інших.
+// 🔒 Вимагає права: Permission.MANAGE_USERS (ADMIN+)
+// Метод: POST /api/v1/admin/users/bulk
+func (h *UsersHandler) BulkUsers(c *gin.Context) {
+	actor, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized",
+		})
+		return
+	}
+
+	var req BulkUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Action == BulkUserActionRole && req.Role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role is required for role action",
+		})
+		return
+	}
+	newRole := models.UserRole(req.Role)
+	if req.Action == BulkUserActionRole && newRole != models.RoleUser && !actor.Role.CanElevateTo(newRole) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Insufficient role to assign " + req.Role,
+		})
+		return
+	}
+
+	results := make([]BulkUserResult, len(req.UserIDs))
+	ids := make([]primitive.ObjectID, 0, len(req.UserIDs))
+	seen := make(map[primitive.ObjectID]bool, len(req.UserIDs))
+	for i, raw := range req.UserIDs {
+		results[i] = BulkUserResult{UserID: raw}
+		id, err := primitive.ObjectIDFromHex(raw)
+		switch {
+		case err != nil:
+			results[i].Status, results[i].Error = BulkUserFailed, "Invalid user ID"
+		case seen[id]:
+			results[i].Status, results[i].Error = BulkUserFailed, "Duplicate user ID"
+		case id == actor.UserID:
+			results[i].Status, results[i].Error = BulkUserFailed, "Cannot apply bulk action to own account"
+		default:
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Поточний стан усіх користувачів одним запитом
+	cursor, err := h.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"role": 1, "is_blocked": 1, "is_verified": 1}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching users",
+			"details": err.Error(),
+		})
+		return
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error fetching users",
+			"details": err.Error(),
+		})
+		return
+	}
+	byID := make(map[primitive.ObjectID]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	summary := map[string]int{BulkUserUpdated: 0, BulkUserUnchanged: 0, BulkUserFailed: 0}
+	for i := range results {
+		result := &results[i]
+		if result.Status == "" {
+			id, _ := primitive.ObjectIDFromHex(result.UserID)
+			h.applyBulkAction(ctx, actor, &req, id, byID[id], result)
+		}
+		summary[result.Status]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"action":  req.Action,
+		"results": results,
+		"summary": summary,
+	})
+}
+
+// applyBulkAction виконує дію для одного користувача і записує результат
+func (h *UsersHandler) applyBulkAction(ctx context.Context, actor *middleware.UserClaims, req *BulkUsersRequest, userID primitive.ObjectID, user *models.User, result *BulkUserResult) {
+	if user == nil {
+		result.Status, result.Error = BulkUserFailed, "User not found"
+		return
+	}
+	// Адміністратор не змінює інших адміністраторів; супер-адмін - будь-кого
+	if !actor.Role.CanManageUser(user.GetRole()) {
+		result.Status, result.Error = BulkUserFailed, "Insufficient role to manage this user"
+		return
+	}
+
+	now := time.Now()
+	var changes bson.M
+	revoke := false
+	switch req.Action {
+	case BulkUserActionBlock:
+		if user.IsBlocked && !user.IsSuspended() {
+			result.Status = BulkUserUnchanged
+			return
+		}
+		changes = bson.M{
+			"$set": bson.M{
+				"is_blocked":    true,
+				"block_reason":  req.Reason,
+				"blocked_at":    now,
+				"blocked_until": nil, // Безстрокове блокування замінює призупинення
+				"updated_at":    now,
+			},
+			// Збільшення token_version відкликає всі видані користувачу токени
+			"$inc": bson.M{"token_version": 1},
+		}
+		revoke = true
+	case BulkUserActionUnblock:
+		if !user.IsBlocked {
+			result.Status = BulkUserUnchanged
+			return
+		}
+		changes = bson.M{"$set": bson.M{
+			"is_blocked":    false,
+			"block_reason":  "",
+			"blocked_at":    nil,
+			"blocked_until": nil,
+			"updated_at":    now,
+		}}
+	case BulkUserActionVerify:
+		if user.IsVerified {
+			result.Status = BulkUserUnchanged
+			return
+		}
+		changes = bson.M{"$set": bson.M{
+			"is_verified": true,
+			"verified_at": now,
+			"updated_at":  now,
+		}}
+	case BulkUserActionRole:
+		if user.GetRole() == models.UserRole(req.Role) {
+			result.Status = BulkUserUnchanged
+			return
+		}
+		changes = bson.M{
+			"$set": bson.M{
+				"role":       req.Role,
+				"updated_at": now,
+			},
+			"$inc": bson.M{"token_version": 1},
+			"$push": roleHistoryPush(models.RoleChange{
+				FromRole:  string(user.GetRole()),
+				ToRole:    req.Role,
+				ChangedBy: &actor.UserID,
+				Source:    models.RoleChangeSourceBulk,
+				Reason:    strings.TrimSpace(req.Reason),
+				ChangedAt: now,
+			}),
+		}
+		revoke = true
+	}
+
+	if _, err := h.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, changes); err != nil {
+		result.Status, result.Error = BulkUserFailed, "Failed to update user"
+		return
+	}
+	result.Status = BulkUserUpdated
+
+	// Блокування і нова роль діють з наступного запиту користувача
+	if revoke {
+		h.revokeSessions(ctx, userID)
+	} else {
+		h.userStatus.Invalidate(userID)
+	}
+}
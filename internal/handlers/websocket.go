@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"math/rand"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/internal/services"
 	"nova-kakhovka-ecity/pkg/auth"
 
 	"github.com/gin-gonic/gin"
@@ -39,6 +45,18 @@ type Hub struct {
 	broadcast chan *BroadcastMessage
 
 	mutex sync.RWMutex
+
+	// Long-poll клиенты, ожидающие событий по топику (group:<id>, user:<id>)
+	waiters      map[string]map[chan struct{}]struct{}
+	waitersMutex sync.Mutex
+
+	// Остановка сервера: closing (под mutex) запрещает новые подключения, stop закрывает
+	// соединения и long-poll, quit завершает run после того, как все pumps вышли
+	closing bool
+	stop    chan struct{}
+	quit    chan struct{}
+	done    chan struct{}
+	pumps   sync.WaitGroup
 }
 
 type Client struct {
@@ -47,6 +65,25 @@ type Client struct {
 	send    chan []byte
 	userID  primitive.ObjectID
 	groupID primitive.ObjectID
+	ip      string // Адреса клієнта для ліміту з'єднань
+
+	// Кадр закрытия, который writePump отправит после очереди (задается до close(send))
+	closeFrame []byte
+
+	// Подтверждения доставки (?ack=true): позиция хранится по сессии токена. Пока досылается
+	// офлайн-очередь (syncing), новые сообщения группы копятся в pending
+	acks      bool
+	sessionID string
+	delivery  sync.Mutex
+	syncing   bool
+	pending   []queuedMessage
+	replayed  map[primitive.ObjectID]bool
+}
+
+// queuedMessage - кадр new_message, ожидающий конца досылки офлайн-очереди
+type queuedMessage struct {
+	id      primitive.ObjectID
+	payload []byte
 }
 
 type BroadcastMessage struct {
@@ -56,8 +93,20 @@ type BroadcastMessage struct {
 
 type WSMessage struct {
 	Type    string      `json:"type"`
+	Version int         `json:"version"`
 	GroupID string      `json:"group_id,omitempty"`
 	Data    interface{} `json:"data"`
+	// Cursor - ID последнего доставленного события, общий для WebSocket и long-poll
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// MarshalJSON проставляет версию протокола во все исходящие кадры
+func (m WSMessage) MarshalJSON() ([]byte, error) {
+	type wsMessage WSMessage
+	if m.Version == 0 {
+		m.Version = WSProtocolVersion
+	}
+	return json.Marshal(wsMessage(m))
 }
 
 type WebSocketHandler struct {
@@ -65,14 +114,26 @@ type WebSocketHandler struct {
 	jwtManager        *auth.JWTManager
 	groupCollection   *mongo.Collection
 	messageCollection *mongo.Collection
+	chatLimiter       *services.ChatLimiter
+	trustService      *services.TrustService
+	connectionGuard   *services.ConnectionGuard
+	userStatus        *services.UserStatusCache
+	sessions          *services.SessionService
+	presence          *services.PresenceService
+	delivery          *services.ChatDeliveryService
+	chatFilter        *services.ChatFilterService
 }
 
-func NewWebSocketHandler(jwtManager *auth.JWTManager, groupCollection, messageCollection *mongo.Collection) *WebSocketHandler {
+func NewWebSocketHandler(jwtManager *auth.JWTManager, groupCollection, messageCollection *mongo.Collection, chatLimiter *services.ChatLimiter, trustService *services.TrustService, connectionGuard *services.ConnectionGuard, userStatus *services.UserStatusCache, sessions *services.SessionService, presence *services.PresenceService, delivery *services.ChatDeliveryService, chatFilter *services.ChatFilterService) *WebSocketHandler {
 	hub := &Hub{
 		clients:    make(map[primitive.ObjectID]map[*Client]bool),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan *BroadcastMessage),
+		waiters:    make(map[string]map[chan struct{}]struct{}),
+		stop:       make(chan struct{}),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
 	}
 
 	return &WebSocketHandler{
@@ -80,17 +141,204 @@ func NewWebSocketHandler(jwtManager *auth.JWTManager, groupCollection, messageCo
 		jwtManager:        jwtManager,
 		groupCollection:   groupCollection,
 		messageCollection: messageCollection,
+		chatLimiter:       chatLimiter,
+		trustService:      trustService,
+		connectionGuard:   connectionGuard,
+		userStatus:        userStatus,
+		sessions:          sessions,
+		presence:          presence,
+		delivery:          delivery,
+		chatFilter:        chatFilter,
+	}
+}
+
+// checkAccount перевіряє, що власник токена існує, не заблокований, а токен і його сесію не відкликано.
+// Повертає false, якщо відмову вже надіслано.
+func (h *WebSocketHandler) checkAccount(c *gin.Context, userID primitive.ObjectID, claims *auth.Claims) (models.AccountStatus, bool) {
+	status, err := h.userStatus.AccountStatus(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Unable to verify account status",
+		})
+		return status, false
+	}
+	if !status.Exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Account no longer exists",
+		})
+		return status, false
+	}
+	if status.IsBlocked {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "Account is blocked",
+			"is_blocked": true,
+		})
+		return status, false
+	}
+	if !status.AcceptsTokenVersion(claims.TokenVersion) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token has been revoked, please log in again",
+			"code":  "TOKEN_REVOKED",
+		})
+		return status, false
+	}
+	if sessionID, err := primitive.ObjectIDFromHex(claims.SessionID); err == nil {
+		active, err := h.sessions.IsActive(c.Request.Context(), sessionID, c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Unable to verify session",
+			})
+			return status, false
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Session has been revoked, please log in again",
+				"code":  "SESSION_REVOKED",
+			})
+			return status, false
+		}
+	}
+	return status, true
+}
+
+// respondConnectionLimited - відмова в підключенні захистом від частих перепідключень
+func respondConnectionLimited(c *gin.Context, err error) {
+	var limitErr *services.ConnectionLimitError
+	if !errors.As(err, &limitErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Connection error",
+		})
+		return
 	}
+
+	payload := gin.H{
+		"error":   "Too many connections",
+		"code":    limitErr.Code,
+		"details": limitErr.Error(),
+	}
+	if limitErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+		payload["retry_after_seconds"] = limitErr.RetryAfterSeconds()
+	}
+	c.JSON(http.StatusTooManyRequests, payload)
 }
 
 func (h *WebSocketHandler) StartHub() {
 	go h.hub.run()
 }
 
+// Параметры остановки сервера
+const (
+	// Клиенты переподключаются с разбросом, чтобы не прийти на новый экземпляр одновременно
+	wsReconnectMinDelay = time.Second
+	wsReconnectJitter   = 4 * time.Second
+	// Retry-After для отклоненных во время остановки подключений
+	wsShutdownRetryAfter = 5
+)
+
+// wsShutdownCloseFrame - кадр закрытия 1012 (Service Restart): клиенту нужно переподключиться
+var wsShutdownCloseFrame = websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+
+// Shutdown останавливает чат: новые подключения отклоняются, клиенты получают server_shutdown
+// с задержкой переподключения и кадр закрытия после уже поставленных в очередь сообщений.
+// Возвращается, когда все соединения закрыты и hub остановлен, или по ctx.
+func (h *WebSocketHandler) Shutdown(ctx context.Context) error {
+	hub := h.hub
+
+	hub.mutex.Lock()
+	if hub.closing {
+		hub.mutex.Unlock()
+		return nil
+	}
+	hub.closing = true
+	hub.mutex.Unlock()
+
+	close(hub.stop)
+
+	drained := make(chan struct{})
+	go func() {
+		hub.pumps.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	close(hub.quit)
+	<-hub.done
+	return nil
+}
+
+// isClosing - сервер останавливается и не принимает новые подключения
+func (hub *Hub) isClosing() bool {
+	hub.mutex.RLock()
+	defer hub.mutex.RUnlock()
+	return hub.closing
+}
+
+// closeForShutdown отправляет клиенту server_shutdown и закрывает очередь: writePump допишет
+// очередь и закроет соединение с кодом 1012. Вызывается под hub.mutex.
+func (hub *Hub) closeForShutdown(client *Client) {
+	reconnectAfter := wsReconnectMinDelay + time.Duration(rand.Int63n(int64(wsReconnectJitter)))
+	client.sendFrame(WSMessage{
+		Type:    "server_shutdown",
+		GroupID: client.groupHex(),
+		Data: map[string]interface{}{
+			"reason":             "restart",
+			"reconnect_after_ms": reconnectAfter.Milliseconds(),
+		},
+	})
+	client.closeFrame = wsShutdownCloseFrame
+	close(client.send)
+}
+
+// publish передает сообщение в run; после остановки hub сообщение не доставляется по WebSocket
+func (hub *Hub) publish(message *BroadcastMessage) {
+	select {
+	case hub.broadcast <- message:
+	case <-hub.done:
+	}
+}
+
 func (hub *Hub) run() {
+	// После остановки канал обнуляется, чтобы закрытый stop не срабатывал повторно
+	stop := hub.stop
+	stopped := false
+
 	for {
 		select {
+		case <-stop:
+			stop = nil
+			stopped = true
+
+			hub.mutex.Lock()
+			count := 0
+			for groupID, clients := range hub.clients {
+				for client := range clients {
+					hub.closeForShutdown(client)
+					count++
+				}
+				delete(hub.clients, groupID)
+			}
+			hub.mutex.Unlock()
+			log.Printf("Closing %d WebSocket connections for shutdown", count)
+
+		case <-hub.quit:
+			close(hub.done)
+			return
+
 		case client := <-hub.register:
+			// Подключение, принятое до остановки, но зарегистрированное после нее
+			if stopped {
+				hub.mutex.Lock()
+				hub.closeForShutdown(client)
+				hub.mutex.Unlock()
+				continue
+			}
+
 			hub.mutex.Lock()
 			if hub.clients[client.groupID] == nil {
 				hub.clients[client.groupID] = make(map[*Client]bool)
@@ -119,18 +367,20 @@ func (hub *Hub) run() {
 			hub.mutex.RUnlock()
 
 			messageBytes, err := json.Marshal(WSMessage{
-				Type: "new_message",
-				Data: message.Message,
+				Type:   "new_message",
+				Data:   message.Message,
+				Cursor: message.Message.ID.Hex(),
 			})
 			if err != nil {
 				log.Printf("Error marshaling message: %v", err)
 				continue
 			}
 
+			// Будим long-poll клиентов этой группы
+			hub.wake(groupTopic(message.GroupID))
+
 			for client := range clients {
-				select {
-				case client.send <- messageBytes:
-				default:
+				if !client.offerMessage(message.Message.ID, messageBytes) {
 					hub.mutex.Lock()
 					close(client.send)
 					delete(clients, client)
@@ -144,10 +394,159 @@ func (hub *Hub) run() {
 	}
 }
 
+func groupTopic(groupID primitive.ObjectID) string {
+	return "group:" + groupID.Hex()
+}
+
+func userTopic(userID primitive.ObjectID) string {
+	return "user:" + userID.Hex()
+}
+
+// wait регистрирует long-poll ожидание по топику. Функция отмены обязательна к вызову.
+func (hub *Hub) wait(topic string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	hub.waitersMutex.Lock()
+	if hub.waiters[topic] == nil {
+		hub.waiters[topic] = make(map[chan struct{}]struct{})
+	}
+	hub.waiters[topic][ch] = struct{}{}
+	hub.waitersMutex.Unlock()
+
+	return ch, func() {
+		hub.waitersMutex.Lock()
+		delete(hub.waiters[topic], ch)
+		if len(hub.waiters[topic]) == 0 {
+			delete(hub.waiters, topic)
+		}
+		hub.waitersMutex.Unlock()
+	}
+}
+
+// wake сигнализирует всем long-poll ожиданиям топика о новых событиях
+func (hub *Hub) wake(topic string) {
+	hub.waitersMutex.Lock()
+	defer hub.waitersMutex.Unlock()
+
+	for ch := range hub.waiters[topic] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// BroadcastMessage доставляет сообщение чата WebSocket и long-poll клиентам группы
+func (h *WebSocketHandler) BroadcastMessage(message *models.Message) {
+	h.hub.publish(&BroadcastMessage{
+		GroupID: message.GroupID,
+		Message: message,
+	})
+}
+
+// OnlineUserCount - число пользователей с открытым WebSocket (пользователь в нескольких группах считается один раз)
+func (h *WebSocketHandler) OnlineUserCount() int {
+	h.hub.mutex.RLock()
+	defer h.hub.mutex.RUnlock()
+
+	users := make(map[primitive.ObjectID]struct{})
+	for _, clients := range h.hub.clients {
+		for client := range clients {
+			users[client.userID] = struct{}{}
+		}
+	}
+	return len(users)
+}
+
+// DisconnectUser закриває чат-з'єднання користувача після блокування або зміни ролі.
+// Клієнт перепідключиться і пройде перевірку токена заново.
+func (h *WebSocketHandler) DisconnectUser(userID primitive.ObjectID) {
+	h.hub.mutex.RLock()
+	var conns []*websocket.Conn
+	for _, clients := range h.hub.clients {
+		for client := range clients {
+			if client.userID == userID {
+				conns = append(conns, client.conn)
+			}
+		}
+	}
+	h.hub.mutex.RUnlock()
+
+	// Закриття з'єднання завершує readPump, який знімає клієнта з хаба
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// DisconnectFromGroup закриває з'єднання користувача з чатом групи, з якої його виключили.
+// Повторне підключення не пройде перевірку членства.
+func (h *WebSocketHandler) DisconnectFromGroup(userID, groupID primitive.ObjectID) {
+	h.hub.mutex.RLock()
+	var conns []*websocket.Conn
+	for client := range h.hub.clients[groupID] {
+		if client.userID == userID {
+			conns = append(conns, client.conn)
+		}
+	}
+	h.hub.mutex.RUnlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// SendToUser доставляет кадр во все WebSocket-соединения пользователя, к какой бы группе
+// они ни были подключены (личные события: direct messages)
+func (h *WebSocketHandler) SendToUser(userID primitive.ObjectID, msg WSMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling WebSocket frame: %v", err)
+		return
+	}
+
+	h.hub.mutex.RLock()
+	defer h.hub.mutex.RUnlock()
+	for _, clients := range h.hub.clients {
+		for client := range clients {
+			if client.userID != userID {
+				continue
+			}
+			select {
+			case client.send <- payload:
+			default:
+			}
+		}
+	}
+}
+
+// NotifyUser доставляет новое уведомление во все WebSocket-соединения пользователя
+// (notification.created) и будит его long-poll клиентов
+func (h *WebSocketHandler) NotifyUser(notification *services.StoredNotification) {
+	h.SendToUser(notification.UserID, WSMessage{
+		Type:   "notification.created",
+		Data:   notification,
+		Cursor: notification.ID.Hex(),
+	})
+	h.hub.wake(userTopic(notification.UserID))
+}
+
 // internal/handlers/websocket.go
 // Замінити функцію HandleWebSocket
 
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
+	// Під час зупинки сервера клієнт підключається до іншого екземпляра або пізніше
+	if h.hub.isClosing() {
+		respondShuttingDown(c)
+		return
+	}
+
+	// Ліміт спроб підключення рахується до перевірки токена
+	ip := c.ClientIP()
+	if err := h.connectionGuard.Admit(ip); err != nil {
+		respondConnectionLimited(c, err)
+		return
+	}
+
 	// Отримуємо JWT токен з query параметра
 	token := c.Query("token")
 	if token == "" {
@@ -166,21 +565,16 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
-	// Отримуємо ID групи
-	groupID := c.Query("group_id")
-	if groupID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Group ID is required",
-		})
-		return
-	}
-
-	groupIDObj, err := primitive.ObjectIDFromHex(groupID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid group ID",
-		})
-		return
+	// Отримуємо ID групи. Без group_id з'єднання отримує лише особисті події (direct messages)
+	var groupIDObj primitive.ObjectID
+	if groupID := c.Query("group_id"); groupID != "" {
+		groupIDObj, err = primitive.ObjectIDFromHex(groupID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid group ID",
+			})
+			return
+		}
 	}
 
 	// ✅ ВИПРАВЛЕННЯ 1: Конвертуємо UserID з string в ObjectID
@@ -192,35 +586,60 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	if _, ok := h.checkAccount(c, userIDObj, claims); !ok {
+		return
+	}
+
 	// Перевіряємо, чи є користувач учасником групи
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if !groupIDObj.IsZero() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-	// ✅ ВИПРАВЛЕННЯ 2: Використовуємо userIDObj замість claims.UserID
-	count, err := h.groupCollection.CountDocuments(ctx, bson.M{
-		"_id":     groupIDObj,
-		"members": bson.M{"$in": []primitive.ObjectID{userIDObj}},
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Database error",
-		})
-		return
+		// ✅ ВИПРАВЛЕННЯ 2: Використовуємо userIDObj замість claims.UserID
+		count, err := h.groupCollection.CountDocuments(ctx, communityScope(c, bson.M{
+			"_id":     groupIDObj,
+			"members": bson.M{"$in": []primitive.ObjectID{userIDObj}},
+		}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Database error",
+			})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "User is not a member of this group",
+			})
+			return
+		}
 	}
-	if count == 0 {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "User is not a member of this group",
-		})
+
+	// Слот з'єднання займаємо лише для автентифікованого учасника, до реєстрації в hub
+	if err := h.connectionGuard.Acquire(ip); err != nil {
+		respondConnectionLimited(c, err)
 		return
 	}
 
 	// Встановлюємо WebSocket з'єднання
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
+		h.connectionGuard.Release(ip)
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
 
+	// Зупинка почалася під час перевірок: закриваємо одразу, pumps не запускаємо
+	h.hub.mutex.Lock()
+	if h.hub.closing {
+		h.hub.mutex.Unlock()
+		conn.WriteControl(websocket.CloseMessage, wsShutdownCloseFrame, time.Now().Add(writeWait))
+		conn.Close()
+		h.connectionGuard.Release(ip)
+		return
+	}
+	h.hub.pumps.Add(2)
+	h.hub.mutex.Unlock()
+
 	// ✅ ВИПРАВЛЕННЯ 3: Використовуємо userIDObj замість claims.UserID
 	client := &Client{
 		hub:     h.hub,
@@ -228,10 +647,28 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		send:    make(chan []byte, 256),
 		userID:  userIDObj, // Тепер правильний тип: primitive.ObjectID
 		groupID: groupIDObj,
+		ip:      ip,
+	}
+	// Офлайн-черга ведеться лише для групових з'єднань, які підтверджують доставку
+	if acks, _ := strconv.ParseBool(c.Query("ack")); acks && !groupIDObj.IsZero() {
+		client.acks = true
+		client.sessionID = claims.SessionID
+		client.syncing = true
 	}
 
+	// Першим кадром клієнт отримує версії протоколу, які підтримує сервер.
+	// Кадр ставиться в чергу до реєстрації: після неї hub може закрити чергу
+	client.sendFrame(WSMessage{Type: "hello", GroupID: client.groupHex(), Data: wsHelloData()})
+
+	// Присутність знімається в readPump, який завершується для кожного з'єднання
+	h.presence.Connected(userIDObj)
 	client.hub.register <- client
 
+	// Досилаємо після реєстрації: нові повідомлення вже накопичуються в pending і не загубляться
+	if client.acks {
+		go h.resumeDelivery(client)
+	}
+
 	// Запускаємо goroutines для читання та запису
 	go client.writePump()
 	go client.readPump(h)
@@ -241,13 +678,26 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 4096 // Вмещает 1000 символов кириллицы с конвертом; больший кадр закрывает соединение
 )
 
+// respondShuttingDown - відмова в підключенні під час зупинки сервера
+func respondShuttingDown(c *gin.Context) {
+	c.Header("Retry-After", strconv.Itoa(wsShutdownRetryAfter))
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":               "Server is shutting down",
+		"code":                "SERVER_SHUTTING_DOWN",
+		"retry_after_seconds": wsShutdownRetryAfter,
+	})
+}
+
 func (c *Client) readPump(h *WebSocketHandler) {
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
+		h.connectionGuard.Release(c.ip)
+		h.presence.Disconnected(c.userID)
+		c.hub.pumps.Done()
 	}()
 
 	c.conn.SetReadLimit(maxMessageSize)
@@ -258,8 +708,7 @@ func (c *Client) readPump(h *WebSocketHandler) {
 	})
 
 	for {
-		var wsMsg WSMessage
-		err := c.conn.ReadJSON(&wsMsg)
+		messageType, raw, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
@@ -267,23 +716,79 @@ func (c *Client) readPump(h *WebSocketHandler) {
 			break
 		}
 
+		if messageType != websocket.TextMessage {
+			c.sendProtocolError(&WSProtocolError{Code: WSErrorInvalidFrame, Message: "frames must be JSON text messages"})
+			continue
+		}
+
+		frame, err := decodeWSFrame(raw)
+		if err != nil {
+			var protocolErr *WSProtocolError
+			if errors.As(err, &protocolErr) {
+				c.sendProtocolError(protocolErr)
+			}
+			continue
+		}
+
+		// Личное соединение (без group_id) не пишет в группы
+		if c.groupID.IsZero() && frame.Type != "ping" {
+			c.sendProtocolError(&WSProtocolError{
+				Code:    WSErrorInvalidFrame,
+				Message: "connection is not bound to a group, reconnect with group_id",
+				Type:    frame.Type,
+			})
+			continue
+		}
+
 		// Обрабатываем разные типы сообщений
-		switch wsMsg.Type {
+		switch frame.Type {
 		case "send_message":
-			h.handleSendMessage(c, wsMsg.Data)
+			h.handleSendMessage(c, &frame.SendMessage)
 		case "typing":
-			h.handleTyping(c, wsMsg.GroupID)
+			h.handleTyping(c, frame.Typing.GroupID)
+		case "ack":
+			h.handleAck(c, frame.Ack.MessageID)
 		case "ping":
-			c.send <- []byte(`{"type": "pong"}`)
+			c.sendFrame(WSMessage{Type: "pong"})
 		}
 	}
 }
 
+// sendFrame ставит кадр в очередь клиента; при переполненной очереди кадр отбрасывается
+func (c *Client) sendFrame(msg WSMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling WebSocket frame: %v", err)
+		return
+	}
+	select {
+	case c.send <- payload:
+	default:
+	}
+}
+
+// groupHex - ID группы соединения для кадров; у личного соединения группы нет
+func (c *Client) groupHex() string {
+	if c.groupID.IsZero() {
+		return ""
+	}
+	return c.groupID.Hex()
+}
+
+func (c *Client) sendProtocolError(protocolErr *WSProtocolError) {
+	c.sendFrame(WSMessage{
+		Type:    "error",
+		GroupID: c.groupHex(),
+		Data:    protocolErr.frameData(),
+	})
+}
+
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		c.hub.pumps.Done()
 	}()
 
 	for {
@@ -291,7 +796,8 @@ func (c *Client) writePump() {
 		case message, ok := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// Очередь закрыта hub: все сообщения до закрытия уже отправлены
+				c.conn.WriteMessage(websocket.CloseMessage, c.closeFrame)
 				return
 			}
 
@@ -321,45 +827,61 @@ func (c *Client) writePump() {
 	}
 }
 
-func (h *WebSocketHandler) handleSendMessage(client *Client, data interface{}) {
-	// Преобразуем data в map для удобства работы
-	messageData, ok := data.(map[string]interface{})
-	if !ok {
-		log.Printf("Invalid message data format")
+// handleSendMessage - payload уже проверен схемой send_message
+func (h *WebSocketHandler) handleSendMessage(client *Client, payload *WSSendMessagePayload) {
+	content := payload.Content
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var group models.Group
+	if err := h.groupCollection.FindOne(ctx, bson.M{"_id": client.groupID}).Decode(&group); err != nil {
+		log.Printf("Error loading group for message: %v", err)
 		return
 	}
 
-	content, ok := messageData["content"].(string)
-	if !ok || content == "" {
-		log.Printf("Invalid or empty message content")
+	// В канале объявлений участники только читают
+	if !group.CanSendMessages(client.userID) {
+		client.sendFrame(WSMessage{
+			Type:    "error",
+			GroupID: client.groupID.Hex(),
+			Data:    announcementsOnlyPayload(),
+		})
 		return
 	}
 
-	messageType, ok := messageData["type"].(string)
-	if !ok {
-		messageType = "text"
+	// Лимит частоты и slow mode - те же, что и для REST SendMessage
+	quota := h.trustService.Quota(ctx, client.userID)
+	if h.chatLimiter != nil {
+		if err := h.chatLimiter.Allow(client.userID, client.groupID, chatSlowMode(&group, client.userID, quota)); err != nil {
+			if limitErr, ok := err.(*services.ChatLimitError); ok {
+				client.sendFrame(WSMessage{
+					Type:    "error",
+					GroupID: client.groupID.Hex(),
+					Data:    chatLimitPayload(limitErr),
+				})
+			}
+			return
+		}
 	}
 
-	mediaURL, _ := messageData["media_url"].(string)
-
 	// Создаем новое сообщение
 	now := time.Now()
 	message := models.Message{
 		GroupID:   client.groupID,
 		UserID:    client.userID,
 		Content:   content,
-		Type:      messageType,
-		MediaURL:  mediaURL,
+		Type:      payload.Type,
+		MediaURL:  payload.MediaURL,
 		IsEdited:  false,
 		IsDeleted: false,
+		IsHeld:    quota.HoldLinks && models.ContainsLink(content),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	applyChatFilter(ctx, h.chatFilter, &message)
 
 	// Сохраняем сообщение в базу данных
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	result, err := h.messageCollection.InsertOne(ctx, message)
 	if err != nil {
 		log.Printf("Error saving message: %v", err)
@@ -368,13 +890,136 @@ func (h *WebSocketHandler) handleSendMessage(client *Client, data interface{}) {
 
 	message.ID = result.InsertedID.(primitive.ObjectID)
 
+	// Удержанное сообщение видит только автор до проверки модератором
+	if message.IsHeld {
+		client.sendFrame(WSMessage{
+			Type:    "message_held",
+			GroupID: client.groupID.Hex(),
+			Data:    message,
+		})
+		return
+	}
+
 	// Отправляем сообщение всем участникам группы
 	broadcastMsg := &BroadcastMessage{
 		GroupID: client.groupID,
 		Message: &message,
 	}
 
-	h.hub.broadcast <- broadcastMsg
+	h.hub.publish(broadcastMsg)
+}
+
+// offerMessage ставит кадр new_message в очередь клиента. Во время досылки офлайн-очереди
+// кадр откладывается, а уже досланные сообщения не повторяются. false - очередь переполнена.
+func (c *Client) offerMessage(id primitive.ObjectID, payload []byte) bool {
+	c.delivery.Lock()
+	defer c.delivery.Unlock()
+
+	if c.syncing {
+		c.pending = append(c.pending, queuedMessage{id: id, payload: payload})
+		return true
+	}
+	if c.replayed[id] {
+		return true
+	}
+	select {
+	case c.send <- payload:
+		return true
+	default:
+		return false
+	}
+}
+
+// resumeDelivery досылает сообщения после подтвержденной позиции сессии, затем отложенные
+// за это время новые сообщения - все по порядку ID. Завершается кадром delivery_resumed.
+func (h *WebSocketHandler) resumeDelivery(client *Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Ошибка базы не останавливает живые сообщения: клиент получит пропущенное при
+	// следующем подключении
+	after, err := h.delivery.Position(ctx, client.groupID, client.userID, client.sessionID)
+	var backlog []models.Message
+	hasMore := false
+	if err == nil {
+		backlog, hasMore, err = h.delivery.Backlog(ctx, client.groupID, after, services.ChatReplayLimit)
+	}
+	if err != nil {
+		log.Printf("Error loading undelivered messages of group %s for user %s: %v", client.groupID.Hex(), client.userID.Hex(), err)
+	}
+
+	frames := make([]queuedMessage, 0, len(backlog))
+	for i := range backlog {
+		payload, err := json.Marshal(WSMessage{
+			Type:   "new_message",
+			Data:   backlog[i],
+			Cursor: backlog[i].ID.Hex(),
+		})
+		if err != nil {
+			continue
+		}
+		frames = append(frames, queuedMessage{id: backlog[i].ID, payload: payload})
+	}
+	resumed, _ := json.Marshal(WSMessage{
+		Type:    "delivery_resumed",
+		GroupID: client.groupID.Hex(),
+		Data: map[string]interface{}{
+			"after_message_id": after,
+			"replayed":         len(frames),
+			"has_more":         hasMore,
+		},
+	})
+
+	// Hub закрывает очередь клиента под записью: пока держим чтение, очередь открыта
+	h.hub.mutex.RLock()
+	defer h.hub.mutex.RUnlock()
+	if !h.hub.clients[client.groupID][client] {
+		return
+	}
+
+	client.delivery.Lock()
+	defer client.delivery.Unlock()
+
+	sort.SliceStable(client.pending, func(i, j int) bool {
+		return bytes.Compare(client.pending[i].id[:], client.pending[j].id[:]) < 0
+	})
+	client.replayed = make(map[primitive.ObjectID]bool, len(frames))
+	for _, frame := range frames {
+		client.replayed[frame.id] = true
+	}
+	queue := append(frames, queuedMessage{payload: resumed})
+	for _, frame := range client.pending {
+		if !client.replayed[frame.id] {
+			queue = append(queue, frame)
+		}
+	}
+	client.pending = nil
+	client.syncing = false
+
+	for _, frame := range queue {
+		select {
+		case client.send <- frame.payload:
+		default:
+			// Очередь переполнена: переподключение дошлет остаток с подтвержденной позиции
+			client.conn.Close()
+			return
+		}
+	}
+}
+
+// handleAck переносит позицию доставки сессии; подтверждение накопительное
+func (h *WebSocketHandler) handleAck(client *Client, messageID string) {
+	messageIDObj, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.delivery.Ack(ctx, client.groupID, client.userID, client.sessionID, messageIDObj); err != nil {
+		log.Printf("Error storing delivery ack of group %s for user %s: %v", client.groupID.Hex(), client.userID.Hex(), err)
+	}
 }
 
 func (h *WebSocketHandler) handleTyping(client *Client, groupID string) {
@@ -387,6 +1032,17 @@ func (h *WebSocketHandler) handleTyping(client *Client, groupID string) {
 		return
 	}
 
+	// Соединение открыто для одной группы: печать в чужую группу не транслируется
+	if groupIDObj != client.groupID {
+		client.sendProtocolError(&WSProtocolError{
+			Code:    WSErrorInvalidPayload,
+			Message: "group_id must match the connected group",
+			Type:    "typing",
+			Fields:  []WSFieldError{{Field: "group_id", Message: "must match the connected group"}},
+		})
+		return
+	}
+
 	// Отправляем уведомление о печати всем участникам группы, кроме отправителя
 	h.hub.mutex.RLock()
 	clients := h.hub.clients[groupIDObj]
@@ -412,27 +1068,33 @@ func (h *WebSocketHandler) handleTyping(client *Client, groupID string) {
 	}
 }
 
-// Метод для отправки системных уведомлений
+// SendSystemMessage отправляет событие группы (message_updated, message_deleted и т.п.)
+// всем WebSocket клиентам группы
 func (h *WebSocketHandler) SendSystemMessage(groupID primitive.ObjectID, messageType string, data interface{}) {
-	h.hub.mutex.RLock()
-	clients := h.hub.clients[groupID]
-	h.hub.mutex.RUnlock()
-
 	systemMsg, err := json.Marshal(WSMessage{
-		Type: messageType,
-		Data: data,
+		Type:    messageType,
+		GroupID: groupID.Hex(),
+		Data:    data,
 	})
 	if err != nil {
 		log.Printf("Error marshaling system message: %v", err)
 		return
 	}
 
+	// Отправка под записью: медленный клиент удаляется из группы, как в hub.run
+	h.hub.mutex.Lock()
+	defer h.hub.mutex.Unlock()
+
+	clients := h.hub.clients[groupID]
 	for client := range clients {
 		select {
 		case client.send <- systemMsg:
 		default:
 			close(client.send)
 			delete(clients, client)
+			if len(clients) == 0 {
+				delete(h.hub.clients, groupID)
+			}
 		}
 	}
 }
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Протокол WebSocket чата (/ws). Описание для клиентов - WEBSOCKET_PROTOCOL.md.
+//
+// Каждый входящий кадр - конверт {type, version, payload}. Кадры без version
+// считаются версией 1; клиенты версии 1 могут передавать payload в поле data.
+// Ошибки разбора не закрывают соединение: клиент получает кадр error с кодом.
+const (
+	WSProtocolVersion = 1
+
+	// Ограничения полей - те же, что и для REST SendMessage
+	wsMaxContentLength  = 1000
+	wsMaxMediaURLLength = 2048
+)
+
+// WSSupportedVersions - версии протокола, которые принимает сервер
+var WSSupportedVersions = []int{1}
+
+// Коды ошибок протокола в кадре error
+const (
+	WSErrorInvalidFrame       = "invalid_frame"
+	WSErrorUnsupportedVersion = "unsupported_version"
+	WSErrorUnknownType        = "unknown_type"
+	WSErrorInvalidPayload     = "invalid_payload"
+)
+
+// WSEnvelope - входящий кадр от клиента
+type WSEnvelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Поля версии 1 до введения конверта: payload в data, group_id на верхнем уровне
+	Data    json.RawMessage `json:"data,omitempty"`
+	GroupID string          `json:"group_id,omitempty"`
+}
+
+// WSSendMessagePayload - payload кадра send_message
+type WSSendMessagePayload struct {
+	Content  string `json:"content"`
+	Type     string `json:"type,omitempty"`
+	MediaURL string `json:"media_url,omitempty"`
+}
+
+// WSTypingPayload - payload кадра typing
+type WSTypingPayload struct {
+	GroupID string `json:"group_id,omitempty"`
+}
+
+// WSAckPayload - payload кадра ack: получены все сообщения группы до message_id включительно
+type WSAckPayload struct {
+	MessageID string `json:"message_id"`
+}
+
+// WSPingPayload - у ping нет полей
+type WSPingPayload struct{}
+
+// WSFieldError - ошибка в конкретном поле payload
+type WSFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// WSProtocolError - отказ в обработке кадра; отправляется клиенту в кадре error
+type WSProtocolError struct {
+	Code    string
+	Message string
+	Type    string // Тип отклоненного кадра, если удалось его прочитать
+	Fields  []WSFieldError
+}
+
+func (e *WSProtocolError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// payload кадра error
+func (e *WSProtocolError) frameData() map[string]interface{} {
+	data := map[string]interface{}{
+		"error": "Invalid frame",
+		"code":  e.Code,
+	}
+	if e.Message != "" {
+		data["details"] = e.Message
+	}
+	if e.Type != "" {
+		data["frame_type"] = e.Type
+	}
+	if len(e.Fields) > 0 {
+		data["fields"] = e.Fields
+	}
+	if e.Code == WSErrorUnsupportedVersion {
+		data["supported_versions"] = WSSupportedVersions
+	}
+	return data
+}
+
+var wsMessageTypes = map[string]bool{
+	"text":  true,
+	"image": true,
+	"video": true,
+	"file":  true,
+	"link":  true,
+}
+
+// Схемы входящих кадров: тип кадра -> разбор и проверка payload
+var wsFrameSchemas = map[string]func(frame *wsFrame) []WSFieldError{
+	"send_message": func(frame *wsFrame) []WSFieldError {
+		payload := &frame.SendMessage
+		var fields []WSFieldError
+
+		payload.Content = strings.TrimSpace(payload.Content)
+		switch {
+		case payload.Content == "":
+			fields = append(fields, WSFieldError{Field: "content", Message: "is required"})
+		case utf8.RuneCountInString(payload.Content) > wsMaxContentLength:
+			fields = append(fields, WSFieldError{Field: "content", Message: fmt.Sprintf("must be at most %d characters", wsMaxContentLength)})
+		}
+
+		if payload.Type == "" {
+			payload.Type = "text"
+		}
+		if !wsMessageTypes[payload.Type] {
+			fields = append(fields, WSFieldError{Field: "type", Message: "must be one of text, image, video, file, link"})
+		}
+
+		if payload.MediaURL != "" {
+			parsed, err := url.Parse(payload.MediaURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(payload.MediaURL) > wsMaxMediaURLLength {
+				fields = append(fields, WSFieldError{Field: "media_url", Message: "must be an absolute http(s) URL"})
+			}
+		}
+		return fields
+	},
+	"typing": func(frame *wsFrame) []WSFieldError {
+		// group_id версии 1 передавался на верхнем уровне кадра
+		if frame.Typing.GroupID == "" {
+			frame.Typing.GroupID = frame.legacyGroupID
+		}
+		if frame.Typing.GroupID == "" {
+			return nil
+		}
+		if _, err := primitive.ObjectIDFromHex(frame.Typing.GroupID); err != nil {
+			return []WSFieldError{{Field: "group_id", Message: "must be a valid ID"}}
+		}
+		return nil
+	},
+	"ack": func(frame *wsFrame) []WSFieldError {
+		if frame.Ack.MessageID == "" {
+			return []WSFieldError{{Field: "message_id", Message: "is required"}}
+		}
+		if _, err := primitive.ObjectIDFromHex(frame.Ack.MessageID); err != nil {
+			return []WSFieldError{{Field: "message_id", Message: "must be a valid ID"}}
+		}
+		return nil
+	},
+	"ping": func(frame *wsFrame) []WSFieldError {
+		return nil
+	},
+}
+
+// wsFrame - проверенный входящий кадр
+type wsFrame struct {
+	Type        string
+	Version     int
+	SendMessage WSSendMessagePayload
+	Typing      WSTypingPayload
+	Ack         WSAckPayload
+
+	legacyGroupID string
+}
+
+// decodeWSFrame разбирает кадр клиента и проверяет payload по схеме его типа
+func decodeWSFrame(raw []byte) (*wsFrame, error) {
+	var envelope WSEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, &WSProtocolError{Code: WSErrorInvalidFrame, Message: "frame must be a JSON object"}
+	}
+	if envelope.Type == "" {
+		return nil, &WSProtocolError{Code: WSErrorInvalidFrame, Message: "type is required"}
+	}
+
+	version := envelope.Version
+	if version == 0 {
+		version = WSProtocolVersion
+	}
+	if !wsVersionSupported(version) {
+		return nil, &WSProtocolError{
+			Code:    WSErrorUnsupportedVersion,
+			Message: fmt.Sprintf("protocol version %d is not supported", version),
+			Type:    envelope.Type,
+		}
+	}
+
+	validate, ok := wsFrameSchemas[envelope.Type]
+	if !ok {
+		return nil, &WSProtocolError{
+			Code:    WSErrorUnknownType,
+			Message: fmt.Sprintf("unknown frame type %q", envelope.Type),
+			Type:    envelope.Type,
+		}
+	}
+
+	payload := envelope.Payload
+	if len(payload) == 0 {
+		payload = envelope.Data
+	}
+
+	frame := &wsFrame{
+		Type:          envelope.Type,
+		Version:       version,
+		legacyGroupID: envelope.GroupID,
+	}
+
+	var target interface{}
+	switch envelope.Type {
+	case "send_message":
+		target = &frame.SendMessage
+	case "typing":
+		target = &frame.Typing
+	case "ack":
+		target = &frame.Ack
+	default:
+		target = &WSPingPayload{}
+	}
+	if err := decodeWSPayload(payload, target); err != nil {
+		return nil, &WSProtocolError{Code: WSErrorInvalidPayload, Message: err.Error(), Type: envelope.Type}
+	}
+
+	if fields := validate(frame); len(fields) > 0 {
+		return nil, &WSProtocolError{
+			Code:    WSErrorInvalidPayload,
+			Message: "payload failed validation",
+			Type:    envelope.Type,
+			Fields:  fields,
+		}
+	}
+	return frame, nil
+}
+
+// decodeWSPayload - строгий разбор: неизвестные поля отклоняются, а не игнорируются молча
+func decodeWSPayload(payload json.RawMessage, target interface{}) error {
+	if len(payload) == 0 || bytes.Equal(payload, []byte("null")) {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return fmt.Errorf("field %s must be %s", typeErr.Field, typeErr.Type.String())
+		}
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+func wsVersionSupported(version int) bool {
+	for _, supported := range WSSupportedVersions {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// wsHelloData - payload кадра hello, который сервер отправляет сразу после подключения
+func wsHelloData() map[string]interface{} {
+	return map[string]interface{}{
+		"protocol_version":   WSProtocolVersion,
+		"supported_versions": WSSupportedVersions,
+		"max_frame_bytes":    maxMessageSize,
+	}
+}
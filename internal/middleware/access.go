@@ -0,0 +1,135 @@
+// internal/middleware/access.go
+
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * RouteAccess - вимоги доступу до маршруту в матриці доступу
+ * MinRole "" - публічний маршрут (без AuthMiddleware), RoleUser - будь-який автентифікований
+ * Permission - додатковий дозвіл (RequirePermission), "" - не потрібен
+ */
+type RouteAccess struct {
+	Method     string
+	Path       string
+	MinRole    models.UserRole
+	Permission models.Permission
+}
+
+/**
+ * Allows - чи має роль доступ до маршруту. Порожня роль - анонімний запит
+ */
+func (a RouteAccess) Allows(role models.UserRole) bool {
+	if a.MinRole == "" {
+		return true
+	}
+	if role == "" || !role.IsHigherOrEqual(a.MinRole) {
+		return false
+	}
+	return a.Permission == "" || role.HasPermission(a.Permission)
+}
+
+/**
+ * AccessMatrix - реєстр вимог доступу для кожного маршруту API
+ * Перевіряється при старті: маршрут без запису в матриці - помилка конфігурації
+ */
+type AccessMatrix []RouteAccess
+
+func (m AccessMatrix) index() map[string]RouteAccess {
+	rules := make(map[string]RouteAccess, len(m))
+	for _, rule := range m {
+		rules[rule.Method+" "+rule.Path] = rule
+	}
+	return rules
+}
+
+/**
+ * Uncovered - зареєстровані маршрути, для яких у матриці немає запису
+ */
+func (m AccessMatrix) Uncovered(routes gin.RoutesInfo) []string {
+	rules := m.index()
+
+	var uncovered []string
+	for _, route := range routes {
+		if _, ok := rules[route.Method+" "+route.Path]; !ok {
+			uncovered = append(uncovered, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(uncovered)
+	return uncovered
+}
+
+/**
+ * SharedHandlers - обробники, підключені до маршрутів з різними вимогами доступу
+ * (як модерація опитувань через той самий UpdatePoll, що й редагування автором)
+ */
+func (m AccessMatrix) SharedHandlers(routes gin.RoutesInfo) []string {
+	rules := m.index()
+
+	byHandler := make(map[string][]string)
+	levels := make(map[string]map[string]bool)
+	for _, route := range routes {
+		rule, ok := rules[route.Method+" "+route.Path]
+		if !ok {
+			continue
+		}
+		level := string(rule.MinRole) + "/" + string(rule.Permission)
+		if levels[route.Handler] == nil {
+			levels[route.Handler] = make(map[string]bool)
+		}
+		levels[route.Handler][level] = true
+		byHandler[route.Handler] = append(byHandler[route.Handler], route.Method+" "+route.Path)
+	}
+
+	var shared []string
+	for handler, paths := range byHandler {
+		if len(levels[handler]) > 1 {
+			shared = append(shared, fmt.Sprintf("%s: %s", shortHandlerName(handler), strings.Join(paths, ", ")))
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func shortHandlerName(name string) string {
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+const routeAccessKey = "route_access"
+
+/**
+ * RouteRules - реєструється на router: додає в context вимоги доступу поточного маршруту з матриці
+ * За ними AuthMiddleware пускає запити з API-ключем лише туди, де матриця вимагає дозвіл ключа
+ */
+func (m AccessMatrix) RouteRules() gin.HandlerFunc {
+	rules := m.index()
+	return func(c *gin.Context) {
+		if rule, ok := rules[c.Request.Method+" "+c.FullPath()]; ok {
+			c.Set(routeAccessKey, rule)
+		}
+		c.Next()
+	}
+}
+
+/**
+ * RouteRule - вимоги доступу поточного маршруту, встановлені RouteRules
+ */
+func RouteRule(c *gin.Context) (RouteAccess, bool) {
+	value, exists := c.Get(routeAccessKey)
+	if !exists {
+		return RouteAccess{}, false
+	}
+	rule, ok := value.(RouteAccess)
+	return rule, ok
+}
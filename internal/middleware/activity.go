@@ -0,0 +1,32 @@
+// internal/middleware/activity.go
+
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+/**
+ * ActivityRecorder - журнал активності користувачів для когортної аналітики
+ * Реалізується services.ActivityService
+ */
+type ActivityRecorder interface {
+	Record(userID primitive.ObjectID, at time.Time)
+}
+
+/**
+ * ActivityMiddleware - відмічає активність автентифікованого користувача
+ * Використовується після AuthMiddleware; запит не затримує
+ */
+func ActivityMiddleware(recorder ActivityRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, err := primitive.ObjectIDFromHex(c.GetString("user_id")); err == nil {
+			recorder.Record(id, time.Now())
+		}
+
+		c.Next()
+	}
+}
@@ -3,23 +3,110 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
+	"nova-kakhovka-ecity/internal/models"
 	"nova-kakhovka-ecity/pkg/auth"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+/**
+ * AccountStatusProvider - актуальний стан облікового запису (блокування, роль)
+ * Реалізується services.UserStatusCache
+ */
+type AccountStatusProvider interface {
+	AccountStatus(ctx context.Context, userID primitive.ObjectID) (models.AccountStatus, error)
+}
+
+/**
+ * SessionChecker - чи не відкликано сесію (вхід на пристрої), з якої видано токен
+ * Реалізується services.SessionService
+ */
+type SessionChecker interface {
+	IsActive(ctx context.Context, sessionID primitive.ObjectID, ip string) (bool, error)
+}
+
+/**
+ * APIKeyAuthenticator - перевірка ключа інтеграції з заголовка X-API-Key
+ * Реалізується services.APIKeyService. nil без помилки - ключ невідомий, відкликаний або прострочений
+ */
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey, ip string) (*models.APIKey, error)
+}
+
+// APIKeyHeader - заголовок з ключем інтеграції (замість Authorization: Bearer)
+const APIKeyHeader = "X-API-Key"
+
+/**
+ * UserClaims - типізовані дані автентифікованого користувача
+ * user_id розбирається в ObjectID один раз в AuthMiddleware, роль береться з бази
+ */
+type UserClaims struct {
+	UserID      primitive.ObjectID
+	Email       string
+	Role        models.UserRole
+	Permissions []models.Permission
+	SessionID   primitive.ObjectID // Порожній для токенів, виданих до появи сесій
+	APIKeyID    primitive.ObjectID // Запит з X-API-Key: дозволи обмежені правами ключа
+
+	ModerationScope *models.ModerationScope // Зона модератора з users.moderation_scope
+}
+
+// HasPermission - чи має користувач дозвіл
+func (u *UserClaims) HasPermission(permission models.Permission) bool {
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope - зона, якою обмежені дії модератора; nil - без обмежень.
+// Зона діє лише для ролі MODERATOR: адміністратори модерують усе місто.
+func (u *UserClaims) Scope() *models.ModerationScope {
+	if u.Role != models.RoleModerator || u.ModerationScope.IsEmpty() {
+		return nil
+	}
+	return u.ModerationScope
+}
+
+const userClaimsKey = "user_claims"
+
+/**
+ * CurrentUser - дані користувача, встановлені AuthMiddleware або OptionalAuth
+ */
+func CurrentUser(c *gin.Context) (*UserClaims, bool) {
+	value, exists := c.Get(userClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*UserClaims)
+	return claims, ok
+}
+
 /**
  * AuthMiddleware - базова автентифікація через JWT
- * Перевіряє наявність та валідність токена
- * Додає в context: user_id, user_email, user_role, is_moderator
+ * Перевіряє наявність та валідність токена, а також що користувач існує і не заблокований,
+ * а сесію токена не відкликано (токен перестає працювати одразу, а не після закінчення терміну)
+ * Додає в context: user_claims (*UserClaims), user_id (string), user_email, user_role, is_moderator
+ *
+ * Без Authorization приймає ключ інтеграції в X-API-Key (див. authenticateAPIKey)
  */
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func AuthMiddleware(jwtManager *auth.JWTManager, accounts AccountStatusProvider, sessions SessionChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Отримуємо Authorization header
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && apiKeys != nil && c.GetHeader(APIKeyHeader) != "" {
+			if authenticateAPIKey(c, apiKeys, accounts) {
+				c.Next()
+			}
+			return
+		}
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authorization header is required",
@@ -50,34 +137,199 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Додаємо інформацію про користувача в контекст
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		userID, err := primitive.ObjectIDFromHex(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or expired token",
+			})
+			c.Abort()
+			return
+		}
+
+		sessionID, _ := primitive.ObjectIDFromHex(claims.SessionID)
+
+		status, err := accounts.AccountStatus(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Unable to verify account status",
+				"details": err.Error(),
+			})
+			c.Abort()
+			return
+		}
 
-		// Визначаємо is_moderator на основі ролі
-		// Модераторами вважаються: MODERATOR, ADMIN, SUPER_ADMIN
-		isModerator := claims.Role == "MODERATOR" ||
-			claims.Role == "ADMIN" ||
-			claims.Role == "SUPER_ADMIN"
+		if !status.Exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Account no longer exists",
+			})
+			c.Abort()
+			return
+		}
 
-		// Також перевіряємо legacy поле (для сумісності)
-		if claims.IsModerator {
-			isModerator = true
+		if status.IsBlocked {
+			c.JSON(http.StatusForbidden, blockedResponse(status))
+			c.Abort()
+			return
 		}
 
-		c.Set("is_moderator", isModerator)
+		// Після блокування або зміни ролі видані раніше токени відкликаються
+		if !status.AcceptsTokenVersion(claims.TokenVersion) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Token has been revoked, please log in again",
+				"code":  "TOKEN_REVOKED",
+			})
+			c.Abort()
+			return
+		}
+
+		active, err := sessionActive(c, sessions, sessionID)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":   "Unable to verify session",
+				"details": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Session has been revoked, please log in again",
+				"code":  "SESSION_REVOKED",
+			})
+			c.Abort()
+			return
+		}
+
+		// Роль і зона модератора з бази: зміни діють без перевипуску токена
+		setUserClaims(c, &UserClaims{
+			UserID:      userID,
+			Email:       claims.Email,
+			Role:        status.Role,
+			Permissions: models.GetRolePermissions(status.Role),
+			SessionID:   sessionID,
+
+			ModerationScope: status.ModerationScope,
+		})
 
 		c.Next()
 	}
 }
 
+/**
+ * authenticateAPIKey - автентифікація ключем інтеграції
+ * Ключ діє від імені власника з його поточною роллю, але лише з дозволами ключа, і тільки
+ * на маршрутах, для яких матриця доступу (RouteRules) вимагає один із цих дозволів.
+ * Маршрути без дозволу в матриці (профіль, сесії, самі ключі) ключем недоступні.
+ * При відмові відповідає і перериває запит (false).
+ */
+func authenticateAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator, accounts AccountStatusProvider) bool {
+	key, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), c.GetHeader(APIKeyHeader), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Unable to verify API key",
+			"details": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+	if key == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid, expired or revoked API key",
+		})
+		c.Abort()
+		return false
+	}
+
+	status, err := accounts.AccountStatus(c.Request.Context(), key.UserID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Unable to verify account status",
+			"details": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+	if !status.Exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Account no longer exists",
+		})
+		c.Abort()
+		return false
+	}
+	if status.IsBlocked {
+		c.JSON(http.StatusForbidden, blockedResponse(status))
+		c.Abort()
+		return false
+	}
+
+	user := &UserClaims{
+		UserID:      key.UserID,
+		Role:        status.Role,
+		Permissions: key.EffectivePermissions(status.Role),
+		APIKeyID:    key.ID,
+
+		ModerationScope: status.ModerationScope,
+	}
+
+	rule, ok := RouteRule(c)
+	if !ok || rule.Permission == "" || !user.HasPermission(rule.Permission) {
+		response := gin.H{
+			"error": "API key is not allowed to access this endpoint",
+		}
+		if rule.Permission != "" {
+			response["required"] = rule.Permission
+		}
+		c.JSON(http.StatusForbidden, response)
+		c.Abort()
+		return false
+	}
+
+	setUserClaims(c, user)
+	return true
+}
+
+/**
+ * blockedResponse - відповідь 403 для заблокованого акаунта;
+ * для тимчасового призупинення містить час його закінчення
+ */
+func blockedResponse(status models.AccountStatus) gin.H {
+	response := gin.H{
+		"error":      "Account is blocked",
+		"is_blocked": true,
+	}
+	if status.BlockedUntil != nil {
+		response["error"] = "Account is suspended"
+		response["is_suspended"] = true
+		response["blocked_until"] = status.BlockedUntil
+	}
+	return response
+}
+
+// sessionActive - чи не відкликано сесію токена; токени без сесії приймаються
+func sessionActive(c *gin.Context, sessions SessionChecker, sessionID primitive.ObjectID) (bool, error) {
+	if sessions == nil || sessionID.IsZero() {
+		return true, nil
+	}
+	return sessions.IsActive(c.Request.Context(), sessionID, c.ClientIP())
+}
+
+// setUserClaims додає дані користувача в context, включно з рядковими ключами для існуючих обробників
+func setUserClaims(c *gin.Context, user *UserClaims) {
+	c.Set(userClaimsKey, user)
+	c.Set("user_id", user.UserID.Hex())
+	c.Set("user_email", user.Email)
+	c.Set("user_role", string(user.Role))
+
+	// Модераторами вважаються: MODERATOR, ADMIN, SUPER_ADMIN
+	c.Set("is_moderator", user.Role.IsHigherOrEqual(models.RoleModerator))
+}
+
 /**
  * ModeratorMiddleware - перевіряє чи користувач є модератором
  * Використовується після AuthMiddleware
  *
  * Приклад використання:
- * protected.Use(middleware.AuthMiddleware(jwtManager))
+ * protected.Use(middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService))
  * protected.Use(middleware.ModeratorMiddleware())
  * protected.PUT("/petitions/:id/status", handler.UpdateStatus)
  */
@@ -113,7 +365,7 @@ func ModeratorMiddleware() gin.HandlerFunc {
  *
  * Приклад використання:
  * admin := api.Group("")
- * admin.Use(middleware.AuthMiddleware(jwtManager))
+ * admin.Use(middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService))
  * admin.Use(middleware.RequireRole("ADMIN", "SUPER_ADMIN"))
  */
 func RequireRole(allowedRoles ...string) gin.HandlerFunc {
@@ -165,17 +417,26 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
  * OptionalAuth - опціональна автентифікація
  * Якщо токен присутній - валідує його та додає user_id в context
  * Якщо токена немає - дозволяє продовжити без автентифікації
+ * Заблоковані та видалені користувачі, відкликані сесії обробляються як анонімні
  *
  * Використовується для публічних endpoints, які можуть працювати
  * по-різному для автентифікованих та неавтентифікованих користувачів
  *
  * Приклад: GET /petitions - показує draft тільки автору
+ *
+ * Ключ інтеграції (X-API-Key) враховується тільки з дозволом, якого вимагає маршрут у матриці
  */
-func OptionalAuth(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func OptionalAuth(jwtManager *auth.JWTManager, accounts AccountStatusProvider, sessions SessionChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Отримуємо Authorization header
 		authHeader := c.GetHeader("Authorization")
 
+		if authHeader == "" && apiKeys != nil && c.GetHeader(APIKeyHeader) != "" {
+			optionalAPIKey(c, apiKeys, accounts)
+			c.Next()
+			return
+		}
+
 		// Якщо немає токена - просто продовжуємо
 		if authHeader == "" {
 			c.Next()
@@ -200,22 +461,62 @@ func OptionalAuth(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Токен валідний - додаємо інформацію в context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		userID, err := primitive.ObjectIDFromHex(claims.UserID)
+		if err != nil {
+			c.Next()
+			return
+		}
 
-		isModerator := claims.Role == "MODERATOR" ||
-			claims.Role == "ADMIN" ||
-			claims.Role == "SUPER_ADMIN" ||
-			claims.IsModerator
+		sessionID, _ := primitive.ObjectIDFromHex(claims.SessionID)
+		status, err := accounts.AccountStatus(c.Request.Context(), userID)
+		if err != nil || !status.Exists || status.IsBlocked || !status.AcceptsTokenVersion(claims.TokenVersion) {
+			c.Next()
+			return
+		}
+		if active, err := sessionActive(c, sessions, sessionID); err != nil || !active {
+			c.Next()
+			return
+		}
+
+		// Токен валідний - додаємо інформацію в context
+		setUserClaims(c, &UserClaims{
+			UserID:      userID,
+			Email:       claims.Email,
+			Role:        status.Role,
+			Permissions: models.GetRolePermissions(status.Role),
+			SessionID:   sessionID,
 
-		c.Set("is_moderator", isModerator)
+			ModerationScope: status.ModerationScope,
+		})
 
 		c.Next()
 	}
 }
 
+// optionalAPIKey - дані власника ключа в context для OptionalAuth; недійсний ключ - анонімний запит
+func optionalAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator, accounts AccountStatusProvider) {
+	key, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), c.GetHeader(APIKeyHeader), c.ClientIP())
+	if err != nil || key == nil {
+		return
+	}
+	status, err := accounts.AccountStatus(c.Request.Context(), key.UserID)
+	if err != nil || !status.Exists || status.IsBlocked {
+		return
+	}
+
+	user := &UserClaims{
+		UserID:      key.UserID,
+		Role:        status.Role,
+		Permissions: key.EffectivePermissions(status.Role),
+		APIKeyID:    key.ID,
+
+		ModerationScope: status.ModerationScope,
+	}
+	if rule, ok := RouteRule(c); ok && rule.Permission != "" && user.HasPermission(rule.Permission) {
+		setUserClaims(c, user)
+	}
+}
+
 /**
  * RateLimitByUser - обмеження швидкості запитів на основі user_id
  * Використовується після AuthMiddleware
@@ -234,10 +535,10 @@ func RateLimitByUser(requestsPerMinute int) gin.HandlerFunc {
  * AdminOnly - швидкий хелпер для admin-only endpoints
  * Комбінація Auth + RequireRole("ADMIN", "SUPER_ADMIN")
  */
-func AdminOnly(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func AdminOnly(jwtManager *auth.JWTManager, accounts AccountStatusProvider, sessions SessionChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Викликаємо AuthMiddleware
-		authMiddleware := AuthMiddleware(jwtManager)
+		authMiddleware := AuthMiddleware(jwtManager, accounts, sessions, apiKeys)
 		authMiddleware(c)
 
 		// Якщо автентифікація не пройшла - зупиняємо
@@ -255,10 +556,10 @@ func AdminOnly(jwtManager *auth.JWTManager) gin.HandlerFunc {
  * ModeratorOrAdmin - швидкий хелпер для moderator/admin endpoints
  * Комбінація Auth + RequireRole("MODERATOR", "ADMIN", "SUPER_ADMIN")
  */
-func ModeratorOrAdmin(jwtManager *auth.JWTManager) gin.HandlerFunc {
+func ModeratorOrAdmin(jwtManager *auth.JWTManager, accounts AccountStatusProvider, sessions SessionChecker, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Викликаємо AuthMiddleware
-		authMiddleware := AuthMiddleware(jwtManager)
+		authMiddleware := AuthMiddleware(jwtManager, accounts, sessions, apiKeys)
 		authMiddleware(c)
 
 		// Якщо автентифікація не пройшла - зупиняємо
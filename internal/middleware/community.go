@@ -0,0 +1,46 @@
+// internal/middleware/community.go
+
+package middleware
+
+import (
+	"net/http"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * CommunityResolver - джерело громад для multi-tenancy
+ * Реалізується services.CommunityService
+ */
+type CommunityResolver interface {
+	Resolve(host, code string) (*models.Community, error)
+}
+
+/**
+ * CommunityMiddleware - визначає громаду (tenant) для кожного запиту
+ * Порядок: заголовок X-Community -> Host запиту -> громада за замовчуванням
+ * Додає в context: community_id, community_code, community_is_default, community_modules
+ */
+func CommunityMiddleware(resolver CommunityResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		community, err := resolver.Resolve(c.Request.Host, c.GetHeader("X-Community"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Community not found",
+				"code":    "COMMUNITY_NOT_FOUND",
+				"details": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("community_id", community.ID)
+		c.Set("community_code", community.Code)
+		c.Set("community_is_default", community.IsDefault)
+		c.Set("community_modules", community.Settings.GetEnabledModules())
+
+		c.Next()
+	}
+}
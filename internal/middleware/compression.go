@@ -0,0 +1,283 @@
+// internal/middleware/compression.go
+
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * CompressionConfig - налаштування стиснення відповідей
+ * MinSize - менші відповіді не стискаються (накладні витрати більші за виграш)
+ * ExcludedPrefixes/ExcludedSuffixes - маршрути без стиснення (WebSocket, long-poll, потокові вивантаження)
+ */
+type CompressionConfig struct {
+	MinSize          int
+	Level            int
+	ExcludedPrefixes []string
+	ExcludedSuffixes []string
+}
+
+/**
+ * Типи вмісту, які має сенс стискати
+ * Зображення, архіви та інше вже стиснене не чіпаємо
+ */
+var compressibleTypes = []string{
+	"application/json",
+	"application/atom+xml",
+	"application/xml",
+	"application/javascript",
+	"text/",
+}
+
+/**
+ * compressionEncoder - алгоритм стиснення для Content-Encoding
+ * Порядок у списку - пріоритет при однаковій вазі в Accept-Encoding
+ */
+type compressionEncoder struct {
+	name string
+	pool *sync.Pool
+}
+
+/**
+ * CompressionMiddleware - gzip-стиснення відповідей
+ * Рішення приймається після перших MinSize байт або в кінці запиту:
+ * стискаються лише відповіді стискуваних типів без власного Content-Encoding.
+ * WebSocket upgrade та HEAD-запити пропускаються без змін.
+ */
+func CompressionMiddleware(config CompressionConfig) gin.HandlerFunc {
+	if config.MinSize <= 0 {
+		config.MinSize = 1024
+	}
+	if config.Level == gzip.NoCompression || config.Level < gzip.HuffmanOnly || config.Level > gzip.BestCompression {
+		config.Level = gzip.DefaultCompression
+	}
+
+	encoders := []compressionEncoder{
+		{
+			name: "gzip",
+			pool: &sync.Pool{New: func() interface{} {
+				writer, _ := gzip.NewWriterLevel(io.Discard, config.Level)
+				return writer
+			}},
+		},
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead ||
+			strings.EqualFold(c.GetHeader("Upgrade"), "websocket") ||
+			isCompressionExcluded(c.Request.URL.Path, config) {
+			c.Next()
+			return
+		}
+
+		encoder := negotiateEncoding(c.GetHeader("Accept-Encoding"), encoders)
+		if encoder == nil {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoder:        encoder,
+			minSize:        config.MinSize,
+		}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+func isCompressionExcluded(path string, config CompressionConfig) bool {
+	for _, prefix := range config.ExcludedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range config.ExcludedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * negotiateEncoding - вибір алгоритму за Accept-Encoding з урахуванням q-ваги
+ * Повертає nil, якщо клієнт не підтримує жоден з алгоритмів
+ */
+func negotiateEncoding(header string, encoders []compressionEncoder) *compressionEncoder {
+	if header == "" {
+		return nil
+	}
+
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		weights[name] = weight
+	}
+
+	var best *compressionEncoder
+	bestWeight := 0.0
+	for i := range encoders {
+		weight, ok := weights[encoders[i].name]
+		if !ok {
+			weight, ok = weights["*"]
+		}
+		if ok && weight > bestWeight {
+			best = &encoders[i]
+			bestWeight = weight
+		}
+	}
+	return best
+}
+
+/**
+ * compressWriter - ResponseWriter, що буферизує початок відповіді
+ * і вмикає стиснення, коли стає відомо тип і розмір вмісту
+ */
+type compressWriter struct {
+	gin.ResponseWriter
+	encoder *compressionEncoder
+	minSize int
+
+	buffer   []byte
+	decided  bool
+	compress bool
+	gz       *gzip.Writer
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.compress {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+/**
+ * Flush - потокові відповіді (CSV) стискаються без порогу розміру
+ */
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.compress {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+/**
+ * decide - вмикає стиснення та скидає буфер
+ * bigEnough=false означає, що відповідь завершилась, не досягнувши MinSize
+ */
+func (w *compressWriter) decide(bigEnough bool) error {
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	status := w.ResponseWriter.Status()
+	w.compress = bigEnough &&
+		!w.ResponseWriter.Written() &&
+		status != http.StatusNoContent &&
+		status != http.StatusNotModified &&
+		status >= http.StatusOK &&
+		header.Get("Content-Encoding") == "" &&
+		isCompressibleType(header.Get("Content-Type"))
+
+	if isCompressibleType(header.Get("Content-Type")) {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	if w.compress {
+		header.Set("Content-Encoding", w.encoder.name)
+		header.Del("Content-Length")
+		w.gz = w.encoder.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	buffered := w.buffer
+	w.buffer = nil
+	var err error
+	if w.compress {
+		_, err = w.gz.Write(buffered)
+	} else {
+		_, err = w.ResponseWriter.Write(buffered)
+	}
+	return err
+}
+
+/**
+ * finish - викликається після обробника: скидає малі відповіді без стиснення
+ * та закриває gzip-потік
+ */
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buffer) == 0 {
+			return
+		}
+		w.decide(false)
+	}
+	if w.compress {
+		w.gz.Close()
+		w.gz.Reset(io.Discard)
+		w.encoder.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+func isCompressibleType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
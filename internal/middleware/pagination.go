@@ -0,0 +1,51 @@
+// internal/middleware/pagination.go
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * PaginationFormatHeader - вибір формату списків клієнтом:
+ * "page_info" - лише конверт {items, page_info}, "legacy" - конверт разом із попередніми ключами
+ * (events, pagination, total_pages тощо). Без заголовка діє налаштування сервера.
+ */
+const PaginationFormatHeader = "X-Pagination-Format"
+
+const (
+	PaginationFormatPageInfo = "page_info"
+	PaginationFormatLegacy   = "legacy"
+)
+
+const paginationLegacyKey = "pagination_legacy"
+
+/**
+ * PaginationFormat - визначає, чи додавати у відповіді списків застарілі ключі пагінації
+ * legacyByDefault=true зберігає сумісність для клієнтів, які ще не перейшли на page_info
+ */
+func PaginationFormat(legacyByDefault bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		legacy := legacyByDefault
+		switch c.GetHeader(PaginationFormatHeader) {
+		case PaginationFormatPageInfo:
+			legacy = false
+		case PaginationFormatLegacy:
+			legacy = true
+		}
+		c.Set(paginationLegacyKey, legacy)
+		c.Next()
+	}
+}
+
+/**
+ * LegacyPagination - чи потрібні поточному запиту застарілі ключі пагінації
+ * Без PaginationFormat у ланцюжку - так, як і до появи page_info
+ */
+func LegacyPagination(c *gin.Context) bool {
+	legacy, ok := c.Get(paginationLegacyKey)
+	if !ok {
+		return true
+	}
+	return legacy.(bool)
+}
@@ -19,14 +19,14 @@ import (
  *
  * Приклад:
  * router.POST("/announcements",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequirePermission(string(models.PermissionCreateAnnouncement)),
  *     handler.CreateAnnouncement)
  */
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// ✅ ВИПРАВЛЕНО: Отримуємо роль з "user_role" (встановлюється AuthMiddleware)
-		roleInterface, exists := c.Get("user_role")
+		// Роль беремо з claims (встановлюються AuthMiddleware), а не з окремих ключів context
+		user, exists := CurrentUser(c)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "User not authenticated",
@@ -35,20 +35,8 @@ func RequirePermission(permission string) gin.HandlerFunc {
 			return
 		}
 
-		roleStr, ok := roleInterface.(string)
-		if !ok || roleStr == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid user role",
-			})
-			c.Abort()
-			return
-		}
-
-		// Конвертуємо string в UserRole
-		userRole := models.UserRole(roleStr)
-
 		// Перевіряємо чи роль валідна
-		if !userRole.IsValid() {
+		if !user.Role.IsValid() {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Invalid role",
 			})
@@ -56,15 +44,13 @@ func RequirePermission(permission string) gin.HandlerFunc {
 			return
 		}
 
-		// Конвертуємо permission string в Permission enum
-		requiredPermission := models.Permission(permission)
-
 		// Перевіряємо чи користувач має необхідне дозволення
-		if !userRole.HasPermission(requiredPermission) {
+		// (дозволи з claims: для API-ключа - лише права ключа)
+		if !user.HasPermission(models.Permission(permission)) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error":     "Insufficient permissions",
 				"required":  permission,
-				"user_role": roleStr,
+				"user_role": string(user.Role),
 			})
 			c.Abort()
 			return
@@ -83,7 +69,7 @@ func RequirePermission(permission string) gin.HandlerFunc {
  *
  * Приклад:
  * router.GET("/analytics",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequireMinimumRole(string(models.RoleModerator)),
  *     handler.GetAnalytics)
  *
@@ -150,7 +136,7 @@ func RequireMinimumRole(minRole string) gin.HandlerFunc {
  *
  * Приклад:
  * router.POST("/reports",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequireAnyRole(
  *         string(models.RoleModerator),
  *         string(models.RoleAdmin),
@@ -209,7 +195,7 @@ func RequireAnyRole(roles ...string) gin.HandlerFunc {
  *
  * Приклад:
  * router.PUT("/content/:id",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequireAnyPermission(
  *         string(models.PermissionEditOwnAnnouncement),
  *         string(models.PermissionModerateAnnouncement),
@@ -268,7 +254,7 @@ func RequireAnyPermission(permissions ...string) gin.HandlerFunc {
  *
  * Приклад:
  * router.DELETE("/users/:id",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequireAllPermissions(
  *         string(models.PermissionManageUsers),
  *         string(models.PermissionBlockUser),
@@ -330,7 +316,7 @@ func RequireAllPermissions(permissions ...string) gin.HandlerFunc {
  *
  * Приклад:
  * router.PUT("/announcements/:id",
- *     middleware.AuthMiddleware(jwtManager),
+ *     middleware.AuthMiddleware(jwtManager, userStatusCache, sessionService, apiKeyService),
  *     middleware.RequireOwnerOrPermission(
  *         "author_id", // поле в базі даних
  *         string(models.PermissionModerateAnnouncement),
@@ -399,3 +385,35 @@ func RequireOwnerOrPermission(ownerField string, permission string) gin.HandlerF
  *     // Продовжуємо оновлення...
  * }
  */
+
+/**
+ * UserHasPermission - чи має поточний користувач дозвіл (за claims з AuthMiddleware/OptionalAuth)
+ * Для перевірок усередині handlers, де доступ залежить і від ролі, і від ресурсу
+ * (наприклад, "автор або модератор"). Без автентифікації повертає false.
+ */
+func UserHasPermission(c *gin.Context, permission models.Permission) bool {
+	user, exists := CurrentUser(c)
+	return exists && user.HasPermission(permission)
+}
+
+// Код відмови для дій поза зоною модератора
+const OutOfModerationScopeCode = "OUT_OF_MODERATION_SCOPE"
+
+/**
+ * RequireCityWideModerator - дія стосується всього міста (блокування користувачів, теги,
+ * FAQ, утримані повідомлення чату), тому недоступна модераторам із зоною мікрорайонів
+ * чи категорій. Ставиться після RequireMinimumRole.
+ */
+func RequireCityWideModerator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, ok := CurrentUser(c); ok && user.Scope() != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Action is not available to moderators with a limited scope",
+				"code":  OutOfModerationScopeCode,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -32,8 +33,8 @@ var (
 // - Безпечна робота з concurrent requests
 func RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Отримуємо ID користувача з контексту
-		userID, exists := c.Get("user_id")
+		// Отримуємо користувача з контексту (встановлюється AuthMiddleware)
+		user, exists := CurrentUser(c)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Unauthorized",
@@ -42,17 +43,7 @@ func RateLimitMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-
-		// Перевіряємо тип user_id
-		userIDObj, ok := userID.(primitive.ObjectID)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal error",
-				"details": "Invalid user ID format",
-			})
-			c.Abort()
-			return
-		}
+		userIDObj := user.UserID
 
 		// Блокуємо для безпечного доступу до map
 		pollRateLimiter.mu.Lock()
@@ -157,7 +148,7 @@ func NewGeneralRateLimiter(limit int, window time.Duration) *GeneralRateLimiter
 func (rl *GeneralRateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Отримуємо ID користувача
-		userID, exists := c.Get("user_id")
+		user, exists := CurrentUser(c)
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized",
@@ -165,15 +156,7 @@ func (rl *GeneralRateLimiter) Middleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-
-		userIDObj, ok := userID.(primitive.ObjectID)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Invalid user ID",
-			})
-			c.Abort()
-			return
-		}
+		userIDObj := user.UserID
 
 		rl.mu.Lock()
 		defer rl.mu.Unlock()
@@ -235,3 +218,89 @@ func (rl *GeneralRateLimiter) startCleanup() {
 		rl.mu.Unlock()
 	}
 }
+
+// ========================================
+// RATE LIMITER ЗА IP-АДРЕСОЮ
+// ========================================
+
+// IPRateLimiter обмежує запити з однієї IP-адреси для публічних endpoints без автентифікації.
+// Адреса береться з c.ClientIP(): X-Forwarded-For враховується лише від проксі з
+// router.SetTrustedProxies (TRUSTED_PROXIES), інакше клієнт обходив би ліміт підробленим заголовком
+type IPRateLimiter struct {
+	limit    int
+	window   time.Duration
+	requests map[string][]time.Time
+	mu       sync.Mutex
+}
+
+// NewIPRateLimiter створює rate limiter за IP-адресою
+func NewIPRateLimiter(limit int, window time.Duration) *IPRateLimiter {
+	limiter := &IPRateLimiter{
+		limit:    limit,
+		window:   window,
+		requests: make(map[string][]time.Time),
+	}
+
+	go limiter.startCleanup()
+
+	return limiter
+}
+
+// Middleware повертає Gin middleware; ліміт 0 вимикає перевірку
+func (rl *IPRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl.limit <= 0 {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		now := time.Now()
+		cutoff := now.Add(-rl.window)
+
+		rl.mu.Lock()
+		timestamps := rl.requests[ip]
+		valid := timestamps[:0]
+		for _, ts := range timestamps {
+			if ts.After(cutoff) {
+				valid = append(valid, ts)
+			}
+		}
+
+		if len(valid) >= rl.limit {
+			retryAfter := rl.window - now.Sub(valid[0])
+			rl.requests[ip] = valid
+			rl.mu.Unlock()
+
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":               "Rate limit exceeded",
+				"retry_after_seconds": int(retryAfter.Seconds()) + 1,
+			})
+			c.Abort()
+			return
+		}
+
+		rl.requests[ip] = append(valid, now)
+		rl.mu.Unlock()
+
+		c.Next()
+	}
+}
+
+// startCleanup видаляє адреси без запитів у межах вікна
+func (rl *IPRateLimiter) startCleanup() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rl.mu.Lock()
+		cutoff := time.Now().Add(-rl.window)
+		for ip, timestamps := range rl.requests {
+			if len(timestamps) == 0 || timestamps[len(timestamps)-1].Before(cutoff) {
+				delete(rl.requests, ip)
+			}
+		}
+		rl.mu.Unlock()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// embedTestRouter - router з лімітом 2 запити на IP, як /embed у main.go
+func embedTestRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("set trusted proxies: %v", err)
+	}
+	limiter := NewIPRateLimiter(2, time.Minute)
+	router.GET("/embed", limiter.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func embedRequest(router *gin.Engine, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/embed", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// Без довірених проксі підроблений X-Forwarded-For не дає нового ліміту
+func TestIPRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	router := embedTestRouter(t, nil)
+
+	for i := 0; i < 3; i++ {
+		got := embedRequest(router, "203.0.113.7:40000", "198.51.100."+strconv.Itoa(i))
+		want := http.StatusOK
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if got != want {
+			t.Errorf("request %d: got %d, want %d", i+1, got, want)
+		}
+	}
+}
+
+// За довіреним балансувальником ліміт рахується за адресою клієнта з X-Forwarded-For
+func TestIPRateLimiterUsesTrustedProxyClientIP(t *testing.T) {
+	router := embedTestRouter(t, []string{"10.0.0.0/8"})
+
+	for i := 0; i < 3; i++ {
+		if got := embedRequest(router, "10.0.0.1:40000", "198.51.100."+strconv.Itoa(i)); got != http.StatusOK {
+			t.Errorf("client %d: got %d, want %d", i+1, got, http.StatusOK)
+		}
+	}
+	embedRequest(router, "10.0.0.1:40000", "198.51.100.0")
+	if got := embedRequest(router, "10.0.0.1:40000", "198.51.100.0"); got != http.StatusTooManyRequests {
+		t.Errorf("third request from one client: got %d, want %d", got, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,248 @@
+// internal/middleware/versioning.go
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * APIVersionHeader - версія API, яку очікує клієнт (v1), і версія, якою відповів сервер.
+ * Версію також можна вказати в Accept: application/vnd.ecity.v1+json
+ */
+const APIVersionHeader = "X-API-Version"
+
+const (
+	apiVersionMediaPrefix = "application/vnd.ecity."
+	apiVersionMediaSuffix = "+json"
+)
+
+// Стан версії API
+const (
+	APIVersionStable     = "stable"
+	APIVersionDeprecated = "deprecated"
+)
+
+// Типи записів журналу змін
+const (
+	APIChangeAdded      = "added"
+	APIChangeChanged    = "changed"
+	APIChangeDeprecated = "deprecated"
+	APIChangeRemoved    = "removed"
+)
+
+// UnsupportedAPIVersionCode - код відповіді 406 для версії, яку сервер не обслуговує
+const UnsupportedAPIVersionCode = "UNSUPPORTED_API_VERSION"
+
+/**
+ * DeprecatedEndpoint - застарілий маршрут версії
+ * Відповіді маршруту отримують заголовки Deprecation, Sunset і Link на заміну
+ */
+type DeprecatedEndpoint struct {
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`                  // Шаблон маршруту gin: /api/v1/polls/:id/force
+	Replacement  string    `json:"replacement,omitempty"` // Шаблон маршруту заміни з тим самим методом
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	SunsetAt     time.Time `json:"sunset_at"` // Після цієї дати маршрут видаляється
+	Note         string    `json:"note,omitempty"`
+}
+
+/**
+ * FieldChange - застаріле або видалене поле відповіді
+ */
+type FieldChange struct {
+	Resource    string     `json:"resource"` // Відповідь або модель: user, list responses
+	Field       string     `json:"field"`
+	Replacement string     `json:"replacement,omitempty"`
+	Since       time.Time  `json:"since"`
+	SunsetAt    *time.Time `json:"sunset_at,omitempty"`
+	Note        string     `json:"note,omitempty"`
+}
+
+/**
+ * APIChange - запис журналу змін API для клієнтів
+ */
+type APIChange struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"` // added, changed, deprecated, removed
+	Description string    `json:"description"`
+}
+
+/**
+ * APIVersion - опис версії API: базовий шлях, стан, застарілі маршрути й поля
+ */
+type APIVersion struct {
+	Version    string    `json:"version"` // v1
+	BasePath   string    `json:"base_path"`
+	MediaType  string    `json:"media_type"`
+	Status     string    `json:"status"` // stable, deprecated
+	ReleasedAt time.Time `json:"released_at"`
+
+	// Для застарілої версії - коли її оголошено застарілою і коли буде вимкнено
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	SunsetAt     *time.Time `json:"sunset_at,omitempty"`
+
+	DeprecatedEndpoints []DeprecatedEndpoint `json:"deprecated_endpoints"`
+	DeprecatedFields    []FieldChange        `json:"deprecated_fields"`
+	RemovedFields       []FieldChange        `json:"removed_fields"`
+	Changelog           []APIChange          `json:"changelog"`
+}
+
+/**
+ * APIVersions - реєстр версій API, який віддає GET /api/versions
+ */
+type APIVersions struct {
+	Current  string       `json:"current"`
+	Versions []APIVersion `json:"versions"`
+}
+
+// MediaTypeFor - vendor media type версії для Accept
+func MediaTypeFor(version string) string {
+	return apiVersionMediaPrefix + version + apiVersionMediaSuffix
+}
+
+// find - версія за назвою (v1)
+func (v *APIVersions) find(version string) (*APIVersion, bool) {
+	for i := range v.Versions {
+		if v.Versions[i].Version == version {
+			return &v.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+// forPath - версія, до базового шляху якої належить запит
+func (v *APIVersions) forPath(path string) (*APIVersion, bool) {
+	for i := range v.Versions {
+		base := v.Versions[i].BasePath
+		if path == base || strings.HasPrefix(path, base+"/") {
+			return &v.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+// supported - назви версій для відповіді 406
+func (v *APIVersions) supported() []string {
+	versions := make([]string, 0, len(v.Versions))
+	for _, version := range v.Versions {
+		versions = append(versions, version.Version)
+	}
+	return versions
+}
+
+/**
+ * Expired - застарілі маршрути, дата вимкнення яких уже минула.
+ * Їх час видалити з коду; сервер продовжує їх обслуговувати.
+ */
+func (v *APIVersions) Expired(now time.Time) []string {
+	var expired []string
+	for _, version := range v.Versions {
+		for _, endpoint := range version.DeprecatedEndpoints {
+			if now.After(endpoint.SunsetAt) {
+				expired = append(expired, endpoint.Method+" "+endpoint.Path)
+			}
+		}
+	}
+	return expired
+}
+
+// requestedAPIVersion - версія із X-API-Version або Accept; "" - клієнт версію не вказав
+func requestedAPIVersion(c *gin.Context) string {
+	if version := strings.TrimSpace(c.GetHeader(APIVersionHeader)); version != "" {
+		version = strings.ToLower(version)
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		return version
+	}
+
+	for _, mediaType := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+		mediaType = strings.ToLower(mediaType)
+		if strings.HasPrefix(mediaType, apiVersionMediaPrefix) && strings.HasSuffix(mediaType, apiVersionMediaSuffix) {
+			return strings.TrimSuffix(strings.TrimPrefix(mediaType, apiVersionMediaPrefix), apiVersionMediaSuffix)
+		}
+	}
+	return ""
+}
+
+// expandRoute підставляє параметри поточного запиту в шаблон маршруту заміни
+func expandRoute(c *gin.Context, route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = c.Param(segment[1:])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// httpDate - дата у форматі заголовка Sunset (RFC 8594)
+func httpDate(t time.Time) string {
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// deprecationDate - значення заголовка Deprecation (RFC 9745): @<unix-час>
+func deprecationDate(t time.Time) string {
+	return "@" + strconv.FormatInt(t.Unix(), 10)
+}
+
+/**
+ * APIVersioning - узгодження версії API та заголовки застарілих маршрутів.
+ * Версію визначає базовий шлях (/api/v1); X-API-Version або Accept лише підтверджують її:
+ * невідома версія або версія, що обслуговується за іншим шляхом, - 406 UNSUPPORTED_API_VERSION.
+ * Відповідь містить X-API-Version; застарілі маршрути та версії - Deprecation, Sunset і Link.
+ */
+func APIVersioning(versions *APIVersions) gin.HandlerFunc {
+	deprecated := make(map[string]DeprecatedEndpoint)
+	for _, version := range versions.Versions {
+		for _, endpoint := range version.DeprecatedEndpoints {
+			deprecated[endpoint.Method+" "+endpoint.Path] = endpoint
+		}
+	}
+
+	return func(c *gin.Context) {
+		version, ok := versions.forPath(c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if requested := requestedAPIVersion(c); requested != "" && requested != version.Version {
+			response := gin.H{
+				"error":     "Unsupported API version",
+				"code":      UnsupportedAPIVersionCode,
+				"requested": requested,
+				"supported": versions.supported(),
+			}
+			if other, exists := versions.find(requested); exists {
+				response["details"] = "Version " + requested + " is served under " + other.BasePath
+			}
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, response)
+			return
+		}
+
+		c.Header(APIVersionHeader, version.Version)
+
+		if endpoint, exists := deprecated[c.Request.Method+" "+c.FullPath()]; exists {
+			c.Header("Deprecation", deprecationDate(endpoint.DeprecatedAt))
+			c.Header("Sunset", httpDate(endpoint.SunsetAt))
+			if endpoint.Replacement != "" {
+				c.Writer.Header().Add("Link", "<"+expandRoute(c, endpoint.Replacement)+`>; rel="successor-version"`)
+			}
+			c.Writer.Header().Add("Link", `</api/versions>; rel="deprecation"`)
+		} else if version.Status == APIVersionDeprecated && version.DeprecatedAt != nil && version.SunsetAt != nil {
+			c.Header("Deprecation", deprecationDate(*version.DeprecatedAt))
+			c.Header("Sunset", httpDate(*version.SunsetAt))
+			c.Writer.Header().Add("Link", `</api/versions>; rel="deprecation"`)
+		}
+
+		c.Next()
+	}
+}
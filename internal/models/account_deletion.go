@@ -0,0 +1,39 @@
+// internal/models/account_deletion.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси запиту на видалення акаунта
+const (
+	AccountDeletionPending    = "pending"
+	AccountDeletionProcessing = "processing"
+	AccountDeletionCompleted  = "completed"
+	AccountDeletionFailed     = "failed"
+)
+
+// Ім'я, яким замінюються персональні дані видаленого користувача
+const (
+	AnonymizedFirstName = "Видалений"
+	AnonymizedLastName  = "користувач"
+	AnonymizedUserName  = AnonymizedFirstName + " " + AnonymizedLastName
+)
+
+// AccountDeletion - запит на видалення акаунта (колекція account_deletions).
+// Акаунт блокується одразу, персональні дані знеособлюються фоновою задачею.
+// Квитанцію (ID + код) отримує користувач; зберігається лише хеш коду.
+type AccountDeletion struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"-"`
+	ReceiptHash string             `bson:"receipt_hash" json:"-"`
+	Status      string             `bson:"status" json:"status"`
+	RequestedAt time.Time          `bson:"requested_at" json:"requested_at"`
+	StartedAt   *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Attempts    int                `bson:"attempts" json:"-"`
+	LastError   string             `bson:"last_error,omitempty" json:"-"`
+	Results     map[string]int64   `bson:"results,omitempty" json:"results,omitempty"` // Знеособлено записів за видами даних
+}
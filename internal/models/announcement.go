@@ -2,23 +2,34 @@
 package models
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Announcement struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	AuthorID primitive.ObjectID `bson:"author_id" json:"author_id" validate:"required"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Slug        string             `bson:"slug,omitempty" json:"slug,omitempty"`                 // Адрес для сайта: заголовок латиницей + суффикс ID
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	AuthorID    primitive.ObjectID `bson:"author_id" json:"author_id" validate:"required"`
 
 	Title       string `bson:"title" json:"title" validate:"required,min=5,max=200"`
 	Description string `bson:"description" json:"description" validate:"required,min=10,max=2000"`
-	Category    string `bson:"category" json:"category" validate:"required,oneof=work help services housing transport"`
+	Language    string `bson:"language" json:"language,omitempty"`           // uk, ru или en - определяется при создании (DetectLanguage)
+	Category    string `bson:"category" json:"category" validate:"required"` // Код із таксономії (taxonomies)
 
 	// Местоположение и тип работы
-	Location   Location `bson:"location" json:"location"`
-	Address    string   `bson:"address" json:"address"`
-	Employment string   `bson:"employment" json:"employment" validate:"oneof=once permanent partial"`
+	Location     Location `bson:"location" json:"location"`
+	Address      string   `bson:"address" json:"address"`
+	Neighborhood string   `bson:"neighborhood,omitempty" json:"neighborhood,omitempty"` // Мікрорайон - зона модератора (ModerationScope)
+	Employment   string   `bson:"employment" json:"employment" validate:"oneof=once permanent partial"`
+
+	// Структурированные поля категории (зарплата, комнаты, график...), схема - AnnouncementAttributeSchemas
+	Attributes map[string]interface{} `bson:"attributes,omitempty" json:"attributes,omitempty"`
 
 	// Контакты и медиа
 	ContactInfo []ContactInfo `bson:"contact_info" json:"contact_info"`
@@ -44,6 +55,19 @@ type Announcement struct {
 	ApprovedAt      *time.Time `bson:"approved_at,omitempty" json:"approved_at,omitempty"`
 	RejectedAt      *time.Time `bson:"rejected_at,omitempty" json:"rejected_at,omitempty"`
 	RejectionReason string     `bson:"rejection_reason,omitempty" json:"rejection_reason,omitempty"`
+
+	// История редактирования после публикации (версии в content_revisions)
+	EditedAt      *time.Time `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	RevisionCount int        `bson:"revision_count,omitempty" json:"revision_count,omitempty"`
+
+	// Пост публичного канала, из которого импортировано объявление
+	Source *ContentSource `bson:"source,omitempty" json:"source,omitempty"`
+
+	// Текст для чтения с экрана и подсказки произношения (plain_text_body, pronunciations)
+	SpeechHints `bson:",inline"`
+
+	// Похожие материалы (related_content) - заполняется только в карточке объявления
+	Related []RelatedItem `bson:"-" json:"related,omitempty"`
 }
 
 type ContactInfo struct {
@@ -61,6 +85,145 @@ const (
 	AnnouncementCategoryTransport = "transport" // Транспорт
 )
 
+// Типы структурированных полей объявления
+const (
+	AttributeTypeNumber = "number"
+	AttributeTypeString = "string"
+	AttributeTypeEnum   = "enum"
+)
+
+// AttributeSchema - описание структурированного поля категории объявлений
+type AttributeSchema struct {
+	Key      string   `json:"key"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Integer  bool     `json:"integer,omitempty"` // Только для number
+	Min      *float64 `json:"min,omitempty"`     // Для number - значение, для string - длина
+	Max      *float64 `json:"max,omitempty"`     // Для number - значение, для string - длина
+	Options  []string `json:"options,omitempty"` // Для enum
+	Unit     string   `json:"unit,omitempty"`    // Единица измерения для UI
+}
+
+func attributeLimit(value float64) *float64 {
+	return &value
+}
+
+// AnnouncementAttributeSchemas - структурированные поля по категориям.
+// Категории без схемы (help, transport и категории из таксономии) полей не принимают.
+var AnnouncementAttributeSchemas = map[string][]AttributeSchema{
+	AnnouncementCategoryWork: {
+		{Key: "salary_min", Type: AttributeTypeNumber, Min: attributeLimit(0), Unit: "UAH"},
+		{Key: "salary_max", Type: AttributeTypeNumber, Min: attributeLimit(0), Unit: "UAH"},
+		{Key: "salary_period", Type: AttributeTypeEnum, Options: []string{"hour", "day", "month", "task"}},
+		{Key: "experience", Type: AttributeTypeEnum, Options: []string{"none", "1y", "3y", "5y"}},
+	},
+	AnnouncementCategoryHousing: {
+		{Key: "rooms", Type: AttributeTypeNumber, Required: true, Integer: true, Min: attributeLimit(0), Max: attributeLimit(20)},
+		{Key: "area", Type: AttributeTypeNumber, Required: true, Min: attributeLimit(1), Max: attributeLimit(10000), Unit: "m2"},
+		{Key: "price", Type: AttributeTypeNumber, Required: true, Min: attributeLimit(0), Unit: "UAH"},
+		{Key: "price_period", Type: AttributeTypeEnum, Options: []string{"day", "month", "sale"}},
+	},
+	AnnouncementCategoryServices: {
+		{Key: "schedule", Type: AttributeTypeString, Required: true, Min: attributeLimit(3), Max: attributeLimit(200)},
+		{Key: "price_from", Type: AttributeTypeNumber, Min: attributeLimit(0), Unit: "UAH"},
+	},
+}
+
+// AnnouncementAttributeSchema возвращает схему поля категории
+func AnnouncementAttributeSchema(category, key string) (AttributeSchema, bool) {
+	for _, schema := range AnnouncementAttributeSchemas[category] {
+		if schema.Key == key {
+			return schema, true
+		}
+	}
+	return AttributeSchema{}, false
+}
+
+// ValidateAnnouncementAttributes проверяет поля по схеме категории и приводит числа к float64
+func ValidateAnnouncementAttributes(category string, attributes map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(attributes))
+	for key, value := range attributes {
+		schema, ok := AnnouncementAttributeSchema(category, key)
+		if !ok {
+			return nil, fmt.Errorf("attribute %q is not supported for category %q", key, category)
+		}
+		if value == nil {
+			continue
+		}
+		checked, err := schema.check(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = checked
+	}
+
+	for _, schema := range AnnouncementAttributeSchemas[category] {
+		if _, ok := normalized[schema.Key]; schema.Required && !ok {
+			return nil, fmt.Errorf("attribute %q is required for category %q", schema.Key, category)
+		}
+	}
+
+	if category == AnnouncementCategoryWork {
+		if from, ok := normalized["salary_min"].(float64); ok {
+			if to, ok := normalized["salary_max"].(float64); ok && to < from {
+				return nil, fmt.Errorf("attribute \"salary_max\" must not be less than \"salary_min\"")
+			}
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+func (s AttributeSchema) check(value interface{}) (interface{}, error) {
+	switch s.Type {
+	case AttributeTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("attribute %q must be a number", s.Key)
+		}
+		if s.Integer && number != math.Trunc(number) {
+			return nil, fmt.Errorf("attribute %q must be an integer", s.Key)
+		}
+		if s.Min != nil && number < *s.Min {
+			return nil, fmt.Errorf("attribute %q must be at least %v", s.Key, *s.Min)
+		}
+		if s.Max != nil && number > *s.Max {
+			return nil, fmt.Errorf("attribute %q must be at most %v", s.Key, *s.Max)
+		}
+		return number, nil
+
+	case AttributeTypeEnum:
+		option, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("attribute %q must be a string", s.Key)
+		}
+		for _, allowed := range s.Options {
+			if option == allowed {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("attribute %q must be one of: %s", s.Key, strings.Join(s.Options, ", "))
+
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("attribute %q must be a string", s.Key)
+		}
+		text = strings.TrimSpace(text)
+		length := float64(utf8.RuneCountInString(text))
+		if s.Min != nil && length < *s.Min {
+			return nil, fmt.Errorf("attribute %q must be at least %v characters", s.Key, *s.Min)
+		}
+		if s.Max != nil && length > *s.Max {
+			return nil, fmt.Errorf("attribute %q must be at most %v characters", s.Key, *s.Max)
+		}
+		return text, nil
+	}
+}
+
 // Типы занятости
 const (
 	EmploymentOnce      = "once"      // Разовая работа
@@ -0,0 +1,42 @@
+// internal/models/api_key.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey - ключ доступу для інтеграцій партнерів громади (колекція api_keys).
+// Ключ показується один раз при створенні, у базі зберігається лише його SHA-256.
+// Ключ діє від імені власника, але тільки в межах Scopes і поточної ролі власника:
+// зниження ролі звужує доступ ключа без перевипуску.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"` // Початок ключа, щоб впізнати його у списку
+	KeyHash    string             `bson:"key_hash" json:"-"`
+	Scopes     []Permission       `bson:"scopes" json:"scopes"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	LastUsedIP string             `bson:"last_used_ip,omitempty" json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// IsActive - ключ не відкликано і строк дії не минув
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// EffectivePermissions - дозволи ключа, які має поточна роль власника
+func (k *APIKey) EffectivePermissions(role UserRole) []Permission {
+	permissions := []Permission{}
+	for _, scope := range k.Scopes {
+		if role.HasPermission(scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,44 @@
+// internal/models/audit_log.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLogEntry - запис журналу аудиту (колекція audit_logs): хто і що змінив.
+// Дії фонових задач записуються без ActorID, з назвою задачі в Actor.
+type AuditLogEntry struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	CommunityID primitive.ObjectID  `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	ActorID     *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	Actor       string              `bson:"actor" json:"actor"` // user або назва фонової задачі
+
+	Action       string              `bson:"action" json:"action"`
+	ResourceType string              `bson:"resource_type" json:"resource_type"` // poll, petition
+	ResourceID   *primitive.ObjectID `bson:"resource_id,omitempty" json:"resource_id,omitempty"`
+
+	Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// Виконавці дій
+const (
+	AuditActorUser         = "user"
+	AuditActorDraftCleanup = "draft_cleanup"
+	AuditActorPollCleanup  = "poll_cleanup"
+)
+
+// Дії журналу аудиту
+const (
+	AuditActionDraftDeleted  = "draft.deleted"  // Черновик видалено після попередження автора
+	AuditActionDraftExtended = "draft.extended" // Автор продовжив строк зберігання черновика
+	AuditActionPollsPurged   = "polls.purged"   // Видалено опитування, що завершилися понад 90 днів тому
+)
+
+// Ресурси журналу аудиту
+const (
+	AuditResourcePoll     = "poll"
+	AuditResourcePetition = "petition"
+)
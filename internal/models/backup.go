@@ -0,0 +1,50 @@
+// internal/models/backup.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси резервної копії
+const (
+	BackupRunning   = "running"
+	BackupCompleted = "completed"
+	BackupFailed    = "failed"
+	BackupRotated   = "rotated" // Файли видалено ротацією, лишився запис в історії
+)
+
+// Хто запустив резервну копію
+const (
+	BackupTriggerScheduled = "scheduled"
+	BackupTriggerManual    = "manual"
+)
+
+// Backup - резервна копія критичних колекцій у файловому сховищі (колекція backups).
+// Кожна колекція зберігається окремим файлом gzip з документами у форматі Extended JSON
+// по одному на рядок (сумісно з mongoimport), поруч лежить manifest.json з контрольними сумами.
+type Backup struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"id"`
+	Trigger     string              `bson:"trigger" json:"trigger"`
+	Slot        string              `bson:"slot,omitempty" json:"slot,omitempty"` // День планової копії (2006-01-02): лише один екземпляр сервера робить її
+	TriggeredBy *primitive.ObjectID `bson:"triggered_by,omitempty" json:"triggered_by,omitempty"`
+	Status      string              `bson:"status" json:"status"`
+	Database    string              `bson:"database" json:"database"`
+	StartedAt   time.Time           `bson:"started_at" json:"started_at"`
+	CompletedAt *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Error       string              `bson:"error,omitempty" json:"error,omitempty"`
+	ManifestKey string              `bson:"manifest_key,omitempty" json:"manifest_key,omitempty"`
+	Collections []BackupCollection  `bson:"collections" json:"collections"`
+	Documents   int64               `bson:"documents" json:"documents"`
+	SizeBytes   int64               `bson:"size_bytes" json:"size_bytes"` // Розмір стиснутих файлів
+}
+
+// BackupCollection - файл однієї колекції в резервній копії
+type BackupCollection struct {
+	Name      string `bson:"name" json:"name"`
+	Key       string `bson:"key" json:"key"`
+	Documents int64  `bson:"documents" json:"documents"`
+	SizeBytes int64  `bson:"size_bytes" json:"size_bytes"`
+	SHA256    string `bson:"sha256" json:"sha256"` // Контрольна сума стиснутого файлу
+}
@@ -0,0 +1,117 @@
+// internal/models/banner.go
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Рівні важливості банера (визначають колір і порядок показу)
+const (
+	BannerSeverityInfo     = "info"
+	BannerSeverityPromo    = "promo"
+	BannerSeverityWarning  = "warning"
+	BannerSeverityCritical = "critical"
+)
+
+// Кому показується банер
+const (
+	BannerAudienceAll           = "all"
+	BannerAudienceGuests        = "guests"        // Лише без входу в акаунт
+	BannerAudienceAuthenticated = "authenticated" // Лише користувачам з акаунтом
+)
+
+// Місця показу банера в застосунках
+const (
+	BannerPlacementGlobal     = "global"     // Над усіма екранами (технічні роботи, аварії)
+	BannerPlacementHome       = "home"       // Головний екран
+	BannerPlacementOnboarding = "onboarding" // Екрани першого запуску
+)
+
+// Платформи клієнтів
+const (
+	BannerPlatformWeb     = "web"
+	BannerPlatformAndroid = "android"
+	BannerPlatformIOS     = "ios"
+)
+
+// bannerSeverityRank - критичні банери показуються першими
+var bannerSeverityRank = map[string]int{
+	BannerSeverityCritical: 3,
+	BannerSeverityWarning:  2,
+	BannerSeverityInfo:     1,
+	BannerSeverityPromo:    0,
+}
+
+// Banner - банер застосунків і вебсайту (колекція banners): повідомлення про технічні роботи,
+// кампанії, онбординг. Адміністратори змінюють банери без випуску нових версій застосунків.
+type Banner struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+
+	Title     string `bson:"title,omitempty" json:"title,omitempty"`
+	Message   string `bson:"message" json:"message"`
+	Severity  string `bson:"severity" json:"severity"` // info, promo, warning, critical
+	LinkURL   string `bson:"link_url,omitempty" json:"link_url,omitempty"`
+	LinkLabel string `bson:"link_label,omitempty" json:"link_label,omitempty"`
+	Placement string `bson:"placement" json:"placement"` // global, home, onboarding
+
+	// Аудиторія: порожні списки не обмежують показ
+	Audience  string   `bson:"audience" json:"audience"` // all, guests, authenticated
+	Platforms []string `bson:"platforms,omitempty" json:"platforms,omitempty"`
+	Roles     []string `bson:"roles,omitempty" json:"roles,omitempty"` // Лише для authenticated
+
+	Dismissible bool `bson:"dismissible" json:"dismissible"` // Користувач може закрити банер
+	Priority    int  `bson:"priority" json:"priority"`       // Більше - вище серед банерів однієї важливості
+
+	// Період показу: без StartsAt - одразу, без EndsAt - до вимкнення
+	StartsAt *time.Time `bson:"starts_at,omitempty" json:"starts_at,omitempty"`
+	EndsAt   *time.Time `bson:"ends_at,omitempty" json:"ends_at,omitempty"`
+	IsActive bool       `bson:"is_active" json:"is_active"`
+
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	UpdatedBy primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsLive - банер увімкнений і момент now входить у період показу
+func (b *Banner) IsLive(now time.Time) bool {
+	if !b.IsActive {
+		return false
+	}
+	if b.StartsAt != nil && now.Before(*b.StartsAt) {
+		return false
+	}
+	return b.EndsAt == nil || now.Before(*b.EndsAt)
+}
+
+// VisibleTo перевіряє аудиторію банера; role порожня для гостя, platform порожня - будь-яка
+func (b *Banner) VisibleTo(role, platform string) bool {
+	authenticated := role != ""
+	switch b.Audience {
+	case BannerAudienceGuests:
+		if authenticated {
+			return false
+		}
+	case BannerAudienceAuthenticated:
+		if !authenticated {
+			return false
+		}
+	}
+
+	if len(b.Roles) > 0 && !slices.Contains(b.Roles, role) {
+		return false
+	}
+	if platform != "" && len(b.Platforms) > 0 && !slices.Contains(b.Platforms, platform) {
+		return false
+	}
+	return true
+}
+
+// SeverityRank - порядок важливості для сортування (більше - важливіше)
+func (b *Banner) SeverityRank() int {
+	return bannerSeverityRank[b.Severity]
+}
@@ -0,0 +1,82 @@
+// internal/models/calendar.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Формат дати в календарі
+const CalendarDateLayout = "2006-01-02"
+
+// Типи днів календаря
+const (
+	CalendarDayPublicHoliday = "public_holiday" // Державне свято
+	CalendarDayHolidayOff    = "holiday_off"    // Вихідний замість свята, що припало на вихідні
+	CalendarDayLocalHoliday  = "local_holiday"  // Місцеве свято (День міста тощо), оголошене громадою
+	CalendarDayClosed        = "closed_day"     // Установи громади не приймають відвідувачів
+	CalendarDayWorkingDay    = "working_day"    // Робочий день замість вихідного (перенесення)
+)
+
+// Типи розкладу транспорту
+const (
+	ScheduleDayWeekday  = "weekday"
+	ScheduleDaySaturday = "saturday"
+	ScheduleDaySunday   = "sunday"
+	ScheduleDayHoliday  = "holiday"
+)
+
+// CalendarDay - особливий день календаря. Державні свята обчислюються сервісом,
+// дні, оголошені громадою, зберігаються в колекції calendar_days і мають пріоритет.
+type CalendarDay struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID  `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	Date        string              `bson:"date" json:"date"` // YYYY-MM-DD
+	Kind        string              `bson:"kind" json:"kind"`
+	Name        string              `bson:"name" json:"name"`
+	DayOff      bool                `bson:"day_off" json:"day_off"`                               // Неробочий день для установ громади
+	ScheduleDay string              `bson:"schedule_day,omitempty" json:"schedule_day,omitempty"` // Розклад транспорту; порожньо - за типом дня
+	Local       bool                `bson:"-" json:"local"`                                       // Оголошено громадою
+	CreatedBy   *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at,omitempty" json:"created_at,omitempty"`
+}
+
+// IsValidCalendarDayKind - тип дня, який може оголосити громада
+func IsValidCalendarDayKind(kind string) bool {
+	switch kind {
+	case CalendarDayLocalHoliday, CalendarDayClosed, CalendarDayWorkingDay, CalendarDayHolidayOff:
+		return true
+	}
+	return false
+}
+
+// IsValidScheduleDay - тип розкладу транспорту
+func IsValidScheduleDay(day string) bool {
+	switch day {
+	case ScheduleDayWeekday, ScheduleDaySaturday, ScheduleDaySunday, ScheduleDayHoliday:
+		return true
+	}
+	return false
+}
+
+// ScheduleDayForWeekday - звичайний розклад транспорту для дня тижня
+func ScheduleDayForWeekday(weekday time.Weekday) string {
+	switch weekday {
+	case time.Saturday:
+		return ScheduleDaySaturday
+	case time.Sunday:
+		return ScheduleDaySunday
+	default:
+		return ScheduleDayWeekday
+	}
+}
+
+// CalendarDayInfo - підсумок для конкретної дати: чи працюють установи і за яким розкладом транспорт
+type CalendarDayInfo struct {
+	Date        string        `json:"date"`
+	Weekday     string        `json:"weekday"`
+	DayOff      bool          `json:"day_off"`
+	ScheduleDay string        `json:"schedule_day"`
+	Days        []CalendarDay `json:"days"` // Особливі дні на цю дату (свято, оголошення громади)
+}
@@ -0,0 +1,88 @@
+// internal/models/campaign.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PushCampaign - push-кампанія адміністратора з A/B тестуванням двох варіантів
+type PushCampaign struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	Name        string             `bson:"name" json:"name"`
+
+	// Варіанти повідомлення (A та B)
+	Variants []CampaignVariant `bson:"variants" json:"variants"`
+
+	// Частка аудиторії (%), яка отримує тестові варіанти. Решта отримає переможця.
+	TestPercentage int `bson:"test_percentage" json:"test_percentage"`
+	// Вікно оцінки відкриттів після старту тесту (години)
+	EvaluationWindowHours int `bson:"evaluation_window_hours" json:"evaluation_window_hours"`
+
+	Status       string     `bson:"status" json:"status"` // draft, testing, completed, cancelled
+	AudienceSize int        `bson:"audience_size" json:"audience_size"`
+	WinnerKey    string     `bson:"winner_key,omitempty" json:"winner_key,omitempty"`
+	StartedAt    *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	EvaluateAt   *time.Time `bson:"evaluate_at,omitempty" json:"evaluate_at,omitempty"`
+	CompletedAt  *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`
+	RolloutCount int        `bson:"rollout_count" json:"rollout_count"`
+	LastRunError string     `bson:"last_run_error,omitempty" json:"last_run_error,omitempty"`
+}
+
+// CampaignVariant - один варіант тексту push-повідомлення
+type CampaignVariant struct {
+	Key       string `bson:"key" json:"key"` // A, B
+	Title     string `bson:"title" json:"title"`
+	Body      string `bson:"body" json:"body"`
+	SentCount int    `bson:"sent_count" json:"sent_count"`
+	OpenCount int    `bson:"open_count" json:"open_count"`
+}
+
+// CampaignRecipient - призначення користувача до варіанту кампанії
+type CampaignRecipient struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CampaignID primitive.ObjectID `bson:"campaign_id" json:"campaign_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Variant    string             `bson:"variant" json:"variant"` // A, B або rollout
+	IsTest     bool               `bson:"is_test" json:"is_test"`
+	SentAt     *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	OpenedAt   *time.Time         `bson:"opened_at,omitempty" json:"opened_at,omitempty"`
+}
+
+// Статуси кампаній
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusTesting   = "testing"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// Ключі варіантів
+const (
+	CampaignVariantA       = "A"
+	CampaignVariantB       = "B"
+	CampaignVariantRollout = "rollout"
+)
+
+// GetVariant повертає варіант за ключем
+func (c *PushCampaign) GetVariant(key string) *CampaignVariant {
+	for i := range c.Variants {
+		if c.Variants[i].Key == key {
+			return &c.Variants[i]
+		}
+	}
+	return nil
+}
+
+// OpenRate повертає частку відкриттів варіанту
+func (v *CampaignVariant) OpenRate() float64 {
+	if v.SentCount == 0 {
+		return 0
+	}
+	return float64(v.OpenCount) / float64(v.SentCount)
+}
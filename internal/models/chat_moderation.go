@@ -0,0 +1,91 @@
+// internal/models/chat_moderation.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MessageReview - причини, з яких повідомлення групи чекає на перевірку модератором.
+// Позначене повідомлення залишається видимим учасникам; приховане фільтром має is_held.
+type MessageReview struct {
+	Reasons      []string  `bson:"reasons" json:"reasons"`                                 // reported, banned_word
+	MatchedWords []string  `bson:"matched_words,omitempty" json:"matched_words,omitempty"` // Заборонені слова з тексту
+	Reports      int       `bson:"reports,omitempty" json:"reports"`                       // Скарги після останньої перевірки
+	FlaggedAt    time.Time `bson:"flagged_at" json:"flagged_at"`
+}
+
+// Причини перевірки повідомлення
+const (
+	MessageReviewReported   = "reported"
+	MessageReviewBannedWord = "banned_word"
+)
+
+// MessageReport - скарга учасника групи на повідомлення (колекція message_reports).
+// Один учасник скаржиться на повідомлення один раз.
+type MessageReport struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	MessageID  primitive.ObjectID `bson:"message_id" json:"message_id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id"`
+	AuthorID   primitive.ObjectID `bson:"author_id" json:"author_id"` // Автор повідомлення
+	ReporterID primitive.ObjectID `bson:"reporter_id" json:"reporter_id"`
+	Reason     string             `bson:"reason" json:"reason"`
+	Comment    string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+
+	// Рішення модератора за повідомленням: kept або removed
+	Resolution string     `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	ResolvedAt *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// Причини скарги на повідомлення
+const (
+	MessageReportSpam           = "spam"
+	MessageReportAbuse          = "abuse"
+	MessageReportHateSpeech     = "hate_speech"
+	MessageReportMisinformation = "misinformation"
+	MessageReportOther          = "other"
+)
+
+// Рішення модератора щодо повідомлення зі скаргами
+const (
+	MessageReviewKeep   = "keep"
+	MessageReviewRemove = "remove"
+)
+
+// Результати перевірки, що зберігаються в скаргах
+const (
+	MessageReportKept    = "kept"
+	MessageReportRemoved = "removed"
+)
+
+// MessageUnderReview - повідомлення в черзі модератора з причинами перевірки та скаргами
+type MessageUnderReview struct {
+	Message
+	Review        *MessageReview  `json:"review,omitempty"`
+	ReportReasons map[string]int  `json:"report_reasons,omitempty"`
+	Reports       []MessageReport `json:"reports,omitempty"` // Останні скарги
+}
+
+// Скільки останніх скарг показується в черзі для кожного повідомлення
+const MaxReviewReportsShown = 10
+
+// BannedWord - правило фільтра заборонених слів чату (колекція banned_words).
+// Слово із зірочкою в кінці ("спам*") збігається з усіма словами, що з нього починаються.
+type BannedWord struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Word      string              `bson:"word" json:"word"`     // У нижньому регістрі
+	Action    string              `bson:"action" json:"action"` // flag або hide
+	CreatedBy *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// Дії фільтра заборонених слів
+const (
+	BannedWordFlag = "flag" // Повідомлення публікується та потрапляє до черги модератора
+	BannedWordHide = "hide" // Повідомлення утримується до перевірки модератором
+)
+
+// Максимальна довжина забороненого слова
+const MaxBannedWordLength = 50
@@ -8,18 +8,21 @@ import (
 )
 
 type CityIssue struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	ReporterID primitive.ObjectID `bson:"reporter_id" json:"reporter_id" validate:"required"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	ReporterID  primitive.ObjectID `bson:"reporter_id" json:"reporter_id" validate:"required"`
 
 	// Основная информация
 	Title       string `bson:"title" json:"title" validate:"required,min=5,max=200"`
 	Description string `bson:"description" json:"description" validate:"required,min=10,max=1000"`
-	Category    string `bson:"category" json:"category" validate:"required,oneof=road lighting water electricity waste transport building safety other"`
+	Language    string `bson:"language" json:"language,omitempty"`           // uk, ru или en - определяется при создании (DetectLanguage)
+	Category    string `bson:"category" json:"category" validate:"required"` // Код из таксономии (taxonomies)
 	Priority    string `bson:"priority" json:"priority" validate:"oneof=low medium high critical"`
 
 	// Местоположение
-	Location Location `bson:"location" json:"location" validate:"required"`
-	Address  string   `bson:"address" json:"address" validate:"required"`
+	Location     Location `bson:"location" json:"location" validate:"required"`
+	Address      string   `bson:"address" json:"address" validate:"required"`
+	Neighborhood string   `bson:"neighborhood,omitempty" json:"neighborhood,omitempty"` // Микрорайон - зона модератора (ModerationScope)
 
 	// Медиафайлы
 	Photos []string `bson:"photos" json:"photos"`
@@ -42,6 +45,8 @@ type CityIssue struct {
 	UpVoteCount int                  `bson:"upvote_count" json:"upvote_count"` //
 	Comments    []IssueComment       `bson:"comments" json:"comments"`
 	Subscribers []primitive.ObjectID `bson:"subscribers" json:"subscribers"` // Пользователи, следящие за проблемой
+	// Режим уведомлений подписчика (ключ - hex ID пользователя); нет записи - IssueNotifyAll
+	NotificationModes map[string]string `bson:"notification_modes,omitempty" json:"-"`
 
 	// Метаданные
 	IsVerified  bool                `bson:"is_verified" json:"is_verified"`
@@ -52,6 +57,13 @@ type CityIssue struct {
 	ResolvedAt  *time.Time          `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
 	DuplicateOf *primitive.ObjectID `bson:"duplicate_of,omitempty" json:"duplicate_of,omitempty"`
 	AssignedAt  *time.Time          `bson:"assigned_at,omitempty" json:"assigned_at,omitempty"`
+
+	// Маршруты транспорта рядом с критической проблемой и черновик объявления об объезде
+	AffectedRouteIDs []primitive.ObjectID `bson:"affected_route_ids,omitempty" json:"affected_route_ids,omitempty"`
+	TransportAlertID *primitive.ObjectID  `bson:"transport_alert_id,omitempty" json:"transport_alert_id,omitempty"`
+
+	// Похожие материалы (related_content) - заполняется только в карточке проблемы
+	Related []RelatedItem `bson:"-" json:"related,omitempty"`
 }
 type IssueStatusChange struct {
 	Status    string             `bson:"status" json:"status"`
@@ -69,6 +81,64 @@ type IssueComment struct {
 	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// Режимы уведомлений подписчика проблемы
+const (
+	IssueNotifyAll      = "all"      // Каждый комментарий и смена статуса
+	IssueNotifyOfficial = "official" // Только официальные ответы и смена статуса
+	IssueNotifyDigest   = "digest"   // Срочное - сразу, остальное - раз в сутки
+)
+
+// Виды обновлений проблемы для подписчиков
+const (
+	IssueUpdateComment = "comment"
+	IssueUpdateStatus  = "status"
+)
+
+// IssueUpdate - обновление проблемы, о котором уведомляются подписчики
+type IssueUpdate struct {
+	Kind     string
+	Official bool   // Официальный комментарий или действие модератора
+	Status   string // Новый статус (для IssueUpdateStatus)
+	Preview  string
+}
+
+// IsUrgent - срочное обновление не откладывается в дайджест:
+// официальный ответ или закрытие проблемы (решена, отклонена, дубликат)
+func (u IssueUpdate) IsUrgent() bool {
+	if u.Kind == IssueUpdateStatus {
+		return u.Status == IssueStatusResolved || u.Status == IssueStatusRejected || u.Status == IssueStatusDuplicate
+	}
+	return u.Official
+}
+
+// Delivery - как доставить обновление подписчику с режимом mode: сразу, в дайджест или не доставлять
+func (u IssueUpdate) Delivery(mode string) (immediate, digest bool) {
+	switch mode {
+	case IssueNotifyOfficial:
+		return u.Official, false
+	case IssueNotifyDigest:
+		if u.IsUrgent() {
+			return true, false
+		}
+		return false, true
+	default:
+		return true, false
+	}
+}
+
+// IssueDigestItem - отложенное обновление для ежедневного дайджеста (коллекция issue_digest_items)
+type IssueDigestItem struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	IssueID    primitive.ObjectID  `bson:"issue_id" json:"issue_id"`
+	IssueTitle string              `bson:"issue_title" json:"issue_title"`
+	Kind       string              `bson:"kind" json:"kind"`
+	Status     string              `bson:"status,omitempty" json:"status,omitempty"`
+	Preview    string              `bson:"preview,omitempty" json:"preview,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	BatchID    *primitive.ObjectID `bson:"batch_id,omitempty" json:"-"` // Рассылка, которая забрала запись
+}
+
 // Категории проблем
 const (
 	IssueCategoryRoad        = "road"        // Дороги
@@ -162,6 +232,14 @@ func (i *CityIssue) HasUserSubscribed(userID primitive.ObjectID) bool {
 	return false
 }
 
+// NotificationMode - режим уведомлений подписчика
+func (i *CityIssue) NotificationMode(userID primitive.ObjectID) string {
+	if mode, ok := i.NotificationModes[userID.Hex()]; ok {
+		return mode
+	}
+	return IssueNotifyAll
+}
+
 func (i *CityIssue) GetUpvoteCount() int {
 	return len(i.UpVotes)
 }
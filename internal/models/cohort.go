@@ -0,0 +1,65 @@
+// internal/models/cohort.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Метрики утримання для когортного аналізу
+const (
+	RetentionMetricActivity = "activity" // Активність у конкретному тижні (запити автентифікованого користувача)
+	RetentionMetricLogin    = "login"    // Останній вхід не раніше тижня (за last_login_at)
+)
+
+// UserActivityWeek - тиждень, у якому користувач був активним (колекція user_activity).
+// Один документ на користувача і тиждень - цього достатньо для тижневих когорт.
+type UserActivityWeek struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	WeekStart  time.Time          `bson:"week_start" json:"week_start"`   // Понеділок 00:00 UTC
+	ActiveDays []int              `bson:"active_days" json:"active_days"` // Дні тижня (0 - понеділок)
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+}
+
+// WeekStart повертає початок тижня (понеділок 00:00 UTC) для моменту t
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7 // Понеділок - 0
+	return day.AddDate(0, 0, -offset)
+}
+
+// SegmentFilters - умови відбору користувачів у сегмент. Порожні поля не обмежують вибірку.
+type SegmentFilters struct {
+	Roles          []string            `bson:"roles,omitempty" json:"roles,omitempty"`
+	IsVerified     *bool               `bson:"is_verified,omitempty" json:"is_verified,omitempty"`
+	Interests      []string            `bson:"interests,omitempty" json:"interests,omitempty"` // Хоча б один з інтересів
+	HasGroups      *bool               `bson:"has_groups,omitempty" json:"has_groups,omitempty"`
+	HasConcession  *bool               `bson:"has_concession,omitempty" json:"has_concession,omitempty"` // Підтверджена пільга на проїзд
+	RegisteredFrom *time.Time          `bson:"registered_from,omitempty" json:"registered_from,omitempty"`
+	RegisteredTo   *time.Time          `bson:"registered_to,omitempty" json:"registered_to,omitempty"`
+	CommunityID    *primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+}
+
+// UserSegment - збережене адміністратором визначення сегмента (колекція user_segments)
+type UserSegment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Filters     SegmentFilters     `bson:"filters" json:"filters"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CohortRow - тижнева когорта реєстрацій та її утримання
+type CohortRow struct {
+	WeekStart time.Time `json:"week_start"`
+	Size      int       `json:"size"`
+	// Retained[k] - кількість користувачів когорти, утриманих на k-му тижні після реєстрації (k=0 - тиждень реєстрації)
+	Retained []int `json:"retained"`
+	// RetentionRates[k] = Retained[k] / Size
+	RetentionRates []float64 `json:"retention_rates"`
+}
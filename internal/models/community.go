@@ -0,0 +1,120 @@
+// internal/models/community.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Community представляє громаду (tenant), яка використовує платформу
+type Community struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Code string             `bson:"code" json:"code" validate:"required,min=2,max=50"` // Унікальний код громади (slug)
+	Name string             `bson:"name" json:"name" validate:"required,min=2,max=200"`
+
+	// Хости, за якими визначається громада (напр. ecity.gov.ua, kherson.ecity.gov.ua)
+	Hosts []string `bson:"hosts" json:"hosts"`
+
+	// Брендинг та налаштування для white-label фронтендів
+	Settings CommunitySettings `bson:"settings" json:"settings"`
+
+	IsActive  bool      `bson:"is_active" json:"is_active"`
+	IsDefault bool      `bson:"is_default" json:"is_default"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CommunitySettings - брендинг громади, що повертається через GET /public/settings
+type CommunitySettings struct {
+	DisplayName    string              `bson:"display_name" json:"display_name"`
+	LogoURL        string              `bson:"logo_url" json:"logo_url"`
+	FaviconURL     string              `bson:"favicon_url,omitempty" json:"favicon_url,omitempty"`
+	PrimaryColor   string              `bson:"primary_color" json:"primary_color"`     // #RRGGBB
+	SecondaryColor string              `bson:"secondary_color" json:"secondary_color"` // #RRGGBB
+	Contact        CommunityContact    `bson:"contact" json:"contact"`
+	EnabledModules []string            `bson:"enabled_modules" json:"enabled_modules"`
+	UpdatedBy      *primitive.ObjectID `bson:"updated_by,omitempty" json:"-"`
+	UpdatedAt      *time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// CommunityContact - контактна інформація громади
+type CommunityContact struct {
+	Email   string `bson:"email,omitempty" json:"email,omitempty"`
+	Phone   string `bson:"phone,omitempty" json:"phone,omitempty"`
+	Address string `bson:"address,omitempty" json:"address,omitempty"`
+	Website string `bson:"website,omitempty" json:"website,omitempty"`
+}
+
+// Модулі платформи, які можна показувати у фронтенді
+const (
+	ModuleGroups        = "groups"
+	ModuleAnnouncements = "announcements"
+	ModuleEvents        = "events"
+	ModulePetitions     = "petitions"
+	ModulePolls         = "polls"
+	ModuleCityIssues    = "city_issues"
+	ModuleTransport     = "transport"
+	ModuleNotifications = "notifications"
+	ModuleFAQ           = "faq"
+	ModuleEducation     = "education"
+	ModuleConsultations = "consultations"
+)
+
+// AllModules повертає список усіх модулів платформи
+func AllModules() []string {
+	return []string{
+		ModuleGroups,
+		ModuleAnnouncements,
+		ModuleEvents,
+		ModulePetitions,
+		ModulePolls,
+		ModuleCityIssues,
+		ModuleTransport,
+		ModuleNotifications,
+		ModuleFAQ,
+		ModuleEducation,
+		ModuleConsultations,
+	}
+}
+
+// IsValidModule перевіряє, чи існує модуль з таким кодом
+func IsValidModule(module string) bool {
+	for _, m := range AllModules() {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEnabledModules повертає увімкнені модулі (якщо список не задано - всі модулі)
+func (s *CommunitySettings) GetEnabledModules() []string {
+	if s.EnabledModules == nil {
+		return AllModules()
+	}
+	return s.EnabledModules
+}
+
+// DefaultCommunityCode - код громади за замовчуванням (існуючі дані без community_id належать їй)
+const DefaultCommunityCode = "nova-kakhovka"
+
+// HasHost перевіряє, чи обслуговує громада вказаний хост
+func (c *Community) HasHost(host string) bool {
+	for _, h := range c.Hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMemberOfCommunity перевіряє, чи приєднався користувач до громади
+func (u *User) IsMemberOfCommunity(communityID primitive.ObjectID) bool {
+	for _, id := range u.CommunityIDs {
+		if id == communityID {
+			return true
+		}
+	}
+	return false
+}
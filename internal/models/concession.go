@@ -0,0 +1,148 @@
+// internal/models/concession.go
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Пільгові категорії проїзду
+const (
+	ConcessionStudent   = "student"   // Студенти та учні
+	ConcessionPensioner = "pensioner" // Пенсіонери
+	ConcessionIDP       = "idp"       // Внутрішньо переміщені особи
+)
+
+// Статуси підтвердження пільги
+const (
+	ConcessionStatusPending  = "pending"
+	ConcessionStatusVerified = "verified"
+	ConcessionStatusRejected = "rejected"
+)
+
+// ConcessionCategories повертає всі пільгові категорії
+func ConcessionCategories() []string {
+	return []string{ConcessionStudent, ConcessionPensioner, ConcessionIDP}
+}
+
+// IsValidConcessionCategory перевіряє код пільгової категорії
+func IsValidConcessionCategory(category string) bool {
+	for _, c := range ConcessionCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConcessionTranslation повертає назву категорії українською
+func GetConcessionTranslation(category string) string {
+	translations := map[string]string{
+		ConcessionStudent:   "Студент / учень",
+		ConcessionPensioner: "Пенсіонер",
+		ConcessionIDP:       "ВПО",
+	}
+	if translation, ok := translations[category]; ok {
+		return translation
+	}
+	return category
+}
+
+// FareRule - тариф маршруту для пільгової категорії
+type FareRule struct {
+	Category        string   `bson:"category" json:"category"`                         // student, pensioner, idp
+	DiscountPercent float64  `bson:"discount_percent" json:"discount_percent"`         // 100 - безкоштовно
+	FixedFare       *float64 `bson:"fixed_fare,omitempty" json:"fixed_fare,omitempty"` // Фіксована ціна замість знижки
+}
+
+// FareConcession - пільга користувача, підтверджена документом через е-сервіси
+type FareConcession struct {
+	Category       string `bson:"category" json:"category"`
+	Status         string `bson:"status" json:"status"` // pending, verified, rejected
+	DocumentType   string `bson:"document_type" json:"document_type"`
+	DocumentNumber string `bson:"document_number" json:"document_number"`
+	// Посилання на завантажений скан документа (бачать лише модератори)
+	DocumentURL string `bson:"document_url,omitempty" json:"-"`
+
+	SubmittedAt     time.Time           `bson:"submitted_at" json:"submitted_at"`
+	VerifiedAt      *time.Time          `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	VerifiedBy      *primitive.ObjectID `bson:"verified_by,omitempty" json:"-"`
+	ExpiresAt       *time.Time          `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	RejectionReason string              `bson:"rejection_reason,omitempty" json:"rejection_reason,omitempty"`
+}
+
+// IsActive перевіряє, що пільга підтверджена і не прострочена
+func (fc *FareConcession) IsActive(now time.Time) bool {
+	if fc == nil || fc.Status != ConcessionStatusVerified {
+		return false
+	}
+	return fc.ExpiresAt == nil || now.Before(*fc.ExpiresAt)
+}
+
+// FareQuote - розрахована вартість проїзду для пасажира
+type FareQuote struct {
+	BaseFare        float64 `json:"base_fare"`
+	Fare            float64 `json:"fare"`
+	Concession      string  `json:"concession,omitempty"`
+	DiscountPercent float64 `json:"discount_percent,omitempty"`
+}
+
+// CalculateFare обчислює вартість проїзду з урахуванням пільги.
+// Якщо для категорії немає правила на маршруті, діє повний тариф.
+func (r *TransportRoute) CalculateFare(concession string) FareQuote {
+	quote := FareQuote{BaseFare: r.Fare, Fare: r.Fare}
+	if concession == "" {
+		return quote
+	}
+
+	for _, rule := range r.FareRules {
+		if rule.Category != concession {
+			continue
+		}
+
+		fare := r.Fare * (1 - rule.DiscountPercent/100)
+		if rule.FixedFare != nil {
+			fare = *rule.FixedFare
+		}
+		if fare < 0 {
+			fare = 0
+		}
+		if fare > r.Fare {
+			fare = r.Fare
+		}
+
+		quote.Fare = math.Round(fare*100) / 100
+		quote.Concession = concession
+		if r.Fare > 0 {
+			quote.DiscountPercent = math.Round((1-quote.Fare/r.Fare)*10000) / 100
+		}
+		break
+	}
+
+	return quote
+}
+
+// ValidateFareRules перевіряє пільгові тарифи маршруту
+func ValidateFareRules(rules []FareRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if !IsValidConcessionCategory(rule.Category) {
+			return fmt.Errorf("unknown concession category: %s", rule.Category)
+		}
+		if seen[rule.Category] {
+			return fmt.Errorf("duplicate fare rule for category: %s", rule.Category)
+		}
+		seen[rule.Category] = true
+
+		if rule.DiscountPercent < 0 || rule.DiscountPercent > 100 {
+			return fmt.Errorf("discount percent must be between 0 and 100")
+		}
+		if rule.FixedFare != nil && *rule.FixedFare < 0 {
+			return fmt.Errorf("fixed fare cannot be negative")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// internal/models/consultation.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси громадського обговорення
+const (
+	ConsultationStatusDraft     = "draft"
+	ConsultationStatusPublished = "published" // Коментування відкрите в межах StartDate-EndDate
+	ConsultationStatusCompleted = "completed" // Підсумковий звіт затверджено
+)
+
+// Рішення щодо коментаря (пропозиції) мешканця
+const (
+	CommentResolutionPending    = "pending"
+	CommentResolutionAccepted   = "accepted"   // Враховано
+	CommentResolutionConsidered = "considered" // Враховано частково / взято до уваги
+	CommentResolutionRejected   = "rejected"   // Відхилено
+)
+
+// IsValidCommentResolution перевіряє рішення модератора щодо коментаря
+func IsValidCommentResolution(resolution string) bool {
+	switch resolution {
+	case CommentResolutionAccepted, CommentResolutionConsidered, CommentResolutionRejected:
+		return true
+	}
+	return false
+}
+
+// ConsultationSection - розділ (стаття, пункт) проєкту документа, до якого залишають коментарі
+type ConsultationSection struct {
+	ID     string `bson:"id" json:"id"`                               // Стабільний ідентифікатор розділу, напр. "1", "2.3"
+	Title  string `bson:"title" json:"title"`                         // Заголовок розділу
+	Text   string `bson:"text" json:"text"`                           // Текст розділу
+	PageNo int    `bson:"page_no,omitempty" json:"page_no,omitempty"` // Сторінка у PDF-версії
+}
+
+// Consultation - громадське обговорення проєкту документа (колекція consultations)
+type Consultation struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description" json:"description"`
+	Department  string `bson:"department,omitempty" json:"department,omitempty"`     // Розробник документа
+	DocumentURL string `bson:"document_url,omitempty" json:"document_url,omitempty"` // PDF-версія проєкту
+
+	Sections []ConsultationSection `bson:"sections" json:"sections"`
+
+	// Вікно коментування
+	StartDate time.Time `bson:"start_date" json:"start_date"`
+	EndDate   time.Time `bson:"end_date" json:"end_date"`
+
+	Status        string `bson:"status" json:"status"` // draft, published, completed
+	CommentsCount int    `bson:"comments_count" json:"comments_count"`
+
+	// Підсумок розробника, додається до звіту
+	Conclusion  string     `bson:"conclusion,omitempty" json:"conclusion,omitempty"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	PublishedAt *time.Time         `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsOpenForComments - обговорення опубліковане і вікно коментування триває
+func (c *Consultation) IsOpenForComments(now time.Time) bool {
+	return c.Status == ConsultationStatusPublished &&
+		!now.Before(c.StartDate) && !now.After(c.EndDate)
+}
+
+// FindSection повертає розділ за ідентифікатором
+func (c *Consultation) FindSection(sectionID string) (*ConsultationSection, bool) {
+	for i := range c.Sections {
+		if c.Sections[i].ID == sectionID {
+			return &c.Sections[i], true
+		}
+	}
+	return nil, false
+}
+
+// ConsultationComment - коментар мешканця до розділу (колекція consultation_comments)
+type ConsultationComment struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	ConsultationID primitive.ObjectID `bson:"consultation_id" json:"consultation_id"`
+	SectionID      string             `bson:"section_id" json:"section_id"`
+	AuthorID       primitive.ObjectID `bson:"author_id" json:"author_id"`
+	AuthorName     string             `bson:"author_name" json:"author_name"`
+	Content        string             `bson:"content" json:"content"`
+
+	// Рішення модератора та обґрунтування, що потрапляє у звіт
+	Resolution     string              `bson:"resolution" json:"resolution"` // pending, accepted, considered, rejected
+	ResolutionNote string              `bson:"resolution_note,omitempty" json:"resolution_note,omitempty"`
+	ResolvedBy     *primitive.ObjectID `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time          `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ConsultationSectionReport - підсумок коментарів до розділу
+type ConsultationSectionReport struct {
+	SectionID   string                `json:"section_id"`
+	Title       string                `json:"title"`
+	Total       int                   `json:"total"`
+	Resolutions map[string]int        `json:"resolutions"` // Кількість коментарів за рішенням
+	Comments    []ConsultationComment `json:"comments"`
+}
+
+// ConsultationReport - підсумковий звіт громадського обговорення
+type ConsultationReport struct {
+	ConsultationID primitive.ObjectID          `json:"consultation_id"`
+	Title          string                      `json:"title"`
+	Status         string                      `json:"status"`
+	StartDate      time.Time                   `json:"start_date"`
+	EndDate        time.Time                   `json:"end_date"`
+	Conclusion     string                      `json:"conclusion,omitempty"`
+	TotalComments  int                         `json:"total_comments"`
+	Participants   int                         `json:"participants"`
+	Resolutions    map[string]int              `json:"resolutions"`
+	Sections       []ConsultationSectionReport `json:"sections"`
+	GeneratedAt    time.Time                   `json:"generated_at"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Довжина тексту останнього повідомлення у списку розмов
+const DirectMessagePreviewLength = 80
+
+// Conversation - особисте листування двох користувачів.
+// Створюється автоматично першим повідомленням; на пару користувачів - одна розмова.
+type Conversation struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Participants   []primitive.ObjectID `bson:"participants" json:"participants"` // Два учасники, відсортовані за ID
+	ParticipantKey string               `bson:"participant_key" json:"-"`         // Унікальний ключ пари (ConversationKey)
+
+	LastMessage   *DirectMessagePreview `bson:"last_message,omitempty" json:"last_message,omitempty"`
+	LastMessageAt *time.Time            `bson:"last_message_at,omitempty" json:"last_message_at,omitempty"`
+
+	// Непрочитані повідомлення: ID учасника (hex) -> кількість
+	UnreadCounts map[string]int `bson:"unread_counts,omitempty" json:"-"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// DirectMessagePreview - останнє повідомлення розмови для списку розмов
+type DirectMessagePreview struct {
+	ID        primitive.ObjectID `bson:"id" json:"id"`
+	SenderID  primitive.ObjectID `bson:"sender_id" json:"sender_id"`
+	Type      string             `bson:"type" json:"type"`
+	Preview   string             `bson:"preview" json:"preview"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// DirectMessage - повідомлення особистої розмови
+type DirectMessage struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	ConversationID primitive.ObjectID `bson:"conversation_id" json:"conversation_id"`
+	SenderID       primitive.ObjectID `bson:"sender_id" json:"sender_id"`
+	RecipientID    primitive.ObjectID `bson:"recipient_id" json:"recipient_id"`
+
+	Content  string `bson:"content" json:"content"`
+	Type     string `bson:"type" json:"type"` // text, image, video, file, link
+	MediaURL string `bson:"media_url,omitempty" json:"media_url,omitempty"`
+
+	IsDeleted bool       `bson:"is_deleted" json:"is_deleted"`
+	ReadAt    *time.Time `bson:"read_at,omitempty" json:"read_at,omitempty"` // Коли отримувач прочитав повідомлення
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// ConversationParticipants - учасники розмови в порядку зростання ID
+func ConversationParticipants(a, b primitive.ObjectID) []primitive.ObjectID {
+	if a.Hex() > b.Hex() {
+		a, b = b, a
+	}
+	return []primitive.ObjectID{a, b}
+}
+
+// ConversationKey - ключ пари користувачів, однаковий незалежно від того, хто написав першим
+func ConversationKey(a, b primitive.ObjectID) string {
+	participants := ConversationParticipants(a, b)
+	return participants[0].Hex() + ":" + participants[1].Hex()
+}
+
+// OtherParticipant - співрозмовник користувача userID
+func (c *Conversation) OtherParticipant(userID primitive.ObjectID) primitive.ObjectID {
+	for _, participant := range c.Participants {
+		if participant != userID {
+			return participant
+		}
+	}
+	return userID
+}
+
+// HasParticipant - користувач є учасником розмови
+func (c *Conversation) HasParticipant(userID primitive.ObjectID) bool {
+	for _, participant := range c.Participants {
+		if participant == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// UnreadFor - кількість непрочитаних повідомлень користувача
+func (c *Conversation) UnreadFor(userID primitive.ObjectID) int {
+	return c.UnreadCounts[userID.Hex()]
+}
+
+// Preview - коротке представлення повідомлення для списку розмов
+func (m *DirectMessage) Preview() DirectMessagePreview {
+	preview := m.Content
+	if m.Type != MessageTypeText && m.Type != MessageTypeLink {
+		preview = (&Message{Type: m.Type}).GetPreview()
+	} else if utf8.RuneCountInString(preview) > DirectMessagePreviewLength {
+		preview = string([]rune(preview)[:DirectMessagePreviewLength-3]) + "..."
+	}
+
+	return DirectMessagePreview{
+		ID:        m.ID,
+		SenderID:  m.SenderID,
+		Type:      m.Type,
+		Preview:   preview,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+// internal/models/counter.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси звірки лічильників
+const (
+	CounterReconciliationRunning   = "running"
+	CounterReconciliationCompleted = "completed"
+	CounterReconciliationFailed    = "failed"
+)
+
+// Хто запустив звірку лічильників
+const (
+	CounterReconciliationScheduled = "scheduled"
+	CounterReconciliationManual    = "manual"
+)
+
+// Скільки прикладів розбіжностей зберігається для кожного лічильника
+const MaxCounterDriftSamples = 10
+
+// CounterReconciliation - прогін звірки денормалізованих лічильників з джерелами
+// (колекція counter_reconciliations). Розбіжності виправляються в тому ж прогоні.
+type CounterReconciliation struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"id"`
+	Trigger     string              `bson:"trigger" json:"trigger"`
+	Slot        string              `bson:"slot,omitempty" json:"slot,omitempty"` // День планової звірки (2006-01-02): лише один екземпляр сервера робить її
+	TriggeredBy *primitive.ObjectID `bson:"triggered_by,omitempty" json:"triggered_by,omitempty"`
+	Status      string              `bson:"status" json:"status"`
+	StartedAt   time.Time           `bson:"started_at" json:"started_at"`
+	CompletedAt *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Error       string              `bson:"error,omitempty" json:"error,omitempty"`
+	Counters    []CounterDrift      `bson:"counters" json:"counters"`
+	Checked     int64               `bson:"checked" json:"checked"`       // Перевірено документів (за всіма лічильниками)
+	Mismatched  int64               `bson:"mismatched" json:"mismatched"` // Документів з розбіжністю
+	Fixed       int64               `bson:"fixed" json:"fixed"`
+}
+
+// CounterDrift - результат звірки одного лічильника
+type CounterDrift struct {
+	Counter    string          `bson:"counter" json:"counter"` // Колекція і поле: petitions.signature_count
+	Source     string          `bson:"source" json:"source"`   // Звідки рахується фактичне значення
+	Checked    int64           `bson:"checked" json:"checked"`
+	Mismatched int64           `bson:"mismatched" json:"mismatched"`
+	Fixed      int64           `bson:"fixed" json:"fixed"`
+	Drift      int64           `bson:"drift" json:"drift"` // Сума |збережене - фактичне|
+	Samples    []CounterSample `bson:"samples,omitempty" json:"samples,omitempty"`
+}
+
+// CounterSample - приклад документа з розбіжністю
+type CounterSample struct {
+	ID     primitive.ObjectID `bson:"id" json:"id"`
+	Stored int64              `bson:"stored" json:"stored"`
+	Actual int64              `bson:"actual" json:"actual"`
+}
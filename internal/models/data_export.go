@@ -0,0 +1,34 @@
+// internal/models/data_export.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси вивантаження даних користувача
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportCompleted  = "completed"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired" // Архів видалено після закінчення строку зберігання
+)
+
+// DataExport - запит користувача на вивантаження власних даних (колекція data_exports).
+// ZIP-архів з JSON-файлами готує фонова задача, після чого користувач отримує сповіщення.
+type DataExport struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"-"`
+	Status      string             `bson:"status" json:"status"`
+	RequestedAt time.Time          `bson:"requested_at" json:"requested_at"`
+	StartedAt   *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"` // Після цього архів видаляється
+	Attempts    int                `bson:"attempts" json:"-"`
+	LastError   string             `bson:"last_error,omitempty" json:"-"`
+	FileKey     string             `bson:"file_key,omitempty" json:"-"`
+	SizeBytes   int64              `bson:"size_bytes,omitempty" json:"size_bytes,omitempty"`
+	Counts      map[string]int64   `bson:"counts,omitempty" json:"counts,omitempty"` // Кількість записів у кожному файлі архіву
+}
@@ -0,0 +1,78 @@
+// internal/models/email.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailMessage - лист у черзі відправки (колекція email_queue)
+type EmailMessage struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID     `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	To          string                 `bson:"to" json:"to"`
+	Template    string                 `bson:"template" json:"template"`
+	Data        map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
+
+	// Відрендерений вміст (заповнюється при постановці в чергу)
+	Subject  string `bson:"subject" json:"subject"`
+	HTMLBody string `bson:"html_body" json:"-"`
+	TextBody string `bson:"text_body" json:"-"`
+
+	// Стан доставки
+	Status        string     `bson:"status" json:"status"` // pending, sending, sent, failed, suppressed
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	MaxAttempts   int        `bson:"max_attempts" json:"max_attempts"`
+	NextAttemptAt time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	Provider      string     `bson:"provider,omitempty" json:"provider,omitempty"` // primary, backup
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+	SentAt        *time.Time `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+}
+
+// EmailSuppression - адреса, на яку не можна надсилати листи (bounce, скарга)
+type EmailSuppression struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Email     string             `bson:"email" json:"email"`
+	Reason    string             `bson:"reason" json:"reason"` // bounce, complaint, manual
+	Details   string             `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// EmailDeliveryLog - запис про кожну спробу доставки (для адміністраторів)
+type EmailDeliveryLog struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	MessageID primitive.ObjectID `bson:"message_id" json:"message_id"`
+	To        string             `bson:"to" json:"to"`
+	Template  string             `bson:"template" json:"template"`
+	Provider  string             `bson:"provider" json:"provider"`
+	Status    string             `bson:"status" json:"status"` // sent, failed, suppressed
+	Error     string             `bson:"error,omitempty" json:"error,omitempty"`
+	Attempt   int                `bson:"attempt" json:"attempt"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Статуси листів
+const (
+	EmailStatusPending    = "pending"
+	EmailStatusSending    = "sending"
+	EmailStatusSent       = "sent"
+	EmailStatusFailed     = "failed"
+	EmailStatusSuppressed = "suppressed"
+)
+
+// Причини блокування адрес
+const (
+	EmailSuppressionBounce    = "bounce"
+	EmailSuppressionComplaint = "complaint"
+	EmailSuppressionManual    = "manual"
+)
+
+// Шаблони листів
+const (
+	EmailTemplateWelcome         = "welcome"
+	EmailTemplatePasswordChanged = "password_changed"
+	EmailTemplateGeneric         = "generic"
+)
@@ -0,0 +1,142 @@
+// internal/models/enrollment.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Типи закладів освіти
+const (
+	InstitutionTypeSchool       = "school"
+	InstitutionTypeKindergarten = "kindergarten"
+)
+
+// IsValidInstitutionType перевіряє тип закладу
+func IsValidInstitutionType(institutionType string) bool {
+	return institutionType == InstitutionTypeSchool || institutionType == InstitutionTypeKindergarten
+}
+
+// Статуси оголошення про набір
+const (
+	EnrollmentStatusDraft     = "draft"
+	EnrollmentStatusPublished = "published"
+	EnrollmentStatusArchived  = "archived"
+)
+
+// Фаза набору відносно дат (обчислюється, не зберігається)
+const (
+	EnrollmentPhaseUpcoming = "upcoming"
+	EnrollmentPhaseOpen     = "open"
+	EnrollmentPhaseClosed   = "closed"
+)
+
+// EducationInstitution - школа або дитячий садок (колекція education_institutions)
+type EducationInstitution struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+
+	Name         string    `bson:"name" json:"name"`
+	Type         string    `bson:"type" json:"type"` // school, kindergarten
+	Address      string    `bson:"address" json:"address"`
+	Neighborhood string    `bson:"neighborhood" json:"neighborhood"` // Мікрорайон - для фільтра підписок
+	Location     *Location `bson:"location,omitempty" json:"location,omitempty"`
+	Phone        string    `bson:"phone,omitempty" json:"phone,omitempty"`
+	Website      string    `bson:"website,omitempty" json:"website,omitempty"`
+	IsActive     bool      `bson:"is_active" json:"is_active"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// EnrollmentDocument - пункт переліку документів для подачі заяви
+type EnrollmentDocument struct {
+	Name     string `bson:"name" json:"name"`
+	Required bool   `bson:"required" json:"required"`
+	Note     string `bson:"note,omitempty" json:"note,omitempty"` // Оригінал/копія, де отримати тощо
+}
+
+// InstitutionPlaces - кількість місць у закладі в межах набору
+type InstitutionPlaces struct {
+	InstitutionID   primitive.ObjectID `bson:"institution_id" json:"institution_id"`
+	InstitutionName string             `bson:"institution_name" json:"institution_name"`
+	Neighborhood    string             `bson:"neighborhood" json:"neighborhood"`
+	Group           string             `bson:"group,omitempty" json:"group,omitempty"` // Клас або вікова група, напр. "1 клас", "3-4 роки"
+	TotalPlaces     int                `bson:"total_places" json:"total_places"`
+	AvailablePlaces int                `bson:"available_places" json:"available_places"`
+}
+
+// EnrollmentAnnouncement - оголошення управління освіти про період набору (колекція enrollments)
+type EnrollmentAnnouncement struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+
+	Title           string `bson:"title" json:"title"`
+	Description     string `bson:"description" json:"description"`
+	InstitutionType string `bson:"institution_type" json:"institution_type"` // school, kindergarten
+	AcademicYear    string `bson:"academic_year" json:"academic_year"`       // напр. "2025-2026"
+
+	// Період подачі заяв
+	StartDate time.Time `bson:"start_date" json:"start_date"`
+	EndDate   time.Time `bson:"end_date" json:"end_date"`
+
+	Places    []InstitutionPlaces  `bson:"places" json:"places"`
+	Documents []EnrollmentDocument `bson:"documents" json:"documents"`
+
+	// Мікрорайони закладів набору - для фільтрації та розсилки підписникам
+	Neighborhoods []string `bson:"neighborhoods" json:"neighborhoods"`
+
+	Status      string     `bson:"status" json:"status"` // draft, published, archived
+	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	Phase       string     `bson:"-" json:"phase,omitempty"` // upcoming, open, closed
+
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// EnrollmentSubscription - підписка батьків на оголошення про набір (одна на користувача в громаді).
+// Порожній список типів чи мікрорайонів означає "усі".
+type EnrollmentSubscription struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"user_id"`
+	CommunityID      primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	InstitutionTypes []string           `bson:"institution_types" json:"institution_types"`
+	Neighborhoods    []string           `bson:"neighborhoods" json:"neighborhoods"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// GetPhase повертає фазу набору на момент now
+func (e *EnrollmentAnnouncement) GetPhase(now time.Time) string {
+	switch {
+	case now.Before(e.StartDate):
+		return EnrollmentPhaseUpcoming
+	case now.After(e.EndDate):
+		return EnrollmentPhaseClosed
+	default:
+		return EnrollmentPhaseOpen
+	}
+}
+
+// TotalAvailablePlaces - вільні місця в усіх закладах набору
+func (e *EnrollmentAnnouncement) TotalAvailablePlaces() int {
+	total := 0
+	for _, p := range e.Places {
+		total += p.AvailablePlaces
+	}
+	return total
+}
+
+// CollectNeighborhoods оновлює список мікрорайонів за закладами набору
+func (e *EnrollmentAnnouncement) CollectNeighborhoods() {
+	seen := make(map[string]bool)
+	e.Neighborhoods = []string{}
+	for _, p := range e.Places {
+		if p.Neighborhood != "" && !seen[p.Neighborhood] {
+			seen[p.Neighborhood] = true
+			e.Neighborhoods = append(e.Neighborhoods, p.Neighborhood)
+		}
+	}
+}
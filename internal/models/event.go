@@ -10,10 +10,13 @@ import (
 
 type Event struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Slug        string             `bson:"slug,omitempty" json:"slug,omitempty"`                 // Адрес для сайта: заголовок латиницей + суффикс ID
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
 	OrganizerID primitive.ObjectID `bson:"organizer_id" json:"organizer_id" validate:"required"`
 
 	Title       string `bson:"title" json:"title" validate:"required,min=5,max=200"`
 	Description string `bson:"description" json:"description" validate:"required,min=10,max=2000"`
+	Language    string `bson:"language" json:"language,omitempty"` // uk, ru или en - определяется при создании (DetectLanguage)
 	Category    string `bson:"category" json:"category" validate:"oneof=cultural educational social business sports charity meeting workshop conference"`
 
 	// Дата и время
@@ -21,11 +24,12 @@ type Event struct {
 	EndDate   *time.Time `bson:"end_date,omitempty" json:"end_date,omitempty"`
 
 	// Местоположение
-	Location  Location `bson:"location" json:"location"`
-	Address   string   `bson:"address" json:"address"`
-	Venue     string   `bson:"venue" json:"venue"` // Название места проведения
-	IsOnline  bool     `bson:"is_online" json:"is_online"`
-	OnlineURL string   `bson:"online_url,omitempty" json:"online_url,omitempty"`
+	Location     Location `bson:"location" json:"location"`
+	Address      string   `bson:"address" json:"address"`
+	Neighborhood string   `bson:"neighborhood,omitempty" json:"neighborhood,omitempty"` // Микрорайон - зона модератора (ModerationScope)
+	Venue        string   `bson:"venue" json:"venue"`                                   // Название места проведения
+	IsOnline     bool     `bson:"is_online" json:"is_online"`
+	OnlineURL    string   `bson:"online_url,omitempty" json:"online_url,omitempty"`
 
 	// Участники
 	Participants    []primitive.ObjectID `bson:"participants" json:"participants"`
@@ -71,6 +75,9 @@ type Event struct {
 	AttendeeCount    int                  `bson:"attendee_count" json:"attendee_count"`
 	ModerationReason string               `bson:"moderation_reason,omitempty" json:"moderation_reason,omitempty"`
 	ModeratedAt      *time.Time           `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+
+	// Пост публичного канала, из которого импортировано событие
+	Source *ContentSource `bson:"source,omitempty" json:"source,omitempty"`
 }
 
 // Категории событий
@@ -92,6 +99,7 @@ const (
 	EventStatusPublished = "published"
 	EventStatusCancelled = "cancelled"
 	EventStatusCompleted = "completed"
+	EventStatusPending   = "pending" // Ожидает модерации (аккаунт с низким доверием)
 )
 
 // Методы для работы с событиями
@@ -299,6 +307,7 @@ func GetEventStatusTranslation(status string) string {
 		EventStatusPublished: "Опубликовано",
 		EventStatusCancelled: "Отменено",
 		EventStatusCompleted: "Завершено",
+		EventStatusPending:   "На модерации",
 	}
 	if translation, exists := translations[status]; exists {
 		return translation
@@ -0,0 +1,45 @@
+// internal/models/export.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportSalt - сіль для псевдонімізації ідентифікаторів у вивантаженнях (колекція export_salts).
+// Поки сіль активна, один і той самий користувач має однаковий псевдонім у всіх вивантаженнях.
+type ExportSalt struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Salt      string              `bson:"salt" json:"-"` // Ніколи не повертається в API
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"expires_at"`
+	RotatedBy *primitive.ObjectID `bson:"rotated_by,omitempty" json:"rotated_by,omitempty"`
+	Reason    string              `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// Режими вивантаження
+const (
+	ExportModePseudonymized = "pseudonymized" // Ідентифікатори замінені псевдонімами (для дослідників)
+	ExportModeRaw           = "raw"           // Справжні ідентифікатори (лише SUPER_ADMIN)
+)
+
+// Набори даних для вивантаження
+const (
+	ExportDatasetUsers              = "users"
+	ExportDatasetCityIssues         = "city_issues"
+	ExportDatasetPetitionSignatures = "petition_signatures"
+	ExportDatasetPollResponses      = "poll_responses"
+	ExportDatasetModerationActions  = "moderation_actions"
+)
+
+// ExportDatasets повертає всі доступні набори даних
+func ExportDatasets() []string {
+	return []string{
+		ExportDatasetUsers,
+		ExportDatasetCityIssues,
+		ExportDatasetPetitionSignatures,
+		ExportDatasetPollResponses,
+		ExportDatasetModerationActions,
+	}
+}
@@ -0,0 +1,72 @@
+// internal/models/faq.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FAQCategory - розділ довідки (вебсайт, застосунок, Telegram-бот)
+type FAQCategory struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+
+	Title       string `bson:"title" json:"title"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	Icon        string `bson:"icon,omitempty" json:"icon,omitempty"`
+	SortOrder   int    `bson:"sort_order" json:"sort_order"`
+	IsActive    bool   `bson:"is_active" json:"is_active"`
+
+	// Кількість опублікованих статей (заповнюється при видачі списку)
+	ArticleCount int64 `bson:"-" json:"article_count"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// FAQArticle - стаття бази знань
+type FAQArticle struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	CategoryID  primitive.ObjectID `bson:"category_id" json:"category_id"`
+
+	Question string `bson:"question" json:"question"`
+	// Короткий текст без розмітки - для Telegram-бота та сніпетів пошуку
+	Summary    string   `bson:"summary,omitempty" json:"summary,omitempty"`
+	Body       string   `bson:"body" json:"body"`
+	BodyFormat string   `bson:"body_format" json:"body_format"` // markdown, html
+	Keywords   []string `bson:"keywords,omitempty" json:"keywords,omitempty"`
+	SortOrder  int      `bson:"sort_order" json:"sort_order"`
+
+	IsPublished bool       `bson:"is_published" json:"is_published"`
+	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+
+	// Статистика
+	ViewCount       int `bson:"view_count" json:"view_count"`
+	HelpfulCount    int `bson:"helpful_count" json:"helpful_count"`
+	NotHelpfulCount int `bson:"not_helpful_count" json:"not_helpful_count"`
+
+	AuthorID  primitive.ObjectID `bson:"author_id" json:"author_id"`
+	UpdatedBy primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// FAQFeedback - відгук "корисно / не корисно" (колекція faq_feedback, один голос на статтю)
+type FAQFeedback struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	ArticleID primitive.ObjectID `bson:"article_id" json:"article_id"`
+	// user:<id> для авторизованих, client:<id> для бота, ip:<addr> для анонімних
+	VoterKey  string    `bson:"voter_key" json:"-"`
+	Helpful   bool      `bson:"helpful" json:"helpful"`
+	Comment   string    `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Формати тексту статті
+const (
+	FAQBodyFormatMarkdown = "markdown"
+	FAQBodyFormatHTML     = "html"
+)
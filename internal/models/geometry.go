@@ -0,0 +1,187 @@
+// internal/models/geometry.go
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// Межі параметрів спрощення геометрії
+const (
+	MinMapZoom           = 0
+	MaxMapZoom           = 22
+	MaxSimplifyTolerance = 5000.0 // Метрів
+	PolylinePrecision    = 5      // Знаків після коми (формат Google Encoded Polyline)
+)
+
+// Метрів на піксель на екваторі при zoom=0 для тайлів 256px (Web Mercator)
+const metersPerPixelAtZoom0 = 156543.03392
+
+// ToleranceForZoom повертає допуск спрощення (у метрах), що відповідає одному пікселю
+// карти на заданому масштабі та широті.
+func ToleranceForZoom(zoom int, latitude float64) float64 {
+	if zoom < MinMapZoom {
+		zoom = MinMapZoom
+	}
+	if zoom > MaxMapZoom {
+		zoom = MaxMapZoom
+	}
+	return metersPerPixelAtZoom0 * math.Cos(latitude*math.Pi/180) / math.Pow(2, float64(zoom))
+}
+
+// SimplifyLocations спрощує ламану алгоритмом Дугласа-Пекера.
+// tolerance - максимальне відхилення від вихідної лінії в метрах; перша та остання точки зберігаються.
+func SimplifyLocations(points []Location, tolerance float64) []Location {
+	if tolerance <= 0 || len(points) < 3 {
+		return points
+	}
+	// Точку без обох координат не спроєктувати - повертаємо лінію без змін
+	for _, point := range points {
+		if len(point.Coordinates) < 2 {
+			return points
+		}
+	}
+
+	// Локальна проєкція в метри: на масштабі маршруту міста похибка нехтовно мала
+	refLat := points[0].Coordinates[1] * math.Pi / 180
+	const metersPerDegree = 111320.0
+	xy := make([][2]float64, len(points))
+	for i, point := range points {
+		xy[i] = [2]float64{
+			point.Coordinates[0] * metersPerDegree * math.Cos(refLat),
+			point.Coordinates[1] * metersPerDegree,
+		}
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+
+	// Ітеративний варіант без рекурсії - маршрути можуть мати тисячі точок
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		segment := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := segment[0], segment[1]
+
+		maxDistance, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			if d := perpendicularDistance(xy[i], xy[first], xy[last]); d > maxDistance {
+				maxDistance, index = d, i
+			}
+		}
+		if index != -1 && maxDistance > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := make([]Location, 0, len(points)/4+2)
+	for i, point := range points {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// perpendicularDistance - відстань від точки p до відрізка ab (у тих самих одиницях)
+func perpendicularDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+// DistanceToPolyline - найменша відстань (метри) від точки до ламаної; для однієї точки - відстань до неї.
+// Повертає -1, якщо координати відсутні.
+func DistanceToPolyline(point Location, line []Location) float64 {
+	if len(point.Coordinates) < 2 || len(line) == 0 {
+		return -1
+	}
+
+	// Та сама локальна проєкція, що й у SimplifyLocations, з початком у точці
+	refLat := point.Coordinates[1] * math.Pi / 180
+	const metersPerDegree = 111320.0
+	project := func(location Location) [2]float64 {
+		return [2]float64{
+			(location.Coordinates[0] - point.Coordinates[0]) * metersPerDegree * math.Cos(refLat),
+			(location.Coordinates[1] - point.Coordinates[1]) * metersPerDegree,
+		}
+	}
+
+	var xy [][2]float64
+	for _, location := range line {
+		if len(location.Coordinates) >= 2 {
+			xy = append(xy, project(location))
+		}
+	}
+	if len(xy) == 0 {
+		return -1
+	}
+	if len(xy) == 1 {
+		return math.Hypot(xy[0][0], xy[0][1])
+	}
+
+	minDistance := math.Inf(1)
+	for i := 1; i < len(xy); i++ {
+		minDistance = math.Min(minDistance, perpendicularDistance([2]float64{0, 0}, xy[i-1], xy[i]))
+	}
+	return minDistance
+}
+
+// EncodePolyline кодує точки у формат Google Encoded Polyline (порядок lat,lng; точність 1e-5)
+func EncodePolyline(points []Location) string {
+	factor := math.Pow(10, PolylinePrecision)
+	var b strings.Builder
+	prevLat, prevLng := 0, 0
+	for _, point := range points {
+		if len(point.Coordinates) < 2 {
+			continue
+		}
+		lat := int(math.Round(point.Coordinates[1] * factor))
+		lng := int(math.Round(point.Coordinates[0] * factor))
+		encodePolylineValue(&b, lat-prevLat)
+		encodePolylineValue(&b, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+func encodePolylineValue(b *strings.Builder, value int) {
+	shifted := value << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+	for shifted >= 0x20 {
+		b.WriteByte(byte((0x20 | (shifted & 0x1f)) + 63))
+		shifted >>= 5
+	}
+	b.WriteByte(byte(shifted + 63))
+}
+
+// Geofence - територія громади: коло радіусом RadiusKm навколо центру
+type Geofence struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radius_km"`
+}
+
+// Contains перевіряє, що точка лежить у межах громади (відстань за формулою гаверсинуса).
+// Без заданого радіуса жодна точка не вважається такою, що лежить у межах.
+func (g Geofence) Contains(point Location) bool {
+	if g.RadiusKm <= 0 || len(point.Coordinates) < 2 {
+		return false
+	}
+	const earthRadiusKm = 6371.0
+	lat1, lat2 := g.Latitude*math.Pi/180, point.Coordinates[1]*math.Pi/180
+	deltaLat := lat2 - lat1
+	deltaLng := (point.Coordinates[0] - g.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLng/2)*math.Sin(deltaLng/2)
+	distance := 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return distance <= g.RadiusKm
+}
@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestSimplifyLocationsKeepsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Location
+	}{
+		{name: "empty", points: nil},
+		{name: "single point", points: []Location{{Type: "Point", Coordinates: []float64{33.36, 46.75}}}},
+		{
+			name: "first point without latitude",
+			points: []Location{
+				{Type: "Point", Coordinates: []float64{33.36}},
+				{Type: "Point", Coordinates: []float64{33.37, 46.76}},
+				{Type: "Point", Coordinates: []float64{33.38, 46.77}},
+			},
+		},
+		{
+			name: "middle point without coordinates",
+			points: []Location{
+				{Type: "Point", Coordinates: []float64{33.36, 46.75}},
+				{Type: "Point"},
+				{Type: "Point", Coordinates: []float64{33.38, 46.77}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SimplifyLocations(tt.points, 10)
+			if len(got) != len(tt.points) {
+				t.Errorf("got %d points, want the input unchanged (%d)", len(got), len(tt.points))
+			}
+		})
+	}
+}
+
+func TestSimplifyLocationsDropsCollinearPoints(t *testing.T) {
+	points := []Location{
+		{Type: "Point", Coordinates: []float64{33.360, 46.750}},
+		{Type: "Point", Coordinates: []float64{33.365, 46.750}},
+		{Type: "Point", Coordinates: []float64{33.370, 46.750}},
+	}
+	if got := SimplifyLocations(points, 10); len(got) != 2 {
+		t.Errorf("got %d points, want 2", len(got))
+	}
+}
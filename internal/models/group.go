@@ -8,6 +8,7 @@ import (
 
 type Group struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
 	Name        string             `bson:"name" json:"name" validate:"required,min=3,max=100"`
 	Description string             `bson:"description" json:"description" validate:"max=500"`
 	Type        string             `bson:"type" json:"type" validate:"required,oneof=country region city interest"`
@@ -23,10 +24,20 @@ type Group struct {
 	Admins     []primitive.ObjectID `bson:"admins" json:"admins"`
 	Moderators []primitive.ObjectID `bson:"moderators" json:"moderators"`
 
+	// Заблокированные в группе: не могут вступить и подать заявку
+	Bans []GroupBan `bson:"bans,omitempty" json:"-"`
+
 	// Настройки
 	IsPublic   bool `bson:"is_public" json:"is_public"`
 	AutoJoin   bool `bson:"auto_join" json:"auto_join"`
 	MaxMembers int  `bson:"max_members" json:"max_members"`
+	// Вступление только по заявке, которую одобряет администратор группы
+	InviteOnly bool `bson:"invite_only" json:"invite_only"`
+
+	// Slow mode: одно сообщение участника в N секунд (0 - выключен)
+	SlowModeSeconds int `bson:"slow_mode_seconds" json:"slow_mode_seconds"`
+	// Канал объявлений: пишут только создатель и администраторы, участники читают
+	AnnouncementsOnly bool `bson:"announcements_only" json:"announcements_only"`
 
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
@@ -43,8 +54,141 @@ const (
 	GroupTypeInterest = "interest"
 )
 
+// Роли участников группы (по убыванию прав)
+const (
+	GroupRoleOwner     = "owner"
+	GroupRoleAdmin     = "admin"
+	GroupRoleModerator = "moderator"
+	GroupRoleMember    = "member"
+)
+
+var groupRoleRanks = map[string]int{
+	GroupRoleOwner:     4,
+	GroupRoleAdmin:     3,
+	GroupRoleModerator: 2,
+	GroupRoleMember:    1,
+}
+
+// GroupBan - блокировка пользователя в группе
+type GroupBan struct {
+	UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Reason   string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	BannedBy primitive.ObjectID `bson:"banned_by" json:"banned_by"`
+	BannedAt time.Time          `bson:"banned_at" json:"banned_at"`
+}
+
+// Статусы заявок на вступление
+const (
+	GroupJoinRequestPending  = "pending"
+	GroupJoinRequestApproved = "approved"
+	GroupJoinRequestRejected = "rejected"
+)
+
+// GroupJoinRequest - заявка на вступление в группу только по приглашению (коллекция group_join_requests)
+type GroupJoinRequest struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Message    string              `bson:"message,omitempty" json:"message,omitempty"`
+	Status     string              `bson:"status" json:"status"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	ReviewedBy *primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+}
+
+// Ограничения ссылок-приглашений
+const (
+	GroupInviteDefaultTTL = 7 * 24 * time.Hour
+	GroupInviteMaxTTL     = 30 * 24 * time.Hour
+	GroupInviteMaxUses    = 1000
+	// Активных приглашений на группу: старые ссылки нужно отзывать
+	GroupInviteMaxActive = 20
+)
+
+// GroupInvite - ссылка-приглашение в группу (коллекция group_invites).
+// По ней вступают без заявки, в том числе в частные группы и группы только по приглашению.
+type GroupInvite struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Code      string             `bson:"code" json:"code"`
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	MaxUses   int                `bson:"max_uses" json:"max_uses"` // 0 - без ограничения
+	Uses      int                `bson:"uses" json:"uses"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// IsUsable - приглашение не отозвано, не истекло и не исчерпано
+func (i *GroupInvite) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && (i.MaxUses == 0 || i.Uses < i.MaxUses)
+}
+
 // Методы для работы с группами
 
+// IsOwner - создатель группы. У групп, созданных до появления creator_id, владелец - created_by.
+func (g *Group) IsOwner(userID primitive.ObjectID) bool {
+	if !g.CreatorID.IsZero() {
+		return g.CreatorID == userID
+	}
+	return g.CreatedBy == userID
+}
+
+// RoleOf возвращает роль пользователя в группе или пустую строку, если он не участник
+func (g *Group) RoleOf(userID primitive.ObjectID) string {
+	switch {
+	case g.IsOwner(userID):
+		return GroupRoleOwner
+	case g.IsAdmin(userID):
+		return GroupRoleAdmin
+	case g.IsModerator(userID):
+		return GroupRoleModerator
+	case g.IsMember(userID):
+		return GroupRoleMember
+	}
+	return ""
+}
+
+// CanManage - может ли actor исключать и блокировать target: модератор управляет участниками,
+// администратор - еще и модераторами, владелец - всеми. Управлять собой нельзя.
+func (g *Group) CanManage(actorID, targetID primitive.ObjectID) bool {
+	if actorID == targetID {
+		return false
+	}
+	actorRank := groupRoleRanks[g.RoleOf(actorID)]
+	return actorRank >= groupRoleRanks[GroupRoleModerator] && actorRank > groupRoleRanks[g.RoleOf(targetID)]
+}
+
+// CanAssignRole - может ли actor назначить target роль role. Роли admin назначает только владелец,
+// moderator и member - владелец и администраторы тем, у кого роль ниже их собственной.
+func (g *Group) CanAssignRole(actorID, targetID primitive.ObjectID, role string) bool {
+	actorRole := g.RoleOf(actorID)
+	if actorRole != GroupRoleOwner && actorRole != GroupRoleAdmin {
+		return false
+	}
+	return g.CanManage(actorID, targetID) && groupRoleRanks[actorRole] > groupRoleRanks[role]
+}
+
+// CanInvite - создавать и отзывать приглашения могут владелец, администраторы и модераторы
+func (g *Group) CanInvite(userID primitive.ObjectID) bool {
+	return groupRoleRanks[g.RoleOf(userID)] >= groupRoleRanks[GroupRoleModerator]
+}
+
+// IsBanned - заблокирован ли пользователь в группе
+func (g *Group) IsBanned(userID primitive.ObjectID) bool {
+	for _, ban := range g.Bans {
+		if ban.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGroupRole - допустимая роль для PUT /groups/:id/members/:userId/role (владелец не назначается)
+func IsGroupRole(role string) bool {
+	return role == GroupRoleAdmin || role == GroupRoleModerator || role == GroupRoleMember
+}
+
 func (g *Group) IsMember(userID primitive.ObjectID) bool {
 	for _, memberID := range g.Members {
 		if memberID == userID {
@@ -77,8 +221,12 @@ func (g *Group) CanUserJoin(userID primitive.ObjectID) bool {
 		return false // Уже участник
 	}
 
-	if !g.IsPublic {
-		return false // Частная группа
+	if g.IsBanned(userID) {
+		return false // Заблокирован в группе
+	}
+
+	if !g.IsPublic || g.InviteOnly {
+		return false // Частная группа или вступление по заявке
 	}
 
 	if g.MaxMembers > 0 && len(g.Members) >= g.MaxMembers {
@@ -92,6 +240,25 @@ func (g *Group) CanUserPost(userID primitive.ObjectID) bool {
 	return g.IsMember(userID) || g.IsAdmin(userID) || g.IsModerator(userID)
 }
 
+// CanSendMessages - в канале объявлений сообщения отправляют только создатель и администраторы
+func (g *Group) CanSendMessages(userID primitive.ObjectID) bool {
+	return !g.AnnouncementsOnly || g.IsOwner(userID) || g.IsAdmin(userID)
+}
+
+// CanPin - закреплять сообщения могут создатель и администраторы группы
+func (g *Group) CanPin(userID primitive.ObjectID) bool {
+	return g.IsOwner(userID) || g.IsAdmin(userID)
+}
+
+// SlowModeFor возвращает интервал slow mode для пользователя.
+// Создатель, администраторы и модераторы группы не ограничиваются.
+func (g *Group) SlowModeFor(userID primitive.ObjectID) time.Duration {
+	if g.SlowModeSeconds <= 0 || g.IsOwner(userID) || g.IsAdmin(userID) || g.IsModerator(userID) {
+		return 0
+	}
+	return time.Duration(g.SlowModeSeconds) * time.Second
+}
+
 func (g *Group) GetMemberCount() int {
 	return len(g.Members)
 }
@@ -0,0 +1,19 @@
+// internal/models/language.go
+package models
+
+import "slices"
+
+// Мови контенту, що визначаються автоматично при створенні (поле language)
+const (
+	LanguageUkrainian = "uk"
+	LanguageRussian   = "ru"
+	LanguageEnglish   = "en"
+)
+
+// ContentLanguages - допустимі значення фільтра ?lang= у списках
+var ContentLanguages = []string{LanguageUkrainian, LanguageRussian, LanguageEnglish}
+
+// IsContentLanguage перевіряє код мови контенту
+func IsContentLanguage(lang string) bool {
+	return slices.Contains(ContentLanguages, lang)
+}
@@ -19,20 +19,116 @@ type Message struct {
 	MediaType string `bson:"media_type,omitempty" json:"media_type,omitempty"`
 	MediaSize int64  `bson:"media_size,omitempty" json:"media_size,omitempty"`
 
+	// Вложения, загруженные через POST /groups/:id/attachments
+	Attachments []MessageAttachment `bson:"attachments,omitempty" json:"attachments,omitempty"`
+
 	// Ответ на сообщение
 	ReplyToID *primitive.ObjectID `bson:"reply_to_id,omitempty" json:"reply_to_id,omitempty"`
 
 	// Метаданные
 	IsEdited  bool      `bson:"is_edited" json:"is_edited"`
 	IsDeleted bool      `bson:"is_deleted" json:"is_deleted"`
+	IsHeld    bool      `bson:"is_held,omitempty" json:"is_held,omitempty"` // Утримано до перевірки модератором (посилання від акаунту з низькою довірою)
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 
+	// История правок: предыдущие версии текста, последняя правка в конце
+	EditHistory []MessageEdit       `bson:"edit_history,omitempty" json:"edit_history,omitempty"`
+	EditedAt    *time.Time          `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	DeletedAt   *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy   *primitive.ObjectID `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"` // Автор или модератор группы
+
+	// Закрепленное администратором группы сообщение
+	PinnedAt *time.Time          `bson:"pinned_at,omitempty" json:"pinned_at,omitempty"`
+	PinnedBy *primitive.ObjectID `bson:"pinned_by,omitempty" json:"pinned_by,omitempty"`
+
+	// Очередь модератора: жалобы участников и срабатывания фильтра запрещенных слов.
+	// Участникам группы не показывается.
+	Review *MessageReview `bson:"review,omitempty" json:"-"`
+
 	// Дополнительные поля для реакций и статистики (опционально)
 	Reactions []MessageReaction `bson:"reactions,omitempty" json:"reactions,omitempty"`
 	ReadBy    []MessageRead     `bson:"read_by,omitempty" json:"read_by,omitempty"`
 }
 
+// MessageAttachment - метаданные вложения сообщения. Файлы не публичны:
+// URL и ThumbnailURL - временные подписанные ссылки, заполняются при выдаче.
+type MessageAttachment struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	Kind     string             `bson:"kind" json:"kind"` // image, video, audio, document
+	FileName string             `bson:"file_name" json:"file_name"`
+	MimeType string             `bson:"mime_type" json:"mime_type"`
+	Size     int64              `bson:"size" json:"size"`
+	Width    int                `bson:"width,omitempty" json:"width,omitempty"`
+	Height   int                `bson:"height,omitempty" json:"height,omitempty"`
+
+	FileKey      string `bson:"file_key" json:"-"`
+	ThumbnailKey string `bson:"thumbnail_key,omitempty" json:"-"`
+
+	URL          string `bson:"-" json:"url,omitempty"`
+	ThumbnailURL string `bson:"-" json:"thumbnail_url,omitempty"`
+}
+
+// ChatUpload - загруженный файл чата (chat_uploads). До отправки сообщения MessageID пуст;
+// неиспользованные загрузки удаляются через ChatUploadTTL.
+type ChatUpload struct {
+	MessageAttachment `bson:",inline"`
+	GroupID           primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	UserID            primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	MessageID         *primitive.ObjectID `bson:"message_id,omitempty" json:"message_id,omitempty"`
+	CreatedAt         time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// Сколько вложений можно прикрепить к одному сообщению
+const MaxMessageAttachments = 10
+
+// Сколько сообщений можно закрепить в группе одновременно
+const MaxPinnedMessages = 20
+
+// Через сколько удаляется загрузка, не прикрепленная к сообщению
+const ChatUploadTTL = 24 * time.Hour
+
+// Допустимые типы вложений: MIME (по содержимому файла) -> вид вложения
+var AttachmentMimeTypes = map[string]string{
+	"image/jpeg":      MediaTypeImage,
+	"image/png":       MediaTypeImage,
+	"image/gif":       MediaTypeImage,
+	"image/webp":      MediaTypeImage,
+	"video/mp4":       MediaTypeVideo,
+	"video/webm":      MediaTypeVideo,
+	"audio/mpeg":      MediaTypeAudio,
+	"audio/ogg":       MediaTypeAudio,
+	"audio/wave":      MediaTypeAudio,
+	"application/ogg": MediaTypeAudio,
+	"application/pdf": MediaTypeDoc,
+	"text/plain":      MediaTypeDoc,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   MediaTypeDoc,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         MediaTypeDoc,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": MediaTypeDoc,
+	"application/vnd.oasis.opendocument.text":                                   MediaTypeDoc,
+	"application/vnd.oasis.opendocument.spreadsheet":                            MediaTypeDoc,
+}
+
+// Максимальный размер вложения по виду
+var MaxAttachmentSizes = map[string]int64{
+	MediaTypeImage: 10 * 1024 * 1024,
+	MediaTypeVideo: 50 * 1024 * 1024,
+	MediaTypeAudio: 20 * 1024 * 1024,
+	MediaTypeDoc:   20 * 1024 * 1024,
+}
+
+// MessageEdit - версия текста сообщения до правки
+type MessageEdit struct {
+	Content  string    `bson:"content" json:"content"`
+	EditedAt time.Time `bson:"edited_at" json:"edited_at"` // Когда версия была заменена
+}
+
+// Сколько версий хранится в истории правок сообщения
+const MaxMessageEditHistory = 20
+
+// Автор может править сообщение в течение этого времени после отправки
+const MessageEditWindow = 15 * time.Minute
+
 type MessageReaction struct {
 	UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
 	Reaction string             `bson:"reaction" json:"reaction"` // emoji или тип реакции
@@ -44,6 +140,52 @@ type MessageRead struct {
 	ReadAt time.Time          `bson:"read_at" json:"read_at"`
 }
 
+// GroupReadState - позиция чтения участника в группе (group_read_states).
+// Прочитаны все сообщения с ID не больше LastReadMessageID: ObjectID растут со временем отправки.
+type GroupReadState struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	GroupID           primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID            primitive.ObjectID `bson:"user_id" json:"user_id"`
+	LastReadMessageID primitive.ObjectID `bson:"last_read_message_id" json:"last_read_message_id"`
+	LastReadAt        time.Time          `bson:"last_read_at" json:"last_read_at"`
+}
+
+// GroupDeliveryState - позиция доставки сообщений группы в сессию (устройство) участника
+// (group_delivery_states). Доставлены и подтверждены клиентом все сообщения с ID не больше
+// LastDeliveredMessageID; остальные досылаются при переподключении WebSocket.
+type GroupDeliveryState struct {
+	ID                     primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	GroupID                primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID                 primitive.ObjectID `bson:"user_id" json:"user_id"`
+	SessionID              string             `bson:"session_id" json:"-"` // Пусто - токен без сессии
+	LastDeliveredMessageID primitive.ObjectID `bson:"last_delivered_message_id" json:"last_delivered_message_id"`
+	UpdatedAt              time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Счетчик непрочитанных в группе не считается дальше этого значения (показывается как 999+)
+const MaxGroupUnreadCount = 999
+
+// MessageSearchHit - сообщение, найденное поиском по группе.
+// Highlights - позиции найденных слов в Snippet, в символах (code points), конец не включается.
+type MessageSearchHit struct {
+	Message    `bson:",inline"`
+	Score      float64     `bson:"score" json:"score"`
+	Snippet    string      `bson:"-" json:"snippet"`
+	Highlights []TextRange `bson:"-" json:"highlights"`
+}
+
+// TextRange - фрагмент текста [Start, End)
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Ограничения поиска по сообщениям
+const (
+	MinMessageSearchQueryLength = 2
+	MaxMessageSearchQueryLength = 200
+)
+
 // Типы сообщений
 const (
 	MessageTypeText  = "text"
@@ -68,7 +210,7 @@ func (m *Message) IsFromUser(userID primitive.ObjectID) bool {
 }
 
 func (m *Message) CanBeEditedBy(userID primitive.ObjectID) bool {
-	// Пользователь может редактировать свои сообщения в течение 15 минут
+	// Пользователь может редактировать свои сообщения в течение MessageEditWindow
 	if m.UserID != userID {
 		return false
 	}
@@ -78,7 +220,7 @@ func (m *Message) CanBeEditedBy(userID primitive.ObjectID) bool {
 	}
 
 	timeSinceCreation := time.Since(m.CreatedAt)
-	return timeSinceCreation < 15*time.Minute
+	return timeSinceCreation < MessageEditWindow
 }
 
 func (m *Message) CanBeDeletedBy(userID primitive.ObjectID, isAdmin bool) bool {
@@ -100,7 +242,7 @@ func (m *Message) IsReply() bool {
 }
 
 func (m *Message) HasMedia() bool {
-	return m.MediaURL != ""
+	return m.MediaURL != "" || len(m.Attachments) > 0
 }
 
 func (m *Message) GetPreview() string {
@@ -132,6 +274,7 @@ func (m *Message) MarkAsDeleted() {
 	m.IsDeleted = true
 	m.Content = ""
 	m.MediaURL = ""
+	m.Attachments = nil
 	m.UpdatedAt = time.Now()
 }
 
@@ -0,0 +1,81 @@
+// internal/models/moderation.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModerationAction - рішення модератора щодо контенту (колекція moderation_actions)
+type ModerationAction struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	ModeratorID primitive.ObjectID `bson:"moderator_id" json:"moderator_id"`
+	ContentType string             `bson:"content_type" json:"content_type"` // announcement, event, message, poll
+	ContentID   primitive.ObjectID `bson:"content_id" json:"content_id"`
+	AuthorID    primitive.ObjectID `bson:"author_id,omitempty" json:"author_id,omitempty"`
+	Decision    string             `bson:"decision" json:"decision"` // approved, rejected
+	Reason      string             `bson:"reason,omitempty" json:"reason,omitempty"`
+
+	// Час від подання контенту до рішення
+	SubmittedAt     time.Time `bson:"submitted_at" json:"submitted_at"`
+	DecidedAt       time.Time `bson:"decided_at" json:"decided_at"`
+	DecisionSeconds int64     `bson:"decision_seconds" json:"decision_seconds"`
+}
+
+// Типи контенту, що модерується
+const (
+	ModerationContentAnnouncement = "announcement"
+	ModerationContentEvent        = "event"
+	ModerationContentMessage      = "message"
+	ModerationContentPoll         = "poll"
+)
+
+// Рішення модератора
+const (
+	ModerationDecisionApproved = "approved"
+	ModerationDecisionRejected = "rejected"
+)
+
+// Межі зони модерації
+const (
+	MaxModerationScopeEntries = 50
+	MaxNeighborhoodLength     = 100
+)
+
+// ModerationScope - зона відповідальності модератора (users.moderation_scope).
+// Порожній список не обмежує відповідний вимір. Контент без мікрорайону (петиції,
+// опитування, загальноміські оголошення) поза зоною модератора з мікрорайонами.
+type ModerationScope struct {
+	Neighborhoods []string `bson:"neighborhoods,omitempty" json:"neighborhoods"`
+	Categories    []string `bson:"categories,omitempty" json:"categories"` // Коди категорій таксономії
+}
+
+// IsEmpty - зону не задано, модератор діє в усьому місті
+func (s *ModerationScope) IsEmpty() bool {
+	return s == nil || (len(s.Neighborhoods) == 0 && len(s.Categories) == 0)
+}
+
+// Allows - чи входить контент з мікрорайоном neighborhood і категорією category до зони
+func (s *ModerationScope) Allows(neighborhood, category string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	if len(s.Neighborhoods) > 0 && !containsString(s.Neighborhoods, neighborhood) {
+		return false
+	}
+	if len(s.Categories) > 0 && !containsString(s.Categories, category) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+// internal/models/moderator_note.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModeratorNote - внутрішня нотатка модераторів до контенту або користувача (колекція
+// moderator_notes). Нотатки однієї сутності утворюють обговорення; автор сутності та
+// інші користувачі їх не бачать.
+type ModeratorNote struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	EntityType  string             `bson:"entity_type" json:"entity_type"`
+	EntityID    primitive.ObjectID `bson:"entity_id" json:"entity_id"`
+	AuthorID    primitive.ObjectID `bson:"author_id" json:"author_id"`
+	AuthorName  string             `bson:"author_name" json:"author_name"`
+	Body        string             `bson:"body" json:"body"`
+
+	// Згадані модератори та адміністратори отримують сповіщення
+	Mentions []primitive.ObjectID `bson:"mentions" json:"mentions"`
+
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	EditedAt  *time.Time `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+}
+
+// Сутності, до яких додаються нотатки модераторів
+const (
+	NoteEntityCityIssue    = "city_issue"
+	NoteEntityPetition     = "petition"
+	NoteEntityAnnouncement = "announcement"
+	NoteEntityEvent        = "event"
+	NoteEntityPoll         = "poll"
+	NoteEntityMessage      = "message"
+	NoteEntityUser         = "user"
+)
+
+// NoteEntityCollections - колекція, в якій зберігається сутність кожного типу
+var NoteEntityCollections = map[string]string{
+	NoteEntityCityIssue:    "city_issues",
+	NoteEntityPetition:     "petitions",
+	NoteEntityAnnouncement: "announcements",
+	NoteEntityEvent:        "events",
+	NoteEntityPoll:         "polls",
+	NoteEntityMessage:      "messages",
+	NoteEntityUser:         "users",
+}
@@ -9,12 +9,16 @@ type Notification struct {
 	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id,omitempty"`
 	UserID    primitive.ObjectID     `bson:"user_id" json:"user_id"`
 	Type      string                 `bson:"type" json:"type"` // announcement, event, poll, etc
+	Module    string                 `bson:"module,omitempty" json:"module,omitempty"`
+	Category  string                 `bson:"category,omitempty" json:"category"` // messages, city, emergency, system
 	Title     string                 `bson:"title" json:"title"`
 	Message   string                 `bson:"message" json:"message"`
 	Data      map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"` // Додаткові дані
 	IsRead    bool                   `bson:"is_read" json:"is_read"`
 	ReadAt    *time.Time             `bson:"read_at,omitempty" json:"read_at,omitempty"`
 	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+	// Текст для читачів екрана і підказки вимови
+	SpeechHints `bson:",inline"`
 }
 
 // Типи сповіщень
@@ -26,4 +30,112 @@ const (
 	NotificationTypeCityIssue    = "city_issue"
 	NotificationTypeMessage      = "message"
 	NotificationTypeSystem       = "system"
+	NotificationTypeEmergency    = "emergency"
+	NotificationTypeCampaign     = "campaign"
+	NotificationTypeEnrollment   = "enrollment"
 )
+
+// Категорії сповіщень (вкладки у застосунку)
+const (
+	NotificationCategoryMessages  = "messages"
+	NotificationCategoryCity      = "city"
+	NotificationCategoryEmergency = "emergency"
+	NotificationCategorySystem    = "system"
+)
+
+// NotificationCategory - опис вкладки інбоксу
+type NotificationCategory struct {
+	Key   string   `json:"key"`
+	Label string   `json:"label"`
+	Types []string `json:"types"`
+}
+
+// NotificationCategories повертає категорії у порядку відображення
+func NotificationCategories() []NotificationCategory {
+	return []NotificationCategory{
+		{Key: NotificationCategoryMessages, Label: "Повідомлення", Types: []string{NotificationTypeMessage}},
+		{Key: NotificationCategoryCity, Label: "Місто", Types: []string{
+			NotificationTypeAnnouncement, NotificationTypeEvent, NotificationTypePoll,
+			NotificationTypePetition, NotificationTypeCityIssue, NotificationTypeEnrollment,
+		}},
+		{Key: NotificationCategoryEmergency, Label: "Екстрені", Types: []string{NotificationTypeEmergency}},
+		{Key: NotificationCategorySystem, Label: "Системні", Types: []string{NotificationTypeSystem, NotificationTypeCampaign}},
+	}
+}
+
+// IsValidNotificationCategory перевіряє ключ категорії
+func IsValidNotificationCategory(category string) bool {
+	for _, c := range NotificationCategories() {
+		if c.Key == category {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationDataModules - ключі в data, за якими визначається модуль сповіщення
+var notificationDataModules = []struct {
+	key    string
+	module string
+}{
+	{"issue_id", ModuleCityIssues},
+	{"petition_id", ModulePetitions},
+	{"poll_id", ModulePolls},
+	{"event_id", ModuleEvents},
+	{"announcement_id", ModuleAnnouncements},
+	{"group_id", ModuleGroups},
+	{"route_id", ModuleTransport},
+	{"vehicle_id", ModuleTransport},
+	{"concession", ModuleTransport},
+	{"enrollment_id", ModuleEducation},
+}
+
+// ResolveNotificationModule визначає модуль, до якого відноситься сповіщення
+func ResolveNotificationModule(notificationType string, data map[string]interface{}) string {
+	switch notificationType {
+	case NotificationTypeMessage:
+		return ModuleGroups
+	case NotificationTypeAnnouncement:
+		return ModuleAnnouncements
+	case NotificationTypeEvent:
+		return ModuleEvents
+	case NotificationTypePoll:
+		return ModulePolls
+	case NotificationTypePetition:
+		return ModulePetitions
+	case NotificationTypeCityIssue:
+		return ModuleCityIssues
+	case NotificationTypeEnrollment:
+		return ModuleEducation
+	}
+
+	// Системні сповіщення модулів (наприклад, статус проблеми) розпізнаємо за data
+	for _, m := range notificationDataModules {
+		if _, ok := data[m.key]; ok {
+			return m.module
+		}
+	}
+	return ""
+}
+
+// ResolveNotificationCategory визначає вкладку інбоксу для сповіщення
+func ResolveNotificationCategory(notificationType, module string) string {
+	switch notificationType {
+	case NotificationTypeMessage:
+		return NotificationCategoryMessages
+	case NotificationTypeEmergency:
+		return NotificationCategoryEmergency
+	case NotificationTypeAnnouncement, NotificationTypeEvent, NotificationTypePoll,
+		NotificationTypePetition, NotificationTypeCityIssue, NotificationTypeEnrollment:
+		return NotificationCategoryCity
+	}
+
+	// Системне сповіщення про міський контент показуємо у вкладці "Місто"
+	switch module {
+	case ModuleCityIssues, ModulePetitions, ModulePolls, ModuleEvents, ModuleAnnouncements, ModuleTransport, ModuleEducation:
+		return NotificationCategoryCity
+	case ModuleGroups:
+		return NotificationCategoryMessages
+	}
+	return NotificationCategorySystem
+}
@@ -8,17 +8,22 @@ import (
 )
 
 type Petition struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	AuthorID primitive.ObjectID `bson:"author_id" json:"author_id" validate:"required"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Slug        string             `bson:"slug,omitempty" json:"slug,omitempty"`                 // Адрес для сайта: заголовок латиницей + суффикс ID
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	AuthorID    primitive.ObjectID `bson:"author_id" json:"author_id" validate:"required"`
 
 	// Основная информация
 	Title       string `bson:"title" json:"title" validate:"required,min=10,max=300"`
 	Description string `bson:"description" json:"description" validate:"required,min=50,max=5000"`
-	Category    string `bson:"category" json:"category" validate:"required,oneof=infrastructure social environment economy governance safety transport education healthcare"`
+	Language    string `bson:"language" json:"language,omitempty"`           // uk, ru или en - определяется при создании (DetectLanguage)
+	Category    string `bson:"category" json:"category" validate:"required"` // Код из таксономии (taxonomies)
 
 	// Цели и требования
 	RequiredSignatures int    `bson:"required_signatures" json:"required_signatures" validate:"min=100"`
 	Demands            string `bson:"demands" json:"demands" validate:"required,min=20,max=2000"`
+	// Порог администрации, примененный при публикации (с нормативным основанием)
+	Threshold *PetitionThresholdApplied `bson:"threshold,omitempty" json:"threshold,omitempty"`
 
 	// Подписи и поддержка
 	Signatures     []PetitionSignature `bson:"signatures" json:"signatures"`
@@ -37,11 +42,34 @@ type Petition struct {
 	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
 	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
 
+	// Заброшенный черновик: продление автором и предупреждение об удалении
+	DraftExtendedAt *time.Time `bson:"draft_extended_at,omitempty" json:"draft_extended_at,omitempty"`
+	DraftWarnedAt   *time.Time `bson:"draft_warned_at,omitempty" json:"draft_warned_at,omitempty"`
+
 	// Дополнительные поля
 	Tags           []string `bson:"tags" json:"tags"`
 	ViewCount      int      `bson:"view_count" json:"view_count"`
 	ShareCount     int      `bson:"share_count" json:"share_count"`
 	AttachmentURLs []string `bson:"attachment_urls" json:"attachment_urls"`
+
+	// История редактирования после публикации (версии в content_revisions)
+	EditedAt      *time.Time `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	RevisionCount int        `bson:"revision_count,omitempty" json:"revision_count,omitempty"`
+
+	// Соавторы: редактируют черновик и получают уведомления о статусе
+	CoAuthors []PetitionCoAuthor `bson:"co_authors,omitempty" json:"co_authors,omitempty"`
+
+	// Похожие материалы (related_content) - заполняется только в карточке петиции
+	Related []RelatedItem `bson:"-" json:"related,omitempty"`
+}
+
+// PetitionCoAuthor - приглашенный автором соавтор петиции. Права появляются после принятия приглашения.
+type PetitionCoAuthor struct {
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	FullName   string             `bson:"full_name" json:"full_name"`
+	Status     string             `bson:"status" json:"status"` // invited, accepted
+	InvitedAt  time.Time          `bson:"invited_at" json:"invited_at"`
+	AcceptedAt *time.Time         `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
 }
 
 type PetitionSignature struct {
@@ -75,6 +103,15 @@ const (
 	PetitionStatusRejected    = "rejected"
 )
 
+// Статусы соавторов петиции
+const (
+	PetitionCoAuthorInvited  = "invited"
+	PetitionCoAuthorAccepted = "accepted"
+)
+
+// Максимальное количество соавторов (включая неподтвердивших приглашение)
+const MaxPetitionCoAuthors = 5
+
 // Категории петиций
 const (
 	PetitionCategoryInfrastructure = "infrastructure"
@@ -95,6 +132,25 @@ const (
 	PetitionDecisionPartiallyAccepted = "partially_accepted"
 )
 
+// Получение переводов категорий петиций для UI
+func GetPetitionCategoryTranslation(category string) string {
+	translations := map[string]string{
+		PetitionCategoryInfrastructure: "Инфраструктура",
+		PetitionCategorySocial:         "Социальная сфера",
+		PetitionCategoryEnvironment:    "Экология",
+		PetitionCategoryEconomy:        "Экономика",
+		PetitionCategoryGovernance:     "Управление",
+		PetitionCategorySafety:         "Безопасность",
+		PetitionCategoryTransport:      "Транспорт",
+		PetitionCategoryEducation:      "Образование",
+		PetitionCategoryHealthcare:     "Здравоохранение",
+	}
+	if translation, exists := translations[category]; exists {
+		return translation
+	}
+	return category
+}
+
 // Методы для работы с петициями
 
 func (p *Petition) IsExpired() bool {
@@ -123,6 +179,47 @@ func (p *Petition) GetSignatureByUser(userID primitive.ObjectID) *PetitionSignat
 	return nil
 }
 
+// GetCoAuthor возвращает запись соавтора (в любом статусе) или nil
+func (p *Petition) GetCoAuthor(userID primitive.ObjectID) *PetitionCoAuthor {
+	for i, coAuthor := range p.CoAuthors {
+		if coAuthor.UserID == userID {
+			return &p.CoAuthors[i]
+		}
+	}
+	return nil
+}
+
+// IsCoAuthor - пользователь принял приглашение в соавторы
+func (p *Petition) IsCoAuthor(userID primitive.ObjectID) bool {
+	coAuthor := p.GetCoAuthor(userID)
+	return coAuthor != nil && coAuthor.Status == PetitionCoAuthorAccepted
+}
+
+// CanEditDraft - автор или принявший приглашение соавтор
+func (p *Petition) CanEditDraft(userID primitive.ObjectID) bool {
+	return p.AuthorID == userID || p.IsCoAuthor(userID)
+}
+
+// AcceptedCoAuthors - соавторы, принявшие приглашение (для публичных ответов)
+func (p *Petition) AcceptedCoAuthors() []PetitionCoAuthor {
+	accepted := []PetitionCoAuthor{}
+	for _, coAuthor := range p.CoAuthors {
+		if coAuthor.Status == PetitionCoAuthorAccepted {
+			accepted = append(accepted, coAuthor)
+		}
+	}
+	return accepted
+}
+
+// NotificationRecipients - автор и принявшие приглашение соавторы
+func (p *Petition) NotificationRecipients() []primitive.ObjectID {
+	recipients := []primitive.ObjectID{p.AuthorID}
+	for _, coAuthor := range p.AcceptedCoAuthors() {
+		recipients = append(recipients, coAuthor.UserID)
+	}
+	return recipients
+}
+
 func (p *Petition) GetProgressPercentage() float64 {
 	if p.RequiredSignatures == 0 {
 		return 0
@@ -0,0 +1,90 @@
+// internal/models/petition_threshold.go
+package models
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Способи визначення порогу підписів
+const (
+	PetitionThresholdFixed      = "fixed"      // Встановлена кількість підписів
+	PetitionThresholdPopulation = "population" // Відсоток від кількості жителів громади
+)
+
+// MinPetitionSignatures - нижня межа порогу: раніше автор вказував не менше 100 підписів
+const MinPetitionSignatures = 100
+
+// PetitionThreshold - поріг підписів, встановлений адміністрацією (колекція petition_thresholds).
+// Правило з порожньою категорією діє для всіх категорій громади, для яких немає окремого правила.
+type PetitionThreshold struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	Category    string             `bson:"category" json:"category"` // Код із таксономії petitions; порожньо - за замовчуванням
+	Mode        string             `bson:"mode" json:"mode"`
+
+	Signatures        int     `bson:"signatures,omitempty" json:"signatures,omitempty"`                 // Для fixed
+	Population        int     `bson:"population,omitempty" json:"population,omitempty"`                 // Для population: кількість жителів
+	PopulationPercent float64 `bson:"population_percent,omitempty" json:"population_percent,omitempty"` // Для population: відсоток жителів
+	MinSignatures     int     `bson:"min_signatures,omitempty" json:"min_signatures,omitempty"`
+	MaxSignatures     int     `bson:"max_signatures,omitempty" json:"max_signatures,omitempty"`
+
+	// Нормативна підстава (статут громади, положення про електронні петиції)
+	LegalBasis    string `bson:"legal_basis" json:"legal_basis"`
+	LegalBasisURL string `bson:"legal_basis_url,omitempty" json:"legal_basis_url,omitempty"`
+
+	Required int `bson:"-" json:"required_signatures"` // Обчислений поріг (заповнює сервіс)
+
+	UpdatedBy *primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// RequiredSignatures обчислює поріг за правилом: для population - частка жителів з округленням
+// вгору, обмежена min/max. Поріг не буває меншим за MinPetitionSignatures.
+func (t *PetitionThreshold) RequiredSignatures() int {
+	required := t.Signatures
+	if t.Mode == PetitionThresholdPopulation {
+		required = int(math.Ceil(float64(t.Population) * t.PopulationPercent / 100))
+	}
+	if t.MaxSignatures > 0 && required > t.MaxSignatures {
+		required = t.MaxSignatures
+	}
+	if required < t.MinSignatures {
+		required = t.MinSignatures
+	}
+	if required < MinPetitionSignatures {
+		required = MinPetitionSignatures
+	}
+	return required
+}
+
+// Applied - знімок правила для петиції: зміна правила не змінює поріг уже опублікованих петицій
+func (t *PetitionThreshold) Applied(requested int, now time.Time) *PetitionThresholdApplied {
+	return &PetitionThresholdApplied{
+		ThresholdID:        t.ID,
+		Mode:               t.Mode,
+		Population:         t.Population,
+		PopulationPercent:  t.PopulationPercent,
+		LegalBasis:         t.LegalBasis,
+		LegalBasisURL:      t.LegalBasisURL,
+		RequestedByAuthor:  requested,
+		RequiredSignatures: t.RequiredSignatures(),
+		AppliedAt:          now,
+	}
+}
+
+// PetitionThresholdApplied - поріг, застосований до петиції при публікації
+type PetitionThresholdApplied struct {
+	ThresholdID        primitive.ObjectID `bson:"threshold_id" json:"threshold_id"`
+	Mode               string             `bson:"mode" json:"mode"`
+	Population         int                `bson:"population,omitempty" json:"population,omitempty"`
+	PopulationPercent  float64            `bson:"population_percent,omitempty" json:"population_percent,omitempty"`
+	LegalBasis         string             `bson:"legal_basis" json:"legal_basis"`
+	LegalBasisURL      string             `bson:"legal_basis_url,omitempty" json:"legal_basis_url,omitempty"`
+	RequestedByAuthor  int                `bson:"requested_by_author" json:"requested_by_author"` // Значення, яке автор вказав у чернетці
+	RequiredSignatures int                `bson:"required_signatures" json:"required_signatures"`
+	AppliedAt          time.Time          `bson:"applied_at" json:"applied_at"`
+}
@@ -0,0 +1,21 @@
+// internal/models/phone_verification.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PhoneVerificationCode - одноразовий код підтвердження номера телефону (колекція phone_codes).
+// Зберігається лише хеш коду; записи також рахують запити для ліміту на номер.
+type PhoneVerificationCode struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Phone      string             `bson:"phone" json:"phone"` // У форматі E.164 (+380XXXXXXXXX)
+	CodeHash   string             `bson:"code_hash" json:"-"`
+	Attempts   int                `bson:"attempts" json:"attempts"` // Невдалі спроби введення
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`
+	VerifiedAt *time.Time         `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+}
@@ -3,19 +3,23 @@ package models
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Poll struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	CreatorID primitive.ObjectID `bson:"creator_id" json:"creator_id" validate:"required"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	CreatorID   primitive.ObjectID `bson:"creator_id" json:"creator_id" validate:"required"`
 
 	// Основная информация
 	Title       string `bson:"title" json:"title" validate:"required,min=5,max=300"`
 	Description string `bson:"description" json:"description" validate:"required,min=10,max=2000"`
-	Category    string `bson:"category" json:"category" validate:"required,oneof=city_planning transport infrastructure social environment governance budget education healthcare"`
+	Language    string `bson:"language" json:"language,omitempty"`           // uk, ru или en - определяется при создании (DetectLanguage)
+	Category    string `bson:"category" json:"category" validate:"required"` // Код из таксономии (taxonomies)
 
 	// Настройки опроса
 	Questions     []PollQuestion `bson:"questions" json:"questions" validate:"required,min=1"`
@@ -25,24 +29,35 @@ type Poll struct {
 
 	// Ограничения участия
 	TargetGroups     []primitive.ObjectID `bson:"target_groups,omitempty" json:"target_groups,omitempty"` // Конкретные группы
+	AnnouncedAt      *time.Time           `bson:"announced_at,omitempty" json:"announced_at,omitempty"`   // Участники целевых групп уведомлены об открытии
 	AgeRestriction   *AgeRestriction      `bson:"age_restriction,omitempty" json:"age_restriction,omitempty"`
 	LocationRequired bool                 `bson:"location_required" json:"location_required"` // Требуется ли быть в определенной локации
 
+	// Официальная консультация: голосуют только пользователи с личностью, подтвержденной Дией или BankID,
+	// один голос на личность (РНОКПП), а не на аккаунт
+	VerifiedVotersOnly bool `bson:"verified_voters_only" json:"verified_voters_only"`
+
 	// Временные рамки
 	StartDate time.Time `bson:"start_date" json:"start_date"`
 	EndDate   time.Time `bson:"end_date" json:"end_date"`
 
 	// Статистика и результаты
-	TotalResponses int            `bson:"total_responses" json:"total_responses"`
-	Responses      []PollResponse `bson:"responses" json:"responses"`
-	ResponseCount  int            `bson:"response_count" json:"response_count"`
-	Results        PollResults    `bson:"results" json:"results"`
+	// Ответы хранятся в коллекции poll_responses, счетчики меняются через $inc
+	TotalResponses int         `bson:"total_responses" json:"total_responses"`
+	ResponseCount  int         `bson:"response_count" json:"response_count"`
+	Results        PollResults `bson:"results" json:"results"`
 
 	// Статус и модерация
-	Status        string `bson:"status" json:"status"` // draft, active, completed, cancelled
+	Status        string `bson:"status" json:"status"` // draft, pending, rejected, active, completed, cancelled
 	IsVerified    bool   `bson:"is_verified" json:"is_verified"`
 	ModeratorNote string `bson:"moderator_note,omitempty" json:"moderator_note,omitempty"`
 
+	// Премодерация опросов пользователей: черновик отправляется на проверку (pending),
+	// модератор одобряет (active) или отклоняет (rejected) с заметкой ModeratorNote
+	SubmittedAt *time.Time          `bson:"submitted_at,omitempty" json:"submitted_at,omitempty"`
+	ModeratedBy *primitive.ObjectID `bson:"moderated_by,omitempty" json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time          `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+
 	// Метаданные
 	ViewCount   int        `bson:"view_count" json:"view_count"`
 	ShareCount  int        `bson:"share_count" json:"share_count"`
@@ -50,17 +65,71 @@ type Poll struct {
 	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
 	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+
+	// Заброшенный черновик: продление автором и предупреждение об удалении
+	DraftExtendedAt *time.Time `bson:"draft_extended_at,omitempty" json:"draft_extended_at,omitempty"`
+	DraftWarnedAt   *time.Time `bson:"draft_warned_at,omitempty" json:"draft_warned_at,omitempty"`
 }
 
 type PollQuestion struct {
 	ID         primitive.ObjectID `bson:"id" json:"id"`
 	Text       string             `bson:"text" json:"text" validate:"required,min=5,max=500"`
-	Type       string             `bson:"type" json:"type" validate:"required,oneof=single_choice multiple_choice rating text scale yes_no"`
+	Type       string             `bson:"type" json:"type" validate:"required,oneof=single_choice multiple_choice rating text scale yes_no ranking"`
 	Options    []PollOption       `bson:"options,omitempty" json:"options,omitempty"`
 	IsRequired bool               `bson:"is_required" json:"is_required"`
 	MinRating  int                `bson:"min_rating,omitempty" json:"min_rating,omitempty"` // Для rating/scale
 	MaxRating  int                `bson:"max_rating,omitempty" json:"max_rating,omitempty"` // Для rating/scale
 	MaxLength  int                `bson:"max_length,omitempty" json:"max_length,omitempty"` // Для text
+	RankLimit  int                `bson:"rank_limit,omitempty" json:"rank_limit,omitempty"` // Для ranking: сколько позиций ранжировать (0 - все)
+	Media      *QuestionMedia     `bson:"media,omitempty" json:"media,omitempty"`           // Иллюстрации к вопросу (варианты дизайна и т.п.)
+	ShowIf     *QuestionCondition `bson:"show_if,omitempty" json:"show_if,omitempty"`       // Вопрос задается, только если выполнено условие
+}
+
+// QuestionCondition - условие показа вопроса по ответу на один из предыдущих вопросов
+// ("Если да, то на какой улице?"). Заполняется поле, соответствующее типу того вопроса.
+type QuestionCondition struct {
+	QuestionID primitive.ObjectID   `bson:"question_id" json:"question_id"`
+	OptionIDs  []primitive.ObjectID `bson:"option_ids,omitempty" json:"option_ids,omitempty"`   // Выбор: выбран любой из вариантов
+	BoolAnswer *bool                `bson:"bool_answer,omitempty" json:"bool_answer,omitempty"` // Да/нет
+	MinValue   *int                 `bson:"min_value,omitempty" json:"min_value,omitempty"`     // Оценка/шкала: не меньше
+	MaxValue   *int                 `bson:"max_value,omitempty" json:"max_value,omitempty"`     // Оценка/шкала: не больше
+}
+
+// Matches проверяет ответ на вопрос, к которому относится условие
+func (c *QuestionCondition) Matches(answer PollAnswer) bool {
+	switch {
+	case len(c.OptionIDs) > 0:
+		for _, selected := range answer.OptionIDs {
+			for _, optionID := range c.OptionIDs {
+				if selected == optionID {
+					return true
+				}
+			}
+		}
+		return false
+	case c.BoolAnswer != nil:
+		return answer.BoolAnswer != nil && *answer.BoolAnswer == *c.BoolAnswer
+	default:
+		if answer.NumberAnswer == nil {
+			return false
+		}
+		value := *answer.NumberAnswer
+		return (c.MinValue == nil || value >= *c.MinValue) && (c.MaxValue == nil || value <= *c.MaxValue)
+	}
+}
+
+// Максимум изображений в одном вопросе
+const MaxQuestionImages = 5
+
+// QuestionMedia - изображения или короткое видео к вопросу
+type QuestionMedia struct {
+	Images   []string `bson:"images,omitempty" json:"images,omitempty"`
+	VideoURL string   `bson:"video_url,omitempty" json:"video_url,omitempty"`
+}
+
+// IsEmpty проверяет, что к вопросу не прикреплено медиа
+func (m *QuestionMedia) IsEmpty() bool {
+	return m == nil || (len(m.Images) == 0 && m.VideoURL == "")
 }
 
 type PollOption struct {
@@ -69,16 +138,20 @@ type PollOption struct {
 	Image string             `bson:"image,omitempty" json:"image,omitempty"`
 }
 
+// PollResponse - ответ пользователя (коллекция poll_responses). UserID хранится и в анонимных
+// опросах: по нему действует правило одного ответа и обезличивание аккаунта, наружу он не отдается.
 type PollResponse struct {
-	ID          primitive.ObjectID `bson:"id" json:"id"`
-	PollID      primitive.ObjectID `bson:"poll_id" json:"poll_id"`
-	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Answers     []PollAnswer       `bson:"answers" json:"answers"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
-	SubmittedAt time.Time          `bson:"submitted_at" json:"submitted_at"`
-	UserAgent   string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
-	IPAddress   string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	PollID       primitive.ObjectID `bson:"poll_id" json:"poll_id"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UniqueVote   bool               `bson:"unique_vote,omitempty" json:"-"`   // Опрос без AllowMultiple: ответ входит в уникальный индекс (poll_id, user_id)
+	IdentityHash string             `bson:"identity_hash,omitempty" json:"-"` // Опрос VerifiedVotersOnly: уникальный индекс (poll_id, identity_hash)
+	Answers      []PollAnswer       `bson:"answers" json:"answers"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	SubmittedAt  time.Time          `bson:"submitted_at" json:"submitted_at"`
+	UserAgent    string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IPAddress    string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
 }
 
 type PollAnswer struct {
@@ -117,6 +190,17 @@ type OptionResult struct {
 	Percentage float64            `bson:"percentage" json:"percentage"`
 }
 
+// RankingResult - итог варианта в вопросе с ранжированием
+type RankingResult struct {
+	OptionID        primitive.ObjectID `bson:"option_id" json:"option_id"`
+	OptionText      string             `bson:"option_text" json:"option_text"`
+	Position        int                `bson:"position" json:"position"`                   // Итоговое место по сумме баллов Борда
+	BordaScore      int                `bson:"borda_score" json:"borda_score"`             // Сумма баллов Борда
+	AverageRank     *float64           `bson:"average_rank,omitempty" json:"average_rank"` // Средняя позиция среди тех, кто ранжировал вариант
+	RankedCount     int                `bson:"ranked_count" json:"ranked_count"`           // Сколько участников поставили вариант в рейтинг
+	FirstPlaceCount int                `bson:"first_place_count" json:"first_place_count"` // Сколько раз вариант занял первое место
+}
+
 type Demographics struct {
 	AgeGroups      map[string]int `bson:"age_groups,omitempty" json:"age_groups,omitempty"`
 	LocationGroups map[string]int `bson:"location_groups,omitempty" json:"location_groups,omitempty"`
@@ -128,6 +212,25 @@ type AgeRestriction struct {
 	MaxAge int `bson:"max_age" json:"max_age" validate:"min=0,max=120"`
 }
 
+// Validate проверяет границы ограничения: MaxAge 0 - без верхней границы
+func (r *AgeRestriction) Validate() error {
+	if r.MinAge < 0 || r.MinAge > 120 || r.MaxAge < 0 || r.MaxAge > 120 {
+		return fmt.Errorf("age limits must be between 0 and 120")
+	}
+	if r.MaxAge != 0 && r.MaxAge < r.MinAge {
+		return fmt.Errorf("max_age must not be less than min_age")
+	}
+	return nil
+}
+
+// Allows - возраст в допустимых пределах; MaxAge 0 - без верхней границы
+func (r *AgeRestriction) Allows(age int) bool {
+	if age < r.MinAge {
+		return false
+	}
+	return r.MaxAge == 0 || age <= r.MaxAge
+}
+
 type Answer struct {
 	QuestionID      primitive.ObjectID   `bson:"question_id" json:"question_id"`
 	SelectedOptions []primitive.ObjectID `bson:"selected_options,omitempty" json:"selected_options,omitempty"`
@@ -141,8 +244,23 @@ const (
 	PollStatusActive    = "active"
 	PollStatusCompleted = "completed"
 	PollStatusCancelled = "cancelled"
+	PollStatusPending   = "pending"  // Ожидает модерации (опрос пользователя)
+	PollStatusRejected  = "rejected" // Отклонен модератором, автор может исправить и отправить снова
 )
 
+// PollModerationStatuses - опросы на модерации и отклоненные видят только автор и модераторы
+var PollModerationStatuses = []string{PollStatusPending, PollStatusRejected}
+
+// InModeration проверяет, что опрос еще не прошел модерацию
+func (p *Poll) InModeration() bool {
+	return p.Status == PollStatusPending || p.Status == PollStatusRejected
+}
+
+// CanSubmitForModeration - черновик или отклоненный опрос можно отправить на проверку
+func (p *Poll) CanSubmitForModeration() bool {
+	return p.Status == PollStatusDraft || p.Status == PollStatusRejected
+}
+
 // Типы вопросов
 const (
 	QuestionTypeSingleChoice   = "single_choice"
@@ -151,6 +269,7 @@ const (
 	QuestionTypeText           = "text"
 	QuestionTypeScale          = "scale"
 	QuestionTypeYesNo          = "yes_no"
+	QuestionTypeRanking        = "ranking" // Упорядочивание вариантов (партисипативный бюджет, приоритеты)
 )
 
 // Категории опросов
@@ -203,22 +322,127 @@ func (p *Poll) CanUserParticipate(user User) bool {
 	return true
 }
 
-func (p *Poll) HasUserResponded(userID primitive.ObjectID) bool {
-	for _, response := range p.Responses {
-		if response.UserID == userID {
-			return true
+// CalculateResults пересчитывает сохраняемые итоги (results) по ответам опроса из poll_responses.
+// Для ранжирования Count варианта - сумма баллов Борда, варианты идут в порядке итоговых мест.
+func (p *Poll) CalculateResults(responses []PollResponse) PollResults {
+	results := PollResults{
+		QuestionResults: make([]QuestionResult, 0, len(p.Questions)),
+		Demographics:    p.Results.Demographics,
+		UpdatedAt:       time.Now(),
+	}
+
+	for _, question := range p.Questions {
+		result := QuestionResult{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			QuestionType: question.Type,
 		}
+
+		switch question.Type {
+		case QuestionTypeSingleChoice, QuestionTypeMultipleChoice:
+			counts := make(map[primitive.ObjectID]int, len(question.Options))
+			for _, response := range responses {
+				for _, answer := range response.Answers {
+					if answer.QuestionID != question.ID {
+						continue
+					}
+					for _, optionID := range answer.OptionIDs {
+						counts[optionID]++
+					}
+				}
+			}
+			for _, option := range question.Options {
+				result.OptionResults = append(result.OptionResults, OptionResult{
+					OptionID:   option.ID,
+					OptionText: option.Text,
+					Count:      counts[option.ID],
+				})
+				result.TotalAnswers += counts[option.ID]
+			}
+			setOptionPercentages(result.OptionResults, result.TotalAnswers)
+
+		case QuestionTypeText:
+			for _, response := range responses {
+				for _, answer := range response.Answers {
+					if answer.QuestionID == question.ID && answer.TextAnswer != "" {
+						result.TextAnswers = append(result.TextAnswers, answer.TextAnswer)
+					}
+				}
+			}
+			result.TotalAnswers = len(result.TextAnswers)
+
+		case QuestionTypeRating, QuestionTypeScale:
+			var values []int
+			for _, response := range responses {
+				for _, answer := range response.Answers {
+					if answer.QuestionID == question.ID && answer.NumberAnswer != nil {
+						values = append(values, *answer.NumberAnswer)
+					}
+				}
+			}
+			result.TotalAnswers = len(values)
+			if len(values) > 0 {
+				sort.Ints(values)
+				sum := 0
+				for _, value := range values {
+					sum += value
+				}
+				average := math.Round(float64(sum)/float64(len(values))*100) / 100
+				median := float64(values[len(values)/2])
+				if len(values)%2 == 0 {
+					median = float64(values[len(values)/2-1]+values[len(values)/2]) / 2
+				}
+				minValue, maxValue := values[0], values[len(values)-1]
+				result.AverageRating = &average
+				result.MedianValue = &median
+				result.MinValue = &minValue
+				result.MaxValue = &maxValue
+			}
+
+		case QuestionTypeYesNo:
+			for _, response := range responses {
+				for _, answer := range response.Answers {
+					if answer.QuestionID != question.ID || answer.BoolAnswer == nil {
+						continue
+					}
+					if *answer.BoolAnswer {
+						result.YesCount++
+					} else {
+						result.NoCount++
+					}
+				}
+			}
+			result.TotalAnswers = result.YesCount + result.NoCount
+
+		case QuestionTypeRanking:
+			ranking, total := question.RankingResults(responses)
+			totalScore := 0
+			for _, option := range ranking {
+				result.OptionResults = append(result.OptionResults, OptionResult{
+					OptionID:   option.OptionID,
+					OptionText: option.OptionText,
+					Count:      option.BordaScore,
+				})
+				totalScore += option.BordaScore
+			}
+			setOptionPercentages(result.OptionResults, totalScore)
+			result.TotalAnswers = total
+		}
+
+		results.QuestionResults = append(results.QuestionResults, result)
 	}
-	return false
+
+	return results
 }
 
-func (p *Poll) GetResponseByUser(userID primitive.ObjectID) *PollResponse {
-	for i, response := range p.Responses {
-		if response.UserID == userID {
-			return &p.Responses[i]
-		}
+// setOptionPercentages заполняет доли вариантов с точностью до сотых
+func setOptionPercentages(options []OptionResult, total int) {
+	if total == 0 {
+		return
+	}
+	for i := range options {
+		options[i].Percentage = math.Round(float64(options[i].Count)/float64(total)*10000) / 100
 	}
-	return nil
 }
 
 func (q *PollQuestion) ValidateQuestion() error {
@@ -261,6 +485,17 @@ func (q *PollQuestion) ValidateQuestion() error {
 		if len(q.Options) > 0 {
 			return fmt.Errorf("yes/no questions should not have options")
 		}
+
+	case QuestionTypeRanking:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("ranking questions must have at least 2 options")
+		}
+		if len(q.Options) > 20 {
+			return fmt.Errorf("too many options (max 20)")
+		}
+		if q.RankLimit < 0 || q.RankLimit > len(q.Options) {
+			return fmt.Errorf("rank_limit must be between 0 and %d", len(q.Options))
+		}
 	}
 
 	return nil
@@ -306,11 +541,166 @@ func (q *PollQuestion) ValidateAnswer(answer PollAnswer) error {
 		if answer.BoolAnswer == nil && q.IsRequired {
 			return fmt.Errorf("yes/no question requires a boolean answer")
 		}
+
+	case QuestionTypeRanking:
+		// Порядок option_ids - это ранжирование: первый элемент - первое место
+		if len(answer.OptionIDs) != q.RankPositions() {
+			return fmt.Errorf("ranking question requires exactly %d ranked options", q.RankPositions())
+		}
+		seen := make(map[primitive.ObjectID]bool, len(answer.OptionIDs))
+		for _, optionID := range answer.OptionIDs {
+			if !q.isValidOptionID(optionID) {
+				return fmt.Errorf("invalid option selected")
+			}
+			if seen[optionID] {
+				return fmt.Errorf("each option can be ranked only once")
+			}
+			seen[optionID] = true
+		}
 	}
 
 	return nil
 }
 
+// ValidateBranching проверяет условия показа вопросов: условие ссылается на один из предыдущих
+// вопросов, поддерживающий ветвление, и задано полем, соответствующим его типу
+func (p *Poll) ValidateBranching() error {
+	index := make(map[primitive.ObjectID]int, len(p.Questions))
+	for i, question := range p.Questions {
+		index[question.ID] = i
+	}
+
+	for i, question := range p.Questions {
+		condition := question.ShowIf
+		if condition == nil {
+			continue
+		}
+		j, ok := index[condition.QuestionID]
+		if !ok || j >= i {
+			return fmt.Errorf("question '%s': condition must refer to a previous question", question.Text)
+		}
+		source := &p.Questions[j]
+
+		switch source.Type {
+		case QuestionTypeSingleChoice, QuestionTypeMultipleChoice:
+			if len(condition.OptionIDs) == 0 || condition.BoolAnswer != nil || condition.MinValue != nil || condition.MaxValue != nil {
+				return fmt.Errorf("question '%s': condition on a choice question requires option_ids only", question.Text)
+			}
+			for _, optionID := range condition.OptionIDs {
+				if !source.isValidOptionID(optionID) {
+					return fmt.Errorf("question '%s': condition refers to an unknown option", question.Text)
+				}
+			}
+
+		case QuestionTypeYesNo:
+			if condition.BoolAnswer == nil || len(condition.OptionIDs) > 0 || condition.MinValue != nil || condition.MaxValue != nil {
+				return fmt.Errorf("question '%s': condition on a yes/no question requires bool_answer only", question.Text)
+			}
+
+		case QuestionTypeRating, QuestionTypeScale:
+			if (condition.MinValue == nil && condition.MaxValue == nil) || len(condition.OptionIDs) > 0 || condition.BoolAnswer != nil {
+				return fmt.Errorf("question '%s': condition on a rating question requires min_value or max_value", question.Text)
+			}
+			if condition.MinValue != nil && condition.MaxValue != nil && *condition.MinValue > *condition.MaxValue {
+				return fmt.Errorf("question '%s': condition min_value cannot exceed max_value", question.Text)
+			}
+
+		default:
+			return fmt.Errorf("question '%s': conditions on %s questions are not supported", question.Text, source.Type)
+		}
+	}
+	return nil
+}
+
+// VisibleQuestions определяет по ответам, какие вопросы были показаны участнику. Вопрос с условием
+// показан, если показан и отвечен вопрос из условия и ответ совпал с условием.
+func (p *Poll) VisibleQuestions(answers []PollAnswer) map[primitive.ObjectID]bool {
+	answered := make(map[primitive.ObjectID]PollAnswer, len(answers))
+	for _, answer := range answers {
+		answered[answer.QuestionID] = answer
+	}
+
+	visible := make(map[primitive.ObjectID]bool, len(p.Questions))
+	for _, question := range p.Questions {
+		if question.ShowIf == nil {
+			visible[question.ID] = true
+			continue
+		}
+		answer, ok := answered[question.ShowIf.QuestionID]
+		visible[question.ID] = ok && visible[question.ShowIf.QuestionID] && question.ShowIf.Matches(answer)
+	}
+	return visible
+}
+
+// RankPositions возвращает количество позиций, которые нужно заполнить в вопросе с ранжированием
+func (q *PollQuestion) RankPositions() int {
+	if q.RankLimit > 0 && q.RankLimit < len(q.Options) {
+		return q.RankLimit
+	}
+	return len(q.Options)
+}
+
+// RankingResults подсчитывает итоги вопроса с ранжированием.
+// Баллы Борда: вариант на позиции i (с 0) получает len(options) - i баллов,
+// не попавшие в частичный рейтинг варианты получают 0.
+func (q *PollQuestion) RankingResults(responses []PollResponse) ([]RankingResult, int) {
+	n := len(q.Options)
+	results := make([]RankingResult, n)
+	index := make(map[primitive.ObjectID]int, n)
+	rankSums := make([]int, n)
+	for i, option := range q.Options {
+		results[i] = RankingResult{OptionID: option.ID, OptionText: option.Text}
+		index[option.ID] = i
+	}
+
+	total := 0
+	for _, response := range responses {
+		for _, answer := range response.Answers {
+			if answer.QuestionID != q.ID || len(answer.OptionIDs) == 0 {
+				continue
+			}
+			total++
+			for position, optionID := range answer.OptionIDs {
+				i, ok := index[optionID]
+				if !ok {
+					continue
+				}
+				results[i].BordaScore += n - position
+				results[i].RankedCount++
+				rankSums[i] += position + 1
+				if position == 0 {
+					results[i].FirstPlaceCount++
+				}
+			}
+		}
+	}
+
+	return finishRanking(results, rankSums), total
+}
+
+// finishRanking считает среднее место и расставляет варианты по сумме баллов Борда
+// (при равенстве выше вариант с большим числом первых мест)
+func finishRanking(results []RankingResult, rankSums []int) []RankingResult {
+	for i := range results {
+		if results[i].RankedCount > 0 {
+			average := float64(rankSums[i]) / float64(results[i].RankedCount)
+			average = math.Round(average*100) / 100
+			results[i].AverageRank = &average
+		}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].BordaScore != results[b].BordaScore {
+			return results[a].BordaScore > results[b].BordaScore
+		}
+		return results[a].FirstPlaceCount > results[b].FirstPlaceCount
+	})
+	for i := range results {
+		results[i].Position = i + 1
+	}
+	return results
+}
+
 func (q *PollQuestion) isValidOptionID(optionID primitive.ObjectID) bool {
 	for _, option := range q.Options {
 		if option.ID == optionID {
@@ -0,0 +1,127 @@
+// internal/models/poll_result.go
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Сколько последних текстовых ответов на вопрос возвращают итоги опроса
+const MaxResultTextAnswers = 100
+
+// PollTally - кэш итогов опроса (коллекция poll_results, _id - ID опроса). Счетчики
+// увеличиваются вместе с каждым ответом ($inc); отсутствующий документ собирается
+// агрегацией по poll_responses.
+type PollTally struct {
+	PollID         primitive.ObjectID       `bson:"_id" json:"poll_id"`
+	TotalResponses int                      `bson:"total_responses" json:"total_responses"`
+	Questions      map[string]QuestionTally `bson:"questions" json:"questions"` // Ключ - hex ID вопроса
+	RebuiltAt      time.Time                `bson:"rebuilt_at" json:"rebuilt_at"`
+	UpdatedAt      time.Time                `bson:"updated_at" json:"updated_at"`
+}
+
+// QuestionTally - счетчики ответов на вопрос; ключи карт - hex ID вариантов или значение оценки
+type QuestionTally struct {
+	Answers    int            `bson:"answers,omitempty" json:"answers,omitempty"`         // Текст и ранжирование: число ответов
+	Options    map[string]int `bson:"options,omitempty" json:"options,omitempty"`         // Выбор варианта: голоса
+	Values     map[string]int `bson:"values,omitempty" json:"values,omitempty"`           // Оценка и шкала: распределение значений
+	Yes        int            `bson:"yes,omitempty" json:"yes,omitempty"`                 // Да/нет
+	No         int            `bson:"no,omitempty" json:"no,omitempty"`                   // Да/нет
+	Ranked     map[string]int `bson:"ranked,omitempty" json:"ranked,omitempty"`           // Ранжирование: сколько раз вариант попал в рейтинг
+	RankSum    map[string]int `bson:"rank_sum,omitempty" json:"rank_sum,omitempty"`       // Ранжирование: сумма мест (с 1)
+	FirstPlace map[string]int `bson:"first_place,omitempty" json:"first_place,omitempty"` // Ранжирование: первые места
+}
+
+// TextAnswer - текстовый ответ в итогах опроса
+type TextAnswer struct {
+	Text      string    `bson:"text" json:"text"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// TallyIncrements - $inc для кэша итогов от одного ответа; правила подсчета совпадают
+// с агрегацией, которой кэш собирается заново
+func (p *Poll) TallyIncrements(response *PollResponse) map[string]int {
+	types := make(map[primitive.ObjectID]string, len(p.Questions))
+	for _, question := range p.Questions {
+		types[question.ID] = question.Type
+	}
+
+	inc := map[string]int{"total_responses": 1}
+	for _, answer := range response.Answers {
+		prefix := "questions." + answer.QuestionID.Hex() + "."
+		switch types[answer.QuestionID] {
+		case QuestionTypeSingleChoice, QuestionTypeMultipleChoice:
+			for _, optionID := range answer.OptionIDs {
+				inc[prefix+"options."+optionID.Hex()]++
+			}
+		case QuestionTypeText:
+			if answer.TextAnswer != "" {
+				inc[prefix+"answers"]++
+			}
+		case QuestionTypeRating, QuestionTypeScale:
+			if answer.NumberAnswer != nil {
+				inc[prefix+"values."+strconv.Itoa(*answer.NumberAnswer)]++
+			}
+		case QuestionTypeYesNo:
+			if answer.BoolAnswer == nil {
+				continue
+			}
+			if *answer.BoolAnswer {
+				inc[prefix+"yes"]++
+			} else {
+				inc[prefix+"no"]++
+			}
+		case QuestionTypeRanking:
+			if len(answer.OptionIDs) == 0 {
+				continue
+			}
+			inc[prefix+"answers"]++
+			for position, optionID := range answer.OptionIDs {
+				inc[prefix+"ranked."+optionID.Hex()]++
+				inc[prefix+"rank_sum."+optionID.Hex()] += position + 1
+				if position == 0 {
+					inc[prefix+"first_place."+optionID.Hex()]++
+				}
+			}
+		}
+	}
+	return inc
+}
+
+// TallyChanges - $inc для кэша итогов при изменении ответа: вклад нового ответа минус вклад
+// прежнего. Число ответов не меняется, нулевые изменения не попадают в результат.
+func (p *Poll) TallyChanges(before, after *PollResponse) map[string]int {
+	inc := p.TallyIncrements(after)
+	for key, n := range p.TallyIncrements(before) {
+		inc[key] -= n
+	}
+	for key, n := range inc {
+		if n == 0 {
+			delete(inc, key)
+		}
+	}
+	return inc
+}
+
+// RankingFromTally - итоги вопроса с ранжированием по счетчикам кэша (те же баллы Борда,
+// что и RankingResults: вариант на месте i с 0 получает len(options) - i баллов)
+func (q *PollQuestion) RankingFromTally(tally QuestionTally) ([]RankingResult, int) {
+	n := len(q.Options)
+	results := make([]RankingResult, n)
+	rankSums := make([]int, n)
+	for i, option := range q.Options {
+		key := option.ID.Hex()
+		ranked := tally.Ranked[key]
+		results[i] = RankingResult{
+			OptionID:        option.ID,
+			OptionText:      option.Text,
+			BordaScore:      n*ranked - (tally.RankSum[key] - ranked),
+			RankedCount:     ranked,
+			FirstPlaceCount: tally.FirstPlace[key],
+		}
+		rankSums[i] = tally.RankSum[key]
+	}
+	return finishRanking(results, rankSums), tally.Answers
+}
@@ -0,0 +1,41 @@
+// internal/models/poll_summary.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Скільки символів опису потрапляє у картку списку
+const PollSummaryDescriptionLength = 280
+
+// PollSummary - картка опитування для списків (колекція poll_summaries).
+// Оновлюється при кожному записі в опитування; замість вкладених відповідей,
+// питань і результатів містить лише лічильники.
+type PollSummary struct {
+	ID                 primitive.ObjectID `bson:"_id" json:"id"`
+	CommunityID        primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	CreatorID          primitive.ObjectID `bson:"creator_id" json:"creator_id"`
+	Title              string             `bson:"title" json:"title"`
+	Description        string             `bson:"description" json:"description"` // Перші PollSummaryDescriptionLength символів
+	Language           string             `bson:"language" json:"language,omitempty"`
+	Category           string             `bson:"category" json:"category"`
+	Status             string             `bson:"status" json:"status"`
+	IsVerified         bool               `bson:"is_verified" json:"is_verified"`
+	IsAnonymous        bool               `bson:"is_anonymous" json:"is_anonymous"`
+	IsPublic           bool               `bson:"is_public" json:"is_public"`
+	AllowMultiple      bool               `bson:"allow_multiple" json:"allow_multiple"`
+	VerifiedVotersOnly bool               `bson:"verified_voters_only" json:"verified_voters_only"`
+	StartDate          time.Time          `bson:"start_date" json:"start_date"`
+	EndDate            time.Time          `bson:"end_date" json:"end_date"`
+	Tags               []string           `bson:"tags" json:"tags"`
+	QuestionCount      int                `bson:"question_count" json:"question_count"`
+	TotalResponses     int                `bson:"total_responses" json:"total_responses"`
+	ViewCount          int                `bson:"view_count" json:"view_count"`
+	ShareCount         int                `bson:"share_count" json:"share_count"`
+	CreatedAt          time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time          `bson:"updated_at" json:"updated_at"`
+	PublishedAt        *time.Time         `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	SyncedAt           time.Time          `bson:"synced_at" json:"-"`
+}
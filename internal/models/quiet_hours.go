@@ -0,0 +1,94 @@
+// internal/models/quiet_hours.go
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours - тихі години, протягом яких неекстрені push-сповіщення відкладаються до їх
+// завершення. Вікно може переходити через північ (22:00-07:00). Сповіщення в застосунку
+// з'являються одразу, відкладається лише push.
+type QuietHours struct {
+	Enabled bool   `bson:"enabled" json:"enabled"`
+	Start   string `bson:"start" json:"start"` // HH:MM
+	End     string `bson:"end" json:"end"`     // HH:MM
+	// Часовий пояс IANA (Europe/Kyiv); порожньо - часовий пояс міста з конфігурації
+	Timezone string `bson:"timezone,omitempty" json:"timezone,omitempty"`
+}
+
+// Validate перевіряє формат вікна та часовий пояс увімкнених тихих годин
+func (q QuietHours) Validate() error {
+	if !q.Enabled {
+		return nil
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("start must be in HH:MM format")
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return fmt.Errorf("end must be in HH:MM format")
+	}
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", q.Timezone)
+		}
+	}
+	return nil
+}
+
+// Until повертає момент завершення тихих годин, якщо now потрапляє у вікно.
+// fallback - часовий пояс для налаштувань без власного часового поясу.
+func (q QuietHours) Until(now time.Time, fallback *time.Location) (time.Time, bool) {
+	if !q.Enabled {
+		return time.Time{}, false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(q.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	loc := fallback
+	if q.Timezone != "" {
+		if l, err := time.LoadLocation(q.Timezone); err == nil {
+			loc = l
+		}
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	inside := minute >= start && minute < end
+	if start > end {
+		inside = minute >= start || minute < end
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	// time.Date враховує перехід на літній/зимовий час у день завершення
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = time.Date(local.Year(), local.Month(), local.Day()+1, end/60, end%60, 0, 0, loc)
+	}
+	return until, true
+}
+
+// parseClock перетворює HH:MM на хвилини від початку доби
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
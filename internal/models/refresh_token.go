@@ -0,0 +1,24 @@
+// internal/models/refresh_token.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken - довгоживучий токен для отримання нових access-токенів (колекція refresh_tokens).
+// Зберігається лише хеш токена. Кожне оновлення видає новий токен і відкликає попередній;
+// повторне використання відкликаного токена відкликає весь ланцюжок (FamilyID).
+type RefreshToken struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	FamilyID   primitive.ObjectID  `bson:"family_id" json:"family_id"` // Ланцюжок токенів одного входу
+	TokenHash  string              `bson:"token_hash" json:"-"`
+	UserAgent  string              `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IP         string              `bson:"ip,omitempty" json:"ip,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time           `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	ReplacedBy *primitive.ObjectID `bson:"replaced_by,omitempty" json:"replaced_by,omitempty"` // Наступний токен ланцюжка
+}
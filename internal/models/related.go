@@ -0,0 +1,44 @@
+// internal/models/related.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Чому матеріал вважається пов'язаним
+const (
+	RelatedReasonCategory = "same_category"
+	RelatedReasonTags     = "shared_tags"
+	RelatedReasonNearby   = "nearby"
+	RelatedReasonText     = "similar_text"
+)
+
+// Скільки пов'язаних матеріалів показується в картці
+const MaxRelatedItems = 5
+
+// RelatedItem - пов'язаний матеріал іншого або того самого модуля ("люди також повідомляли")
+type RelatedItem struct {
+	Module         string             `bson:"module" json:"module"` // city_issues, petitions, announcements
+	ID             primitive.ObjectID `bson:"id" json:"id"`
+	Slug           string             `bson:"slug,omitempty" json:"slug,omitempty"`
+	Title          string             `bson:"title" json:"title"`
+	Category       string             `bson:"category" json:"category"`
+	Status         string             `bson:"status" json:"status"`
+	Score          float64            `bson:"score" json:"score"`
+	Reasons        []string           `bson:"reasons" json:"reasons"`
+	DistanceMeters *float64           `bson:"distance_meters,omitempty" json:"distance_meters,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// RelatedContent - підбірка для одного матеріалу (колекція related_content),
+// яку перераховує фонове завдання
+type RelatedContent struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Module      string             `bson:"module" json:"module"`
+	ItemID      primitive.ObjectID `bson:"item_id" json:"item_id"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+	Items       []RelatedItem      `bson:"items" json:"items"`
+	ComputedAt  time.Time          `bson:"computed_at" json:"computed_at"`
+}
@@ -0,0 +1,113 @@
+// internal/models/resident.go
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Рівні верифікації користувача
+const (
+	VerificationLevelBasic    = "basic"    // Звичайний акаунт (за замовчуванням)
+	VerificationLevelResident = "resident" // Підтверджена картка жителя громади
+)
+
+// Провайдери підтвердження особи
+const (
+	IdentityProviderDiia   = "diia"
+	IdentityProviderBankID = "bankid"
+)
+
+// Статуси картки жителя
+const (
+	ResidentCardPending  = "pending"  // Очікує перевірки адміністратором
+	ResidentCardVerified = "verified" // Підтверджена реєстром або адміністратором
+	ResidentCardRejected = "rejected" // Не знайдена в реєстрі або відхилена
+	ResidentCardRevoked  = "revoked"  // Відкликана адміністратором
+)
+
+// Допустима довжина номера картки після нормалізації
+const (
+	MinResidentCardNumberLength = 6
+	MaxResidentCardNumberLength = 20
+)
+
+// ResidentCard - прив'язана до акаунта картка жителя (ID-картка громади).
+// Номер зберігається лише як HMAC (перевірка, що картку не прив'язано до іншого акаунта)
+// та в маскованому вигляді для показу.
+type ResidentCard struct {
+	NumberHash   string `bson:"number_hash,omitempty" json:"-"` // Видаляється при відхиленні та відкликанні
+	NumberMasked string `bson:"number_masked" json:"number_masked"`
+	LastName     string `bson:"last_name" json:"last_name"`
+	Status       string `bson:"status" json:"status"`
+
+	// Адаптер реєстру, яким перевірялась картка (manual - без реєстру), та відповідь реєстру
+	Registry          string `bson:"registry" json:"registry"`
+	RegistryResult    string `bson:"registry_result,omitempty" json:"registry_result,omitempty"`
+	RegistryReference string `bson:"registry_reference,omitempty" json:"-"`
+
+	SubmittedAt     time.Time           `bson:"submitted_at" json:"submitted_at"`
+	VerifiedAt      *time.Time          `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	VerifiedBy      *primitive.ObjectID `bson:"verified_by,omitempty" json:"-"` // Порожньо - підтверджено реєстром
+	ExpiresAt       *time.Time          `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	RejectionReason string              `bson:"rejection_reason,omitempty" json:"rejection_reason,omitempty"`
+}
+
+// IsActive перевіряє, що картка підтверджена і не прострочена
+func (rc *ResidentCard) IsActive(now time.Time) bool {
+	if rc == nil || rc.Status != ResidentCardVerified {
+		return false
+	}
+	return rc.ExpiresAt == nil || now.Before(*rc.ExpiresAt)
+}
+
+// IsVerifiedResident - чи доступні користувачу функції лише для жителів
+// (голосування за громадський бюджет, пільговий проїзд)
+func (u *User) IsVerifiedResident(now time.Time) bool {
+	return u.VerificationLevel == VerificationLevelResident && u.ResidentCard.IsActive(now)
+}
+
+// HasVerifiedIdentity - акаунт верифіковано з підтвердженою особою (Дія/BankID)
+func (u *User) HasVerifiedIdentity() bool {
+	return u.IsVerified && u.IdentityHash != ""
+}
+
+// NormalizeTaxNumber прибирає пробіли з РНОКПП. Повертає порожній рядок, якщо це не 10 цифр.
+func NormalizeTaxNumber(number string) string {
+	number = strings.ReplaceAll(strings.TrimSpace(number), " ", "")
+	if len(number) != 10 {
+		return ""
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return number
+}
+
+// NormalizeResidentCardNumber прибирає пробіли й дефіси та приводить літери до верхнього регістру.
+// Повертає порожній рядок, якщо номер недійсний.
+func NormalizeResidentCardNumber(number string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(number) {
+		switch {
+		case r == ' ' || r == '-':
+		case (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		default:
+			return ""
+		}
+	}
+	if b.Len() < MinResidentCardNumberLength || b.Len() > MaxResidentCardNumberLength {
+		return ""
+	}
+	return b.String()
+}
+
+// MaskResidentCardNumber залишає видимими останні 4 символи номера
+func MaskResidentCardNumber(number string) string {
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
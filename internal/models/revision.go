@@ -0,0 +1,46 @@
+// internal/models/revision.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Типи контенту з історією редагувань
+const (
+	RevisionEntityPetition     = "petition"
+	RevisionEntityAnnouncement = "announcement"
+)
+
+// ContentRevision - знімок тексту опублікованого контенту до редагування (колекція content_revisions).
+// Версія 1 - текст на момент публікації, поточний текст сутності - версія RevisionCount+1.
+type ContentRevision struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	EntityType string             `bson:"entity_type" json:"entity_type"`
+	EntityID   primitive.ObjectID `bson:"entity_id" json:"entity_id"`
+	Version    int                `bson:"version" json:"version"`
+	Fields     map[string]string  `bson:"fields" json:"fields"`           // Текстові поля цієї версії
+	EditedBy   primitive.ObjectID `bson:"edited_by" json:"edited_by"`     // Хто замінив цю версію наступною
+	ReplacedAt time.Time          `bson:"replaced_at" json:"replaced_at"` // Коли версію замінено
+	Changed    []string           `bson:"changed" json:"changed"`         // Поля, змінені в наступній версії
+}
+
+// Операції порівняння текстів
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffOp - фрагмент порівняння двох версій тексту
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// FieldDiff - зміни одного поля між сусідніми версіями
+type FieldDiff struct {
+	Field string   `json:"field"`
+	Ops   []DiffOp `json:"ops"`
+}
@@ -0,0 +1,29 @@
+// internal/models/role_history.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Джерела зміни ролі
+const (
+	RoleChangeSourceAdmin = "admin" // PUT /users/:id/role
+	RoleChangeSourceBulk  = "bulk"  // POST /admin/users/bulk
+	RoleChangeSourceSSO   = "sso"   // Роль з каталогу організації при вході через SSO
+	RoleChangeSourceCLI   = "cli"   // go run ./cmd/admin promote
+)
+
+// Скільки останніх змін ролі зберігається в документі користувача
+const MaxRoleHistory = 100
+
+// RoleChange - запис історії ролей користувача (users.role_history)
+type RoleChange struct {
+	FromRole  string              `bson:"from_role" json:"from_role"`
+	ToRole    string              `bson:"to_role" json:"to_role"`
+	ChangedBy *primitive.ObjectID `bson:"changed_by,omitempty" json:"changed_by,omitempty"` // Порожній для змін через SSO
+	Source    string              `bson:"source" json:"source"`
+	Reason    string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	ChangedAt time.Time           `bson:"changed_at" json:"changed_at"`
+}
@@ -0,0 +1,24 @@
+// internal/models/session.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session - активний вхід користувача на пристрої (колекція sessions).
+// ID сесії збігається з ланцюжком refresh-токенів (RefreshToken.FamilyID) і передається
+// в access-токені (sid), тому відкликання сесії припиняє дію обох токенів.
+type Session struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Device     string             `bson:"device" json:"device"` // Платформа, визначена за User-Agent
+	UserAgent  string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IP         string             `bson:"ip,omitempty" json:"ip,omitempty"` // Остання IP-адреса
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"` // Закінчення refresh-токена
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	Current    bool               `bson:"-" json:"current"` // Сесія, з якої зроблено запит
+}
@@ -0,0 +1,92 @@
+// internal/models/social_import.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Платформы публичных каналов для импорта
+const (
+	SocialPlatformVK       = "vk"
+	SocialPlatformFacebook = "facebook"
+	SocialPlatformTelegram = "telegram"
+)
+
+// Во что превращаются посты канала
+const (
+	SocialImportTargetAnnouncement = "announcement"
+	SocialImportTargetEvent        = "event"
+)
+
+// Результат обработки поста (social_imports.status)
+const (
+	SocialImportImported  = "imported"  // Создан черновик на модерации
+	SocialImportDuplicate = "duplicate" // Тот же текст уже импортирован (кросспостинг)
+	SocialImportSkipped   = "skipped"   // Пост без текста или слишком короткий
+)
+
+// SocialFeed - подключенный модератором публичный канал (social_feeds)
+type SocialFeed struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"`
+
+	Platform string `bson:"platform" json:"platform"` // vk, facebook, telegram
+	Channel  string `bson:"channel" json:"channel"`   // Короткое имя или ID канала на платформе
+	Title    string `bson:"title,omitempty" json:"title,omitempty"`
+	URL      string `bson:"url" json:"url"`
+
+	// Параметры создаваемых черновиков
+	Target       string `bson:"target" json:"target"`     // announcement, event
+	Category     string `bson:"category" json:"category"` // Категория объявления (таксономия) или события
+	Neighborhood string `bson:"neighborhood,omitempty" json:"neighborhood,omitempty"`
+
+	IsActive    bool               `bson:"is_active" json:"is_active"`
+	ConnectedBy primitive.ObjectID `bson:"connected_by" json:"connected_by"` // Автор черновиков
+
+	// Курсор импорта: посты не новее LastPostAt уже обработаны
+	LastPostAt    time.Time  `bson:"last_post_at" json:"last_post_at"`
+	LastFetchedAt *time.Time `bson:"last_fetched_at,omitempty" json:"last_fetched_at,omitempty"`
+	NextFetchAt   time.Time  `bson:"next_fetch_at" json:"next_fetch_at"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+
+	ImportedCount  int `bson:"imported_count" json:"imported_count"`
+	DuplicateCount int `bson:"duplicate_count" json:"duplicate_count"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SocialImport - журнал обработанных постов (social_imports) для дедупликации.
+// Уникальный индекс (platform, post_id) не дает импортировать пост дважды,
+// content_hash находит тот же текст, опубликованный в нескольких каналах.
+type SocialImport struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FeedID      primitive.ObjectID `bson:"feed_id" json:"feed_id"`
+	Platform    string             `bson:"platform" json:"platform"`
+	PostID      string             `bson:"post_id" json:"post_id"`
+	ContentHash string             `bson:"content_hash,omitempty" json:"content_hash,omitempty"`
+
+	Status      string             `bson:"status" json:"status"`
+	ContentType string             `bson:"content_type,omitempty" json:"content_type,omitempty"` // announcement, event
+	ContentID   primitive.ObjectID `bson:"content_id,omitempty" json:"content_id,omitempty"`     // Черновик (для дубликата - ранее импортированный)
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ContentSource - атрибуция импортированного контента: откуда и когда взят пост
+type ContentSource struct {
+	Platform     string             `bson:"platform" json:"platform"`
+	FeedID       primitive.ObjectID `bson:"feed_id" json:"feed_id"`
+	Channel      string             `bson:"channel" json:"channel"`
+	ChannelTitle string             `bson:"channel_title,omitempty" json:"channel_title,omitempty"`
+	PostID       string             `bson:"post_id" json:"post_id"`
+	URL          string             `bson:"url" json:"url"`                                   // Ссылка на оригинальный пост
+	MediaURLs    []string           `bson:"media_urls,omitempty" json:"media_urls,omitempty"` // Вложения поста; в медиа контента не копируются
+	PostedAt     time.Time          `bson:"posted_at" json:"posted_at"`
+	ImportedAt   time.Time          `bson:"imported_at" json:"imported_at"`
+
+	// Дата или время события не найдены в тексте: start_date нужно проверить при модерации
+	NeedsDate bool `bson:"needs_date,omitempty" json:"needs_date,omitempty"`
+}
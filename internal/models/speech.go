@@ -0,0 +1,24 @@
+// internal/models/speech.go
+package models
+
+// Обмеження підказок для синтезу мовлення
+const (
+	MaxPronunciationHints  = 20
+	MaxPronunciationLength = 100
+	MaxPlainTextBodyLength = 4000
+)
+
+// PronunciationHint - як читати слово, яке синтезатор мовлення зазвичай вимовляє неправильно
+// (назви населених пунктів, вулиць, абревіатури)
+type PronunciationHint struct {
+	Term   string `bson:"term" json:"term"`                   // Як написано в тексті: "Нова Каховка"
+	Spoken string `bson:"spoken" json:"spoken"`               // Як читати, з наголосами (U+0301): "Нова́ Кахо́вка"
+	IPA    string `bson:"ipa,omitempty" json:"ipa,omitempty"` // Транскрипція для SSML <phoneme>, якщо відома
+}
+
+// SpeechHints - необов'язкові поля для читачів екрана і голосового помічника.
+// Вбудовується в сповіщення та оголошення; відсутні поля клієнт читає з основного тексту.
+type SpeechHints struct {
+	PlainTextBody  string              `bson:"plain_text_body,omitempty" json:"plain_text_body,omitempty"` // Текст без розмітки, посилань і емодзі
+	Pronunciations []PronunciationHint `bson:"pronunciations,omitempty" json:"pronunciations,omitempty"`
+}
@@ -0,0 +1,38 @@
+// internal/models/stats_history.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatsSnapshot - щоденний знімок ключових показників платформи (колекція stats_history).
+// Один знімок на день: значення на початок дня за місцевим часом сервера.
+type StatsSnapshot struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Date           string             `bson:"date" json:"date"` // YYYY-MM-DD (CalendarDateLayout)
+	Users          int64              `bson:"users" json:"users"`
+	ActiveIssues   int64              `bson:"active_issues" json:"active_issues"` // Повідомлені та в роботі
+	ResolvedIssues int64              `bson:"resolved_issues" json:"resolved_issues"`
+	Signatures     int64              `bson:"signatures" json:"signatures"` // Усього підписів під петиціями
+	TakenAt        time.Time          `bson:"taken_at" json:"taken_at"`
+}
+
+// StatsMetrics - показники знімка, для яких рахується порівняння з минулим роком
+var StatsMetrics = []string{"users", "active_issues", "resolved_issues", "signatures"}
+
+// Metric повертає значення показника знімка за назвою
+func (s *StatsSnapshot) Metric(name string) int64 {
+	switch name {
+	case "users":
+		return s.Users
+	case "active_issues":
+		return s.ActiveIssues
+	case "resolved_issues":
+		return s.ResolvedIssues
+	case "signatures":
+		return s.Signatures
+	}
+	return 0
+}
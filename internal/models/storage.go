@@ -0,0 +1,39 @@
+// internal/models/storage.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModuleCore - службові колекції, що не належать окремому модулю (користувачі, сесії, налаштування)
+const ModuleCore = "core"
+
+// CollectionStorage - розмір однієї колекції MongoDB
+type CollectionStorage struct {
+	Name        string `bson:"name" json:"name"`
+	Module      string `bson:"module" json:"module"`
+	Count       int64  `bson:"count" json:"count"`
+	AvgObjSize  int64  `bson:"avg_obj_size" json:"avg_obj_size"`                   // Середній розмір документа, байт
+	DataSize    int64  `bson:"data_size" json:"data_size"`                         // Розмір документів без стиснення
+	StorageSize int64  `bson:"storage_size" json:"storage_size"`                   // Місце на диску (WiredTiger, зі стисненням)
+	IndexSize   int64  `bson:"index_size" json:"index_size"`                       // Усі індекси колекції
+	MediaFiles  int64  `bson:"media_files,omitempty" json:"media_files,omitempty"` // Посилання на файли медіасховища
+}
+
+// StorageSnapshot - щоденний знімок використання сховища (колекція storage_snapshots) для розрахунку приросту
+type StorageSnapshot struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TakenAt     time.Time           `bson:"taken_at" json:"taken_at"`
+	Collections []CollectionStorage `bson:"collections" json:"collections"`
+	DataSize    int64               `bson:"data_size" json:"data_size"`
+	StorageSize int64               `bson:"storage_size" json:"storage_size"`
+	IndexSize   int64               `bson:"index_size" json:"index_size"`
+	MediaFiles  int64               `bson:"media_files" json:"media_files"`
+}
+
+// UsedBytes - обсяг, який Atlas зараховує до ліміту спільних тарифів (M0-M5): дані без стиснення та індекси
+func (s *StorageSnapshot) UsedBytes() int64 {
+	return s.DataSize + s.IndexSize
+}
@@ -0,0 +1,89 @@
+// internal/models/tag.go
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Обмеження тегів
+const (
+	MaxTagLength     = 50 // Символів у тегу
+	MaxTagsPerEntity = 10 // Тегів на один документ
+)
+
+// Модулі, контент яких має теги
+var TagModules = []string{ModulePetitions, ModulePolls, ModuleEvents}
+
+// IsTagModule перевіряє, що модуль підтримує теги
+func IsTagModule(module string) bool {
+	for _, m := range TagModules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag - канонічний тег міста (колекція tags)
+type Tag struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name string             `bson:"name" json:"name"` // Канонічна форма
+
+	// Попередні назви злитих або перейменованих тегів - нові значення з ними замінюються на Name
+	Aliases []string `bson:"aliases,omitempty" json:"aliases,omitempty"`
+
+	// Кількість документів з тегом за модулями та загалом
+	Usage      map[string]int `bson:"usage" json:"usage"`
+	UsageCount int            `bson:"usage_count" json:"usage_count"`
+
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	LastUsedAt time.Time `bson:"last_used_at" json:"last_used_at"`
+}
+
+// CanonicalTag приводить тег до канонічної форми: нижній регістр, без "#",
+// пробіли замінюються дефісом, інші розділові знаки (крім "-" та "_") відкидаються.
+func CanonicalTag(tag string) string {
+	tag = strings.TrimSpace(strings.ToLower(tag))
+	tag = strings.TrimLeft(tag, "#")
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range tag {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if pendingDash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			pendingDash = true
+		}
+	}
+
+	result := b.String()
+	if utf8.RuneCountInString(result) > MaxTagLength {
+		result = strings.TrimRight(string([]rune(result)[:MaxTagLength]), "-")
+	}
+	return result
+}
+
+// NormalizeTags канонізує теги, прибирає порожні та дублікати (зберігаючи порядок)
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		canonical := CanonicalTag(tag)
+		if canonical == "" || seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		result = append(result, canonical)
+	}
+	return result
+}
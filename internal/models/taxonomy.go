@@ -0,0 +1,173 @@
+// internal/models/taxonomy.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxonomyInterests - довідник інтересів користувачів (User.Interests), керується як таксономія
+const TaxonomyInterests = "interests"
+
+// Модулі, категорії яких керуються через таксономію
+var TaxonomyModules = []string{ModuleCityIssues, ModuleAnnouncements, ModulePolls, ModulePetitions, TaxonomyInterests}
+
+// Модулі, контент яких підбирається за інтересами
+var InterestContentModules = []string{ModuleEvents, ModulePolls, ModuleAnnouncements}
+
+// IsTaxonomyModule перевіряє, що модуль підтримує таксономію категорій
+func IsTaxonomyModule(module string) bool {
+	for _, m := range TaxonomyModules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// Taxonomy - категорія контенту модуля, якою керують адміністратори (колекція taxonomies)
+type Taxonomy struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Module string             `bson:"module" json:"module"` // city_issues, announcements, polls, petitions, interests
+	Code   string             `bson:"code" json:"code"`     // Значення поля category у документах
+
+	// Локалізовані назви: uk, en, ...
+	Names     map[string]string `bson:"names" json:"names"`
+	Icon      string            `bson:"icon,omitempty" json:"icon,omitempty"`
+	SortOrder int               `bson:"sort_order" json:"sort_order"`
+
+	// Лише для інтересів: модуль -> категорії контенту, що відповідають інтересу.
+	// Крім категорій, інтерес збігається з тегом контенту з тим самим кодом.
+	Matches map[string][]string `bson:"matches,omitempty" json:"matches,omitempty"`
+
+	// Неактивні категорії не приймаються для нового контенту, але залишаються в наявних документах
+	IsActive bool `bson:"is_active" json:"is_active"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Name повертає назву мовою lang, за відсутності - українською, інакше код
+func (t *Taxonomy) Name(lang string) string {
+	if name, ok := t.Names[lang]; ok && name != "" {
+		return name
+	}
+	if name, ok := t.Names["uk"]; ok && name != "" {
+		return name
+	}
+	return t.Code
+}
+
+// DefaultTaxonomies - початкові категорії, що раніше були зашиті у валідацію запитів
+func DefaultTaxonomies() []Taxonomy {
+	type item struct {
+		code, uk, en, icon string
+	}
+	build := func(module string, items []item) []Taxonomy {
+		result := make([]Taxonomy, 0, len(items))
+		for i, it := range items {
+			result = append(result, Taxonomy{
+				Module:    module,
+				Code:      it.code,
+				Names:     map[string]string{"uk": it.uk, "en": it.en},
+				Icon:      it.icon,
+				SortOrder: i + 1,
+				IsActive:  true,
+			})
+		}
+		return result
+	}
+
+	var taxonomies []Taxonomy
+	taxonomies = append(taxonomies, build(ModuleCityIssues, []item{
+		{"road", "Дороги", "Roads", "road"},
+		{"lighting", "Освітлення", "Street lighting", "lightbulb"},
+		{"water", "Водопостачання", "Water supply", "water_drop"},
+		{"electricity", "Електропостачання", "Electricity", "bolt"},
+		{"waste", "Сміття", "Waste", "delete"},
+		{"transport", "Транспорт", "Transport", "directions_bus"},
+		{"building", "Будівлі", "Buildings", "apartment"},
+		{"safety", "Безпека", "Safety", "shield"},
+		{"other", "Інше", "Other", "more_horiz"},
+	})...)
+	taxonomies = append(taxonomies, build(ModuleAnnouncements, []item{
+		{"work", "Робота", "Work", "work"},
+		{"help", "Допомога", "Help", "volunteer_activism"},
+		{"services", "Послуги", "Services", "handyman"},
+		{"housing", "Житло", "Housing", "home"},
+		{"transport", "Транспорт", "Transport", "directions_car"},
+	})...)
+	taxonomies = append(taxonomies, build(ModulePolls, []item{
+		{PollCategoryCityPlanning, "Містобудування", "City planning", "location_city"},
+		{PollCategoryTransport, "Транспорт", "Transport", "directions_bus"},
+		{PollCategoryInfrastructure, "Інфраструктура", "Infrastructure", "construction"},
+		{PollCategorySocial, "Соціальна сфера", "Social", "groups"},
+		{PollCategoryEnvironment, "Довкілля", "Environment", "park"},
+		{PollCategoryGovernance, "Врядування", "Governance", "account_balance"},
+		{PollCategoryBudget, "Бюджет", "Budget", "payments"},
+		{PollCategoryEducation, "Освіта", "Education", "school"},
+		{PollCategoryHealthcare, "Охорона здоров'я", "Healthcare", "local_hospital"},
+	})...)
+	taxonomies = append(taxonomies, build(ModulePetitions, []item{
+		{PetitionCategoryInfrastructure, "Інфраструктура", "Infrastructure", "construction"},
+		{PetitionCategorySocial, "Соціальна сфера", "Social", "groups"},
+		{PetitionCategoryEnvironment, "Довкілля", "Environment", "park"},
+		{PetitionCategoryEconomy, "Економіка", "Economy", "trending_up"},
+		{PetitionCategoryGovernance, "Врядування", "Governance", "account_balance"},
+		{PetitionCategorySafety, "Безпека", "Safety", "shield"},
+		{PetitionCategoryTransport, "Транспорт", "Transport", "directions_bus"},
+		{PetitionCategoryEducation, "Освіта", "Education", "school"},
+		{PetitionCategoryHealthcare, "Охорона здоров'я", "Healthcare", "local_hospital"},
+	})...)
+
+	interests := build(TaxonomyInterests, []item{
+		{"culture", "Культура", "Culture", "theater_comedy"},
+		{"education", "Освіта", "Education", "school"},
+		{"sport", "Спорт", "Sport", "sports_soccer"},
+		{"volunteering", "Волонтерство", "Volunteering", "volunteer_activism"},
+		{"business", "Бізнес і робота", "Business and jobs", "work"},
+		{"transport", "Транспорт", "Transport", "directions_bus"},
+		{"environment", "Довкілля", "Environment", "park"},
+		{"city_development", "Розвиток міста", "City development", "location_city"},
+		{"community", "Громадське життя", "Community life", "groups"},
+		{"healthcare", "Здоров'я", "Healthcare", "local_hospital"},
+		{"governance", "Врядування і бюджет", "Governance and budget", "account_balance"},
+		{"housing", "Житло", "Housing", "home"},
+	})
+	interestMatches := map[string]map[string][]string{
+		"culture":          {ModuleEvents: {EventCategoryCultural}},
+		"education":        {ModuleEvents: {EventCategoryEducational, EventCategoryWorkshop}, ModulePolls: {PollCategoryEducation}},
+		"sport":            {ModuleEvents: {EventCategorySports}},
+		"volunteering":     {ModuleEvents: {EventCategoryCharity}, ModuleAnnouncements: {"help"}},
+		"business":         {ModuleEvents: {EventCategoryBusiness, EventCategoryConference}, ModuleAnnouncements: {"work", "services"}},
+		"transport":        {ModulePolls: {PollCategoryTransport}, ModuleAnnouncements: {"transport"}},
+		"environment":      {ModulePolls: {PollCategoryEnvironment}},
+		"city_development": {ModulePolls: {PollCategoryCityPlanning, PollCategoryInfrastructure}},
+		"community":        {ModuleEvents: {EventCategorySocial, EventCategoryMeeting}, ModulePolls: {PollCategorySocial}},
+		"healthcare":       {ModulePolls: {PollCategoryHealthcare}},
+		"governance":       {ModulePolls: {PollCategoryGovernance, PollCategoryBudget}},
+		"housing":          {ModuleAnnouncements: {"housing"}},
+	}
+	for i := range interests {
+		interests[i].Matches = interestMatches[interests[i].Code]
+	}
+	taxonomies = append(taxonomies, interests...)
+
+	return taxonomies
+}
+
+// MatchesContent перевіряє, що контент модуля з категорією та тегами відповідає інтересу
+func (t *Taxonomy) MatchesContent(module, category string, tags []string) bool {
+	for _, code := range t.Matches[module] {
+		if code == category {
+			return true
+		}
+	}
+	for _, tag := range tags {
+		if tag == t.Code {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,7 @@ import (
 
 type TransportRoute struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID   primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
 	RouteNumber   string             `bson:"route_number" json:"route_number" validate:"required"`
 	RouteName     string             `bson:"route_name" json:"route_name" validate:"required,min=5,max=200"`
 	TransportType string             `bson:"transport_type" json:"transport_type" validate:"required,oneof=bus trolley minibus taxi"`
@@ -28,16 +29,24 @@ type TransportRoute struct {
 	LastDeparture  time.Time           `bson:"last_departure" json:"last_departure"`
 
 	// Вартість і характеристики
-	Fare         float64 `bson:"fare" json:"fare"`
-	IsAccessible bool    `bson:"is_accessible" json:"is_accessible"`
-	HasWiFi      bool    `bson:"has_wifi" json:"has_wifi"`
-	HasAC        bool    `bson:"has_ac" json:"has_ac"`
+	Fare         float64    `bson:"fare" json:"fare"`
+	FareRules    []FareRule `bson:"fare_rules,omitempty" json:"fare_rules,omitempty"` // Пільгові тарифи
+	IsAccessible bool       `bson:"is_accessible" json:"is_accessible"`
+	HasWiFi      bool       `bson:"has_wifi" json:"has_wifi"`
+	HasAC        bool       `bson:"has_ac" json:"has_ac"`
+
+	// Критичні проблеми міста поблизу маршруту (можлива зміна руху)
+	Incidents []RouteIncident `bson:"incidents,omitempty" json:"incidents,omitempty"`
 
 	// Статус і метадані
 	IsActive  bool               `bson:"is_active" json:"is_active"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
 	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+
+	// Закодована геометрія у відповіді (?geometry=polyline), не зберігається в DB
+	RoutePolyline string `bson:"-" json:"route_polyline,omitempty"`
+	PathPolyline  string `bson:"-" json:"path_polyline,omitempty"`
 }
 
 type TransportStop struct {
@@ -54,7 +63,7 @@ type TransportStop struct {
 }
 
 type TransportSchedule struct {
-	DayType       string             `bson:"day_type" json:"day_type"` // weekday, saturday, sunday
+	DayType       string             `bson:"day_type" json:"day_type"` // weekday, saturday, sunday, holiday
 	StopName      string             `bson:"stop_name" json:"stop_name"`
 	StopID        primitive.ObjectID `bson:"stop_id" json:"stop_id"`
 	ArrivalTime   string             `bson:"arrival_time" json:"arrival_time"`     // "HH:MM"
@@ -64,6 +73,7 @@ type TransportSchedule struct {
 	Weekdays []ScheduleInterval `bson:"weekdays,omitempty" json:"weekdays,omitempty"`
 	Saturday []ScheduleInterval `bson:"saturday,omitempty" json:"saturday,omitempty"`
 	Sunday   []ScheduleInterval `bson:"sunday,omitempty" json:"sunday,omitempty"`
+	Holiday  []ScheduleInterval `bson:"holiday,omitempty" json:"holiday,omitempty"` // Святковий розклад; якщо порожній - недільний
 }
 
 type ScheduleInterval struct {
@@ -74,6 +84,7 @@ type ScheduleInterval struct {
 
 type TransportVehicle struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID   primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
 	VehicleNumber string             `bson:"vehicle_number" json:"vehicle_number" validate:"required"`
 	RouteID       primitive.ObjectID `bson:"route_id" json:"route_id" validate:"required"`
 
@@ -103,6 +114,9 @@ type TransportVehicle struct {
 	IsTracked bool                `bson:"is_tracked" json:"is_tracked"` // Чи є GPS трекінг
 	DriverID  *primitive.ObjectID `bson:"driver_id,omitempty" json:"driver_id,omitempty"`
 
+	// Джерело позицій: застосунок водія або зовнішній GPS-провайдер автопарку
+	Tracking *VehicleTracking `bson:"tracking,omitempty" json:"tracking,omitempty"`
+
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 
@@ -110,6 +124,19 @@ type TransportVehicle struct {
 	RouteNumber string `bson:"-" json:"route_number,omitempty"`
 }
 
+// VehicleTracking - налаштування джерела GPS-позицій транспорту
+type VehicleTracking struct {
+	Source     string `bson:"source" json:"source"`                               // driver_app, provider
+	Provider   string `bson:"provider,omitempty" json:"provider,omitempty"`       // Назва адаптера провайдера
+	ExternalID string `bson:"external_id,omitempty" json:"external_id,omitempty"` // ID транспорту в системі провайдера
+}
+
+// Джерела GPS-позицій
+const (
+	TrackingSourceDriverApp = "driver_app"
+	TrackingSourceProvider  = "provider"
+)
+
 type TransportArrival struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	StopID    primitive.ObjectID `bson:"stop_id" json:"stop_id"`
@@ -157,6 +184,20 @@ const (
 // МЕТОДИ TransportRoute
 // ========================================
 
+// SimplifyGeometry спрощує RoutePoints і PathCoords з допуском tolerance (метри).
+// При encode точки замінюються рядками Encoded Polyline.
+func (r *TransportRoute) SimplifyGeometry(tolerance float64, encode bool) {
+	r.RoutePoints = SimplifyLocations(r.RoutePoints, tolerance)
+	r.PathCoords = SimplifyLocations(r.PathCoords, tolerance)
+
+	if encode {
+		r.RoutePolyline = EncodePolyline(r.RoutePoints)
+		r.PathPolyline = EncodePolyline(r.PathCoords)
+		r.RoutePoints = nil
+		r.PathCoords = nil
+	}
+}
+
 func (r *TransportRoute) GetStopByID(stopID primitive.ObjectID) *TransportStop {
 	for i, stop := range r.Stops {
 		if stop.ID == stopID {
@@ -326,6 +367,23 @@ func (s *TransportSchedule) GetScheduleForWeekday(weekday time.Weekday) []Schedu
 	}
 }
 
+// GetScheduleForDay повертає інтервали за типом розкладу з календаря (weekday, saturday, sunday, holiday)
+func (s *TransportSchedule) GetScheduleForDay(scheduleDay string) []ScheduleInterval {
+	switch scheduleDay {
+	case ScheduleDayHoliday:
+		if len(s.Holiday) > 0 {
+			return s.Holiday
+		}
+		return s.Sunday
+	case ScheduleDaySaturday:
+		return s.Saturday
+	case ScheduleDaySunday:
+		return s.Sunday
+	default:
+		return s.Weekdays
+	}
+}
+
 // IsOperatingNow перевіряє чи працює транспорт зараз
 func (s *TransportSchedule) IsOperatingNow() bool {
 	now := time.Now()
@@ -385,3 +443,11 @@ func parseTime(date time.Time, timeStr string) time.Time {
 	}
 	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location())
 }
+
+// StopTimetable - відправлення з зупинки за типами днів (weekday, saturday, sunday, holiday) для друку розкладу
+type StopTimetable struct {
+	StopID    primitive.ObjectID  `json:"stop_id"`
+	StopName  string              `json:"stop_name"`
+	StopOrder int                 `json:"stop_order"`
+	Days      map[string][]string `json:"days"` // Тип дня -> "HH:MM" за зростанням
+}
@@ -0,0 +1,56 @@
+// internal/models/transport_alert.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Статуси оголошення про зміну руху
+const (
+	TransportAlertDraft     = "draft"     // Чернетка, чекає перевірки адміністратором транспорту
+	TransportAlertPublished = "published" // Показується пасажирам
+	TransportAlertDismissed = "dismissed" // Відхилена чернетка
+	TransportAlertEnded     = "ended"     // Зміна руху завершена
+)
+
+// Джерела оголошень
+const (
+	TransportAlertSourceIncident = "incident" // Створено автоматично через критичну проблему біля маршруту
+)
+
+// TransportAlert - оголошення про об'їзд або зміну руху маршрутів (колекція transport_alerts).
+// Для критичної проблеми категорії road/safety поблизу маршруту створюється автоматично як чернетка.
+type TransportAlert struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	CommunityID primitive.ObjectID   `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада (multi-tenancy)
+	RouteIDs    []primitive.ObjectID `bson:"route_ids" json:"route_ids"`
+	IssueID     *primitive.ObjectID  `bson:"issue_id,omitempty" json:"issue_id,omitempty"` // Проблема міста, через яку змінено рух
+	Source      string               `bson:"source" json:"source"`
+	Title       string               `bson:"title" json:"title"`
+	Message     string               `bson:"message" json:"message"`
+	Status      string               `bson:"status" json:"status"`
+
+	CreatedBy   *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"` // Порожньо - створено автоматично
+	PublishedBy *primitive.ObjectID `bson:"published_by,omitempty" json:"published_by,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+	PublishedAt *time.Time          `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	EndedAt     *time.Time          `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+}
+
+// RouteIncident - позначка маршруту, що проходить поруч з критичною проблемою
+type RouteIncident struct {
+	IssueID   primitive.ObjectID  `bson:"issue_id" json:"issue_id"`
+	AlertID   *primitive.ObjectID `bson:"alert_id,omitempty" json:"alert_id,omitempty"`
+	Category  string              `bson:"category" json:"category"`
+	Title     string              `bson:"title" json:"title"`
+	Distance  float64             `bson:"distance" json:"distance"` // Метрів від проблеми до лінії маршруту
+	FlaggedAt time.Time           `bson:"flagged_at" json:"flagged_at"`
+}
+
+// IsActive - оголошення ще стосується пасажирів або адміністраторів
+func (a *TransportAlert) IsActive() bool {
+	return a.Status == TransportAlertDraft || a.Status == TransportAlertPublished
+}
@@ -0,0 +1,155 @@
+// internal/models/trust.go
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Рівні довіри до акаунту (бачать лише модератори)
+const (
+	TrustLevelLow    = "low"
+	TrustLevelMedium = "medium"
+	TrustLevelHigh   = "high"
+)
+
+// Межі рівнів довіри за балами
+const (
+	trustBaseScore      = 50
+	trustLowThreshold   = 40
+	trustHighThreshold  = 70
+	trustRemovalPenalty = 15
+)
+
+// TrustAssessment - оцінка довіри до акаунту для захисту від спаму та ботів
+type TrustAssessment struct {
+	UserID      primitive.ObjectID `json:"user_id"`
+	Score       int                `json:"score"`
+	Level       string             `json:"level"` // low, medium, high
+	Factors     []TrustFactor      `json:"factors"`
+	Quota       TrustQuota         `json:"quota"`
+	EvaluatedAt time.Time          `json:"evaluated_at"`
+}
+
+// TrustFactor - внесок окремого чинника в оцінку
+type TrustFactor struct {
+	Name   string `json:"name"` // account_age, verification, prior_removals, staff
+	Impact int    `json:"impact"`
+}
+
+// TrustQuota - обмеження для акаунтів відповідного рівня довіри
+type TrustQuota struct {
+	MaxActiveAnnouncements int  `json:"max_active_announcements"`
+	MaxEventsPerDay        int  `json:"max_events_per_day"`
+	ChatMinIntervalSeconds int  `json:"chat_min_interval_seconds"` // Мінімальний інтервал між повідомленнями в чаті
+	PremoderateContent     bool `json:"premoderate_content"`       // Контент спочатку потрапляє на модерацію
+	HoldLinks              bool `json:"hold_links"`                // Повідомлення з посиланнями утримуються до перевірки
+}
+
+// QuotaForTrustLevel повертає обмеження для рівня довіри
+func QuotaForTrustLevel(level string) TrustQuota {
+	switch level {
+	case TrustLevelLow:
+		return TrustQuota{
+			MaxActiveAnnouncements: 1,
+			MaxEventsPerDay:        1,
+			ChatMinIntervalSeconds: 30,
+			PremoderateContent:     true,
+			HoldLinks:              true,
+		}
+	case TrustLevelMedium:
+		return TrustQuota{
+			MaxActiveAnnouncements: 3,
+			MaxEventsPerDay:        3,
+			ChatMinIntervalSeconds: 5,
+		}
+	default:
+		return TrustQuota{
+			MaxActiveAnnouncements: 5,
+			MaxEventsPerDay:        10,
+		}
+	}
+}
+
+// CalculateTrust обчислює довіру за віком акаунту, верифікацією та попередніми видаленнями контенту
+func CalculateTrust(user *User, now time.Time) TrustAssessment {
+	assessment := TrustAssessment{
+		UserID:      user.ID,
+		EvaluatedAt: now,
+	}
+
+	// Модератори та адміністратори завжди мають високу довіру
+	if user.IsAtLeast(RoleModerator) {
+		assessment.Score = 100
+		assessment.Level = TrustLevelHigh
+		assessment.Factors = []TrustFactor{{Name: "staff", Impact: 100 - trustBaseScore}}
+		assessment.Quota = QuotaForTrustLevel(TrustLevelHigh)
+		return assessment
+	}
+
+	score := trustBaseScore
+
+	// Вік акаунту
+	age := now.Sub(user.CreatedAt)
+	ageImpact := 0
+	switch {
+	case age < 24*time.Hour:
+		ageImpact = -30
+	case age < 7*24*time.Hour:
+		ageImpact = -15
+	case age >= 30*24*time.Hour:
+		ageImpact = 20
+	}
+	assessment.Factors = append(assessment.Factors, TrustFactor{Name: "account_age", Impact: ageImpact})
+	score += ageImpact
+
+	// Верифікація
+	verificationImpact := 0
+	if user.IsVerified {
+		verificationImpact += 20
+	}
+	if user.EmailVerifiedAt != nil {
+		verificationImpact += 5
+	}
+	if user.PhoneVerifiedAt != nil {
+		verificationImpact += 10
+	}
+	assessment.Factors = append(assessment.Factors, TrustFactor{Name: "verification", Impact: verificationImpact})
+	score += verificationImpact
+
+	// Контент, раніше видалений або відхилений модераторами
+	if user.RemovedContentCount > 0 {
+		removalImpact := -trustRemovalPenalty * user.RemovedContentCount
+		assessment.Factors = append(assessment.Factors, TrustFactor{Name: "prior_removals", Impact: removalImpact})
+		score += removalImpact
+	}
+
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+
+	assessment.Score = score
+	switch {
+	case score < trustLowThreshold:
+		assessment.Level = TrustLevelLow
+	case score < trustHighThreshold:
+		assessment.Level = TrustLevelMedium
+	default:
+		assessment.Level = TrustLevelHigh
+	}
+	assessment.Quota = QuotaForTrustLevel(assessment.Level)
+
+	return assessment
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|ru|ua|info|biz|xyz|top|link|click)\b`)
+
+// ContainsLink перевіряє, чи є в тексті посилання
+func ContainsLink(text string) bool {
+	return linkPattern.MatchString(text)
+}
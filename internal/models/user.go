@@ -2,6 +2,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -57,14 +58,16 @@ const (
 	PermissionViewReports          Permission = "view:reports"
 
 	// Admin permissions
-	PermissionManageUsers       Permission = "manage:users"
-	PermissionUsersManage       Permission = "users:manage" // ✅ ДОДАНО для відповідності Frontend
-	PermissionBlockUser         Permission = "block:user"
-	PermissionVerifyUser        Permission = "verify:user"
-	PermissionPromoteModerator  Permission = "promote:moderator"
-	PermissionViewAnalytics     Permission = "view:analytics"
-	PermissionManageTransport   Permission = "manage:transport"
-	PermissionSendNotifications Permission = "send:notifications"
+	PermissionManageUsers         Permission = "manage:users"
+	PermissionUsersManage         Permission = "users:manage" // ✅ ДОДАНО для відповідності Frontend
+	PermissionBlockUser           Permission = "block:user"
+	PermissionVerifyUser          Permission = "verify:user"
+	PermissionPromoteModerator    Permission = "promote:moderator"
+	PermissionViewAnalytics       Permission = "view:analytics"
+	PermissionManageTransport     Permission = "manage:transport"
+	PermissionSendNotifications   Permission = "send:notifications"
+	PermissionManageEducation     Permission = "manage:education"     // Управління освіти: набори до шкіл і садків
+	PermissionManageConsultations Permission = "manage:consultations" // Публікація проєктів документів на обговорення
 
 	// Super Admin permissions
 	PermissionManageAdmins         Permission = "manage:admins"
@@ -116,6 +119,7 @@ type User struct {
 	FirstName    string             `bson:"first_name" json:"first_name"`
 	LastName     string             `bson:"last_name" json:"last_name"`
 	Avatar       string             `bson:"avatar,omitempty" json:"avatar,omitempty"` // ✅ ДОДАНО для відповідності Frontend
+	AvatarKey    string             `bson:"avatar_key,omitempty" json:"-"`            // Каталог мініатюр у файловому сховищі
 
 	// Додаткова інформація
 	Profession        string   `bson:"profession,omitempty" json:"profession,omitempty"`
@@ -123,6 +127,9 @@ type User struct {
 	IsAddressVisible  bool     `bson:"is_address_visible" json:"is_address_visible"`
 	Interests         []string `bson:"interests" json:"interests"`
 
+	// Дата народження (лише дата, UTC) - для вікових обмежень опитувань
+	BirthDate *time.Time `bson:"birth_date,omitempty" json:"birth_date,omitempty"`
+
 	// Локація та статус
 	CurrentLocation *Location  `bson:"current_location,omitempty" json:"location,omitempty"` // ✅ Відповідає Frontend: location
 	Status          UserStatus `bson:"status" json:"status"`
@@ -133,9 +140,15 @@ type User struct {
 	// Групи користувача
 	Groups []primitive.ObjectID `bson:"groups" json:"groups"`
 
+	// Громади, до яких приєднався користувач (спільний акаунт для кількох громад)
+	CommunityIDs []primitive.ObjectID `bson:"community_ids" json:"community_ids"`
+
 	// Налаштування сповіщень
 	NotificationPreferences *NotificationPreferences `bson:"notification_preferences,omitempty" json:"notification_preferences,omitempty"`
 
+	// Що показувати в публічному профілі (GET /users/:id/public)
+	Privacy *PrivacySettings `bson:"privacy,omitempty" json:"privacy,omitempty"`
+
 	// ========================================
 	// СИСТЕМА РОЛЕЙ ТА ПРАВ
 	// ========================================
@@ -143,18 +156,50 @@ type User struct {
 	Role        string `bson:"role" json:"role"`                 // USER, MODERATOR, ADMIN, SUPER_ADMIN
 	IsModerator bool   `bson:"is_moderator" json:"is_moderator"` // LEGACY: Для зворотної сумісності
 
+	// Історія змін ролі, від старих до нових (GET /admin/users/:id/role-history)
+	RoleHistory []RoleChange `bson:"role_history,omitempty" json:"-"`
+
+	// Зона модератора: мікрорайони та категорії (PUT /admin/users/:id/moderation-scope)
+	ModerationScope *ModerationScope `bson:"moderation_scope,omitempty" json:"moderation_scope,omitempty"`
+
 	// Статус акаунту
 	IsVerified bool `bson:"is_verified" json:"is_verified"`
 	IsBlocked  bool `bson:"is_blocked" json:"is_blocked"`
 
+	// Особу підтверджено через Дію або BankID (PUT /users/:id/verify): провайдер і HMAC РНОКПП.
+	// Один РНОКПП на кількох акаунтах - одна особа в опитуваннях verified_voters_only.
+	IdentityProvider string `bson:"identity_provider,omitempty" json:"identity_provider,omitempty"`
+	IdentityHash     string `bson:"identity_hash,omitempty" json:"-"`
+
 	// ✅ ДОДАНО: Поля блокування (відповідають Frontend)
 	BlockReason *string    `bson:"block_reason,omitempty" json:"block_reason,omitempty"` // Причина блокування
 	BlockedAt   *time.Time `bson:"blocked_at,omitempty" json:"blocked_at,omitempty"`     // Час блокування
 
+	// Кінець тимчасового призупинення (PUT /users/:id/suspend); порожній - безстрокове блокування
+	BlockedUntil *time.Time `bson:"blocked_until,omitempty" json:"blocked_until,omitempty"`
+
+	// Версія токенів: збільшується при блокуванні та зміні ролі, токени старішої версії недійсні
+	TokenVersion int `bson:"token_version" json:"-"`
+
+	// Вхід лише через SSO міськради (azure, google): пароль для такого акаунта не діє
+	SSOProvider string `bson:"sso_provider,omitempty" json:"sso_provider,omitempty"`
+	SSOSubject  string `bson:"sso_subject,omitempty" json:"-"` // Ідентифікатор користувача у провайдера (sub)
+
+	// Пільга на проїзд (студент, пенсіонер, ВПО)
+	FareConcession *FareConcession `bson:"fare_concession,omitempty" json:"fare_concession,omitempty"`
+
+	// Рівень верифікації (basic, resident) та картка жителя, що його підтверджує
+	VerificationLevel string        `bson:"verification_level,omitempty" json:"verification_level,omitempty"`
+	ResidentCard      *ResidentCard `bson:"resident_card,omitempty" json:"resident_card,omitempty"`
+
+	// Кількість відхиленого/видаленого модераторами контенту (для оцінки довіри, не повертається в JSON)
+	RemovedContentCount int `bson:"removed_content_count,omitempty" json:"-"`
+
 	// Часові мітки
 	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
 	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
 	LastLoginAt     *time.Time `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+	LastSeenAt      *time.Time `bson:"last_seen_at,omitempty" json:"last_seen_at,omitempty"` // Підключення або відключення від чату
 	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
 	PhoneVerifiedAt *time.Time `bson:"phone_verified_at,omitempty" json:"phone_verified_at,omitempty"`
 }
@@ -169,12 +214,59 @@ type NotificationPreferences struct {
 	CityIssues    bool `bson:"city_issues" json:"city_issues"`
 	Polls         bool `bson:"polls" json:"polls"`
 	Petitions     bool `bson:"petitions" json:"petitions"`
+
+	// Власні тихі години; порожньо - тихі години міста за замовчуванням
+	QuietHours *QuietHours `bson:"quiet_hours,omitempty" json:"quiet_hours,omitempty"`
+}
+
+// PrivacySettings - поля, які користувач погодився показувати в публічному профілі.
+// За замовчуванням (немає налаштувань) публічний профіль містить лише ім'я та аватар.
+type PrivacySettings struct {
+	ShowProfession   bool `bson:"show_profession" json:"show_profession"`
+	ShowInterests    bool `bson:"show_interests" json:"show_interests"`
+	ShowBusinessInfo bool `bson:"show_business_info" json:"show_business_info"`
+	// Присутність (онлайн, last_seen_at) за замовчуванням видно іншим мешканцям
+	HidePresence bool `bson:"hide_presence" json:"hide_presence"`
 }
 
 // ========================================
 // USER METHODS
 // ========================================
 
+// ShowsPresence - чи видно іншим, що користувач онлайн і коли був востаннє
+func (u *User) ShowsPresence() bool {
+	return u.Privacy == nil || !u.Privacy.HidePresence
+}
+
+// AgeAt - повних років на дату now; false, якщо дата народження не вказана
+func (u *User) AgeAt(now time.Time) (int, bool) {
+	if u.BirthDate == nil {
+		return 0, false
+	}
+	birth := u.BirthDate.UTC()
+	now = now.UTC()
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return age, true
+}
+
+// ParseBirthDate розбирає дату народження з профілю (YYYY-MM-DD): не в майбутньому і не раніше ніж 120 років тому
+func ParseBirthDate(value string, now time.Time) (time.Time, error) {
+	birthDate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("birth date must be in YYYY-MM-DD format")
+	}
+	if birthDate.After(now) {
+		return time.Time{}, fmt.Errorf("birth date cannot be in the future")
+	}
+	if birthDate.Before(now.AddDate(-120, 0, 0)) {
+		return time.Time{}, fmt.Errorf("birth date is more than 120 years ago")
+	}
+	return birthDate, nil
+}
+
 // GetFullName повертає повне ім'я користувача
 // ✅ ВІДПОВІДАЄ Frontend: UserHelpers.getFullName()
 func (u *User) GetFullName() string {
@@ -220,6 +312,33 @@ func (u *User) GetRole() UserRole {
 	return UserRole(u.Role)
 }
 
+// IsBlockedAt - чи діє блокування в момент now; призупинення знімається після BlockedUntil,
+// навіть якщо фонова задача ще не оновила документ
+func (u *User) IsBlockedAt(now time.Time) bool {
+	return u.IsBlocked && (u.BlockedUntil == nil || now.Before(*u.BlockedUntil))
+}
+
+// IsSuspended - блокування тимчасове
+func (u *User) IsSuspended() bool {
+	return u.IsBlocked && u.BlockedUntil != nil
+}
+
+// AccountStatus - актуальний стан облікового запису, який перевіряється на кожному запиті
+// (токен лишається валідним до закінчення терміну, навіть якщо користувача заблоковано)
+type AccountStatus struct {
+	Exists          bool
+	IsBlocked       bool
+	BlockedUntil    *time.Time // Кінець призупинення, якщо блокування тимчасове
+	Role            UserRole
+	TokenVersion    int
+	ModerationScope *ModerationScope // Зона модератора; nil - без обмежень
+}
+
+// AcceptsTokenVersion - чи не відкликано токен з версією version
+func (s AccountStatus) AcceptsTokenVersion(version int) bool {
+	return version >= s.TokenVersion
+}
+
 // SetRole встановлює роль користувача
 func (u *User) SetRole(role UserRole) {
 	u.Role = string(role)
@@ -383,6 +502,8 @@ func GetRolePermissions(role UserRole) []Permission {
 		PermissionViewAnalytics,
 		PermissionManageTransport,
 		PermissionSendNotifications,
+		PermissionManageEducation,
+		PermissionManageConsultations,
 	}
 
 	// Права супер-адміністратора
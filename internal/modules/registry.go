@@ -0,0 +1,138 @@
+// internal/modules/registry.go
+package modules
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Module описує модуль платформи та префікси його маршрутів
+type Module struct {
+	Code     string
+	Prefixes []string
+}
+
+// Registry - центральний реєстр модулів.
+// Вимкнені на рівні деплойменту модулі не реєструють маршрути взагалі,
+// вимкнені в налаштуваннях громади - відповідають 404 з кодом MODULE_DISABLED.
+type Registry struct {
+	mu       sync.RWMutex
+	disabled map[string]bool
+	modules  []Module
+}
+
+// NewRegistry створює реєстр з переліком вимкнених модулів (з конфігурації)
+func NewRegistry(disabled []string) *Registry {
+	r := &Registry{
+		disabled: make(map[string]bool),
+	}
+	for _, code := range disabled {
+		r.disabled[strings.TrimSpace(code)] = true
+	}
+	return r
+}
+
+// Add реєструє модуль. Функція register викликається лише якщо модуль увімкнений.
+func (r *Registry) Add(code string, prefixes []string, register func()) {
+	r.mu.Lock()
+	r.modules = append(r.modules, Module{Code: code, Prefixes: prefixes})
+	enabled := !r.disabled[code]
+	r.mu.Unlock()
+
+	if !enabled {
+		log.Printf("⏸️  Module %s is disabled", code)
+		return
+	}
+	register()
+}
+
+// IsEnabled перевіряє, чи увімкнений модуль на рівні деплойменту
+func (r *Registry) IsEnabled(code string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !r.disabled[code]
+}
+
+// FilterEnabled залишає у списку лише модулі, увімкнені на рівні деплойменту
+func (r *Registry) FilterEnabled(codes []string) []string {
+	enabled := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if r.IsEnabled(code) {
+			enabled = append(enabled, code)
+		}
+	}
+	return enabled
+}
+
+// ModuleForPath визначає модуль за шляхом запиту
+func (r *Registry) ModuleForPath(path string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, module := range r.modules {
+		for _, prefix := range module.Prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return module.Code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Middleware перевіряє, чи увімкнений модуль у налаштуваннях поточної громади.
+// Список модулів громади кладе в context CommunityMiddleware (community_modules).
+func (r *Registry) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		module, ok := r.ModuleForPath(c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if value, exists := c.Get("community_modules"); exists {
+			if enabled, ok := value.([]string); ok && !contains(enabled, module) {
+				moduleDisabled(c, module)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// NoRouteHandler повертає 404 з кодом MODULE_DISABLED для маршрутів вимкнених модулів
+func (r *Registry) NoRouteHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if module, ok := r.ModuleForPath(c.Request.URL.Path); ok && !r.IsEnabled(module) {
+			moduleDisabled(c, module)
+			return
+		}
+
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Route not found",
+			"code":  "NOT_FOUND",
+		})
+	}
+}
+
+func moduleDisabled(c *gin.Context, module string) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":  "Module is disabled",
+		"code":   "MODULE_DISABLED",
+		"module": module,
+	})
+	c.Abort()
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Как часто воркер ищет необработанные запросы (новые будят его сразу)
+	accountErasureCheckInterval = time.Minute
+	// Запрос в processing дольше этого считается прерванным (перезапуск сервера) и берется повторно
+	accountErasureStaleAfter = 15 * time.Minute
+	// После стольких неудачных попыток запрос помечается failed для разбора администратором
+	accountErasureMaxAttempts = 5
+)
+
+// ErrAccountDeletionNotFound - квитанция не найдена или код не совпадает
+var ErrAccountDeletionNotFound = errors.New("account deletion not found")
+
+// erasureStep - обезличивание одного вида данных; возвращает количество измененных записей
+type erasureStep struct {
+	name string
+	run  func(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+// AccountErasureService удаляет аккаунт по запросу пользователя (право на забвение):
+// аккаунт блокируется сразу, личные данные в пользователях, сообщениях, подписях петиций,
+// ответах опросов и комментариях обезличиваются фоновой задачей.
+// Содержательные данные (счетчики подписей, результаты опросов) сохраняются.
+// Шаги идемпотентны: прерванная задача безопасно выполняется повторно.
+type AccountErasureService struct {
+	deletionCollection            *mongo.Collection
+	userCollection                *mongo.Collection
+	messageCollection             *mongo.Collection
+	messageReportCollection       *mongo.Collection
+	directMessageCollection       *mongo.Collection
+	groupReadStateCollection      *mongo.Collection
+	groupDeliveryStateCollection  *mongo.Collection
+	petitionCollection            *mongo.Collection
+	pollResponseCollection        *mongo.Collection
+	consultationCommentCollection *mongo.Collection
+	moderatorNoteCollection       *mongo.Collection
+	notificationCollection        *mongo.Collection
+	deviceTokenCollection         *mongo.Collection
+	avatarService                 *AvatarService
+	chatAttachmentService         *ChatAttachmentService
+	dataExportService             *DataExportService
+	apiKeyService                 *APIKeyService
+
+	wake      chan struct{}
+	listeners []func(userID primitive.ObjectID)
+}
+
+func NewAccountErasureService(db *mongo.Database, avatarService *AvatarService, chatAttachmentService *ChatAttachmentService, dataExportService *DataExportService, apiKeyService *APIKeyService) *AccountErasureService {
+	return &AccountErasureService{
+		deletionCollection:            db.Collection("account_deletions"),
+		userCollection:                db.Collection("users"),
+		messageCollection:             db.Collection("messages"),
+		messageReportCollection:       db.Collection("message_reports"),
+		directMessageCollection:       db.Collection("direct_messages"),
+		groupReadStateCollection:      db.Collection("group_read_states"),
+		groupDeliveryStateCollection:  db.Collection("group_delivery_states"),
+		petitionCollection:            db.Collection("petitions"),
+		pollResponseCollection:        db.Collection("poll_responses"),
+		consultationCommentCollection: db.Collection("consultation_comments"),
+		moderatorNoteCollection:       db.Collection("moderator_notes"),
+		notificationCollection:        db.Collection("notifications"),
+		deviceTokenCollection:         db.Collection("device_tokens"),
+		avatarService:                 avatarService,
+		chatAttachmentService:         chatAttachmentService,
+		dataExportService:             dataExportService,
+		apiKeyService:                 apiKeyService,
+		wake:                          make(chan struct{}, 1),
+	}
+}
+
+// OnAccountDeleted регистрирует обработчик удаления аккаунта (закрытие WebSocket и т.п.).
+// Регистрация выполняется при старте, до начала обработки запросов.
+func (s *AccountErasureService) OnAccountDeleted(listener func(userID primitive.ObjectID)) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Request блокирует аккаунт, отзывает выданные токены и ставит обезличивание в очередь.
+// Возвращает запрос и код квитанции для проверки статуса (код показывается только один раз).
+func (s *AccountErasureService) Request(ctx context.Context, userID primitive.ObjectID) (*models.AccountDeletion, string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	code := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	deletion := &models.AccountDeletion{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		ReceiptHash: hashReceiptCode(code),
+		Status:      models.AccountDeletionPending,
+		RequestedAt: now,
+	}
+
+	// Вход и все выданные токены перестают работать до начала фоновой обработки
+	_, err := s.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+			"deleted_at": now,
+			"is_blocked": true,
+			"updated_at": now,
+		},
+		"$inc": bson.M{"token_version": 1},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := s.deletionCollection.InsertOne(ctx, deletion); err != nil {
+		return nil, "", err
+	}
+
+	for _, listener := range s.listeners {
+		listener(userID)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return deletion, code, nil
+}
+
+// Receipt возвращает статус удаления по квитанции
+func (s *AccountErasureService) Receipt(ctx context.Context, deletionID primitive.ObjectID, code string) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := s.deletionCollection.FindOne(ctx, bson.M{"_id": deletionID}).Decode(&deletion)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrAccountDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(deletion.ReceiptHash), []byte(hashReceiptCode(code))) != 1 {
+		return nil, ErrAccountDeletionNotFound
+	}
+	return &deletion, nil
+}
+
+// StartWorker обрабатывает очередь удалений
+func (s *AccountErasureService) StartWorker() {
+	ticker := time.NewTicker(accountErasureCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.processNext() {
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// ProcessPending обрабатывает очередь до конца без фонового обработчика (cmd/admin).
+// Возвращает количество обработанных запросов, включая неудачные попытки.
+func (s *AccountErasureService) ProcessPending() int {
+	processed := 0
+	for s.processNext() {
+		processed++
+	}
+	return processed
+}
+
+// processNext берет один запрос из очереди; false - очередь пуста
+func (s *AccountErasureService) processNext() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	var deletion models.AccountDeletion
+	err := s.deletionCollection.FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{"status": models.AccountDeletionPending},
+			{"status": models.AccountDeletionProcessing, "started_at": bson.M{"$lt": now.Add(-accountErasureStaleAfter)}},
+		}},
+		bson.M{
+			"$set": bson.M{"status": models.AccountDeletionProcessing, "started_at": now},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "requested_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Account erasure: error claiming request: %v", err)
+		}
+		return false
+	}
+
+	results, err := s.erase(ctx, deletion.UserID)
+	if err != nil {
+		status := models.AccountDeletionPending
+		if deletion.Attempts >= accountErasureMaxAttempts {
+			status = models.AccountDeletionFailed
+		}
+		log.Printf("Account erasure %s failed (attempt %d): %v", deletion.ID.Hex(), deletion.Attempts, err)
+		s.deletionCollection.UpdateOne(ctx, bson.M{"_id": deletion.ID}, bson.M{
+			"$set": bson.M{"status": status, "last_error": err.Error(), "results": results},
+		})
+		return true
+	}
+
+	completedAt := time.Now()
+	_, err = s.deletionCollection.UpdateOne(ctx, bson.M{"_id": deletion.ID}, bson.M{
+		"$set":   bson.M{"status": models.AccountDeletionCompleted, "completed_at": completedAt, "results": results},
+		"$unset": bson.M{"last_error": ""},
+	})
+	if err != nil {
+		log.Printf("Account erasure %s: error saving result: %v", deletion.ID.Hex(), err)
+	}
+	return true
+}
+
+// erase выполняет все шаги обезличивания
+func (s *AccountErasureService) erase(ctx context.Context, userID primitive.ObjectID) (map[string]int64, error) {
+	results := make(map[string]int64)
+	for _, step := range s.steps() {
+		count, err := step.run(ctx, userID)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", step.name, err)
+		}
+		results[step.name] = count
+	}
+	return results, nil
+}
+
+func (s *AccountErasureService) steps() []erasureStep {
+	return []erasureStep{
+		// Файлы аватара удаляются до обезличивания: ключ хранится в профиле пользователя
+		{"avatar", s.avatarService.Remove},
+		// Файлы вложений чата: ключи хранятся в chat_uploads
+		{"chat_attachments", s.chatAttachmentService.RemoveForUser},
+		// Готовые архивы выгрузки содержат все личные данные
+		{"data_exports", s.dataExportService.RemoveForUser},
+		{"api_keys", s.apiKeyService.RemoveForUser},
+		{"user", s.eraseUser},
+		{"messages", s.eraseMessages},
+		{"direct_messages", s.eraseDirectMessages},
+		// Жалобы показывают, какие сообщения читал пользователь; решения модераторов остаются в moderation_actions
+		{"message_reports", s.eraseMessageReports},
+		// Позиции чтения показывают другим участникам активность пользователя
+		{"group_read_states", s.eraseGroupReadStates},
+		{"group_delivery_states", s.eraseGroupDeliveryStates},
+		{"petition_signatures", s.erasePetitionSignatures},
+		{"petition_co_authors", s.erasePetitionCoAuthors},
+		{"poll_responses", s.erasePollResponses},
+		{"consultation_comments", s.eraseConsultationComments},
+		{"moderator_notes", s.eraseModeratorNotes},
+		{"notifications", s.eraseNotifications},
+		{"device_tokens", s.eraseDeviceTokens},
+	}
+}
+
+// eraseUser заменяет контакты и имя, удаляет профиль, адрес, местоположение и пароль
+func (s *AccountErasureService) eraseUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			// Уникальный адрес, который не может принадлежать реальному пользователю
+			"email":         fmt.Sprintf("deleted-%s@deleted.invalid", userID.Hex()),
+			"first_name":    models.AnonymizedFirstName,
+			"last_name":     models.AnonymizedLastName,
+			"password_hash": "",
+			"interests":     []string{},
+			"is_verified":   false,
+			"updated_at":    time.Now(),
+		},
+		"$unset": bson.M{
+			"phone":                    "",
+			"status":                   "",
+			"avatar":                   "",
+			"avatar_key":               "",
+			"profession":               "",
+			"registered_address":       "",
+			"birth_date":               "",
+			"current_location":         "",
+			"business_info":            "",
+			"notification_preferences": "",
+			"privacy":                  "",
+			"sso_provider":             "",
+			"sso_subject":              "",
+			"fare_concession":          "",
+			"resident_card":            "",
+			"verification_level":       "",
+			"identity_provider":        "",
+			"identity_hash":            "",
+			"last_login_at":            "",
+			"email_verified_at":        "",
+			"phone_verified_at":        "",
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// eraseMessages удаляет текст, вложения и историю правок сообщений; сами записи остаются для целостности переписки
+func (s *AccountErasureService) eraseMessages(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.messageCollection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "$or": []bson.M{
+			{"content": bson.M{"$ne": ""}},
+			{"media_url": bson.M{"$exists": true}},
+			{"edit_history": bson.M{"$exists": true}},
+			{"attachments": bson.M{"$exists": true}},
+		}},
+		bson.M{
+			"$set":   bson.M{"content": "", "is_deleted": true, "updated_at": time.Now()},
+			"$unset": bson.M{"media_url": "", "media_type": "", "media_size": "", "edit_history": "", "attachments": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// eraseDirectMessages удаляет текст и вложения личных сообщений пользователя
+func (s *AccountErasureService) eraseDirectMessages(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.directMessageCollection.UpdateMany(ctx,
+		bson.M{"sender_id": userID, "$or": []bson.M{{"content": bson.M{"$ne": ""}}, {"media_url": bson.M{"$exists": true}}}},
+		bson.M{
+			"$set":   bson.M{"content": "", "is_deleted": true},
+			"$unset": bson.M{"media_url": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// erasePetitionSignatures обезличивает подписи: подпись засчитана, но имя, ключ ДІЯ и комментарий удаляются
+func (s *AccountErasureService) erasePetitionSignatures(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.petitionCollection.UpdateMany(ctx,
+		bson.M{"signatures.user_id": userID},
+		bson.M{
+			"$set": bson.M{"signatures.$[signature].full_name": models.AnonymizedUserName},
+			"$unset": bson.M{
+				"signatures.$[signature].diia_key_id": "",
+				"signatures.$[signature].comment":     "",
+			},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"signature.user_id": userID}},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// erasePetitionCoAuthors заменяет имя в списке соавторов петиций
+func (s *AccountErasureService) erasePetitionCoAuthors(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.petitionCollection.UpdateMany(ctx,
+		bson.M{"co_authors.user_id": userID},
+		bson.M{"$set": bson.M{"co_authors.$[coAuthor].full_name": models.AnonymizedUserName}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"coAuthor.user_id": userID}},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// erasePollResponses удаляет IP, User-Agent и свободные текстовые ответы; выбранные варианты остаются в результатах
+func (s *AccountErasureService) erasePollResponses(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.pollResponseCollection.UpdateMany(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$unset": bson.M{
+			"ip_address":              "",
+			"user_agent":              "",
+			"answers.$[].text_answer": "",
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// eraseConsultationComments заменяет имя автора в комментариях к обсуждениям
+func (s *AccountErasureService) eraseConsultationComments(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.consultationCommentCollection.UpdateMany(ctx,
+		bson.M{"author_id": userID, "author_name": bson.M{"$ne": models.AnonymizedUserName}},
+		bson.M{"$set": bson.M{"author_name": models.AnonymizedUserName}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// eraseModeratorNotes заменяет имя автора во внутренних заметках модераторов; заметки нужны остальным модераторам
+func (s *AccountErasureService) eraseModeratorNotes(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.moderatorNoteCollection.UpdateMany(ctx,
+		bson.M{"author_id": userID, "author_name": bson.M{"$ne": models.AnonymizedUserName}},
+		bson.M{"$set": bson.M{"author_name": models.AnonymizedUserName}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *AccountErasureService) eraseNotifications(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.notificationCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (s *AccountErasureService) eraseMessageReports(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.messageReportCollection.DeleteMany(ctx, bson.M{"reporter_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (s *AccountErasureService) eraseGroupReadStates(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.groupReadStateCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (s *AccountErasureService) eraseGroupDeliveryStates(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.groupDeliveryStateCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (s *AccountErasureService) eraseDeviceTokens(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.deviceTokenCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// hashReceiptCode - в базе хранится только SHA-256 кода квитанции
+func hashReceiptCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ActivityService отмечает активные недели пользователей для когортного анализа.
+// В базу пишется не чаще одного раза в сутки на пользователя - остальные запросы отсекаются в памяти.
+type ActivityService struct {
+	activityCollection *mongo.Collection
+
+	mu       sync.Mutex
+	recorded map[primitive.ObjectID]time.Time // Пользователь -> последний записанный день
+	day      time.Time                        // День, к которому относится recorded
+}
+
+func NewActivityService(activityCollection *mongo.Collection) *ActivityService {
+	return &ActivityService{
+		activityCollection: activityCollection,
+		recorded:           make(map[primitive.ObjectID]time.Time),
+	}
+}
+
+// Record отмечает активность пользователя. Запись выполняется в фоне и не задерживает запрос.
+func (s *ActivityService) Record(userID primitive.ObjectID, at time.Time) {
+	at = at.UTC()
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+
+	s.mu.Lock()
+	// С новым днем память о вчерашних записях больше не нужна
+	if !day.Equal(s.day) {
+		s.day = day
+		s.recorded = make(map[primitive.ObjectID]time.Time)
+	}
+	if _, done := s.recorded[userID]; done {
+		s.mu.Unlock()
+		return
+	}
+	s.recorded[userID] = day
+	s.mu.Unlock()
+
+	go s.store(userID, at, day)
+}
+
+func (s *ActivityService) store(userID primitive.ObjectID, at, day time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	weekStart := models.WeekStart(day)
+	weekday := int(day.Sub(weekStart).Hours() / 24)
+
+	_, err := s.activityCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "week_start": weekStart},
+		bson.M{
+			"$addToSet": bson.M{"active_days": weekday},
+			"$max":      bson.M{"last_seen_at": at},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Printf("Error recording user activity: %v", err)
+		// Повторим при следующем запросе пользователя
+		s.mu.Lock()
+		delete(s.recorded, userID)
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Все ключи начинаются с префикса: так их легко найти сканерами утечек в репозиториях
+	apiKeyPrefix = "nke_"
+	// Сколько символов ключа сохраняется открыто для списка ключей
+	apiKeyDisplayLength = 12
+	// last_used_at обновляется не чаще, чем раз в этот интервал
+	apiKeyTouchInterval = time.Minute
+	// Максимум действующих ключей у пользователя
+	APIKeyMaxPerUser = 10
+)
+
+var (
+	ErrAPIKeyLimit    = errors.New("api key limit reached")
+	ErrAPIKeyScope    = errors.New("scope is not allowed for this account")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// Права, которые не выдаются ключам: управление администраторами и ролями - только при входе
+var apiKeyForbiddenScopes = map[models.Permission]bool{
+	models.PermissionManageAdmins: true,
+	models.PermissionManageRoles:  true,
+}
+
+// APIKeyService выпускает ключи X-API-Key для машинного доступа партнеров и проверяет их.
+// Открытый ключ возвращается только при создании, в базе хранится его SHA-256.
+type APIKeyService struct {
+	collection *mongo.Collection
+}
+
+func NewAPIKeyService(collection *mongo.Collection) *APIKeyService {
+	return &APIKeyService{
+		collection: collection,
+	}
+}
+
+// AvailableScopes - права, которые пользователь с ролью role может выдать ключу
+func AvailableScopes(role models.UserRole) []models.Permission {
+	scopes := []models.Permission{}
+	for _, permission := range models.GetRolePermissions(role) {
+		if !apiKeyForbiddenScopes[permission] {
+			scopes = append(scopes, permission)
+		}
+	}
+	return scopes
+}
+
+// Create выпускает ключ; права ключа должны входить в права текущей роли владельца
+func (s *APIKeyService) Create(ctx context.Context, userID primitive.ObjectID, role models.UserRole, name string, scopes []models.Permission, expiresAt *time.Time) (string, *models.APIKey, error) {
+	seen := map[models.Permission]bool{}
+	unique := []models.Permission{}
+	for _, scope := range scopes {
+		if apiKeyForbiddenScopes[scope] || !role.HasPermission(scope) {
+			return "", nil, ErrAPIKeyScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+
+	now := time.Now()
+	active, err := s.collection.CountDocuments(ctx, activeAPIKeyFilter(userID, now))
+	if err != nil {
+		return "", nil, err
+	}
+	if active >= APIKeyMaxPerUser {
+		return "", nil, ErrAPIKeyLimit
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &models.APIKey{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   sha256Hex([]byte(rawKey)),
+		Scopes:    unique,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if _, err := s.collection.InsertOne(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return rawKey, key, nil
+}
+
+// AuthenticateAPIKey - действующий ключ по открытому значению; nil без ошибки - ключ
+// неизвестен, отозван или истек. Отмечает последнее использование ключа.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey, ip string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil
+	}
+
+	var key models.APIKey
+	err := s.collection.FindOne(ctx, bson.M{"key_hash": sha256Hex([]byte(rawKey))}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, nil
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": key.ID}, bson.M{"$set": bson.M{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}})
+		if err != nil {
+			log.Printf("Error updating API key %s last use: %v", key.ID.Hex(), err)
+		}
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+	return &key, nil
+}
+
+// List - действующие ключи пользователя, новые первыми
+func (s *APIKeyService) List(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	cursor, err := s.collection.Find(ctx, activeAPIKeyFilter(userID, time.Now()),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke отзывает ключ пользователя; ключ перестает работать сразу
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID primitive.ObjectID) error {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": keyID, "user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RemoveForUser удаляет все ключи пользователя (удаление аккаунта)
+func (s *APIKeyService) RemoveForUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func activeAPIKeyFilter(userID primitive.ObjectID, now time.Time) bson.M {
+	return bson.M{
+		"user_id":    userID,
+		"revoked_at": nil,
+		"$or": []bson.M{
+			{"expires_at": nil},
+			{"expires_at": bson.M{"$gt": now}},
+		},
+	}
+}
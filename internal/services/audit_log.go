@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditLogService - журнал аудиту действий пользователей и фоновых задач (audit_logs)
+type AuditLogService struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogService(collection *mongo.Collection) *AuditLogService {
+	return &AuditLogService{
+		collection: collection,
+	}
+}
+
+// Record сохраняет запись журнала. Ошибка журнала не отменяет само действие, поэтому только логируется.
+func (s *AuditLogService) Record(ctx context.Context, entry models.AuditLogEntry) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if entry.Actor == "" {
+		entry.Actor = models.AuditActorUser
+	}
+
+	if _, err := s.collection.InsertOne(ctx, entry); err != nil {
+		log.Printf("Error recording audit log entry %s: %v", entry.Action, err)
+	}
+}
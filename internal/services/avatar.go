@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"log"
+	"strconv"
+	"time"
+
+	// Декодеры форматов, которые принимаются при загрузке
+	_ "image/gif"
+	_ "image/png"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AvatarSizes - стороны квадратных миниатюр аватара в пикселях, от большей к меньшей
+var AvatarSizes = []int{512, 128, 48}
+
+const (
+	// Изображения больше 40 Мп не декодируются: распакованное заняло бы более 160 МБ памяти
+	maxAvatarPixels = 40_000_000
+	minAvatarSide   = 32
+	avatarQuality   = 85
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageDimensions  = errors.New("image dimensions are out of range")
+)
+
+// AvatarService - загрузка аватаров: миниатюры нескольких размеров в файловом хранилище,
+// ключ хранится в users.avatar_key, а в users.avatar - постоянная ссылка на API.
+type AvatarService struct {
+	storage        FileStorage
+	userCollection *mongo.Collection
+	urlTTL         time.Duration
+}
+
+func NewAvatarService(storage FileStorage, userCollection *mongo.Collection, urlTTL time.Duration) *AvatarService {
+	return &AvatarService{storage: storage, userCollection: userCollection, urlTTL: urlTTL}
+}
+
+// AvatarPath - постоянная ссылка на аватар; перенаправляет на подписанную ссылку файла
+func AvatarPath(userID primitive.ObjectID) string {
+	return "/api/v1/users/" + userID.Hex() + "/avatar"
+}
+
+// Upload сохраняет новый аватар и удаляет файлы предыдущего. Возвращает ключ аватара.
+func (s *AvatarService) Upload(ctx context.Context, userID primitive.ObjectID, data []byte) (string, error) {
+	thumbnails, err := makeAvatarThumbnails(data)
+	if err != nil {
+		return "", err
+	}
+
+	// Новая версия в отдельном каталоге: кэшированные ссылки на старый аватар не покажут новый файл частично
+	avatarKey := "avatars/" + userID.Hex() + "/" + primitive.NewObjectID().Hex()
+	for i, size := range AvatarSizes {
+		if err := s.storage.Put(ctx, avatarFileKey(avatarKey, size), "image/jpeg", thumbnails[i]); err != nil {
+			s.deleteFiles(ctx, avatarKey)
+			return "", fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	var previous struct {
+		AvatarKey string `bson:"avatar_key"`
+	}
+	err = s.userCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{
+			"avatar":     AvatarPath(userID),
+			"avatar_key": avatarKey,
+			"updated_at": time.Now(),
+		}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"avatar_key": 1}),
+	).Decode(&previous)
+	if err != nil {
+		s.deleteFiles(ctx, avatarKey)
+		return "", err
+	}
+
+	if previous.AvatarKey != "" {
+		s.deleteFiles(ctx, previous.AvatarKey)
+	}
+	return avatarKey, nil
+}
+
+// Remove удаляет аватар пользователя. Возвращает число удаленных аватаров (0 или 1).
+func (s *AvatarService) Remove(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	var previous struct {
+		AvatarKey string `bson:"avatar_key"`
+	}
+	err := s.userCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$unset": bson.M{"avatar": "", "avatar_key": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetProjection(bson.M{"avatar_key": 1}),
+	).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if previous.AvatarKey == "" {
+		return 0, nil
+	}
+	s.deleteFiles(ctx, previous.AvatarKey)
+	return 1, nil
+}
+
+// SignedURL - временная ссылка на миниатюру, ближайшую к size (не меньше запрошенной, если есть)
+func (s *AvatarService) SignedURL(avatarKey string, size int) (string, error) {
+	chosen := AvatarSizes[0]
+	for _, candidate := range AvatarSizes {
+		if candidate >= size {
+			chosen = candidate
+		}
+	}
+	return s.storage.SignedURL(avatarFileKey(avatarKey, chosen), s.urlTTL)
+}
+
+// SignedURLs - временные ссылки на все размеры, ключ - сторона в пикселях
+func (s *AvatarService) SignedURLs(avatarKey string) (map[string]string, error) {
+	urls := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		signed, err := s.storage.SignedURL(avatarFileKey(avatarKey, size), s.urlTTL)
+		if err != nil {
+			return nil, err
+		}
+		urls[strconv.Itoa(size)] = signed
+	}
+	return urls, nil
+}
+
+// URLTTL - срок действия подписанных ссылок (для Cache-Control перенаправлений)
+func (s *AvatarService) URLTTL() time.Duration {
+	return s.urlTTL
+}
+
+// deleteFiles удаляет все миниатюры аватара; ошибки только логируются - аватар уже не используется
+func (s *AvatarService) deleteFiles(ctx context.Context, avatarKey string) {
+	for _, size := range AvatarSizes {
+		if err := s.storage.Delete(ctx, avatarFileKey(avatarKey, size)); err != nil {
+			log.Printf("Avatar storage: failed to delete %s: %v", avatarFileKey(avatarKey, size), err)
+		}
+	}
+}
+
+func avatarFileKey(avatarKey string, size int) string {
+	return avatarKey + "/" + strconv.Itoa(size) + ".jpg"
+}
+
+// makeAvatarThumbnails декодирует JPEG, PNG или GIF, обрезает по центру до квадрата
+// и уменьшает до AvatarSizes. Результат перекодируется в JPEG, поэтому EXIF
+// (включая координаты съемки) в сохраненные файлы не попадает.
+func makeAvatarThumbnails(data []byte) ([][]byte, error) {
+	// Размер проверяется по заголовку до декодирования всего файла
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width < minAvatarSide || config.Height < minAvatarSide || config.Width*config.Height > maxAvatarPixels {
+		return nil, ErrImageDimensions
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	// Квадрат по центру на белом фоне (прозрачность PNG/GIF в JPEG не сохраняется)
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), src, origin, draw.Over)
+
+	thumbnails := make([][]byte, len(AvatarSizes))
+	for i, size := range AvatarSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, downscaleSquare(square, size), &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return nil, err
+		}
+		thumbnails[i] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// downscaleSquare уменьшает квадратное изображение усреднением по области (box filter).
+// Изображения меньше size не увеличиваются.
+func downscaleSquare(src *image.RGBA, size int) *image.RGBA {
+	if src.Bounds().Dx() <= size {
+		return src
+	}
+	return downscale(src, size, size)
+}
+
+// downscale уменьшает изображение до width x height усреднением по области (box filter)
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, (y+1)*srcHeight/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, (x+1)*srcWidth/width
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for j := 0; j < len(row); j += 4 {
+					r += int(row[j])
+					g += int(row[j+1])
+					b += int(row[j+2])
+					a += int(row[j+3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
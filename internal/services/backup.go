@@ -0,0 +1,549 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	// Как часто планировщик проверяет, не пора ли делать копию
+	backupCheckInterval = 10 * time.Minute
+	// Пауза перед повтором плановой копии после неудачи
+	backupRetryDelay = time.Hour
+	// Копия, не завершенная за это время (например, сервер перезапустили), считается прерванной
+	backupRunTimeout = 2 * time.Hour
+	backupBatchSize  = 1000
+	backupKeyPrefix  = "backups"
+)
+
+var (
+	ErrBackupRunning  = errors.New("backup is already running")
+	ErrBackupNotFound = errors.New("backup not found")
+)
+
+// defaultBackupCollections - критичные коллекции, которые нельзя восстановить из других источников.
+// Очереди, сессии, коды подтверждения и журналы активности не копируются.
+var defaultBackupCollections = []string{
+	"users",
+	"communities",
+	"groups",
+	"messages",
+	"announcements",
+	"events",
+	"petitions",
+	"polls",
+	"poll_responses",
+	"city_issues",
+	"transport_routes",
+	"transport_vehicles",
+	"calendar_days",
+	"taxonomies",
+	"tags",
+	"education_institutions",
+	"enrollments",
+	"consultations",
+	"consultation_comments",
+	"content_revisions",
+	"moderation_actions",
+	"moderator_notes",
+	"faq_categories",
+	"faq_articles",
+	"banners",
+	"banned_words",
+}
+
+// BackupConfig - расписание и ротация резервных копий
+type BackupConfig struct {
+	Enabled     bool // Плановые копии; ручной запуск доступен всегда
+	Hour        int  // Час плановой копии (локальное время сервера)
+	Retention   int  // Сколько последних успешных копий хранить
+	Collections []string
+	MaxAge      time.Duration // Копия старше - предупреждение в /health/ready
+}
+
+// BackupService делает резервные копии критичных коллекций в файловое хранилище (S3).
+// Копия только читает данные - с вторичного узла, если он есть, - и не блокирует запись,
+// поэтому это не снимок на один момент времени: коллекции копируются по очереди.
+type BackupService struct {
+	db               *mongo.Database
+	backupCollection *mongo.Collection
+	storage          FileStorage
+	cfg              BackupConfig
+}
+
+func NewBackupService(db *mongo.Database, storage FileStorage, cfg BackupConfig) *BackupService {
+	if len(cfg.Collections) == 0 {
+		cfg.Collections = defaultBackupCollections
+	}
+	if cfg.Retention < 1 {
+		cfg.Retention = 1
+	}
+	return &BackupService{
+		db:               db,
+		backupCollection: db.Collection("backups"),
+		storage:          storage,
+		cfg:              cfg,
+	}
+}
+
+// NewBackupStorage - хранилище копий: отдельный bucket BACKUP_S3_BUCKET
+// или то же хранилище, что и для загруженных файлов (префикс backups/)
+func NewBackupStorage(cfg *config.Config) (FileStorage, error) {
+	switch {
+	case cfg.BackupS3Bucket != "":
+		return NewS3FileStorage(cfg.S3Endpoint, cfg.S3Region, cfg.BackupS3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PathStyle)
+	case cfg.FileStorageDriver == "s3":
+		return NewS3FileStorage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PathStyle)
+	default:
+		return NewLocalFileStorage(cfg.FileStorageDir, cfg.FileURLSecret, "/media")
+	}
+}
+
+// Trigger запускает копию вручную (администратор); копирование идет в фоне
+func (s *BackupService) Trigger(ctx context.Context, adminID primitive.ObjectID) (*models.Backup, error) {
+	backup, err := s.begin(ctx, models.BackupTriggerManual, "", &adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), backupRunTimeout)
+		defer cancel()
+		if err := s.run(runCtx, backup); err != nil {
+			log.Printf("Backup %s failed: %v", backup.ID.Hex(), err)
+		}
+	}()
+
+	return backup, nil
+}
+
+// RunScheduledIfDue делает плановую копию, если сегодня ее еще не было и час наступил
+func (s *BackupService) RunScheduledIfDue(ctx context.Context) error {
+	now := time.Now()
+	if now.Hour() < s.cfg.Hour {
+		return nil
+	}
+
+	slot := now.Format("2006-01-02")
+	done, err := s.backupCollection.CountDocuments(ctx, bson.M{"slot": slot})
+	if err != nil || done > 0 {
+		return err
+	}
+	recentFailures, err := s.backupCollection.CountDocuments(ctx, bson.M{
+		"trigger":    models.BackupTriggerScheduled,
+		"status":     models.BackupFailed,
+		"started_at": bson.M{"$gt": now.Add(-backupRetryDelay)},
+	})
+	if err != nil || recentFailures > 0 {
+		return err
+	}
+
+	backup, err := s.begin(ctx, models.BackupTriggerScheduled, slot, nil)
+	if errors.Is(err, ErrBackupRunning) {
+		// Копию уже делает другой экземпляр сервера или администратор
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.run(ctx, backup)
+}
+
+// StartScheduler запускает ежедневные плановые копии
+func (s *BackupService) StartScheduler() {
+	ticker := time.NewTicker(backupCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), backupRunTimeout)
+		if err := s.RunScheduledIfDue(ctx); err != nil {
+			log.Printf("Error running scheduled backup: %v", err)
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+// List - последние копии, новые первыми
+func (s *BackupService) List(ctx context.Context, limit int64) ([]models.Backup, error) {
+	cursor, err := s.backupCollection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	backups := []models.Backup{}
+	if err := cursor.All(ctx, &backups); err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// BackupHealth - состояние резервного копирования для /health/ready
+type BackupHealth struct {
+	Status          string     `json:"status"` // ok, stale, missing, disabled
+	LastCompletedAt *time.Time `json:"last_completed_at,omitempty"`
+	LastBackupID    string     `json:"last_backup_id,omitempty"`
+	LastAttempt     string     `json:"last_attempt_status,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	AgeHours        float64    `json:"age_hours,omitempty"`
+}
+
+// Healthy - нет оснований для предупреждения (копии выключены или свежие)
+func (h *BackupHealth) Healthy() bool {
+	return h.Status == "ok" || h.Status == "disabled"
+}
+
+func (s *BackupService) Health(ctx context.Context) (*BackupHealth, error) {
+	health := &BackupHealth{Status: "missing"}
+
+	var last models.Backup
+	err := s.backupCollection.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err == nil {
+		health.LastAttempt = last.Status
+		health.LastError = last.Error
+	}
+
+	var completed models.Backup
+	err = s.backupCollection.FindOne(ctx, bson.M{"status": models.BackupCompleted},
+		options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})).Decode(&completed)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err == nil && completed.CompletedAt != nil {
+		age := time.Since(*completed.CompletedAt)
+		health.LastCompletedAt = completed.CompletedAt
+		health.LastBackupID = completed.ID.Hex()
+		health.AgeHours = float64(int(age.Hours()*10)) / 10
+		health.Status = "ok"
+		if age > s.cfg.MaxAge {
+			health.Status = "stale"
+		}
+	}
+
+	if !s.cfg.Enabled {
+		health.Status = "disabled"
+	}
+	return health, nil
+}
+
+// begin создает запись о копии; уникальные индексы не дают запустить вторую копию одновременно
+func (s *BackupService) begin(ctx context.Context, trigger, slot string, triggeredBy *primitive.ObjectID) (*models.Backup, error) {
+	// Копии, прерванные перезапуском сервера, больше не блокируют новые
+	_, err := s.backupCollection.UpdateMany(ctx, bson.M{
+		"status":     models.BackupRunning,
+		"started_at": bson.M{"$lt": time.Now().Add(-backupRunTimeout)},
+	}, bson.M{
+		"$set":   bson.M{"status": models.BackupFailed, "error": "interrupted"},
+		"$unset": bson.M{"slot": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &models.Backup{
+		ID:          primitive.NewObjectID(),
+		Trigger:     trigger,
+		Slot:        slot,
+		TriggeredBy: triggeredBy,
+		Status:      models.BackupRunning,
+		Database:    s.db.Name(),
+		StartedAt:   time.Now(),
+		Collections: []models.BackupCollection{},
+	}
+	if _, err := s.backupCollection.InsertOne(ctx, backup); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrBackupRunning
+		}
+		return nil, err
+	}
+	return backup, nil
+}
+
+// run копирует коллекции, записывает манифест и удаляет старые копии
+func (s *BackupService) run(ctx context.Context, backup *models.Backup) error {
+	for _, name := range s.cfg.Collections {
+		collection, err := s.dumpCollection(ctx, backup, name)
+		if err != nil {
+			return s.fail(backup, fmt.Errorf("collection %s: %w", name, err))
+		}
+		backup.Collections = append(backup.Collections, *collection)
+		backup.Documents += collection.Documents
+		backup.SizeBytes += collection.SizeBytes
+	}
+
+	// Манифест пишется последним: его наличие означает, что копия полная
+	now := time.Now()
+	backup.Status = models.BackupCompleted
+	backup.CompletedAt = &now
+	backup.ManifestKey = backupKey(backup.ID, "manifest.json")
+	manifest, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return s.fail(backup, err)
+	}
+	if err := s.storage.Put(ctx, backup.ManifestKey, "application/json", manifest); err != nil {
+		return s.fail(backup, fmt.Errorf("manifest: %w", err))
+	}
+
+	_, err = s.backupCollection.UpdateOne(ctx, bson.M{"_id": backup.ID}, bson.M{"$set": bson.M{
+		"status":       backup.Status,
+		"completed_at": backup.CompletedAt,
+		"manifest_key": backup.ManifestKey,
+		"collections":  backup.Collections,
+		"documents":    backup.Documents,
+		"size_bytes":   backup.SizeBytes,
+	}})
+	if err != nil {
+		return err
+	}
+	log.Printf("Backup %s completed: %d documents, %d bytes", backup.ID.Hex(), backup.Documents, backup.SizeBytes)
+
+	if err := s.rotate(ctx); err != nil {
+		log.Printf("Error rotating backups: %v", err)
+	}
+	return nil
+}
+
+// dumpCollection - документы коллекции в Extended JSON по одному на строку, сжатые gzip
+func (s *BackupService) dumpCollection(ctx context.Context, backup *models.Backup, name string) (*models.BackupCollection, error) {
+	collection := s.db.Collection(name, options.Collection().
+		SetReadPreference(readpref.SecondaryPreferred()).
+		SetReadConcern(readconcern.Majority()))
+
+	cursor, err := collection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetBatchSize(backupBatchSize))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	var documents int64
+	for cursor.Next(ctx) {
+		line, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return nil, err
+		}
+		gz.Write(line)
+		gz.Write([]byte{'\n'})
+		documents++
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	key := backupKey(backup.ID, name+".jsonl.gz")
+	if err := s.storage.Put(ctx, key, "application/gzip", buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return &models.BackupCollection{
+		Name:      name,
+		Key:       key,
+		Documents: documents,
+		SizeBytes: int64(buf.Len()),
+		SHA256:    sha256Hex(buf.Bytes()),
+	}, nil
+}
+
+// fail помечает копию неудачной и удаляет уже загруженные файлы
+func (s *BackupService) fail(backup *models.Backup, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	s.removeFiles(ctx, backup)
+	_, err := s.backupCollection.UpdateOne(ctx, bson.M{"_id": backup.ID}, bson.M{
+		"$set": bson.M{"status": models.BackupFailed, "error": cause.Error(), "completed_at": time.Now()},
+		// Плановую копию можно повторить в тот же день
+		"$unset": bson.M{"slot": ""},
+	})
+	if err != nil {
+		log.Printf("Error marking backup %s as failed: %v", backup.ID.Hex(), err)
+	}
+	return cause
+}
+
+// rotate удаляет файлы успешных копий сверх Retention; записи остаются в истории
+func (s *BackupService) rotate(ctx context.Context) error {
+	cursor, err := s.backupCollection.Find(ctx, bson.M{"status": models.BackupCompleted},
+		options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetSkip(int64(s.cfg.Retention)))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var old []models.Backup
+	if err := cursor.All(ctx, &old); err != nil {
+		return err
+	}
+	for i := range old {
+		if err := s.removeFiles(ctx, &old[i]); err != nil {
+			return err
+		}
+		_, err := s.backupCollection.UpdateOne(ctx, bson.M{"_id": old[i].ID}, bson.M{"$set": bson.M{
+			"status": models.BackupRotated,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BackupService) removeFiles(ctx context.Context, backup *models.Backup) error {
+	// Сначала манифест: копия без манифеста не считается полной
+	if backup.ManifestKey != "" {
+		if err := s.storage.Delete(ctx, backup.ManifestKey); err != nil {
+			return err
+		}
+	}
+	for _, collection := range backup.Collections {
+		if err := s.storage.Delete(ctx, collection.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupVerification - результат пробного восстановления (restore dry-run)
+type BackupVerification struct {
+	Backup      *models.Backup          `json:"backup"`
+	Collections []BackupCollectionCheck `json:"collections"`
+	Valid       bool                    `json:"valid"`
+}
+
+// BackupCollectionCheck - проверка файла одной коллекции
+type BackupCollectionCheck struct {
+	Name      string `json:"name"`
+	Expected  int64  `json:"expected"`  // Документов по манифесту
+	Documents int64  `json:"documents"` // Прочитано из файла
+	Existing  int64  `json:"existing"`  // Сейчас в базе: были бы заменены при восстановлении (-1 - неизвестно)
+	Error     string `json:"error,omitempty"`
+}
+
+// Verify - пробное восстановление: скачивает файлы копии, сверяет контрольные суммы
+// и количество документов с манифестом и разбирает каждый документ. В базу ничего не пишется.
+// backupID "latest" - последняя успешная копия по истории в базе; иначе манифест читается
+// прямо из хранилища, поэтому проверка работает и при потерянной коллекции backups.
+func (s *BackupService) Verify(ctx context.Context, backupID string) (*BackupVerification, error) {
+	if backupID == "latest" {
+		var last models.Backup
+		err := s.backupCollection.FindOne(ctx, bson.M{"status": models.BackupCompleted},
+			options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})).Decode(&last)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrBackupNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		backupID = last.ID.Hex()
+	}
+
+	id, err := primitive.ObjectIDFromHex(backupID)
+	if err != nil {
+		return nil, ErrBackupNotFound
+	}
+	data, err := s.storage.Get(ctx, backupKey(id, "manifest.json"))
+	if errors.Is(err, ErrFileNotFound) {
+		return nil, ErrBackupNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	var backup models.Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+
+	verification := &BackupVerification{Backup: &backup, Collections: []BackupCollectionCheck{}, Valid: true}
+	for _, collection := range backup.Collections {
+		check := s.verifyCollection(ctx, collection)
+		if check.Error != "" {
+			verification.Valid = false
+		}
+		verification.Collections = append(verification.Collections, check)
+	}
+	return verification, nil
+}
+
+func (s *BackupService) verifyCollection(ctx context.Context, collection models.BackupCollection) BackupCollectionCheck {
+	check := BackupCollectionCheck{Name: collection.Name, Expected: collection.Documents, Existing: -1}
+	if existing, err := s.db.Collection(collection.Name).EstimatedDocumentCount(ctx); err == nil {
+		check.Existing = existing
+	}
+
+	data, err := s.storage.Get(ctx, collection.Key)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	if sha256Hex(data) != collection.SHA256 {
+		check.Error = "checksum mismatch"
+		return check
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	reader := bufio.NewReader(gz)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var document bson.Raw
+			if err := bson.UnmarshalExtJSON(line, true, &document); err != nil {
+				check.Error = fmt.Sprintf("document %d: %v", check.Documents+1, err)
+				return check
+			}
+			if _, err := document.LookupErr("_id"); err != nil {
+				check.Error = fmt.Sprintf("document %d: missing _id", check.Documents+1)
+				return check
+			}
+			check.Documents++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			check.Error = err.Error()
+			return check
+		}
+	}
+
+	if check.Documents != check.Expected {
+		check.Error = fmt.Sprintf("expected %d documents, found %d", check.Expected, check.Documents)
+	}
+	return check
+}
+
+func backupKey(backupID primitive.ObjectID, name string) string {
+	return backupKeyPrefix + "/" + backupID.Hex() + "/" + name
+}
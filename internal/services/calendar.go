@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrCalendarDayExists - на эту дату громада уже объявила особый день
+var ErrCalendarDayExists = errors.New("calendar day already declared")
+
+// fixedPublicHoliday - государственный праздник с фиксированной датой
+type fixedPublicHoliday struct {
+	month time.Month
+	day   int
+	name  string
+}
+
+// Государственные праздники Украины с фиксированной датой (в редакции 2023 года)
+var fixedPublicHolidays = []fixedPublicHoliday{
+	{time.January, 1, "Новий рік"},
+	{time.March, 8, "Міжнародний жіночий день"},
+	{time.May, 1, "День праці"},
+	{time.May, 8, "День пам'яті та перемоги над нацизмом у Другій світовій війні"},
+	{time.June, 28, "День Конституції України"},
+	{time.July, 15, "День Української Державності"},
+	{time.August, 24, "День незалежності України"},
+	{time.October, 1, "День захисників і захисниць України"},
+	{time.December, 25, "Різдво Христове"},
+}
+
+// CalendarService - календарь праздников и особых дней громады.
+// Используется расписанием транспорта (праздничное расписание) и всем, что зависит от рабочих дней учреждений.
+type CalendarService struct {
+	dayCollection  *mongo.Collection
+	holidaysDayOff bool // Праздники - выходные дни (выключается на время военного положения)
+}
+
+func NewCalendarService(dayCollection *mongo.Collection, holidaysDayOff bool) *CalendarService {
+	return &CalendarService{
+		dayCollection:  dayCollection,
+		holidaysDayOff: holidaysDayOff,
+	}
+}
+
+// OrthodoxEaster - дата Пасхи по юлианской пасхалии (алгоритм Меёса) в григорианском календаре
+func OrthodoxEaster(year int) time.Time {
+	a := year % 4
+	b := year % 7
+	c := year % 19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+
+	// Разница юлианского и григорианского календарей: 13 дней для 1900-2099 годов
+	return time.Date(year, time.Month(month), day+13, 0, 0, 0, 0, time.Local)
+}
+
+// PublicHolidays - государственные праздники года и выходные, перенесенные с праздников на выходных
+func (s *CalendarService) PublicHolidays(year int) []models.CalendarDay {
+	type holiday struct {
+		date time.Time
+		name string
+	}
+
+	easter := OrthodoxEaster(year)
+	holidays := []holiday{
+		{easter, "Великдень"},
+		{easter.AddDate(0, 0, 49), "Трійця"},
+	}
+	for _, fixed := range fixedPublicHolidays {
+		holidays = append(holidays, holiday{time.Date(year, fixed.month, fixed.day, 0, 0, 0, 0, time.Local), fixed.name})
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].date.Before(holidays[j].date) })
+
+	taken := make(map[string]bool, len(holidays))
+	days := make([]models.CalendarDay, 0, len(holidays)*2)
+	for _, h := range holidays {
+		date := h.date.Format(models.CalendarDateLayout)
+		taken[date] = true
+		days = append(days, models.CalendarDay{
+			Date:   date,
+			Kind:   models.CalendarDayPublicHoliday,
+			Name:   h.name,
+			DayOff: s.holidaysDayOff,
+		})
+	}
+
+	// Праздник на субботу или воскресенье переносит выходной на ближайший рабочий день (ст. 67 КЗпП)
+	if s.holidaysDayOff {
+		for _, h := range holidays {
+			if !isWeekend(h.date) {
+				continue
+			}
+			next := h.date.AddDate(0, 0, 1)
+			for isWeekend(next) || taken[next.Format(models.CalendarDateLayout)] {
+				next = next.AddDate(0, 0, 1)
+			}
+			date := next.Format(models.CalendarDateLayout)
+			taken[date] = true
+			days = append(days, models.CalendarDay{
+				Date:   date,
+				Kind:   models.CalendarDayHolidayOff,
+				Name:   "Вихідний замість свята: " + h.name,
+				DayOff: true,
+			})
+		}
+	}
+
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// Days возвращает особые дни в диапазоне [from, to]: праздники и дни, объявленные громадой.
+// scope - ограничение по громаде для объявленных дней.
+func (s *CalendarService) Days(ctx context.Context, from, to time.Time, scope bson.M) ([]models.CalendarDay, error) {
+	fromDate := from.Format(models.CalendarDateLayout)
+	toDate := to.Format(models.CalendarDateLayout)
+
+	days := []models.CalendarDay{}
+	for year := from.Year(); year <= to.Year(); year++ {
+		for _, day := range s.PublicHolidays(year) {
+			if day.Date >= fromDate && day.Date <= toDate {
+				days = append(days, day)
+			}
+		}
+	}
+
+	filter := bson.M{"date": bson.M{"$gte": fromDate, "$lte": toDate}}
+	for key, value := range scope {
+		filter[key] = value
+	}
+
+	cursor, err := s.dayCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var local []models.CalendarDay
+	if err := cursor.All(ctx, &local); err != nil {
+		return nil, err
+	}
+	for _, day := range local {
+		day.Local = true
+		days = append(days, day)
+	}
+
+	// Объявленные громадой дни идут после праздников той же даты и переопределяют их
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
+
+// Day - итог для даты: выходной ли день для учреждений и по какому расписанию ходит транспорт
+func (s *CalendarService) Day(ctx context.Context, date time.Time, scope bson.M) (models.CalendarDayInfo, error) {
+	days, err := s.Days(ctx, date, date, scope)
+	if err != nil {
+		return models.CalendarDayInfo{}, err
+	}
+	return ResolveCalendarDay(date, days), nil
+}
+
+// IsDayOff - учреждения громады не работают в эту дату
+func (s *CalendarService) IsDayOff(ctx context.Context, date time.Time, scope bson.M) (bool, error) {
+	info, err := s.Day(ctx, date, scope)
+	if err != nil {
+		return false, err
+	}
+	return info.DayOff, nil
+}
+
+// ResolveCalendarDay применяет особые дни даты поверх обычной недели:
+// сначала праздники, затем объявления громады
+func ResolveCalendarDay(date time.Time, days []models.CalendarDay) models.CalendarDayInfo {
+	info := models.CalendarDayInfo{
+		Date:        date.Format(models.CalendarDateLayout),
+		Weekday:     strings.ToLower(date.Weekday().String()),
+		DayOff:      isWeekend(date),
+		ScheduleDay: models.ScheduleDayForWeekday(date.Weekday()),
+		Days:        []models.CalendarDay{},
+	}
+
+	for _, day := range days {
+		if day.Date != info.Date {
+			continue
+		}
+		info.Days = append(info.Days, day)
+
+		if !day.Local && !day.DayOff {
+			// Праздник без выходного (военное положение) - обычный режим работы
+			continue
+		}
+		info.DayOff = day.DayOff
+		if scheduleDay := calendarScheduleDay(day); scheduleDay != "" {
+			info.ScheduleDay = scheduleDay
+		}
+	}
+	return info
+}
+
+// Declare сохраняет день, объявленный громадой
+func (s *CalendarService) Declare(ctx context.Context, day *models.CalendarDay) error {
+	day.ID = primitive.NewObjectID()
+	day.DayOff = day.Kind != models.CalendarDayWorkingDay
+	if day.CreatedAt.IsZero() {
+		day.CreatedAt = time.Now()
+	}
+
+	if _, err := s.dayCollection.InsertOne(ctx, day); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCalendarDayExists
+		}
+		return err
+	}
+	day.Local = true
+	return nil
+}
+
+// Remove удаляет объявленный громадой день
+func (s *CalendarService) Remove(ctx context.Context, id primitive.ObjectID, scope bson.M) (bool, error) {
+	filter := bson.M{"_id": id}
+	for key, value := range scope {
+		filter[key] = value
+	}
+
+	result, err := s.dayCollection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// calendarScheduleDay - расписание транспорта для особого дня; пусто - не меняется
+func calendarScheduleDay(day models.CalendarDay) string {
+	if day.ScheduleDay != "" {
+		return day.ScheduleDay
+	}
+	switch day.Kind {
+	case models.CalendarDayPublicHoliday, models.CalendarDayHolidayOff, models.CalendarDayLocalHoliday:
+		return models.ScheduleDayHoliday
+	case models.CalendarDayWorkingDay:
+		return models.ScheduleDayWeekday
+	}
+	return ""
+}
+
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCampaignNotDraft - кампания уже запущена, отменена или завершена
+var ErrCampaignNotDraft = errors.New("campaign is not a draft")
+
+const (
+	campaignEvaluatorInterval = time.Minute
+	campaignSendBatchSize     = 500
+)
+
+// CampaignService - A/B тестирование push-кампаний
+type CampaignService struct {
+	campaignCollection  *mongo.Collection
+	recipientCollection *mongo.Collection
+	userCollection      *mongo.Collection
+	notificationService *NotificationService
+}
+
+func NewCampaignService(campaignCollection, recipientCollection, userCollection *mongo.Collection, notificationService *NotificationService) *CampaignService {
+	return &CampaignService{
+		campaignCollection:  campaignCollection,
+		recipientCollection: recipientCollection,
+		userCollection:      userCollection,
+		notificationService: notificationService,
+	}
+}
+
+// Start формирует аудиторию, делит тестовую часть между вариантами A/B и отправляет их
+func (s *CampaignService) Start(ctx context.Context, campaignID primitive.ObjectID, isDefaultCommunity bool) (*models.PushCampaign, error) {
+	// Сначала переводим черновик в testing: повторный или параллельный запуск
+	// не найдет черновик и не разошлет варианты второй раз
+	now := time.Now()
+	var campaign models.PushCampaign
+	err := s.campaignCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": campaignID, "status": models.CampaignStatusDraft},
+		bson.M{"$set": bson.M{
+			"status":     models.CampaignStatusTesting,
+			"started_at": now,
+			"updated_at": now,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&campaign)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCampaignNotDraft
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := s.loadAudience(ctx, campaign.CommunityID, isDefaultCommunity)
+	if err != nil {
+		s.releaseStart(ctx, campaign.ID)
+		return nil, err
+	}
+	if len(audience) < 2 {
+		s.releaseStart(ctx, campaign.ID)
+		return nil, fmt.Errorf("audience is too small for A/B test")
+	}
+
+	// Случайное разбиение аудитории
+	rand.Shuffle(len(audience), func(i, j int) { audience[i], audience[j] = audience[j], audience[i] })
+
+	testSize := len(audience) * campaign.TestPercentage / 100
+	if testSize < 2 {
+		testSize = 2
+	}
+	half := testSize / 2
+	groupA := audience[:half]
+	groupB := audience[half:testSize]
+	rollout := audience[testSize:]
+
+	var recipients []interface{}
+	for _, userID := range groupA {
+		recipients = append(recipients, models.CampaignRecipient{CampaignID: campaign.ID, UserID: userID, Variant: models.CampaignVariantA, IsTest: true, SentAt: &now})
+	}
+	for _, userID := range groupB {
+		recipients = append(recipients, models.CampaignRecipient{CampaignID: campaign.ID, UserID: userID, Variant: models.CampaignVariantB, IsTest: true, SentAt: &now})
+	}
+	for _, userID := range rollout {
+		recipients = append(recipients, models.CampaignRecipient{CampaignID: campaign.ID, UserID: userID, Variant: models.CampaignVariantRollout})
+	}
+	if _, err := s.recipientCollection.InsertMany(ctx, recipients); err != nil {
+		s.releaseStart(ctx, campaign.ID)
+		return nil, fmt.Errorf("failed to save campaign recipients: %w", err)
+	}
+
+	for key, users := range map[string][]primitive.ObjectID{
+		models.CampaignVariantA: groupA,
+		models.CampaignVariantB: groupB,
+	} {
+		variant := campaign.GetVariant(key)
+		if variant == nil {
+			continue
+		}
+		s.sendVariant(ctx, &campaign, variant, users)
+		variant.SentCount = len(users)
+	}
+
+	evaluateAt := now.Add(time.Duration(campaign.EvaluationWindowHours) * time.Hour)
+	campaign.AudienceSize = len(audience)
+	campaign.EvaluateAt = &evaluateAt
+
+	// Статус не перезаписываем: отмена во время рассылки остается в силе
+	_, err = s.campaignCollection.UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$set": bson.M{
+		"variants":      campaign.Variants,
+		"audience_size": campaign.AudienceSize,
+		"evaluate_at":   evaluateAt,
+		"updated_at":    time.Now(),
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+// releaseStart возвращает кампанию в черновик, если запуск сорвался до рассылки,
+// и удаляет уже сохраненных получателей, чтобы повторный запуск собрал аудиторию заново
+func (s *CampaignService) releaseStart(ctx context.Context, campaignID primitive.ObjectID) {
+	if _, err := s.recipientCollection.DeleteMany(ctx, bson.M{"campaign_id": campaignID}); err != nil {
+		log.Printf("Error removing recipients of campaign %s: %v", campaignID.Hex(), err)
+		return
+	}
+	if _, err := s.campaignCollection.UpdateOne(ctx,
+		bson.M{"_id": campaignID, "status": models.CampaignStatusTesting},
+		bson.M{
+			"$set":   bson.M{"status": models.CampaignStatusDraft, "updated_at": time.Now()},
+			"$unset": bson.M{"started_at": ""},
+		},
+	); err != nil {
+		log.Printf("Error releasing campaign %s: %v", campaignID.Hex(), err)
+	}
+}
+
+// TrackOpen фиксирует открытие уведомления кампании пользователем
+func (s *CampaignService) TrackOpen(ctx context.Context, campaignID, userID primitive.ObjectID) error {
+	var recipient models.CampaignRecipient
+	err := s.recipientCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"campaign_id": campaignID,
+			"user_id":     userID,
+			"opened_at":   nil,
+		},
+		bson.M{"$set": bson.M{"opened_at": time.Now()}},
+	).Decode(&recipient)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil // Уже открыто или пользователь не из кампании
+		}
+		return err
+	}
+
+	// Открытия победителя после раскатки в сравнении вариантов не участвуют
+	if !recipient.IsTest {
+		return nil
+	}
+
+	_, err = s.campaignCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": campaignID, "variants.key": recipient.Variant},
+		bson.M{"$inc": bson.M{"variants.$.open_count": 1}},
+	)
+	return err
+}
+
+// StartEvaluator периодически подводит итоги тестов и раскатывает победителя
+func (s *CampaignService) StartEvaluator() {
+	ticker := time.NewTicker(campaignEvaluatorInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.evaluateDue()
+	}
+}
+
+func (s *CampaignService) evaluateDue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := s.campaignCollection.Find(ctx, bson.M{
+		"status":      models.CampaignStatusTesting,
+		"evaluate_at": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		log.Printf("Error fetching campaigns for evaluation: %v", err)
+		return
+	}
+
+	var campaigns []models.PushCampaign
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		log.Printf("Error decoding campaigns: %v", err)
+		return
+	}
+
+	for i := range campaigns {
+		if err := s.rolloutWinner(ctx, &campaigns[i]); err != nil {
+			log.Printf("Error rolling out campaign %s: %v", campaigns[i].ID.Hex(), err)
+			s.campaignCollection.UpdateOne(ctx, bson.M{"_id": campaigns[i].ID}, bson.M{"$set": bson.M{
+				"last_run_error": err.Error(),
+				"updated_at":     time.Now(),
+			}})
+		}
+	}
+}
+
+// PickWinner выбирает вариант с лучшей долей открытий (при равенстве - A)
+func PickWinner(campaign *models.PushCampaign) *models.CampaignVariant {
+	var winner *models.CampaignVariant
+	for i := range campaign.Variants {
+		variant := &campaign.Variants[i]
+		if winner == nil || variant.OpenRate() > winner.OpenRate() {
+			winner = variant
+		}
+	}
+	return winner
+}
+
+func (s *CampaignService) rolloutWinner(ctx context.Context, campaign *models.PushCampaign) error {
+	winner := PickWinner(campaign)
+	if winner == nil {
+		return fmt.Errorf("campaign has no variants")
+	}
+
+	// Помечаем кампанию, чтобы параллельный запуск не отправил победителя повторно
+	result, err := s.campaignCollection.UpdateOne(ctx,
+		bson.M{"_id": campaign.ID, "status": models.CampaignStatusTesting},
+		bson.M{"$set": bson.M{"winner_key": winner.Key, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return nil
+	}
+
+	rolloutCount := 0
+	for {
+		cursor, err := s.recipientCollection.Find(ctx, bson.M{
+			"campaign_id": campaign.ID,
+			"variant":     models.CampaignVariantRollout,
+			"sent_at":     nil,
+		}, options.Find().SetLimit(campaignSendBatchSize))
+		if err != nil {
+			return err
+		}
+
+		var batch []models.CampaignRecipient
+		if err := cursor.All(ctx, &batch); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		userIDs := make([]primitive.ObjectID, 0, len(batch))
+		recipientIDs := make([]primitive.ObjectID, 0, len(batch))
+		for _, recipient := range batch {
+			userIDs = append(userIDs, recipient.UserID)
+			recipientIDs = append(recipientIDs, recipient.ID)
+		}
+
+		s.sendVariant(ctx, campaign, winner, userIDs)
+		if _, err := s.recipientCollection.UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": recipientIDs}},
+			bson.M{"$set": bson.M{"sent_at": time.Now()}},
+		); err != nil {
+			return err
+		}
+		rolloutCount += len(batch)
+	}
+
+	now := time.Now()
+	_, err = s.campaignCollection.UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$set": bson.M{
+		"status":        models.CampaignStatusCompleted,
+		"rollout_count": rolloutCount,
+		"completed_at":  now,
+		"updated_at":    now,
+	}})
+	return err
+}
+
+func (s *CampaignService) sendVariant(ctx context.Context, campaign *models.PushCampaign, variant *models.CampaignVariant, userIDs []primitive.ObjectID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	data := map[string]interface{}{
+		"type":        NotificationTypeCampaign,
+		"campaign_id": campaign.ID.Hex(),
+		"variant":     variant.Key,
+	}
+	campaignID := campaign.ID
+	if err := s.notificationService.SendNotificationToUsers(ctx, userIDs, variant.Title, variant.Body, NotificationTypeCampaign, data, &campaignID); err != nil {
+		// Уведомления сохранены в базе, ошибка касается только FCM доставки
+		log.Printf("Error sending campaign %s variant %s: %v", campaign.ID.Hex(), variant.Key, err)
+	}
+}
+
+func (s *CampaignService) loadAudience(ctx context.Context, communityID primitive.ObjectID, isDefaultCommunity bool) ([]primitive.ObjectID, error) {
+	filter := bson.M{"is_blocked": false}
+	if !communityID.IsZero() {
+		if isDefaultCommunity {
+			// Пользователи, зарегистрированные до multi-tenancy, относятся к громаде по умолчанию
+			filter["$or"] = []bson.M{
+				{"community_ids": communityID},
+				{"community_ids": bson.M{"$exists": false}},
+				{"community_ids": bson.M{"$size": 0}},
+			}
+		} else {
+			filter["community_ids"] = communityID
+		}
+	}
+
+	cursor, err := s.userCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audience: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var userIDs []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var row struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err == nil {
+			userIDs = append(userIDs, row.ID)
+		}
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func testCampaignService(mt *mtest.T) *CampaignService {
+	return NewCampaignService(mt.DB.Collection("push_campaigns"), mt.DB.Collection("campaign_recipients"), mt.DB.Collection("users"), nil)
+}
+
+// Повторний або паралельний запуск не знаходить чернетку і нічого не розсилає
+func TestCampaignStartClaimsDraft(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("already started", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+
+		_, err := testCampaignService(mt).Start(context.Background(), primitive.NewObjectID(), false)
+		if !errors.Is(err, ErrCampaignNotDraft) {
+			mt.Fatalf("got %v, want ErrCampaignNotDraft", err)
+		}
+
+		events := mt.GetAllStartedEvents()
+		if len(events) != 1 || events[0].CommandName != "findAndModify" {
+			mt.Fatalf("start must only claim the draft, sent %d commands", len(events))
+		}
+		if status := events[0].Command.Lookup("query", "status").StringValue(); status != models.CampaignStatusDraft {
+			mt.Errorf("claim filter status = %q, want %q", status, models.CampaignStatusDraft)
+		}
+	})
+
+	mt.Run("audience too small", func(mt *mtest.T) {
+		campaign := bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "status", Value: models.CampaignStatusTesting},
+		}
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: campaign}),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".users", mtest.FirstBatch, bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		if _, err := testCampaignService(mt).Start(context.Background(), primitive.NewObjectID(), false); err == nil {
+			mt.Fatal("start with one recipient must fail")
+		}
+
+		// Кампанія повертається в чернетку, щоб її можна було запустити знову
+		events := mt.GetAllStartedEvents()
+		release := events[len(events)-1]
+		if release.CommandName != "update" {
+			mt.Fatalf("campaign was not released back to draft, last command %s", release.CommandName)
+		}
+		if status := release.Command.Lookup("updates", "0", "u", "$set", "status").StringValue(); status != models.CampaignStatusDraft {
+			mt.Errorf("released status = %q, want %q", status, models.CampaignStatusDraft)
+		}
+	})
+}
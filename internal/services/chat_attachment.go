@@ -0,0 +1,449 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// Большая сторона миниатюры изображения в пикселях
+	chatThumbnailSide = 320
+	// Как часто удаляются загрузки, не прикрепленные к сообщениям
+	chatUploadCleanupInterval = time.Hour
+	maxAttachmentFileName     = 255
+)
+
+var (
+	ErrUnsupportedAttachment = errors.New("unsupported attachment type")
+	ErrAttachmentNotFound    = errors.New("attachment not found or already used")
+)
+
+// AttachmentTooLargeError - файл больше допустимого для его вида
+type AttachmentTooLargeError struct {
+	Kind     string
+	MaxBytes int64
+}
+
+func (e *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("%s attachment exceeds %d bytes", e.Kind, e.MaxBytes)
+}
+
+// Офисные документы - ZIP-архивы: тип определяется по расширению после проверки сигнатуры
+var zipDocumentTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+}
+
+// Расширение файла в хранилище по проверенному MIME-типу: локальное хранилище отдает
+// Content-Type по расширению, поэтому расширение из имени клиента не используется
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+	"audio/mpeg":      ".mp3",
+	"audio/ogg":       ".ogg",
+	"audio/wave":      ".wav",
+	"application/ogg": ".ogg",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// ChatAttachmentService - вложения сообщений групп: файл загружается заранее
+// (POST /groups/:id/attachments), сообщение ссылается на него по ID.
+// Тип проверяется по содержимому, а не по заголовку клиента; для изображений
+// сохраняется миниатюра, из JPEG удаляются EXIF и XMP (координаты съемки).
+type ChatAttachmentService struct {
+	storage    FileStorage
+	collection *mongo.Collection // chat_uploads
+	maxBytes   int64
+	urlTTL     time.Duration
+}
+
+func NewChatAttachmentService(storage FileStorage, collection *mongo.Collection, maxBytes int64, urlTTL time.Duration) *ChatAttachmentService {
+	return &ChatAttachmentService{storage: storage, collection: collection, maxBytes: maxBytes, urlTTL: urlTTL}
+}
+
+// MaxBytes - максимальный размер загружаемого файла любого вида
+func (s *ChatAttachmentService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// MaxBytesFor - максимальный размер вложения вида kind с учетом общего лимита
+func (s *ChatAttachmentService) MaxBytesFor(kind string) int64 {
+	if limit, ok := models.MaxAttachmentSizes[kind]; ok && limit < s.maxBytes {
+		return limit
+	}
+	return s.maxBytes
+}
+
+// DetectAttachmentType - MIME-тип и вид вложения по содержимому файла
+func DetectAttachmentType(fileName string, data []byte) (string, string, error) {
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "", "", ErrUnsupportedAttachment
+	}
+	if mimeType == "application/zip" {
+		mimeType = zipDocumentTypes[strings.ToLower(path.Ext(fileName))]
+	}
+	// text/plain определяется и для бинарных данных без сигнатуры: допускается только UTF-8
+	if mimeType == "text/plain" && !utf8.Valid(data) {
+		return "", "", ErrUnsupportedAttachment
+	}
+
+	kind, ok := models.AttachmentMimeTypes[mimeType]
+	if !ok {
+		return "", "", ErrUnsupportedAttachment
+	}
+	return mimeType, kind, nil
+}
+
+// Upload проверяет и сохраняет файл вложения автора в группе
+func (s *ChatAttachmentService) Upload(ctx context.Context, groupID, userID primitive.ObjectID, fileName string, data []byte) (*models.ChatUpload, error) {
+	mimeType, kind, err := DetectAttachmentType(fileName, data)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes := s.MaxBytesFor(kind); int64(len(data)) > maxBytes {
+		return nil, &AttachmentTooLargeError{Kind: kind, MaxBytes: maxBytes}
+	}
+
+	upload := &models.ChatUpload{
+		MessageAttachment: models.MessageAttachment{
+			ID:       primitive.NewObjectID(),
+			Kind:     kind,
+			FileName: sanitizeFileName(fileName),
+			MimeType: mimeType,
+		},
+		GroupID:   groupID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+
+	var thumbnail []byte
+	if kind == models.MediaTypeImage {
+		if mimeType == "image/jpeg" {
+			if data, err = stripJPEGMetadata(data); err != nil {
+				return nil, err
+			}
+		}
+		thumbnail, upload.Width, upload.Height, err = makeChatThumbnail(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	upload.Size = int64(len(data))
+
+	// Каталог загрузки: оригинал и миниатюра удаляются вместе
+	baseKey := "chat/" + groupID.Hex() + "/" + upload.ID.Hex()
+	upload.FileKey = baseKey + "/original" + attachmentExtension(mimeType)
+	if err := s.storage.Put(ctx, upload.FileKey, mimeType, data); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if thumbnail != nil {
+		upload.ThumbnailKey = baseKey + "/thumbnail.jpg"
+		if err := s.storage.Put(ctx, upload.ThumbnailKey, "image/jpeg", thumbnail); err != nil {
+			s.deleteFiles(ctx, &upload.MessageAttachment)
+			return nil, fmt.Errorf("failed to store thumbnail: %w", err)
+		}
+	}
+
+	if _, err := s.collection.InsertOne(ctx, upload); err != nil {
+		s.deleteFiles(ctx, &upload.MessageAttachment)
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Claim прикрепляет загрузки автора к сообщению messageID. Каждая загрузка используется один раз;
+// если хотя бы одна не найдена, ни одна не прикрепляется. Возвращает вложения в порядке ids.
+func (s *ChatAttachmentService) Claim(ctx context.Context, groupID, userID, messageID primitive.ObjectID, ids []primitive.ObjectID) ([]models.MessageAttachment, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{
+			"_id":        bson.M{"$in": ids},
+			"group_id":   groupID,
+			"user_id":    userID,
+			"message_id": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"message_id": messageID}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.ModifiedCount != int64(len(ids)) {
+		s.Release(ctx, messageID)
+		return nil, ErrAttachmentNotFound
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{"message_id": messageID})
+	if err != nil {
+		s.Release(ctx, messageID)
+		return nil, err
+	}
+	var uploads []models.ChatUpload
+	if err := cursor.All(ctx, &uploads); err != nil {
+		s.Release(ctx, messageID)
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]models.MessageAttachment, len(uploads))
+	for _, upload := range uploads {
+		byID[upload.ID] = upload.MessageAttachment
+	}
+	attachments := make([]models.MessageAttachment, 0, len(ids))
+	for _, id := range ids {
+		attachments = append(attachments, byID[id])
+	}
+	return attachments, nil
+}
+
+// Release возвращает загрузки сообщения, которое не удалось сохранить
+func (s *ChatAttachmentService) Release(ctx context.Context, messageID primitive.ObjectID) {
+	if _, err := s.collection.UpdateMany(ctx,
+		bson.M{"message_id": messageID},
+		bson.M{"$unset": bson.M{"message_id": ""}},
+	); err != nil {
+		log.Printf("Chat attachments: failed to release uploads of message %s: %v", messageID.Hex(), err)
+	}
+}
+
+// Sign заполняет временные ссылки на файлы вложений
+func (s *ChatAttachmentService) Sign(attachments []models.MessageAttachment) {
+	for i := range attachments {
+		attachment := &attachments[i]
+		if attachment.FileKey == "" {
+			continue
+		}
+		if signed, err := s.storage.SignedURL(attachment.FileKey, s.urlTTL); err == nil {
+			attachment.URL = signed
+		}
+		if attachment.ThumbnailKey != "" {
+			if signed, err := s.storage.SignedURL(attachment.ThumbnailKey, s.urlTTL); err == nil {
+				attachment.ThumbnailURL = signed
+			}
+		}
+	}
+}
+
+// SignMessages заполняет ссылки на вложения сообщений
+func (s *ChatAttachmentService) SignMessages(messages []models.Message) {
+	for i := range messages {
+		s.Sign(messages[i].Attachments)
+	}
+}
+
+// RemoveForMessage удаляет файлы вложений удаленного сообщения
+func (s *ChatAttachmentService) RemoveForMessage(ctx context.Context, messageID primitive.ObjectID) (int64, error) {
+	return s.remove(ctx, bson.M{"message_id": messageID})
+}
+
+// RemoveForGroup удаляет все файлы чата удаленной группы
+func (s *ChatAttachmentService) RemoveForGroup(ctx context.Context, groupID primitive.ObjectID) (int64, error) {
+	return s.remove(ctx, bson.M{"group_id": groupID})
+}
+
+// RemoveForUser удаляет все загруженные пользователем файлы (удаление аккаунта)
+func (s *ChatAttachmentService) RemoveForUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return s.remove(ctx, bson.M{"user_id": userID})
+}
+
+// remove удаляет файлы и записи загрузок по фильтру. Возвращает число удаленных загрузок.
+func (s *ChatAttachmentService) remove(ctx context.Context, filter bson.M) (int64, error) {
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	var uploads []models.ChatUpload
+	if err := cursor.All(ctx, &uploads); err != nil {
+		return 0, err
+	}
+	if len(uploads) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(uploads))
+	for i := range uploads {
+		s.deleteFiles(ctx, &uploads[i].MessageAttachment)
+		ids = append(ids, uploads[i].ID)
+	}
+	result, err := s.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// StartCleanup раз в час удаляет загрузки, которые не прикрепили к сообщению за models.ChatUploadTTL
+func (s *ChatAttachmentService) StartCleanup() {
+	ticker := time.NewTicker(chatUploadCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		removed, err := s.remove(ctx, bson.M{
+			"message_id": bson.M{"$exists": false},
+			"created_at": bson.M{"$lt": time.Now().Add(-models.ChatUploadTTL)},
+		})
+		cancel()
+		if err != nil {
+			log.Printf("Chat attachments: failed to remove unused uploads: %v", err)
+		} else if removed > 0 {
+			log.Printf("Chat attachments: removed %d unused uploads", removed)
+		}
+	}
+}
+
+// deleteFiles удаляет оригинал и миниатюру; ошибки только логируются
+func (s *ChatAttachmentService) deleteFiles(ctx context.Context, attachment *models.MessageAttachment) {
+	for _, key := range []string{attachment.FileKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Chat attachments: failed to delete %s: %v", key, err)
+		}
+	}
+}
+
+// attachmentExtension - расширение файла вложения в хранилище
+func attachmentExtension(mimeType string) string {
+	if ext, ok := attachmentExtensions[mimeType]; ok {
+		return ext
+	}
+	for ext, zipType := range zipDocumentTypes {
+		if zipType == mimeType {
+			return ext
+		}
+	}
+	return ".bin"
+}
+
+// sanitizeFileName - имя файла без пути и управляющих символов, не длиннее maxAttachmentFileName байт
+func sanitizeFileName(fileName string) string {
+	fileName = path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	fileName = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, fileName)
+	if fileName == "." || fileName == "/" || fileName == "" {
+		return "file"
+	}
+	for len(fileName) > maxAttachmentFileName {
+		_, size := utf8.DecodeLastRuneInString(fileName)
+		fileName = fileName[:len(fileName)-size]
+	}
+	return fileName
+}
+
+// makeChatThumbnail - JPEG не больше chatThumbnailSide по большей стороне и размеры оригинала.
+// WebP стандартная библиотека не декодирует: такие изображения сохраняются без миниатюры.
+func makeChatThumbnail(data []byte) ([]byte, int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if http.DetectContentType(data) == "image/webp" {
+			return nil, 0, 0, nil
+		}
+		return nil, 0, 0, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return nil, 0, 0, ErrImageDimensions
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, ErrUnsupportedImage
+	}
+
+	// Прозрачность PNG/GIF в JPEG не сохраняется: белый фон
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	thumbnail := flat
+	if width > chatThumbnailSide || height > chatThumbnailSide {
+		if width >= height {
+			width, height = chatThumbnailSide, max(1, height*chatThumbnailSide/width)
+		} else {
+			width, height = max(1, width*chatThumbnailSide/height), chatThumbnailSide
+		}
+		thumbnail = downscale(flat, width, height)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: avatarQuality}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), bounds.Dx(), bounds.Dy(), nil
+}
+
+// stripJPEGMetadata удаляет сегменты APP1 (EXIF, XMP) из JPEG без перекодирования изображения
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrUnsupportedImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, ErrUnsupportedImage
+		}
+		marker := data[pos+1]
+		// Начало данных изображения: дальше метаданных нет
+		if marker == 0xDA {
+			return append(out, data[pos:]...), nil
+		}
+		// Заполняющий байт перед маркером
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		// Маркеры без длины
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrUnsupportedImage
+		}
+		if marker != 0xE1 {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return nil, ErrUnsupportedImage
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChatReplayLimit - сколько неподтвержденных сообщений досылается при подключении.
+// Остальные клиент загружает через GET /groups/:id/messages.
+const ChatReplayLimit = 200
+
+// ChatDeliveryService хранит позиции доставки сообщений групп по сессиям (устройствам)
+// участников. Сообщения группы после позиции - офлайн-очередь сессии: при переподключении
+// WebSocket они досылаются по порядку ID. Позицию двигают подтверждения клиента (кадр ack).
+type ChatDeliveryService struct {
+	deliveryCollection  *mongo.Collection
+	readStateCollection *mongo.Collection
+	messageCollection   *mongo.Collection
+	attachments         *ChatAttachmentService
+}
+
+func NewChatDeliveryService(deliveryCollection, readStateCollection, messageCollection *mongo.Collection, attachments *ChatAttachmentService) *ChatDeliveryService {
+	return &ChatDeliveryService{
+		deliveryCollection:  deliveryCollection,
+		readStateCollection: readStateCollection,
+		messageCollection:   messageCollection,
+		attachments:         attachments,
+	}
+}
+
+func deliveryStateFilter(groupID, userID primitive.ObjectID, sessionID string) bson.M {
+	return bson.M{"group_id": groupID, "user_id": userID, "session_id": sessionID}
+}
+
+// Position возвращает позицию доставки сессии и отмечает подключение. Первая позиция сессии -
+// позиция чтения участника, а без нее последнее сообщение группы: история не досылается.
+func (s *ChatDeliveryService) Position(ctx context.Context, groupID, userID primitive.ObjectID, sessionID string) (primitive.ObjectID, error) {
+	filter := deliveryStateFilter(groupID, userID, sessionID)
+	now := time.Now()
+
+	var state models.GroupDeliveryState
+	err := s.deliveryCollection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"updated_at": now}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"last_delivered_message_id": 1}),
+	).Decode(&state)
+	if err == nil {
+		return state.LastDeliveredMessageID, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, err
+	}
+
+	initial, err := s.initialPosition(ctx, groupID, userID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	_, err = s.deliveryCollection.UpdateOne(ctx, filter,
+		bson.M{
+			"$setOnInsert": bson.M{"last_delivered_message_id": initial},
+			"$set":         bson.M{"updated_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	// Параллельное подключение той же сессии уже создало позицию
+	if mongo.IsDuplicateKeyError(err) {
+		err = s.deliveryCollection.FindOne(ctx, filter).Decode(&state)
+		return state.LastDeliveredMessageID, err
+	}
+	return initial, err
+}
+
+func (s *ChatDeliveryService) initialPosition(ctx context.Context, groupID, userID primitive.ObjectID) (primitive.ObjectID, error) {
+	var readState models.GroupReadState
+	err := s.readStateCollection.FindOne(ctx, bson.M{"group_id": groupID, "user_id": userID}).Decode(&readState)
+	if err == nil {
+		return readState.LastReadMessageID, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, err
+	}
+
+	var latest struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err = s.messageCollection.FindOne(ctx,
+		bson.M{"group_id": groupID},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}).SetProjection(bson.M{"_id": 1}),
+	).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, nil
+	}
+	return latest.ID, err
+}
+
+// Backlog - сообщения группы после позиции по порядку ID, не больше limit.
+// hasMore - неподтвержденных сообщений больше limit.
+func (s *ChatDeliveryService) Backlog(ctx context.Context, groupID, after primitive.ObjectID, limit int) ([]models.Message, bool, error) {
+	filter := bson.M{
+		"group_id":   groupID,
+		"is_deleted": false,
+		"is_held":    bson.M{"$ne": true},
+	}
+	if !after.IsZero() {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	cursor, err := s.messageCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit+1)))
+	if err != nil {
+		return nil, false, err
+	}
+	var messages []models.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+	s.attachments.SignMessages(messages)
+	return messages, hasMore, nil
+}
+
+// Ack переносит позицию доставки сессии вперед; подтверждение накопительное и
+// назад позицию не возвращает
+func (s *ChatDeliveryService) Ack(ctx context.Context, groupID, userID primitive.ObjectID, sessionID string, messageID primitive.ObjectID) error {
+	filter := deliveryStateFilter(groupID, userID, sessionID)
+	filter["$or"] = []bson.M{
+		{"last_delivered_message_id": bson.M{"$lt": messageID}},
+		{"last_delivered_message_id": bson.M{"$exists": false}},
+	}
+
+	_, err := s.deliveryCollection.UpdateOne(ctx, filter,
+		bson.M{"$set": bson.M{
+			"last_delivered_message_id": messageID,
+			"updated_at":                time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	// Позиция уже дальше: фильтр не совпал, а вставка уперлась в уникальный индекс
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrBannedWordExists - слово уже есть в фильтре
+var ErrBannedWordExists = errors.New("banned word already exists")
+
+// Как часто правила фильтра перечитываются из базы: изменения, сделанные на другом
+// экземпляре сервера, применяются не позже этого интервала
+const chatFilterReload = time.Minute
+
+// ChatFilterResult - срабатывание фильтра на тексте сообщения
+type ChatFilterResult struct {
+	Action  string   // Пусто - фильтр не сработал; hide важнее flag
+	Matches []string // Найденные запрещенные слова
+}
+
+// Matched - в тексте есть запрещенные слова
+func (r ChatFilterResult) Matched() bool {
+	return r.Action != ""
+}
+
+// ChatFilterService - фильтр запрещенных слов в сообщениях групп. Правила задает
+// администратор; в зависимости от правила сообщение отправляется в очередь модератора
+// или удерживается до проверки.
+type ChatFilterService struct {
+	collection *mongo.Collection
+
+	mu       sync.RWMutex
+	words    map[string]string // Слово -> действие
+	prefixes map[string]string // Начало слова (правило "спам*") -> действие
+	loadedAt time.Time
+}
+
+func NewChatFilterService(collection *mongo.Collection) *ChatFilterService {
+	return &ChatFilterService{collection: collection}
+}
+
+// isWordRune - буквы, цифры и апостроф (м'ясо, з’їзд) входят в слово
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == 'ʼ'
+}
+
+// normalizeWord приводит слово к виду, в котором правило хранится и сравнивается
+func normalizeWord(word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	return strings.NewReplacer("’", "'", "ʼ", "'").Replace(word)
+}
+
+// NormalizeBannedWord проверяет и нормализует правило: одно слово, звездочка допускается
+// только в конце. false - правило некорректно.
+func NormalizeBannedWord(word string) (string, bool) {
+	word = normalizeWord(word)
+	stem := strings.TrimSuffix(word, "*")
+	if stem == "" || len([]rune(word)) > models.MaxBannedWordLength {
+		return "", false
+	}
+	for _, r := range stem {
+		if !isWordRune(r) {
+			return "", false
+		}
+	}
+	return word, true
+}
+
+// Check проверяет текст сообщения. При недоступности базы используются последние
+// загруженные правила.
+func (s *ChatFilterService) Check(ctx context.Context, text string) ChatFilterResult {
+	s.ensureLoaded(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result ChatFilterResult
+	if len(s.words) == 0 && len(s.prefixes) == 0 {
+		return result
+	}
+
+	seen := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+		token = strings.Trim(normalizeWord(token), "'")
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		action, ok := s.words[token]
+		if !ok {
+			for prefix, prefixAction := range s.prefixes {
+				if strings.HasPrefix(token, prefix) {
+					action, ok = prefixAction, true
+					if action == models.BannedWordHide {
+						break
+					}
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		result.Matches = append(result.Matches, token)
+		if result.Action != models.BannedWordHide {
+			result.Action = action
+		}
+	}
+	return result
+}
+
+// ensureLoaded перечитывает правила, если кэш устарел
+func (s *ChatFilterService) ensureLoaded(ctx context.Context) {
+	s.mu.RLock()
+	fresh := !s.loadedAt.IsZero() && time.Since(s.loadedAt) < chatFilterReload
+	s.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	rules, err := s.List(ctx)
+	if err != nil {
+		log.Printf("Error loading banned words, using cached rules: %v", err)
+		// Повторная попытка - через интервал, а не на каждом сообщении
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+		return
+	}
+
+	words := make(map[string]string)
+	prefixes := make(map[string]string)
+	for _, rule := range rules {
+		if stem, ok := strings.CutSuffix(rule.Word, "*"); ok {
+			prefixes[stem] = rule.Action
+		} else {
+			words[rule.Word] = rule.Action
+		}
+	}
+
+	s.mu.Lock()
+	s.words = words
+	s.prefixes = prefixes
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// invalidate - изменения правил применяются на этом экземпляре сразу
+func (s *ChatFilterService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// List возвращает правила фильтра по алфавиту
+func (s *ChatFilterService) List(ctx context.Context) ([]models.BannedWord, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "word", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	words := []models.BannedWord{}
+	if err := cursor.All(ctx, &words); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// Add добавляет правило; слово должно быть нормализовано NormalizeBannedWord
+func (s *ChatFilterService) Add(ctx context.Context, word *models.BannedWord) error {
+	word.ID = primitive.NewObjectID()
+	word.CreatedAt = time.Now()
+
+	if _, err := s.collection.InsertOne(ctx, word); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrBannedWordExists
+		}
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Remove удаляет правило; false - правила нет
+func (s *ChatFilterService) Remove(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	s.invalidate()
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Коды ошибок ограничения чата
+const (
+	ChatLimitCodeRateLimited = "CHAT_RATE_LIMITED"
+	ChatLimitCodeSlowMode    = "SLOW_MODE"
+)
+
+// ChatLimitError - сообщение отклонено ограничением частоты
+type ChatLimitError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+func (e *ChatLimitError) Error() string {
+	if e.Code == ChatLimitCodeSlowMode {
+		return fmt.Sprintf("slow mode is enabled, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many messages, retry after %s", e.RetryAfter)
+}
+
+// RetryAfterSeconds - время ожидания, округленное вверх до секунды
+func (e *ChatLimitError) RetryAfterSeconds() int {
+	seconds := int(e.RetryAfter / time.Second)
+	if e.RetryAfter%time.Second > 0 {
+		seconds++
+	}
+	return seconds
+}
+
+type chatSlowModeKey struct {
+	groupID primitive.ObjectID
+	userID  primitive.ObjectID
+}
+
+// ChatLimiter - лимит сообщений пользователя и slow mode групп.
+// Общий для REST (SendMessage) и WebSocket, чтобы ограничения нельзя было обойти сменой транспорта.
+type ChatLimiter struct {
+	maxMessages int
+	window      time.Duration
+
+	mu           sync.Mutex
+	userMessages map[primitive.ObjectID][]time.Time
+	lastInGroup  map[chatSlowModeKey]time.Time
+}
+
+func NewChatLimiter(maxMessages int, window time.Duration) *ChatLimiter {
+	return &ChatLimiter{
+		maxMessages:  maxMessages,
+		window:       window,
+		userMessages: make(map[primitive.ObjectID][]time.Time),
+		lastInGroup:  make(map[chatSlowModeKey]time.Time),
+	}
+}
+
+// Allow проверяет ограничения и при успехе учитывает сообщение.
+// slowMode = 0 отключает slow mode (например, для администраторов группы).
+func (l *ChatLimiter) Allow(userID, groupID primitive.ObjectID, slowMode time.Duration) error {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Slow mode: одно сообщение в N секунд в группе
+	key := chatSlowModeKey{groupID: groupID, userID: userID}
+	if slowMode > 0 {
+		if last, ok := l.lastInGroup[key]; ok {
+			if elapsed := now.Sub(last); elapsed < slowMode {
+				return &ChatLimitError{Code: ChatLimitCodeSlowMode, RetryAfter: slowMode - elapsed}
+			}
+		}
+	}
+
+	// Скользящее окно сообщений пользователя по всем группам
+	if l.maxMessages > 0 {
+		cutoff := now.Add(-l.window)
+		recent := l.userMessages[userID][:0]
+		for _, sentAt := range l.userMessages[userID] {
+			if sentAt.After(cutoff) {
+				recent = append(recent, sentAt)
+			}
+		}
+		if len(recent) >= l.maxMessages {
+			l.userMessages[userID] = recent
+			return &ChatLimitError{Code: ChatLimitCodeRateLimited, RetryAfter: recent[0].Add(l.window).Sub(now)}
+		}
+		l.userMessages[userID] = append(recent, now)
+	}
+
+	l.lastInGroup[key] = now
+	return nil
+}
+
+// StartCleanup периодически удаляет устаревшие записи
+func (l *ChatLimiter) StartCleanup() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		l.cleanup()
+	}
+}
+
+func (l *ChatLimiter) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for userID, times := range l.userMessages {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) > l.window {
+			delete(l.userMessages, userID)
+		}
+	}
+	// Slow mode ограничен часом, более старые записи не нужны
+	for key, last := range l.lastInGroup {
+		if now.Sub(last) > time.Hour {
+			delete(l.lastInGroup, key)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Время жизни кэша громад
+const communityCacheTTL = time.Minute
+
+// CommunityService определяет громаду для запроса и кэширует список громад
+type CommunityService struct {
+	communityCollection *mongo.Collection
+	defaultCode         string
+	staticHosts         map[string]string // host -> code из конфигурации
+
+	mu       sync.RWMutex
+	byCode   map[string]*models.Community
+	byHost   map[string]*models.Community
+	loadedAt time.Time
+}
+
+func NewCommunityService(cfg *config.Config, communityCollection *mongo.Collection) *CommunityService {
+	defaultCode := cfg.DefaultCommunity
+	if defaultCode == "" {
+		defaultCode = models.DefaultCommunityCode
+	}
+
+	return &CommunityService{
+		communityCollection: communityCollection,
+		defaultCode:         defaultCode,
+		staticHosts:         cfg.CommunityHosts,
+		byCode:              make(map[string]*models.Community),
+		byHost:              make(map[string]*models.Community),
+	}
+}
+
+// EnsureDefault создает громаду по умолчанию, если ее еще нет
+func (s *CommunityService) EnsureDefault(ctx context.Context) error {
+	now := time.Now()
+	_, err := s.communityCollection.UpdateOne(
+		ctx,
+		bson.M{"code": s.defaultCode},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"code":  s.defaultCode,
+				"name":  "Нова Каховка",
+				"hosts": []string{},
+				"settings": models.CommunitySettings{
+					DisplayName:    "Нова Каховка e-City",
+					PrimaryColor:   "#0057B7",
+					SecondaryColor: "#FFD700",
+					EnabledModules: models.AllModules(),
+				},
+				"is_active":  true,
+				"is_default": true,
+				"created_at": now,
+				"updated_at": now,
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка создания громады по умолчанию: %w", err)
+	}
+
+	return s.Reload(ctx)
+}
+
+// Reload перечитывает список активных громад из базы
+func (s *CommunityService) Reload(ctx context.Context) error {
+	cursor, err := s.communityCollection.Find(ctx, bson.M{"is_active": true})
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки громад: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var communities []models.Community
+	if err := cursor.All(ctx, &communities); err != nil {
+		return fmt.Errorf("ошибка декодирования громад: %w", err)
+	}
+
+	byCode := make(map[string]*models.Community, len(communities))
+	byHost := make(map[string]*models.Community)
+	for i := range communities {
+		community := &communities[i]
+		byCode[community.Code] = community
+		for _, host := range community.Hosts {
+			byHost[strings.ToLower(host)] = community
+		}
+	}
+
+	// Статическое соответствие из конфигурации дополняет хосты из базы
+	for host, code := range s.staticHosts {
+		if community, ok := byCode[code]; ok {
+			if _, exists := byHost[host]; !exists {
+				byHost[host] = community
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.byCode = byCode
+	s.byHost = byHost
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Resolve определяет громаду по явному коду (заголовок X-Community) или по хосту запроса.
+// Если ничего не найдено, возвращается громада по умолчанию.
+func (s *CommunityService) Resolve(host, code string) (*models.Community, error) {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if code != "" {
+		if community, ok := s.byCode[code]; ok {
+			return community, nil
+		}
+		return nil, fmt.Errorf("громада %q не найдена", code)
+	}
+
+	host = strings.ToLower(host)
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+	if community, ok := s.byHost[host]; ok {
+		return community, nil
+	}
+
+	if community, ok := s.byCode[s.defaultCode]; ok {
+		return community, nil
+	}
+	return nil, fmt.Errorf("громада по умолчанию %q не найдена", s.defaultCode)
+}
+
+// GetByCode возвращает активную громаду по коду
+func (s *CommunityService) GetByCode(code string) (*models.Community, bool) {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	community, ok := s.byCode[code]
+	return community, ok
+}
+
+// DefaultCode возвращает код громады по умолчанию
+func (s *CommunityService) DefaultCode() string {
+	return s.defaultCode
+}
+
+func (s *CommunityService) refreshIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > communityCacheTTL
+	s.mu.RUnlock()
+
+	if !stale {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Reload(ctx); err != nil {
+		// Оставляем старый кэш, попробуем снова при следующем запросе
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+		fmt.Printf("Error reloading communities: %v\n", err)
+	}
+}
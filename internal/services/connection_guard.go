@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Коды отказа в WebSocket-подключении
+const (
+	ConnectionCodeBanned             = "WS_TEMPORARILY_BANNED"
+	ConnectionCodeHandshakeLimit     = "WS_HANDSHAKE_RATE_LIMITED"
+	ConnectionCodeTooManyConnections = "WS_TOO_MANY_CONNECTIONS"
+)
+
+// Окно, в котором частые переподключения считаются циклом
+const connectionChurnWindow = 10 * time.Second
+
+// ConnectionLimitError - подключение отклонено защитой от переподключений
+type ConnectionLimitError struct {
+	Code       string
+	RetryAfter time.Duration
+}
+
+func (e *ConnectionLimitError) Error() string {
+	switch e.Code {
+	case ConnectionCodeBanned:
+		return fmt.Sprintf("address is temporarily banned, retry after %s", e.RetryAfter.Round(time.Second))
+	case ConnectionCodeTooManyConnections:
+		return "too many open connections from this address"
+	default:
+		return fmt.Sprintf("too many connection attempts, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+}
+
+// RetryAfterSeconds - время ожидания, округленное вверх до секунды
+func (e *ConnectionLimitError) RetryAfterSeconds() int {
+	seconds := int(e.RetryAfter / time.Second)
+	if e.RetryAfter%time.Second > 0 {
+		seconds++
+	}
+	return seconds
+}
+
+// ConnectionGuardConfig - пределы для одного IP. Нулевое значение отключает соответствующую проверку.
+type ConnectionGuardConfig struct {
+	MaxConnectionsPerIP int           // Одновременно открытых WebSocket
+	HandshakesPerMinute int           // Попыток подключения в минуту
+	ChurnBanThreshold   int           // Попыток за 10 секунд, после которых адрес банится
+	BanDuration         time.Duration // Длительность временного бана
+}
+
+type connectionState struct {
+	handshakes  []time.Time // Попытки за последнюю минуту
+	open        int
+	bannedUntil time.Time
+}
+
+// ConnectionGuard защищает WebSocket от клиентов, переподключающихся в цикле:
+// ограничивает число открытых соединений и попыток подключения с одного IP
+// и временно банит адреса, которые продолжают переподключаться.
+// Проверка выполняется до аутентификации, чтобы невалидные токены тоже расходовали лимит.
+type ConnectionGuard struct {
+	config ConnectionGuardConfig
+
+	mu    sync.Mutex
+	state map[string]*connectionState
+}
+
+func NewConnectionGuard(config ConnectionGuardConfig) *ConnectionGuard {
+	return &ConnectionGuard{
+		config: config,
+		state:  make(map[string]*connectionState),
+	}
+}
+
+// Admit учитывает попытку подключения (handshake) с адреса ip
+func (g *ConnectionGuard) Admit(ip string) error {
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := g.stateFor(ip)
+	if now.Before(state.bannedUntil) {
+		return &ConnectionLimitError{Code: ConnectionCodeBanned, RetryAfter: state.bannedUntil.Sub(now)}
+	}
+
+	cutoff := now.Add(-time.Minute)
+	recent := state.handshakes[:0]
+	for _, at := range state.handshakes {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	state.handshakes = append(recent, now)
+
+	if g.config.ChurnBanThreshold > 0 && g.config.BanDuration > 0 {
+		churn := 0
+		for _, at := range state.handshakes {
+			if now.Sub(at) <= connectionChurnWindow {
+				churn++
+			}
+		}
+		if churn > g.config.ChurnBanThreshold {
+			state.bannedUntil = now.Add(g.config.BanDuration)
+			state.handshakes = nil
+			log.Printf("WebSocket: %s banned for %s after %d reconnects in %s", ip, g.config.BanDuration, churn, connectionChurnWindow)
+			return &ConnectionLimitError{Code: ConnectionCodeBanned, RetryAfter: g.config.BanDuration}
+		}
+	}
+
+	if g.config.HandshakesPerMinute > 0 && len(state.handshakes) > g.config.HandshakesPerMinute {
+		return &ConnectionLimitError{Code: ConnectionCodeHandshakeLimit, RetryAfter: state.handshakes[0].Add(time.Minute).Sub(now)}
+	}
+	return nil
+}
+
+// Acquire занимает слот открытого соединения; после закрытия соединения нужно вызвать Release
+func (g *ConnectionGuard) Acquire(ip string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := g.stateFor(ip)
+	if g.config.MaxConnectionsPerIP > 0 && state.open >= g.config.MaxConnectionsPerIP {
+		return &ConnectionLimitError{Code: ConnectionCodeTooManyConnections}
+	}
+	state.open++
+	return nil
+}
+
+// Release освобождает слот соединения
+func (g *ConnectionGuard) Release(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if state, ok := g.state[ip]; ok && state.open > 0 {
+		state.open--
+	}
+}
+
+func (g *ConnectionGuard) stateFor(ip string) *connectionState {
+	state, ok := g.state[ip]
+	if !ok {
+		state = &connectionState{}
+		g.state[ip] = state
+	}
+	return state
+}
+
+// StartCleanup периодически удаляет адреса без соединений, попыток и бана
+func (g *ConnectionGuard) StartCleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		g.cleanup()
+	}
+}
+
+func (g *ConnectionGuard) cleanup() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for ip, state := range g.state {
+		idle := len(state.handshakes) == 0 || now.Sub(state.handshakes[len(state.handshakes)-1]) > time.Minute
+		if state.open == 0 && idle && now.After(state.bannedUntil) {
+			delete(g.state, ip)
+		}
+	}
+}
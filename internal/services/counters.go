@@ -0,0 +1,609 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Как часто планировщик проверяет, не пора ли сверять счетчики
+	counterCheckInterval = 10 * time.Minute
+	// Пауза перед повтором плановой сверки после неудачи
+	counterRetryDelay = time.Hour
+	// Сверка, не завершенная за это время (например, сервер перезапустили), считается прерванной
+	counterRunTimeout = 30 * time.Minute
+)
+
+var ErrCounterReconciliationRunning = errors.New("counter reconciliation is already running")
+
+// arrayCounter - счетчик, который должен равняться длине массива в том же документе
+type arrayCounter struct {
+	collection string
+	field      string
+	source     string
+}
+
+// arrayCounters - денормализованные счетчики, которые обработчики меняют через $inc рядом с массивом.
+// Новый счетчик такого вида добавляется сюда.
+var arrayCounters = []arrayCounter{
+	{collection: "petitions", field: "signature_count", source: "signatures"},
+	{collection: "events", field: "attendee_count", source: "attendees"},
+	{collection: "city_issues", field: "upvote_count", source: "upvotes"},
+	{collection: "groups", field: "member_count", source: "members"},
+}
+
+// collectionCounter - счетчик, который должен равняться числу документов другой коллекции
+type collectionCounter struct {
+	collection string
+	field      string
+	source     string
+	key        string // Поле документа-источника со ссылкой на документ счетчика
+}
+
+// collectionCounters - счетчики комментариев и ответов, хранящихся в отдельных коллекциях
+var collectionCounters = []collectionCounter{
+	{collection: "consultations", field: "comments_count", source: "consultation_comments", key: "consultation_id"},
+	{collection: "polls", field: "response_count", source: "poll_responses", key: "poll_id"},
+	{collection: "polls", field: "total_responses", source: "poll_responses", key: "poll_id"},
+}
+
+// CounterMetrics - метрики сверок с запуска сервера (GET /admin/counters/reconciliations)
+type CounterMetrics struct {
+	Runs           int64            `json:"runs"`
+	FailedRuns     int64            `json:"failed_runs"`
+	LastRunAt      *time.Time       `json:"last_run_at,omitempty"`
+	LastMismatched int64            `json:"last_mismatched"`
+	Mismatched     map[string]int64 `json:"mismatched"` // Найдено расхождений по счетчику
+	Fixed          map[string]int64 `json:"fixed"`
+	Drift          map[string]int64 `json:"drift"`
+}
+
+// CounterService сверяет денормализованные счетчики (подписи, участники, голоса, ответы опросов)
+// с исходными массивами и коллекциями и исправляет расхождения: ночью по расписанию и по запросу
+// администратора. Исправление пересчитывает значение в самом обновлении, поэтому одновременные
+// голоса не теряются.
+type CounterService struct {
+	db            *mongo.Database
+	runCollection *mongo.Collection
+	pollSummaries *PollSummaryService
+	pollResponses *PollResponseService
+	pollResults   *PollResultService
+	hour          int
+	metricsMu     sync.Mutex
+	metrics       CounterMetrics
+}
+
+func NewCounterService(db *mongo.Database, pollSummaries *PollSummaryService, hour int, pollResponses *PollResponseService, pollResults *PollResultService) *CounterService {
+	return &CounterService{
+		db:            db,
+		runCollection: db.Collection("counter_reconciliations"),
+		pollSummaries: pollSummaries,
+		pollResponses: pollResponses,
+		pollResults:   pollResults,
+		hour:          hour,
+		metrics: CounterMetrics{
+			Mismatched: map[string]int64{},
+			Fixed:      map[string]int64{},
+			Drift:      map[string]int64{},
+		},
+	}
+}
+
+// Counters - названия сверяемых счетчиков
+func (s *CounterService) Counters() []string {
+	names := make([]string, 0, len(arrayCounters)+len(collectionCounters)+1)
+	for _, counter := range arrayCounters {
+		names = append(names, counter.collection+"."+counter.field)
+	}
+	for _, counter := range collectionCounters {
+		names = append(names, counter.collection+"."+counter.field)
+	}
+	return append(names, "polls.results", "poll_results.total_responses")
+}
+
+// Trigger запускает сверку вручную (администратор); сверка идет в фоне
+func (s *CounterService) Trigger(ctx context.Context, adminID primitive.ObjectID) (*models.CounterReconciliation, error) {
+	run, err := s.begin(ctx, models.CounterReconciliationManual, "", &adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.Background(), counterRunTimeout)
+		defer cancel()
+		if err := s.run(runCtx, run); err != nil {
+			log.Printf("Counter reconciliation %s failed: %v", run.ID.Hex(), err)
+		}
+	}()
+
+	return run, nil
+}
+
+// RunScheduledIfDue сверяет счетчики, если сегодня сверки еще не было и час наступил
+func (s *CounterService) RunScheduledIfDue(ctx context.Context) error {
+	now := time.Now()
+	if now.Hour() < s.hour {
+		return nil
+	}
+
+	slot := now.Format("2006-01-02")
+	done, err := s.runCollection.CountDocuments(ctx, bson.M{"slot": slot})
+	if err != nil || done > 0 {
+		return err
+	}
+	recentFailures, err := s.runCollection.CountDocuments(ctx, bson.M{
+		"trigger":    models.CounterReconciliationScheduled,
+		"status":     models.CounterReconciliationFailed,
+		"started_at": bson.M{"$gt": now.Add(-counterRetryDelay)},
+	})
+	if err != nil || recentFailures > 0 {
+		return err
+	}
+
+	run, err := s.begin(ctx, models.CounterReconciliationScheduled, slot, nil)
+	if errors.Is(err, ErrCounterReconciliationRunning) {
+		// Сверку уже выполняет другой экземпляр сервера или администратор
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.run(ctx, run)
+}
+
+// StartScheduler запускает ежедневную сверку счетчиков
+func (s *CounterService) StartScheduler() {
+	ticker := time.NewTicker(counterCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), counterRunTimeout)
+		if err := s.RunScheduledIfDue(ctx); err != nil {
+			log.Printf("Error running scheduled counter reconciliation: %v", err)
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+// List - последние сверки, новые первыми
+func (s *CounterService) List(ctx context.Context, limit int64) ([]models.CounterReconciliation, error) {
+	cursor, err := s.runCollection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []models.CounterReconciliation{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// Metrics - копия метрик сверок
+func (s *CounterService) Metrics() CounterMetrics {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	metrics := s.metrics
+	metrics.Mismatched = copyCounts(s.metrics.Mismatched)
+	metrics.Fixed = copyCounts(s.metrics.Fixed)
+	metrics.Drift = copyCounts(s.metrics.Drift)
+	return metrics
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	copied := make(map[string]int64, len(counts))
+	for name, count := range counts {
+		copied[name] = count
+	}
+	return copied
+}
+
+func (s *CounterService) begin(ctx context.Context, trigger, slot string, triggeredBy *primitive.ObjectID) (*models.CounterReconciliation, error) {
+	// Сверки, прерванные перезапуском сервера, больше не блокируют новые
+	_, err := s.runCollection.UpdateMany(ctx, bson.M{
+		"status":     models.CounterReconciliationRunning,
+		"started_at": bson.M{"$lt": time.Now().Add(-counterRunTimeout)},
+	}, bson.M{
+		"$set":   bson.M{"status": models.CounterReconciliationFailed, "error": "interrupted"},
+		"$unset": bson.M{"slot": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.CounterReconciliation{
+		ID:          primitive.NewObjectID(),
+		Trigger:     trigger,
+		Slot:        slot,
+		TriggeredBy: triggeredBy,
+		Status:      models.CounterReconciliationRunning,
+		StartedAt:   time.Now(),
+		Counters:    []models.CounterDrift{},
+	}
+	if _, err := s.runCollection.InsertOne(ctx, run); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrCounterReconciliationRunning
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// run сверяет все счетчики по очереди и сохраняет итог
+func (s *CounterService) run(ctx context.Context, run *models.CounterReconciliation) error {
+	for _, counter := range arrayCounters {
+		drift, err := s.reconcileArray(ctx, counter)
+		if err != nil {
+			return s.fail(run, fmt.Errorf("%s.%s: %w", counter.collection, counter.field, err))
+		}
+		addCounterDrift(run, drift)
+	}
+
+	for _, counter := range collectionCounters {
+		drift, err := s.reconcileCollection(ctx, counter)
+		if err != nil {
+			return s.fail(run, fmt.Errorf("%s.%s: %w", counter.collection, counter.field, err))
+		}
+		addCounterDrift(run, drift)
+	}
+
+	// Итоги опросов сверяются после счетчиков ответов, на которые опирается их запись
+	drift, err := s.reconcilePollResults(ctx)
+	if err != nil {
+		return s.fail(run, fmt.Errorf("polls.results: %w", err))
+	}
+	addCounterDrift(run, drift)
+
+	drift, err = s.reconcilePollTallies(ctx)
+	if err != nil {
+		return s.fail(run, fmt.Errorf("poll_results.total_responses: %w", err))
+	}
+	addCounterDrift(run, drift)
+
+	now := time.Now()
+	run.Status = models.CounterReconciliationCompleted
+	run.CompletedAt = &now
+	if _, err := s.runCollection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run); err != nil {
+		return err
+	}
+	s.record(run)
+
+	if run.Mismatched > 0 {
+		log.Printf("⚠️  Counter reconciliation %s: fixed %d of %d drifted counters", run.ID.Hex(), run.Fixed, run.Mismatched)
+		for _, counter := range run.Counters {
+			if counter.Mismatched > 0 {
+				log.Printf("   %s: %d mismatched, total drift %d", counter.Counter, counter.Mismatched, counter.Drift)
+			}
+		}
+	}
+	return nil
+}
+
+// addCounterDrift учитывает результат одного счетчика в итоге сверки
+func addCounterDrift(run *models.CounterReconciliation, drift models.CounterDrift) {
+	run.Counters = append(run.Counters, drift)
+	run.Checked += drift.Checked
+	run.Mismatched += drift.Mismatched
+	run.Fixed += drift.Fixed
+}
+
+// addCounterSample учитывает документ с расхождением; примеров сохраняется не больше MaxCounterDriftSamples
+func addCounterSample(drift *models.CounterDrift, sample models.CounterSample) {
+	drift.Mismatched++
+	if sample.Stored > sample.Actual {
+		drift.Drift += sample.Stored - sample.Actual
+	} else {
+		drift.Drift += sample.Actual - sample.Stored
+	}
+	if len(drift.Samples) < models.MaxCounterDriftSamples {
+		drift.Samples = append(drift.Samples, sample)
+	}
+}
+
+func (s *CounterService) fail(run *models.CounterReconciliation, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := s.runCollection.UpdateOne(ctx, bson.M{"_id": run.ID}, bson.M{
+		"$set": bson.M{
+			"status":       models.CounterReconciliationFailed,
+			"error":        cause.Error(),
+			"completed_at": time.Now(),
+			"counters":     run.Counters,
+		},
+		// Плановую сверку можно повторить в тот же день
+		"$unset": bson.M{"slot": ""},
+	})
+	if err != nil {
+		log.Printf("Error marking counter reconciliation %s as failed: %v", run.ID.Hex(), err)
+	}
+	s.record(run)
+	return cause
+}
+
+// record добавляет итог сверки в метрики
+func (s *CounterService) record(run *models.CounterReconciliation) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	s.metrics.Runs++
+	if run.Status != models.CounterReconciliationCompleted {
+		s.metrics.FailedRuns++
+	}
+	startedAt := run.StartedAt
+	s.metrics.LastRunAt = &startedAt
+	s.metrics.LastMismatched = run.Mismatched
+	for _, counter := range run.Counters {
+		s.metrics.Mismatched[counter.Counter] += counter.Mismatched
+		s.metrics.Fixed[counter.Counter] += counter.Fixed
+		s.metrics.Drift[counter.Counter] += counter.Drift
+	}
+}
+
+// reconcileArray приравнивает счетчик к длине исходного массива
+func (s *CounterService) reconcileArray(ctx context.Context, counter arrayCounter) (models.CounterDrift, error) {
+	collection := s.db.Collection(counter.collection)
+	stored := bson.M{"$ifNull": bson.A{"$" + counter.field, 0}}
+	actual := bson.M{"$size": bson.M{"$ifNull": bson.A{"$" + counter.source, bson.A{}}}}
+	mismatch := bson.M{"$expr": bson.M{"$ne": bson.A{stored, actual}}}
+
+	drift := models.CounterDrift{
+		Counter: counter.collection + "." + counter.field,
+		Source:  counter.collection + "." + counter.source,
+	}
+
+	checked, err := collection.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return drift, err
+	}
+	drift.Checked = checked
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: mismatch}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "id": "$_id", "stored": stored, "actual": actual}}},
+	})
+	if err != nil {
+		return drift, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var sample models.CounterSample
+		if err := cursor.Decode(&sample); err != nil {
+			return drift, err
+		}
+		addCounterSample(&drift, sample)
+	}
+	if err := cursor.Err(); err != nil {
+		return drift, err
+	}
+	if drift.Mismatched == 0 {
+		return drift, nil
+	}
+
+	// Значение пересчитывается в самом обновлении: голос между поиском и исправлением не теряется
+	result, err := collection.UpdateMany(ctx, mismatch, bson.A{
+		bson.M{"$set": bson.M{counter.field: actual}},
+	})
+	if err != nil {
+		return drift, err
+	}
+	drift.Fixed = result.ModifiedCount
+	return drift, nil
+}
+
+// reconcileCollection приравнивает счетчик к числу документов источника (комментарии консультации, ответы опроса)
+func (s *CounterService) reconcileCollection(ctx context.Context, counter collectionCounter) (models.CounterDrift, error) {
+	collection := s.db.Collection(counter.collection)
+	source := s.db.Collection(counter.source)
+	drift := models.CounterDrift{
+		Counter: counter.collection + "." + counter.field,
+		Source:  counter.source,
+	}
+
+	cursor, err := source.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$" + counter.key, "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return drift, err
+	}
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return drift, err
+	}
+	actual := make(map[primitive.ObjectID]int64, len(counts))
+	for _, count := range counts {
+		actual[count.ID] = count.Count
+	}
+
+	cursor, err = collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"count": bson.M{"$ifNull": bson.A{"$" + counter.field, 0}}}}},
+	})
+	if err != nil {
+		return drift, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var document struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Count int64              `bson:"count"`
+		}
+		if err := cursor.Decode(&document); err != nil {
+			return drift, err
+		}
+		drift.Checked++
+		if document.Count == actual[document.ID] {
+			continue
+		}
+		addCounterSample(&drift, models.CounterSample{ID: document.ID, Stored: document.Count, Actual: actual[document.ID]})
+
+		// Пересчет перед записью сужает окно для комментария или ответа, добавленного во время сверки
+		count, err := source.CountDocuments(ctx, bson.M{counter.key: document.ID})
+		if err != nil {
+			return drift, err
+		}
+		result, err := collection.UpdateOne(ctx, bson.M{"_id": document.ID}, bson.M{"$set": bson.M{counter.field: count}})
+		if err != nil {
+			return drift, err
+		}
+		drift.Fixed += result.ModifiedCount
+	}
+	return drift, cursor.Err()
+}
+
+// reconcilePollResults пересчитывает сохраненные итоги опросов (голоса за варианты) по ответам
+func (s *CounterService) reconcilePollResults(ctx context.Context) (models.CounterDrift, error) {
+	polls := s.db.Collection("polls")
+	drift := models.CounterDrift{
+		Counter: "polls.results",
+		Source:  "poll_responses",
+	}
+
+	cursor, err := polls.Find(ctx,
+		bson.M{"status": bson.M{"$ne": models.PollStatusDraft}},
+		options.Find().SetProjection(bson.M{"questions": 1, "results": 1}),
+	)
+	if err != nil {
+		return drift, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var poll models.Poll
+		if err := cursor.Decode(&poll); err != nil {
+			return drift, err
+		}
+		drift.Checked++
+
+		responses, err := s.pollResponses.ForPoll(ctx, poll.ID)
+		if err != nil {
+			return drift, err
+		}
+		results := poll.CalculateResults(responses)
+		stored, actual := resultVotes(poll.Results), resultVotes(results)
+		if stored == actual && resultsMatch(poll.Results, results) {
+			continue
+		}
+		addCounterSample(&drift, models.CounterSample{ID: poll.ID, Stored: stored, Actual: actual})
+
+		// Итоги записываются, только если за время пересчета не появилось новых ответов
+		result, err := polls.UpdateOne(ctx,
+			bson.M{"_id": poll.ID, "total_responses": len(responses)},
+			bson.M{"$set": bson.M{"results": results}},
+		)
+		if err != nil {
+			return drift, err
+		}
+		if result.ModifiedCount > 0 {
+			drift.Fixed++
+			if err := s.pollSummaries.Sync(ctx, poll.ID); err != nil {
+				log.Printf("Error syncing poll summary %s: %v", poll.ID.Hex(), err)
+			}
+		}
+	}
+	return drift, cursor.Err()
+}
+
+// reconcilePollTallies пересобирает агрегацией кэш итогов (poll_results), число ответов
+// в котором разошлось с poll_responses; кэш удаленного опроса удаляется
+func (s *CounterService) reconcilePollTallies(ctx context.Context) (models.CounterDrift, error) {
+	polls := s.db.Collection("polls")
+	responses := s.db.Collection("poll_responses")
+	drift := models.CounterDrift{
+		Counter: "poll_results.total_responses",
+		Source:  "poll_responses",
+	}
+
+	cursor, err := s.db.Collection("poll_results").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"total_responses": 1}))
+	if err != nil {
+		return drift, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var tally struct {
+			ID             primitive.ObjectID `bson:"_id"`
+			TotalResponses int64              `bson:"total_responses"`
+		}
+		if err := cursor.Decode(&tally); err != nil {
+			return drift, err
+		}
+		drift.Checked++
+
+		actual, err := responses.CountDocuments(ctx, bson.M{"poll_id": tally.ID})
+		if err != nil {
+			return drift, err
+		}
+		if tally.TotalResponses == actual {
+			continue
+		}
+		addCounterSample(&drift, models.CounterSample{ID: tally.ID, Stored: tally.TotalResponses, Actual: actual})
+
+		var poll models.Poll
+		err = polls.FindOne(ctx, bson.M{"_id": tally.ID}, options.FindOne().SetProjection(bson.M{"questions": 1})).Decode(&poll)
+		if err == mongo.ErrNoDocuments {
+			if err := s.pollResults.RemoveForPolls(ctx, []primitive.ObjectID{tally.ID}); err != nil {
+				return drift, err
+			}
+			drift.Fixed++
+			continue
+		}
+		if err != nil {
+			return drift, err
+		}
+		if _, err := s.pollResults.Rebuild(ctx, &poll); err != nil {
+			return drift, err
+		}
+		drift.Fixed++
+	}
+	return drift, cursor.Err()
+}
+
+// resultVotes - сумма голосов за варианты во всех вопросах
+func resultVotes(results models.PollResults) int64 {
+	var votes int64
+	for _, question := range results.QuestionResults {
+		for _, option := range question.OptionResults {
+			votes += int64(option.Count)
+		}
+	}
+	return votes
+}
+
+// resultsMatch сравнивает счетчики итогов: ответы на вопросы и голоса за каждый вариант
+func resultsMatch(stored, actual models.PollResults) bool {
+	if len(stored.QuestionResults) != len(actual.QuestionResults) {
+		return false
+	}
+	for i, question := range actual.QuestionResults {
+		storedQuestion := stored.QuestionResults[i]
+		if storedQuestion.QuestionID != question.QuestionID ||
+			storedQuestion.TotalAnswers != question.TotalAnswers ||
+			len(storedQuestion.OptionResults) != len(question.OptionResults) {
+			return false
+		}
+		for j, option := range question.OptionResults {
+			if storedQuestion.OptionResults[j].OptionID != option.OptionID || storedQuestion.OptionResults[j].Count != option.Count {
+				return false
+			}
+		}
+	}
+	return true
+}
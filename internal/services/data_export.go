@@ -0,0 +1,441 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Как часто воркер ищет необработанные запросы и просроченные архивы (новые запросы будят его сразу)
+	dataExportCheckInterval = time.Minute
+	// Запрос в processing дольше этого считается прерванным (перезапуск сервера) и берется повторно
+	dataExportStaleAfter = 15 * time.Minute
+	// После стольких неудачных попыток запрос помечается failed, пользователь может запросить архив заново
+	dataExportMaxAttempts = 3
+)
+
+// ErrDataExportNotReady - архив еще готовится или больше не хранится
+var ErrDataExportNotReady = errors.New("data export is not ready")
+
+// dataExportSection - один JSON-файл архива
+type dataExportSection struct {
+	file  string
+	build func(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error)
+}
+
+// DataExportService готовит архив данных пользователя (право на переносимость данных):
+// профиль, объявления, события, петиции, ответы опросов, обращения и сообщения.
+// Данные других пользователей (участники, подписи, реакции) в архив не попадают.
+// Архив хранится в файловом хранилище ограниченное время и выдается по подписанной ссылке.
+type DataExportService struct {
+	exportCollection        *mongo.Collection
+	userCollection          *mongo.Collection
+	announcementCollection  *mongo.Collection
+	eventCollection         *mongo.Collection
+	petitionCollection      *mongo.Collection
+	pollCollection          *mongo.Collection
+	pollResponseCollection  *mongo.Collection
+	cityIssueCollection     *mongo.Collection
+	messageCollection       *mongo.Collection
+	directMessageCollection *mongo.Collection
+	storage                 FileStorage
+	notificationService     *NotificationService
+	retention               time.Duration
+	urlTTL                  time.Duration
+
+	wake chan struct{}
+}
+
+func NewDataExportService(db *mongo.Database, storage FileStorage, notificationService *NotificationService, retention, urlTTL time.Duration) *DataExportService {
+	return &DataExportService{
+		exportCollection:        db.Collection("data_exports"),
+		userCollection:          db.Collection("users"),
+		announcementCollection:  db.Collection("announcements"),
+		eventCollection:         db.Collection("events"),
+		petitionCollection:      db.Collection("petitions"),
+		pollCollection:          db.Collection("polls"),
+		pollResponseCollection:  db.Collection("poll_responses"),
+		cityIssueCollection:     db.Collection("city_issues"),
+		messageCollection:       db.Collection("messages"),
+		directMessageCollection: db.Collection("direct_messages"),
+		storage:                 storage,
+		notificationService:     notificationService,
+		retention:               retention,
+		urlTTL:                  urlTTL,
+		wake:                    make(chan struct{}, 1),
+	}
+}
+
+// Request возвращает текущую выгрузку пользователя или ставит новую в очередь.
+// Готовый архив переиспользуется до окончания срока хранения; true - создан новый запрос.
+func (s *DataExportService) Request(ctx context.Context, userID primitive.ObjectID) (*models.DataExport, bool, error) {
+	var current models.DataExport
+	err := s.exportCollection.FindOne(ctx,
+		bson.M{"user_id": userID, "status": bson.M{"$in": []string{
+			models.DataExportPending, models.DataExportProcessing, models.DataExportCompleted,
+		}}},
+		options.FindOne().SetSort(bson.D{{Key: "requested_at", Value: -1}}),
+	).Decode(&current)
+	if err == nil && (current.ExpiresAt == nil || time.Now().Before(*current.ExpiresAt)) {
+		return &current, false, nil
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+
+	export := &models.DataExport{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Status:      models.DataExportPending,
+		RequestedAt: time.Now(),
+	}
+	if _, err := s.exportCollection.InsertOne(ctx, export); err != nil {
+		return nil, false, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return export, true, nil
+}
+
+// DownloadURL - подписанная ссылка на готовый архив
+func (s *DataExportService) DownloadURL(export *models.DataExport) (string, time.Duration, error) {
+	if export.Status != models.DataExportCompleted || export.FileKey == "" {
+		return "", 0, ErrDataExportNotReady
+	}
+	ttl := s.urlTTL
+	if export.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*export.ExpiresAt))
+	}
+	if ttl <= 0 {
+		return "", 0, ErrDataExportNotReady
+	}
+	url, err := s.storage.SignedURL(export.FileKey, ttl)
+	return url, ttl, err
+}
+
+// StartWorker обрабатывает очередь выгрузок и удаляет просроченные архивы
+func (s *DataExportService) StartWorker() {
+	ticker := time.NewTicker(dataExportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.processNext() {
+		}
+		s.removeExpired()
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// processNext берет один запрос из очереди; false - очередь пуста
+func (s *DataExportService) processNext() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	var export models.DataExport
+	err := s.exportCollection.FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{"status": models.DataExportPending},
+			{"status": models.DataExportProcessing, "started_at": bson.M{"$lt": now.Add(-dataExportStaleAfter)}},
+		}},
+		bson.M{
+			"$set": bson.M{"status": models.DataExportProcessing, "started_at": now},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "requested_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&export)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Data export: error claiming request: %v", err)
+		}
+		return false
+	}
+
+	archive, counts, err := s.buildArchive(ctx, export.UserID)
+	fileKey := fmt.Sprintf("exports/%s/%s.zip", export.UserID.Hex(), export.ID.Hex())
+	if err == nil {
+		err = s.storage.Put(ctx, fileKey, "application/zip", archive)
+	}
+	if err != nil {
+		status := models.DataExportPending
+		if export.Attempts >= dataExportMaxAttempts {
+			status = models.DataExportFailed
+		}
+		log.Printf("Data export %s failed (attempt %d): %v", export.ID.Hex(), export.Attempts, err)
+		s.exportCollection.UpdateOne(ctx, bson.M{"_id": export.ID}, bson.M{
+			"$set": bson.M{"status": status, "last_error": err.Error()},
+		})
+		return true
+	}
+
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(s.retention)
+	_, err = s.exportCollection.UpdateOne(ctx, bson.M{"_id": export.ID}, bson.M{
+		"$set": bson.M{
+			"status":       models.DataExportCompleted,
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+			"file_key":     fileKey,
+			"size_bytes":   int64(len(archive)),
+			"counts":       counts,
+		},
+		"$unset": bson.M{"last_error": ""},
+	})
+	if err != nil {
+		log.Printf("Data export %s: error saving result: %v", export.ID.Hex(), err)
+		return true
+	}
+
+	// Ссылка в уведомлении ведет на GET /auth/export, который выдает свежую подписанную ссылку
+	err = s.notificationService.SendNotificationToUser(ctx, export.UserID,
+		"Архів ваших даних готовий",
+		fmt.Sprintf("Завантажити архів можна до %s", expiresAt.Format("02.01.2006")),
+		NotificationTypeSystem,
+		map[string]interface{}{
+			"action":     "data_export",
+			"export_id":  export.ID.Hex(),
+			"export_url": "/api/v1/auth/export",
+		},
+		&export.ID,
+	)
+	if err != nil {
+		log.Printf("Data export %s: error sending notification: %v", export.ID.Hex(), err)
+	}
+	return true
+}
+
+// removeExpired удаляет архивы после окончания срока хранения
+func (s *DataExportService) removeExpired() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cursor, err := s.exportCollection.Find(ctx, bson.M{
+		"status":     models.DataExportCompleted,
+		"expires_at": bson.M{"$lt": time.Now()},
+	}, options.Find().SetLimit(100))
+	if err != nil {
+		log.Printf("Data export: error finding expired archives: %v", err)
+		return
+	}
+	var expired []models.DataExport
+	if err := cursor.All(ctx, &expired); err != nil {
+		log.Printf("Data export: error reading expired archives: %v", err)
+		return
+	}
+
+	for _, export := range expired {
+		if err := s.storage.Delete(ctx, export.FileKey); err != nil {
+			log.Printf("Data export %s: error deleting archive: %v", export.ID.Hex(), err)
+			continue
+		}
+		s.exportCollection.UpdateOne(ctx, bson.M{"_id": export.ID}, bson.M{
+			"$set":   bson.M{"status": models.DataExportExpired},
+			"$unset": bson.M{"file_key": ""},
+		})
+	}
+}
+
+// buildArchive собирает ZIP: по одному JSON-файлу на вид данных
+func (s *DataExportService) buildArchive(ctx context.Context, userID primitive.ObjectID) ([]byte, map[string]int64, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	counts := make(map[string]int64)
+
+	for _, section := range s.sections() {
+		data, count, err := section.build(ctx, userID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", section.file, err)
+		}
+		file, err := archive.Create(section.file)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", section.file, err)
+		}
+		counts[section.file] = count
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), counts, nil
+}
+
+func (s *DataExportService) sections() []dataExportSection {
+	return []dataExportSection{
+		{"profile.json", s.exportProfile},
+		{"announcements.json", s.collectionSection(s.announcementCollection, "author_id", nil)},
+		// Списки участников, подписи и голоса других пользователей исключаются
+		{"events.json", s.exportEvents},
+		{"petitions.json", s.collectionSection(s.petitionCollection, "author_id", bson.M{"signatures": 0, "co_authors": 0})},
+		{"petition_signatures.json", s.exportPetitionSignatures},
+		{"poll_responses.json", s.exportPollResponses},
+		{"city_issues.json", s.collectionSection(s.cityIssueCollection, "reporter_id", bson.M{"upvotes": 0, "subscribers": 0, "comments": 0})},
+		{"messages.json", s.collectionSection(s.messageCollection, "user_id", bson.M{"reactions": 0, "read_by": 0})},
+		// Лишь отправленные пользователем: входящие - личные данные собеседника
+		{"direct_messages.json", s.collectionSection(s.directMessageCollection, "sender_id", nil)},
+	}
+}
+
+func (s *DataExportService) exportProfile(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+	var user models.User
+	if err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return nil, 0, err
+	}
+	return user, 1, nil
+}
+
+// collectionSection - документы коллекции, где field равно пользователю
+func (s *DataExportService) collectionSection(collection *mongo.Collection, field string, projection bson.M) func(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+	return func(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+		return findAll(ctx, collection, bson.M{field: userID}, projection)
+	}
+}
+
+// exportEvents - организованные пользователем события и события, в которых он участвует
+func (s *DataExportService) exportEvents(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+	return findAll(ctx, s.eventCollection,
+		bson.M{"$or": []bson.M{{"organizer_id": userID}, {"participants": userID}}},
+		bson.M{"participants": 0},
+	)
+}
+
+// exportPetitionSignatures - подписи пользователя под петициями (без подписей других пользователей)
+func (s *DataExportService) exportPetitionSignatures(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+	cursor, err := s.petitionCollection.Find(ctx, bson.M{"signatures.user_id": userID},
+		options.Find().SetProjection(bson.M{
+			"title":      1,
+			"signatures": bson.M{"$elemMatch": bson.M{"user_id": userID}},
+		}),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	var petitions []models.Petition
+	if err := cursor.All(ctx, &petitions); err != nil {
+		return nil, 0, err
+	}
+
+	signatures := make([]bson.M, 0, len(petitions))
+	for _, petition := range petitions {
+		for _, signature := range petition.Signatures {
+			signatures = append(signatures, bson.M{
+				"petition_id":    petition.ID,
+				"petition_title": petition.Title,
+				"full_name":      signature.FullName,
+				"is_verified":    signature.IsVerified,
+				"signed_at":      signature.SignedAt,
+				"comment":        signature.Comment,
+			})
+		}
+	}
+	return signatures, int64(len(signatures)), nil
+}
+
+// exportPollResponses - ответы пользователя в опросах
+func (s *DataExportService) exportPollResponses(ctx context.Context, userID primitive.ObjectID) (interface{}, int64, error) {
+	cursor, err := s.pollResponseCollection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, 0, err
+	}
+	var pollResponses []models.PollResponse
+	if err := cursor.All(ctx, &pollResponses); err != nil {
+		return nil, 0, err
+	}
+
+	pollIDs := make([]primitive.ObjectID, 0, len(pollResponses))
+	for _, response := range pollResponses {
+		pollIDs = append(pollIDs, response.PollID)
+	}
+	cursor, err = s.pollCollection.Find(ctx, bson.M{"_id": bson.M{"$in": pollIDs}},
+		options.Find().SetProjection(bson.M{"title": 1, "questions": 1}))
+	if err != nil {
+		return nil, 0, err
+	}
+	var polls []models.Poll
+	if err := cursor.All(ctx, &polls); err != nil {
+		return nil, 0, err
+	}
+	pollsByID := make(map[primitive.ObjectID]models.Poll, len(polls))
+	for _, poll := range polls {
+		pollsByID[poll.ID] = poll
+	}
+
+	responses := make([]bson.M, 0, len(pollResponses))
+	for _, response := range pollResponses {
+		poll := pollsByID[response.PollID]
+		responses = append(responses, bson.M{
+			"poll_id":      response.PollID,
+			"poll_title":   poll.Title,
+			"questions":    poll.Questions,
+			"answers":      response.Answers,
+			"submitted_at": response.SubmittedAt,
+		})
+	}
+	return responses, int64(len(responses)), nil
+}
+
+func findAll(ctx context.Context, collection *mongo.Collection, filter, projection bson.M) (interface{}, int64, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	documents := []bson.M{}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, 0, err
+	}
+	return documents, int64(len(documents)), nil
+}
+
+// RemoveForUser удаляет архивы и запросы выгрузки пользователя (шаг удаления аккаунта)
+func (s *DataExportService) RemoveForUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	cursor, err := s.exportCollection.Find(ctx, bson.M{"user_id": userID, "file_key": bson.M{"$exists": true}})
+	if err != nil {
+		return 0, err
+	}
+	var exports []models.DataExport
+	if err := cursor.All(ctx, &exports); err != nil {
+		return 0, err
+	}
+	for _, export := range exports {
+		if err := s.storage.Delete(ctx, export.FileKey); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := s.exportCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Как часто воркер ищет заброшенные черновики
+const draftCleanupInterval = time.Hour
+
+// Сколько черновиков одного вида обрабатывается за проход
+const draftCleanupBatchSize = 500
+
+var (
+	ErrDraftNotFound  = errors.New("draft not found")
+	ErrDraftForbidden = errors.New("only the author can extend the draft")
+)
+
+// draftKind - вид черновиков, которые удаляются без изменений
+type draftKind struct {
+	resource         string // models.AuditResource*
+	collection       *mongo.Collection
+	status           string // Статус черновика
+	authorField      string // Поле автора: creator_id, author_id
+	notificationType string
+	idKey            string // Ключ ID в data уведомления: poll_id, petition_id
+	extendPath       string // Шаблон ссылки продления
+	label            string // Вид в тексте уведомления
+}
+
+// draftDocument - поля черновика, нужные для предупреждения и журнала
+type draftDocument struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	CommunityID     primitive.ObjectID `bson:"community_id,omitempty"`
+	Title           string             `bson:"title"`
+	CreatorID       primitive.ObjectID `bson:"creator_id,omitempty"`
+	AuthorID        primitive.ObjectID `bson:"author_id,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at"`
+	DraftExtendedAt *time.Time         `bson:"draft_extended_at,omitempty"`
+	DraftWarnedAt   *time.Time         `bson:"draft_warned_at,omitempty"`
+}
+
+// owner - автор черновика (у опросов creator_id, у петиций author_id)
+func (d *draftDocument) owner() primitive.ObjectID {
+	if !d.AuthorID.IsZero() {
+		return d.AuthorID
+	}
+	return d.CreatorID
+}
+
+// lastActivity - последнее изменение или продление черновика
+func (d *draftDocument) lastActivity() time.Time {
+	if d.DraftExtendedAt != nil && d.DraftExtendedAt.After(d.UpdatedAt) {
+		return *d.DraftExtendedAt
+	}
+	return d.UpdatedAt
+}
+
+// DraftCleanupService удаляет черновики опросов и петиций, которые не менялись ttl.
+// За warning до удаления автор получает уведомление со ссылкой продления;
+// черновик удаляется не раньше чем через warning после уведомления. Удаления пишутся в журнал аудита.
+type DraftCleanupService struct {
+	kinds               []draftKind
+	notificationService *NotificationService
+	auditLog            *AuditLogService
+	pollSummaries       *PollSummaryService
+	ttl                 time.Duration
+	warning             time.Duration
+}
+
+func NewDraftCleanupService(pollCollection, petitionCollection *mongo.Collection, notificationService *NotificationService, auditLog *AuditLogService, pollSummaries *PollSummaryService, ttl, warning time.Duration) *DraftCleanupService {
+	return &DraftCleanupService{
+		kinds: []draftKind{
+			{
+				resource:         models.AuditResourcePoll,
+				collection:       pollCollection,
+				status:           models.PollStatusDraft,
+				authorField:      "creator_id",
+				notificationType: models.NotificationTypePoll,
+				idKey:            "poll_id",
+				extendPath:       "/api/v1/polls/%s/extend-draft",
+				label:            "Чернетка опитування",
+			},
+			{
+				resource:         models.AuditResourcePetition,
+				collection:       petitionCollection,
+				status:           models.PetitionStatusDraft,
+				authorField:      "author_id",
+				notificationType: models.NotificationTypePetition,
+				idKey:            "petition_id",
+				extendPath:       "/api/v1/petitions/%s/extend-draft",
+				label:            "Чернетка петиції",
+			},
+		},
+		notificationService: notificationService,
+		auditLog:            auditLog,
+		pollSummaries:       pollSummaries,
+		ttl:                 ttl,
+		warning:             warning,
+	}
+}
+
+// TTL - через сколько без изменений черновик удаляется
+func (s *DraftCleanupService) TTL() time.Duration {
+	return s.ttl
+}
+
+// StartWorker раз в час предупреждает авторов и удаляет черновики после предупреждения
+func (s *DraftCleanupService) StartWorker() {
+	ticker := time.NewTicker(draftCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce()
+		<-ticker.C
+	}
+}
+
+// RunOnce - один проход по всем видам черновиков
+func (s *DraftCleanupService) RunOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	for _, kind := range s.kinds {
+		now := time.Now()
+		if err := s.warnAuthors(ctx, kind, now); err != nil {
+			log.Printf("Error warning authors of abandoned %s drafts: %v", kind.resource, err)
+		}
+		if err := s.deleteWarned(ctx, kind, now); err != nil {
+			log.Printf("Error deleting abandoned %s drafts: %v", kind.resource, err)
+		}
+	}
+}
+
+// draftActivityExpr - последнее изменение или продление черновика в запросе
+var draftActivityExpr = bson.M{"$max": bson.A{
+	"$updated_at",
+	bson.M{"$ifNull": bson.A{"$draft_extended_at", "$updated_at"}},
+}}
+
+// warnAuthors уведомляет авторов черновиков, которые будут удалены через warning.
+// Предупреждение повторяется, только если черновик после него меняли или продлевали.
+func (s *DraftCleanupService) warnAuthors(ctx context.Context, kind draftKind, now time.Time) error {
+	warnBefore := now.Add(-(s.ttl - s.warning))
+	filter := bson.M{
+		"status":     kind.status,
+		"updated_at": bson.M{"$lt": warnBefore},
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$lt": bson.A{draftActivityExpr, warnBefore}},
+			bson.M{"$or": bson.A{
+				bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$draft_warned_at", nil}}, nil}},
+				bson.M{"$lt": bson.A{"$draft_warned_at", draftActivityExpr}},
+			}},
+		}},
+	}
+
+	for i := 0; i < draftCleanupBatchSize; i++ {
+		var draft draftDocument
+		err := kind.collection.FindOneAndUpdate(ctx, filter,
+			bson.M{"$set": bson.M{"draft_warned_at": now}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&draft)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.notifyAuthor(ctx, kind, &draft, now.Add(s.warning))
+	}
+	return nil
+}
+
+// notifyAuthor - уведомление автору с действием extend_draft
+func (s *DraftCleanupService) notifyAuthor(ctx context.Context, kind draftKind, draft *draftDocument, deleteAfter time.Time) {
+	data := map[string]interface{}{
+		kind.idKey:     draft.ID.Hex(),
+		"action":       "extend_draft",
+		"extend_url":   fmt.Sprintf(kind.extendPath, draft.ID.Hex()),
+		"delete_after": deleteAfter.Format(time.RFC3339),
+	}
+
+	err := s.notificationService.SendNotificationToUser(ctx, draft.owner(),
+		kind.label+" буде видалена",
+		fmt.Sprintf("'%s' не змінювалася %d днів і буде видалена після %s. Продовжіть зберігання, якщо ще працюєте над нею.",
+			draft.Title, int((s.ttl-s.warning).Hours()/24), deleteAfter.Format("02.01.2006")),
+		kind.notificationType,
+		data,
+		&draft.ID,
+	)
+	if err != nil {
+		log.Printf("Error notifying author of abandoned %s draft %s: %v", kind.resource, draft.ID.Hex(), err)
+	}
+}
+
+// deleteWarned удаляет черновики, автор которых был предупрежден не меньше warning назад
+// и с тех пор не менял и не продлевал черновик
+func (s *DraftCleanupService) deleteWarned(ctx context.Context, kind draftKind, now time.Time) error {
+	filter := bson.M{
+		"status":          kind.status,
+		"draft_warned_at": bson.M{"$lte": now.Add(-s.warning)},
+		"$expr":           bson.M{"$gte": bson.A{"$draft_warned_at", draftActivityExpr}},
+	}
+
+	for i := 0; i < draftCleanupBatchSize; i++ {
+		var draft draftDocument
+		err := kind.collection.FindOneAndDelete(ctx, filter).Decode(&draft)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if kind.resource == models.AuditResourcePoll {
+			if err := s.pollSummaries.Remove(ctx, draft.ID); err != nil {
+				log.Printf("Error removing poll summary %s: %v", draft.ID.Hex(), err)
+			}
+		}
+
+		authorID := draft.owner()
+		s.auditLog.Record(ctx, models.AuditLogEntry{
+			CommunityID:  draft.CommunityID,
+			Actor:        models.AuditActorDraftCleanup,
+			Action:       models.AuditActionDraftDeleted,
+			ResourceType: kind.resource,
+			ResourceID:   &draft.ID,
+			Details: map[string]interface{}{
+				"title":            draft.Title,
+				"author_id":        authorID,
+				"created_at":       draft.CreatedAt,
+				"last_activity_at": draft.lastActivity(),
+				"warned_at":        draft.DraftWarnedAt,
+			},
+			CreatedAt: now,
+		})
+	}
+	return nil
+}
+
+// Extend продлевает хранение черновика автором: срок отсчитывается заново от now.
+// Возвращает дату, после которой черновик снова может быть удален.
+func (s *DraftCleanupService) Extend(ctx context.Context, resource string, draftID, userID primitive.ObjectID) (time.Time, error) {
+	var kind *draftKind
+	for i := range s.kinds {
+		if s.kinds[i].resource == resource {
+			kind = &s.kinds[i]
+		}
+	}
+	if kind == nil {
+		return time.Time{}, fmt.Errorf("unknown draft resource %q", resource)
+	}
+
+	now := time.Now()
+	var draft draftDocument
+	err := kind.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": draftID, "status": kind.status, kind.authorField: userID},
+		bson.M{
+			"$set":   bson.M{"draft_extended_at": now},
+			"$unset": bson.M{"draft_warned_at": ""},
+		},
+	).Decode(&draft)
+	if err == mongo.ErrNoDocuments {
+		// Черновик есть, но у другого автора
+		count, countErr := kind.collection.CountDocuments(ctx, bson.M{"_id": draftID, "status": kind.status})
+		if countErr == nil && count > 0 {
+			return time.Time{}, ErrDraftForbidden
+		}
+		return time.Time{}, ErrDraftNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	details := map[string]interface{}{
+		"title": draft.Title,
+	}
+	if draft.DraftWarnedAt != nil {
+		details["warned_at"] = draft.DraftWarnedAt
+	}
+	s.auditLog.Record(ctx, models.AuditLogEntry{
+		CommunityID:  draft.CommunityID,
+		ActorID:      &userID,
+		Action:       models.AuditActionDraftExtended,
+		ResourceType: kind.resource,
+		ResourceID:   &draftID,
+		Details:      details,
+		CreatedAt:    now,
+	})
+
+	return now.Add(s.ttl), nil
+}
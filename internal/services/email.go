@@ -0,0 +1,486 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	emailWorkerInterval   = 15 * time.Second
+	emailBatchSize        = 20
+	emailDefaultAttempts  = 5
+	emailRetryBaseDelay   = time.Minute
+	emailStuckSendingTime = 10 * time.Minute
+)
+
+// ErrEmailSuppressed возвращается, если адрес находится в списке блокировки
+var ErrEmailSuppressed = errors.New("email address is suppressed")
+
+type smtpProvider struct {
+	name     string
+	host     string
+	port     int
+	username string
+	password string
+	breaker  *ProviderBreaker
+}
+
+type emailTemplate struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+// EmailService ставит письма в очередь и отправляет их в фоне
+type EmailService struct {
+	from                  string
+	queueCollection       *mongo.Collection
+	suppressionCollection *mongo.Collection
+	logCollection         *mongo.Collection
+	providers             []smtpProvider
+	templates             map[string]emailTemplate
+	send                  func(p smtpProvider, from, to string, msg []byte) error
+}
+
+func NewEmailService(cfg *config.Config, queueCollection, suppressionCollection, logCollection *mongo.Collection) *EmailService {
+	breakerOpen := time.Duration(cfg.ProviderBreakerOpenSec) * time.Second
+
+	var providers []smtpProvider
+	if cfg.SMTPHost != "" {
+		providers = append(providers, smtpProvider{
+			name:     "primary",
+			host:     cfg.SMTPHost,
+			port:     cfg.SMTPPort,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			breaker:  NewProviderBreaker("smtp_primary", cfg.ProviderBreakerThreshold, breakerOpen),
+		})
+	}
+	if cfg.SMTPBackupHost != "" {
+		providers = append(providers, smtpProvider{
+			name:     "backup",
+			host:     cfg.SMTPBackupHost,
+			port:     cfg.SMTPBackupPort,
+			username: cfg.SMTPBackupUsername,
+			password: cfg.SMTPBackupPassword,
+			breaker:  NewProviderBreaker("smtp_backup", cfg.ProviderBreakerThreshold, breakerOpen),
+		})
+	}
+
+	return &EmailService{
+		from:                  cfg.SMTPFrom,
+		queueCollection:       queueCollection,
+		suppressionCollection: suppressionCollection,
+		logCollection:         logCollection,
+		providers:             providers,
+		templates:             defaultEmailTemplates(),
+		send:                  sendSMTP,
+	}
+}
+
+// Enqueue рендерит шаблон и ставит письмо в очередь. Отправка происходит в фоне.
+func (s *EmailService) Enqueue(ctx context.Context, to, templateName string, data map[string]interface{}) (*models.EmailMessage, error) {
+	to = strings.ToLower(strings.TrimSpace(to))
+	if to == "" {
+		return nil, fmt.Errorf("recipient is required")
+	}
+
+	subject, textBody, htmlBody, err := s.render(templateName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	message := models.EmailMessage{
+		To:            to,
+		Template:      templateName,
+		Data:          data,
+		Subject:       subject,
+		TextBody:      textBody,
+		HTMLBody:      htmlBody,
+		Status:        models.EmailStatusPending,
+		MaxAttempts:   emailDefaultAttempts,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	suppressed, err := s.IsSuppressed(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if suppressed {
+		message.Status = models.EmailStatusSuppressed
+		message.LastError = ErrEmailSuppressed.Error()
+	}
+
+	result, err := s.queueCollection.InsertOne(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue email: %w", err)
+	}
+	message.ID = result.InsertedID.(primitive.ObjectID)
+
+	if suppressed {
+		s.logDelivery(ctx, &message, "", models.EmailStatusSuppressed, ErrEmailSuppressed.Error())
+		return &message, ErrEmailSuppressed
+	}
+
+	return &message, nil
+}
+
+// IsSuppressed проверяет, есть ли адрес в списке блокировки
+func (s *EmailService) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	count, err := s.suppressionCollection.CountDocuments(ctx, bson.M{"email": strings.ToLower(email)})
+	if err != nil {
+		return false, fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Suppress добавляет адрес в список блокировки
+func (s *EmailService) Suppress(ctx context.Context, email, reason, details string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	_, err := s.suppressionCollection.UpdateOne(
+		ctx,
+		bson.M{"email": email},
+		bson.M{"$setOnInsert": models.EmailSuppression{
+			Email:     email,
+			Reason:    reason,
+			Details:   details,
+			CreatedAt: time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to suppress email: %w", err)
+	}
+	return nil
+}
+
+// Unsuppress удаляет адрес из списка блокировки
+func (s *EmailService) Unsuppress(ctx context.Context, email string) (bool, error) {
+	result, err := s.suppressionCollection.DeleteOne(ctx, bson.M{"email": strings.ToLower(strings.TrimSpace(email))})
+	if err != nil {
+		return false, fmt.Errorf("failed to unsuppress email: %w", err)
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// StartWorker запускает фоновую обработку очереди писем
+func (s *EmailService) StartWorker() {
+	if len(s.providers) == 0 {
+		log.Println("⚠️  SMTP is not configured, emails will stay in the queue")
+	}
+
+	ticker := time.NewTicker(emailWorkerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if len(s.providers) == 0 {
+			continue
+		}
+		s.processQueue()
+	}
+}
+
+func (s *EmailService) processQueue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Возвращаем в очередь письма, "зависшие" при падении сервера во время отправки
+	s.queueCollection.UpdateMany(ctx, bson.M{
+		"status":     models.EmailStatusSending,
+		"updated_at": bson.M{"$lt": time.Now().Add(-emailStuckSendingTime)},
+	}, bson.M{"$set": bson.M{"status": models.EmailStatusPending}})
+
+	for i := 0; i < emailBatchSize; i++ {
+		// Все провайдеры отключены автоматом: письма ждут в очереди, попытки не расходуются
+		if !s.providersAvailable() {
+			return
+		}
+
+		var message models.EmailMessage
+		err := s.queueCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{
+				"status":          models.EmailStatusPending,
+				"next_attempt_at": bson.M{"$lte": time.Now()},
+			},
+			bson.M{"$set": bson.M{
+				"status":     models.EmailStatusSending,
+				"updated_at": time.Now(),
+			}},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&message)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Printf("Error claiming email from queue: %v", err)
+			}
+			return
+		}
+
+		s.deliver(ctx, &message)
+	}
+}
+
+func (s *EmailService) deliver(ctx context.Context, message *models.EmailMessage) {
+	// Адрес могли заблокировать уже после постановки в очередь
+	if suppressed, _ := s.IsSuppressed(ctx, message.To); suppressed {
+		s.finish(ctx, message, models.EmailStatusSuppressed, "", ErrEmailSuppressed.Error())
+		s.logDelivery(ctx, message, "", models.EmailStatusSuppressed, ErrEmailSuppressed.Error())
+		return
+	}
+
+	raw := s.buildMIME(message)
+	message.Attempts++
+
+	var lastErr error
+	attempted := false
+	for _, provider := range s.providers {
+		if !provider.breaker.Allow() {
+			continue
+		}
+		attempted = true
+
+		err := s.send(provider, s.from, message.To, raw)
+		if err == nil {
+			if provider.breaker.Success() {
+				log.Printf("✅ SMTP provider %s recovered", provider.name)
+			}
+			s.finish(ctx, message, models.EmailStatusSent, provider.name, "")
+			s.logDelivery(ctx, message, provider.name, models.EmailStatusSent, "")
+			return
+		}
+
+		lastErr = err
+		s.logDelivery(ctx, message, provider.name, models.EmailStatusFailed, err.Error())
+
+		// Постоянная ошибка получателя (bounce) - переключение провайдера не поможет
+		if isPermanentRecipientError(err) {
+			// Провайдер ответил - он доступен
+			provider.breaker.Success()
+			s.Suppress(ctx, message.To, models.EmailSuppressionBounce, err.Error())
+			s.finish(ctx, message, models.EmailStatusSuppressed, provider.name, err.Error())
+			return
+		}
+
+		if provider.breaker.Failure(err) {
+			log.Printf("⚠️  SMTP provider %s is unavailable, switching to failover: %v", provider.name, err)
+		}
+	}
+
+	// Провайдеры отключились во время обработки пакета: письмо возвращается в очередь без задержки
+	if !attempted {
+		message.Attempts--
+		s.queueCollection.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$set": bson.M{
+			"status":     models.EmailStatusPending,
+			"updated_at": time.Now(),
+		}})
+		return
+	}
+
+	if message.Attempts >= message.MaxAttempts {
+		s.finish(ctx, message, models.EmailStatusFailed, "", lastErr.Error())
+		return
+	}
+
+	// Экспоненциальная задержка: 1м, 2м, 4м, 8м...
+	delay := emailRetryBaseDelay * time.Duration(1<<uint(message.Attempts-1))
+	s.queueCollection.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$set": bson.M{
+		"status":          models.EmailStatusPending,
+		"attempts":        message.Attempts,
+		"last_error":      lastErr.Error(),
+		"next_attempt_at": time.Now().Add(delay),
+		"updated_at":      time.Now(),
+	}})
+}
+
+// providersAvailable - хотя бы один SMTP провайдер принимает письма
+func (s *EmailService) providersAvailable() bool {
+	for _, provider := range s.providers {
+		if provider.breaker.Available() {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderHealth - состояние SMTP провайдеров для /health/ready и админ-панели
+func (s *EmailService) ProviderHealth() []ProviderHealth {
+	if len(s.providers) == 0 {
+		return []ProviderHealth{{Name: "smtp", State: ProviderDisabled}}
+	}
+	health := make([]ProviderHealth, 0, len(s.providers))
+	for _, provider := range s.providers {
+		health = append(health, provider.breaker.Health())
+	}
+	return health
+}
+
+func (s *EmailService) finish(ctx context.Context, message *models.EmailMessage, status, provider, lastError string) {
+	now := time.Now()
+	update := bson.M{
+		"status":     status,
+		"attempts":   message.Attempts,
+		"last_error": lastError,
+		"updated_at": now,
+	}
+	if provider != "" {
+		update["provider"] = provider
+	}
+	if status == models.EmailStatusSent {
+		update["sent_at"] = now
+	}
+	s.queueCollection.UpdateOne(ctx, bson.M{"_id": message.ID}, bson.M{"$set": update})
+}
+
+func (s *EmailService) logDelivery(ctx context.Context, message *models.EmailMessage, provider, status, errText string) {
+	s.logCollection.InsertOne(ctx, models.EmailDeliveryLog{
+		MessageID: message.ID,
+		To:        message.To,
+		Template:  message.Template,
+		Provider:  provider,
+		Status:    status,
+		Error:     errText,
+		Attempt:   message.Attempts,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *EmailService) buildMIME(message *models.EmailMessage) []byte {
+	boundary := "ecity-" + message.ID.Hex()
+
+	// Тема рендерится из данных пользователя (first_name): перевод строки в ней добавил бы свои заголовки.
+	// Кириллица в заголовке кодируется по RFC 2047
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue(s.from))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(message.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", headerValue(message.Subject)))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	writeMIMEPart(&buf, boundary, "text/plain", message.TextBody)
+	if message.HTMLBody != "" {
+		writeMIMEPart(&buf, boundary, "text/html", message.HTMLBody)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes()
+}
+
+// writeMIMEPart пишет часть письма в quoted-printable: 8-битный UTF-8 строгие релеи искажают или отклоняют
+func writeMIMEPart(buf *bytes.Buffer, boundary, contentType, body string) {
+	fmt.Fprintf(buf, "--%s\r\nContent-Type: %s; charset=UTF-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", boundary, contentType)
+	qp := quotedprintable.NewWriter(buf)
+	qp.Write([]byte(body))
+	qp.Close()
+	buf.WriteString("\r\n")
+}
+
+// headerValue убирает переводы строки из значения заголовка
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+func (s *EmailService) render(name string, data map[string]interface{}) (subject, text, html string, err error) {
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template: %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.subject.Execute(&buf, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render subject: %w", err)
+	}
+	subject = buf.String()
+
+	buf.Reset()
+	if err := tmpl.text.Execute(&buf, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render text body: %w", err)
+	}
+	text = buf.String()
+
+	if tmpl.html != nil {
+		buf.Reset()
+		if err := tmpl.html.Execute(&buf, data); err != nil {
+			return "", "", "", fmt.Errorf("failed to render html body: %w", err)
+		}
+		html = buf.String()
+	}
+
+	return subject, text, html, nil
+}
+
+func sendSMTP(p smtpProvider, from, to string, msg []byte) error {
+	addr := fmt.Sprintf("%s:%d", p.host, p.port)
+	var auth smtp.Auth
+	if p.username != "" {
+		auth = smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+	return smtp.SendMail(addr, auth, from, []string{to}, msg)
+}
+
+// isPermanentRecipientError определяет 5xx ошибки, связанные с получателем
+func isPermanentRecipientError(err error) bool {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return false
+	}
+	switch protoErr.Code {
+	case 550, 551, 553:
+		return true
+	}
+	return false
+}
+
+func defaultEmailTemplates() map[string]emailTemplate {
+	build := func(name, subject, text, html string) emailTemplate {
+		return emailTemplate{
+			subject: template.Must(template.New(name + "_subject").Parse(subject)),
+			text:    template.Must(template.New(name + "_text").Parse(text)),
+			html:    htmltemplate.Must(htmltemplate.New(name + "_html").Parse(html)),
+		}
+	}
+
+	return map[string]emailTemplate{
+		models.EmailTemplateWelcome: build(
+			models.EmailTemplateWelcome,
+			"Ласкаво просимо до e-City, {{.first_name}}!",
+			"Вітаємо, {{.first_name}}!\n\nВаш акаунт на платформі e-City створено.\n",
+			"<p>Вітаємо, <b>{{.first_name}}</b>!</p><p>Ваш акаунт на платформі e-City створено.</p>",
+		),
+		models.EmailTemplatePasswordChanged: build(
+			models.EmailTemplatePasswordChanged,
+			"Пароль змінено",
+			"Пароль до вашого акаунту e-City було змінено. Якщо це були не ви - зверніться до підтримки.\n",
+			"<p>Пароль до вашого акаунту e-City було змінено.</p><p>Якщо це були не ви - зверніться до підтримки.</p>",
+		),
+		models.EmailTemplateGeneric: build(
+			models.EmailTemplateGeneric,
+			"{{.subject}}",
+			"{{.body}}\n",
+			"<p>{{.body}}</p>",
+		),
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testWelcomeMessage рендерить вітальний лист так само, як Enqueue
+func testWelcomeMessage(t *testing.T, s *EmailService, firstName string) *models.EmailMessage {
+	t.Helper()
+	subject, text, html, err := s.render(models.EmailTemplateWelcome, map[string]interface{}{"first_name": firstName})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return &models.EmailMessage{
+		ID:       primitive.NewObjectID(),
+		To:       "resident@example.com",
+		Subject:  subject,
+		TextBody: text,
+		HTMLBody: html,
+	}
+}
+
+func TestBuildMIMEEncodesUTF8(t *testing.T) {
+	s := &EmailService{from: "e-City <noreply@ecity.gov.ua>", templates: defaultEmailTemplates()}
+	message := testWelcomeMessage(t, s, "Олена")
+
+	raw := s.buildMIME(message)
+	for _, b := range raw {
+		if b >= 0x80 {
+			t.Fatal("message must be 7-bit: headers RFC 2047, parts quoted-printable")
+		}
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("subject = %q (%v), want %q", subject, err, message.Subject)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("content type: %v", err)
+	}
+	// multipart.Reader сам декодує quoted-printable частини
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	want := map[string]string{"text/plain": message.TextBody, "text/html": message.HTMLBody}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(part)
+		// Текстові частини передаються з переводами рядка CRLF
+		got := strings.TrimRight(string(body), "\r\n")
+		if expected := strings.TrimRight(strings.ReplaceAll(want[contentType], "\n", "\r\n"), "\r\n"); got != expected {
+			t.Errorf("%s body = %q, want %q", contentType, got, expected)
+		}
+		delete(want, contentType)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing parts: %v", want)
+	}
+}
+
+// Ім'я з переводом рядка не додає заголовків у лист
+func TestBuildMIMEStripsHeaderInjection(t *testing.T) {
+	s := &EmailService{from: "noreply@ecity.gov.ua", templates: defaultEmailTemplates()}
+	message := testWelcomeMessage(t, s, "Олена\r\nBcc: victim@example.com")
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(s.buildMIME(message)))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	if bcc := parsed.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("injected Bcc header: %q", bcc)
+	}
+	for _, key := range []string{"From", "To", "Subject", "Mime-Version", "Content-Type"} {
+		if len(parsed.Header[key]) != 1 {
+			t.Errorf("header %s appears %d times", key, len(parsed.Header[key]))
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Типы встраиваемых виджетов
+const (
+	EmbedResourcePetition = "petition"
+	EmbedResourcePoll     = "poll"
+)
+
+// Максимальный срок токена: сайт, встроивший виджет, должен периодически получать новый
+const MaxEmbedTokenTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidEmbedToken = errors.New("invalid embed token")
+	ErrEmbedTokenExpired = errors.New("embed token has expired")
+	ErrEmbedOrigin       = errors.New("origin is not allowed for embedding")
+)
+
+// EmbedClaims - содержимое токена виджета
+type EmbedClaims struct {
+	ResourceType string             `json:"typ"`
+	ResourceID   primitive.ObjectID `json:"rid"`
+	Origins      []string           `json:"org"` // Сайты, на которых разрешено встраивание
+	ExpiresAt    int64              `json:"exp"`
+}
+
+// AllowsOrigin - виджет можно показывать на сайте origin ("https://example.gov.ua")
+func (c *EmbedClaims) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(strings.TrimRight(origin, "/"))
+	for _, allowed := range c.Origins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// EmbedService выдает и проверяет подписанные токены виджетов петиций и опросов для iframe.
+// Токен не хранится в базе: в нем указаны ресурс, разрешенные сайты и срок действия.
+type EmbedService struct {
+	secret         []byte
+	allowedOrigins map[string]bool // Пусто - администратор задает сайты без ограничений
+	defaultTTL     time.Duration
+}
+
+func NewEmbedService(secret string, allowedOrigins []string, defaultTTL time.Duration) *EmbedService {
+	s := &EmbedService{secret: []byte(secret), allowedOrigins: make(map[string]bool), defaultTTL: defaultTTL}
+	for _, origin := range allowedOrigins {
+		if normalized, err := NormalizeOrigin(origin); err == nil {
+			s.allowedOrigins[normalized] = true
+		}
+	}
+	return s
+}
+
+// NormalizeOrigin приводит адрес сайта к виду origin: схема и хост без пути
+func NormalizeOrigin(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+		return "", fmt.Errorf("invalid origin: %s", raw)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && strings.HasPrefix(parsed.Host, "localhost")) {
+		return "", fmt.Errorf("origin must use https: %s", raw)
+	}
+	return parsed.Scheme + "://" + strings.ToLower(parsed.Host), nil
+}
+
+// Issue создает токен виджета. ttl = 0 - срок по умолчанию.
+func (s *EmbedService) Issue(resourceType string, resourceID primitive.ObjectID, origins []string, ttl time.Duration) (string, *EmbedClaims, error) {
+	if resourceType != EmbedResourcePetition && resourceType != EmbedResourcePoll {
+		return "", nil, fmt.Errorf("unsupported embed resource: %s", resourceType)
+	}
+	if ttl <= 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl > MaxEmbedTokenTTL {
+		ttl = MaxEmbedTokenTTL
+	}
+
+	claims := &EmbedClaims{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		ExpiresAt:    time.Now().Add(ttl).Unix(),
+	}
+	seen := make(map[string]bool, len(origins))
+	for _, raw := range origins {
+		origin, err := NormalizeOrigin(raw)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(s.allowedOrigins) > 0 && !s.allowedOrigins[origin] {
+			return "", nil, fmt.Errorf("%w: %s", ErrEmbedOrigin, origin)
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			claims.Origins = append(claims.Origins, origin)
+		}
+	}
+	if len(claims.Origins) == 0 {
+		return "", nil, fmt.Errorf("at least one origin is required")
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), claims, nil
+}
+
+// Verify проверяет подпись и срок токена
+func (s *EmbedService) Verify(token string) (*EmbedClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, ErrInvalidEmbedToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidEmbedToken
+	}
+	var claims EmbedClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidEmbedToken
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrEmbedTokenExpired
+	}
+	return &claims, nil
+}
+
+func (s *EmbedService) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("embed:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
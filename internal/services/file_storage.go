@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFileKey   = errors.New("invalid file key")
+	ErrInvalidSignature = errors.New("invalid or expired file signature")
+	ErrFileNotFound     = errors.New("file not found")
+)
+
+// FileStorage - хранилище загруженных файлов. Файлы не публичны: клиенты получают
+// временные подписанные ссылки, поэтому драйвер можно сменить без миграции URL в базе.
+type FileStorage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get - содержимое файла; ErrFileNotFound, если файла нет
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL - ссылка на чтение файла, действительная ttl
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// validateFileKey - ключ вида "avatars/<id>/<версия>/128.jpg" без выхода за пределы хранилища
+func validateFileKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return ErrInvalidFileKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "." || part == ".." {
+			return ErrInvalidFileKey
+		}
+	}
+	return nil
+}
+
+// LocalFileStorage хранит файлы на диске сервера и раздает их через GET /media/*key
+// с HMAC-подписью в ссылке. Подходит для одного экземпляра сервера и разработки.
+type LocalFileStorage struct {
+	dir     string
+	secret  []byte
+	baseURL string // Префикс ссылок, например "/media"
+}
+
+func NewLocalFileStorage(dir, secret, baseURL string) (*LocalFileStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalFileStorage{dir: dir, secret: []byte(secret), baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (s *LocalFileStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return err
+	}
+	// Запись во временный файл и переименование: читатель не увидит недописанный файл
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+func (s *LocalFileStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+	return data, err
+}
+
+func (s *LocalFileStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalFileStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if err := validateFileKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// Open проверяет подпись ссылки и возвращает путь к файлу на диске
+func (s *LocalFileStorage) Open(key, expires, signature string) (string, error) {
+	if err := validateFileKey(key); err != nil {
+		return "", err
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return "", ErrInvalidSignature
+	}
+	return s.path(key)
+}
+
+func (s *LocalFileStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalFileStorage) path(key string) (string, error) {
+	if err := validateFileKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
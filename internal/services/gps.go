@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	gpsDefaultPollInterval = 15 * time.Second
+	gpsFetchTimeout        = 10 * time.Second
+	gpsMaxPayloadSize      = 10 << 20
+	// Позиции из будущего (рассинхрон часов провайдера) ограничиваем текущим временем
+	gpsMaxClockSkew = 2 * time.Minute
+)
+
+// ErrGPSSignature возвращается, если подпись webhook не совпадает
+var ErrGPSSignature = errors.New("invalid webhook signature")
+
+// VehiclePosition - позиция транспорта в нормализованном виде, независимо от провайдера
+type VehiclePosition struct {
+	ExternalID string    // Идентификатор транспорта у провайдера
+	Latitude   float64   // Градусы
+	Longitude  float64   // Градусы
+	Speed      float64   // км/ч
+	Heading    float64   // Градусы от севера
+	RecordedAt time.Time // Время фиксации позиции
+}
+
+// GPSProvider - внешний источник позиций транспорта (система мониторинга автопарка)
+type GPSProvider interface {
+	Name() string
+}
+
+// GPSPollingProvider - провайдер, у которого позиции забираются по расписанию.
+// Interval() <= 0 отключает опрос.
+type GPSPollingProvider interface {
+	GPSProvider
+	Interval() time.Duration
+	Fetch(ctx context.Context) ([]VehiclePosition, error)
+}
+
+// GPSWebhookProvider - провайдер, который сам присылает позиции на webhook
+type GPSWebhookProvider interface {
+	GPSProvider
+	ParseWebhook(r *http.Request, body []byte) ([]VehiclePosition, error)
+}
+
+// GPSIngestStats - результат обработки пакета позиций
+type GPSIngestStats struct {
+	Received int `json:"received"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"` // Неизвестный транспорт, устаревшие или некорректные позиции
+}
+
+// GPSIngestionService нормализует позиции провайдеров и записывает их в коллекцию транспорта.
+// Транспорт принимает позиции только от провайдера, указанного в его настройках tracking.
+type GPSIngestionService struct {
+	vehicleCollection *mongo.Collection
+	providers         map[string]GPSProvider
+}
+
+func NewGPSIngestionService(vehicleCollection *mongo.Collection) *GPSIngestionService {
+	return &GPSIngestionService{
+		vehicleCollection: vehicleCollection,
+		providers:         make(map[string]GPSProvider),
+	}
+}
+
+// Register добавляет адаптер провайдера
+func (s *GPSIngestionService) Register(provider GPSProvider) {
+	s.providers[provider.Name()] = provider
+}
+
+// Provider возвращает зарегистрированный адаптер по имени
+func (s *GPSIngestionService) Provider(name string) (GPSProvider, bool) {
+	provider, ok := s.providers[name]
+	return provider, ok
+}
+
+// ProviderInfo - описание провайдера для администраторов
+type ProviderInfo struct {
+	Name    string `json:"name"`
+	Polling bool   `json:"polling"`
+	Webhook bool   `json:"webhook"`
+}
+
+// Providers возвращает список зарегистрированных провайдеров
+func (s *GPSIngestionService) Providers() []ProviderInfo {
+	result := make([]ProviderInfo, 0, len(s.providers))
+	for name, provider := range s.providers {
+		pollingProvider, polling := provider.(GPSPollingProvider)
+		polling = polling && pollingProvider.Interval() > 0
+		_, webhook := provider.(GPSWebhookProvider)
+		result = append(result, ProviderInfo{Name: name, Polling: polling, Webhook: webhook})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Ingest записывает позиции провайдера в транспорт, привязанный к нему.
+// Более старые позиции, чем уже сохраненная, пропускаются.
+func (s *GPSIngestionService) Ingest(ctx context.Context, providerName string, positions []VehiclePosition) GPSIngestStats {
+	stats := GPSIngestStats{Received: len(positions)}
+	now := time.Now()
+
+	for _, position := range positions {
+		if position.ExternalID == "" ||
+			position.Latitude < -90 || position.Latitude > 90 ||
+			position.Longitude < -180 || position.Longitude > 180 {
+			stats.Skipped++
+			continue
+		}
+
+		recordedAt := position.RecordedAt
+		if recordedAt.IsZero() || recordedAt.After(now.Add(gpsMaxClockSkew)) {
+			recordedAt = now
+		}
+
+		result, err := s.vehicleCollection.UpdateOne(ctx,
+			bson.M{
+				"tracking.source":      models.TrackingSourceProvider,
+				"tracking.provider":    providerName,
+				"tracking.external_id": position.ExternalID,
+				"$or": []bson.M{
+					{"last_update": nil}, // Поле отсутствует или пустое
+					{"last_update": bson.M{"$lt": recordedAt}},
+				},
+			},
+			bson.M{"$set": bson.M{
+				"current_location": models.Location{
+					Type:        "Point",
+					Coordinates: []float64{position.Longitude, position.Latitude},
+				},
+				"speed":       position.Speed,
+				"heading":     position.Heading,
+				"is_online":   true,
+				"last_update": recordedAt,
+				"updated_at":  now,
+			}},
+		)
+		if err != nil {
+			log.Printf("Error ingesting GPS position from %s: %v", providerName, err)
+			stats.Skipped++
+			continue
+		}
+		if result.MatchedCount == 0 {
+			stats.Skipped++
+			continue
+		}
+		stats.Updated++
+	}
+
+	return stats
+}
+
+// StartPolling запускает опрос всех polling-провайдеров, каждый в своей горутине
+func (s *GPSIngestionService) StartPolling() {
+	for _, provider := range s.providers {
+		if polling, ok := provider.(GPSPollingProvider); ok && polling.Interval() > 0 {
+			go s.poll(polling)
+		}
+	}
+}
+
+func (s *GPSIngestionService) poll(provider GPSPollingProvider) {
+	interval := provider.Interval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		positions, err := provider.Fetch(ctx)
+		if err != nil {
+			log.Printf("Error polling GPS provider %s: %v", provider.Name(), err)
+			cancel()
+			continue
+		}
+		s.Ingest(ctx, provider.Name(), positions)
+		cancel()
+	}
+}
+
+// ========================================
+// УНИВЕРСАЛЬНЫЙ JSON-АДАПТЕР
+// ========================================
+
+// genericGPSPayload - формат универсального адаптера:
+// {"positions":[{"id":"bus-17","lat":46.76,"lng":33.37,"speed":32,"heading":90,"timestamp":"2025-01-01T10:00:00Z"}]}
+type genericGPSPayload struct {
+	Positions []struct {
+		ID        string    `json:"id"`
+		Lat       float64   `json:"lat"`
+		Lng       float64   `json:"lng"`
+		Speed     float64   `json:"speed"`
+		Heading   float64   `json:"heading"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"positions"`
+}
+
+func parseGenericGPSPayload(body []byte) ([]VehiclePosition, error) {
+	var payload genericGPSPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid positions payload: %w", err)
+	}
+
+	positions := make([]VehiclePosition, 0, len(payload.Positions))
+	for _, p := range payload.Positions {
+		positions = append(positions, VehiclePosition{
+			ExternalID: p.ID,
+			Latitude:   p.Lat,
+			Longitude:  p.Lng,
+			Speed:      p.Speed,
+			Heading:    p.Heading,
+			RecordedAt: p.Timestamp,
+		})
+	}
+	return positions, nil
+}
+
+// GenericGPSProvider - провайдер с универсальным JSON-форматом.
+// С URL позиции забираются опросом; с секретом принимаются на webhook
+// (подпись X-Signature: hex HMAC-SHA256 тела запроса).
+type GenericGPSProvider struct {
+	name     string
+	url      string
+	token    string
+	secret   string
+	interval time.Duration
+	client   *http.Client
+}
+
+func NewGenericGPSProvider(name, url, token, secret string, interval time.Duration) *GenericGPSProvider {
+	if interval <= 0 {
+		interval = gpsDefaultPollInterval
+	}
+	return &GenericGPSProvider{
+		name:     name,
+		url:      url,
+		token:    token,
+		secret:   secret,
+		interval: interval,
+		client:   &http.Client{Timeout: gpsFetchTimeout},
+	}
+}
+
+func (p *GenericGPSProvider) Name() string {
+	return p.name
+}
+
+// Interval - без URL провайдер работает только через webhook и не опрашивается
+func (p *GenericGPSProvider) Interval() time.Duration {
+	if p.url == "" {
+		return 0
+	}
+	return p.interval
+}
+
+func (p *GenericGPSProvider) Fetch(ctx context.Context) ([]VehiclePosition, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider responded with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, gpsMaxPayloadSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseGenericGPSPayload(body)
+}
+
+// ParseWebhook - без секрета webhook отключен
+func (p *GenericGPSProvider) ParseWebhook(r *http.Request, body []byte) ([]VehiclePosition, error) {
+	if p.secret == "" {
+		return nil, ErrGPSSignature
+	}
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Signature"))) {
+		return nil, ErrGPSSignature
+	}
+	return parseGenericGPSPayload(body)
+}
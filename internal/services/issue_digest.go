@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Как часто воркер проверяет, не пора ли отправить дайджест
+const issueDigestCheckInterval = 10 * time.Minute
+
+// Сколько проблем перечислять в тексте дайджеста
+const issueDigestTitlesLimit = 3
+
+// IssueDigestService копит несрочные обновления проблем для подписчиков в режиме digest
+// и раз в сутки отправляет каждому одно уведомление вместо уведомления на каждый комментарий.
+type IssueDigestService struct {
+	digestCollection    *mongo.Collection
+	notificationService *NotificationService
+	hour                int // Час отправки (локальное время сервера)
+
+	mu       sync.Mutex
+	lastSent time.Time // День последней отправки этим экземпляром
+}
+
+func NewIssueDigestService(digestCollection *mongo.Collection, notificationService *NotificationService, hour int) *IssueDigestService {
+	return &IssueDigestService{
+		digestCollection:    digestCollection,
+		notificationService: notificationService,
+		hour:                hour,
+	}
+}
+
+// Queue откладывает обновление проблемы в дайджест пользователей
+func (s *IssueDigestService) Queue(ctx context.Context, userIDs []primitive.ObjectID, issue *models.CityIssue, update models.IssueUpdate) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	items := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		items = append(items, models.IssueDigestItem{
+			UserID:     userID,
+			IssueID:    issue.ID,
+			IssueTitle: issue.Title,
+			Kind:       update.Kind,
+			Status:     update.Status,
+			Preview:    update.Preview,
+			CreatedAt:  now,
+		})
+	}
+
+	_, err := s.digestCollection.InsertMany(ctx, items)
+	return err
+}
+
+// StartWorker раз в сутки в заданный час отправляет накопленные дайджесты
+func (s *IssueDigestService) StartWorker() {
+	ticker := time.NewTicker(issueDigestCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		if now.Hour() < s.hour || !s.markDue(now) {
+			continue
+		}
+		if err := s.SendDue(); err != nil {
+			log.Printf("Error sending issue digests: %v", err)
+		}
+	}
+}
+
+// markDue отмечает сегодняшнюю отправку; false - сегодня уже отправляли
+func (s *IssueDigestService) markDue(now time.Time) bool {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.lastSent.Before(day) {
+		return false
+	}
+	s.lastSent = day
+	return true
+}
+
+// SendDue отправляет все накопленные записи. Записи сначала помечаются batch_id,
+// поэтому несколько экземпляров сервера не отправят одну запись дважды.
+func (s *IssueDigestService) SendDue() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	batchID := primitive.NewObjectID()
+	claimed, err := s.digestCollection.UpdateMany(ctx,
+		bson.M{"batch_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"batch_id": batchID}},
+	)
+	if err != nil {
+		return err
+	}
+	if claimed.ModifiedCount == 0 {
+		return nil
+	}
+
+	cursor, err := s.digestCollection.Find(ctx, bson.M{"batch_id": batchID})
+	if err != nil {
+		return err
+	}
+	var items []models.IssueDigestItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return err
+	}
+
+	byUser := make(map[primitive.ObjectID][]models.IssueDigestItem)
+	for _, item := range items {
+		byUser[item.UserID] = append(byUser[item.UserID], item)
+	}
+
+	sent := 0
+	for userID, userItems := range byUser {
+		title, body, data := issueDigestMessage(userItems)
+		if err := s.notificationService.SendNotificationToUser(ctx, userID, title, body, NotificationTypeSystem, data, nil); err != nil {
+			log.Printf("Error sending issue digest to %s: %v", userID.Hex(), err)
+			continue
+		}
+		sent++
+	}
+
+	if _, err := s.digestCollection.DeleteMany(ctx, bson.M{"batch_id": batchID}); err != nil {
+		return fmt.Errorf("failed to delete sent digest items: %w", err)
+	}
+
+	log.Printf("Issue digests sent: %d users, %d updates", sent, len(items))
+	return nil
+}
+
+// issueDigestMessage собирает текст дайджеста: число обновлений и названия проблем
+func issueDigestMessage(items []models.IssueDigestItem) (string, string, map[string]interface{}) {
+	var issueIDs []string
+	var titles []string
+	seen := make(map[primitive.ObjectID]bool)
+	comments := 0
+	statuses := 0
+
+	for _, item := range items {
+		if item.Kind == models.IssueUpdateStatus {
+			statuses++
+		} else {
+			comments++
+		}
+		if seen[item.IssueID] {
+			continue
+		}
+		seen[item.IssueID] = true
+		issueIDs = append(issueIDs, item.IssueID.Hex())
+		if len(titles) < issueDigestTitlesLimit {
+			titles = append(titles, item.IssueTitle)
+		}
+	}
+
+	body := fmt.Sprintf("Новых обновлений: %d (комментарии: %d, статус: %d). Проблемы: %s",
+		len(items), comments, statuses, strings.Join(titles, "; "))
+	if len(issueIDs) > len(titles) {
+		body += fmt.Sprintf(" и еще %d", len(issueIDs)-len(titles))
+	}
+
+	data := map[string]interface{}{
+		"digest":    true,
+		"issue_ids": issueIDs,
+		"updates":   len(items),
+	}
+	return "Обновления по отслеживаемым проблемам", body, data
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Меньше букв - язык не определяется (слишком короткий текст)
+const minLanguageLetters = 8
+
+// В украинском тексте такой длины почти всегда есть хотя бы одна і, ї или є
+const ukrainianMarkerLetters = 40
+
+// Доля букв алфавита, при которой текст считается написанным на этом алфавите
+const languageScriptShare = 0.6
+
+// Служебные слова, которые есть только в одном из языков. Общие (на, не, до, для)
+// не учитываются: по ним украинский и русский не различить.
+var (
+	ukrainianWords = wordSet("і", "й", "та", "що", "це", "як", "від", "або", "ще", "вже", "який", "яка", "які",
+		"він", "вона", "вони", "ми", "ви", "його", "був", "було", "буде", "немає", "ні", "є", "між", "коли")
+	russianWords = wordSet("и", "что", "это", "как", "от", "или", "еще", "ещё", "уже", "который", "которая",
+		"которые", "он", "она", "они", "мы", "вы", "его", "был", "было", "будет", "нет", "ни", "между", "когда")
+)
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// DetectLanguage определяет язык текста (uk, ru, en) по алфавиту, буквам,
+// которые есть только в украинском (і ї є ґ) или русском (ы э ъ ё), и служебным словам.
+// Пустая строка - язык не определен: текст слишком короткий, смешанный или без признаков.
+func DetectLanguage(texts ...string) string {
+	text := strings.ToLower(strings.Join(texts, " "))
+
+	var latin, cyrillic, ukrainian, russian int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			switch r {
+			case 'і', 'ї', 'є', 'ґ':
+				ukrainian++
+			case 'ы', 'э', 'ъ', 'ё':
+				russian++
+			}
+		}
+	}
+
+	letters := latin + cyrillic
+	if letters < minLanguageLetters {
+		return ""
+	}
+	if float64(latin) >= float64(letters)*languageScriptShare {
+		return models.LanguageEnglish
+	}
+	if float64(cyrillic) < float64(letters)*languageScriptShare {
+		return ""
+	}
+
+	if ukrainian == 0 && cyrillic >= ukrainianMarkerLetters {
+		russian++
+	}
+
+	// Служебное слово весит больше отдельной буквы
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if ukrainianWords[token] {
+			ukrainian += 2
+		}
+		if russianWords[token] {
+			russian += 2
+		}
+	}
+
+	switch {
+	case ukrainian > russian:
+		return models.LanguageUkrainian
+	case russian > ukrainian:
+		return models.LanguageRussian
+	}
+	return ""
+}
+
+// BackfillLanguages определяет язык документов, созданных до появления поля language.
+// Документы, язык которых определить не удалось, получают пустое значение и повторно
+// не проверяются. Возвращает число документов с определенным языком.
+func BackfillLanguages(ctx context.Context, collection *mongo.Collection) (int, error) {
+	cursor, err := collection.Find(ctx,
+		bson.M{"language": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"title": 1, "description": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID          primitive.ObjectID `bson:"_id"`
+			Title       string             `bson:"title"`
+			Description string             `bson:"description"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+
+		language := DetectLanguage(doc.Title, doc.Description)
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "language": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"language": language}},
+		); err != nil {
+			return updated, err
+		}
+		if language != "" {
+			updated++
+		}
+	}
+	return updated, cursor.Err()
+}
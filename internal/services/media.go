@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Видеохостинги, ссылки на которые разрешены по умолчанию
+var defaultVideoHosts = []string{"youtube.com", "www.youtube.com", "youtu.be", "vimeo.com", "player.vimeo.com"}
+
+// MediaService - проверка ссылок на медиафайлы.
+// Изображения принимаются только из медиахранилища города, видео - также с разрешенных видеохостингов.
+type MediaService struct {
+	mediaHost  string
+	mediaPath  string
+	videoHosts map[string]bool
+}
+
+func NewMediaService(mediaBaseURL string, videoHosts []string) *MediaService {
+	s := &MediaService{videoHosts: make(map[string]bool)}
+
+	if parsed, err := url.Parse(strings.TrimRight(mediaBaseURL, "/")); err == nil {
+		s.mediaHost = strings.ToLower(parsed.Host)
+		s.mediaPath = parsed.Path
+	}
+
+	if len(videoHosts) == 0 {
+		videoHosts = defaultVideoHosts
+	}
+	for _, host := range videoHosts {
+		s.videoHosts[strings.ToLower(host)] = true
+	}
+	if s.mediaHost != "" {
+		s.videoHosts[s.mediaHost] = true
+	}
+
+	return s
+}
+
+// ValidateImageURL проверяет, что изображение загружено в медиахранилище
+func (s *MediaService) ValidateImageURL(rawURL string) error {
+	parsed, err := s.parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if s.mediaHost == "" || parsed.Host != s.mediaHost || !strings.HasPrefix(parsed.Path, s.mediaPath+"/") {
+		return fmt.Errorf("image must be uploaded to the media service: %s", rawURL)
+	}
+	return nil
+}
+
+// ValidateVideoURL проверяет ссылку на видео (медиахранилище или разрешенный видеохостинг)
+func (s *MediaService) ValidateVideoURL(rawURL string) error {
+	parsed, err := s.parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if !s.videoHosts[parsed.Host] {
+		return fmt.Errorf("video host is not allowed: %s", parsed.Host)
+	}
+	return nil
+}
+
+func (s *MediaService) parse(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid media URL: %s", rawURL)
+	}
+	if parsed.Scheme != "https" {
+		return nil, fmt.Errorf("media URL must use https: %s", rawURL)
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	return parsed, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"nova-kakhovka-ecity/internal/models"
+)
+
+// Размеры фрагмента сообщения в результатах поиска (в символах)
+const (
+	messageSnippetLength  = 160
+	messageSnippetContext = 40 // Сколько текста показывать перед первым найденным словом
+	messageSnippetSlack   = 15 // Насколько можно сдвинуть границу фрагмента до пробела
+)
+
+// MessageSearchTerms разбирает запрос так же, как $text: слова, фразы в кавычках
+// и исключенные слова с минусом (они не подсвечиваются). Результат - в нижнем регистре.
+func MessageSearchTerms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(term string) {
+		term = strings.Join(strings.Fields(strings.ToLower(term)), " ")
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		// Нечетные части находятся внутри кавычек
+		if i%2 == 1 {
+			add(part)
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+			for _, token := range strings.FieldsFunc(word, func(r rune) bool { return !isSearchWordRune(r) }) {
+				add(token)
+			}
+		}
+	}
+	return terms
+}
+
+// MessageSnippet возвращает фрагмент сообщения вокруг первого найденного слова
+// и позиции всех найденных слов внутри фрагмента
+func MessageSnippet(content string, terms []string) (string, []models.TextRange) {
+	text := []rune(content)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		if r == '\n' || r == '\r' || r == '\t' {
+			text[i] = ' '
+		}
+		lower[i] = unicode.ToLower(text[i])
+	}
+
+	matches := findSearchTerms(lower, terms)
+	if len(text) <= messageSnippetLength {
+		return string(text), matches
+	}
+
+	anchor := 0
+	if len(matches) > 0 {
+		anchor = matches[0].Start
+	}
+	start := anchor - messageSnippetContext
+	if start < 0 {
+		start = 0
+	}
+	end := start + messageSnippetLength
+	if end > len(text) {
+		end = len(text)
+		start = end - messageSnippetLength
+	}
+
+	// Фрагмент начинается и заканчивается на границе слова, если она рядом
+	if start > 0 {
+		for i := start; i < start+messageSnippetSlack && i < anchor; i++ {
+			if text[i] == ' ' {
+				start = i + 1
+				break
+			}
+		}
+	}
+	if end < len(text) {
+		for i := end; i > end-messageSnippetSlack && i > start; i-- {
+			if text[i-1] == ' ' {
+				end = i - 1
+				break
+			}
+		}
+	}
+
+	var snippet strings.Builder
+	offset := -start
+	if start > 0 {
+		snippet.WriteRune('…')
+		offset++
+	}
+	snippet.WriteString(string(text[start:end]))
+	if end < len(text) {
+		snippet.WriteRune('…')
+	}
+
+	var highlights []models.TextRange
+	for _, m := range matches {
+		if m.Start >= start && m.End <= end {
+			highlights = append(highlights, models.TextRange{Start: m.Start + offset, End: m.End + offset})
+		}
+	}
+	return snippet.String(), highlights
+}
+
+// findSearchTerms находит вхождения терминов целыми словами; пересекающиеся вхождения
+// не дублируются (из "Нова Каховка" и "каховка" остается более раннее и длинное)
+func findSearchTerms(text []rune, terms []string) []models.TextRange {
+	var matches []models.TextRange
+	for _, term := range terms {
+		pattern := []rune(term)
+		for i := 0; i+len(pattern) <= len(text); i++ {
+			if i > 0 && isSearchWordRune(text[i-1]) {
+				continue
+			}
+			end := i + len(pattern)
+			if end < len(text) && isSearchWordRune(text[end]) {
+				continue
+			}
+			if string(text[i:end]) == term {
+				matches = append(matches, models.TextRange{Start: i, End: end})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	var result []models.TextRange
+	for _, m := range matches {
+		if len(result) > 0 && m.Start < result[len(result)-1].End {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func isSearchWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ModerationLogService - журнал решений модераторов для аналитики нагрузки
+type ModerationLogService struct {
+	actionCollection *mongo.Collection
+}
+
+func NewModerationLogService(actionCollection *mongo.Collection) *ModerationLogService {
+	return &ModerationLogService{
+		actionCollection: actionCollection,
+	}
+}
+
+// Record сохраняет решение модератора. Ошибка журнала не должна ломать саму модерацию, поэтому только логируется.
+func (s *ModerationLogService) Record(ctx context.Context, action models.ModerationAction) {
+	if action.DecidedAt.IsZero() {
+		action.DecidedAt = time.Now()
+	}
+	if !action.SubmittedAt.IsZero() {
+		action.DecisionSeconds = int64(action.DecidedAt.Sub(action.SubmittedAt).Seconds())
+	}
+
+	if _, err := s.actionCollection.InsertOne(ctx, action); err != nil {
+		log.Printf("Error recording moderation action: %v", err)
+	}
+}
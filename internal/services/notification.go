@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"nova-kakhovka-ecity/internal/config"
@@ -14,6 +17,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type NotificationService struct {
@@ -21,6 +25,20 @@ type NotificationService struct {
 	userCollection         *mongo.Collection
 	notificationCollection *mongo.Collection
 	httpClient             *http.Client
+	pushBreaker            *ProviderBreaker
+
+	// Подписчики на сохранение уведомлений (long-poll, WebSocket)
+	storedListeners []func(notification *StoredNotification)
+
+	// Outbox: намерения уведомить, записанные вместе с изменением данных (notification_outbox.go)
+	outboxCollection *mongo.Collection
+	outboxWake       chan struct{}
+	txOnce           sync.Once
+	txSupported      bool
+
+	// Тихие часы города; у пользователя могут быть свои (notification_preferences.quiet_hours)
+	quietHours    models.QuietHours
+	quietLocation *time.Location
 }
 
 type FCMMessage struct {
@@ -72,12 +90,21 @@ type StoredNotification struct {
 	Title     string                 `bson:"title" json:"title"`
 	Body      string                 `bson:"body" json:"body"`
 	Type      string                 `bson:"type" json:"type"` // message, event, announcement, system
+	Module    string                 `bson:"module,omitempty" json:"module,omitempty"`
+	Category  string                 `bson:"category" json:"category"` // messages, city, emergency, system
 	RelatedID *primitive.ObjectID    `bson:"related_id,omitempty" json:"related_id,omitempty"`
 	Data      map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
 	IsRead    bool                   `bson:"is_read" json:"is_read"`
 	IsSent    bool                   `bson:"is_sent" json:"is_sent"`
 	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
 	ReadAt    *time.Time             `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	// Текст для чтения с экрана и подсказки произношения
+	models.SpeechHints `bson:",inline"`
+	// Push отложен (FCM недоступен или тихие часы получателя) и будет отправлен, когда
+	// наступит это время
+	PushQueuedAt *time.Time `bson:"push_queued_at,omitempty" json:"-"`
+	// Намерение outbox, по которому создано уведомление (повторная доставка не дублирует его)
+	IntentID *primitive.ObjectID `bson:"intent_id,omitempty" json:"-"`
 }
 
 const (
@@ -89,9 +116,18 @@ const (
 	NotificationTypeAnnouncement = "announcement"
 	NotificationTypeSystem       = "system"
 	NotificationTypeEmergency    = "emergency"
+	NotificationTypeCampaign     = "campaign"
+
+	// Повторная отправка push, отложенных при недоступности FCM
+	pushRetryInterval  = 30 * time.Second
+	pushRetryBatchSize = 500
+	pushQueueMaxAge    = 24 * time.Hour // Более старые push не отправляются, уведомление остается в инбоксе
 )
 
-func NewNotificationService(cfg *config.Config, userCollection, notificationCollection *mongo.Collection) *NotificationService {
+var errFirebaseNotConfigured = errors.New("Firebase key is not configured")
+
+func NewNotificationService(cfg *config.Config, userCollection, notificationCollection, outboxCollection *mongo.Collection) *NotificationService {
+	quietHours, quietLocation := defaultQuietHours(cfg)
 	return &NotificationService{
 		config:                 cfg,
 		userCollection:         userCollection,
@@ -99,30 +135,52 @@ func NewNotificationService(cfg *config.Config, userCollection, notificationColl
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		pushBreaker:      NewProviderBreaker("fcm", cfg.ProviderBreakerThreshold, time.Duration(cfg.ProviderBreakerOpenSec)*time.Second),
+		outboxCollection: outboxCollection,
+		outboxWake:       make(chan struct{}, 1),
+		quietHours:       quietHours,
+		quietLocation:    quietLocation,
+	}
+}
+
+// OnNotificationStored регистрирует обработчик, вызываемый после сохранения уведомления пользователю.
+// Регистрация выполняется при старте, до начала обработки запросов.
+// Обработчик получает сохраненное уведомление с ID и не должен блокировать отправку.
+func (ns *NotificationService) OnNotificationStored(listener func(notification *StoredNotification)) {
+	ns.storedListeners = append(ns.storedListeners, listener)
+}
+
+func (ns *NotificationService) notifyStored(notification *StoredNotification) {
+	for _, listener := range ns.storedListeners {
+		listener(notification)
 	}
 }
 
 // Отправка уведомления одному пользователю
 func (ns *NotificationService) SendNotificationToUser(ctx context.Context, userID primitive.ObjectID, title, body, notificationType string, data map[string]interface{}, relatedID *primitive.ObjectID) error {
 	// Сохраняем уведомление в базе данных
+	module := models.ResolveNotificationModule(notificationType, data)
 	notification := StoredNotification{
-		UserID:    userID,
-		Title:     title,
-		Body:      body,
-		Type:      notificationType,
-		RelatedID: relatedID,
-		Data:      data,
-		IsRead:    false,
-		IsSent:    false,
-		CreatedAt: time.Now(),
+		UserID:      userID,
+		Title:       title,
+		Body:        body,
+		Type:        notificationType,
+		Module:      module,
+		Category:    models.ResolveNotificationCategory(notificationType, module),
+		RelatedID:   relatedID,
+		Data:        data,
+		IsRead:      false,
+		IsSent:      false,
+		CreatedAt:   time.Now(),
+		SpeechHints: SpeechHintsFor(body, models.SpeechHints{}, title, body),
 	}
 
 	result, err := ns.notificationCollection.InsertOne(ctx, notification)
 	if err != nil {
 		return fmt.Errorf("failed to save notification: %w", err)
 	}
-
 	notification.ID = result.InsertedID.(primitive.ObjectID)
+	ns.notifyStored(&notification)
 
 	// Получаем FCM токены пользователя
 	tokens, err := ns.getUserFCMTokens(ctx, userID)
@@ -136,35 +194,62 @@ func (ns *NotificationService) SendNotificationToUser(ctx context.Context, userI
 		return nil
 	}
 
+	hold := ns.quietHoursHold(ctx, []primitive.ObjectID{userID}, notificationType, false)
+	if hold.add(userID, notification.ID) {
+		ns.holdPush(ctx, hold)
+		return nil
+	}
+
 	// Отправляем FCM уведомление
-	err = ns.sendFCMNotification(tokens, title, body, data)
-	if err != nil {
+	if err := ns.deliverPush(ctx, []primitive.ObjectID{notification.ID}, tokens, title, body, pushData(data, notification.SpeechHints)); err != nil {
 		return fmt.Errorf("failed to send FCM notification: %w", err)
 	}
 
-	// Помечаем уведомление как отправленное
-	ns.markNotificationAsSent(ctx, notification.ID)
-
 	return nil
 }
 
 // Отправка уведомления группе пользователей
 func (ns *NotificationService) SendNotificationToUsers(ctx context.Context, userIDs []primitive.ObjectID, title, body, notificationType string, data map[string]interface{}, relatedID *primitive.ObjectID) error {
+	return ns.SendNotificationToUsersWithHints(ctx, userIDs, title, body, notificationType, data, relatedID, models.SpeechHints{})
+}
+
+// SendNotificationToUsersWithHints отправляет уведомление с текстом для чтения с экрана и
+// подсказками произношения от автора. Названия из словаря ударений добавляются автоматически.
+func (ns *NotificationService) SendNotificationToUsersWithHints(ctx context.Context, userIDs []primitive.ObjectID, title, body, notificationType string, data map[string]interface{}, relatedID *primitive.ObjectID, hints models.SpeechHints) error {
+	return ns.sendToUsers(ctx, userIDs, title, body, notificationType, data, relatedID, hints, false)
+}
+
+// SendNotificationIgnoringQuietHours отправляет экстренное уведомление без учета тихих часов.
+// Уведомления других типов отправляются как обычно: тихие часы переопределяются только для emergency.
+func (ns *NotificationService) SendNotificationIgnoringQuietHours(ctx context.Context, userIDs []primitive.ObjectID, title, body, notificationType string, data map[string]interface{}, hints models.SpeechHints) error {
+	return ns.sendToUsers(ctx, userIDs, title, body, notificationType, data, nil, hints, true)
+}
+
+func (ns *NotificationService) sendToUsers(ctx context.Context, userIDs []primitive.ObjectID, title, body, notificationType string, data map[string]interface{}, relatedID *primitive.ObjectID, hints models.SpeechHints, overrideQuietHours bool) error {
 	var allTokens []string
 	var notificationIDs []primitive.ObjectID
+	hold := ns.quietHoursHold(ctx, userIDs, notificationType, overrideQuietHours)
+
+	// Модуль и категория вычисляются при сохранении, чтобы инбокс фильтровался на стороне базы
+	module := models.ResolveNotificationModule(notificationType, data)
+	category := models.ResolveNotificationCategory(notificationType, module)
+	speech := SpeechHintsFor(body, hints, title, body)
 
 	// Сохраняем уведомления для всех пользователей
 	for _, userID := range userIDs {
 		notification := StoredNotification{
-			UserID:    userID,
-			Title:     title,
-			Body:      body,
-			Type:      notificationType,
-			RelatedID: relatedID,
-			Data:      data,
-			IsRead:    false,
-			IsSent:    false,
-			CreatedAt: time.Now(),
+			UserID:      userID,
+			Title:       title,
+			Body:        body,
+			Type:        notificationType,
+			Module:      module,
+			Category:    category,
+			RelatedID:   relatedID,
+			Data:        data,
+			IsRead:      false,
+			IsSent:      false,
+			CreatedAt:   time.Now(),
+			SpeechHints: speech,
 		}
 
 		result, err := ns.notificationCollection.InsertOne(ctx, notification)
@@ -172,15 +257,23 @@ func (ns *NotificationService) SendNotificationToUsers(ctx context.Context, user
 			continue // Продолжаем даже если не удалось сохранить одно уведомление
 		}
 
-		notificationIDs = append(notificationIDs, result.InsertedID.(primitive.ObjectID))
+		notificationID := result.InsertedID.(primitive.ObjectID)
+		notification.ID = notificationID
+		ns.notifyStored(&notification)
 
 		// Получаем токены для каждого пользователя
 		tokens, err := ns.getUserFCMTokens(ctx, userID)
 		if err != nil {
+			notificationIDs = append(notificationIDs, notificationID)
 			continue
 		}
+		if len(tokens) > 0 && hold.add(userID, notificationID) {
+			continue
+		}
+		notificationIDs = append(notificationIDs, notificationID)
 		allTokens = append(allTokens, tokens...)
 	}
+	ns.holdPush(ctx, hold)
 
 	if len(allTokens) == 0 {
 		// Помечаем все уведомления как отправленные
@@ -191,21 +284,16 @@ func (ns *NotificationService) SendNotificationToUsers(ctx context.Context, user
 	}
 
 	// Отправляем FCM уведомление всем токенам
-	err := ns.sendFCMNotification(allTokens, title, body, data)
-	if err != nil {
+	if err := ns.deliverPush(ctx, notificationIDs, allTokens, title, body, pushData(data, speech)); err != nil {
 		return fmt.Errorf("failed to send batch FCM notification: %w", err)
 	}
 
-	// Помечаем все уведомления как отправленные
-	for _, notificationID := range notificationIDs {
-		ns.markNotificationAsSent(ctx, notificationID)
-	}
-
 	return nil
 }
 
 // Отправка экстренного уведомления всем пользователям
-func (ns *NotificationService) SendEmergencyNotification(ctx context.Context, title, body string, data map[string]interface{}) error {
+// overrideQuietHours - отправить push сразу, не дожидаясь конца тихих часов получателей.
+func (ns *NotificationService) SendEmergencyNotification(ctx context.Context, title, body string, data map[string]interface{}, hints models.SpeechHints, overrideQuietHours bool) error {
 	// Получаем всех активных пользователей
 	cursor, err := ns.userCollection.Find(ctx, bson.M{
 		"is_blocked": false,
@@ -224,7 +312,7 @@ func (ns *NotificationService) SendEmergencyNotification(ctx context.Context, ti
 		userIDs = append(userIDs, user.ID)
 	}
 
-	return ns.SendNotificationToUsers(ctx, userIDs, title, body, NotificationTypeEmergency, data, nil)
+	return ns.sendToUsers(ctx, userIDs, title, body, NotificationTypeEmergency, data, nil, hints, overrideQuietHours)
 }
 
 // Специализированные методы для разных типов уведомлений
@@ -337,9 +425,183 @@ func (ns *NotificationService) getUserFCMTokens(ctx context.Context, userID prim
 	return tokens, nil
 }
 
+// deliverPush отправляет push и помечает уведомления отправленными. Пока FCM недоступен,
+// уведомления уже сохранены в инбоксе: push откладывается в очередь повторной отправки,
+// и вызывающий обработчик не получает ошибку.
+func (ns *NotificationService) deliverPush(ctx context.Context, notificationIDs []primitive.ObjectID, tokens []string, title, body string, data map[string]interface{}) error {
+	if ns.config.FirebaseKey == "" {
+		return errFirebaseNotConfigured
+	}
+
+	if !ns.pushBreaker.Allow() {
+		ns.queuePush(ctx, notificationIDs)
+		return nil
+	}
+
+	if err := ns.sendFCMNotification(tokens, title, body, data); err != nil {
+		if ns.pushBreaker.Failure(err) {
+			log.Printf("⚠️  FCM is unavailable, push notifications are queued: %v", err)
+		} else {
+			log.Printf("Error sending FCM notification, queued for retry: %v", err)
+		}
+		ns.queuePush(ctx, notificationIDs)
+		return nil
+	}
+
+	if ns.pushBreaker.Success() {
+		log.Println("✅ FCM recovered, queued push notifications are being resent")
+	}
+	ns.markNotificationsAsSent(ctx, notificationIDs)
+	return nil
+}
+
+// pushData добавляет к data push-уведомления подсказки для чтения вслух.
+// Значения data в FCM - строки, поэтому подсказки произношения передаются JSON-строкой.
+func pushData(data map[string]interface{}, hints models.SpeechHints) map[string]interface{} {
+	if hints.PlainTextBody == "" && len(hints.Pronunciations) == 0 {
+		return data
+	}
+	payload := make(map[string]interface{}, len(data)+2)
+	for key, value := range data {
+		payload[key] = value
+	}
+	if hints.PlainTextBody != "" {
+		payload["plain_text_body"] = hints.PlainTextBody
+	}
+	if len(hints.Pronunciations) > 0 {
+		if encoded, err := json.Marshal(hints.Pronunciations); err == nil {
+			payload["pronunciations"] = string(encoded)
+		}
+	}
+	return payload
+}
+
+// queuePush откладывает push уведомлений до восстановления FCM
+func (ns *NotificationService) queuePush(ctx context.Context, notificationIDs []primitive.ObjectID) {
+	if len(notificationIDs) == 0 {
+		return
+	}
+	_, err := ns.notificationCollection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": notificationIDs}, "push_queued_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"push_queued_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Error queuing push notifications: %v", err)
+	}
+}
+
+// StartPushRetryWorker повторяет отложенные push, когда FCM снова принимает запросы
+func (ns *NotificationService) StartPushRetryWorker() {
+	if ns.config.FirebaseKey == "" {
+		return
+	}
+
+	ticker := time.NewTicker(pushRetryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ns.retryQueuedPush()
+	}
+}
+
+// queuedPush - отложенные уведомления с одинаковым содержимым: отправляются одним запросом
+type queuedPush struct {
+	notification    StoredNotification
+	userIDs         []primitive.ObjectID
+	notificationIDs []primitive.ObjectID
+}
+
+func (ns *NotificationService) retryQueuedPush() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Устаревший push уже не актуален: уведомление остается только в инбоксе
+	ns.notificationCollection.UpdateMany(ctx, bson.M{
+		"is_sent":        false,
+		"push_queued_at": bson.M{"$lt": time.Now().Add(-pushQueueMaxAge)},
+	}, bson.M{"$unset": bson.M{"push_queued_at": ""}})
+
+	if !ns.pushBreaker.Available() {
+		return
+	}
+
+	// Push, отложенные до конца тихих часов, выбираются, когда наступит их время
+	cursor, err := ns.notificationCollection.Find(ctx,
+		bson.M{"is_sent": false, "push_queued_at": bson.M{"$lte": time.Now()}},
+		options.Find().
+			SetSort(bson.D{{Key: "push_queued_at", Value: 1}}).
+			SetLimit(pushRetryBatchSize))
+	if err != nil {
+		log.Printf("Error loading queued push notifications: %v", err)
+		return
+	}
+	var notifications []StoredNotification
+	err = cursor.All(ctx, &notifications)
+	cursor.Close(ctx)
+	if err != nil {
+		log.Printf("Error loading queued push notifications: %v", err)
+		return
+	}
+
+	// Массовая рассылка откладывается отдельным уведомлением на каждого получателя
+	var groups []*queuedPush
+	byContent := map[string]*queuedPush{}
+	for _, notification := range notifications {
+		key := notification.Type + "\x00" + notification.Title + "\x00" + notification.Body
+		if notification.RelatedID != nil {
+			key += "\x00" + notification.RelatedID.Hex()
+		}
+		group, ok := byContent[key]
+		if !ok {
+			group = &queuedPush{notification: notification}
+			byContent[key] = group
+			groups = append(groups, group)
+		}
+		group.userIDs = append(group.userIDs, notification.UserID)
+		group.notificationIDs = append(group.notificationIDs, notification.ID)
+	}
+
+	for _, group := range groups {
+		var tokens []string
+		for _, userID := range group.userIDs {
+			userTokens, err := ns.getUserFCMTokens(ctx, userID)
+			if err != nil {
+				continue
+			}
+			tokens = append(tokens, userTokens...)
+		}
+		if len(tokens) == 0 {
+			ns.markNotificationsAsSent(ctx, group.notificationIDs)
+			continue
+		}
+
+		if !ns.pushBreaker.Allow() {
+			return
+		}
+		notification := group.notification
+		if err := ns.sendFCMNotification(tokens, notification.Title, notification.Body, pushData(notification.Data, notification.SpeechHints)); err != nil {
+			ns.pushBreaker.Failure(err)
+			log.Printf("Error resending queued push notifications: %v", err)
+			return
+		}
+		if ns.pushBreaker.Success() {
+			log.Println("✅ FCM recovered, queued push notifications are being resent")
+		}
+		ns.markNotificationsAsSent(ctx, group.notificationIDs)
+	}
+}
+
+// ProviderHealth - состояние FCM для /health/ready и админ-панели
+func (ns *NotificationService) ProviderHealth() []ProviderHealth {
+	if ns.config.FirebaseKey == "" {
+		return []ProviderHealth{{Name: "fcm", State: ProviderDisabled}}
+	}
+	return []ProviderHealth{ns.pushBreaker.Health()}
+}
+
 func (ns *NotificationService) sendFCMNotification(tokens []string, title, body string, data map[string]interface{}) error {
 	if ns.config.FirebaseKey == "" {
-		return fmt.Errorf("Firebase key is not configured")
+		return errFirebaseNotConfigured
 	}
 
 	// Разбиваем на батчи по 1000 токенов (лимит FCM)
@@ -450,78 +712,98 @@ func (ns *NotificationService) handleFCMResponse(response FCMResponse, tokens []
 
 func (ns *NotificationService) markNotificationAsSent(ctx context.Context, notificationID primitive.ObjectID) {
 	ns.notificationCollection.UpdateOne(ctx, bson.M{"_id": notificationID}, bson.M{
-		"$set": bson.M{"is_sent": true},
+		"$set":   bson.M{"is_sent": true},
+		"$unset": bson.M{"push_queued_at": ""},
 	})
 }
 
-// NotifyNewPoll надсилає повідомлення про новий опрос цільовим групам
-func (ns *NotificationService) NotifyNewPoll(pollID primitive.ObjectID, targetGroups []primitive.ObjectID) error {
-	if len(targetGroups) == 0 {
-		return nil // Немає цільових груп
+func (ns *NotificationService) markNotificationsAsSent(ctx context.Context, notificationIDs []primitive.ObjectID) {
+	if len(notificationIDs) == 0 {
+		return
 	}
+	ns.notificationCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": notificationIDs}}, bson.M{
+		"$set":   bson.M{"is_sent": true},
+		"$unset": bson.M{"push_queued_at": ""},
+	})
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Отримуємо інформацію про опрос
-	pollCollection := ns.userCollection.Database().Collection("polls")
-	var poll struct {
-		Title       string             `bson:"title"`
-		Description string             `bson:"description"`
-		CreatorID   primitive.ObjectID `bson:"creator_id"`
+// TargetGroupMemberIDs повертає незаблокованих учасників цільових груп опитування без повторів
+func (ns *NotificationService) TargetGroupMemberIDs(ctx context.Context, targetGroups []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(targetGroups) == 0 {
+		return nil, nil // Немає цільових груп
 	}
 
-	err := pollCollection.FindOne(ctx, bson.M{"_id": pollID}).Decode(&poll)
+	values, err := ns.userCollection.Distinct(ctx, "_id", bson.M{
+		"groups":     bson.M{"$in": targetGroups},
+		"is_blocked": false,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get poll: %w", err)
+		return nil, fmt.Errorf("failed to find target group members: %w", err)
 	}
 
-	// Знаходимо всіх користувачів з цільових груп
-	var userIDs []primitive.ObjectID
-	for _, groupID := range targetGroups {
-		cursor, err := ns.userCollection.Find(ctx, bson.M{
-			"groups":     groupID,
-			"is_blocked": false,
-		})
-		if err != nil {
-			continue
+	userIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			userIDs = append(userIDs, id)
 		}
+	}
+	return userIDs, nil
+}
 
-		for cursor.Next(ctx) {
-			var user models.User
-			if err := cursor.Decode(&user); err != nil {
-				continue
-			}
+// InterestAudience - получатели уведомления о новом контенте, подобранные по интересам
+type InterestAudience struct {
+	Interests   []string           `bson:"interests" json:"interests"`                           // Коды интересов, которым соответствует контент
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty" json:"community_id,omitempty"` // Громада контента (пусто - все громады)
+	// Громада по умолчанию включает пользователей, зарегистрированных до multi-tenancy
+	IsDefaultCommunity bool               `bson:"is_default_community,omitempty" json:"is_default_community,omitempty"`
+	Preference         string             `bson:"preference,omitempty" json:"preference,omitempty"` // Поле notification_preferences, отключающее такие уведомления (events, polls, ...)
+	ExcludeID          primitive.ObjectID `bson:"exclude_id,omitempty" json:"exclude_id,omitempty"` // Автор контента
+}
 
-			// Перевіряємо чи користувач вже не в списку
-			exists := false
-			for _, uid := range userIDs {
-				if uid == user.ID {
-					exists = true
-					break
-				}
-			}
-			if !exists {
-				userIDs = append(userIDs, user.ID)
+// interestedUserIDs подбирает получателей уведомления о новом контенте: пользователей, выбравших
+// хотя бы один из интересов контента. Отключившие уведомления этого типа в настройках пропускаются.
+func (ns *NotificationService) interestedUserIDs(ctx context.Context, audience InterestAudience) ([]primitive.ObjectID, error) {
+	if len(audience.Interests) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"interests":  bson.M{"$in": audience.Interests},
+		"is_blocked": bson.M{"$ne": true},
+		"is_deleted": bson.M{"$ne": true},
+	}
+	if !audience.ExcludeID.IsZero() {
+		filter["_id"] = bson.M{"$ne": audience.ExcludeID}
+	}
+	if !audience.CommunityID.IsZero() {
+		if audience.IsDefaultCommunity {
+			filter["$or"] = []bson.M{
+				{"community_ids": audience.CommunityID},
+				{"community_ids": bson.M{"$exists": false}},
+				{"community_ids": bson.M{"$size": 0}},
 			}
+		} else {
+			filter["community_ids"] = audience.CommunityID
 		}
-		cursor.Close(ctx)
 	}
-
-	if len(userIDs) == 0 {
-		return nil // Немає користувачів для сповіщення
+	if audience.Preference != "" {
+		filter["notification_preferences."+audience.Preference] = bson.M{"$ne": false}
 	}
 
-	// Формуємо дані для повідомлення
-	data := map[string]interface{}{
-		"type":    "poll",
-		"poll_id": pollID.Hex(),
-		"action":  "open_poll",
+	cursor, err := ns.userCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load interested users: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	title := "Нове опитування"
-	body := fmt.Sprintf("Доступне нове опитування: %s", poll.Title)
-
-	// Надсилаємо повідомлення
-	return ns.SendNotificationToUsers(ctx, userIDs, title, body, "poll", data, &pollID)
+	var userIDs []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var row struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err == nil {
+			userIDs = append(userIDs, row.ID)
+		}
+	}
+	return userIDs, cursor.Err()
 }
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Статусы намерений outbox
+const (
+	OutboxStatusPending    = "pending"    // Ждет доставки или повторной попытки
+	OutboxStatusProcessing = "processing" // Доставляется воркером
+	OutboxStatusDelivered  = "delivered"
+	OutboxStatusFailed     = "failed" // Попытки исчерпаны - нужна повторная постановка администратором
+)
+
+// Параметры доставки outbox
+const (
+	OutboxMaxAttempts   = 8
+	outboxPollInterval  = 5 * time.Second
+	outboxBatchSize     = 50
+	outboxLease         = 2 * time.Minute // Намерение, захваченное упавшим воркером, снова доступно после срока
+	outboxDeliveryLimit = time.Minute
+	outboxRetryBase     = 30 * time.Second
+	outboxRetryMax      = time.Hour
+	// Ожидающее дольше намерение считается зависшим (админ-панель)
+	OutboxStuckAfter = 5 * time.Minute
+)
+
+// NotificationIntent - намерение уведомить, записанное в той же транзакции, что и изменение данных.
+// Воркер доставляет его в инбокс и push, пока доставка не удастся или не исчерпаются попытки.
+type NotificationIntent struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Source string             `bson:"source" json:"source"` // Событие-источник: petition.status_changed, city_issue.comment_added, ...
+
+	// Получатели: явный список или аудитория по интересам, подбираемая при доставке
+	UserIDs  []primitive.ObjectID `bson:"user_ids,omitempty" json:"user_ids,omitempty"`
+	Audience *InterestAudience    `bson:"audience,omitempty" json:"audience,omitempty"`
+
+	Title     string                 `bson:"title" json:"title"`
+	Body      string                 `bson:"body" json:"body"`
+	Type      string                 `bson:"type" json:"type"`
+	Data      map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
+	RelatedID *primitive.ObjectID    `bson:"related_id,omitempty" json:"related_id,omitempty"`
+
+	Status        string     `bson:"status" json:"status"`
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	Delivered     int        `bson:"delivered" json:"delivered"` // Получателей, которым уведомление сохранено
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+	DeliveredAt   *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// WithTransaction выполняет изменение данных и запись намерений (Enqueue с переданным ctx) атомарно.
+// Транзакции MongoDB доступны только в replica set; на одиночном сервере fn выполняется без транзакции.
+func (ns *NotificationService) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !ns.supportsTransactions(ctx) {
+		err := fn(ctx)
+		ns.wakeOutbox()
+		return err
+	}
+
+	session, err := ns.outboxCollection.Database().Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Намерения видны воркеру только после фиксации транзакции
+	ns.wakeOutbox()
+	return nil
+}
+
+// supportsTransactions определяет один раз, работает ли база в replica set или через mongos
+func (ns *NotificationService) supportsTransactions(ctx context.Context) bool {
+	ns.txOnce.Do(func() {
+		var hello bson.M
+		err := ns.outboxCollection.Database().RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err != nil {
+			log.Printf("⚠️  Unable to detect MongoDB topology, notification intents are recorded without transactions: %v", err)
+			return
+		}
+		_, replicaSet := hello["setName"]
+		ns.txSupported = replicaSet || hello["msg"] == "isdbgrid"
+		if !ns.txSupported {
+			log.Println("⚠️  MongoDB is not a replica set, notification intents are recorded without transactions")
+		}
+	})
+	return ns.txSupported
+}
+
+// Enqueue записывает намерение уведомить. Внутри WithTransaction запись фиксируется вместе с данными.
+func (ns *NotificationService) Enqueue(ctx context.Context, intent NotificationIntent) error {
+	if len(intent.UserIDs) == 0 && intent.Audience == nil {
+		return nil
+	}
+
+	now := time.Now()
+	intent.ID = primitive.NewObjectID()
+	intent.Status = OutboxStatusPending
+	intent.Attempts = 0
+	intent.NextAttemptAt = now
+	intent.CreatedAt = now
+	intent.UpdatedAt = now
+
+	if _, err := ns.outboxCollection.InsertOne(ctx, intent); err != nil {
+		return fmt.Errorf("failed to record notification intent: %w", err)
+	}
+	ns.wakeOutbox()
+	return nil
+}
+
+// wakeOutbox будит воркер, не дожидаясь следующего тика
+func (ns *NotificationService) wakeOutbox() {
+	select {
+	case ns.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartOutboxWorker доставляет намерения outbox
+func (ns *NotificationService) StartOutboxWorker() {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ns.ProcessOutbox()
+		select {
+		case <-ticker.C:
+		case <-ns.outboxWake:
+		}
+	}
+}
+
+// ProcessOutbox доставляет готовые к отправке намерения и возвращает их число
+func (ns *NotificationService) ProcessOutbox() int {
+	processed := 0
+	for processed < outboxBatchSize {
+		intent, err := ns.claimIntent()
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Printf("Error claiming notification intent: %v", err)
+			}
+			return processed
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), outboxDeliveryLimit)
+		delivered, deliveryErr := ns.deliverIntent(ctx, &intent)
+		cancel()
+
+		ns.finishIntent(&intent, delivered, deliveryErr)
+		processed++
+	}
+	return processed
+}
+
+// claimIntent захватывает намерение, срок повтора которого наступил, или брошенное упавшим воркером
+func (ns *NotificationService) claimIntent() (NotificationIntent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var intent NotificationIntent
+	err := ns.outboxCollection.FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{"status": OutboxStatusPending, "next_attempt_at": bson.M{"$lte": now}},
+			{"status": OutboxStatusProcessing, "locked_until": bson.M{"$lt": now}},
+		}},
+		bson.M{
+			"$set": bson.M{
+				"status":       OutboxStatusProcessing,
+				"locked_until": now.Add(outboxLease),
+				"updated_at":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&intent)
+	return intent, err
+}
+
+// deliverIntent сохраняет уведомления получателям и отправляет push. Получатели, которым
+// уведомление уже сохранено предыдущей попыткой, пропускаются. Недоступный FCM не считается
+// ошибкой: push уходит в очередь повторной отправки (deliverPush).
+func (ns *NotificationService) deliverIntent(ctx context.Context, intent *NotificationIntent) (int, error) {
+	recipients := intent.UserIDs
+	if intent.Audience != nil {
+		userIDs, err := ns.interestedUserIDs(ctx, *intent.Audience)
+		if err != nil {
+			return 0, err
+		}
+		recipients = userIDs
+	}
+
+	saved, err := ns.notificationCollection.Distinct(ctx, "user_id", bson.M{"intent_id": intent.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check delivered notifications: %w", err)
+	}
+	done := make(map[primitive.ObjectID]bool, len(saved))
+	for _, value := range saved {
+		if userID, ok := value.(primitive.ObjectID); ok {
+			done[userID] = true
+		}
+	}
+
+	module := models.ResolveNotificationModule(intent.Type, intent.Data)
+	category := models.ResolveNotificationCategory(intent.Type, module)
+	speech := SpeechHintsFor(intent.Body, models.SpeechHints{}, intent.Title, intent.Body)
+
+	var notificationIDs []primitive.ObjectID
+	var tokens []string
+	var failed int
+	var lastErr error
+	hold := ns.quietHoursHold(ctx, recipients, intent.Type, false)
+	for _, userID := range recipients {
+		if done[userID] {
+			continue
+		}
+
+		notification := StoredNotification{
+			UserID:      userID,
+			Title:       intent.Title,
+			Body:        intent.Body,
+			Type:        intent.Type,
+			Module:      module,
+			Category:    category,
+			RelatedID:   intent.RelatedID,
+			Data:        intent.Data,
+			CreatedAt:   time.Now(),
+			SpeechHints: speech,
+			IntentID:    &intent.ID,
+		}
+		result, err := ns.notificationCollection.InsertOne(ctx, notification)
+		if mongo.IsDuplicateKeyError(err) {
+			// Сохранено параллельной попыткой после истечения срока захвата
+			done[userID] = true
+			continue
+		}
+		if err != nil {
+			failed++
+			lastErr = err
+			continue
+		}
+		done[userID] = true
+		notificationID := result.InsertedID.(primitive.ObjectID)
+		notification.ID = notificationID
+		ns.notifyStored(&notification)
+
+		userTokens, err := ns.getUserFCMTokens(ctx, userID)
+		if err != nil {
+			notificationIDs = append(notificationIDs, notificationID)
+			continue
+		}
+		if len(userTokens) > 0 && hold.add(userID, notificationID) {
+			continue
+		}
+		notificationIDs = append(notificationIDs, notificationID)
+		tokens = append(tokens, userTokens...)
+	}
+	ns.holdPush(ctx, hold)
+
+	if len(tokens) == 0 {
+		ns.markNotificationsAsSent(ctx, notificationIDs)
+	} else if err := ns.deliverPush(ctx, notificationIDs, tokens, intent.Title, intent.Body, pushData(intent.Data, speech)); err != nil && err != errFirebaseNotConfigured {
+		log.Printf("Error sending push for notification intent %s: %v", intent.ID.Hex(), err)
+	}
+
+	if failed > 0 {
+		return len(done), fmt.Errorf("failed to save %d of %d notifications: %w", failed, len(recipients), lastErr)
+	}
+	return len(done), nil
+}
+
+// finishIntent фиксирует результат попытки: доставлено, повтор с задержкой или отказ
+func (ns *NotificationService) finishIntent(intent *NotificationIntent, delivered int, deliveryErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{
+		"delivered":  delivered,
+		"updated_at": now,
+	}
+	unset := bson.M{"locked_until": ""}
+
+	switch {
+	case deliveryErr == nil:
+		set["status"] = OutboxStatusDelivered
+		set["delivered_at"] = now
+		unset["last_error"] = ""
+	case intent.Attempts >= OutboxMaxAttempts:
+		set["status"] = OutboxStatusFailed
+		set["last_error"] = deliveryErr.Error()
+		log.Printf("⚠️  Notification intent %s (%s) failed after %d attempts: %v", intent.ID.Hex(), intent.Source, intent.Attempts, deliveryErr)
+	default:
+		set["status"] = OutboxStatusPending
+		set["last_error"] = deliveryErr.Error()
+		set["next_attempt_at"] = now.Add(outboxRetryDelay(intent.Attempts))
+	}
+
+	_, err := ns.outboxCollection.UpdateOne(ctx,
+		bson.M{"_id": intent.ID, "status": OutboxStatusProcessing},
+		bson.M{"$set": set, "$unset": unset},
+	)
+	if err != nil {
+		log.Printf("Error updating notification intent %s: %v", intent.ID.Hex(), err)
+	}
+}
+
+// outboxRetryDelay - экспоненциальная задержка повтора: 30 с, 1 мин, 2 мин ... до часа
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBase
+	for i := 1; i < attempts && delay < outboxRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > outboxRetryMax {
+		delay = outboxRetryMax
+	}
+	return delay
+}
+
+// OutboxStuckFilter - намерения, требующие внимания: отказ, повторные попытки, брошенные воркером
+// и слишком долго ожидающие доставки
+func OutboxStuckFilter(now time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"status": OutboxStatusFailed},
+		{"status": OutboxStatusPending, "attempts": bson.M{"$gt": 0}},
+		{"status": OutboxStatusPending, "created_at": bson.M{"$lt": now.Add(-OutboxStuckAfter)}},
+		{"status": OutboxStatusProcessing, "locked_until": bson.M{"$lt": now}},
+	}}
+}
+
+// RequeueIntent возвращает отказавшее или зависшее намерение в очередь с новым счетчиком попыток.
+// false - намерение не найдено или уже доставлено.
+func (ns *NotificationService) RequeueIntent(ctx context.Context, intentID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	result, err := ns.outboxCollection.UpdateOne(ctx,
+		bson.M{"$and": []bson.M{{"_id": intentID}, OutboxStuckFilter(now)}},
+		bson.M{
+			"$set": bson.M{
+				"status":          OutboxStatusPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+				"updated_at":      now,
+			},
+			"$unset": bson.M{"locked_until": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, nil
+	}
+	ns.wakeOutbox()
+	return true, nil
+}
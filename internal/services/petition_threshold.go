@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrPetitionThresholdExists - для категории громады уже задан порог
+var ErrPetitionThresholdExists = errors.New("petition threshold already defined for this category")
+
+// PetitionThresholdService - пороги подписей петиций, заданные администрацией по категориям.
+// Порог применяется при публикации и заменяет значение, указанное автором.
+type PetitionThresholdService struct {
+	collection *mongo.Collection
+}
+
+func NewPetitionThresholdService(collection *mongo.Collection) *PetitionThresholdService {
+	return &PetitionThresholdService{collection: collection}
+}
+
+// List возвращает пороги громады: сначала правило по умолчанию, затем категории по алфавиту
+func (s *PetitionThresholdService) List(ctx context.Context, scope bson.M) ([]models.PetitionThreshold, error) {
+	cursor, err := s.collection.Find(ctx, scope, options.Find().SetSort(bson.D{{Key: "category", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	thresholds := []models.PetitionThreshold{}
+	if err := cursor.All(ctx, &thresholds); err != nil {
+		return nil, err
+	}
+	for i := range thresholds {
+		thresholds[i].Required = thresholds[i].RequiredSignatures()
+	}
+	return thresholds, nil
+}
+
+// Resolve находит порог для категории: правило категории, иначе правило по умолчанию.
+// nil - администрация порог не задала, действует значение автора.
+func (s *PetitionThresholdService) Resolve(ctx context.Context, category string, scope bson.M) (*models.PetitionThreshold, error) {
+	filter := bson.M{"category": bson.M{"$in": []string{category, ""}}}
+	for key, value := range scope {
+		filter[key] = value
+	}
+
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []models.PetitionThreshold
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// Правило категории важнее правила по умолчанию, правило громады - правила без community_id
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].Category != "") != (candidates[j].Category != "") {
+			return candidates[i].Category != ""
+		}
+		return !candidates[i].CommunityID.IsZero() && candidates[j].CommunityID.IsZero()
+	})
+	candidates[0].Required = candidates[0].RequiredSignatures()
+	return &candidates[0], nil
+}
+
+// Create сохраняет новый порог
+func (s *PetitionThresholdService) Create(ctx context.Context, threshold *models.PetitionThreshold) error {
+	now := time.Now()
+	threshold.ID = primitive.NewObjectID()
+	threshold.CreatedAt = now
+	threshold.UpdatedAt = now
+
+	if _, err := s.collection.InsertOne(ctx, threshold); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrPetitionThresholdExists
+		}
+		return err
+	}
+	threshold.Required = threshold.RequiredSignatures()
+	return nil
+}
+
+// Update заменяет правило порога; категория и громада не меняются
+func (s *PetitionThresholdService) Update(ctx context.Context, id primitive.ObjectID, threshold *models.PetitionThreshold, scope bson.M) (bool, error) {
+	filter := bson.M{"_id": id}
+	for key, value := range scope {
+		filter[key] = value
+	}
+
+	threshold.UpdatedAt = time.Now()
+	var updated models.PetitionThreshold
+	err := s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{
+		"mode":               threshold.Mode,
+		"signatures":         threshold.Signatures,
+		"population":         threshold.Population,
+		"population_percent": threshold.PopulationPercent,
+		"min_signatures":     threshold.MinSignatures,
+		"max_signatures":     threshold.MaxSignatures,
+		"legal_basis":        threshold.LegalBasis,
+		"legal_basis_url":    threshold.LegalBasisURL,
+		"updated_by":         threshold.UpdatedBy,
+		"updated_at":         threshold.UpdatedAt,
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*threshold = updated
+	threshold.Required = threshold.RequiredSignatures()
+	return true, nil
+}
+
+// Remove удаляет порог; опубликованные петиции сохраняют примененный порог
+func (s *PetitionThresholdService) Remove(ctx context.Context, id primitive.ObjectID, scope bson.M) (bool, error) {
+	filter := bson.M{"_id": id}
+	for key, value := range scope {
+		filter[key] = value
+	}
+
+	result, err := s.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const phoneCodeDigits = 6
+
+var (
+	// ErrInvalidPhone - номер не удалось привести к формату E.164
+	ErrInvalidPhone = errors.New("invalid phone number")
+	// ErrPhoneTaken - номер уже подтвержден другим пользователем
+	ErrPhoneTaken = errors.New("phone number is already verified by another user")
+	// ErrPhoneCodeInvalid - код неверный, истек или не запрашивался
+	ErrPhoneCodeInvalid = errors.New("invalid or expired verification code")
+	// ErrPhoneCodeAttempts - исчерпаны попытки ввода кода, нужно запросить новый
+	ErrPhoneCodeAttempts = errors.New("too many verification attempts")
+	// ErrSMSUnavailable - SMS-шлюз не принял сообщение
+	ErrSMSUnavailable = errors.New("sms delivery failed")
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{9,14}$`)
+
+// PhoneRateLimitError - превышен лимит запросов кода на номер
+type PhoneRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PhoneRateLimitError) Error() string {
+	return fmt.Sprintf("too many verification codes requested, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// RetryAfterSeconds - время ожидания, округленное вверх до секунды
+func (e *PhoneRateLimitError) RetryAfterSeconds() int {
+	seconds := int(e.RetryAfter / time.Second)
+	if e.RetryAfter%time.Second > 0 {
+		seconds++
+	}
+	return seconds
+}
+
+// PhoneVerificationConfig - сроки и лимиты кодов подтверждения
+type PhoneVerificationConfig struct {
+	CodeTTL        time.Duration // Время жизни кода
+	MaxAttempts    int           // Неверных вводов одного кода
+	CodesPerHour   int           // Запросов кода на один номер в час
+	ResendInterval time.Duration // Минимальный интервал между запросами на один номер
+}
+
+// PhoneVerificationService подтверждает номер телефона пользователя одноразовым кодом по SMS.
+// Лимиты считаются по записям в базе, поэтому действуют для всех экземпляров сервера.
+type PhoneVerificationService struct {
+	codeCollection *mongo.Collection
+	userCollection *mongo.Collection
+	provider       SMSProvider
+	config         PhoneVerificationConfig
+}
+
+func NewPhoneVerificationService(codeCollection, userCollection *mongo.Collection, provider SMSProvider, config PhoneVerificationConfig) *PhoneVerificationService {
+	return &PhoneVerificationService{
+		codeCollection: codeCollection,
+		userCollection: userCollection,
+		provider:       provider,
+		config:         config,
+	}
+}
+
+// NormalizePhone приводит номер к E.164; украинские номера допускаются в формате 0XXXXXXXXX и 380XXXXXXXXX
+func NormalizePhone(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case international:
+	case len(number) == 10 && strings.HasPrefix(number, "0"):
+		number = "38" + number
+	case len(number) == 9:
+		number = "380" + number
+	}
+
+	number = "+" + number
+	if !e164Pattern.MatchString(number) {
+		return "", ErrInvalidPhone
+	}
+	return number, nil
+}
+
+// RequestCode отправляет код подтверждения на номер. Возвращает время истечения кода.
+func (s *PhoneVerificationService) RequestCode(ctx context.Context, userID primitive.ObjectID, phone string) (time.Time, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := s.checkPhoneOwner(ctx, userID, phone); err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	if err := s.checkRateLimit(ctx, phone, now); err != nil {
+		return time.Time{}, err
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	record := models.PhoneVerificationCode{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Phone:     phone,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.CodeTTL),
+	}
+	record.CodeHash = hashPhoneCode(record.ID, code)
+
+	// Запись создается до отправки: неудачная отправка тоже расходует лимит номера
+	if _, err := s.codeCollection.InsertOne(ctx, record); err != nil {
+		return time.Time{}, err
+	}
+
+	// Новый код заменяет ранее выданные этому пользователю
+	_, err = s.codeCollection.UpdateMany(ctx, bson.M{
+		"user_id":     userID,
+		"_id":         bson.M{"$ne": record.ID},
+		"verified_at": bson.M{"$exists": false},
+		"expires_at":  bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{"expires_at": now}})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	text := fmt.Sprintf("Код підтвердження єМісто: %s. Дійсний %d хв. Нікому його не повідомляйте.",
+		code, int(s.config.CodeTTL/time.Minute))
+	if err := s.provider.Send(ctx, phone, text); err != nil {
+		log.Printf("SMS provider %s: error sending verification code: %v", s.provider.Name(), err)
+		return time.Time{}, ErrSMSUnavailable
+	}
+
+	return record.ExpiresAt, nil
+}
+
+// Verify проверяет код и сохраняет подтвержденный номер в профиле пользователя
+func (s *PhoneVerificationService) Verify(ctx context.Context, userID primitive.ObjectID, phone, code string) (string, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	var record models.PhoneVerificationCode
+	err = s.codeCollection.FindOne(ctx, bson.M{
+		"user_id":     userID,
+		"phone":       phone,
+		"verified_at": bson.M{"$exists": false},
+		"expires_at":  bson.M{"$gt": now},
+	}, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return "", ErrPhoneCodeInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if record.Attempts >= s.config.MaxAttempts {
+		return "", ErrPhoneCodeAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.CodeHash), []byte(hashPhoneCode(record.ID, strings.TrimSpace(code)))) != 1 {
+		// Попытка засчитывается атомарно: параллельные запросы не обходят лимит
+		result, err := s.codeCollection.UpdateOne(ctx,
+			bson.M{"_id": record.ID, "attempts": bson.M{"$lt": s.config.MaxAttempts}},
+			bson.M{"$inc": bson.M{"attempts": 1}},
+		)
+		if err != nil {
+			return "", err
+		}
+		if result.ModifiedCount == 0 {
+			return "", ErrPhoneCodeAttempts
+		}
+		return "", ErrPhoneCodeInvalid
+	}
+
+	// Код погашается один раз
+	result, err := s.codeCollection.UpdateOne(ctx,
+		bson.M{"_id": record.ID, "verified_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"verified_at": now}},
+	)
+	if err != nil {
+		return "", err
+	}
+	if result.ModifiedCount == 0 {
+		return "", ErrPhoneCodeInvalid
+	}
+
+	if err := s.checkPhoneOwner(ctx, userID, phone); err != nil {
+		return "", err
+	}
+
+	// Подтвержденный владелец забирает номер у неподтвержденных профилей (номер уникален)
+	_, err = s.userCollection.UpdateMany(ctx, bson.M{
+		"_id":               bson.M{"$ne": userID},
+		"phone":             phone,
+		"phone_verified_at": bson.M{"$exists": false},
+	}, bson.M{"$unset": bson.M{"phone": ""}, "$set": bson.M{"updated_at": now}})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"phone":             phone,
+			"phone_verified_at": now,
+			"updated_at":        now,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return phone, nil
+}
+
+// checkPhoneOwner - номер не подтвержден другим пользователем
+func (s *PhoneVerificationService) checkPhoneOwner(ctx context.Context, userID primitive.ObjectID, phone string) error {
+	count, err := s.userCollection.CountDocuments(ctx, bson.M{
+		"_id":               bson.M{"$ne": userID},
+		"phone":             phone,
+		"phone_verified_at": bson.M{"$exists": true},
+		"is_deleted":        bson.M{"$ne": true},
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPhoneTaken
+	}
+	return nil
+}
+
+// checkRateLimit - не чаще ResendInterval и не больше CodesPerHour кодов на номер за час
+func (s *PhoneVerificationService) checkRateLimit(ctx context.Context, phone string, now time.Time) error {
+	cursor, err := s.codeCollection.Find(ctx,
+		bson.M{"phone": phone, "created_at": bson.M{"$gt": now.Add(-time.Hour)}},
+		options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: 1}}).
+			SetProjection(bson.M{"created_at": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var recent []models.PhoneVerificationCode
+	if err := cursor.All(ctx, &recent); err != nil {
+		return err
+	}
+	if len(recent) == 0 {
+		return nil
+	}
+
+	last := recent[len(recent)-1].CreatedAt
+	if wait := last.Add(s.config.ResendInterval).Sub(now); wait > 0 {
+		return &PhoneRateLimitError{RetryAfter: wait}
+	}
+	if s.config.CodesPerHour > 0 && len(recent) >= s.config.CodesPerHour {
+		// Следующий запрос станет возможен, когда самый старый выйдет из окна
+		oldest := recent[len(recent)-s.config.CodesPerHour].CreatedAt
+		return &PhoneRateLimitError{RetryAfter: oldest.Add(time.Hour).Sub(now)}
+	}
+	return nil
+}
+
+func generatePhoneCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < phoneCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n.Int64()), nil
+}
+
+// hashPhoneCode - хеш кода с ID записи: одинаковые коды разных запросов дают разные хеши
+func hashPhoneCode(id primitive.ObjectID, code string) string {
+	sum := sha256.Sum256([]byte(id.Hex() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
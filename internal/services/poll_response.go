@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrAlreadyResponded - пользователь уже ответил в опросе без AllowMultiple
+var ErrAlreadyResponded = errors.New("already responded to poll")
+
+// ErrResponseNotFound - у пользователя нет ответа, который можно изменить
+var ErrResponseNotFound = errors.New("poll response not found")
+
+// PollResponseService хранит ответы опросов отдельно от опроса (poll_responses).
+// Голос - одна вставка и $inc счетчиков опроса: одновременные ответы не теряются,
+// а документ опроса не растет вместе с числом участников.
+type PollResponseService struct {
+	pollCollection     *mongo.Collection
+	responseCollection *mongo.Collection
+	results            *PollResultService
+}
+
+func NewPollResponseService(pollCollection, responseCollection *mongo.Collection, results *PollResultService) *PollResponseService {
+	return &PollResponseService{
+		pollCollection:     pollCollection,
+		responseCollection: responseCollection,
+		results:            results,
+	}
+}
+
+// Submit сохраняет ответ и увеличивает счетчики опроса и кэш итогов. Повторный ответ в опросе
+// без AllowMultiple отклоняет уникальный индекс (poll_id, user_id), а в опросе VerifiedVotersOnly -
+// еще и (poll_id, identity_hash) для другого аккаунта той же личности: ErrAlreadyResponded.
+func (s *PollResponseService) Submit(ctx context.Context, poll *models.Poll, response *models.PollResponse) error {
+	response.PollID = poll.ID
+	response.UniqueVote = !poll.AllowMultiple
+
+	if _, err := s.responseCollection.InsertOne(ctx, response); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyResponded
+		}
+		return err
+	}
+
+	// Ответ уже сохранен, ошибка счетчиков его не отменяет: расхождение исправит ночная сверка (CounterService)
+	_, err := s.pollCollection.UpdateOne(ctx, bson.M{"_id": poll.ID}, bson.M{
+		"$inc": bson.M{"total_responses": 1, "response_count": 1},
+	})
+	if err != nil {
+		log.Printf("Error incrementing response counters of poll %s: %v", poll.ID.Hex(), err)
+	}
+	if err := s.results.Record(ctx, poll, response); err != nil {
+		log.Printf("Error updating results of poll %s: %v", poll.ID.Hex(), err)
+	}
+	return nil
+}
+
+// Update заменяет ответы пользователя в опросе без AllowMultiple и переносит голоса в кэше итогов.
+// Прежние ответы возвращает та же атомарная операция, что их заменяет: при одновременных
+// изменениях каждое вычитает из итогов именно то, что заменило. Счетчики ответов не меняются.
+func (s *PollResponseService) Update(ctx context.Context, poll *models.Poll, userID primitive.ObjectID, answers []models.PollAnswer) (*models.PollResponse, error) {
+	now := time.Now()
+	var before models.PollResponse
+	err := s.responseCollection.FindOneAndUpdate(ctx,
+		bson.M{"poll_id": poll.ID, "user_id": userID, "unique_vote": true},
+		bson.M{"$set": bson.M{"answers": answers, "updated_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrResponseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	after := before
+	after.Answers = answers
+	after.UpdatedAt = now
+	// Ответ уже изменен. Сверка сравнивает только число ответов и перенос голосов не заметит,
+	// поэтому при ошибке кэш удаляется и соберется агрегацией при следующем чтении.
+	if err := s.results.Change(ctx, poll, &before, &after); err != nil {
+		log.Printf("Error updating results of poll %s: %v", poll.ID.Hex(), err)
+		if err := s.results.Invalidate(ctx, poll.ID); err != nil {
+			log.Printf("Error invalidating results of poll %s: %v", poll.ID.Hex(), err)
+		}
+	}
+	return &after, nil
+}
+
+// ForPoll - ответы опроса в порядке отправки
+func (s *PollResponseService) ForPoll(ctx context.Context, pollID primitive.ObjectID) ([]models.PollResponse, error) {
+	cursor, err := s.responseCollection.Find(ctx, bson.M{"poll_id": pollID},
+		options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	responses := []models.PollResponse{}
+	if err := cursor.All(ctx, &responses); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// RespondedPollIDs - опросы, в которых пользователь уже отвечал
+func (s *PollResponseService) RespondedPollIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	values, err := s.responseCollection.Distinct(ctx, "poll_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	pollIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if pollID, ok := value.(primitive.ObjectID); ok {
+			pollIDs = append(pollIDs, pollID)
+		}
+	}
+	return pollIDs, nil
+}
+
+// RemoveForPolls удаляет ответы и кэш итогов удаленных опросов
+func (s *PollResponseService) RemoveForPolls(ctx context.Context, pollIDs []primitive.ObjectID) error {
+	if len(pollIDs) == 0 {
+		return nil
+	}
+	if _, err := s.responseCollection.DeleteMany(ctx, bson.M{"poll_id": bson.M{"$in": pollIDs}}); err != nil {
+		return err
+	}
+	return s.results.RemoveForPolls(ctx, pollIDs)
+}
+
+// MigrateEmbedded переносит ответы из массива responses документов опросов в poll_responses
+// и возвращает число перенесенных ответов. Ответ сохраняет прежний ID, поэтому запуск,
+// прерванный между вставкой и очисткой массива, можно повторить.
+func (s *PollResponseService) MigrateEmbedded(ctx context.Context) (int, error) {
+	cursor, err := s.pollCollection.Find(ctx, bson.M{"responses": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"allow_multiple": 1, "responses": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	moved := 0
+	for cursor.Next(ctx) {
+		var poll struct {
+			ID            primitive.ObjectID `bson:"_id"`
+			AllowMultiple bool               `bson:"allow_multiple"`
+			Responses     []bson.Raw         `bson:"responses"`
+		}
+		if err := cursor.Decode(&poll); err != nil {
+			return moved, err
+		}
+
+		documents := make([]interface{}, 0, len(poll.Responses))
+		voted := make(map[primitive.ObjectID]bool)
+		for _, raw := range poll.Responses {
+			var response models.PollResponse
+			if err := bson.Unmarshal(raw, &response); err != nil {
+				return moved, err
+			}
+			// Во встроенном массиве ID ответа хранился в поле id
+			if id, ok := raw.Lookup("id").ObjectIDOK(); ok {
+				response.ID = id
+			} else {
+				response.ID = primitive.NewObjectID()
+			}
+			response.PollID = poll.ID
+
+			// Анонимные ответы раньше сохранялись без пользователя, а повторы, проскочившие
+			// мимо прежней проверки, в уникальный индекс не попадают
+			if !poll.AllowMultiple && !response.UserID.IsZero() && !voted[response.UserID] {
+				response.UniqueVote = true
+				voted[response.UserID] = true
+			}
+			documents = append(documents, response)
+		}
+
+		if len(documents) > 0 {
+			_, err := s.responseCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+			if err != nil && !mongo.IsDuplicateKeyError(err) {
+				return moved, err
+			}
+		}
+
+		_, err := s.pollCollection.UpdateOne(ctx, bson.M{"_id": poll.ID}, bson.M{
+			"$set":   bson.M{"total_responses": len(documents), "response_count": len(documents)},
+			"$unset": bson.M{"responses": ""},
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved += len(documents)
+	}
+	return moved, cursor.Err()
+}
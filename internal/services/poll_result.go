@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PollResultService ведет кэш итогов опросов (poll_results): каждый ответ увеличивает
+// счетчики через $inc, поэтому итоги большого опроса читаются одним документом.
+// Документ, которого нет (новый опрос, изменены вопросы), собирается агрегацией по poll_responses.
+type PollResultService struct {
+	responseCollection *mongo.Collection
+	resultCollection   *mongo.Collection
+}
+
+func NewPollResultService(responseCollection, resultCollection *mongo.Collection) *PollResultService {
+	return &PollResultService{
+		responseCollection: responseCollection,
+		resultCollection:   resultCollection,
+	}
+}
+
+// Record учитывает сохраненный ответ в кэше итогов
+func (s *PollResultService) Record(ctx context.Context, poll *models.Poll, response *models.PollResponse) error {
+	result, err := s.resultCollection.UpdateOne(ctx, bson.M{"_id": poll.ID}, bson.M{
+		"$inc": poll.TallyIncrements(response),
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Кэша еще нет: агрегация уже учитывает сохраненный ответ
+		_, err = s.Rebuild(ctx, poll)
+	}
+	return err
+}
+
+// Change переносит в кэше итогов голоса измененного ответа: одним $inc вычитает прежние
+// ответы и прибавляет новые, поэтому одновременные голоса и изменения не теряются
+func (s *PollResultService) Change(ctx context.Context, poll *models.Poll, before, after *models.PollResponse) error {
+	inc := poll.TallyChanges(before, after)
+	if len(inc) == 0 {
+		return nil
+	}
+	result, err := s.resultCollection.UpdateOne(ctx, bson.M{"_id": poll.ID}, bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Кэша еще нет: агрегация уже учитывает измененный ответ
+		_, err = s.Rebuild(ctx, poll)
+	}
+	return err
+}
+
+// Get возвращает кэш итогов, при необходимости собирая его заново
+func (s *PollResultService) Get(ctx context.Context, poll *models.Poll) (*models.PollTally, error) {
+	var tally models.PollTally
+	err := s.resultCollection.FindOne(ctx, bson.M{"_id": poll.ID}).Decode(&tally)
+	if err == mongo.ErrNoDocuments {
+		return s.Rebuild(ctx, poll)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tally, nil
+}
+
+// Rebuild собирает счетчики итогов агрегацией по ответам опроса и сохраняет кэш.
+// Ответ, сохраненный во время сборки, может не попасть в счетчики - расхождение исправит сверка (CounterService).
+func (s *PollResultService) Rebuild(ctx context.Context, poll *models.Poll) (*models.PollTally, error) {
+	total, err := s.responseCollection.CountDocuments(ctx, bson.M{"poll_id": poll.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.responseCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"poll_id": poll.ID}}},
+		{{Key: "$unwind", Value: "$answers"}},
+		{{Key: "$facet", Value: bson.M{
+			// Голоса за варианты и места вариантов в ранжировании
+			"options": bson.A{
+				bson.M{"$unwind": bson.M{"path": "$answers.option_ids", "includeArrayIndex": "position"}},
+				bson.M{"$group": bson.M{
+					"_id":         bson.M{"question": "$answers.question_id", "option": "$answers.option_ids"},
+					"count":       bson.M{"$sum": 1},
+					"rank_sum":    bson.M{"$sum": bson.M{"$add": bson.A{"$position", 1}}},
+					"first_place": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$position", 0}}, 1, 0}}},
+				}},
+			},
+			// Текст, оценки, да/нет и ранжирование: число ответов по значению
+			"values": bson.A{
+				bson.M{"$group": bson.M{
+					"_id": bson.M{
+						"question": "$answers.question_id",
+						"number":   "$answers.number_answer",
+						"bool":     "$answers.bool_answer",
+						"text":     bson.M{"$gt": bson.A{bson.M{"$strLenCP": bson.M{"$ifNull": bson.A{"$answers.text_answer", ""}}}, 0}},
+						"ranked":   bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$answers.option_ids", bson.A{}}}}, 0}},
+					},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var facets []struct {
+		Options []struct {
+			ID struct {
+				Question primitive.ObjectID `bson:"question"`
+				Option   primitive.ObjectID `bson:"option"`
+			} `bson:"_id"`
+			Count      int `bson:"count"`
+			RankSum    int `bson:"rank_sum"`
+			FirstPlace int `bson:"first_place"`
+		} `bson:"options"`
+		Values []struct {
+			ID struct {
+				Question primitive.ObjectID `bson:"question"`
+				Number   *int               `bson:"number"`
+				Bool     *bool              `bson:"bool"`
+				Text     bool               `bson:"text"`
+				Ranked   bool               `bson:"ranked"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		} `bson:"values"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	types := make(map[primitive.ObjectID]string, len(poll.Questions))
+	for _, question := range poll.Questions {
+		types[question.ID] = question.Type
+	}
+
+	now := time.Now()
+	tally := &models.PollTally{
+		PollID:         poll.ID,
+		TotalResponses: int(total),
+		Questions:      map[string]models.QuestionTally{},
+		RebuiltAt:      now,
+		UpdatedAt:      now,
+	}
+	if len(facets) > 0 {
+		for _, group := range facets[0].Options {
+			key, option := group.ID.Question.Hex(), group.ID.Option.Hex()
+			question := tally.Questions[key]
+			switch types[group.ID.Question] {
+			case models.QuestionTypeSingleChoice, models.QuestionTypeMultipleChoice:
+				question.Options = addTally(question.Options, option, group.Count)
+			case models.QuestionTypeRanking:
+				question.Ranked = addTally(question.Ranked, option, group.Count)
+				question.RankSum = addTally(question.RankSum, option, group.RankSum)
+				question.FirstPlace = addTally(question.FirstPlace, option, group.FirstPlace)
+			default:
+				continue
+			}
+			tally.Questions[key] = question
+		}
+
+		for _, group := range facets[0].Values {
+			key := group.ID.Question.Hex()
+			question := tally.Questions[key]
+			switch types[group.ID.Question] {
+			case models.QuestionTypeText:
+				if group.ID.Text {
+					question.Answers += group.Count
+				}
+			case models.QuestionTypeRating, models.QuestionTypeScale:
+				if group.ID.Number != nil {
+					question.Values = addTally(question.Values, strconv.Itoa(*group.ID.Number), group.Count)
+				}
+			case models.QuestionTypeYesNo:
+				if group.ID.Bool == nil {
+					continue
+				}
+				if *group.ID.Bool {
+					question.Yes += group.Count
+				} else {
+					question.No += group.Count
+				}
+			case models.QuestionTypeRanking:
+				if group.ID.Ranked {
+					question.Answers += group.Count
+				}
+			default:
+				continue
+			}
+			tally.Questions[key] = question
+		}
+	}
+
+	if _, err := s.resultCollection.ReplaceOne(ctx, bson.M{"_id": poll.ID}, tally, options.Replace().SetUpsert(true)); err != nil {
+		return nil, err
+	}
+	return tally, nil
+}
+
+// addTally прибавляет n к счетчику карты, создавая карту при первом значении
+func addTally(counts map[string]int, key string, n int) map[string]int {
+	if n == 0 {
+		return counts
+	}
+	if counts == nil {
+		counts = map[string]int{}
+	}
+	counts[key] += n
+	return counts
+}
+
+// TextAnswers - последние текстовые ответы на вопрос, новые первыми
+func (s *PollResultService) TextAnswers(ctx context.Context, pollID, questionID primitive.ObjectID, limit int64) ([]models.TextAnswer, error) {
+	cursor, err := s.responseCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"poll_id": pollID, "answers.question_id": questionID}}},
+		{{Key: "$sort", Value: bson.D{{Key: "submitted_at", Value: -1}}}},
+		{{Key: "$unwind", Value: "$answers"}},
+		{{Key: "$match", Value: bson.M{
+			"answers.question_id": questionID,
+			"answers.text_answer": bson.M{"$nin": bson.A{"", nil}},
+		}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "text": "$answers.text_answer", "created_at": "$created_at"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	answers := []models.TextAnswer{}
+	if err := cursor.All(ctx, &answers); err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// Invalidate удаляет кэш итогов (изменены вопросы опроса); он соберется при следующем чтении
+func (s *PollResultService) Invalidate(ctx context.Context, pollID primitive.ObjectID) error {
+	_, err := s.resultCollection.DeleteOne(ctx, bson.M{"_id": pollID})
+	return err
+}
+
+// RemoveForPolls удаляет кэш итогов удаленных опросов
+func (s *PollResultService) RemoveForPolls(ctx context.Context, pollIDs []primitive.ObjectID) error {
+	if len(pollIDs) == 0 {
+		return nil
+	}
+	_, err := s.resultCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": pollIDs}})
+	return err
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PollSummaryService поддерживает read-модель списка опросов (poll_summaries).
+// Списки читают только карточки без вопросов и итогов: их пересчитывает сама MongoDB
+// проекцией опроса, ответы (poll_responses) учитываются счетчиком total_responses.
+type PollSummaryService struct {
+	pollCollection    *mongo.Collection
+	summaryCollection *mongo.Collection
+}
+
+func NewPollSummaryService(pollCollection, summaryCollection *mongo.Collection) *PollSummaryService {
+	return &PollSummaryService{
+		pollCollection:    pollCollection,
+		summaryCollection: summaryCollection,
+	}
+}
+
+// pollSummaryProjection - карточка опроса; вопросы превращаются в счетчик
+func pollSummaryProjection(syncedAt time.Time) bson.D {
+	return bson.D{{Key: "$project", Value: bson.M{
+		"community_id":         1,
+		"creator_id":           1,
+		"title":                1,
+		"description":          bson.M{"$substrCP": bson.A{bson.M{"$ifNull": bson.A{"$description", ""}}, 0, models.PollSummaryDescriptionLength}},
+		"language":             bson.M{"$ifNull": bson.A{"$language", ""}},
+		"category":             1,
+		"status":               1,
+		"is_verified":          1,
+		"is_anonymous":         1,
+		"is_public":            1,
+		"allow_multiple":       1,
+		"verified_voters_only": 1,
+		"start_date":           1,
+		"end_date":             1,
+		"tags":                 bson.M{"$ifNull": bson.A{"$tags", bson.A{}}},
+		"question_count":       bson.M{"$size": bson.M{"$ifNull": bson.A{"$questions", bson.A{}}}},
+		"total_responses":      bson.M{"$ifNull": bson.A{"$total_responses", 0}},
+		"view_count":           1,
+		"share_count":          1,
+		"created_at":           1,
+		"updated_at":           1,
+		"published_at":         1,
+		"synced_at":            bson.M{"$literal": syncedAt},
+	}}}
+}
+
+// Sync пересчитывает карточку опроса после записи; удаленный опрос убирает карточку
+func (s *PollSummaryService) Sync(ctx context.Context, pollID primitive.ObjectID) error {
+	cursor, err := s.pollCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": pollID}}},
+		pollSummaryProjection(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var summaries []models.PollSummary
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return err
+	}
+	if len(summaries) == 0 {
+		return s.Remove(ctx, pollID)
+	}
+
+	_, err = s.summaryCollection.ReplaceOne(ctx, bson.M{"_id": pollID}, summaries[0], options.Replace().SetUpsert(true))
+	return err
+}
+
+// Remove удаляет карточку удаленного опроса
+func (s *PollSummaryService) Remove(ctx context.Context, pollID primitive.ObjectID) error {
+	_, err := s.summaryCollection.DeleteOne(ctx, bson.M{"_id": pollID})
+	return err
+}
+
+// RemoveMatching удаляет карточки по тому же фильтру, которым удалены опросы
+func (s *PollSummaryService) RemoveMatching(ctx context.Context, filter bson.M) error {
+	_, err := s.summaryCollection.DeleteMany(ctx, filter)
+	return err
+}
+
+// IncrementViews повторяет в карточке счетчик просмотров без полного пересчета
+func (s *PollSummaryService) IncrementViews(ctx context.Context, pollID primitive.ObjectID) error {
+	_, err := s.summaryCollection.UpdateOne(ctx, bson.M{"_id": pollID}, bson.M{"$inc": bson.M{"view_count": 1}})
+	return err
+}
+
+// List - страница карточек и общее количество по фильтру
+func (s *PollSummaryService) List(ctx context.Context, filter bson.M, sort bson.D, skip, limit int64) ([]models.PollSummary, int64, error) {
+	cursor, err := s.summaryCollection.Find(ctx, filter, options.Find().
+		SetSort(sort).
+		SetSkip(skip).
+		SetLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	summaries := []models.PollSummary{}
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.summaryCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return summaries, total, nil
+}
+
+// EnsureBackfilled перестраивает карточки, если их число разошлось с числом опросов
+// (первый запуск, опросы, записанные в обход обработчиков, восстановление из копии)
+func (s *PollSummaryService) EnsureBackfilled(ctx context.Context) (int64, error) {
+	polls, err := s.pollCollection.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	summaries, err := s.summaryCollection.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if polls == summaries {
+		return 0, nil
+	}
+	return s.Rebuild(ctx)
+}
+
+// Rebuild пересчитывает все карточки на стороне MongoDB ($merge) и удаляет карточки
+// опросов, которых больше нет. Возвращает количество карточек.
+func (s *PollSummaryService) Rebuild(ctx context.Context) (int64, error) {
+	// MongoDB хранит время с точностью до миллисекунд
+	syncedAt := time.Now().Truncate(time.Millisecond)
+	cursor, err := s.pollCollection.Aggregate(ctx, mongo.Pipeline{
+		pollSummaryProjection(syncedAt),
+		{{Key: "$merge", Value: bson.M{
+			"into":           s.summaryCollection.Name(),
+			"whenMatched":    "replace",
+			"whenNotMatched": "insert",
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	cursor.Close(ctx)
+
+	if _, err := s.summaryCollection.DeleteMany(ctx, bson.M{"synced_at": bson.M{"$lt": syncedAt}}); err != nil {
+		return 0, err
+	}
+	return s.summaryCollection.CountDocuments(ctx, bson.M{})
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Сторінка списку GET /polls (limit до 100, за замовчуванням 10)
+const pollListPageSize = 20
+
+// testConsultationPoll - типова міська консультація: повний опис, 12 питань по 5 варіантів,
+// накопичені результати й текстові відповіді
+func testConsultationPoll() models.Poll {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	poll := models.Poll{
+		ID:          primitive.NewObjectID(),
+		CommunityID: primitive.NewObjectID(),
+		CreatorID:   primitive.NewObjectID(),
+		Title:       "Благоустрій набережної та центрального парку",
+		Description: strings.Repeat("Опис проєкту благоустрою набережної. ", 54),
+		Language:    "uk",
+		Category:    "infrastructure",
+		Status:      models.PollStatusActive,
+		IsVerified:  true,
+		IsPublic:    true,
+		StartDate:   now.AddDate(0, 0, -7),
+		EndDate:     now.AddDate(0, 0, 21),
+		Tags:        []string{"парк", "набережна", "благоустрій"},
+		CreatedAt:   now.AddDate(0, 0, -8),
+		UpdatedAt:   now,
+	}
+
+	for q := 0; q < 12; q++ {
+		question := models.PollQuestion{
+			ID:         primitive.NewObjectID(),
+			Text:       "Яке рішення для цієї ділянки набережної ви підтримуєте найбільше?",
+			Type:       "single_choice",
+			IsRequired: true,
+		}
+		result := models.QuestionResult{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			QuestionType: question.Type,
+			TotalAnswers: 1500,
+		}
+		for o := 0; o < 5; o++ {
+			option := models.PollOption{ID: primitive.NewObjectID(), Text: "Варіант облаштування з лавками та освітленням"}
+			question.Options = append(question.Options, option)
+			result.OptionResults = append(result.OptionResults, models.OptionResult{
+				OptionID: option.ID, OptionText: option.Text, Count: 300, Percentage: 20,
+			})
+		}
+		if q%4 == 3 {
+			question.Type, question.Options = "text", nil
+			result.QuestionType, result.OptionResults = "text", nil
+			for a := 0; a < 30; a++ {
+				result.TextAnswers = append(result.TextAnswers, "Потрібні пандуси й більше тіні біля дитячого майданчика")
+			}
+		}
+		poll.Questions = append(poll.Questions, question)
+		poll.Results.QuestionResults = append(poll.Results.QuestionResults, result)
+	}
+	poll.TotalResponses = 1500
+	poll.ResponseCount = 1500
+	return poll
+}
+
+// testPollSummary будує картку так само, як pollSummaryProjection
+func testPollSummary(poll models.Poll) models.PollSummary {
+	description := []rune(poll.Description)
+	if len(description) > models.PollSummaryDescriptionLength {
+		description = description[:models.PollSummaryDescriptionLength]
+	}
+	return models.PollSummary{
+		ID:                 poll.ID,
+		CommunityID:        poll.CommunityID,
+		CreatorID:          poll.CreatorID,
+		Title:              poll.Title,
+		Description:        string(description),
+		Language:           poll.Language,
+		Category:           poll.Category,
+		Status:             poll.Status,
+		IsVerified:         poll.IsVerified,
+		IsAnonymous:        poll.IsAnonymous,
+		IsPublic:           poll.IsPublic,
+		AllowMultiple:      poll.AllowMultiple,
+		VerifiedVotersOnly: poll.VerifiedVotersOnly,
+		StartDate:          poll.StartDate,
+		EndDate:            poll.EndDate,
+		Tags:               poll.Tags,
+		QuestionCount:      len(poll.Questions),
+		TotalResponses:     poll.TotalResponses,
+		ViewCount:          poll.ViewCount,
+		ShareCount:         poll.ShareCount,
+		CreatedAt:          poll.CreatedAt,
+		UpdatedAt:          poll.UpdatedAt,
+		PublishedAt:        poll.PublishedAt,
+		SyncedAt:           poll.UpdatedAt,
+	}
+}
+
+// testPollListPages кодує сторінку списку з повних документів і з карток, як їх повертає MongoDB
+func testPollListPages(t testing.TB) (polls, summaries [][]byte) {
+	for i := 0; i < pollListPageSize; i++ {
+		poll := testConsultationPoll()
+		pollDoc, err := bson.Marshal(poll)
+		if err != nil {
+			t.Fatalf("marshal poll: %v", err)
+		}
+		summaryDoc, err := bson.Marshal(testPollSummary(poll))
+		if err != nil {
+			t.Fatalf("marshal summary: %v", err)
+		}
+		polls = append(polls, pollDoc)
+		summaries = append(summaries, summaryDoc)
+	}
+	return polls, summaries
+}
+
+func totalSize(docs [][]byte) int {
+	size := 0
+	for _, doc := range docs {
+		size += len(doc)
+	}
+	return size
+}
+
+// Проєкція має відповідати полям PollSummary: інакше картка втратить поле або потягне зайве
+func TestPollSummaryProjectionMatchesModel(t *testing.T) {
+	projection := pollSummaryProjection(time.Now())[0].Value.(bson.M)
+
+	summaryType := reflect.TypeOf(models.PollSummary{})
+	fields := make(map[string]bool, summaryType.NumField())
+	for i := 0; i < summaryType.NumField(); i++ {
+		name := strings.Split(summaryType.Field(i).Tag.Get("bson"), ",")[0]
+		if name == "_id" {
+			continue
+		}
+		fields[name] = true
+		if _, ok := projection[name]; !ok {
+			t.Errorf("projection is missing PollSummary field %q", name)
+		}
+	}
+	for name := range projection {
+		if !fields[name] {
+			t.Errorf("projection field %q is not part of PollSummary", name)
+		}
+	}
+}
+
+func TestPollSummaryPageSize(t *testing.T) {
+	polls, summaries := testPollListPages(t)
+	pollBytes, summaryBytes := totalSize(polls), totalSize(summaries)
+	t.Logf("page of %d: polls %d bytes, summaries %d bytes", pollListPageSize, pollBytes, summaryBytes)
+
+	// Картка без питань і результатів має бути щонайменше вдесятеро меншою за повний документ
+	if summaryBytes*10 > pollBytes {
+		t.Errorf("summary page is %d bytes, want under a tenth of the full page (%d bytes)", summaryBytes, pollBytes)
+	}
+}
+
+// BenchmarkPollList порівнює сторінку списку з повних опитувань і з карток poll_summaries:
+// декодування відповіді MongoDB і серіалізація JSON для клієнта
+func BenchmarkPollList(b *testing.B) {
+	polls, summaries := testPollListPages(b)
+
+	b.Run("polls", func(b *testing.B) {
+		b.ReportMetric(float64(totalSize(polls)), "page-bytes")
+		for i := 0; i < b.N; i++ {
+			page := make([]models.Poll, len(polls))
+			for j, doc := range polls {
+				if err := bson.Unmarshal(doc, &page[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if _, err := json.Marshal(page); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("summaries", func(b *testing.B) {
+		b.ReportMetric(float64(totalSize(summaries)), "page-bytes")
+		for i := 0; i < b.N; i++ {
+			page := make([]models.PollSummary, len(summaries))
+			for j, doc := range summaries {
+				if err := bson.Unmarshal(doc, &page[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if _, err := json.Marshal(page); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
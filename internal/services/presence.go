@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PresenceService отслеживает пользователей с открытым WebSocket-соединением (хаб сообщает
+// о подключении и отключении) и записывает last_seen_at при первом подключении и отключении
+// последнего соединения. Состояние хранится в памяти экземпляра сервера.
+type PresenceService struct {
+	userCollection *mongo.Collection
+
+	mu          sync.RWMutex
+	connections map[primitive.ObjectID]int // Пользователь -> число открытых соединений
+}
+
+func NewPresenceService(userCollection *mongo.Collection) *PresenceService {
+	return &PresenceService{
+		userCollection: userCollection,
+		connections:    make(map[primitive.ObjectID]int),
+	}
+}
+
+// Connected учитывает новое соединение пользователя
+func (s *PresenceService) Connected(userID primitive.ObjectID) {
+	s.mu.Lock()
+	s.connections[userID]++
+	first := s.connections[userID] == 1
+	s.mu.Unlock()
+
+	if first {
+		go s.storeLastSeen(userID, time.Now())
+	}
+}
+
+// Disconnected снимает соединение пользователя; после последнего пользователь офлайн
+func (s *PresenceService) Disconnected(userID primitive.ObjectID) {
+	s.mu.Lock()
+	s.connections[userID]--
+	last := s.connections[userID] <= 0
+	if last {
+		delete(s.connections, userID)
+	}
+	s.mu.Unlock()
+
+	if last {
+		go s.storeLastSeen(userID, time.Now())
+	}
+}
+
+// IsOnline - есть ли у пользователя открытое соединение
+func (s *PresenceService) IsOnline(userID primitive.ObjectID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connections[userID] > 0
+}
+
+func (s *PresenceService) storeLastSeen(userID primitive.ObjectID, at time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// $max: запись с более ранним временем, выполненная позже, не откатывает отметку
+	_, err := s.userCollection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$max": bson.M{"last_seen_at": at}},
+	)
+	if err != nil {
+		log.Printf("Error storing last seen for user %s: %v", userID.Hex(), err)
+	}
+}
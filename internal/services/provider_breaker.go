@@ -0,0 +1,164 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/database"
+)
+
+// Провайдер не настроен: автомат не участвует, состояние только для отчетов
+const ProviderDisabled = "disabled"
+
+// ProviderHealth - состояние внешнего провайдера доставки для /health/ready и админ-панели
+type ProviderHealth struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"` // closed, open, half_open, disabled
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // Когда будет пропущен пробный запрос
+}
+
+// Degraded - провайдер настроен и сейчас не принимает сообщения
+func (h ProviderHealth) Degraded() bool {
+	return h.State == database.CircuitOpen || h.State == database.CircuitHalfOpen
+}
+
+// ProviderHealthSource - сервис, который отчитывается о своих провайдерах
+type ProviderHealthSource interface {
+	ProviderHealth() []ProviderHealth
+}
+
+// CollectProviderHealth - состояние провайдеров всех сервисов одним списком
+func CollectProviderHealth(sources ...ProviderHealthSource) []ProviderHealth {
+	health := []ProviderHealth{}
+	for _, source := range sources {
+		health = append(health, source.ProviderHealth()...)
+	}
+	return health
+}
+
+// ProviderBreaker - автомат отключения внешнего провайдера (FCM, SMTP).
+// После threshold ошибок подряд провайдер считается недоступным на cooldown:
+// сообщения остаются в очереди, а вызывающие обработчики не получают ошибку.
+// После паузы пропускается один пробный запрос; его успех возвращает провайдер в работу.
+type ProviderBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	lastError           string
+	lastFailureAt       time.Time
+	lastSuccessAt       time.Time
+	openedAt            time.Time
+	probing             bool
+}
+
+func NewProviderBreaker(name string, threshold int, cooldown time.Duration) *ProviderBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &ProviderBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow - можно ли обратиться к провайдеру. В полуоткрытом состоянии занимает
+// единственный пробный запрос: его результат нужно передать в Success или Failure.
+func (b *ProviderBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// Available - провайдер примет запрос (без занятия пробного запроса)
+func (b *ProviderBreaker) Available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openedAt.IsZero() || (!b.probing && time.Since(b.openedAt) >= b.cooldown)
+}
+
+// Success замыкает автомат; true - провайдер восстановился после отключения
+func (b *ProviderBreaker) Success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recovered := !b.openedAt.IsZero()
+	b.consecutiveFailures = 0
+	b.lastSuccessAt = time.Now()
+	b.openedAt = time.Time{}
+	b.probing = false
+	return recovered
+}
+
+// Failure учитывает ошибку провайдера; true - автомат разомкнут этой ошибкой
+func (b *ProviderBreaker) Failure(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.consecutiveFailures++
+	b.lastFailureAt = now
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.probing {
+		// Пробный запрос не прошел: пауза начинается заново
+		b.openedAt = now
+		b.probing = false
+		return true
+	}
+	if b.openedAt.IsZero() && b.consecutiveFailures >= b.threshold {
+		b.openedAt = now
+		return true
+	}
+	return false
+}
+
+// Health - текущее состояние автомата
+func (b *ProviderBreaker) Health() ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := ProviderHealth{
+		Name:                b.name,
+		State:               database.CircuitClosed,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LastError:           b.lastError,
+		LastFailureAt:       optionalTime(b.lastFailureAt),
+		LastSuccessAt:       optionalTime(b.lastSuccessAt),
+	}
+	if !b.openedAt.IsZero() {
+		retryAt := b.openedAt.Add(b.cooldown)
+		health.State = database.CircuitOpen
+		if b.probing || !time.Now().Before(retryAt) {
+			health.State = database.CircuitHalfOpen
+		}
+		health.OpenedAt = optionalTime(b.openedAt)
+		health.RetryAt = &retryAt
+	}
+	return health
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Длина псевдонима в hex-символах (80 бит)
+const pseudonymLength = 20
+
+// Pseudonymizer - псевдонимизация идентификаторов пользователей в выгрузках (HMAC-SHA256 с ротируемой солью).
+// Пока соль активна, псевдонимы совпадают во всех выгрузках, что позволяет исследователям
+// связывать наборы данных, не раскрывая личности жителей.
+type Pseudonymizer struct {
+	saltCollection *mongo.Collection
+	rotationPeriod time.Duration
+
+	mu      sync.Mutex
+	current *models.ExportSalt
+}
+
+func NewPseudonymizer(saltCollection *mongo.Collection, rotationDays int) *Pseudonymizer {
+	if rotationDays <= 0 {
+		rotationDays = 90
+	}
+	return &Pseudonymizer{
+		saltCollection: saltCollection,
+		rotationPeriod: time.Duration(rotationDays) * 24 * time.Hour,
+	}
+}
+
+// Current возвращает активную соль, при истечении срока создает новую
+func (p *Pseudonymizer) Current(ctx context.Context) (*models.ExportSalt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.current != nil && now.Before(p.current.ExpiresAt) {
+		return p.current, nil
+	}
+
+	var salt models.ExportSalt
+	err := p.saltCollection.FindOne(ctx,
+		bson.M{"expires_at": bson.M{"$gt": now}},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&salt)
+	if err == nil {
+		p.current = &salt
+		return p.current, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	return p.rotateLocked(ctx, nil, "scheduled rotation")
+}
+
+// Rotate досрочно заменяет соль: псевдонимы в новых выгрузках не совпадут с прежними
+func (p *Pseudonymizer) Rotate(ctx context.Context, rotatedBy primitive.ObjectID, reason string) (*models.ExportSalt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Прежняя соль перестает действовать сразу
+	if _, err := p.saltCollection.UpdateMany(ctx,
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		bson.M{"$set": bson.M{"expires_at": time.Now()}},
+	); err != nil {
+		return nil, err
+	}
+
+	return p.rotateLocked(ctx, &rotatedBy, reason)
+}
+
+func (p *Pseudonymizer) rotateLocked(ctx context.Context, rotatedBy *primitive.ObjectID, reason string) (*models.ExportSalt, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	salt := models.ExportSalt{
+		Salt:      hex.EncodeToString(secret),
+		CreatedAt: now,
+		ExpiresAt: now.Add(p.rotationPeriod),
+		RotatedBy: rotatedBy,
+		Reason:    reason,
+	}
+
+	result, err := p.saltCollection.InsertOne(ctx, salt)
+	if err != nil {
+		return nil, err
+	}
+	salt.ID = result.InsertedID.(primitive.ObjectID)
+
+	p.current = &salt
+	return p.current, nil
+}
+
+// Pseudonym возвращает стабильный псевдоним идентификатора для данной соли
+func Pseudonym(salt *models.ExportSalt, id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(salt.Salt))
+	mac.Write([]byte(id.Hex()))
+	return "u_" + hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/config"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultQuietHours - тихие часы города из конфигурации. Неверный формат отключает их,
+// неизвестный часовой пояс заменяется локальным временем сервера.
+func defaultQuietHours(cfg *config.Config) (models.QuietHours, *time.Location) {
+	location, err := time.LoadLocation(cfg.QuietHoursTimezone)
+	if err != nil {
+		log.Printf("⚠️  Unknown QUIET_HOURS_TIMEZONE %q, using server local time", cfg.QuietHoursTimezone)
+		location = time.Local
+	}
+
+	quietHours := models.QuietHours{
+		Enabled: cfg.QuietHoursStart != "off",
+		Start:   cfg.QuietHoursStart,
+		End:     cfg.QuietHoursEnd,
+	}
+	if err := quietHours.Validate(); err != nil {
+		log.Printf("⚠️  Invalid quiet hours configuration, quiet hours are disabled: %v", err)
+		quietHours.Enabled = false
+	}
+	return quietHours, location
+}
+
+// DefaultQuietHours - тихие часы города (для пользователей без собственных настроек)
+func (ns *NotificationService) DefaultQuietHours() models.QuietHours {
+	quietHours := ns.quietHours
+	quietHours.Timezone = ns.quietLocation.String()
+	return quietHours
+}
+
+// pushHold - получатели, у которых сейчас тихие часы, и уведомления, push которых отложен
+type pushHold struct {
+	until map[primitive.ObjectID]time.Time // Получатель -> конец его тихих часов
+	held  map[int64][]primitive.ObjectID   // Конец тихих часов (unix) -> уведомления
+}
+
+// add откладывает push уведомления, если у получателя тихие часы
+func (h *pushHold) add(userID, notificationID primitive.ObjectID) bool {
+	if h == nil {
+		return false
+	}
+	until, ok := h.until[userID]
+	if !ok {
+		return false
+	}
+	key := until.Unix()
+	h.held[key] = append(h.held[key], notificationID)
+	return true
+}
+
+// quietHoursHold определяет получателей, у которых сейчас тихие часы. Собственные настройки
+// пользователя заменяют тихие часы города. Экстренное уведомление с overrideQuietHours
+// отправляется сразу.
+func (ns *NotificationService) quietHoursHold(ctx context.Context, userIDs []primitive.ObjectID, notificationType string, overrideQuietHours bool) *pushHold {
+	if len(userIDs) == 0 || (overrideQuietHours && notificationType == NotificationTypeEmergency) {
+		return nil
+	}
+
+	now := time.Now()
+	hold := &pushHold{
+		until: make(map[primitive.ObjectID]time.Time),
+		held:  make(map[int64][]primitive.ObjectID),
+	}
+
+	// Собственные тихие часы задает меньшинство, поэтому загружаем только их
+	custom := make(map[primitive.ObjectID]*models.QuietHours)
+	cursor, err := ns.userCollection.Find(ctx,
+		bson.M{
+			"_id":                                  bson.M{"$in": userIDs},
+			"notification_preferences.quiet_hours": bson.M{"$exists": true},
+		},
+		options.Find().SetProjection(bson.M{"notification_preferences.quiet_hours": 1}),
+	)
+	if err != nil {
+		log.Printf("Error loading quiet hours, applying city defaults: %v", err)
+	} else {
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			log.Printf("Error loading quiet hours, applying city defaults: %v", err)
+		}
+		for _, user := range users {
+			if user.NotificationPreferences != nil && user.NotificationPreferences.QuietHours != nil {
+				custom[user.ID] = user.NotificationPreferences.QuietHours
+			}
+		}
+	}
+
+	defaultUntil, defaultQuiet := ns.quietHours.Until(now, ns.quietLocation)
+	for _, userID := range userIDs {
+		if quietHours, ok := custom[userID]; ok {
+			if until, quiet := quietHours.Until(now, ns.quietLocation); quiet {
+				hold.until[userID] = until
+			}
+			continue
+		}
+		if defaultQuiet {
+			hold.until[userID] = defaultUntil
+		}
+	}
+
+	if len(hold.until) == 0 {
+		return nil
+	}
+	return hold
+}
+
+// holdPush ставит отложенные push в очередь повторной отправки со временем конца тихих часов:
+// StartPushRetryWorker отправит их, когда это время наступит
+func (ns *NotificationService) holdPush(ctx context.Context, hold *pushHold) {
+	if hold == nil {
+		return
+	}
+	for until, notificationIDs := range hold.held {
+		_, err := ns.notificationCollection.UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": notificationIDs}, "is_sent": false},
+			bson.M{"$set": bson.M{"push_queued_at": time.Unix(until, 0)}},
+		)
+		if err != nil {
+			log.Printf("Error holding push notifications for quiet hours: %v", err)
+		}
+	}
+}
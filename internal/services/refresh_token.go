@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrRefreshTokenInvalid - токен не найден, истек или отозван при выходе
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	// ErrRefreshTokenReused - предъявлен уже замененный токен; вся цепочка отозвана
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// RefreshTokenService выдает и ротирует refresh-токены, чтобы мобильные клиенты
+// могли работать с короткоживущими access-токенами без повторного входа.
+type RefreshTokenService struct {
+	refreshTokenCollection *mongo.Collection
+	ttl                    time.Duration
+}
+
+func NewRefreshTokenService(refreshTokenCollection *mongo.Collection, ttl time.Duration) *RefreshTokenService {
+	return &RefreshTokenService{
+		refreshTokenCollection: refreshTokenCollection,
+		ttl:                    ttl,
+	}
+}
+
+// Issue выдает первый токен новой цепочки (вход или регистрация); FamilyID токена - ID сессии
+func (s *RefreshTokenService) Issue(ctx context.Context, userID primitive.ObjectID, userAgent, ip string) (string, *models.RefreshToken, error) {
+	return s.insert(ctx, primitive.NewObjectID(), userID, primitive.NewObjectID(), userAgent, ip)
+}
+
+// Rotate заменяет действующий токен новым из той же цепочки.
+// Повторное предъявление замененного токена означает утечку - отзываются все токены цепочки.
+func (s *RefreshTokenService) Rotate(ctx context.Context, raw, userAgent, ip string) (string, *models.RefreshToken, error) {
+	now := time.Now()
+	hash := hashRefreshToken(raw)
+
+	var current models.RefreshToken
+	err := s.refreshTokenCollection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			s.revokeFamily(ctx, current.FamilyID)
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, ErrRefreshTokenInvalid
+	}
+	if !now.Before(current.ExpiresAt) {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	nextID := primitive.NewObjectID()
+
+	// Отзыв с условием revoked_at = null: из двух параллельных обновлений проходит одно
+	result, err := s.refreshTokenCollection.UpdateOne(ctx,
+		bson.M{"_id": current.ID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "replaced_by": nextID}},
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.ModifiedCount == 0 {
+		s.revokeFamily(ctx, current.FamilyID)
+		return "", nil, ErrRefreshTokenReused
+	}
+
+	return s.insert(ctx, nextID, current.UserID, current.FamilyID, userAgent, ip)
+}
+
+// Revoke отзывает цепочку предъявленного токена (выход на одном устройстве).
+// Возвращает найденный токен (владелец и цепочка); уже отозванный токен не считается ошибкой.
+func (s *RefreshTokenService) Revoke(ctx context.Context, raw string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.refreshTokenCollection.FindOne(ctx, bson.M{"token_hash": hashRefreshToken(raw)}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, s.RevokeFamily(ctx, token.FamilyID)
+}
+
+// RevokeFamily отзывает цепочку токенов (завершение сессии из списка сессий)
+func (s *RefreshTokenService) RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	_, err := s.refreshTokenCollection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
+
+// RevokeAll отзывает все токены пользователя (выход на всех устройствах, смена пароля)
+func (s *RefreshTokenService) RevokeAll(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.refreshTokenCollection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *RefreshTokenService) revokeFamily(ctx context.Context, familyID primitive.ObjectID) {
+	_, err := s.refreshTokenCollection.UpdateMany(ctx,
+		bson.M{"family_id": familyID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Error revoking refresh token family %s: %v", familyID.Hex(), err)
+		return
+	}
+	log.Printf("Refresh token reuse detected, family %s revoked", familyID.Hex())
+}
+
+// insert создает токен с заданным ID; клиенту отдается только raw, в базу - хеш
+func (s *RefreshTokenService) insert(ctx context.Context, id, userID, familyID primitive.ObjectID, userAgent, ip string) (string, *models.RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	token := models.RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(raw),
+		UserAgent: userAgent,
+		IP:        ip,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if _, err := s.refreshTokenCollection.InsertOne(ctx, token); err != nil {
+		return "", nil, err
+	}
+	return raw, &token, nil
+}
+
+// hashRefreshToken - в базе хранится только SHA-256 токена
+func hashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
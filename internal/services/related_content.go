@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Параметры подбора связанных материалов
+const (
+	relatedContentInterval   = time.Hour
+	relatedContentWindow     = 180 * 24 * time.Hour // Учитываются материалы за последние полгода
+	relatedContentMinScore   = 0.4                  // Одной общей категории недостаточно
+	relatedNearbyMeters      = 500.0
+	relatedTextMinSimilarity = 0.3
+	// Слова и теги, встречающиеся у большего числа материалов, не используются для поиска пар:
+	// они слишком общие и делают подбор квадратичным
+	relatedMaxBucketSize = 200
+)
+
+// Сетка для поиска соседей: ячейка ~500 м по широте
+const relatedGridDegrees = relatedNearbyMeters / 111320.0
+
+// relatedSource - модуль, материалы которого участвуют в подборе
+type relatedSource struct {
+	module     string
+	collection *mongo.Collection
+	filter     func(now time.Time) bson.M // Опубликованные материалы
+}
+
+// relatedDocument - поля материала, нужные для подбора
+type relatedDocument struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	CommunityID primitive.ObjectID `bson:"community_id,omitempty"`
+	Slug        string             `bson:"slug,omitempty"`
+	Title       string             `bson:"title"`
+	Category    string             `bson:"category"`
+	Status      string             `bson:"status"`
+	Tags        []string           `bson:"tags,omitempty"`
+	Location    models.Location    `bson:"location"`
+	CreatedAt   time.Time          `bson:"created_at"`
+
+	module string
+	stems  []string
+}
+
+// hasLocation - у объявлений координаты необязательны
+func (d *relatedDocument) hasLocation() bool {
+	return len(d.Location.Coordinates) >= 2 && (d.Location.Coordinates[0] != 0 || d.Location.Coordinates[1] != 0)
+}
+
+// gridCell - ячейка сетки, в которой находится материал
+func (d *relatedDocument) gridCell() (int, int) {
+	return int(math.Floor(d.Location.Coordinates[1] / relatedGridDegrees)),
+		int(math.Floor(d.Location.Coordinates[0] / relatedGridDegrees))
+}
+
+// RelatedContentService фоново подбирает для проблем, петиций и объявлений связанные материалы
+// (общие теги, рядом на карте, та же категория, похожий заголовок) и хранит их в related_content,
+// чтобы карточка материала отдавалась с related[] без дополнительных запросов приложения.
+type RelatedContentService struct {
+	collection *mongo.Collection
+	sources    []relatedSource
+}
+
+func NewRelatedContentService(collection, issueCollection, petitionCollection, announcementCollection *mongo.Collection, moduleEnabled func(module string) bool) *RelatedContentService {
+	sources := []relatedSource{
+		{
+			module:     models.ModuleCityIssues,
+			collection: issueCollection,
+			filter: func(now time.Time) bson.M {
+				return bson.M{
+					"is_public": true,
+					"status":    bson.M{"$nin": []string{models.IssueStatusRejected, models.IssueStatusDuplicate}},
+				}
+			},
+		},
+		{
+			module:     models.ModulePetitions,
+			collection: petitionCollection,
+			filter: func(now time.Time) bson.M {
+				return bson.M{"status": bson.M{"$ne": models.PetitionStatusDraft}}
+			},
+		},
+		{
+			module:     models.ModuleAnnouncements,
+			collection: announcementCollection,
+			filter: func(now time.Time) bson.M {
+				return bson.M{
+					"status":      "approved",
+					"is_verified": true,
+					"is_active":   true,
+					"is_blocked":  bson.M{"$ne": true},
+					"expires_at":  bson.M{"$gt": now},
+				}
+			},
+		},
+	}
+
+	service := &RelatedContentService{collection: collection}
+	for _, source := range sources {
+		if moduleEnabled(source.module) {
+			service.sources = append(service.sources, source)
+		}
+	}
+	return service
+}
+
+// StartWorker пересчитывает подборки раз в час
+func (s *RelatedContentService) StartWorker() {
+	ticker := time.NewTicker(relatedContentInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(); err != nil {
+			log.Printf("Error computing related content: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// RunOnce пересчитывает подборки всех опубликованных материалов. Подборки снятых
+// с публикации и устаревших материалов удаляются.
+func (s *RelatedContentService) RunOnce() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	documents, err := s.loadDocuments(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	// Подбор идет внутри громады
+	byCommunity := make(map[primitive.ObjectID][]*relatedDocument)
+	for _, doc := range documents {
+		byCommunity[doc.CommunityID] = append(byCommunity[doc.CommunityID], doc)
+	}
+
+	var writes []mongo.WriteModel
+	for communityID, docs := range byCommunity {
+		related := relatedForCommunity(docs)
+		for i, doc := range docs {
+			writes = append(writes, mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"module": doc.module, "item_id": doc.ID}).
+				SetReplacement(models.RelatedContent{
+					Module:      doc.module,
+					ItemID:      doc.ID,
+					CommunityID: communityID,
+					Items:       related[i],
+					ComputedAt:  now,
+				}).
+				SetUpsert(true))
+		}
+	}
+
+	for start := 0; start < len(writes); start += 500 {
+		end := start + 500
+		if end > len(writes) {
+			end = len(writes)
+		}
+		if _, err := s.collection.BulkWrite(ctx, writes[start:end], options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("save related content: %w", err)
+		}
+	}
+
+	if _, err := s.collection.DeleteMany(ctx, bson.M{"computed_at": bson.M{"$lt": now}}); err != nil {
+		return fmt.Errorf("remove stale related content: %w", err)
+	}
+
+	log.Printf("Related content computed for %d items", len(documents))
+	return nil
+}
+
+// loadDocuments загружает опубликованные материалы за relatedContentWindow
+func (s *RelatedContentService) loadDocuments(ctx context.Context, now time.Time) ([]*relatedDocument, error) {
+	var documents []*relatedDocument
+	projection := bson.M{
+		"community_id": 1, "slug": 1, "title": 1, "category": 1,
+		"status": 1, "tags": 1, "location": 1, "created_at": 1,
+	}
+
+	for _, source := range s.sources {
+		filter := source.filter(now)
+		filter["created_at"] = bson.M{"$gte": now.Add(-relatedContentWindow)}
+
+		cursor, err := source.collection.Find(ctx, filter, options.Find().SetProjection(projection))
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", source.module, err)
+		}
+		var docs []relatedDocument
+		err = cursor.All(ctx, &docs)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", source.module, err)
+		}
+
+		for i := range docs {
+			docs[i].module = source.module
+			docs[i].stems = NormalizeCrossLanguage(docs[i].Title)
+			documents = append(documents, &docs[i])
+		}
+	}
+	return documents, nil
+}
+
+// relatedForCommunity подбирает связанные материалы для каждого материала громады.
+// Кандидаты - материалы с общей основой слова заголовка, общим тегом или в соседней ячейке сетки.
+func relatedForCommunity(docs []*relatedDocument) [][]models.RelatedItem {
+	index := make(map[string][]int)
+	for i, doc := range docs {
+		for _, key := range relatedKeys(doc) {
+			index[key] = append(index[key], i)
+		}
+	}
+
+	result := make([][]models.RelatedItem, len(docs))
+	for i, doc := range docs {
+		candidates := make(map[int]bool)
+		for _, key := range relatedLookupKeys(doc) {
+			bucket := index[key]
+			if len(bucket) > relatedMaxBucketSize {
+				continue
+			}
+			for _, j := range bucket {
+				if j != i {
+					candidates[j] = true
+				}
+			}
+		}
+
+		items := []models.RelatedItem{}
+		for j := range candidates {
+			if item, ok := relatedScore(doc, docs[j]); ok {
+				items = append(items, item)
+			}
+		}
+		sort.Slice(items, func(a, b int) bool {
+			if items[a].Score != items[b].Score {
+				return items[a].Score > items[b].Score
+			}
+			return items[a].CreatedAt.After(items[b].CreatedAt)
+		})
+		if len(items) > models.MaxRelatedItems {
+			items = items[:models.MaxRelatedItems]
+		}
+		result[i] = items
+	}
+	return result
+}
+
+// relatedKeys - ключи, под которыми материал попадает в индекс кандидатов
+func relatedKeys(doc *relatedDocument) []string {
+	var keys []string
+	for _, stem := range doc.stems {
+		keys = append(keys, "s:"+stem)
+	}
+	for _, tag := range doc.Tags {
+		keys = append(keys, "t:"+tag)
+	}
+	if doc.hasLocation() {
+		lat, lon := doc.gridCell()
+		keys = append(keys, fmt.Sprintf("g:%d:%d", lat, lon))
+	}
+	return keys
+}
+
+// relatedLookupKeys - ключи для поиска кандидатов: как relatedKeys, но с соседними ячейками сетки
+func relatedLookupKeys(doc *relatedDocument) []string {
+	var keys []string
+	for _, stem := range doc.stems {
+		keys = append(keys, "s:"+stem)
+	}
+	for _, tag := range doc.Tags {
+		keys = append(keys, "t:"+tag)
+	}
+	if doc.hasLocation() {
+		lat, lon := doc.gridCell()
+		for dLat := -1; dLat <= 1; dLat++ {
+			for dLon := -1; dLon <= 1; dLon++ {
+				keys = append(keys, fmt.Sprintf("g:%d:%d", lat+dLat, lon+dLon))
+			}
+		}
+	}
+	return keys
+}
+
+// relatedScore оценивает связь двух материалов от 0 до 1.5:
+// категория 0.3, общие теги до 0.4, близость до 0.3, похожий заголовок до 0.5
+func relatedScore(doc, other *relatedDocument) (models.RelatedItem, bool) {
+	item := models.RelatedItem{
+		Module:    other.module,
+		ID:        other.ID,
+		Slug:      other.Slug,
+		Title:     other.Title,
+		Category:  other.Category,
+		Status:    other.Status,
+		Reasons:   []string{},
+		CreatedAt: other.CreatedAt,
+	}
+
+	if doc.Category != "" && doc.Category == other.Category {
+		item.Score += 0.3
+		item.Reasons = append(item.Reasons, models.RelatedReasonCategory)
+	}
+
+	shared := 0
+	for _, tag := range doc.Tags {
+		for _, otherTag := range other.Tags {
+			if tag == otherTag {
+				shared++
+				break
+			}
+		}
+	}
+	if shared > 0 {
+		item.Score += math.Min(0.4, 0.2*float64(shared))
+		item.Reasons = append(item.Reasons, models.RelatedReasonTags)
+	}
+
+	if doc.hasLocation() && other.hasLocation() {
+		distance := models.DistanceToPolyline(doc.Location, []models.Location{other.Location})
+		if distance >= 0 && distance <= relatedNearbyMeters {
+			rounded := math.Round(distance)
+			item.DistanceMeters = &rounded
+			item.Score += 0.3 * (1 - distance/relatedNearbyMeters)
+			item.Reasons = append(item.Reasons, models.RelatedReasonNearby)
+		}
+	}
+
+	if similarity := TextSimilarity(doc.stems, other.stems); similarity >= relatedTextMinSimilarity {
+		item.Score += 0.5 * similarity
+		item.Reasons = append(item.Reasons, models.RelatedReasonText)
+	}
+
+	item.Score = math.Round(item.Score*100) / 100
+	return item, item.Score >= relatedContentMinScore
+}
+
+// For возвращает подборку для карточки материала; nil - подборка еще не рассчитана
+func (s *RelatedContentService) For(ctx context.Context, module string, itemID primitive.ObjectID) []models.RelatedItem {
+	var related models.RelatedContent
+	err := s.collection.FindOne(ctx, bson.M{"module": module, "item_id": itemID},
+		options.FindOne().SetProjection(bson.M{"items": 1})).Decode(&related)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Error fetching related content for %s %s: %v", module, itemID.Hex(), err)
+		}
+		return nil
+	}
+	return related.Items
+}
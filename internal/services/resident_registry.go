@@ -0,0 +1,141 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Результаты проверки карты жителя в реестре
+const (
+	ResidentRegistryMatched    = "matched"     // Карта действительна и принадлежит жителю с этой фамилией
+	ResidentRegistryNotMatched = "not_matched" // Карта не найдена или фамилия не совпадает
+	ResidentRegistryManual     = "manual"      // Реестр не подключен - нужна проверка администратором
+	ResidentRegistryError      = "error"       // Реестр недоступен - карту проверит администратор
+)
+
+// ResidentCardCheck - данные для проверки карты жителя
+type ResidentCardCheck struct {
+	CardNumber string
+	LastName   string
+}
+
+// ResidentRegistryResult - ответ реестра
+type ResidentRegistryResult struct {
+	Status     string
+	Reference  string     // Идентификатор записи в реестре (для разбора обращений)
+	ValidUntil *time.Time // Срок действия карты, если реестр его сообщает
+}
+
+// ResidentRegistry - адаптер реестра жителей громады. Реализации подключаются в main по конфигурации.
+type ResidentRegistry interface {
+	Name() string
+	Verify(ctx context.Context, check ResidentCardCheck) (ResidentRegistryResult, error)
+}
+
+// ManualResidentRegistry используется без подключенного реестра: все карты проверяет администратор
+type ManualResidentRegistry struct{}
+
+func (ManualResidentRegistry) Name() string {
+	return "manual"
+}
+
+func (ManualResidentRegistry) Verify(ctx context.Context, check ResidentCardCheck) (ResidentRegistryResult, error) {
+	return ResidentRegistryResult{Status: ResidentRegistryManual}, nil
+}
+
+// HTTPResidentRegistry проверяет карту через HTTP API реестра:
+// POST url с JSON {"card_number", "last_name"} и заголовком Authorization: Bearer token.
+// Ответ 200 - {"valid": bool, "reference": "...", "valid_until": RFC3339}, 404 - карта не найдена.
+type HTTPResidentRegistry struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewHTTPResidentRegistry(url, token string) *HTTPResidentRegistry {
+	return &HTTPResidentRegistry{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (r *HTTPResidentRegistry) Name() string {
+	return "http"
+}
+
+func (r *HTTPResidentRegistry) Verify(ctx context.Context, check ResidentCardCheck) (ResidentRegistryResult, error) {
+	body, err := json.Marshal(map[string]string{
+		"card_number": check.CardNumber,
+		"last_name":   check.LastName,
+	})
+	if err != nil {
+		return ResidentRegistryResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return ResidentRegistryResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return ResidentRegistryResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ResidentRegistryResult{Status: ResidentRegistryNotMatched}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return ResidentRegistryResult{}, fmt.Errorf("resident registry returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	var payload struct {
+		Valid      bool       `json:"valid"`
+		Reference  string     `json:"reference"`
+		ValidUntil *time.Time `json:"valid_until"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&payload); err != nil {
+		return ResidentRegistryResult{}, fmt.Errorf("invalid resident registry response: %w", err)
+	}
+
+	result := ResidentRegistryResult{
+		Status:     ResidentRegistryNotMatched,
+		Reference:  payload.Reference,
+		ValidUntil: payload.ValidUntil,
+	}
+	if payload.Valid {
+		result.Status = ResidentRegistryMatched
+	}
+	return result, nil
+}
+
+// ResidentCardHash - HMAC нормализованного номера карты. По нему одну карту нельзя
+// привязать к двум аккаунтам, а сам номер в базе не хранится.
+func ResidentCardHash(number, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IdentityHash - HMAC нормализованного РНОКПП, подтвержденного Дией или BankID.
+// Одинаков для всех аккаунтов одного человека; сам номер в базе не хранится.
+func IdentityHash(taxNumber, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("identity:" + taxNumber))
+	return hex.EncodeToString(mac.Sum(nil))
+}
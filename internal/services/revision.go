@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+	"unicode"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Выше этого размера матрицы LCS поле показывается как полная замена текста
+const maxDiffCells = 4_000_000
+
+// ErrRevisionConflict - версию уже сохранил параллельный запрос редактирования
+var ErrRevisionConflict = errors.New("revision already exists")
+
+// RevisionService хранит историю текста опубликованных петиций и объявлений,
+// чтобы текст не менялся незаметно для уже подписавших и откликнувшихся.
+type RevisionService struct {
+	revisionCollection *mongo.Collection
+}
+
+func NewRevisionService(revisionCollection *mongo.Collection) *RevisionService {
+	return &RevisionService{
+		revisionCollection: revisionCollection,
+	}
+}
+
+// ChangedFields возвращает отсортированный список полей, значения которых отличаются
+func ChangedFields(current, updated map[string]string) []string {
+	var changed []string
+	for field, value := range updated {
+		if current[field] != value {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Record сохраняет текущий текст как версию version перед заменой новым.
+// Уникальный индекс (entity_type, entity_id, version) не дает двум параллельным правкам записать одну версию.
+func (s *RevisionService) Record(ctx context.Context, revision models.ContentRevision) error {
+	if revision.ReplacedAt.IsZero() {
+		revision.ReplacedAt = time.Now()
+	}
+
+	if _, err := s.revisionCollection.InsertOne(ctx, revision); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrRevisionConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// List возвращает версии контента по возрастанию номера
+func (s *RevisionService) List(ctx context.Context, entityType string, entityID primitive.ObjectID) ([]models.ContentRevision, error) {
+	cursor, err := s.revisionCollection.Find(ctx,
+		bson.M{"entity_type": entityType, "entity_id": entityID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.ContentRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// DiffFields сравнивает две версии по изменившимся полям
+func DiffFields(from, to map[string]string) []models.FieldDiff {
+	diffs := []models.FieldDiff{}
+	for _, field := range ChangedFields(from, to) {
+		diffs = append(diffs, models.FieldDiff{
+			Field: field,
+			Ops:   DiffText(from[field], to[field]),
+		})
+	}
+	return diffs
+}
+
+// DiffText - пословное сравнение текстов (LCS). Пробелы остаются при словах,
+// поэтому склеивание фрагментов equal+insert дает новый текст, equal+delete - старый.
+func DiffText(from, to string) []models.DiffOp {
+	a, b := diffTokens(from), diffTokens(to)
+
+	if len(a)*len(b) > maxDiffCells {
+		return mergeDiffOps([]models.DiffOp{
+			{Op: models.DiffDelete, Text: from},
+			{Op: models.DiffInsert, Text: to},
+		})
+	}
+
+	// lcs[i][j] - длина общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []models.DiffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, models.DiffOp{Op: models.DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, models.DiffOp{Op: models.DiffDelete, Text: a[i]})
+			i++
+		default:
+			ops = append(ops, models.DiffOp{Op: models.DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, models.DiffOp{Op: models.DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, models.DiffOp{Op: models.DiffInsert, Text: b[j]})
+	}
+
+	return mergeDiffOps(ops)
+}
+
+// diffTokens делит текст на слова вместе с последующими пробелами
+func diffTokens(text string) []string {
+	var tokens []string
+	start := 0
+	inSpace := false
+	for idx, r := range text {
+		space := unicode.IsSpace(r)
+		if !space && inSpace {
+			tokens = append(tokens, text[start:idx])
+			start = idx
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// mergeDiffOps склеивает соседние фрагменты одного типа и убирает пустые
+func mergeDiffOps(ops []models.DiffOp) []models.DiffOp {
+	merged := []models.DiffOp{}
+	for _, op := range ops {
+		if op.Text == "" {
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].Op == op.Op {
+			merged[n-1].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+	return merged
+}
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Пароль всех демо-аккаунтов песочницы
+const SandboxPassword = "sandbox2026"
+
+// Не чаще одного сброса песочницы за это время: участники хакатона работают с общими данными
+const sandboxResetCooldown = time.Minute
+
+var ErrSandboxResetTooSoon = errors.New("sandbox was reset less than a minute ago")
+
+// SandboxAccount - демо-аккаунт песочницы
+type SandboxAccount struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// SandboxSummary - итог сброса песочницы
+type SandboxSummary struct {
+	ResetAt  time.Time        `json:"reset_at"`
+	Accounts []SandboxAccount `json:"accounts"`
+	Seeded   map[string]int   `json:"seeded"` // Сколько документов создано в каждой коллекции
+}
+
+// SandboxService очищает базу песочницы и заполняет ее демо-данными: пользователи всех ролей,
+// объявления, события, петиции, опрос, проблемы города и группа с сообщениями.
+// Работает только при SANDBOX_MODE: данные удаляются без резервной копии.
+type SandboxService struct {
+	db            *mongo.Database
+	communities   *CommunityService
+	taxonomies    *TaxonomyService
+	pollSummaries *PollSummaryService
+	mu            sync.Mutex
+	lastReset     time.Time
+}
+
+func NewSandboxService(db *mongo.Database, communities *CommunityService, taxonomies *TaxonomyService, pollSummaries *PollSummaryService) *SandboxService {
+	return &SandboxService{
+		db:            db,
+		communities:   communities,
+		taxonomies:    taxonomies,
+		pollSummaries: pollSummaries,
+	}
+}
+
+// Reset удаляет все документы (индексы остаются) и заново создает демо-данные
+func (s *SandboxService) Reset(ctx context.Context) (*SandboxSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastReset) < sandboxResetCooldown {
+		return nil, ErrSandboxResetTooSoon
+	}
+
+	names, err := s.db.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+		if _, err := s.db.Collection(name).DeleteMany(ctx, bson.M{}); err != nil {
+			return nil, fmt.Errorf("ошибка очистки %s: %w", name, err)
+		}
+	}
+
+	if err := s.communities.EnsureDefault(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.taxonomies.EnsureDefaults(ctx); err != nil {
+		return nil, err
+	}
+	community, ok := s.communities.GetByCode(s.communities.DefaultCode())
+	if !ok {
+		return nil, errors.New("default community is not available")
+	}
+
+	summary, err := s.seed(ctx, community.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.pollSummaries.Rebuild(ctx); err != nil {
+		return nil, err
+	}
+
+	s.lastReset = summary.ResetAt
+	return summary, nil
+}
+
+// sandboxLocation - точка в Новой Каховке со смещением, чтобы демо-объекты не совпадали на карте
+func sandboxLocation(offset float64, address string) models.Location {
+	return models.Location{
+		Type:        "Point",
+		Coordinates: []float64{33.3600 + offset, 46.7550 + offset/2},
+		Address:     address,
+		City:        "Нова Каховка",
+	}
+}
+
+func (s *SandboxService) seed(ctx context.Context, communityID primitive.ObjectID) (*SandboxSummary, error) {
+	now := time.Now()
+	summary := &SandboxSummary{ResetAt: now, Seeded: map[string]int{}}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(SandboxPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	insert := func(collection string, documents []interface{}) error {
+		if _, err := s.db.Collection(collection).InsertMany(ctx, documents); err != nil {
+			return fmt.Errorf("ошибка заполнения %s: %w", collection, err)
+		}
+		summary.Seeded[collection] = len(documents)
+		return nil
+	}
+
+	// Пользователи: по одному на каждую роль и два жителя
+	groupID := primitive.NewObjectID()
+	type account struct {
+		email, first, last string
+		role               models.UserRole
+		interests          []string
+	}
+	accounts := []account{
+		{"admin@sandbox.ecity.local", "Ірина", "Адміністратор", models.RoleAdmin, []string{"governance"}},
+		{"moderator@sandbox.ecity.local", "Андрій", "Модератор", models.RoleModerator, []string{"community"}},
+		{"olena@sandbox.ecity.local", "Олена", "Коваленко", models.RoleUser, []string{"culture", "environment"}},
+		{"taras@sandbox.ecity.local", "Тарас", "Шевчук", models.RoleUser, []string{"transport", "sport"}},
+	}
+	users := make([]models.User, len(accounts))
+	userDocs := make([]interface{}, len(accounts))
+	for i, a := range accounts {
+		users[i] = models.User{
+			ID:           primitive.NewObjectID(),
+			Email:        a.email,
+			PasswordHash: string(passwordHash),
+			FirstName:    a.first,
+			LastName:     a.last,
+			Interests:    a.interests,
+			Role:         string(a.role),
+			IsModerator:  a.role != models.RoleUser,
+			IsVerified:   true,
+			Groups:       []primitive.ObjectID{groupID},
+			CommunityIDs: []primitive.ObjectID{communityID},
+			Status:       models.UserStatus{UpdatedAt: now},
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		userDocs[i] = users[i]
+		summary.Accounts = append(summary.Accounts, SandboxAccount{Email: a.email, Password: SandboxPassword, Role: string(a.role)})
+	}
+	if err := insert("users", userDocs); err != nil {
+		return nil, err
+	}
+	admin, moderator, olena, taras := users[0], users[1], users[2], users[3]
+	residents := []primitive.ObjectID{olena.ID, taras.ID}
+
+	// Объявления
+	announcements := []interface{}{}
+	for i, a := range []struct{ title, description, category string }{
+		{"Шукаю репетитора з математики", "Потрібен репетитор для учня 9 класу, двічі на тиждень після уроків.", "help"},
+		{"Ремонт побутової техніки", "Ремонтую пральні машини та холодильники, виїзд по місту протягом дня.", "services"},
+	} {
+		id := primitive.NewObjectID()
+		approvedAt := now
+		announcements = append(announcements, models.Announcement{
+			ID:          id,
+			Slug:        buildSlug(a.title, "announcement", id, false),
+			CommunityID: communityID,
+			AuthorID:    residents[i],
+			Title:       a.title,
+			Description: a.description,
+			Language:    DetectLanguage(a.title, a.description),
+			Category:    a.category,
+			Location:    sandboxLocation(float64(i)*0.004, "вул. Соборності, 1"),
+			Address:     "вул. Соборності, 1",
+			Employment:  models.EmploymentOnce,
+			ContactInfo: []models.ContactInfo{},
+			MediaFiles:  []string{},
+			IsActive:    true,
+			IsModerated: true,
+			Status:      "approved",
+			IsVerified:  true,
+			ApprovedAt:  &approvedAt,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			ExpiresAt:   now.AddDate(0, 1, 0),
+		})
+	}
+	if err := insert("announcements", announcements); err != nil {
+		return nil, err
+	}
+
+	// События: предстоящее с участниками и онлайн-встреча
+	events := []interface{}{}
+	for i, e := range []struct {
+		title, description, category string
+		days                         int
+		online                       bool
+		attendees                    []primitive.ObjectID
+	}{
+		{"Толока в парку Шевченка", "Прибираємо парк разом: рукавички та мішки видаємо на місці.", models.EventCategorySocial, 7, false, residents},
+		{"Онлайн-зустріч з депутатами", "Обговорення бюджету громади на наступний рік, питання можна надіслати заздалегідь.", models.EventCategoryEducational, 14, true, []primitive.ObjectID{olena.ID}},
+	} {
+		id := primitive.NewObjectID()
+		startDate := now.AddDate(0, 0, e.days)
+		publishedAt := now
+		event := models.Event{
+			ID:            id,
+			Slug:          buildSlug(e.title, "event", id, false),
+			CommunityID:   communityID,
+			OrganizerID:   moderator.ID,
+			Title:         e.title,
+			Description:   e.description,
+			Language:      DetectLanguage(e.title, e.description),
+			Category:      e.category,
+			StartDate:     startDate,
+			Location:      sandboxLocation(0.01+float64(i)*0.004, "парк Шевченка"),
+			Address:       "парк Шевченка",
+			Venue:         "Головна алея",
+			IsOnline:      e.online,
+			Participants:  e.attendees,
+			IsPublic:      true,
+			IsFree:        true,
+			Status:        models.EventStatusPublished,
+			IsVerified:    true,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			PublishedAt:   &publishedAt,
+			Tags:          []string{},
+			Attendees:     e.attendees,
+			AttendeeCount: len(e.attendees),
+		}
+		if e.online {
+			event.OnlineURL = "https://meet.example.com/sandbox"
+		}
+		events = append(events, event)
+	}
+	if err := insert("events", events); err != nil {
+		return nil, err
+	}
+
+	// Петиции: активная с подписями и черновик автора
+	petitions := []interface{}{}
+	for i, p := range []struct {
+		title, description, demands, category, status string
+		signers                                       []models.User
+	}{
+		{
+			"Облаштувати велодоріжку на проспекті Дніпровському",
+			"Велосипедом у місті користуються все більше мешканців, але безпечної інфраструктури немає. Пропонуємо виділити смугу вздовж проспекту.",
+			"Розробити проєкт велодоріжки та передбачити кошти в бюджеті на наступний рік.",
+			models.PetitionCategoryTransport, models.PetitionStatusActive, []models.User{olena, taras},
+		},
+		{
+			"Встановити питні фонтанчики в центральних парках",
+			"Влітку в парках немає де набрати питної води. Фонтанчики потрібні біля дитячих майданчиків і зупинок.",
+			"Встановити щонайменше чотири питні фонтанчики до початку літнього сезону.",
+			models.PetitionCategoryInfrastructure, models.PetitionStatusDraft, nil,
+		},
+	} {
+		id := primitive.NewObjectID()
+		signatures := make([]models.PetitionSignature, 0, len(p.signers))
+		for _, signer := range p.signers {
+			signatures = append(signatures, models.PetitionSignature{
+				UserID:     signer.ID,
+				FullName:   signer.FirstName + " " + signer.LastName,
+				IsVerified: true,
+				SignedAt:   now,
+			})
+		}
+		petitions = append(petitions, models.Petition{
+			ID:                 id,
+			Slug:               buildSlug(p.title, "petition", id, false),
+			CommunityID:        communityID,
+			AuthorID:           residents[i],
+			Title:              p.title,
+			Description:        p.description,
+			Language:           DetectLanguage(p.title, p.description),
+			Category:           p.category,
+			RequiredSignatures: 100,
+			Demands:            p.demands,
+			Signatures:         signatures,
+			SignatureCount:     len(signatures),
+			Status:             p.status,
+			StartDate:          now,
+			EndDate:            now.AddDate(0, 2, 0),
+			CreatedAt:          now,
+			UpdatedAt:          now,
+			Tags:               []string{},
+			AttachmentURLs:     []string{},
+		})
+	}
+	if err := insert("petitions", petitions); err != nil {
+		return nil, err
+	}
+
+	// Опрос с двумя ответами
+	pollID := primitive.NewObjectID()
+	question := models.PollQuestion{
+		ID:         primitive.NewObjectID(),
+		Text:       "Що варто облаштувати в міському парку насамперед?",
+		Type:       models.QuestionTypeSingleChoice,
+		IsRequired: true,
+		Options: []models.PollOption{
+			{ID: primitive.NewObjectID(), Text: "Дитячий майданчик"},
+			{ID: primitive.NewObjectID(), Text: "Спортивний майданчик"},
+			{ID: primitive.NewObjectID(), Text: "Освітлення алей"},
+		},
+	}
+	responses := make([]models.PollResponse, 0, len(residents))
+	responseDocuments := make([]interface{}, 0, len(residents))
+	for i, userID := range residents {
+		responses = append(responses, models.PollResponse{
+			ID:         primitive.NewObjectID(),
+			PollID:     pollID,
+			UserID:     userID,
+			UniqueVote: true,
+			Answers: []models.PollAnswer{{
+				QuestionID: question.ID,
+				OptionIDs:  []primitive.ObjectID{question.Options[i].ID},
+			}},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			SubmittedAt: now,
+		})
+		responseDocuments = append(responseDocuments, responses[i])
+	}
+	publishedAt := now
+	poll := models.Poll{
+		ID:             pollID,
+		CommunityID:    communityID,
+		CreatorID:      admin.ID,
+		Title:          "Благоустрій міського парку",
+		Description:    "Допоможіть визначити, на що спрямувати кошти на благоустрій парку цього року.",
+		Language:       models.LanguageUkrainian,
+		Category:       models.PollCategoryCityPlanning,
+		Questions:      []models.PollQuestion{question},
+		IsPublic:       true,
+		StartDate:      now,
+		EndDate:        now.AddDate(0, 1, 0),
+		TotalResponses: len(responses),
+		ResponseCount:  len(responses),
+		Status:         models.PollStatusActive,
+		IsVerified:     true,
+		Tags:           []string{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		PublishedAt:    &publishedAt,
+	}
+	poll.Results = poll.CalculateResults(responses)
+	if err := insert("polls", []interface{}{poll}); err != nil {
+		return nil, err
+	}
+	if err := insert("poll_responses", responseDocuments); err != nil {
+		return nil, err
+	}
+
+	// Проблемы города
+	issues := []interface{}{}
+	for i, issue := range []struct {
+		title, description, category, priority, status string
+		upvotes                                        []primitive.ObjectID
+	}{
+		{"Яма на дорозі біля школи №3", "Глибока яма на проїжджій частині, автомобілі об'їжджають її по зустрічній смузі.", "road", "high", models.IssueStatusReported, residents},
+		{"Не працює ліхтар у дворі", "Третій тиждень не світить ліхтар біля будинку, ввечері у дворі зовсім темно.", "lighting", "medium", models.IssueStatusInProgress, []primitive.ObjectID{taras.ID}},
+	} {
+		location := sandboxLocation(-0.006-float64(i)*0.004, "вул. Пушкіна, 12")
+		issues = append(issues, models.CityIssue{
+			ID:          primitive.NewObjectID(),
+			CommunityID: communityID,
+			ReporterID:  residents[i],
+			Title:       issue.title,
+			Description: issue.description,
+			Language:    DetectLanguage(issue.title, issue.description),
+			Category:    issue.category,
+			Priority:    issue.priority,
+			Location:    location,
+			Address:     location.Address,
+			Photos:      []string{},
+			Videos:      []string{},
+			Status:      issue.status,
+			UpVotes:     issue.upvotes,
+			UpVoteCount: len(issue.upvotes),
+			Comments:    []models.IssueComment{},
+			Subscribers: []primitive.ObjectID{residents[i]},
+			IsPublic:    true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+	if err := insert("city_issues", issues); err != nil {
+		return nil, err
+	}
+
+	// Группа со всеми демо-пользователями и несколькими сообщениями
+	members := []primitive.ObjectID{admin.ID, moderator.ID, olena.ID, taras.ID}
+	group := models.Group{
+		ID:          groupID,
+		CommunityID: communityID,
+		Name:        "Мешканці Нової Каховки",
+		Description: "Загальний чат громади для новин і взаємодопомоги",
+		Type:        models.GroupTypeCity,
+		CreatorID:   admin.ID,
+		Members:     members,
+		Admins:      []primitive.ObjectID{admin.ID},
+		Moderators:  []primitive.ObjectID{moderator.ID},
+		IsPublic:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   admin.ID,
+		MemberCount: len(members),
+	}
+	if err := insert("groups", []interface{}{group}); err != nil {
+		return nil, err
+	}
+
+	messages := []interface{}{}
+	for i, m := range []struct {
+		author  primitive.ObjectID
+		content string
+	}{
+		{admin.ID, "Вітаємо в пісочниці e-City! Дані скидаються через POST /api/v1/sandbox/reset."},
+		{olena.ID, "Хто йде на толоку в суботу?"},
+		{taras.ID, "Я буду, візьму граблі."},
+	} {
+		sentAt := now.Add(time.Duration(i-3) * time.Minute)
+		messages = append(messages, models.Message{
+			ID:        primitive.NewObjectID(),
+			GroupID:   groupID,
+			UserID:    m.author,
+			Content:   m.content,
+			Type:      models.MessageTypeText,
+			CreatedAt: sentAt,
+			UpdatedAt: sentAt,
+		})
+	}
+	if err := insert("messages", messages); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/database"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type cachedSession struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// SessionService ведет список входов пользователя (устройство, IP, последняя активность)
+// и проверяет, не отозвана ли сессия access-токена.
+// Проверка кэшируется на checkInterval: с той же частотой обновляется last_seen_at,
+// а отзыв на другом экземпляре сервера становится заметен не позже, чем через этот интервал.
+type SessionService struct {
+	sessionCollection *database.RetryCollection
+	checkInterval     time.Duration
+
+	mu      sync.RWMutex
+	entries map[primitive.ObjectID]cachedSession
+}
+
+func NewSessionService(sessionCollection *database.RetryCollection, checkInterval time.Duration) *SessionService {
+	return &SessionService{
+		sessionCollection: sessionCollection,
+		checkInterval:     checkInterval,
+		entries:           make(map[primitive.ObjectID]cachedSession),
+	}
+}
+
+// Start записывает новую сессию при входе или регистрации
+func (s *SessionService) Start(ctx context.Context, sessionID, userID primitive.ObjectID, userAgent, ip string, expiresAt time.Time) error {
+	now := time.Now()
+	_, err := s.sessionCollection.InsertOne(ctx, models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		Device:     DeviceFromUserAgent(userAgent),
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	})
+	return err
+}
+
+// Extend продлевает сессию после ротации refresh-токена
+func (s *SessionService) Extend(ctx context.Context, sessionID primitive.ObjectID, userAgent, ip string, expiresAt time.Time) error {
+	_, err := s.sessionCollection.UpdateOne(ctx,
+		bson.M{"_id": sessionID, "revoked_at": nil},
+		bson.M{"$set": bson.M{
+			"device":       DeviceFromUserAgent(userAgent),
+			"user_agent":   userAgent,
+			"ip":           ip,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}},
+	)
+	return err
+}
+
+// IsActive проверяет сессию access-токена и отмечает активность.
+// Отозванная или истекшая сессия кэшируется так же, как активная.
+func (s *SessionService) IsActive(ctx context.Context, sessionID primitive.ObjectID, ip string) (bool, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.entries[sessionID]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	// Проверка выполняется на каждом запросе, поэтому переживает кратковременные сбои базы
+	result, err := s.sessionCollection.UpdateOneIdempotent(ctx,
+		bson.M{"_id": sessionID, "revoked_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"last_seen_at": now, "ip": ip}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	active := result.MatchedCount > 0
+	s.mu.Lock()
+	s.entries[sessionID] = cachedSession{active: active, expiresAt: now.Add(s.checkInterval)}
+	s.mu.Unlock()
+
+	return active, nil
+}
+
+// List возвращает действующие сессии пользователя, начиная с последней активной
+func (s *SessionService) List(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	cursor, err := s.sessionCollection.Find(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now()}},
+		options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Revoke отзывает сессию пользователя; false - сессия не найдена или уже отозвана
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID primitive.ObjectID) (bool, error) {
+	result, err := s.sessionCollection.UpdateOne(ctx,
+		bson.M{"_id": sessionID, "user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	s.invalidate(sessionID)
+	return result.ModifiedCount > 0, nil
+}
+
+// RevokeAll отзывает все сессии пользователя (выход везде, смена пароля, блокировка)
+func (s *SessionService) RevokeAll(ctx context.Context, userID primitive.ObjectID) error {
+	filter := bson.M{"user_id": userID, "revoked_at": nil}
+
+	cursor, err := s.sessionCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var revoked []models.Session
+	if err := cursor.All(ctx, &revoked); err != nil {
+		return err
+	}
+
+	if _, err := s.sessionCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}}); err != nil {
+		return err
+	}
+	for _, session := range revoked {
+		s.invalidate(session.ID)
+	}
+	return nil
+}
+
+func (s *SessionService) invalidate(sessionID primitive.ObjectID) {
+	s.mu.Lock()
+	delete(s.entries, sessionID)
+	s.mu.Unlock()
+}
+
+// StartCleanup периодически удаляет устаревшие записи кэша
+func (s *SessionService) StartCleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		s.mu.Lock()
+		for sessionID, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, sessionID)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// DeviceFromUserAgent - платформа устройства для списка сессий
+func DeviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return "Unknown"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "cfnetwork"):
+		return "iOS"
+	case strings.Contains(ua, "android"), strings.Contains(ua, "okhttp"):
+		return "Android"
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	case strings.Contains(ua, "telegrambot"):
+		return "Telegram"
+	}
+	return "Other"
+}
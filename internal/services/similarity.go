@@ -0,0 +1,197 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Поиск дубликатов между украинским и русским текстом: оба языка сводятся к общей
+// латинской транскрипции, в которой близкие по звучанию буквы совпадают (і/и/ы, є/е/э, г/ґ),
+// затем слова сравниваются по основе с допуском на опечатки и различия в окончаниях.
+
+const (
+	// Минимальная похожесть двух основ, чтобы считать их одним словом
+	tokenMatchThreshold = 0.75
+	// Основа слова ограничивается по длине: длинные окончания в UA/RU почти всегда грамматические
+	maxStemLength  = 7
+	minTokenLength = 3
+)
+
+// crossLanguageLetters - общая транскрипция для украинских и русских букв
+var crossLanguageLetters = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d",
+	'е': "e", 'є': "e", 'э': "e", 'ё': "e",
+	'ж': "zh", 'з': "z",
+	'и': "i", 'і': "i", 'ы': "i", 'ї': "i", 'й': "i",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c",
+	'ч': "ch", 'ш': "sh", 'щ': "sch",
+	'ъ': "", 'ь': "", '\'': "", '’': "", 'ʼ': "",
+	'ю': "iu", 'я': "ia",
+}
+
+// latinFolding приводит текст, набранный латиницей (транслит), к той же транскрипции
+var latinFolding = strings.NewReplacer(
+	"shch", "sch", "kh", "h", "ts", "c", "y", "i", "j", "i", "w", "v", "x", "ks",
+)
+
+// stopWords - служебные слова обоих языков в общей транскрипции
+var stopWords = map[string]bool{
+	"dlia": true, "pro": true, "vid": true, "pri": true, "pid": true, "pod": true, "nad": true, "bez": true,
+	"kak": true, "iak": true, "scho": true, "sho": true, "chto": true, "abo": true, "ili": true, "ale": true,
+	"tak": true, "vse": true, "ego": true, "iogo": true, "tse": true, "ce": true, "eto": true, "nas": true,
+	"nam": true, "vzhe": true, "uzhe": true, "takozh": true, "takzhe": true, "che": true, "tut": true,
+	"mozhna": true, "mozhno": true, "duzhe": true, "ochen": true, "tomu": true, "poetomu": true,
+}
+
+// crossLanguageGlossary - частые в обращениях украинские слова, которые транскрипцией
+// к русскому варианту не сводятся (пары UA - RU)
+var crossLanguageGlossary = [][2]string{
+	{"вулиця", "улица"}, {"біля", "возле"}, {"працює", "работает"}, {"освітлення", "освещение"},
+	{"світло", "свет"}, {"лавки", "скамейки"}, {"сміття", "мусор"}, {"смітник", "мусорка"},
+	{"прибрати", "убрать"}, {"прибирання", "уборка"}, {"відремонтувати", "отремонтировать"},
+	{"встановити", "установить"}, {"зупинка", "остановка"}, {"будинок", "дом"}, {"двір", "двор"},
+	{"подвір'я", "двор"}, {"відключення", "отключение"}, {"опалення", "отопление"},
+	{"гілка", "ветка"}, {"майданчик", "площадка"}, {"дитячий", "детский"}, {"під'їзд", "подъезд"},
+	{"місто", "город"}, {"міський", "городской"}, {"лікарня", "больница"}, {"перехід", "переход"},
+	{"пішохідний", "пешеходный"}, {"міст", "мост"}, {"вибоїна", "выбоина"}, {"ліхтар", "фонарь"},
+	{"безпритульні", "бездомные"}, {"тварини", "животные"}, {"зламаний", "сломанный"},
+	{"потрібно", "нужно"}, {"вимагаємо", "требуем"}, {"заборонити", "запретить"},
+}
+
+// glossaryStems - основа украинского слова -> основа русского эквивалента
+var glossaryStems = func() map[string]string {
+	stems := make(map[string]string, len(crossLanguageGlossary))
+	for _, pair := range crossLanguageGlossary {
+		ua, ru := stemWord(transliterate(pair[0])), stemWord(transliterate(pair[1]))
+		if ua != ru {
+			stems[ua] = ru
+		}
+	}
+	return stems
+}()
+
+// NormalizeCrossLanguage возвращает основы значимых слов текста в общей UA/RU транскрипции
+func NormalizeCrossLanguage(text string) []string {
+	seen := make(map[string]bool)
+	var stems []string
+	for _, word := range strings.Fields(transliterate(text)) {
+		if len(word) < minTokenLength || stopWords[word] {
+			continue
+		}
+		stem := stemWord(word)
+		if canonical, ok := glossaryStems[stem]; ok {
+			stem = canonical
+		}
+		if !seen[stem] {
+			seen[stem] = true
+			stems = append(stems, stem)
+		}
+	}
+	return stems
+}
+
+// transliterate переводит текст в общую транскрипцию; все, кроме букв и цифр, становится пробелом
+func transliterate(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if mapped, ok := crossLanguageLetters[r]; ok {
+			b.WriteString(mapped)
+			continue
+		}
+		// Прочие алфавиты не участвуют в сравнении, остается ASCII
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte(' ')
+	}
+	return latinFolding.Replace(b.String())
+}
+
+// stemWord отбрасывает конечные гласные (окончания) и обрезает основу
+func stemWord(word string) string {
+	stem := strings.TrimRight(word, "aeiou")
+	if len(stem) < minTokenLength {
+		stem = word
+	}
+	if len(stem) > maxStemLength {
+		stem = stem[:maxStemLength]
+	}
+	return stem
+}
+
+// TextSimilarity - похожесть двух наборов основ от 0 до 1 (коэффициент Дайса с нечетким совпадением слов)
+func TextSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	used := make([]bool, len(b))
+	matched := 0.0
+	for _, tokenA := range a {
+		best, bestIndex := 0.0, -1
+		for j, tokenB := range b {
+			if used[j] {
+				continue
+			}
+			if sim := tokenSimilarity(tokenA, tokenB); sim > best {
+				best, bestIndex = sim, j
+				if sim == 1 {
+					break
+				}
+			}
+		}
+		if bestIndex != -1 && best >= tokenMatchThreshold {
+			used[bestIndex] = true
+			matched += best
+		}
+	}
+	return 2 * matched / float64(len(a)+len(b))
+}
+
+// tokenSimilarity - 1 минус нормированное расстояние Левенштейна
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	// Основы сильно разной длины заведомо не дотянут до порога
+	if diff := len(a) - len(b); diff > 2 || diff < -2 {
+		return 0
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein - редакционное расстояние; после транскрипции строки в ASCII, поэтому сравниваем байты
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// DuplicateScore - итоговая похожесть контента: заголовок важнее описания
+func DuplicateScore(titleA, descriptionA, titleB, descriptionB []string) float64 {
+	titleScore := TextSimilarity(titleA, titleB)
+	if len(descriptionA) == 0 || len(descriptionB) == 0 {
+		return titleScore
+	}
+	return 0.7*titleScore + 0.3*TextSimilarity(descriptionA, descriptionB)
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Максимальная длина части slug из заголовка (без суффикса ID)
+	maxSlugTitleLength = 60
+	// Короткий суффикс - последние символы ObjectID; при совпадении берется весь ID
+	slugSuffixLength = 6
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ukrainianLatin - официальная транслитерация украинского алфавита (постановление КМУ №55 от 2010 г.)
+// и русские буквы, которые встречаются в заголовках
+var ukrainianLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e",
+	'є': "ie", 'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ю': "iu", 'я': "ia",
+	'ь': "", '\'': "", '’': "", 'ʼ': "",
+	'ё': "io", 'ы': "y", 'э': "e", 'ъ': "",
+}
+
+// ukrainianLatinInitial - написание в начале слова
+var ukrainianLatinInitial = map[rune]string{
+	'є': "ye", 'ї': "yi", 'й': "y", 'ю': "yu", 'я': "ya",
+}
+
+// Slugify переводит заголовок в латиницу для URL: "Ремонт мосту" -> "remont-mostu"
+func Slugify(title string) string {
+	var b strings.Builder
+	wordStart := true
+	var prev rune
+	for _, r := range strings.ToLower(title) {
+		latin, cyrillic := ukrainianLatin[r]
+		switch {
+		case r == 'г' && prev == 'з':
+			// "зг" передается как "zgh", чтобы не читалось как "zh"
+			b.WriteString("gh")
+		case wordStart && ukrainianLatinInitial[r] != "":
+			b.WriteString(ukrainianLatinInitial[r])
+		case cyrillic:
+			b.WriteString(latin)
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+		// Апостроф не разрывает слово: "з'явився" -> "ziavyvsia"
+		wordStart = !unicode.IsLetter(r) && !cyrillic
+		prev = r
+	}
+
+	slug := strings.Join(strings.FieldsFunc(b.String(), func(r rune) bool { return r == '-' }), "-")
+	if len(slug) > maxSlugTitleLength {
+		slug = slug[:maxSlugTitleLength]
+		// Обрезаем по границе слова, если она не слишком далеко
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlugTitleLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.Trim(slug, "-")
+	}
+	return slug
+}
+
+// IsSlug - строка имеет формат slug (для различения slug и ObjectID в URL)
+func IsSlug(value string) bool {
+	return len(value) <= maxSlugTitleLength+len(primitive.NilObjectID.Hex())+1 && slugPattern.MatchString(value)
+}
+
+// buildSlug - заголовок с суффиксом ID; если в заголовке нет букв, используется fallback (например, "petition")
+func buildSlug(title, fallback string, id primitive.ObjectID, fullID bool) string {
+	base := Slugify(title)
+	if base == "" {
+		base = fallback
+	}
+	suffix := id.Hex()
+	if !fullID {
+		suffix = suffix[len(suffix)-slugSuffixLength:]
+	}
+	return base + "-" + suffix
+}
+
+// UniqueSlug формирует slug для документа с заранее выбранным ID. Сначала пробуется короткий суффикс,
+// при совпадении с другим документом коллекции - полный ID, который уникален сам по себе.
+func UniqueSlug(ctx context.Context, collection *mongo.Collection, title, fallback string, id primitive.ObjectID) (string, error) {
+	slug := buildSlug(title, fallback, id, false)
+	count, err := collection.CountDocuments(ctx, bson.M{"slug": slug, "_id": bson.M{"$ne": id}})
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return buildSlug(title, fallback, id, true), nil
+	}
+	return slug, nil
+}
+
+// BackfillSlugs присваивает slug документам, созданным до появления slug. Возвращает число обновленных.
+func BackfillSlugs(ctx context.Context, collection *mongo.Collection, fallback string) (int, error) {
+	cursor, err := collection.Find(ctx,
+		bson.M{"slug": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"title": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Title string             `bson:"title"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+
+		slug, err := UniqueSlug(ctx, collection, doc.Title, fallback, doc.ID)
+		if err != nil {
+			return updated, err
+		}
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "slug": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"slug": slug}},
+		)
+		if err != nil {
+			return updated, err
+		}
+		updated += int(result.ModifiedCount)
+	}
+	return updated, cursor.Err()
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// SMSProvider - шлюз отправки SMS. Реализации подключаются в main по конфигурации.
+type SMSProvider interface {
+	Name() string
+	Send(ctx context.Context, phone, text string) error
+}
+
+// LogSMSProvider пишет сообщения в лог вместо отправки - для разработки без SMS-шлюза
+type LogSMSProvider struct{}
+
+func (LogSMSProvider) Name() string {
+	return "log"
+}
+
+func (LogSMSProvider) Send(ctx context.Context, phone, text string) error {
+	log.Printf("SMS to %s: %s", phone, text)
+	return nil
+}
+
+// HTTPSMSProvider отправляет SMS через HTTP API шлюза:
+// POST url с JSON {"to", "text", "sender"} и заголовком Authorization: Bearer token.
+// Любой ответ 2xx считается принятым к отправке.
+type HTTPSMSProvider struct {
+	url    string
+	token  string
+	sender string
+	client *http.Client
+}
+
+func NewHTTPSMSProvider(url, token, sender string) *HTTPSMSProvider {
+	return &HTTPSMSProvider{
+		url:    url,
+		token:  token,
+		sender: sender,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPSMSProvider) Name() string {
+	return "http"
+}
+
+func (p *HTTPSMSProvider) Send(ctx context.Context, phone, text string) error {
+	body, err := json.Marshal(map[string]string{
+		"to":     phone,
+		"text":   text,
+		"sender": p.sender,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms gateway returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	socialFetchTimeout   = 15 * time.Second
+	socialMaxPayloadSize = 5 << 20
+	socialFetchLimit     = 20 // Последних постов за один запрос
+)
+
+// ErrSocialChannel возвращается, если ссылку или имя канала не удалось разобрать
+var ErrSocialChannel = errors.New("invalid channel")
+
+// SocialPost - пост публичного канала в нормализованном виде, независимо от платформы
+type SocialPost struct {
+	ID        string // Уникален в пределах платформы
+	Text      string
+	URL       string
+	PostedAt  time.Time
+	MediaURLs []string
+}
+
+// SocialFeedPage - последние посты канала
+type SocialFeedPage struct {
+	Title string // Название канала, если платформа его отдает
+	Posts []SocialPost
+}
+
+// SocialFeedProvider - адаптер платформы с публичными каналами
+type SocialFeedProvider interface {
+	Platform() string
+	// NormalizeChannel приводит ссылку, @имя или ID канала к идентификатору для Fetch
+	NormalizeChannel(raw string) (string, error)
+	ChannelURL(channel string) string
+	Fetch(ctx context.Context, channel string) (*SocialFeedPage, error)
+}
+
+// socialChannelPattern - допустимые короткие имена и ID каналов
+var socialChannelPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{3,100}$`)
+
+// normalizeSocialChannel разбирает ссылку на канал одного из хостов или просто имя
+func normalizeSocialChannel(raw string, hosts ...string) (string, error) {
+	channel := strings.TrimSpace(raw)
+	if strings.Contains(channel, "/") {
+		if !strings.Contains(channel, "://") {
+			channel = "https://" + channel
+		}
+		link, err := url.Parse(channel)
+		if err != nil {
+			return "", ErrSocialChannel
+		}
+		host := strings.TrimPrefix(strings.ToLower(link.Hostname()), "www.")
+		known := false
+		for _, h := range hosts {
+			if host == h {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("%w: unexpected host %s", ErrSocialChannel, host)
+		}
+		segments := strings.Split(strings.Trim(link.Path, "/"), "/")
+		channel = segments[0]
+		// t.me/s/<channel> - веб-превью канала
+		if channel == "s" && len(segments) > 1 {
+			channel = segments[1]
+		}
+	}
+	channel = strings.TrimPrefix(channel, "@")
+	if !socialChannelPattern.MatchString(channel) {
+		return "", ErrSocialChannel
+	}
+	return channel, nil
+}
+
+func socialGet(ctx context.Context, client *http.Client, endpoint string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", "eCity-importer/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, socialMaxPayloadSize))
+	return body, resp.StatusCode, err
+}
+
+// ========================================
+// VK
+// ========================================
+
+// VKFeedProvider - стена сообщества VK через API wall.get (сервисный ключ приложения)
+type VKFeedProvider struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+func NewVKFeedProvider(token string) *VKFeedProvider {
+	return &VKFeedProvider{
+		token:   token,
+		baseURL: "https://api.vk.com/method",
+		client:  &http.Client{Timeout: socialFetchTimeout},
+	}
+}
+
+func (p *VKFeedProvider) Platform() string {
+	return models.SocialPlatformVK
+}
+
+func (p *VKFeedProvider) NormalizeChannel(raw string) (string, error) {
+	return normalizeSocialChannel(raw, "vk.com", "m.vk.com", "vk.ru")
+}
+
+func (p *VKFeedProvider) ChannelURL(channel string) string {
+	return "https://vk.com/" + channel
+}
+
+type vkWallResponse struct {
+	Response struct {
+		Items []struct {
+			ID          int64  `json:"id"`
+			OwnerID     int64  `json:"owner_id"`
+			Date        int64  `json:"date"`
+			Text        string `json:"text"`
+			MarkedAsAds int    `json:"marked_as_ads"`
+			Attachments []struct {
+				Type  string `json:"type"`
+				Photo struct {
+					Sizes []struct {
+						URL   string `json:"url"`
+						Width int    `json:"width"`
+					} `json:"sizes"`
+				} `json:"photo"`
+			} `json:"attachments"`
+		} `json:"items"`
+		Groups []struct {
+			Name string `json:"name"`
+		} `json:"groups"`
+	} `json:"response"`
+	Error *struct {
+		Code    int    `json:"error_code"`
+		Message string `json:"error_msg"`
+	} `json:"error"`
+}
+
+func (p *VKFeedProvider) Fetch(ctx context.Context, channel string) (*SocialFeedPage, error) {
+	query := url.Values{
+		"domain":       {channel},
+		"count":        {strconv.Itoa(socialFetchLimit)},
+		"extended":     {"1"},
+		"v":            {"5.199"},
+		"access_token": {p.token},
+	}
+	body, status, err := socialGet(ctx, p.client, p.baseURL+"/wall.get?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("vk responded with status %d", status)
+	}
+
+	var payload vkWallResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid vk response: %w", err)
+	}
+	if payload.Error != nil {
+		return nil, fmt.Errorf("vk error %d: %s", payload.Error.Code, payload.Error.Message)
+	}
+
+	page := &SocialFeedPage{}
+	if len(payload.Response.Groups) > 0 {
+		page.Title = payload.Response.Groups[0].Name
+	}
+	for _, item := range payload.Response.Items {
+		// Рекламные посты сообщества не импортируются
+		if item.MarkedAsAds != 0 {
+			continue
+		}
+		postID := fmt.Sprintf("%d_%d", item.OwnerID, item.ID)
+		post := SocialPost{
+			ID:       postID,
+			Text:     item.Text,
+			URL:      "https://vk.com/wall" + postID,
+			PostedAt: time.Unix(item.Date, 0),
+		}
+		for _, attachment := range item.Attachments {
+			if attachment.Type != "photo" {
+				continue
+			}
+			// Самый крупный вариант фотографии
+			best, width := "", 0
+			for _, size := range attachment.Photo.Sizes {
+				if size.Width >= width {
+					best, width = size.URL, size.Width
+				}
+			}
+			if best != "" {
+				post.MediaURLs = append(post.MediaURLs, best)
+			}
+		}
+		page.Posts = append(page.Posts, post)
+	}
+	return page, nil
+}
+
+// ========================================
+// FACEBOOK
+// ========================================
+
+// FacebookFeedProvider - посты публичной страницы через Graph API (токен страницы или приложения)
+type FacebookFeedProvider struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+func NewFacebookFeedProvider(token string) *FacebookFeedProvider {
+	return &FacebookFeedProvider{
+		token:   token,
+		baseURL: "https://graph.facebook.com/v19.0",
+		client:  &http.Client{Timeout: socialFetchTimeout},
+	}
+}
+
+func (p *FacebookFeedProvider) Platform() string {
+	return models.SocialPlatformFacebook
+}
+
+func (p *FacebookFeedProvider) NormalizeChannel(raw string) (string, error) {
+	return normalizeSocialChannel(raw, "facebook.com", "m.facebook.com", "fb.com")
+}
+
+func (p *FacebookFeedProvider) ChannelURL(channel string) string {
+	return "https://www.facebook.com/" + channel
+}
+
+type facebookPageResponse struct {
+	Name  string `json:"name"`
+	Posts struct {
+		Data []struct {
+			ID           string `json:"id"`
+			Message      string `json:"message"`
+			CreatedTime  string `json:"created_time"`
+			PermalinkURL string `json:"permalink_url"`
+			FullPicture  string `json:"full_picture"`
+		} `json:"data"`
+	} `json:"posts"`
+	Error *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+func (p *FacebookFeedProvider) Fetch(ctx context.Context, channel string) (*SocialFeedPage, error) {
+	query := url.Values{
+		"fields":       {fmt.Sprintf("name,posts.limit(%d){id,message,created_time,permalink_url,full_picture}", socialFetchLimit)},
+		"access_token": {p.token},
+	}
+	body, status, err := socialGet(ctx, p.client, p.baseURL+"/"+url.PathEscape(channel)+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var payload facebookPageResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid facebook response (status %d): %w", status, err)
+	}
+	if payload.Error != nil {
+		return nil, fmt.Errorf("facebook error %d: %s", payload.Error.Code, payload.Error.Message)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("facebook responded with status %d", status)
+	}
+
+	page := &SocialFeedPage{Title: payload.Name}
+	for _, item := range payload.Posts.Data {
+		postedAt, err := time.Parse("2006-01-02T15:04:05-0700", item.CreatedTime)
+		if err != nil {
+			continue
+		}
+		post := SocialPost{
+			ID:       item.ID,
+			Text:     item.Message,
+			URL:      item.PermalinkURL,
+			PostedAt: postedAt,
+		}
+		if item.FullPicture != "" {
+			post.MediaURLs = []string{item.FullPicture}
+		}
+		page.Posts = append(page.Posts, post)
+	}
+	return page, nil
+}
+
+// ========================================
+// TELEGRAM
+// ========================================
+
+// TelegramFeedProvider - публичный канал через веб-превью t.me/s/<channel>.
+// Токен не нужен, но превью есть только у публичных каналов.
+type TelegramFeedProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewTelegramFeedProvider() *TelegramFeedProvider {
+	return &TelegramFeedProvider{
+		baseURL: "https://t.me/s",
+		client:  &http.Client{Timeout: socialFetchTimeout},
+	}
+}
+
+func (p *TelegramFeedProvider) Platform() string {
+	return models.SocialPlatformTelegram
+}
+
+func (p *TelegramFeedProvider) NormalizeChannel(raw string) (string, error) {
+	return normalizeSocialChannel(raw, "t.me", "telegram.me")
+}
+
+func (p *TelegramFeedProvider) ChannelURL(channel string) string {
+	return "https://t.me/" + channel
+}
+
+// Фото поста задается фоном: background-image:url('https://...')
+var telegramBackgroundURL = regexp.MustCompile(`background-image:url\('([^']+)'\)`)
+
+func (p *TelegramFeedProvider) Fetch(ctx context.Context, channel string) (*SocialFeedPage, error) {
+	body, status, err := socialGet(ctx, p.client, p.baseURL+"/"+url.PathEscape(channel))
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("telegram responded with status %d", status)
+	}
+
+	doc, err := html.Parse(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid telegram page: %w", err)
+	}
+
+	page := &SocialFeedPage{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.Data == "meta" && htmlAttr(n, "property") == "og:title" {
+				page.Title = htmlAttr(n, "content")
+			}
+			if n.Data == "div" && htmlHasClass(n, "tgme_widget_message") && htmlAttr(n, "data-post") != "" {
+				if post, ok := parseTelegramMessage(n); ok {
+					page.Posts = append(page.Posts, post)
+				}
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	// Превью без постов и без названия - канала нет или он не публичный
+	if page.Title == "" && len(page.Posts) == 0 {
+		return nil, fmt.Errorf("telegram channel %s has no public preview", channel)
+	}
+	return page, nil
+}
+
+// parseTelegramMessage разбирает блок поста: data-post="<channel>/<id>", текст, время и фото
+func parseTelegramMessage(message *html.Node) (SocialPost, bool) {
+	dataPost := htmlAttr(message, "data-post")
+	post := SocialPost{
+		ID:  dataPost,
+		URL: "https://t.me/" + dataPost,
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "div" && htmlHasClass(n, "tgme_widget_message_text") && post.Text == "":
+				post.Text = htmlText(n)
+				return
+			case n.Data == "time" && post.PostedAt.IsZero():
+				if postedAt, err := time.Parse(time.RFC3339, htmlAttr(n, "datetime")); err == nil {
+					post.PostedAt = postedAt
+				}
+			case n.Data == "a" && htmlHasClass(n, "tgme_widget_message_photo_wrap"):
+				if match := telegramBackgroundURL.FindStringSubmatch(htmlAttr(n, "style")); match != nil {
+					post.MediaURLs = append(post.MediaURLs, match[1])
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(message)
+
+	return post, !post.PostedAt.IsZero()
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func htmlHasClass(n *html.Node, class string) bool {
+	for _, value := range strings.Fields(htmlAttr(n, "class")) {
+		if value == class {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlText - текст узла; <br> становится переводом строки
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			b.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	socialImportCheckInterval = time.Minute
+	socialDefaultFetchEvery   = 30 * time.Minute
+	// Тот же текст в другом канале в течение этого срока считается кросспостингом
+	socialDuplicateWindow = 30 * 24 * time.Hour
+	socialMinTextLength   = 20
+	socialTitleMaxLength  = 120
+	socialTextMaxLength   = 2000
+	socialAnnouncementTTL = 30 * 24 * time.Hour
+)
+
+// SocialImportStats - результат одного прохода по каналу
+type SocialImportStats struct {
+	Fetched    int `json:"fetched"`
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	Skipped    int `json:"skipped"`
+}
+
+// SocialImportService забирает новые посты подключенных каналов и создает из них
+// черновики объявлений и событий на модерации (status=pending) с атрибуцией источника.
+type SocialImportService struct {
+	feedCollection         *mongo.Collection
+	importCollection       *mongo.Collection
+	announcementCollection *mongo.Collection
+	eventCollection        *mongo.Collection
+	fetchEvery             time.Duration
+
+	providers map[string]SocialFeedProvider
+}
+
+func NewSocialImportService(feedCollection, importCollection, announcementCollection, eventCollection *mongo.Collection, fetchEvery time.Duration) *SocialImportService {
+	if fetchEvery <= 0 {
+		fetchEvery = socialDefaultFetchEvery
+	}
+	return &SocialImportService{
+		feedCollection:         feedCollection,
+		importCollection:       importCollection,
+		announcementCollection: announcementCollection,
+		eventCollection:        eventCollection,
+		fetchEvery:             fetchEvery,
+		providers:              make(map[string]SocialFeedProvider),
+	}
+}
+
+// Register добавляет адаптер платформы
+func (s *SocialImportService) Register(provider SocialFeedProvider) {
+	s.providers[provider.Platform()] = provider
+}
+
+// Provider возвращает адаптер платформы; платформы без настроенного токена не регистрируются
+func (s *SocialImportService) Provider(platform string) (SocialFeedProvider, bool) {
+	provider, ok := s.providers[platform]
+	return provider, ok
+}
+
+// Platforms - список доступных платформ
+func (s *SocialImportService) Platforms() []string {
+	platforms := make([]string, 0, len(s.providers))
+	for platform := range s.providers {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// FetchEvery - интервал опроса каждого канала
+func (s *SocialImportService) FetchEvery() time.Duration {
+	return s.fetchEvery
+}
+
+// StartWorker опрашивает каналы, у которых подошло время next_fetch_at
+func (s *SocialImportService) StartWorker() {
+	ticker := time.NewTicker(socialImportCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.importDue()
+	}
+}
+
+func (s *SocialImportService) importDue() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		feed, err := s.claimDue(ctx)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Printf("Error claiming social feed: %v", err)
+			}
+			cancel()
+			return
+		}
+		if _, err := s.ImportFeed(ctx, feed); err != nil {
+			log.Printf("Error importing %s channel %s: %v", feed.Platform, feed.Channel, err)
+		}
+		cancel()
+	}
+}
+
+// claimDue переносит next_fetch_at канала вперед и возвращает его: несколько экземпляров
+// сервера не опрашивают один канал одновременно
+func (s *SocialImportService) claimDue(ctx context.Context) (*models.SocialFeed, error) {
+	now := time.Now()
+	var feed models.SocialFeed
+	err := s.feedCollection.FindOneAndUpdate(ctx,
+		bson.M{"is_active": true, "next_fetch_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"next_fetch_at": now.Add(s.fetchEvery)}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_fetch_at", Value: 1}}),
+	).Decode(&feed)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ImportFeed забирает последние посты канала и импортирует опубликованные после курсора last_post_at.
+// Ошибка платформы сохраняется в last_error канала.
+func (s *SocialImportService) ImportFeed(ctx context.Context, feed *models.SocialFeed) (SocialImportStats, error) {
+	var stats SocialImportStats
+
+	provider, ok := s.providers[feed.Platform]
+	if !ok {
+		return stats, fmt.Errorf("platform %s is not configured", feed.Platform)
+	}
+
+	now := time.Now()
+	page, err := provider.Fetch(ctx, feed.Channel)
+	if err != nil {
+		s.feedCollection.UpdateOne(ctx, bson.M{"_id": feed.ID}, bson.M{"$set": bson.M{
+			"last_fetched_at": now,
+			"last_error":      err.Error(),
+		}})
+		return stats, err
+	}
+	stats.Fetched = len(page.Posts)
+
+	// Старые посты первыми: курсор сдвигается только за успешно обработанными
+	posts := page.Posts
+	sort.Slice(posts, func(i, j int) bool { return posts[i].PostedAt.Before(posts[j].PostedAt) })
+
+	cursor := feed.LastPostAt
+	var importErr error
+	for _, post := range posts {
+		if !post.PostedAt.After(feed.LastPostAt) {
+			continue
+		}
+		status, err := s.importPost(ctx, feed, page.Title, post)
+		if err != nil {
+			importErr = fmt.Errorf("post %s: %w", post.ID, err)
+			break
+		}
+		switch status {
+		case models.SocialImportImported:
+			stats.Imported++
+		case models.SocialImportDuplicate:
+			stats.Duplicates++
+		default:
+			stats.Skipped++
+		}
+		cursor = post.PostedAt
+	}
+
+	set := bson.M{
+		"last_post_at":    cursor,
+		"last_fetched_at": now,
+		"last_error":      "",
+		"updated_at":      now,
+	}
+	if importErr != nil {
+		set["last_error"] = importErr.Error()
+	}
+	if page.Title != "" {
+		set["title"] = page.Title
+	}
+	if _, err := s.feedCollection.UpdateOne(ctx, bson.M{"_id": feed.ID}, bson.M{
+		"$set": set,
+		"$inc": bson.M{"imported_count": stats.Imported, "duplicate_count": stats.Duplicates},
+	}); err != nil {
+		return stats, err
+	}
+	return stats, importErr
+}
+
+// importPost создает черновик из поста. Пост, уже записанный в журнал (в том числе
+// другим экземпляром сервера), пропускается.
+func (s *SocialImportService) importPost(ctx context.Context, feed *models.SocialFeed, channelTitle string, post SocialPost) (string, error) {
+	now := time.Now()
+	text := normalizePostText(post.Text)
+	record := models.SocialImport{
+		ID:        primitive.NewObjectID(),
+		FeedID:    feed.ID,
+		Platform:  feed.Platform,
+		PostID:    post.ID,
+		Status:    models.SocialImportSkipped,
+		CreatedAt: now,
+	}
+	if utf8.RuneCountInString(text) >= socialMinTextLength {
+		record.Status = models.SocialImportImported
+		record.ContentHash = socialContentHash(text)
+		record.ContentType = feed.Target
+	}
+
+	if _, err := s.importCollection.InsertOne(ctx, record); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.SocialImportSkipped, nil
+		}
+		return "", err
+	}
+	if record.Status == models.SocialImportSkipped {
+		return record.Status, nil
+	}
+
+	// Тот же текст из другого канала: черновик уже есть
+	var original models.SocialImport
+	err := s.importCollection.FindOne(ctx, bson.M{
+		"content_hash": record.ContentHash,
+		"status":       models.SocialImportImported,
+		"_id":          bson.M{"$ne": record.ID},
+		"created_at":   bson.M{"$gte": now.Add(-socialDuplicateWindow)},
+	}).Decode(&original)
+	if err == nil {
+		_, err = s.importCollection.UpdateOne(ctx, bson.M{"_id": record.ID}, bson.M{"$set": bson.M{
+			"status":       models.SocialImportDuplicate,
+			"content_type": original.ContentType,
+			"content_id":   original.ContentID,
+		}})
+		return models.SocialImportDuplicate, err
+	}
+	if err != mongo.ErrNoDocuments {
+		s.importCollection.DeleteOne(ctx, bson.M{"_id": record.ID})
+		return "", err
+	}
+
+	source := &models.ContentSource{
+		Platform:     feed.Platform,
+		FeedID:       feed.ID,
+		Channel:      feed.Channel,
+		ChannelTitle: channelTitle,
+		PostID:       post.ID,
+		URL:          post.URL,
+		MediaURLs:    post.MediaURLs,
+		PostedAt:     post.PostedAt,
+		ImportedAt:   now,
+	}
+
+	var contentID primitive.ObjectID
+	if feed.Target == models.SocialImportTargetEvent {
+		contentID, err = s.createEvent(ctx, feed, text, source)
+	} else {
+		contentID, err = s.createAnnouncement(ctx, feed, text, source)
+	}
+	if err != nil {
+		// Пост будет обработан заново при следующем опросе
+		s.importCollection.DeleteOne(ctx, bson.M{"_id": record.ID})
+		return "", err
+	}
+
+	if _, err := s.importCollection.UpdateOne(ctx, bson.M{"_id": record.ID}, bson.M{"$set": bson.M{"content_id": contentID}}); err != nil {
+		log.Printf("Error linking social import %s to %s: %v", record.ID.Hex(), contentID.Hex(), err)
+	}
+	return models.SocialImportImported, nil
+}
+
+func (s *SocialImportService) createAnnouncement(ctx context.Context, feed *models.SocialFeed, text string, source *models.ContentSource) (primitive.ObjectID, error) {
+	now := time.Now()
+	announcement := models.Announcement{
+		ID:           primitive.NewObjectID(),
+		CommunityID:  feed.CommunityID,
+		AuthorID:     feed.ConnectedBy,
+		Title:        socialPostTitle(text),
+		Description:  truncateRunes(text, socialTextMaxLength),
+		Language:     DetectLanguage(text),
+		Category:     feed.Category,
+		Neighborhood: feed.Neighborhood,
+		ContactInfo:  []models.ContactInfo{},
+		MediaFiles:   []string{},
+		IsActive:     true,
+		IsVerified:   false, // Требует модерации
+		Status:       "pending",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		ExpiresAt:    now.Add(socialAnnouncementTTL),
+		Source:       source,
+	}
+	// Посты каналов полны эмодзи, ссылок и хэштегов - для чтения с экрана готовим чистый текст
+	announcement.SpeechHints = AnnouncementSpeechHints(announcement, models.SpeechHints{})
+
+	var err error
+	announcement.Slug, err = UniqueSlug(ctx, s.announcementCollection, announcement.Title, "announcement", announcement.ID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if _, err := s.announcementCollection.InsertOne(ctx, announcement); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return announcement.ID, nil
+}
+
+func (s *SocialImportService) createEvent(ctx context.Context, feed *models.SocialFeed, text string, source *models.ContentSource) (primitive.ObjectID, error) {
+	startDate, detected := detectEventStart(text, source.PostedAt)
+	source.NeedsDate = !detected
+
+	now := time.Now()
+	event := models.Event{
+		ID:           primitive.NewObjectID(),
+		CommunityID:  feed.CommunityID,
+		OrganizerID:  feed.ConnectedBy, // Модератор, подключивший канал, может исправить черновик
+		Title:        socialPostTitle(text),
+		Description:  truncateRunes(text, socialTextMaxLength),
+		Language:     DetectLanguage(text),
+		Category:     feed.Category,
+		StartDate:    startDate,
+		Neighborhood: feed.Neighborhood,
+		Participants: []primitive.ObjectID{},
+		Attendees:    []primitive.ObjectID{},
+		IsPublic:     true,
+		Status:       models.EventStatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Source:       source,
+	}
+
+	var err error
+	event.Slug, err = UniqueSlug(ctx, s.eventCollection, event.Title, "event", event.ID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if _, err := s.eventCollection.InsertOne(ctx, event); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return event.ID, nil
+}
+
+// ErrSocialFeedExists возвращается при повторном подключении канала в громаде
+var ErrSocialFeedExists = errors.New("social feed already connected")
+
+// Connect подключает канал; импортируются только посты, опубликованные после подключения
+func (s *SocialImportService) Connect(ctx context.Context, feed *models.SocialFeed) error {
+	now := time.Now()
+	feed.ID = primitive.NewObjectID()
+	feed.IsActive = true
+	feed.LastPostAt = now
+	feed.NextFetchAt = now.Add(s.fetchEvery)
+	feed.CreatedAt = now
+	feed.UpdatedAt = now
+
+	if _, err := s.feedCollection.InsertOne(ctx, feed); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrSocialFeedExists
+		}
+		return err
+	}
+	return nil
+}
+
+// ========================================
+// ТЕКСТ ПОСТА
+// ========================================
+
+var socialBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// normalizePostText убирает лишние пробелы и пустые строки, сохраняя абзацы
+func normalizePostText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(socialBlankLines.ReplaceAllString(text, "\n\n"))
+}
+
+// socialContentHash - хеш текста без регистра, пунктуации и ссылок: кросспост
+// с другой подписью или ссылкой на свой канал остается тем же текстом
+func socialContentHash(text string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if strings.Contains(word, "://") || strings.HasPrefix(word, "@") || strings.HasPrefix(word, "#") {
+			continue
+		}
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !(r >= '0' && r <= '9') && !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzабвгґдеєёжзиіїйклмнопрстуфхцчшщъыьэюя'’", r)
+		})
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// socialPostTitle - первая строка поста, сокращенная по границе слова
+func socialPostTitle(text string) string {
+	title := text
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	// Короткая первая строка (эмодзи, "Увага!") не годится для заголовка
+	if utf8.RuneCountInString(title) < 10 {
+		title = strings.ReplaceAll(text, "\n", " ")
+	}
+	if utf8.RuneCountInString(title) <= socialTitleMaxLength {
+		return title
+	}
+	title = truncateRunes(title, socialTitleMaxLength-1)
+	if i := strings.LastIndexByte(title, ' '); i > socialTitleMaxLength/2 {
+		title = title[:i]
+	}
+	return strings.TrimRight(title, " ,.;:-") + "…"
+}
+
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit])
+}
+
+// ========================================
+// ДАТА СОБЫТИЯ
+// ========================================
+
+var (
+	// 15.05, 15.05.2025, 15/05/25
+	socialNumericDate = regexp.MustCompile(`(?:^|[^\d])(\d{1,2})[./](\d{1,2})(?:[./](\d{4}|\d{2}))?(?:[^\d]|$)`)
+	// 15 травня, 15 мая 2025
+	socialWordDate = regexp.MustCompile(`(?i)(?:^|[^\d])(\d{1,2})\s+(січня|лютого|березня|квітня|травня|червня|липня|серпня|вересня|жовтня|листопада|грудня|января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)(?:\s+(\d{4}))?`)
+	// 18:30
+	socialTime = regexp.MustCompile(`(?:^|[^\d])([01]?\d|2[0-3]):([0-5]\d)(?:[^\d]|$)`)
+)
+
+var socialMonths = map[string]time.Month{
+	"січня": time.January, "лютого": time.February, "березня": time.March, "квітня": time.April,
+	"травня": time.May, "червня": time.June, "липня": time.July, "серпня": time.August,
+	"вересня": time.September, "жовтня": time.October, "листопада": time.November, "грудня": time.December,
+	"января": time.January, "февраля": time.February, "марта": time.March, "апреля": time.April,
+	"мая": time.May, "июня": time.June, "июля": time.July, "августа": time.August,
+	"сентября": time.September, "октября": time.October, "ноября": time.November, "декабря": time.December,
+}
+
+// detectEventStart ищет в тексте дату и время события. Без года берется ближайшая дата
+// не раньше публикации. false - дата или время не найдены, возвращается время поста.
+func detectEventStart(text string, postedAt time.Time) (time.Time, bool) {
+	day, month, year, found := 0, time.Month(0), 0, false
+
+	if match := socialWordDate.FindStringSubmatch(text); match != nil {
+		day, _ = strconv.Atoi(match[1])
+		month = socialMonths[strings.ToLower(match[2])]
+		year, _ = strconv.Atoi(match[3])
+		found = true
+	} else {
+		for _, match := range socialNumericDate.FindAllStringSubmatch(text, -1) {
+			d, _ := strconv.Atoi(match[1])
+			m, _ := strconv.Atoi(match[2])
+			if d < 1 || d > 31 || m < 1 || m > 12 {
+				continue
+			}
+			day, month = d, time.Month(m)
+			year, _ = strconv.Atoi(match[3])
+			if year > 0 && year < 100 {
+				year += 2000
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return postedAt, false
+	}
+
+	hour, minute, timeFound := 0, 0, false
+	if match := socialTime.FindStringSubmatch(text); match != nil {
+		hour, _ = strconv.Atoi(match[1])
+		minute, _ = strconv.Atoi(match[2])
+		timeFound = true
+	}
+
+	posted := postedAt.In(time.Local)
+	explicitYear := year != 0
+	if !explicitYear {
+		year = posted.Year()
+	}
+	start := time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+	// 31.02 и подобные time.Date переносит на следующий месяц
+	if start.Day() != day {
+		return postedAt, false
+	}
+	if !explicitYear && start.Before(posted.Add(-24*time.Hour)) {
+		start = start.AddDate(1, 0, 0)
+	}
+	return start, timeFound
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"nova-kakhovka-ecity/internal/models"
+)
+
+var (
+	speechHTMLTag      = regexp.MustCompile(`<[^>]*>`)
+	speechMarkdownLink = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	speechURL          = regexp.MustCompile(`https?://\S+|www\.\S+`)
+)
+
+// placePronunciations - ударения в названиях громады и соседних населенных пунктов.
+// Синтезаторы речи читают их с неверным ударением, поэтому подсказки добавляются автоматически.
+// Падежные формы перечислены отдельно: в тексте "у Новій Каховці" встречается чаще именительного.
+var placePronunciations = []models.PronunciationHint{
+	{Term: "Нова Каховка", Spoken: "Нова́ Кахо́вка"},
+	{Term: "Нової Каховки", Spoken: "Ново́ї Кахо́вки"},
+	{Term: "Новій Каховці", Spoken: "Нові́й Кахо́вці"},
+	{Term: "Нову Каховку", Spoken: "Нову́ Кахо́вку"},
+	{Term: "Каховка", Spoken: "Кахо́вка"},
+	{Term: "Каховки", Spoken: "Кахо́вки"},
+	{Term: "Каховці", Spoken: "Кахо́вці"},
+	{Term: "Каховку", Spoken: "Кахо́вку"},
+	{Term: "Каховське водосховище", Spoken: "Кахо́вське водосхо́вище"},
+	{Term: "Каховського водосховища", Spoken: "Кахо́вського водосхо́вища"},
+	{Term: "Каховському водосховищі", Spoken: "Кахо́вському водосхо́вищі"},
+	{Term: "Херсон", Spoken: "Херсо́н"},
+	{Term: "Херсона", Spoken: "Херсо́на"},
+	{Term: "Херсоні", Spoken: "Херсо́ні"},
+	{Term: "Таврійськ", Spoken: "Таврі́йськ"},
+	{Term: "Таврійська", Spoken: "Таврі́йська"},
+	{Term: "Таврійську", Spoken: "Таврі́йську"},
+	{Term: "Берислав", Spoken: "Берисла́в"},
+	{Term: "Берислава", Spoken: "Берисла́ва"},
+	{Term: "Бериславі", Spoken: "Берисла́ві"},
+	{Term: "Дніпряни", Spoken: "Дніпря́ни"},
+	{Term: "Дніпрян", Spoken: "Дніпря́н"},
+	{Term: "Дніпрянах", Spoken: "Дніпря́нах"},
+	{Term: "Корсунка", Spoken: "Корсу́нка"},
+	{Term: "Корсунки", Spoken: "Корсу́нки"},
+	{Term: "Корсунці", Spoken: "Корсу́нці"},
+	{Term: "Олешки", Spoken: "Оле́шки"},
+	{Term: "Олешок", Spoken: "Оле́шок"},
+	{Term: "Олешках", Spoken: "Оле́шках"},
+	{Term: "Гола Пристань", Spoken: "Гола́ При́стань"},
+	{Term: "Голої Пристані", Spoken: "Голо́ї При́стані"},
+	{Term: "Голій Пристані", Spoken: "Голі́й При́стані"},
+	{Term: "Дніпро", Spoken: "Дніпро́"},
+	{Term: "Дніпра", Spoken: "Дніпра́"},
+	{Term: "Дніпрі", Spoken: "Дніпрі́"},
+}
+
+// placePronunciationsByLength - словарь, упорядоченный от длинных терминов к коротким:
+// "Нова Каховка" находится раньше, чем входящая в нее "Каховка"
+var placePronunciationsByLength = func() []models.PronunciationHint {
+	sorted := append([]models.PronunciationHint(nil), placePronunciations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return utf8.RuneCountInString(sorted[i].Term) > utf8.RuneCountInString(sorted[j].Term)
+	})
+	return sorted
+}()
+
+// SpeechPlainText готовит текст для чтения вслух: убирает HTML, markdown, ссылки и эмодзи,
+// а строки без знака препинания в конце разделяет точкой, чтобы синтезатор делал паузу.
+func SpeechPlainText(text string) string {
+	text = speechHTMLTag.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = speechMarkdownLink.ReplaceAllString(text, "$1")
+	text = speechURL.ReplaceAllString(text, "")
+
+	var cleaned strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '*' || r == '_' || r == '`' || r == '~' || r == '#':
+			// Разметка и хэштеги: "#Каховка" читается как "Каховка"
+		case r == '\u200d' || r == '\ufe0f':
+			// Соединители и селекторы вариантов эмодзи
+		case unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r):
+			// Эмодзи и пиктограммы
+		default:
+			cleaned.WriteRune(r)
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(cleaned.String(), "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, ">•")
+		line = strings.TrimPrefix(line, "- ")
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			last, _ := utf8.DecodeLastRuneInString(b.String())
+			if !strings.ContainsRune(".!?:;,…", last) {
+				b.WriteByte('.')
+			}
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+	}
+
+	plain := b.String()
+	if utf8.RuneCountInString(plain) > models.MaxPlainTextBodyLength {
+		plain = string([]rune(plain)[:models.MaxPlainTextBodyLength])
+	}
+	return plain
+}
+
+// PronunciationHintsFor находит в текстах названия из словаря ударений.
+// Каждое название возвращается один раз, вхождения внутри более длинного названия не учитываются.
+func PronunciationHintsFor(texts ...string) []models.PronunciationHint {
+	text := strings.Join(texts, "\n")
+	var hints []models.PronunciationHint
+	for _, hint := range placePronunciationsByLength {
+		masked, found := maskSpeechTerm(text, hint.Term)
+		if !found {
+			continue
+		}
+		text = masked
+		hints = append(hints, hint)
+		if len(hints) == models.MaxPronunciationHints {
+			break
+		}
+	}
+	return hints
+}
+
+// maskSpeechTerm заменяет пробелами все вхождения термина целым словом
+func maskSpeechTerm(text, term string) (string, bool) {
+	found := false
+	offset := 0
+	for {
+		index := strings.Index(text[offset:], term)
+		if index < 0 {
+			return text, found
+		}
+		start := offset + index
+		end := start + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isSpeechWordRune(before)) && (end == len(text) || !isSpeechWordRune(after)) {
+			text = text[:start] + strings.Repeat(" ", len(term)) + text[end:]
+			found = true
+		}
+		offset = end
+	}
+}
+
+func isSpeechWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' || r == 'ʼ'
+}
+
+// SpeechHintsFor собирает подсказки для контента. Текст автора и его подсказки имеют приоритет;
+// plain_text_body не сохраняется, если совпадает с body - клиент читает body.
+// texts - все поля, которые озвучиваются (заголовок, текст, адрес).
+func SpeechHintsFor(body string, custom models.SpeechHints, texts ...string) models.SpeechHints {
+	hints := models.SpeechHints{PlainTextBody: custom.PlainTextBody}
+	if hints.PlainTextBody == "" {
+		hints.PlainTextBody = SpeechPlainText(body)
+	}
+	if hints.PlainTextBody == strings.TrimSpace(body) {
+		hints.PlainTextBody = ""
+	}
+
+	seen := map[string]bool{}
+	for _, hint := range custom.Pronunciations {
+		seen[hint.Term] = true
+		hints.Pronunciations = append(hints.Pronunciations, hint)
+	}
+	for _, hint := range PronunciationHintsFor(texts...) {
+		if len(hints.Pronunciations) == models.MaxPronunciationHints {
+			break
+		}
+		if !seen[hint.Term] {
+			hints.Pronunciations = append(hints.Pronunciations, hint)
+		}
+	}
+	return hints
+}
+
+// AnnouncementSpeechHints - подсказки объявления: озвучиваются заголовок, текст и адрес
+func AnnouncementSpeechHints(announcement models.Announcement, custom models.SpeechHints) models.SpeechHints {
+	return SpeechHintsFor(announcement.Description, custom, announcement.Title, announcement.Description, announcement.Address)
+}
+
+// AuthorPronunciations оставляет подсказки, добавленные автором: словарные пересчитываются
+// заново при изменении текста
+func AuthorPronunciations(hints []models.PronunciationHint) []models.PronunciationHint {
+	var author []models.PronunciationHint
+	for _, hint := range hints {
+		if !isDictionaryPronunciation(hint) {
+			author = append(author, hint)
+		}
+	}
+	return author
+}
+
+func isDictionaryPronunciation(hint models.PronunciationHint) bool {
+	for _, known := range placePronunciations {
+		if known == hint {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeSpeechHints проверяет подсказки, переданные в запросе
+func NormalizeSpeechHints(hints models.SpeechHints) (models.SpeechHints, error) {
+	hints.PlainTextBody = strings.TrimSpace(hints.PlainTextBody)
+	if utf8.RuneCountInString(hints.PlainTextBody) > models.MaxPlainTextBodyLength {
+		return hints, fmt.Errorf("plain_text_body must be at most %d characters", models.MaxPlainTextBodyLength)
+	}
+	if len(hints.Pronunciations) > models.MaxPronunciationHints {
+		return hints, fmt.Errorf("at most %d pronunciation hints are allowed", models.MaxPronunciationHints)
+	}
+
+	normalized := make([]models.PronunciationHint, 0, len(hints.Pronunciations))
+	seen := map[string]bool{}
+	for _, hint := range hints.Pronunciations {
+		hint.Term = strings.TrimSpace(hint.Term)
+		hint.Spoken = strings.TrimSpace(hint.Spoken)
+		hint.IPA = strings.TrimSpace(hint.IPA)
+		if hint.Term == "" || hint.Spoken == "" {
+			return hints, errors.New("pronunciation hints require term and spoken")
+		}
+		if utf8.RuneCountInString(hint.Term) > models.MaxPronunciationLength ||
+			utf8.RuneCountInString(hint.Spoken) > models.MaxPronunciationLength ||
+			utf8.RuneCountInString(hint.IPA) > models.MaxPronunciationLength {
+			return hints, fmt.Errorf("pronunciation hint fields must be at most %d characters", models.MaxPronunciationLength)
+		}
+		if seen[hint.Term] {
+			continue
+		}
+		seen[hint.Term] = true
+		normalized = append(normalized, hint)
+	}
+	hints.Pronunciations = normalized
+	if len(hints.Pronunciations) == 0 {
+		hints.Pronunciations = nil
+	}
+	return hints, nil
+}
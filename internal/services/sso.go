@@ -0,0 +1,496 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Провайдеры SSO сотрудников горсовета
+const (
+	SSOProviderAzure  = "azure"
+	SSOProviderGoogle = "google"
+)
+
+// Время между переходом к провайдеру и возвратом на callback
+const SSOStateTTL = 10 * time.Minute
+
+const (
+	oidcDiscoveryTTL = 24 * time.Hour
+	oidcKeysMinAge   = 5 * time.Minute // Не чаще одной загрузки ключей при неизвестном kid
+)
+
+var (
+	ErrSSOUnknownProvider = errors.New("unknown SSO provider")
+	ErrSSOInvalidState    = errors.New("invalid or expired SSO state")
+	ErrSSORedirect        = errors.New("redirect URL is not allowed for SSO")
+	ErrSSOEmail           = errors.New("identity provider did not return a verified email")
+	ErrSSODomain          = errors.New("email domain is not allowed for SSO")
+	ErrSSONoRole          = errors.New("no staff role is mapped for this account")
+)
+
+// SSOProviderConfig - OIDC-приложение, зарегистрированное у провайдера
+type SSOProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+// AzureSSOProvider - приложение Azure AD одного тенанта горсовета
+func AzureSSOProvider(tenantID, clientID, clientSecret string) SSOProviderConfig {
+	return SSOProviderConfig{
+		Name:         SSOProviderAzure,
+		Issuer:       "https://login.microsoftonline.com/" + tenantID + "/v2.0",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// GoogleSSOProvider - приложение Google Workspace
+func GoogleSSOProvider(clientID, clientSecret string) SSOProviderConfig {
+	return SSOProviderConfig{
+		Name:         SSOProviderGoogle,
+		Issuer:       "https://accounts.google.com",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// SSOIdentity - сотрудник, подтвержденный провайдером, с ролью по правилам сопоставления
+type SSOIdentity struct {
+	Provider  string
+	Subject   string
+	Email     string
+	FirstName string
+	LastName  string
+	Role      models.UserRole
+}
+
+// SSOService - вход сотрудников через OIDC (authorization code + PKCE).
+// Состояние входа не хранится: параметр state подписан, а PKCE verifier выводится из nonce секретом сервиса.
+type SSOService struct {
+	providers       map[string]*oidcProvider
+	allowedDomains  map[string]bool
+	roleRules       map[string]models.UserRole // "group:<id>", "role:<app role>", "domain:<домен>", "email:<адрес>"
+	callbackBaseURL string
+	appRedirects    map[string]bool
+	secret          []byte
+	httpClient      *http.Client
+}
+
+type oidcProvider struct {
+	SSOProviderConfig
+
+	mu           sync.Mutex
+	discovery    *oidcDiscovery
+	discoveredAt time.Time
+	keys         map[string]*rsa.PublicKey
+	keysLoadedAt time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type ssoState struct {
+	Provider  string `json:"p"`
+	Nonce     string `json:"n"`
+	Redirect  string `json:"r,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewSSOService - провайдеры без client ID пропускаются; правила с ролью, отличной от MODERATOR и ADMIN, игнорируются
+func NewSSOService(providers []SSOProviderConfig, allowedDomains []string, roleMapping map[string]string, callbackBaseURL string, appRedirects []string, secret string) *SSOService {
+	s := &SSOService{
+		providers:       make(map[string]*oidcProvider),
+		allowedDomains:  make(map[string]bool),
+		roleRules:       make(map[string]models.UserRole),
+		callbackBaseURL: strings.TrimRight(callbackBaseURL, "/"),
+		appRedirects:    make(map[string]bool),
+		secret:          []byte(secret),
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, provider := range providers {
+		if provider.ClientID != "" {
+			s.providers[provider.Name] = &oidcProvider{SSOProviderConfig: provider}
+		}
+	}
+	for _, domain := range allowedDomains {
+		s.allowedDomains[strings.ToLower(strings.TrimSpace(domain))] = true
+	}
+	for rule, role := range roleMapping {
+		mapped := models.UserRole(strings.ToUpper(role))
+		if mapped != models.RoleModerator && mapped != models.RoleAdmin {
+			log.Printf("SSO: правило %s пропущено: роль %s не назначается через SSO", rule, role)
+			continue
+		}
+		s.roleRules[strings.ToLower(rule)] = mapped
+	}
+	for _, redirect := range appRedirects {
+		s.appRedirects[redirect] = true
+	}
+	return s
+}
+
+// Enabled - настроен хотя бы один провайдер
+func (s *SSOService) Enabled() bool {
+	return len(s.providers) > 0
+}
+
+// Providers - имена настроенных провайдеров
+func (s *SSOService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for _, name := range []string{SSOProviderAzure, SSOProviderGoogle} {
+		if _, ok := s.providers[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CallbackURL - redirect_uri, зарегистрированный у провайдера
+func (s *SSOService) CallbackURL(provider string) string {
+	return s.callbackBaseURL + "/api/v1/auth/sso/" + provider + "/callback"
+}
+
+// Begin готовит переход к провайдеру. Возвращает адрес авторизации и nonce,
+// который обработчик сохраняет в cookie браузера и передает в Complete.
+func (s *SSOService) Begin(ctx context.Context, providerName, redirect string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrSSOUnknownProvider
+	}
+	if redirect != "" && !s.appRedirects[redirect] {
+		return "", "", ErrSSORedirect
+	}
+	discovery, err := provider.discover(ctx, s.httpClient)
+	if err != nil {
+		return "", "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+
+	payload, err := json.Marshal(ssoState{
+		Provider:  providerName,
+		Nonce:     nonce,
+		Redirect:  redirect,
+		ExpiresAt: time.Now().Add(SSOStateTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	challenge := sha256.Sum256([]byte(s.pkceVerifier(nonce)))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", s.CallbackURL(providerName))
+	query.Set("scope", "openid email profile")
+	query.Set("state", encoded+"."+s.sign("sso-state:"+encoded))
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	query.Set("prompt", "select_account")
+	// Google показывает только аккаунты домена горсовета
+	if providerName == SSOProviderGoogle && len(s.allowedDomains) == 1 {
+		for domain := range s.allowedDomains {
+			query.Set("hd", domain)
+		}
+	}
+
+	return discovery.AuthorizationEndpoint + "?" + query.Encode(), nonce, nil
+}
+
+// Complete проверяет state, обменивает код на ID-токен и определяет роль сотрудника.
+// Возвращает также адрес админ-панели, указанный при Begin.
+func (s *SSOService) Complete(ctx context.Context, providerName, code, state, nonce string) (*SSOIdentity, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, "", ErrSSOUnknownProvider
+	}
+
+	encoded, signature, found := strings.Cut(state, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.sign("sso-state:"+encoded))) {
+		return nil, "", ErrSSOInvalidState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", ErrSSOInvalidState
+	}
+	var st ssoState
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return nil, "", ErrSSOInvalidState
+	}
+	// Nonce из cookie привязывает ответ провайдера к браузеру, начавшему вход
+	if st.Provider != providerName || time.Now().Unix() > st.ExpiresAt || nonce == "" || !hmac.Equal([]byte(st.Nonce), []byte(nonce)) {
+		return nil, "", ErrSSOInvalidState
+	}
+
+	rawIDToken, err := s.exchangeCode(ctx, provider, code, s.pkceVerifier(st.Nonce))
+	if err != nil {
+		return nil, "", err
+	}
+	claims, err := s.verifyIDToken(ctx, provider, rawIDToken, st.Nonce)
+	if err != nil {
+		return nil, "", err
+	}
+
+	identity, err := s.identity(providerName, claims)
+	if err != nil {
+		return nil, "", err
+	}
+	return identity, st.Redirect, nil
+}
+
+func (s *SSOService) identity(providerName string, claims jwt.MapClaims) (*SSOIdentity, error) {
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	// В Azure AD email может отсутствовать, тогда адрес берется из UPN
+	if email == "" {
+		if username, _ := claims["preferred_username"].(string); strings.Contains(username, "@") {
+			email = username
+		}
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if verified, ok := claims["email_verified"].(bool); subject == "" || email == "" || (ok && !verified) {
+		return nil, ErrSSOEmail
+	}
+
+	domain := email[strings.LastIndex(email, "@")+1:]
+	if len(s.allowedDomains) == 0 || !s.allowedDomains[domain] {
+		return nil, fmt.Errorf("%w: %s", ErrSSODomain, domain)
+	}
+
+	// Наивысшая роль среди подошедших правил
+	candidates := []string{"email:" + email, "domain:" + domain}
+	for _, claim := range []string{"groups", "roles"} {
+		values, _ := claims[claim].([]interface{})
+		for _, value := range values {
+			if str, ok := value.(string); ok {
+				candidates = append(candidates, strings.TrimSuffix(claim, "s")+":"+strings.ToLower(str))
+			}
+		}
+	}
+	var role models.UserRole
+	for _, candidate := range candidates {
+		if mapped, ok := s.roleRules[candidate]; ok && mapped.GetRoleLevel() > role.GetRoleLevel() {
+			role = mapped
+		}
+	}
+	if role == "" {
+		return nil, ErrSSONoRole
+	}
+
+	identity := &SSOIdentity{
+		Provider: providerName,
+		Subject:  subject,
+		Email:    email,
+		Role:     role,
+	}
+	identity.FirstName, _ = claims["given_name"].(string)
+	identity.LastName, _ = claims["family_name"].(string)
+	if identity.FirstName == "" {
+		name, _ := claims["name"].(string)
+		identity.FirstName, identity.LastName, _ = strings.Cut(strings.TrimSpace(name), " ")
+	}
+	if identity.FirstName == "" {
+		identity.FirstName = email[:strings.LastIndex(email, "@")]
+	}
+	return identity, nil
+}
+
+func (s *SSOService) exchangeCode(ctx context.Context, provider *oidcProvider, code, verifier string) (string, error) {
+	discovery, err := provider.discover(ctx, s.httpClient)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.CallbackURL(provider.Name))
+	form.Set("client_id", provider.ClientID)
+	form.Set("client_secret", provider.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("SSO token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("SSO token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.IDToken == "" {
+		return "", fmt.Errorf("SSO token endpoint returned no id_token")
+	}
+	return token.IDToken, nil
+}
+
+func (s *SSOService) verifyIDToken(ctx context.Context, provider *oidcProvider, raw, nonce string) (jwt.MapClaims, error) {
+	discovery, err := provider.discover(ctx, s.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return provider.key(ctx, s.httpClient, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); !hmac.Equal([]byte(tokenNonce), []byte(nonce)) {
+		return nil, fmt.Errorf("invalid ID token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+func (s *SSOService) pkceVerifier(nonce string) string {
+	return s.sign("sso-pkce:" + nonce)
+}
+
+func (s *SSOService) sign(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// discover загружает OpenID-конфигурацию провайдера (кэшируется на сутки)
+func (p *oidcProvider) discover(ctx context.Context, client *http.Client) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, client, p.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("SSO discovery for %s failed: %w", p.Name, err)
+	}
+	if discovery.Issuer != p.Issuer || discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("SSO discovery for %s returned unexpected configuration", p.Name)
+	}
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// key - открытый ключ подписи ID-токенов; при неизвестном kid ключи перечитываются (ротация у провайдера)
+func (p *oidcProvider) key(ctx context.Context, client *http.Client, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if p.discovery == nil || time.Since(p.keysLoadedAt) < oidcKeysMinAge {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, client, p.discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("SSO keys for %s: %w", p.Name, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	p.keys = keys
+	p.keysLoadedAt = time.Now()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func getJSON(ctx context.Context, client *http.Client, rawURL string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", rawURL, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(target)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Как часто воркер проверяет, сделан ли сегодняшний снимок статистики
+const statsSnapshotCheck = time.Hour
+
+// StatsHistoryService раз в сутки сохраняет ключевые показатели платформы в stats_history,
+// чтобы аналитика показывала динамику год к году, а не только текущие значения.
+type StatsHistoryService struct {
+	historyCollection  *mongo.Collection
+	userCollection     *mongo.Collection
+	issueCollection    *mongo.Collection
+	petitionCollection *mongo.Collection
+}
+
+func NewStatsHistoryService(historyCollection, userCollection, issueCollection, petitionCollection *mongo.Collection) *StatsHistoryService {
+	return &StatsHistoryService{
+		historyCollection:  historyCollection,
+		userCollection:     userCollection,
+		issueCollection:    issueCollection,
+		petitionCollection: petitionCollection,
+	}
+}
+
+// Collect считает показатели на текущий момент
+func (s *StatsHistoryService) Collect(ctx context.Context, now time.Time) (*models.StatsSnapshot, error) {
+	snapshot := &models.StatsSnapshot{
+		Date:    now.Format(models.CalendarDateLayout),
+		TakenAt: now,
+	}
+
+	var err error
+	if snapshot.Users, err = s.userCollection.CountDocuments(ctx, bson.M{"is_deleted": bson.M{"$ne": true}}); err != nil {
+		return nil, err
+	}
+	if snapshot.ActiveIssues, err = s.issueCollection.CountDocuments(ctx, bson.M{
+		"status": bson.M{"$in": []string{models.IssueStatusReported, models.IssueStatusInProgress}},
+	}); err != nil {
+		return nil, err
+	}
+	if snapshot.ResolvedIssues, err = s.issueCollection.CountDocuments(ctx, bson.M{"status": models.IssueStatusResolved}); err != nil {
+		return nil, err
+	}
+
+	// Черновики не собирают подписи, остальные статусы сохраняют собранные
+	cursor, err := s.petitionCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$ne": models.PetitionStatusDraft}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"total": bson.M{"$sum": "$signature_count"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if cursor.Next(ctx) {
+		var row struct {
+			Total int64 `bson:"total"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		snapshot.Signatures = row.Total
+	}
+	return snapshot, cursor.Err()
+}
+
+// TakeSnapshotIfDue сохраняет снимок текущего дня, если его еще нет. Несколько экземпляров
+// сервера не создадут второй снимок: день уникален в индексе.
+func (s *StatsHistoryService) TakeSnapshotIfDue(ctx context.Context) error {
+	now := time.Now()
+	count, err := s.historyCollection.CountDocuments(ctx, bson.M{"date": now.Format(models.CalendarDateLayout)})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	snapshot, err := s.Collect(ctx, now)
+	if err != nil {
+		return err
+	}
+	_, err = s.historyCollection.InsertOne(ctx, snapshot)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// StartSnapshots запускает ежедневные снимки статистики; первый - при старте сервера
+func (s *StatsHistoryService) StartSnapshots() {
+	ticker := time.NewTicker(statsSnapshotCheck)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		if err := s.TakeSnapshotIfDue(ctx); err != nil {
+			log.Printf("Error taking stats snapshot: %v", err)
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
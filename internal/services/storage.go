@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Снимок делается раз в сутки; проверка - каждый час, чтобы перезапуски не сбивали расписание
+	storageSnapshotInterval = 24 * time.Hour
+	storageSnapshotCheck    = time.Hour
+	// Окно для расчета прироста и минимальный интервал между снимками для него
+	storageGrowthWindow   = 30 * 24 * time.Hour
+	storageGrowthMinRange = 20 * time.Hour
+	// Прогноз достижения лимита, при котором выдается предупреждение
+	storageForecastWarnDays = 30
+	// Ограничение общих тарифов Atlas (M0-M5) на количество коллекций
+	atlasSharedMaxCollections = 500
+)
+
+// Уровни предупреждений отчета
+const (
+	StorageAlertWarning  = "warning"
+	StorageAlertCritical = "critical"
+)
+
+// storageCollectionModules - к какому модулю платформы относится коллекция; прочие - models.ModuleCore
+var storageCollectionModules = map[string]string{
+	"groups":                   models.ModuleGroups,
+	"messages":                 models.ModuleGroups,
+	"message_reports":          models.ModuleGroups,
+	"banned_words":             models.ModuleGroups,
+	"conversations":            models.ModuleGroups,
+	"direct_messages":          models.ModuleGroups,
+	"announcements":            models.ModuleAnnouncements,
+	"events":                   models.ModuleEvents,
+	"calendar_days":            models.ModuleEvents,
+	"petitions":                models.ModulePetitions,
+	"polls":                    models.ModulePolls,
+	"poll_summaries":           models.ModulePolls,
+	"poll_responses":           models.ModulePolls,
+	"poll_results":             models.ModulePolls,
+	"city_issues":              models.ModuleCityIssues,
+	"issue_digest_items":       models.ModuleCityIssues,
+	"transport_routes":         models.ModuleTransport,
+	"transport_vehicles":       models.ModuleTransport,
+	"transport_alerts":         models.ModuleTransport,
+	"notifications":            models.ModuleNotifications,
+	"device_tokens":            models.ModuleNotifications,
+	"push_campaigns":           models.ModuleNotifications,
+	"campaign_recipients":      models.ModuleNotifications,
+	"email_queue":              models.ModuleNotifications,
+	"email_delivery_logs":      models.ModuleNotifications,
+	"email_suppressions":       models.ModuleNotifications,
+	"faq_categories":           models.ModuleFAQ,
+	"faq_articles":             models.ModuleFAQ,
+	"faq_feedback":             models.ModuleFAQ,
+	"education_institutions":   models.ModuleEducation,
+	"enrollments":              models.ModuleEducation,
+	"enrollment_subscriptions": models.ModuleEducation,
+	"consultations":            models.ModuleConsultations,
+	"consultation_comments":    models.ModuleConsultations,
+}
+
+// storageMediaFields - поля со ссылками на файлы медиахранилища; сами файлы хранятся вне MongoDB
+var storageMediaFields = map[string]struct {
+	field string
+	array bool
+}{
+	"announcements": {field: "media_files", array: true},
+	"city_issues":   {field: "photos", array: true},
+	"petitions":     {field: "attachment_urls", array: true},
+	"users":         {field: "avatar"},
+}
+
+// StorageQuotaConfig - лимит тарифа Atlas для предупреждений (0 - без лимита, например выделенный кластер)
+type StorageQuotaConfig struct {
+	LimitBytes  int64
+	WarnPercent int
+}
+
+// ModuleStorage - использование хранилища модулем
+type ModuleStorage struct {
+	Module      string   `json:"module"`
+	Collections []string `json:"collections"`
+	Count       int64    `json:"count"`
+	AvgObjSize  int64    `json:"avg_obj_size"`
+	DataSize    int64    `json:"data_size"`
+	StorageSize int64    `json:"storage_size"`
+	IndexSize   int64    `json:"index_size"`
+	MediaFiles  int64    `json:"media_files"`
+	// Прирост в сутки (байты данных и индексов, документы); отсутствует, пока нет снимка для сравнения
+	BytesPerDay     *int64 `json:"bytes_per_day,omitempty"`
+	DocumentsPerDay *int64 `json:"documents_per_day,omitempty"`
+}
+
+// StorageGrowth - прирост базы относительно самого раннего снимка в окне
+type StorageGrowth struct {
+	Since           time.Time `json:"since"`
+	Days            float64   `json:"days"`
+	BytesPerDay     int64     `json:"bytes_per_day"`
+	DocumentsPerDay int64     `json:"documents_per_day"`
+	MediaPerDay     float64   `json:"media_files_per_day"`
+	DaysUntilLimit  *int      `json:"days_until_limit,omitempty"`
+}
+
+// StorageAlert - предупреждение о приближении к лимиту тарифа
+type StorageAlert struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// StorageReport - отчет об использовании хранилища для планирования мощностей
+type StorageReport struct {
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Collections []models.CollectionStorage `json:"collections"`
+	Modules     []ModuleStorage            `json:"modules"`
+	Totals      struct {
+		Collections int   `json:"collections"`
+		Count       int64 `json:"count"`
+		DataSize    int64 `json:"data_size"`
+		StorageSize int64 `json:"storage_size"`
+		IndexSize   int64 `json:"index_size"`
+		MediaFiles  int64 `json:"media_files"`
+	} `json:"totals"`
+	LimitBytes  int64          `json:"limit_bytes,omitempty"`
+	UsedBytes   int64          `json:"used_bytes"`
+	UsedPercent float64        `json:"used_percent,omitempty"`
+	Growth      *StorageGrowth `json:"growth,omitempty"`
+	Alerts      []StorageAlert `json:"alerts"`
+}
+
+// StorageService собирает размеры коллекций и хранит ежедневные снимки для расчета прироста
+type StorageService struct {
+	db                 *mongo.Database
+	snapshotCollection *mongo.Collection
+	quota              StorageQuotaConfig
+}
+
+func NewStorageService(db *mongo.Database, snapshotCollection *mongo.Collection, quota StorageQuotaConfig) *StorageService {
+	return &StorageService{
+		db:                 db,
+		snapshotCollection: snapshotCollection,
+		quota:              quota,
+	}
+}
+
+// Collect измеряет все коллекции базы (collStats) и подсчитывает ссылки на медиафайлы
+func (s *StorageService) Collect(ctx context.Context) (*models.StorageSnapshot, error) {
+	names, err := s.db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	snapshot := &models.StorageSnapshot{
+		TakenAt:     time.Now(),
+		Collections: make([]models.CollectionStorage, 0, len(names)),
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+
+		var stats struct {
+			Count          int64   `bson:"count"`
+			Size           int64   `bson:"size"`
+			AvgObjSize     float64 `bson:"avgObjSize"`
+			StorageSize    int64   `bson:"storageSize"`
+			TotalIndexSize int64   `bson:"totalIndexSize"`
+		}
+		if err := s.db.RunCommand(ctx, bson.D{{Key: "collStats", Value: name}}).Decode(&stats); err != nil {
+			return nil, fmt.Errorf("collStats %s: %w", name, err)
+		}
+
+		media, err := s.countMedia(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("media files %s: %w", name, err)
+		}
+
+		collection := models.CollectionStorage{
+			Name:        name,
+			Module:      storageModule(name),
+			Count:       stats.Count,
+			AvgObjSize:  int64(stats.AvgObjSize),
+			DataSize:    stats.Size,
+			StorageSize: stats.StorageSize,
+			IndexSize:   stats.TotalIndexSize,
+			MediaFiles:  media,
+		}
+		snapshot.Collections = append(snapshot.Collections, collection)
+		snapshot.DataSize += collection.DataSize
+		snapshot.StorageSize += collection.StorageSize
+		snapshot.IndexSize += collection.IndexSize
+		snapshot.MediaFiles += collection.MediaFiles
+	}
+	return snapshot, nil
+}
+
+// countMedia - количество ссылок на файлы медиахранилища в коллекции
+func (s *StorageService) countMedia(ctx context.Context, name string) (int64, error) {
+	media, ok := storageMediaFields[name]
+	if !ok {
+		return 0, nil
+	}
+	collection := s.db.Collection(name)
+	if !media.array {
+		return collection.CountDocuments(ctx, bson.M{media.field: bson.M{"$exists": true, "$ne": ""}})
+	}
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{media.field + ".0": bson.M{"$exists": true}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"files": bson.M{"$sum": bson.M{"$size": "$" + media.field}},
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Files int64 `bson:"files"`
+	}
+	if err := cursor.All(ctx, &result); err != nil || len(result) == 0 {
+		return 0, err
+	}
+	return result[0].Files, nil
+}
+
+// Report - текущее использование по коллекциям и модулям, прирост и предупреждения о лимите тарифа
+func (s *StorageService) Report(ctx context.Context) (*StorageReport, error) {
+	current, err := s.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := s.baseline(ctx, current.TakenAt)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageReport{
+		GeneratedAt: current.TakenAt,
+		Collections: current.Collections,
+		Modules:     moduleStorage(current, baseline),
+		LimitBytes:  s.quota.LimitBytes,
+		UsedBytes:   current.UsedBytes(),
+		Alerts:      []StorageAlert{},
+	}
+	report.Totals.Collections = len(current.Collections)
+	report.Totals.DataSize = current.DataSize
+	report.Totals.StorageSize = current.StorageSize
+	report.Totals.IndexSize = current.IndexSize
+	report.Totals.MediaFiles = current.MediaFiles
+	for _, collection := range current.Collections {
+		report.Totals.Count += collection.Count
+	}
+
+	if baseline != nil {
+		days := current.TakenAt.Sub(baseline.TakenAt).Hours() / 24
+		growth := &StorageGrowth{
+			Since:           baseline.TakenAt,
+			Days:            days,
+			BytesPerDay:     int64(float64(current.UsedBytes()-baseline.UsedBytes()) / days),
+			DocumentsPerDay: int64(float64(report.Totals.Count-snapshotCount(baseline)) / days),
+			MediaPerDay:     float64(current.MediaFiles-baseline.MediaFiles) / days,
+		}
+		if s.quota.LimitBytes > 0 && growth.BytesPerDay > 0 {
+			remaining := int((s.quota.LimitBytes - current.UsedBytes()) / growth.BytesPerDay)
+			if remaining < 0 {
+				remaining = 0
+			}
+			growth.DaysUntilLimit = &remaining
+		}
+		report.Growth = growth
+	}
+
+	if s.quota.LimitBytes > 0 {
+		report.UsedPercent = float64(report.UsedBytes) * 100 / float64(s.quota.LimitBytes)
+		report.Alerts = s.alerts(report)
+	}
+	return report, nil
+}
+
+func (s *StorageService) alerts(report *StorageReport) []StorageAlert {
+	alerts := []StorageAlert{}
+	switch {
+	case report.UsedPercent >= 95:
+		alerts = append(alerts, StorageAlert{
+			Level:   StorageAlertCritical,
+			Message: fmt.Sprintf("Storage is %.1f%% of the tier limit (%d MB); writes will be rejected at 100%%", report.UsedPercent, s.quota.LimitBytes>>20),
+		})
+	case s.quota.WarnPercent > 0 && report.UsedPercent >= float64(s.quota.WarnPercent):
+		alerts = append(alerts, StorageAlert{
+			Level:   StorageAlertWarning,
+			Message: fmt.Sprintf("Storage is %.1f%% of the tier limit (%d MB)", report.UsedPercent, s.quota.LimitBytes>>20),
+		})
+	}
+
+	if report.Growth != nil && report.Growth.DaysUntilLimit != nil && *report.Growth.DaysUntilLimit <= storageForecastWarnDays {
+		alerts = append(alerts, StorageAlert{
+			Level:   StorageAlertWarning,
+			Message: fmt.Sprintf("At the current growth rate the tier limit will be reached in %d days", *report.Growth.DaysUntilLimit),
+		})
+	}
+
+	if report.Totals.Collections >= atlasSharedMaxCollections*9/10 {
+		alerts = append(alerts, StorageAlert{
+			Level:   StorageAlertWarning,
+			Message: fmt.Sprintf("%d collections of %d allowed on shared Atlas tiers", report.Totals.Collections, atlasSharedMaxCollections),
+		})
+	}
+	return alerts
+}
+
+// baseline - самый ранний снимок в окне прироста, сделанный не позже чем storageGrowthMinRange назад
+func (s *StorageService) baseline(ctx context.Context, now time.Time) (*models.StorageSnapshot, error) {
+	var snapshot models.StorageSnapshot
+	err := s.snapshotCollection.FindOne(ctx,
+		bson.M{"taken_at": bson.M{
+			"$gte": now.Add(-storageGrowthWindow),
+			"$lte": now.Add(-storageGrowthMinRange),
+		}},
+		options.FindOne().SetSort(bson.D{{Key: "taken_at", Value: 1}}),
+	).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// TakeSnapshotIfDue сохраняет снимок, если с предыдущего прошли сутки
+func (s *StorageService) TakeSnapshotIfDue(ctx context.Context) error {
+	var last models.StorageSnapshot
+	err := s.snapshotCollection.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: "taken_at", Value: -1}})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	// Допуск на время самого измерения, чтобы снимок не сдвигался на час каждые сутки
+	if err == nil && time.Since(last.TakenAt) < storageSnapshotInterval-storageSnapshotCheck/2 {
+		return nil
+	}
+
+	snapshot, err := s.Collect(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = s.snapshotCollection.InsertOne(ctx, snapshot)
+	return err
+}
+
+// StartSnapshots запускает ежедневные снимки использования хранилища
+func (s *StorageService) StartSnapshots() {
+	ticker := time.NewTicker(storageSnapshotCheck)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		if err := s.TakeSnapshotIfDue(ctx); err != nil {
+			log.Printf("Error taking storage snapshot: %v", err)
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+func storageModule(collection string) string {
+	if module, ok := storageCollectionModules[collection]; ok {
+		return module
+	}
+	return models.ModuleCore
+}
+
+func snapshotCount(snapshot *models.StorageSnapshot) int64 {
+	var count int64
+	for _, collection := range snapshot.Collections {
+		count += collection.Count
+	}
+	return count
+}
+
+// moduleStorage суммирует коллекции по модулям; прирост считается относительно baseline
+func moduleStorage(current, baseline *models.StorageSnapshot) []ModuleStorage {
+	byModule := map[string]*ModuleStorage{}
+	modules := []string{}
+	for _, collection := range current.Collections {
+		module, ok := byModule[collection.Module]
+		if !ok {
+			module = &ModuleStorage{Module: collection.Module}
+			byModule[collection.Module] = module
+			modules = append(modules, collection.Module)
+		}
+		module.Collections = append(module.Collections, collection.Name)
+		module.Count += collection.Count
+		module.DataSize += collection.DataSize
+		module.StorageSize += collection.StorageSize
+		module.IndexSize += collection.IndexSize
+		module.MediaFiles += collection.MediaFiles
+	}
+
+	if baseline != nil {
+		days := current.TakenAt.Sub(baseline.TakenAt).Hours() / 24
+		previous := map[string]*ModuleStorage{}
+		for _, collection := range baseline.Collections {
+			module, ok := previous[collection.Module]
+			if !ok {
+				module = &ModuleStorage{}
+				previous[collection.Module] = module
+			}
+			module.Count += collection.Count
+			module.DataSize += collection.DataSize
+			module.IndexSize += collection.IndexSize
+		}
+		for name, module := range byModule {
+			before := previous[name]
+			if before == nil {
+				before = &ModuleStorage{}
+			}
+			bytesPerDay := int64(float64(module.DataSize+module.IndexSize-before.DataSize-before.IndexSize) / days)
+			documentsPerDay := int64(float64(module.Count-before.Count) / days)
+			module.BytesPerDay = &bytesPerDay
+			module.DocumentsPerDay = &documentsPerDay
+		}
+	}
+
+	// Крупнейшие модули первыми
+	result := make([]ModuleStorage, 0, len(modules))
+	for _, name := range modules {
+		module := byModule[name]
+		if module.Count > 0 {
+			module.AvgObjSize = module.DataSize / module.Count
+		}
+		result = append(result, *module)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DataSize+result[i].IndexSize > result[j].DataSize+result[j].IndexSize
+	})
+	return result
+}
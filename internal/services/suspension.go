@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Как часто снимаются истекшие приостановки
+const suspensionCheckInterval = time.Minute
+
+// SuspensionService снимает истекшие временные блокировки (is_blocked + blocked_until).
+// Проверки доступа смотрят на blocked_until сами, задача лишь приводит документы в порядок.
+type SuspensionService struct {
+	userCollection *mongo.Collection
+	userStatus     *UserStatusCache
+}
+
+func NewSuspensionService(userCollection *mongo.Collection, userStatus *UserStatusCache) *SuspensionService {
+	return &SuspensionService{
+		userCollection: userCollection,
+		userStatus:     userStatus,
+	}
+}
+
+// StartWorker периодически снимает истекшие приостановки
+func (s *SuspensionService) StartWorker() {
+	ticker := time.NewTicker(suspensionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		lifted, err := s.LiftExpired(ctx, time.Now())
+		cancel()
+		if err != nil {
+			log.Printf("Error lifting expired suspensions: %v", err)
+		} else if lifted > 0 {
+			log.Printf("Lifted %d expired account suspensions", lifted)
+		}
+
+		<-ticker.C
+	}
+}
+
+// LiftExpired разблокирует пользователей, чья приостановка закончилась к моменту now
+func (s *SuspensionService) LiftExpired(ctx context.Context, now time.Time) (int, error) {
+	filter := bson.M{
+		"is_blocked":    true,
+		"blocked_until": bson.M{"$lte": now},
+	}
+	cursor, err := s.userCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var users []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for _, user := range users {
+		// Фильтр повторяется: администратор мог продлить приостановку или заблокировать навсегда
+		result, err := s.userCollection.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "is_blocked": true, "blocked_until": bson.M{"$lte": now}},
+			bson.M{
+				"$set": bson.M{
+					"is_blocked":   false,
+					"block_reason": "",
+					"blocked_at":   nil,
+					"updated_at":   now,
+				},
+				"$unset": bson.M{"blocked_until": ""},
+			},
+		)
+		if err != nil {
+			return lifted, err
+		}
+		if result.ModifiedCount > 0 {
+			lifted++
+			s.userStatus.Invalidate(user.ID)
+		}
+	}
+	return lifted, nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrendingTag - тег и количество нового контента с ним за период
+type TrendingTag struct {
+	Tag   string `bson:"_id" json:"tag"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// TagService - канонизация тегов, счетчики использования, слияние и переименование
+type TagService struct {
+	tagCollection   *mongo.Collection
+	contentByModule map[string]*mongo.Collection // module -> коллекция контента с полем tags
+}
+
+func NewTagService(tagCollection *mongo.Collection, contentByModule map[string]*mongo.Collection) *TagService {
+	return &TagService{
+		tagCollection:   tagCollection,
+		contentByModule: contentByModule,
+	}
+}
+
+// Canonicalize нормализует теги и заменяет устаревшие названия (после слияния) на актуальные
+func (s *TagService) Canonicalize(ctx context.Context, tags []string) ([]string, error) {
+	normalized := models.NormalizeTags(tags)
+	if len(normalized) == 0 {
+		return []string{}, nil
+	}
+
+	cursor, err := s.tagCollection.Find(ctx,
+		bson.M{"aliases": bson.M{"$in": normalized}},
+		options.Find().SetProjection(bson.M{"name": 1, "aliases": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var merged []models.Tag
+	if err := cursor.All(ctx, &merged); err != nil {
+		return nil, err
+	}
+
+	if len(merged) > 0 {
+		replacement := make(map[string]string)
+		for _, tag := range merged {
+			for _, alias := range tag.Aliases {
+				replacement[alias] = tag.Name
+			}
+		}
+		for i, tag := range normalized {
+			if name, ok := replacement[tag]; ok {
+				normalized[i] = name
+			}
+		}
+		normalized = models.NormalizeTags(normalized)
+	}
+
+	if len(normalized) > models.MaxTagsPerEntity {
+		return nil, fmt.Errorf("too many tags (max %d)", models.MaxTagsPerEntity)
+	}
+	return normalized, nil
+}
+
+// Track обновляет счетчики использования после сохранения контента модуля
+func (s *TagService) Track(ctx context.Context, module string, previous, current []string) {
+	before := make(map[string]bool, len(previous))
+	for _, tag := range previous {
+		before[tag] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, tag := range current {
+		after[tag] = true
+	}
+
+	now := time.Now()
+	for tag := range after {
+		if before[tag] {
+			continue
+		}
+		_, err := s.tagCollection.UpdateOne(ctx,
+			bson.M{"name": tag},
+			bson.M{
+				"$inc":         bson.M{"usage." + module: 1, "usage_count": 1},
+				"$set":         bson.M{"last_used_at": now},
+				"$setOnInsert": bson.M{"name": tag, "created_at": now},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			fmt.Printf("Error tracking tag %q: %v\n", tag, err)
+		}
+	}
+
+	for tag := range before {
+		if after[tag] {
+			continue
+		}
+		if _, err := s.tagCollection.UpdateOne(ctx,
+			bson.M{"name": tag},
+			bson.M{"$inc": bson.M{"usage." + module: -1, "usage_count": -1}},
+		); err != nil {
+			fmt.Printf("Error tracking tag %q: %v\n", tag, err)
+		}
+	}
+}
+
+// Autocomplete возвращает популярные теги, начинающиеся с prefix
+func (s *TagService) Autocomplete(ctx context.Context, prefix, module string, limit int) ([]models.Tag, error) {
+	filter := bson.M{"usage_count": bson.M{"$gt": 0}}
+	if prefix = models.CanonicalTag(prefix); prefix != "" {
+		filter["name"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	if module != "" {
+		filter["usage."+module] = bson.M{"$gt": 0}
+	}
+
+	cursor, err := s.tagCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "usage_count", Value: -1}, {Key: "name", Value: 1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := []models.Tag{}
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Trending возвращает теги, чаще всего встречающиеся в контенте модуля, созданном после since.
+// scope - дополнительный фильтр (например, громада).
+func (s *TagService) Trending(ctx context.Context, module string, since time.Time, limit int, scope bson.M) ([]TrendingTag, error) {
+	collection, ok := s.contentByModule[module]
+	if !ok {
+		return nil, fmt.Errorf("module %q has no tags", module)
+	}
+
+	match := bson.M{
+		"created_at": bson.M{"$gte": since},
+		"tags.0":     bson.M{"$exists": true},
+	}
+	for key, value := range scope {
+		match[key] = value
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	trending := []TrendingTag{}
+	if err := cursor.All(ctx, &trending); err != nil {
+		return nil, err
+	}
+	return trending, nil
+}
+
+// Merge объединяет теги sources в target во всем контенте.
+// Переименование - частный случай слияния одного тега в новое название.
+func (s *TagService) Merge(ctx context.Context, sources []string, target string) (*models.Tag, int64, error) {
+	target = models.CanonicalTag(target)
+	if target == "" {
+		return nil, 0, fmt.Errorf("target tag is empty")
+	}
+
+	var names []string
+	for _, source := range models.NormalizeTags(sources) {
+		if source != target {
+			names = append(names, source)
+		}
+	}
+	if len(names) == 0 {
+		return nil, 0, fmt.Errorf("no source tags to merge")
+	}
+
+	// Переписываем теги в контенте: сначала добавляем target, затем убираем исходные
+	var modified int64
+	for module, collection := range s.contentByModule {
+		filter := bson.M{"tags": bson.M{"$in": names}}
+		result, err := collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": target}})
+		if err != nil {
+			return nil, modified, fmt.Errorf("ошибка слияния тегов в %s: %w", module, err)
+		}
+		if _, err := collection.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": bson.M{"$in": names}}}); err != nil {
+			return nil, modified, fmt.Errorf("ошибка слияния тегов в %s: %w", module, err)
+		}
+		modified += result.MatchedCount
+	}
+
+	// Устаревшие названия и их собственные алиасы переходят к target
+	aliases := append([]string{}, names...)
+	cursor, err := s.tagCollection.Find(ctx, bson.M{"name": bson.M{"$in": names}})
+	if err != nil {
+		return nil, modified, err
+	}
+	var mergedTags []models.Tag
+	if err := cursor.All(ctx, &mergedTags); err != nil {
+		return nil, modified, err
+	}
+	for _, tag := range mergedTags {
+		aliases = append(aliases, tag.Aliases...)
+	}
+
+	now := time.Now()
+	if _, err := s.tagCollection.UpdateOne(ctx,
+		bson.M{"name": target},
+		bson.M{
+			"$addToSet":    bson.M{"aliases": bson.M{"$each": aliases}},
+			"$set":         bson.M{"last_used_at": now},
+			"$setOnInsert": bson.M{"name": target, "created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		return nil, modified, err
+	}
+	// target мог ранее быть алиасом другого тега
+	if _, err := s.tagCollection.UpdateMany(ctx,
+		bson.M{"name": bson.M{"$ne": target}},
+		bson.M{"$pull": bson.M{"aliases": target}},
+	); err != nil {
+		return nil, modified, err
+	}
+	if _, err := s.tagCollection.DeleteMany(ctx, bson.M{"name": bson.M{"$in": names}}); err != nil {
+		return nil, modified, err
+	}
+
+	tag, err := s.recount(ctx, target)
+	return tag, modified, err
+}
+
+// recount пересчитывает использование тега по всем модулям
+func (s *TagService) recount(ctx context.Context, name string) (*models.Tag, error) {
+	usage := make(map[string]int, len(s.contentByModule))
+	total := 0
+	for module, collection := range s.contentByModule {
+		count, err := collection.CountDocuments(ctx, bson.M{"tags": name})
+		if err != nil {
+			return nil, err
+		}
+		usage[module] = int(count)
+		total += int(count)
+	}
+
+	var tag models.Tag
+	err := s.tagCollection.FindOneAndUpdate(ctx,
+		bson.M{"name": name},
+		bson.M{"$set": bson.M{"usage": usage, "usage_count": total}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&tag)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Время жизни кэша таксономий
+const taxonomyCacheTTL = time.Minute
+
+// TaxonomyService хранит категории модулей и проверяет значения category при создании контента
+type TaxonomyService struct {
+	taxonomyCollection *mongo.Collection
+
+	mu       sync.RWMutex
+	byModule map[string][]models.Taxonomy // module -> категории (включая неактивные), по sort_order
+	loadedAt time.Time
+}
+
+func NewTaxonomyService(taxonomyCollection *mongo.Collection) *TaxonomyService {
+	return &TaxonomyService{
+		taxonomyCollection: taxonomyCollection,
+		byModule:           make(map[string][]models.Taxonomy),
+	}
+}
+
+// EnsureDefaults создает категории по умолчанию, не трогая уже измененные администраторами
+func (s *TaxonomyService) EnsureDefaults(ctx context.Context) error {
+	now := time.Now()
+	for _, taxonomy := range models.DefaultTaxonomies() {
+		insert := bson.M{
+			"module":     taxonomy.Module,
+			"code":       taxonomy.Code,
+			"names":      taxonomy.Names,
+			"icon":       taxonomy.Icon,
+			"sort_order": taxonomy.SortOrder,
+			"is_active":  true,
+			"created_at": now,
+			"updated_at": now,
+		}
+		if len(taxonomy.Matches) > 0 {
+			insert["matches"] = taxonomy.Matches
+		}
+
+		_, err := s.taxonomyCollection.UpdateOne(
+			ctx,
+			bson.M{"module": taxonomy.Module, "code": taxonomy.Code},
+			bson.M{"$setOnInsert": insert},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка создания категории %s/%s: %w", taxonomy.Module, taxonomy.Code, err)
+		}
+	}
+
+	return s.Reload(ctx)
+}
+
+// Reload перечитывает таксономии из базы
+func (s *TaxonomyService) Reload(ctx context.Context) error {
+	cursor, err := s.taxonomyCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки таксономий: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var taxonomies []models.Taxonomy
+	if err := cursor.All(ctx, &taxonomies); err != nil {
+		return fmt.Errorf("ошибка декодирования таксономий: %w", err)
+	}
+
+	byModule := make(map[string][]models.Taxonomy)
+	for _, taxonomy := range taxonomies {
+		byModule[taxonomy.Module] = append(byModule[taxonomy.Module], taxonomy)
+	}
+	for module := range byModule {
+		items := byModule[module]
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].SortOrder != items[j].SortOrder {
+				return items[i].SortOrder < items[j].SortOrder
+			}
+			return items[i].Code < items[j].Code
+		})
+	}
+
+	s.mu.Lock()
+	s.byModule = byModule
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// List возвращает категории модуля; неактивные - только при includeInactive
+func (s *TaxonomyService) List(module string, includeInactive bool) []models.Taxonomy {
+	s.refreshIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Taxonomy, 0, len(s.byModule[module]))
+	for _, taxonomy := range s.byModule[module] {
+		if taxonomy.IsActive || includeInactive {
+			result = append(result, taxonomy)
+		}
+	}
+	return result
+}
+
+// Validate проверяет, что категория существует и активна в модуле
+func (s *TaxonomyService) Validate(module, code string) error {
+	for _, taxonomy := range s.List(module, true) {
+		if taxonomy.Code != code {
+			continue
+		}
+		if !taxonomy.IsActive {
+			return fmt.Errorf("category %q is no longer available", code)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown category %q", code)
+}
+
+// NormalizeInterests приводит интересы пользователя к кодам таксономии interests.
+// Принимаются код или название на любом языке без учета регистра; дубликаты убираются.
+// unknown - значения, не найденные среди активных интересов.
+func (s *TaxonomyService) NormalizeInterests(values []string) (codes []string, unknown []string) {
+	lookup := make(map[string]string)
+	for _, interest := range s.List(models.TaxonomyInterests, false) {
+		lookup[strings.ToLower(interest.Code)] = interest.Code
+		for _, name := range interest.Names {
+			if name != "" {
+				lookup[strings.ToLower(name)] = interest.Code
+			}
+		}
+	}
+
+	codes = []string{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if key == "" {
+			continue
+		}
+		code, ok := lookup[key]
+		if !ok {
+			unknown = append(unknown, value)
+			continue
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes, unknown
+}
+
+// InterestFilter - условие выборки контента модуля, подходящего под интересы:
+// категория из matches интереса или тег с кодом интереса. nil - интересов нет.
+func (s *TaxonomyService) InterestFilter(module string, interests []string) bson.M {
+	if len(interests) == 0 {
+		return nil
+	}
+
+	selected := make(map[string]bool, len(interests))
+	for _, code := range interests {
+		selected[code] = true
+	}
+
+	var categories []string
+	for _, interest := range s.List(models.TaxonomyInterests, false) {
+		if selected[interest.Code] {
+			categories = append(categories, interest.Matches[module]...)
+		}
+	}
+
+	conditions := []bson.M{{"tags": bson.M{"$in": interests}}}
+	if len(categories) > 0 {
+		conditions = append(conditions, bson.M{"category": bson.M{"$in": categories}})
+	}
+	return bson.M{"$or": conditions}
+}
+
+// InterestsForContent - коды интересов, которым соответствует контент модуля
+func (s *TaxonomyService) InterestsForContent(module, category string, tags []string) []string {
+	var codes []string
+	for _, interest := range s.List(models.TaxonomyInterests, false) {
+		if interest.MatchesContent(module, category, tags) {
+			codes = append(codes, interest.Code)
+		}
+	}
+	return codes
+}
+
+// NormalizeStoredInterests переводит ранее сохраненные произвольные интересы пользователей в коды таксономии.
+// Нераспознанные значения удаляются. Возвращает количество измененных пользователей.
+func (s *TaxonomyService) NormalizeStoredInterests(ctx context.Context, userCollection *mongo.Collection) (int, error) {
+	cursor, err := userCollection.Find(ctx,
+		bson.M{"interests.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"interests": 1}),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка загрузки интересов пользователей: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+
+		codes, unknown := s.NormalizeInterests(user.Interests)
+		if len(unknown) == 0 && slices.Equal(codes, user.Interests) {
+			continue
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{
+			"$set": bson.M{"interests": codes},
+		})
+		if err != nil {
+			return updated, fmt.Errorf("ошибка обновления интересов пользователя %s: %w", user.ID.Hex(), err)
+		}
+		updated++
+	}
+	return updated, cursor.Err()
+}
+
+func (s *TaxonomyService) refreshIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > taxonomyCacheTTL
+	s.mu.RUnlock()
+
+	if !stale {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Reload(ctx); err != nil {
+		// Оставляем старый кэш, попробуем снова при следующем запросе
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+		fmt.Printf("Error reloading taxonomies: %v\n", err)
+	}
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+	"nova-kakhovka-ecity/pkg/pdf"
+)
+
+// ErrTimetableEmpty - у маршрута (остановки) нет отправлений для печати
+var ErrTimetableEmpty = errors.New("timetable is empty")
+
+// Колонки печатного расписания: тип дня и заголовок
+var timetableColumns = []struct {
+	day   string
+	title string
+}{
+	{models.ScheduleDayWeekday, "Будні"},
+	{models.ScheduleDaySaturday, "Субота"},
+	{models.ScheduleDaySunday, "Неділя"},
+	{models.ScheduleDayHoliday, "Святкові дні"},
+}
+
+var transportTypeTitles = map[string]string{
+	models.TransportTypeBus:     "Автобус",
+	models.TransportTypeTrolley: "Тролейбус",
+	models.TransportTypeMinibus: "Маршрутне таксі",
+	models.TransportTypeTaxi:    "Таксі",
+}
+
+// Разметка страницы (пункты)
+const (
+	timetableMargin      = 40.0
+	timetableColumnsTop  = 160.0
+	timetableFooterSpace = 60.0
+	timetableHourWidth   = 24.0
+	timetableMaxFontSize = 10.0
+	timetableMinFontSize = 6.0
+)
+
+// TimetableService формирует печатные расписания маршрутов (PDF) для размещения на остановках.
+// Шрифт загружается при первом запросе: без него сервер работает, недоступен только PDF.
+type TimetableService struct {
+	fontPath string
+
+	once    sync.Once
+	font    *pdf.Font
+	fontErr error
+}
+
+func NewTimetableService(fontPath string) *TimetableService {
+	return &TimetableService{
+		fontPath: fontPath,
+	}
+}
+
+func (s *TimetableService) loadFont() (*pdf.Font, error) {
+	s.once.Do(func() {
+		s.font, s.fontErr = pdf.LoadFont(s.fontPath)
+		if s.fontErr != nil {
+			s.fontErr = fmt.Errorf("timetable font %s: %w", s.fontPath, s.fontErr)
+		}
+	})
+	return s.font, s.fontErr
+}
+
+// BuildTimetable раскладывает расписание маршрута по остановкам и типам дней.
+// Отправления остановки берутся из ее записей расписания (departure_time или интервалы);
+// если у остановки своих интервалов нет, интервалы маршрута (записи без остановки
+// или начальной остановки) сдвигаются на время в пути до нее.
+func BuildTimetable(route *models.TransportRoute) []models.StopTimetable {
+	stops := make([]models.TransportStop, len(route.Stops))
+	copy(stops, route.Stops)
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].StopOrder < stops[j].StopOrder })
+	if len(stops) == 0 {
+		return nil
+	}
+
+	origin := stops[0]
+	isOrigin := func(schedule models.TransportSchedule) bool {
+		if schedule.StopID.IsZero() && schedule.StopName == "" {
+			return true
+		}
+		return scheduleAtStop(schedule, origin)
+	}
+
+	timetables := make([]models.StopTimetable, 0, len(stops))
+	for _, stop := range stops {
+		timetable := models.StopTimetable{
+			StopID:    stop.ID,
+			StopName:  stop.Name,
+			StopOrder: stop.StopOrder,
+			Days:      make(map[string][]string),
+		}
+
+		for _, column := range timetableColumns {
+			minutes := make(map[int]bool)
+			ownIntervals := false
+
+			for _, schedule := range route.Schedule {
+				if !scheduleAtStop(schedule, stop) {
+					continue
+				}
+				if schedule.DayType == column.day {
+					for _, value := range []string{schedule.DepartureTime, schedule.ArrivalTime} {
+						if m, ok := parseClock(value); ok {
+							minutes[m] = true
+							break
+						}
+					}
+				}
+				if intervals := scheduleIntervals(schedule, column.day); len(intervals) > 0 {
+					ownIntervals = true
+					expandIntervals(minutes, intervals, 0)
+				}
+			}
+
+			if !ownIntervals {
+				offset := stop.TravelTimeFromStart - origin.TravelTimeFromStart
+				for _, schedule := range route.Schedule {
+					if isOrigin(schedule) && !scheduleAtStop(schedule, stop) {
+						expandIntervals(minutes, scheduleIntervals(schedule, column.day), offset)
+					}
+				}
+			}
+
+			if len(minutes) > 0 {
+				timetable.Days[column.day] = sortedClock(minutes)
+			}
+		}
+
+		timetables = append(timetables, timetable)
+	}
+	return timetables
+}
+
+// RenderPDF формирует PDF: по странице на каждую остановку из timetables, у которой есть отправления.
+// dayType - печатать только этот тип дня (пусто - все колонки).
+func (s *TimetableService) RenderPDF(route *models.TransportRoute, timetables []models.StopTimetable, dayType string, generatedAt time.Time) ([]byte, error) {
+	days := make([]string, 0, len(timetableColumns))
+	for _, column := range timetableColumns {
+		if dayType == "" || column.day == dayType {
+			days = append(days, column.day)
+		}
+	}
+
+	var printable []models.StopTimetable
+	for _, timetable := range timetables {
+		for _, day := range days {
+			if len(timetable.Days[day]) > 0 {
+				printable = append(printable, timetable)
+				break
+			}
+		}
+	}
+	if len(printable) == 0 {
+		return nil, ErrTimetableEmpty
+	}
+
+	font, err := s.loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := pdf.NewDocument(font)
+	doc.SetTitle(fmt.Sprintf("Маршрут № %s - %s", route.RouteNumber, route.RouteName))
+
+	terminus := route.GetLastStop()
+	for _, timetable := range printable {
+		direction := ""
+		if terminus != nil && terminus.ID != timetable.StopID {
+			direction = terminus.Name
+		}
+		renderStopPage(doc.AddPage(), route, timetable, days, direction, generatedAt)
+	}
+	return doc.Bytes()
+}
+
+// renderStopPage - страница остановки; direction - конечная маршрута (пусто - эта остановка конечная)
+func renderStopPage(page *pdf.Page, route *models.TransportRoute, timetable models.StopTimetable, days []string, direction string, generatedAt time.Time) {
+	right := pdf.PageWidth - timetableMargin
+
+	page.Text(timetableMargin, 58, 22, "Маршрут № "+route.RouteNumber)
+	if title, ok := transportTypeTitles[route.TransportType]; ok {
+		page.TextRight(right, 58, 12, title)
+	}
+	page.Text(timetableMargin, 80, 12, route.RouteName)
+
+	page.Text(timetableMargin, 112, 16, "Зупинка: "+timetable.StopName)
+	if direction != "" {
+		page.Text(timetableMargin, 132, 11, "Напрямок: "+direction)
+	} else {
+		page.Text(timetableMargin, 132, 11, "Кінцева зупинка")
+	}
+	page.Line(timetableMargin, 142, right, 142, 1)
+
+	// Праздничная колонка не нужна, если в праздники действует воскресное расписание
+	columns := make([]string, 0, len(days))
+	sundayForHoliday := false
+	for _, day := range days {
+		if day == models.ScheduleDayHoliday && len(timetable.Days[day]) == 0 && len(days) > 1 {
+			sundayForHoliday = true
+			continue
+		}
+		columns = append(columns, day)
+	}
+
+	columnWidth := (right - timetableMargin) / float64(len(columns))
+	bottom := pdf.PageHeight - timetableFooterSpace
+
+	// Подбираем кегль, при котором самая длинная колонка помещается на страницу
+	size := timetableMaxFontSize
+	for ; size > timetableMinFontSize; size -= 0.5 {
+		fits := true
+		for _, day := range columns {
+			rows := hourRows(page, timetable.Days[day], columnWidth, size)
+			if timetableColumnsTop+24+float64(len(rows))*size*1.35 > bottom {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			break
+		}
+	}
+	lineHeight := size * 1.35
+
+	for i, day := range columns {
+		x := timetableMargin + float64(i)*columnWidth
+		page.FillRect(x, timetableColumnsTop-16, columnWidth-4, 22, 0.88)
+		page.Text(x+4, timetableColumnsTop, 12, timetableColumnTitle(day))
+
+		times := timetable.Days[day]
+		if len(times) == 0 {
+			page.Text(x+4, timetableColumnsTop+24, size, "Не курсує")
+			continue
+		}
+
+		y := timetableColumnsTop + 24
+		currentHour := ""
+		for _, row := range hourRows(page, times, columnWidth, size) {
+			if y > bottom {
+				break
+			}
+			if row.hour != currentHour {
+				if currentHour != "" {
+					page.Line(x, y-lineHeight+2, x+columnWidth-8, y-lineHeight+2, 0.3)
+				}
+				page.Text(x+4, y, size, row.hour)
+				currentHour = row.hour
+			}
+			page.Text(x+timetableHourWidth, y, size, row.minutes)
+			y += lineHeight
+		}
+	}
+
+	footer := pdf.PageHeight - 30
+	if sundayForHoliday {
+		page.Text(timetableMargin, footer-18, 10, "У святкові дні транспорт курсує за розкладом неділі.")
+	}
+	notes := "Розклад сформовано " + generatedAt.Format("02.01.2006")
+	if route.Fare > 0 {
+		notes += ". Вартість проїзду: " + strconv.FormatFloat(route.Fare, 'f', 2, 64) + " грн"
+	}
+	page.Text(timetableMargin, footer, 9, notes)
+}
+
+// hourRow - строка расписания: час и минуты отправлений (длинный час переносится на несколько строк)
+type hourRow struct {
+	hour    string
+	minutes string
+}
+
+func hourRows(page *pdf.Page, times []string, columnWidth, size float64) []hourRow {
+	available := columnWidth - timetableHourWidth - 8
+	perLine := int(math.Max(1, math.Floor(available/page.TextWidth("00 ", size))))
+
+	var rows []hourRow
+	var hour string
+	var minutes []string
+	flush := func() {
+		for start := 0; start < len(minutes); start += perLine {
+			end := min(start+perLine, len(minutes))
+			rows = append(rows, hourRow{hour: hour, minutes: strings.Join(minutes[start:end], " ")})
+		}
+	}
+
+	for _, t := range times {
+		h, m, _ := strings.Cut(t, ":")
+		if h != hour {
+			flush()
+			hour = h
+			minutes = nil
+		}
+		minutes = append(minutes, m)
+	}
+	flush()
+	return rows
+}
+
+func timetableColumnTitle(day string) string {
+	for _, column := range timetableColumns {
+		if column.day == day {
+			return column.title
+		}
+	}
+	return day
+}
+
+// scheduleAtStop - запись расписания относится к остановке (по ID или, для старых записей, по названию)
+func scheduleAtStop(schedule models.TransportSchedule, stop models.TransportStop) bool {
+	if !schedule.StopID.IsZero() {
+		return schedule.StopID == stop.ID
+	}
+	return schedule.StopName != "" && schedule.StopName == stop.Name
+}
+
+// scheduleIntervals - интервалы записи для типа дня без подстановки воскресных в праздник
+func scheduleIntervals(schedule models.TransportSchedule, day string) []models.ScheduleInterval {
+	if day == models.ScheduleDayHoliday {
+		return schedule.Holiday
+	}
+	return schedule.GetScheduleForDay(day)
+}
+
+// expandIntervals добавляет отправления каждые Interval минут от StartTime до EndTime
+func expandIntervals(minutes map[int]bool, intervals []models.ScheduleInterval, offset int) {
+	for _, interval := range intervals {
+		start, okStart := parseClock(interval.StartTime)
+		end, okEnd := parseClock(interval.EndTime)
+		if !okStart || !okEnd || interval.Interval <= 0 {
+			continue
+		}
+		if end < start {
+			// Интервал через полночь
+			end += 24 * 60
+		}
+		for m := start; m <= end; m += interval.Interval {
+			if t := m + offset; t >= 0 {
+				minutes[t] = true
+			}
+		}
+	}
+}
+
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// sortedClock - отправления по порядку; после полуночи время считается по кругу
+func sortedClock(minutes map[int]bool) []string {
+	values := make([]int, 0, len(minutes))
+	for m := range minutes {
+		values = append(values, m)
+	}
+	sort.Ints(values)
+
+	times := make([]string, 0, len(values))
+	seen := make(map[int]bool, len(values))
+	for _, m := range values {
+		m %= 24 * 60
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		times = append(times, fmt.Sprintf("%02d:%02d", m/60, m%60))
+	}
+	return times
+}
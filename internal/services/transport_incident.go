@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Категории проблем, которые могут перекрыть движение транспорта
+var reroutingCategories = map[string]bool{
+	models.IssueCategoryRoad:   true,
+	models.IssueCategorySafety: true,
+}
+
+// TransportIncidentService связывает критические проблемы города с маршрутами транспорта:
+// маршруты, проходящие ближе radius метров от проблемы, помечаются, а администраторам
+// транспорта предлагается черновик объявления об объезде.
+// Проблема, маршруты и объявление ссылаются друг на друга.
+type TransportIncidentService struct {
+	routeCollection     *mongo.Collection
+	alertCollection     *mongo.Collection
+	issueCollection     *mongo.Collection
+	userCollection      *mongo.Collection
+	notificationService *NotificationService
+	radius              float64
+}
+
+func NewTransportIncidentService(routeCollection, alertCollection, issueCollection, userCollection *mongo.Collection, notificationService *NotificationService, radius float64) *TransportIncidentService {
+	return &TransportIncidentService{
+		routeCollection:     routeCollection,
+		alertCollection:     alertCollection,
+		issueCollection:     issueCollection,
+		userCollection:      userCollection,
+		notificationService: notificationService,
+		radius:              radius,
+	}
+}
+
+// AffectsTransport - проблема может требовать изменения движения:
+// критическая, категории road/safety и еще не закрыта
+func AffectsTransport(issue *models.CityIssue) bool {
+	if !reroutingCategories[issue.Category] || issue.Priority != models.PriorityCritical {
+		return false
+	}
+	return issue.Status == models.IssueStatusReported || issue.Status == models.IssueStatusInProgress
+}
+
+// Sync приводит пометки маршрутов в соответствие с проблемой после ее создания или изменения:
+// для затрагивающей транспорт проблемы помечает маршруты и создает черновик объявления,
+// для закрытой или переставшей быть критической - снимает пометки и отклоняет черновик.
+// Обновляет AffectedRouteIDs и TransportAlertID в issue.
+func (s *TransportIncidentService) Sync(ctx context.Context, issue *models.CityIssue) error {
+	if AffectsTransport(issue) {
+		return s.flag(ctx, issue)
+	}
+	return s.clear(ctx, issue)
+}
+
+// routeMatch - маршрут рядом с проблемой
+type routeMatch struct {
+	route    models.TransportRoute
+	distance float64
+}
+
+func (s *TransportIncidentService) flag(ctx context.Context, issue *models.CityIssue) error {
+	// Маршруты уже помечены этой проблемой
+	if len(issue.AffectedRouteIDs) > 0 {
+		return nil
+	}
+
+	matches, err := s.nearbyRoutes(ctx, issue)
+	if err != nil || len(matches) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	routeIDs := make([]primitive.ObjectID, 0, len(matches))
+	numbers := make([]string, 0, len(matches))
+	for _, match := range matches {
+		routeIDs = append(routeIDs, match.route.ID)
+		numbers = append(numbers, "№ "+match.route.RouteNumber)
+	}
+
+	alert := models.TransportAlert{
+		ID:          primitive.NewObjectID(),
+		CommunityID: issue.CommunityID,
+		RouteIDs:    routeIDs,
+		IssueID:     &issue.ID,
+		Source:      models.TransportAlertSourceIncident,
+		Title:       "Зміна руху маршрутів " + strings.Join(numbers, ", "),
+		Message: fmt.Sprintf("Через %s (%s) можливі зміни руху та об'їзд. Стежте за оновленнями.",
+			strings.ToLower(models.GetCategoryTranslation(issue.Category)), issue.Address),
+		Status:    models.TransportAlertDraft,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := s.alertCollection.InsertOne(ctx, alert); err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		_, err := s.routeCollection.UpdateOne(ctx, bson.M{"_id": match.route.ID}, bson.M{
+			"$push": bson.M{"incidents": models.RouteIncident{
+				IssueID:   issue.ID,
+				AlertID:   &alert.ID,
+				Category:  issue.Category,
+				Title:     issue.Title,
+				Distance:  match.distance,
+				FlaggedAt: now,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.issueCollection.UpdateOne(ctx, bson.M{"_id": issue.ID}, bson.M{
+		"$set": bson.M{"affected_route_ids": routeIDs, "transport_alert_id": alert.ID},
+	})
+	if err != nil {
+		return err
+	}
+	issue.AffectedRouteIDs = routeIDs
+	issue.TransportAlertID = &alert.ID
+
+	s.notifyTransportAdmins(ctx, issue, &alert)
+	return nil
+}
+
+// clear снимает пометки маршрутов; опубликованное объявление остается до завершения администратором
+func (s *TransportIncidentService) clear(ctx context.Context, issue *models.CityIssue) error {
+	if len(issue.AffectedRouteIDs) == 0 {
+		return nil
+	}
+
+	_, err := s.routeCollection.UpdateMany(ctx,
+		bson.M{"incidents.issue_id": issue.ID},
+		bson.M{"$pull": bson.M{"incidents": bson.M{"issue_id": issue.ID}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if issue.TransportAlertID != nil {
+		now := time.Now()
+		_, err = s.alertCollection.UpdateOne(ctx,
+			bson.M{"_id": *issue.TransportAlertID, "status": models.TransportAlertDraft},
+			bson.M{"$set": bson.M{"status": models.TransportAlertDismissed, "updated_at": now}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.issueCollection.UpdateOne(ctx, bson.M{"_id": issue.ID}, bson.M{
+		"$unset": bson.M{"affected_route_ids": ""},
+	})
+	if err != nil {
+		return err
+	}
+	issue.AffectedRouteIDs = nil
+	return nil
+}
+
+// nearbyRoutes - активные маршруты громады, линия которых проходит ближе radius к проблеме.
+// Линия - path_coords, при отсутствии - route_points, затем остановки.
+func (s *TransportIncidentService) nearbyRoutes(ctx context.Context, issue *models.CityIssue) ([]routeMatch, error) {
+	filter := bson.M{"is_active": true}
+	if !issue.CommunityID.IsZero() {
+		filter["community_id"] = issue.CommunityID
+	}
+
+	cursor, err := s.routeCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var matches []routeMatch
+	for cursor.Next(ctx) {
+		var route models.TransportRoute
+		if err := cursor.Decode(&route); err != nil {
+			continue
+		}
+
+		line := route.PathCoords
+		if len(line) == 0 {
+			line = route.RoutePoints
+		}
+		if len(line) == 0 {
+			for _, stop := range route.Stops {
+				line = append(line, stop.Location)
+			}
+		}
+
+		distance := models.DistanceToPolyline(issue.Location, line)
+		if distance >= 0 && distance <= s.radius {
+			matches = append(matches, routeMatch{route: route, distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	return matches, cursor.Err()
+}
+
+// notifyTransportAdmins сообщает пользователям с правом manage:transport о черновике объявления
+func (s *TransportIncidentService) notifyTransportAdmins(ctx context.Context, issue *models.CityIssue, alert *models.TransportAlert) {
+	var roles []string
+	for _, role := range models.AllRoles() {
+		if role.HasPermission(models.PermissionManageTransport) {
+			roles = append(roles, string(role))
+		}
+	}
+
+	cursor, err := s.userCollection.Find(ctx, bson.M{"role": bson.M{"$in": roles}, "is_blocked": bson.M{"$ne": true}})
+	if err != nil {
+		log.Printf("Transport incident %s: error fetching transport admins: %v", issue.ID.Hex(), err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var adminIDs []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+		adminIDs = append(adminIDs, user.ID)
+	}
+	if len(adminIDs) == 0 {
+		return
+	}
+
+	data := map[string]interface{}{
+		"alert_id":  alert.ID.Hex(),
+		"issue_id":  issue.ID.Hex(),
+		"route_ids": alert.RouteIDs,
+	}
+	err = s.notificationService.SendNotificationToUsers(
+		ctx,
+		adminIDs,
+		"Критична проблема біля маршрутів транспорту",
+		fmt.Sprintf("%s. Перевірте чернетку оголошення: %s", issue.Title, alert.Title),
+		NotificationTypeSystem,
+		data,
+		&alert.ID,
+	)
+	if err != nil {
+		log.Printf("Transport incident %s: error notifying transport admins: %v", issue.ID.Hex(), err)
+	}
+}
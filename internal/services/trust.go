@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const trustCacheTTL = 5 * time.Minute
+
+type cachedTrust struct {
+	assessment models.TrustAssessment
+	expiresAt  time.Time
+}
+
+// TrustService - оценка доверия к аккаунтам (защита от спама и ботов)
+type TrustService struct {
+	userCollection *mongo.Collection
+
+	mu    sync.Mutex
+	cache map[primitive.ObjectID]cachedTrust
+}
+
+func NewTrustService(userCollection *mongo.Collection) *TrustService {
+	return &TrustService{
+		userCollection: userCollection,
+		cache:          make(map[primitive.ObjectID]cachedTrust),
+	}
+}
+
+// Evaluate возвращает оценку доверия пользователя (с кэшированием)
+func (s *TrustService) Evaluate(ctx context.Context, userID primitive.ObjectID) (*models.TrustAssessment, error) {
+	s.mu.Lock()
+	if cached, ok := s.cache[userID]; ok && time.Now().Before(cached.expiresAt) {
+		s.mu.Unlock()
+		assessment := cached.assessment
+		return &assessment, nil
+	}
+	s.mu.Unlock()
+
+	var user models.User
+	if err := s.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	assessment := models.CalculateTrust(&user, time.Now())
+
+	s.mu.Lock()
+	if len(s.cache) > 10000 {
+		s.cache = make(map[primitive.ObjectID]cachedTrust)
+	}
+	s.cache[userID] = cachedTrust{assessment: assessment, expiresAt: time.Now().Add(trustCacheTTL)}
+	s.mu.Unlock()
+
+	return &assessment, nil
+}
+
+// Quota возвращает ограничения пользователя. При ошибке применяются ограничения низкого уровня доверия.
+func (s *TrustService) Quota(ctx context.Context, userID primitive.ObjectID) models.TrustQuota {
+	assessment, err := s.Evaluate(ctx, userID)
+	if err != nil {
+		return models.QuotaForTrustLevel(models.TrustLevelLow)
+	}
+	return assessment.Quota
+}
+
+// RecordRemoval учитывает контент автора, отклоненный или удаленный модератором
+func (s *TrustService) RecordRemoval(ctx context.Context, authorID primitive.ObjectID) error {
+	_, err := s.userCollection.UpdateOne(ctx,
+		bson.M{"_id": authorID},
+		bson.M{"$inc": bson.M{"removed_content_count": 1}},
+	)
+	s.Invalidate(authorID)
+	return err
+}
+
+// Invalidate сбрасывает кэш оценки пользователя
+func (s *TrustService) Invalidate(userID primitive.ObjectID) {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"nova-kakhovka-ecity/internal/database"
+	"nova-kakhovka-ecity/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type cachedAccountStatus struct {
+	status    models.AccountStatus
+	expiresAt time.Time
+}
+
+// UserStatusCache проверяет блокировку и актуальную роль пользователя на каждом запросе.
+// Результат кэшируется в памяти на короткое время, чтобы не читать users на каждый запрос;
+// изменения статуса через админку сбрасывают кэш сразу (Invalidate).
+type UserStatusCache struct {
+	userCollection *database.RetryCollection
+	ttl            time.Duration
+
+	mu      sync.RWMutex
+	entries map[primitive.ObjectID]cachedAccountStatus
+}
+
+func NewUserStatusCache(userCollection *database.RetryCollection, ttl time.Duration) *UserStatusCache {
+	return &UserStatusCache{
+		userCollection: userCollection,
+		ttl:            ttl,
+		entries:        make(map[primitive.ObjectID]cachedAccountStatus),
+	}
+}
+
+// AccountStatus возвращает состояние учетной записи из кэша или из базы
+func (s *UserStatusCache) AccountStatus(ctx context.Context, userID primitive.ObjectID) (models.AccountStatus, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.entries[userID]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.status, nil
+	}
+
+	var user models.User
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"role": 1, "is_moderator": 1, "is_blocked": 1, "blocked_until": 1, "token_version": 1, "moderation_scope": 1}),
+	).Decode(&user)
+
+	expiresAt := now.Add(s.ttl)
+	status := models.AccountStatus{}
+	switch err {
+	case nil:
+		status = models.AccountStatus{
+			Exists:          true,
+			IsBlocked:       user.IsBlockedAt(now),
+			Role:            user.GetRole(),
+			TokenVersion:    user.TokenVersion,
+			ModerationScope: user.ModerationScope,
+		}
+		if status.IsBlocked && user.BlockedUntil != nil {
+			status.BlockedUntil = user.BlockedUntil
+			// Призупинення знімається вчасно, а не після закінчення строку кешу
+			if user.BlockedUntil.Before(expiresAt) {
+				expiresAt = *user.BlockedUntil
+			}
+		}
+	case mongo.ErrNoDocuments:
+		// Удаленный пользователь тоже кэшируется, чтобы его токен не нагружал базу
+	default:
+		// Ошибку базы не кэшируем
+		return status, err
+	}
+
+	s.mu.Lock()
+	s.entries[userID] = cachedAccountStatus{status: status, expiresAt: expiresAt}
+	s.mu.Unlock()
+
+	return status, nil
+}
+
+// Invalidate сбрасывает кэш пользователя после блокировки, смены роли или удаления
+func (s *UserStatusCache) Invalidate(userID primitive.ObjectID) {
+	s.mu.Lock()
+	delete(s.entries, userID)
+	s.mu.Unlock()
+}
+
+// StartCleanup периодически удаляет устаревшие записи кэша
+func (s *UserStatusCache) StartCleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		s.mu.Lock()
+		for userID, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, userID)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -20,6 +20,10 @@ type Claims struct {
 	Email       string `json:"email"`
 	Role        string `json:"role"`         // ✅ ДОДАНО
 	IsModerator bool   `json:"is_moderator"` // Legacy support
+	// Версія токенів користувача на момент видачі; старіші версії відкликані
+	TokenVersion int `json:"token_version,omitempty"`
+	// Сесія (ланцюжок refresh-токенів), з якої видано токен; відкликання сесії відкликає токен
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,14 +34,21 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	}
 }
 
+// TokenDuration - час життя access-токена
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
 // ✅ ОНОВЛЕНО: Додано параметр role
-func (m *JWTManager) GenerateToken(userID string, email string, role string, isModerator bool) (string, error) {
+func (m *JWTManager) GenerateToken(userID string, email string, role string, isModerator bool, tokenVersion int, sessionID string) (string, error) {
 	// Створюємо claims з усіма полями
 	claims := Claims{
-		UserID:      userID,
-		Email:       email,
-		Role:        role,
-		IsModerator: isModerator,
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		IsModerator:  isModerator,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -98,5 +109,7 @@ func (m *JWTManager) RefreshToken(oldToken string) (string, error) {
 		claims.Email,
 		claims.Role,
 		claims.IsModerator,
+		claims.TokenVersion,
+		claims.SessionID,
 	)
 }
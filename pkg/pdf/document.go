@@ -0,0 +1,279 @@
+// pkg/pdf/document.go
+
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// Розмір сторінки A4 у пунктах
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+/**
+ * Document - простий PDF-документ: текст одним вбудованим шрифтом, лінії та заливки.
+ * Координати сторінки відраховуються від лівого верхнього кута, y - базова лінія тексту.
+ * Документ не потокобезпечний; шрифт можна використовувати в кількох документах.
+ */
+type Document struct {
+	font  *Font
+	title string
+	pages []*Page
+	used  map[uint16]rune // Використані гліфи - для ширин і ToUnicode (копіювання та пошук тексту)
+}
+
+// Page - сторінка документа
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+func NewDocument(font *Font) *Document {
+	return &Document{
+		font: font,
+		used: make(map[uint16]rune),
+	}
+}
+
+// SetTitle задає заголовок документа (показується переглядачем замість імені файлу)
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// AddPage додає сторінку A4 у книжковій орієнтації
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// PageCount - кількість сторінок документа
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text виводить рядок тексту; x - лівий край, y - базова лінія від верхнього краю сторінки
+func (p *Page) Text(x, y, size float64, text string) {
+	if text == "" {
+		return
+	}
+
+	var hex strings.Builder
+	for _, r := range text {
+		gid := p.doc.font.glyph(r)
+		if gid != 0 {
+			p.doc.used[gid] = r
+		}
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td <%s> Tj ET\n",
+		num(size), num(x), num(PageHeight-y), hex.String())
+}
+
+// TextRight виводить текст, вирівняний по правому краю right
+func (p *Page) TextRight(right, y, size float64, text string) {
+	p.Text(right-p.doc.font.TextWidth(text, size), y, size, text)
+}
+
+// TextWidth - ширина тексту шрифтом документа
+func (p *Page) TextWidth(text string, size float64) float64 {
+	return p.doc.font.TextWidth(text, size)
+}
+
+// Line малює відрізок товщиною width
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// FillRect заливає прямокутник відтінком сірого (0 - чорний, 1 - білий); y - верхній край
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "q %s g %s %s %s %s re f Q\n",
+		num(gray), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Bytes формує файл PDF
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Номери об'єктів: 1 - каталог, 2 - дерево сторінок, 3-7 - шрифт, далі сторінки
+	const (
+		catalogObj = iota + 1
+		pagesObj
+		fontObj
+		cidFontObj
+		descriptorObj
+		fontFileObj
+		toUnicodeObj
+		infoObj
+		firstPageObj
+	)
+
+	w.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+i*2)
+	}
+	w.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	f := d.font
+	w.object(fontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cidFontObj, toUnicodeObj))
+	w.object(cidFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW %d /W [%s] >>",
+		f.name, descriptorObj, f.scale(f.advance(0)), d.widths()))
+	w.object(descriptorObj, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fontFileObj))
+	if err := w.stream(fontFileObj, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return nil, err
+	}
+	if err := w.stream(toUnicodeObj, "", []byte(d.toUnicode())); err != nil {
+		return nil, err
+	}
+	w.object(infoObj, fmt.Sprintf("<< /Title %s /Producer (nova-kakhovka-ecity) >>", textString(d.title)))
+
+	for i, page := range d.pages {
+		pageObj := firstPageObj + i*2
+		w.object(pageObj, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, num(PageWidth), num(PageHeight), fontObj, pageObj+1))
+		if err := w.stream(pageObj+1, "", page.content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	w.finish(catalogObj, infoObj)
+	return w.buf.Bytes(), nil
+}
+
+// widths - масив /W з шириною кожного використаного гліфа
+func (d *Document) widths() string {
+	gids := make([]int, 0, len(d.used))
+	for gid := range d.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var b strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&b, "%d [%d] ", gid, d.font.scale(d.font.advance(uint16(gid))))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// toUnicode - CMap гліф -> Unicode, щоб текст PDF можна було копіювати і шукати
+func (d *Document) toUnicode() string {
+	gids := make([]int, 0, len(d.used))
+	for gid := range d.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// У блоці bfchar не більше 100 записів
+	for start := 0; start < len(gids); start += 100 {
+		end := min(start+100, len(gids))
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			var unicodeHex strings.Builder
+			for _, unit := range utf16.Encode([]rune{d.used[uint16(gid)]}) {
+				fmt.Fprintf(&unicodeHex, "%04X", unit)
+			}
+			fmt.Fprintf(&b, "<%04X> <%s>\n", gid, unicodeHex.String())
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.String()
+}
+
+// writer послідовно записує об'єкти і запам'ятовує їх зміщення для таблиці xref
+type writer struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *writer) object(id int, body string) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream записує потік, стиснутий FlateDecode; extra - додаткові ключі словника потоку
+func (w *writer) stream(id int, extra string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode %s>>\nstream\n", id, compressed.Len(), extra+" ")
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (w *writer) finish(rootObj, infoObj int) {
+	size := 0
+	for id := range w.offsets {
+		size = max(size, id)
+	}
+	size++
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for id := 1; id < size; id++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[id])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, rootObj, infoObj, xref)
+}
+
+// num форматує число для PDF без експоненти і зайвих нулів
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// textString - рядок PDF у UTF-16BE (для метаданих документа)
+func textString(text string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
@@ -0,0 +1,271 @@
+// pkg/pdf/font.go
+
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Font - шрифт TrueType, що вбудовується в документ повністю.
+// Стандартні шрифти PDF не містять кирилиці, тому текст виводиться вбудованим шрифтом.
+// Після завантаження шрифт лише читається і може використовуватися кількома документами одночасно.
+type Font struct {
+	name       string
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []int
+	glyphs     map[rune]uint16
+}
+
+// LoadFont завантажує шрифт TrueType (.ttf) з файлу
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseFont(name, data)
+}
+
+// ParseFont розбирає шрифт TrueType; name - ім'я шрифту в документі
+func ParseFont(name string, data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, errors.New("pdf: font file is too short")
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if record+16 > len(data) {
+			return nil, errors.New("pdf: truncated font table directory")
+		}
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("pdf: font table %s is out of range", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("pdf: font has no %s table (only TrueType fonts are supported)", tag)
+		}
+	}
+	if _, ok := tables["glyf"]; !ok {
+		return nil, errors.New("pdf: font has no glyf table (only TrueType fonts are supported)")
+	}
+
+	f := &Font{name: fontName(name), data: data}
+
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, errors.New("pdf: invalid head table")
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("pdf: invalid unitsPerEm")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+i*2:])))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, errors.New("pdf: invalid hhea table")
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	maxp := tables["maxp"]
+	if len(maxp) < 6 {
+		return nil, errors.New("pdf: invalid maxp table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+
+	hmtx := tables["hmtx"]
+	if numberOfHMetrics == 0 || len(hmtx) < numberOfHMetrics*4 {
+		return nil, errors.New("pdf: invalid hmtx table")
+	}
+	f.advances = make([]int, numGlyphs)
+	for gid := 0; gid < numGlyphs; gid++ {
+		metric := gid
+		if metric >= numberOfHMetrics {
+			// Решта гліфів мають ширину останнього запису
+			metric = numberOfHMetrics - 1
+		}
+		f.advances[gid] = int(binary.BigEndian.Uint16(hmtx[metric*4:]))
+	}
+
+	glyphs, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+	return f, nil
+}
+
+// TextWidth - ширина тексту в пунктах при розмірі шрифту size
+func (f *Font) TextWidth(text string, size float64) float64 {
+	units := 0
+	for _, r := range text {
+		units += f.advance(f.glyph(r))
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// glyph повертає номер гліфа символу; 0 - гліф відсутній (виводиться як порожній прямокутник)
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+func (f *Font) advance(gid uint16) int {
+	if int(gid) < len(f.advances) {
+		return f.advances[gid]
+	}
+	return 0
+}
+
+// scale переводить одиниці шрифту в тисячні частки кегля (одиниці PDF для метрик шрифту)
+func (f *Font) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// parseCmap будує відповідність Unicode -> гліф з таблиці cmap (формати 4 і 12)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("pdf: invalid cmap table")
+	}
+
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			break
+		}
+		platformID := binary.BigEndian.Uint16(cmap[record:])
+		encodingID := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		subtable := cmap[offset:]
+		unicodeTable := platformID == 0 || (platformID == 3 && (encodingID == 1 || encodingID == 10))
+		if !unicodeTable {
+			continue
+		}
+		switch binary.BigEndian.Uint16(subtable) {
+		case 4:
+			format4 = subtable
+		case 12:
+			format12 = subtable
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseCmapFormat12(format12)
+	case format4 != nil:
+		return parseCmapFormat4(format4)
+	default:
+		return nil, errors.New("pdf: font has no Unicode cmap")
+	}
+}
+
+func parseCmapFormat4(table []byte) (map[rune]uint16, error) {
+	if len(table) < 14 {
+		return nil, errors.New("pdf: invalid cmap format 4")
+	}
+	segCount := int(binary.BigEndian.Uint16(table[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + segCount*2 + 2
+	idDeltas := startCodes + segCount*2
+	idRangeOffsets := idDeltas + segCount*2
+	if idRangeOffsets+segCount*2 > len(table) {
+		return nil, errors.New("pdf: truncated cmap format 4")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(table[endCodes+i*2:]))
+		start := int(binary.BigEndian.Uint16(table[startCodes+i*2:]))
+		delta := int(binary.BigEndian.Uint16(table[idDeltas+i*2:]))
+		rangeOffsetPos := idRangeOffsets + i*2
+		rangeOffset := int(binary.BigEndian.Uint16(table[rangeOffsetPos:]))
+
+		for code := start; code <= end && code != 0xFFFF; code++ {
+			var gid int
+			if rangeOffset == 0 {
+				gid = (code + delta) & 0xFFFF
+			} else {
+				addr := rangeOffsetPos + rangeOffset + (code-start)*2
+				if addr+2 > len(table) {
+					continue
+				}
+				gid = int(binary.BigEndian.Uint16(table[addr:]))
+				if gid != 0 {
+					gid = (gid + delta) & 0xFFFF
+				}
+			}
+			if gid != 0 {
+				glyphs[rune(code)] = uint16(gid)
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+func parseCmapFormat12(table []byte) (map[rune]uint16, error) {
+	if len(table) < 16 {
+		return nil, errors.New("pdf: invalid cmap format 12")
+	}
+	numGroups := int(binary.BigEndian.Uint32(table[12:]))
+	if 16+numGroups*12 > len(table) {
+		return nil, errors.New("pdf: truncated cmap format 12")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		group := 16 + i*12
+		start := binary.BigEndian.Uint32(table[group:])
+		end := binary.BigEndian.Uint32(table[group+4:])
+		startGlyph := binary.BigEndian.Uint32(table[group+8:])
+		if end > unicode.MaxRune || start > end {
+			continue
+		}
+		for code := start; code <= end; code++ {
+			if gid := startGlyph + (code - start); gid != 0 && gid <= 0xFFFF {
+				glyphs[rune(code)] = uint16(gid)
+			}
+		}
+	}
+	return glyphs, nil
+}
+
+// fontName - ім'я шрифту PDF без пробілів і службових символів
+func fontName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "EmbeddedFont"
+	}
+	return b.String()
+}